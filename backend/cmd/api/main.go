@@ -3,12 +3,14 @@ package main
 import (
 	"log"
 	"os"
+	_ "time/tzdata" // user timezones must resolve even without system zoneinfo
 
 	"github.com/opentracing/opentracing-go"
 	jaegerlog "github.com/uber/jaeger-client-go/log"
 	"github.com/uber/jaeger-lib/metrics"
 
 	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/leaderboard"
 	"github.com/AleksK1NG/api-mc/internal/leaderboard/repository"
 	"github.com/AleksK1NG/api-mc/internal/leaderboard/usecase"
 	"github.com/AleksK1NG/api-mc/internal/server"
@@ -85,14 +87,15 @@ func main() {
 	defer closer.Close()
 	appLogger.Info("Opentracing connected")
 
-	// Initialize repositories
-	leaderboardRepository := repository.NewPostgresRepository(psqlDB, appLogger)
+	// Initialize repositories, Postgres stays the source of truth behind the Redis boards
+	leaderboardPgRepository := repository.NewPostgresRepository(psqlDB, appLogger)
+	var leaderboardRepository leaderboard.Repository = leaderboardPgRepository
+	if cfg.Leaderboard.Backend != "postgres" {
+		leaderboardRepository = repository.NewRedisRepository(redisClient, leaderboardPgRepository, appLogger)
+	}
 
 	// Initialize use cases
-	leaderboardUseCase := usecase.NewLeaderboardUseCaseWithoutDeps(
-		leaderboardRepository,
-		appLogger,
-	)
+	leaderboardUseCase := usecase.NewLeaderboardUseCase(cfg, leaderboardRepository, appLogger)
 
 	// Initialize server
 	s := server.NewServer(cfg, psqlDB, redisClient, appLogger, leaderboardUseCase)
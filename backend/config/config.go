@@ -10,19 +10,33 @@ import (
 
 // App config struct
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	MongoDB  MongoDB
-	Cookie   Cookie
-	Store    Store
-	Session  Session
-	Metrics  Metrics
-	Logger   Logger
-	AWS      AWS
-	Jaeger   Jaeger
-	OpenAI   OpenAIConfig
-	Gemini   GeminiConfig
+	Server        ServerConfig
+	Postgres      PostgresConfig
+	Redis         RedisConfig
+	MongoDB       MongoDB
+	Cookie        Cookie
+	Store         Store
+	Session       Session
+	Metrics       Metrics
+	Logger        Logger
+	AWS           AWS
+	Jaeger        Jaeger
+	OpenAI        OpenAIConfig
+	Gemini        GeminiConfig
+	Analytics     AnalyticsConfig
+	XP            XPConfig
+	Lessons       LessonsConfig
+	Streak        StreakConfig
+	Levels        LevelsConfig
+	Events        EventsConfig
+	Leaderboard   LeaderboardConfig
+	League        LeagueConfig
+	Challenge     ChallengeConfig
+	Live          LiveConfig
+	Quests        QuestsConfig
+	Notifications NotificationsConfig
+	Reminders     RemindersConfig
+	Auth          AuthConfig
 }
 
 // Server config struct
@@ -140,6 +154,135 @@ type GeminiConfig struct {
 	Model      string
 }
 
+// Item analytics config
+type AnalyticsConfig struct {
+	MinResponses int           // responses needed before an item is flagged or recalibrated
+	JobInterval  time.Duration // in minutes
+}
+
+// XP rules config
+type XPConfig struct {
+	CorrectAnswer    int // per correct answer
+	QuizCompletion   int // per submitted quiz attempt
+	FirstTryBonus    int // first attempt at a quiz that reaches FirstTryMinScore
+	FirstTryMinScore int
+	PerfectScore     int // first 100% score on a quiz
+	LessonCompletion int
+}
+
+// Lesson reading time config
+type LessonsConfig struct {
+	WordsPerMinute      int     // reading speed the expected reading time is based on
+	MinReadingRatio     float64 // share of the expected reading time needed to complete a lesson
+	MinReadingSeconds   int     // reading time needed to complete a lesson however short its content is
+	MaxHeartbeatSeconds int     // most reading time a single heartbeat can credit
+	HeartbeatGrace      int     // seconds of clock skew tolerated between heartbeats
+}
+
+// Daily streak config
+type StreakConfig struct {
+	FreezeEveryDays  int           // a streak freeze is earned every N consecutive days
+	MaxFreezes       int           // most freezes a user can hold
+	RepairWindowDays int           // days after a break during which the streak can be repaired
+	JobInterval      time.Duration // in minutes, lapsed streaks are broken once the user's local day ends
+}
+
+// Level curve config
+type LevelsConfig struct {
+	Thresholds      []int // total XP needed to reach level 2, 3, ..., past the last one every level costs the last step
+	FreezesPerLevel int   // streak freezes granted per level gained, capped by Streak.MaxFreezes
+}
+
+// Domain events config
+type EventsConfig struct {
+	Backend        string        // memory or redis, redis delivers through a stream shared by all instances
+	Stream         string        // Redis stream name
+	BufferSize     int           // events queued per subscriber by the memory backend
+	MaxRetries     int           // retries after a failed delivery
+	RetryDelay     time.Duration // in milliseconds, doubled after every retry
+	HandlerTimeout time.Duration // in seconds, per delivery attempt
+	PollInterval   time.Duration // in milliseconds, how often the outbox worker looks for due events
+	BatchSize      int           // outbox events claimed per poll
+	MaxAttempts    int           // outbox deliveries before an event is dead-lettered
+	RetentionDays  int           // delivered outbox events are purged after this many days
+}
+
+// Leaderboard config
+type LeaderboardConfig struct {
+	Backend           string        // redis or postgres, redis serves ranks from sorted sets kept in sync with Postgres
+	ReconcileInterval time.Duration // in minutes, entries are rebuilt from the XP ledger and the boards re-ranked
+	MaxAroundRadius   int           // most entries above and below a user an around-me window returns
+	Timezone          string        // IANA timezone the daily, weekly and monthly boards reset in, UTC when empty
+	HistoryLimit      int           // most closed periods a history request returns
+}
+
+// League config
+type LeagueConfig struct {
+	Tiers         []string // tier names from the lowest, users start in the first one
+	CohortSize    int      // users per weekly cohort
+	PromoteCount  int      // top of a full cohort promoted at week close, scaled down for smaller cohorts
+	RelegateCount int      // bottom of a full cohort relegated at week close, scaled down for smaller cohorts
+}
+
+// Quiz challenge config
+type ChallengeConfig struct {
+	QuestionCount int           // questions drawn from the quiz for both players
+	AcceptWindow  time.Duration // in hours, a challenge the opponent has not accepted in time expires
+	PlayWindow    time.Duration // in hours from the acceptance, a player who has not submitted by then forfeits
+	WinnerBonusXP int           // XP awarded to the winner on top of the quiz XP
+	JobInterval   time.Duration // in minutes, how often the ended challenges are expired
+}
+
+// Live quiz config
+type LiveConfig struct {
+	QuestionCount    int           // questions drawn from the quiz when the host does not choose, all of them when not positive
+	QuestionDuration time.Duration // in seconds, countdown of a question when the host does not choose
+	MaxPoints        int           // points of an instant correct answer, a correct answer at the deadline earns half
+	MaxPlayers       int           // players who can join a session
+	StandingsSize    int           // players in the standings broadcast after each question
+	SessionTTL       time.Duration // in hours, a session's state is dropped from Redis after it
+}
+
+// Quests config
+type QuestsConfig struct {
+	DailyCount  int           // quests drawn from the active daily templates for each user and day
+	WeeklyCount int           // quests drawn from the active weekly templates for each user and week
+	JobInterval time.Duration // in minutes, how often the ended quests are expired and failed rewards paid out
+}
+
+// Notifications config
+type NotificationsConfig struct {
+	SMTPHost        string // email is disabled when empty, Mailpit listens on port 1025 in development
+	SMTPPort        int
+	SMTPUsername    string // the server is not authenticated with when empty
+	SMTPPassword    string
+	EmailFrom       string
+	VAPIDPublicKey  string        // base64url uncompressed P-256 point, web push is disabled when empty
+	VAPIDPrivateKey string        // base64url P-256 scalar
+	VAPIDSubject    string        // mailto: or https: contact of the push services
+	PushTTL         time.Duration // in seconds, how long a push service keeps a message for an offline browser
+	PollInterval    time.Duration // in seconds, how often the notification worker sends the due deliveries
+	BatchSize       int           // deliveries claimed per poll
+	MaxAttempts     int           // attempts before a delivery is given up
+	RetryDelay      time.Duration // in seconds, doubled after every failed attempt
+	StreamHeartbeat time.Duration // in seconds, comment lines keep the idle notification streams open
+}
+
+// Reminders config
+type RemindersConfig struct {
+	JobInterval   time.Duration // in minutes, how often the users whose streak is at risk are looked up
+	BatchSize     int           // users reminded per run at most
+	MaxPerWeek    int           // reminders a user gets over 7 days at most
+	RatePerMinute int           // reminders sent per minute across every instance at most
+	LockTTL       time.Duration // in seconds, the job lock expires after it when an instance dies mid run
+}
+
+// Auth config
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration // in minutes, lifetime of the JWT access tokens
+	RefreshTokenTTL time.Duration // in hours, a refresh token not used within it expires, every rotation restarts it
+}
+
 // Load config file from given path
 func LoadConfig(filename string) (*viper.Viper, error) {
 	v := viper.New()
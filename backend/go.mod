@@ -32,6 +32,7 @@ require (
 	github.com/uber/jaeger-lib v2.4.0+incompatible
 	go.uber.org/zap v1.21.0
 	golang.org/x/crypto v0.32.0
+	golang.org/x/net v0.34.0
 	google.golang.org/api v0.220.0
 )
 
@@ -117,7 +118,6 @@ require (
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.9.0 // indirect
 	golang.org/x/exp v0.0.0-20231006140011-7918f672742d // indirect
-	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/oauth2 v0.25.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/sys v0.29.0 // indirect
@@ -10,8 +10,10 @@ type Handlers interface {
 	GetAllAchievements() echo.HandlerFunc
 	UpdateAchievement() echo.HandlerFunc
 	DeleteAchievement() echo.HandlerFunc
+	DryRunRule() echo.HandlerFunc
 
 	// User Achievement Management
 	GetUserAchievements() echo.HandlerFunc
+	GetUserAchievementStatus() echo.HandlerFunc
 	AwardAchievementToUser() echo.HandlerFunc
 }
@@ -124,12 +124,6 @@ func (h *achievementHandlers) GetUserAchievements() echo.HandlerFunc {
 			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "achievementHandlers.GetUserAchievements.GetUserIDFromContext"))
 		}
 
-		go func() {
-			if err := h.achievementUC.CheckAndAwardAchievements(c.Request().Context(), userID); err != nil {
-				h.logger.Errorf("Error checking and awarding achievements: %v", err)
-			}
-		}()
-
 		achievements, err := h.achievementUC.GetUserAchievements(c.Request().Context(), userID)
 		if err != nil {
 			return httpErrors.NewInternalServerError(errors.Wrap(err, "achievementHandlers.GetUserAchievements.GetUserAchievements"))
@@ -139,6 +133,22 @@ func (h *achievementHandlers) GetUserAchievements() echo.HandlerFunc {
 	}
 }
 
+func (h *achievementHandlers) GetUserAchievementStatus() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "achievementHandlers.GetUserAchievementStatus.GetUserIDFromContext"))
+		}
+
+		statuses, err := h.achievementUC.GetUserAchievementStatus(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "achievementHandlers.GetUserAchievementStatus.GetUserAchievementStatus"))
+		}
+
+		return c.JSON(http.StatusOK, statuses)
+	}
+}
+
 func (h *achievementHandlers) AwardAchievementToUser() echo.HandlerFunc {
 	return func(c echo.Context) error {
 
@@ -164,27 +174,22 @@ func (h *achievementHandlers) AwardAchievementToUser() echo.HandlerFunc {
 	}
 }
 
-func CheckUserAchievements(achievementUC achievement.UseCase, logger logger.Logger) echo.MiddlewareFunc {
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-
-			if err := next(c); err != nil {
-				return err
-			}
-
-			userID, err := utils.GetUserIDFromContext(c)
-			if err != nil {
-				logger.Errorf("Error getting user ID from context: %v", err)
-				return nil
-			}
+func (h *achievementHandlers) DryRunRule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := &models.AchievementDryRunRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "achievementHandlers.DryRunRule.Bind"))
+		}
 
-			go func() {
-				if err := achievementUC.CheckAndAwardAchievements(c.Request().Context(), userID); err != nil {
-					logger.Errorf("Error checking and awarding achievements: %v", err)
-				}
-			}()
+		if err := utils.ValidateStruct(c.Request().Context(), req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "achievementHandlers.DryRunRule.ValidateStruct"))
+		}
 
-			return nil
+		result, err := h.achievementUC.DryRunRule(c.Request().Context(), req)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "achievementHandlers.DryRunRule.DryRunRule"))
 		}
+
+		return c.JSON(http.StatusOK, result)
 	}
 }
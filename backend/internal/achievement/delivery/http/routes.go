@@ -5,6 +5,7 @@ import (
 
 	"github.com/AleksK1NG/api-mc/internal/achievement"
 	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
@@ -24,34 +25,16 @@ func MapAchievementRoutes(
 	{
 
 		protected.GET("/user", h.GetUserAchievements())
+		protected.GET("/user/progress", h.GetUserAchievementStatus())
 
 		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionAchievementsManage))
 		{
 			admin.POST("", h.CreateAchievement())
 			admin.PUT("/:id", h.UpdateAchievement())
 			admin.DELETE("/:id", h.DeleteAchievement())
 			admin.POST("/award", h.AwardAchievementToUser())
+			admin.POST("/dry-run", h.DryRunRule())
 		}
 	}
 }
-
-func RegisterAchievementMiddleware(
-	e *echo.Echo,
-	achievementUC achievement.UseCase,
-	logger logger.Logger,
-) {
-
-	achievementMiddleware := CheckUserAchievements(achievementUC, logger)
-
-	if lessonGroup := e.Group("/lessons"); lessonGroup != nil {
-		lessonGroup.Use(achievementMiddleware)
-	}
-
-	if quizGroup := e.Group("/quizzes"); quizGroup != nil {
-		quizGroup.Use(achievementMiddleware)
-	}
-
-	if progressGroup := e.Group("/progress"); progressGroup != nil {
-		progressGroup.Use(achievementMiddleware)
-	}
-}
@@ -14,6 +14,7 @@ type Repository interface {
 	CreateAchievement(ctx context.Context, achievement *models.Achievement) (*models.Achievement, error)
 	GetAchievementByID(ctx context.Context, achievementID uuid.UUID) (*models.Achievement, error)
 	GetAllAchievements(ctx context.Context) ([]*models.Achievement, error)
+	GetEnabledAchievements(ctx context.Context) ([]*models.Achievement, error)
 	UpdateAchievement(ctx context.Context, achievement *models.Achievement) (*models.Achievement, error)
 	DeleteAchievement(ctx context.Context, achievementID uuid.UUID) error
 
@@ -21,4 +22,11 @@ type Repository interface {
 	GetUserAchievements(ctx context.Context, userID uuid.UUID) ([]*models.Achievement, error)
 	AwardAchievementToUser(ctx context.Context, userAchievement *models.UserAchievement) error
 	CheckUserHasAchievement(ctx context.Context, userID uuid.UUID, achievementID uuid.UUID) (bool, error)
+	GetUserAchievementRecords(ctx context.Context, userID uuid.UUID) ([]*models.UserAchievement, error)
+
+	// Achievement Progress
+	GetAchievementProgress(ctx context.Context, userID uuid.UUID) ([]*models.AchievementProgress, error)
+	UpsertAchievementProgress(ctx context.Context, progress []*models.AchievementProgress) error
+	DeleteAchievementProgress(ctx context.Context, achievementID uuid.UUID) error
+	GetAchievementRarity(ctx context.Context) ([]*models.AchievementRarity, error)
 }
@@ -10,19 +10,22 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/internal/achievement"
+	"github.com/AleksK1NG/api-mc/internal/events"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
 type achievementRepo struct {
-	db     *sqlx.DB
-	logger logger.Logger
+	db       *sqlx.DB
+	recorder events.Recorder
+	logger   logger.Logger
 }
 
-func NewAchievementRepository(db *sqlx.DB, logger logger.Logger) achievement.Repository {
+func NewAchievementRepository(db *sqlx.DB, recorder events.Recorder, logger logger.Logger) achievement.Repository {
 	return &achievementRepo{
-		db:     db,
-		logger: logger,
+		db:       db,
+		recorder: recorder,
+		logger:   logger,
 	}
 }
 
@@ -39,6 +42,8 @@ func (r *achievementRepo) CreateAchievement(ctx context.Context, achievement *mo
 		achievement.Type,
 		achievement.RequiredValue,
 		achievement.IconURL,
+		achievement.Rule,
+		achievement.IsEnabled,
 		achievement.CreatedAt,
 	).StructScan(achievement); err != nil {
 		return nil, errors.Wrap(err, "achievementRepo.CreateAchievement.QueryRowxContext")
@@ -63,6 +68,14 @@ func (r *achievementRepo) GetAllAchievements(ctx context.Context) ([]*models.Ach
 	return achievements, nil
 }
 
+func (r *achievementRepo) GetEnabledAchievements(ctx context.Context) ([]*models.Achievement, error) {
+	achievements := make([]*models.Achievement, 0)
+	if err := r.db.SelectContext(ctx, &achievements, getEnabledAchievementsQuery); err != nil {
+		return nil, errors.Wrap(err, "achievementRepo.GetEnabledAchievements.SelectContext")
+	}
+	return achievements, nil
+}
+
 func (r *achievementRepo) UpdateAchievement(ctx context.Context, achievement *models.Achievement) (*models.Achievement, error) {
 	if err := r.db.GetContext(
 		ctx,
@@ -73,6 +86,8 @@ func (r *achievementRepo) UpdateAchievement(ctx context.Context, achievement *mo
 		achievement.Type,
 		achievement.RequiredValue,
 		achievement.IconURL,
+		achievement.Rule,
+		achievement.IsEnabled,
 		achievement.AchievementID,
 	); err != nil {
 		return nil, errors.Wrap(err, "achievementRepo.UpdateAchievement.GetContext")
@@ -110,7 +125,13 @@ func (r *achievementRepo) AwardAchievementToUser(ctx context.Context, userAchiev
 	userAchievement.EarnedAt = time.Now()
 	userAchievement.CreatedAt = time.Now()
 
-	_, err := r.db.ExecContext(
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "achievementRepo.AwardAchievementToUser.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(
 		ctx,
 		awardAchievementToUserQuery,
 		userAchievement.UserAchievementID,
@@ -118,11 +139,20 @@ func (r *achievementRepo) AwardAchievementToUser(ctx context.Context, userAchiev
 		userAchievement.AchievementID,
 		userAchievement.EarnedAt,
 		userAchievement.CreatedAt,
-	)
-	if err != nil {
+	); err != nil {
 		return errors.Wrap(err, "achievementRepo.AwardAchievementToUser.ExecContext")
 	}
 
+	if err := r.recorder.Record(ctx, tx, userAchievement.UserID, &events.AchievementAwarded{
+		AchievementID: userAchievement.AchievementID,
+	}); err != nil {
+		return errors.Wrap(err, "achievementRepo.AwardAchievementToUser.Record")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "achievementRepo.AwardAchievementToUser.Commit")
+	}
+
 	return nil
 }
 
@@ -135,106 +165,137 @@ func (r *achievementRepo) CheckUserHasAchievement(ctx context.Context, userID uu
 	return exists, nil
 }
 
-type userProgressRepo struct {
-	db     *sqlx.DB
-	logger logger.Logger
+func (r *achievementRepo) GetUserAchievementRecords(ctx context.Context, userID uuid.UUID) ([]*models.UserAchievement, error) {
+	records := make([]*models.UserAchievement, 0)
+	if err := r.db.SelectContext(ctx, &records, getUserAchievementRecordsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "achievementRepo.GetUserAchievementRecords.SelectContext")
+	}
+	return records, nil
 }
 
-func NewUserProgressRepository(db *sqlx.DB, logger logger.Logger) achievement.UserProgressRepository {
-	return &userProgressRepo{
-		db:     db,
-		logger: logger,
+func (r *achievementRepo) GetAchievementProgress(ctx context.Context, userID uuid.UUID) ([]*models.AchievementProgress, error) {
+	progress := make([]*models.AchievementProgress, 0)
+	if err := r.db.SelectContext(ctx, &progress, getAchievementProgressQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "achievementRepo.GetAchievementProgress.SelectContext")
 	}
+	return progress, nil
 }
 
-func (r *userProgressRepo) GetUserStreak(ctx context.Context, userID uuid.UUID) (int, error) {
-	var streak int
-	err := r.db.QueryRowContext(ctx, getUserStreakQuery, userID).Scan(&streak)
-	if err != nil && err != sql.ErrNoRows {
-		return 0, errors.Wrap(err, "userProgressRepo.GetUserStreak.QueryRowContext")
+func (r *achievementRepo) UpsertAchievementProgress(ctx context.Context, progress []*models.AchievementProgress) error {
+	if len(progress) == 0 {
+		return nil
 	}
-	if err == sql.ErrNoRows {
-		return 0, nil
-	}
-	return streak, nil
-}
 
-func (r *userProgressRepo) GetUserSubjectProgress(ctx context.Context, userID uuid.UUID) ([]*models.UserProgress, error) {
-	progress := make([]*models.UserProgress, 0)
-	if err := r.db.SelectContext(ctx, &progress, getUserSubjectProgressQuery, userID); err != nil {
-		return nil, errors.Wrap(err, "userProgressRepo.GetUserSubjectProgress.SelectContext")
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "achievementRepo.UpsertAchievementProgress.BeginTxx")
 	}
-	return progress, nil
-}
+	defer tx.Rollback()
 
-func (r *userProgressRepo) GetUserXP(ctx context.Context, userID uuid.UUID) (int, error) {
-	var xp int
-	err := r.db.QueryRowContext(ctx, getUserXPQuery, userID).Scan(&xp)
-	if err != nil && err != sql.ErrNoRows {
-		return 0, errors.Wrap(err, "userProgressRepo.GetUserXP.QueryRowContext")
+	for _, p := range progress {
+		if _, err := tx.ExecContext(
+			ctx,
+			upsertAchievementProgressQuery,
+			p.UserID,
+			p.AchievementID,
+			p.CurrentValue,
+			p.RequiredValue,
+			p.Percentage,
+		); err != nil {
+			return errors.Wrap(err, "achievementRepo.UpsertAchievementProgress.ExecContext")
+		}
 	}
-	if err == sql.ErrNoRows {
-		return 0, nil
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "achievementRepo.UpsertAchievementProgress.Commit")
 	}
-	return xp, nil
-}
 
-type lessonProgressRepo struct {
-	db     *sqlx.DB
-	logger logger.Logger
+	return nil
 }
 
-func NewLessonProgressRepository(db *sqlx.DB, logger logger.Logger) achievement.LessonProgressRepository {
-	return &lessonProgressRepo{
-		db:     db,
-		logger: logger,
+func (r *achievementRepo) DeleteAchievementProgress(ctx context.Context, achievementID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, deleteAchievementProgressQuery, achievementID); err != nil {
+		return errors.Wrap(err, "achievementRepo.DeleteAchievementProgress.ExecContext")
 	}
+	return nil
 }
 
-func (r *lessonProgressRepo) GetUserCompletedLessonsCount(ctx context.Context, userID uuid.UUID) (int, error) {
-	var count int
-	err := r.db.QueryRowContext(ctx, getUserCompletedLessonsCountQuery, userID).Scan(&count)
-	if err != nil && err != sql.ErrNoRows {
-		return 0, errors.Wrap(err, "lessonProgressRepo.GetUserCompletedLessonsCount.QueryRowContext")
+func (r *achievementRepo) GetAchievementRarity(ctx context.Context) ([]*models.AchievementRarity, error) {
+	rarity := make([]*models.AchievementRarity, 0)
+	if err := r.db.SelectContext(ctx, &rarity, getAchievementRarityQuery); err != nil {
+		return nil, errors.Wrap(err, "achievementRepo.GetAchievementRarity.SelectContext")
 	}
-	if err == sql.ErrNoRows {
-		return 0, nil
-	}
-	return count, nil
+	return rarity, nil
 }
 
-type userQuizAttemptsRepo struct {
+type metricsRepo struct {
 	db     *sqlx.DB
 	logger logger.Logger
 }
 
-func NewUserQuizAttemptsRepository(db *sqlx.DB, logger logger.Logger) achievement.UserQuizAttemptsRepository {
-	return &userQuizAttemptsRepo{
+func NewMetricsRepository(db *sqlx.DB, logger logger.Logger) achievement.MetricsRepository {
+	return &metricsRepo{
 		db:     db,
 		logger: logger,
 	}
 }
 
-func (r *userQuizAttemptsRepo) GetUserHighestQuizScore(ctx context.Context, userID uuid.UUID) (int, error) {
-	var score int
-	err := r.db.QueryRowContext(ctx, getUserHighestQuizScoreQuery, userID).Scan(&score)
-	if err != nil && err != sql.ErrNoRows {
-		return 0, errors.Wrap(err, "userQuizAttemptsRepo.GetUserHighestQuizScore.QueryRowContext")
+func (r *metricsRepo) GetMetric(ctx context.Context, userID uuid.UUID, metric string, filter *models.MetricFilter) (float64, error) {
+	if filter == nil {
+		filter = &models.MetricFilter{}
+	}
+
+	var since *time.Time
+	if filter.WithinDays > 0 {
+		from := time.Now().AddDate(0, 0, -filter.WithinDays)
+		since = &from
 	}
-	if err == sql.ErrNoRows {
-		return 0, nil
+
+	var query string
+	var args []interface{}
+	switch metric {
+	case models.MetricXP:
+		query, args = getUserXPMetricQuery, []interface{}{userID}
+	case models.MetricLevel:
+		query, args = getUserLevelMetricQuery, []interface{}{userID}
+	case models.MetricCurrentStreak:
+		query, args = getCurrentStreakMetricQuery, []interface{}{userID}
+	case models.MetricMaxStreak:
+		query, args = getMaxStreakMetricQuery, []interface{}{userID}
+	case models.MetricXPEarned:
+		query, args = getXPEarnedMetricQuery, []interface{}{userID, filter.Subject, filter.Grade, since}
+	case models.MetricLessonsCompleted:
+		query, args = getLessonsCompletedMetricQuery, []interface{}{userID, filter.Subject, filter.Grade, since}
+	case models.MetricQuizAttempts:
+		query, args = getQuizAttemptsMetricQuery, []interface{}{userID, filter.Subject, filter.Grade, filter.MinScore, since}
+	case models.MetricQuizzes:
+		query, args = getQuizzesMetricQuery, []interface{}{userID, filter.Subject, filter.Grade, filter.MinScore, since}
+	case models.MetricBestQuizScore:
+		query, args = getBestQuizScoreMetricQuery, []interface{}{userID, filter.Subject, filter.Grade, filter.MinScore, since}
+	case models.MetricAvgQuizScore:
+		query, args = getAvgQuizScoreMetricQuery, []interface{}{userID, filter.Subject, filter.Grade, filter.MinScore, since}
+	case models.MetricChaptersRead:
+		query, args = getChaptersReadMetricQuery, []interface{}{userID, filter.Subject, filter.Grade}
+	case models.MetricTopSubjectChaptersRead:
+		query, args = getTopSubjectChaptersReadMetricQuery, []interface{}{userID, filter.Grade}
+	default:
+		return 0, errors.Errorf("unknown metric %q", metric)
 	}
-	return score, nil
-}
 
-func (r *userQuizAttemptsRepo) GetUserQuizAttemptsCount(ctx context.Context, userID uuid.UUID) (int, error) {
-	var count int
-	err := r.db.QueryRowContext(ctx, getUserQuizAttemptsCountQuery, userID).Scan(&count)
-	if err != nil && err != sql.ErrNoRows {
-		return 0, errors.Wrap(err, "userQuizAttemptsRepo.GetUserQuizAttemptsCount.QueryRowContext")
+	var value float64
+	if err := r.db.QueryRowxContext(ctx, query, args...).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, errors.Wrapf(err, "metricsRepo.GetMetric.Scan(%s)", metric)
 	}
-	if err == sql.ErrNoRows {
-		return 0, nil
+	return value, nil
+}
+
+func (r *metricsRepo) ListUserIDs(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	userIDs := make([]uuid.UUID, 0, limit)
+	if err := r.db.SelectContext(ctx, &userIDs, listUserIDsQuery, limit); err != nil {
+		return nil, errors.Wrap(err, "metricsRepo.ListUserIDs.SelectContext")
 	}
-	return count, nil
+	return userIDs, nil
 }
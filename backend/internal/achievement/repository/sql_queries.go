@@ -2,8 +2,8 @@ package repository
 
 const (
 	createAchievementQuery = `
-		INSERT INTO achievements (achievement_id, title, description, type, required_value, icon_url, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO achievements (achievement_id, title, description, type, required_value, icon_url, rule, is_enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
 		RETURNING *
 	`
 
@@ -15,10 +15,17 @@ const (
 		SELECT * FROM achievements ORDER BY created_at DESC
 	`
 
+	getEnabledAchievementsQuery = `
+		SELECT * FROM achievements
+		WHERE is_enabled AND rule IS NOT NULL
+		ORDER BY created_at
+	`
+
 	updateAchievementQuery = `
 		UPDATE achievements
-		SET title = $1, description = $2, type = $3, required_value = $4, icon_url = $5
-		WHERE achievement_id = $6
+		SET title = $1, description = $2, type = $3, required_value = $4, icon_url = $5,
+			rule = $6, is_enabled = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE achievement_id = $8
 		RETURNING *
 	`
 
@@ -45,37 +52,100 @@ const (
 			WHERE user_id = $1 AND achievement_id = $2
 		)
 	`
-)
 
-const (
-	getUserStreakQuery = `
-		SELECT streak FROM users WHERE user_id = $1
+	getUserAchievementRecordsQuery = `
+		SELECT * FROM user_achievements WHERE user_id = $1
 	`
 
-	getUserSubjectProgressQuery = `
-		SELECT * FROM user_progress WHERE user_id = $1
+	getAchievementProgressQuery = `
+		SELECT * FROM achievement_progress WHERE user_id = $1
 	`
 
-	getUserXPQuery = `
-		SELECT xp FROM users WHERE user_id = $1
+	upsertAchievementProgressQuery = `
+		INSERT INTO achievement_progress (user_id, achievement_id, current_value, required_value, percentage, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, achievement_id) DO UPDATE
+		SET current_value = EXCLUDED.current_value,
+			required_value = EXCLUDED.required_value,
+			percentage = EXCLUDED.percentage,
+			updated_at = EXCLUDED.updated_at
 	`
-)
 
-const (
-	getUserCompletedLessonsCountQuery = `
-		SELECT COUNT(*) FROM lesson_progress
-		WHERE user_id = $1 AND status = 'completed'
+	deleteAchievementProgressQuery = `
+		DELETE FROM achievement_progress WHERE achievement_id = $1
+	`
+
+	getAchievementRarityQuery = `
+		SELECT a.achievement_id,
+			COUNT(ua.user_id) AS earned_by,
+			COALESCE(COUNT(ua.user_id) * 100.0 / NULLIF((SELECT COUNT(*) FROM users), 0), 0) AS rarity
+		FROM achievements a
+		LEFT JOIN user_achievements ua ON ua.achievement_id = a.achievement_id
+		GROUP BY a.achievement_id
 	`
 )
 
+// Rule metrics, the filter parameters are optional: ” or 0 for any subject or grade, NULL for no score or time bound
 const (
-	getUserHighestQuizScoreQuery = `
-		SELECT COALESCE(MAX(score), 0) FROM user_quiz_attempts
+	getUserXPMetricQuery = `SELECT xp FROM users WHERE user_id = $1`
+
+	getUserLevelMetricQuery = `SELECT level FROM users WHERE user_id = $1`
+
+	getCurrentStreakMetricQuery = `
+		SELECT COALESCE((SELECT current_streak FROM daily_streaks WHERE user_id = $1), 0)
+	`
+
+	getMaxStreakMetricQuery = `
+		SELECT COALESCE((SELECT max_streak FROM daily_streaks WHERE user_id = $1), 0)
+	`
+
+	getXPEarnedMetricQuery = `
+		SELECT COALESCE(SUM(amount), 0) FROM xp_transactions
 		WHERE user_id = $1
+			AND ($2 = '' OR subject = $2)
+			AND ($3 = 0 OR grade = $3)
+			AND ($4::timestamptz IS NULL OR created_at >= $4)
+	`
+
+	getLessonsCompletedMetricQuery = `
+		SELECT COUNT(*) FROM lesson_progress lp
+		JOIN lessons l ON l.lesson_id = lp.lesson_id
+		WHERE lp.user_id = $1 AND lp.status = 'completed'
+			AND ($2 = '' OR l.subject = $2)
+			AND ($3 = 0 OR l.grade = $3)
+			AND ($4::timestamptz IS NULL OR lp.completed_at >= $4)
 	`
 
-	getUserQuizAttemptsCountQuery = `
-		SELECT COUNT(*) FROM user_quiz_attempts
+	quizAttemptsMetricFilter = `
+		FROM user_quiz_attempts a
+		JOIN quizzes qz ON qz.quiz_id = a.quiz_id
+		JOIN lessons l ON l.lesson_id = qz.lesson_id
+		WHERE a.user_id = $1
+			AND ($2 = '' OR l.subject = $2)
+			AND ($3 = 0 OR l.grade = $3)
+			AND ($4::int IS NULL OR a.score >= $4)
+			AND ($5::timestamptz IS NULL OR a.completed_at >= $5)
+	`
+
+	getQuizAttemptsMetricQuery = `SELECT COUNT(*)` + quizAttemptsMetricFilter
+
+	getQuizzesMetricQuery = `SELECT COUNT(DISTINCT a.quiz_id)` + quizAttemptsMetricFilter
+
+	getBestQuizScoreMetricQuery = `SELECT COALESCE(MAX(a.score), 0)` + quizAttemptsMetricFilter
+
+	getAvgQuizScoreMetricQuery = `SELECT COALESCE(AVG(a.score), 0)::float8` + quizAttemptsMetricFilter
+
+	getChaptersReadMetricQuery = `
+		SELECT COALESCE(SUM(chapters_read), 0) FROM user_progress
 		WHERE user_id = $1
+			AND ($2 = '' OR subject = $2)
+			AND ($3 = 0 OR grade = $3)
 	`
+
+	getTopSubjectChaptersReadMetricQuery = `
+		SELECT COALESCE(MAX(chapters_read), 0) FROM user_progress
+		WHERE user_id = $1 AND ($2 = 0 OR grade = $2)
+	`
+
+	listUserIDsQuery = `SELECT user_id FROM users ORDER BY created_at LIMIT $1`
 )
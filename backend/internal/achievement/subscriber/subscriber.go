@@ -0,0 +1,28 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/achievement"
+	"github.com/AleksK1NG/api-mc/internal/events"
+)
+
+const subscriberName = "achievements"
+
+// RegisterAchievementSubscribers re-evaluates a user's achievements whenever one of the metrics the rules read changes
+func RegisterAchievementSubscribers(bus events.Bus, achievementUC achievement.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		if err := achievementUC.CheckAndAwardAchievements(ctx, event.UserID); err != nil {
+			return errors.Wrap(err, "achievementSubscriber.CheckAndAwardAchievements")
+		}
+		return nil
+	},
+		events.QuizSubmittedType,
+		events.LessonCompletedType,
+		events.XPAwardedType,
+		events.LevelUpType,
+		events.StreakUpdatedType,
+	)
+}
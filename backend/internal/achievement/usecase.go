@@ -21,22 +21,14 @@ type UseCase interface {
 	GetUserAchievements(ctx context.Context, userID uuid.UUID) ([]*models.Achievement, error)
 	AwardAchievementToUser(ctx context.Context, userID uuid.UUID, achievementID uuid.UUID) error
 	CheckAndAwardAchievements(ctx context.Context, userID uuid.UUID) error
-}
-
-// UserProgressRepository provides access to user progress data
-type UserProgressRepository interface {
-	GetUserStreak(ctx context.Context, userID uuid.UUID) (int, error)
-	GetUserSubjectProgress(ctx context.Context, userID uuid.UUID) ([]*models.UserProgress, error)
-	GetUserXP(ctx context.Context, userID uuid.UUID) (int, error)
-}
+	GetUserAchievementStatus(ctx context.Context, userID uuid.UUID) ([]*models.UserAchievementStatus, error)
 
-// LessonProgressRepository provides access to lesson progress data
-type LessonProgressRepository interface {
-	GetUserCompletedLessonsCount(ctx context.Context, userID uuid.UUID) (int, error)
+	// Evaluate a rule against existing users without awarding anything
+	DryRunRule(ctx context.Context, request *models.AchievementDryRunRequest) (*models.AchievementDryRunResult, error)
 }
 
-// UserQuizAttemptsRepository provides access to quiz attempt data
-type UserQuizAttemptsRepository interface {
-	GetUserHighestQuizScore(ctx context.Context, userID uuid.UUID) (int, error)
-	GetUserQuizAttemptsCount(ctx context.Context, userID uuid.UUID) (int, error)
+// MetricsRepository reads the metrics achievement rules are evaluated on
+type MetricsRepository interface {
+	GetMetric(ctx context.Context, userID uuid.UUID, metric string, filter *models.MetricFilter) (float64, error)
+	ListUserIDs(ctx context.Context, limit int) ([]uuid.UUID, error)
 }
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/achievement"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+const (
+	maxRuleDepth      = 5
+	maxRuleWithinDays = 366
+
+	// keeps an unmet "> threshold" condition at exactly the threshold below 100%
+	maxUnmetRatio = 0.99
+)
+
+var ruleOps = map[string]func(value float64, threshold float64) bool{
+	">=": func(value float64, threshold float64) bool { return value >= threshold },
+	">":  func(value float64, threshold float64) bool { return value > threshold },
+	"<=": func(value float64, threshold float64) bool { return value <= threshold },
+	"<":  func(value float64, threshold float64) bool { return value < threshold },
+	"==": func(value float64, threshold float64) bool { return value == threshold },
+	"!=": func(value float64, threshold float64) bool { return value != threshold },
+}
+
+// metricFilters are the filters each metric accepts
+type metricFilters struct {
+	subject    bool
+	grade      bool
+	minScore   bool
+	withinDays bool
+}
+
+var ruleMetrics = map[string]metricFilters{
+	models.MetricXP:                     {},
+	models.MetricLevel:                  {},
+	models.MetricCurrentStreak:          {},
+	models.MetricMaxStreak:              {},
+	models.MetricXPEarned:               {subject: true, grade: true, withinDays: true},
+	models.MetricLessonsCompleted:       {subject: true, grade: true, withinDays: true},
+	models.MetricQuizAttempts:           {subject: true, grade: true, minScore: true, withinDays: true},
+	models.MetricQuizzes:                {subject: true, grade: true, minScore: true, withinDays: true},
+	models.MetricBestQuizScore:          {subject: true, grade: true, withinDays: true},
+	models.MetricAvgQuizScore:           {subject: true, grade: true, minScore: true, withinDays: true},
+	models.MetricChaptersRead:           {subject: true, grade: true},
+	models.MetricTopSubjectChaptersRead: {grade: true},
+}
+
+// validateRule checks the rule only uses known metrics, their filters and operators
+func validateRule(rule *models.AchievementRule) error {
+	return validateRuleNode(rule, "rule", 1)
+}
+
+func validateRuleNode(node *models.AchievementRule, path string, depth int) error {
+	if node == nil {
+		return fmt.Errorf("%s: empty rule", path)
+	}
+	if depth > maxRuleDepth {
+		return fmt.Errorf("%s: rules can be nested at most %d levels deep", path, maxRuleDepth)
+	}
+
+	kinds := 0
+	for _, set := range []bool{node.All != nil, node.Any != nil, node.Not != nil, node.Metric != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("%s: exactly one of all, any, not or metric must be set", path)
+	}
+
+	switch {
+	case node.All != nil:
+		return validateRuleNodes(node.All, path+".all", depth)
+	case node.Any != nil:
+		return validateRuleNodes(node.Any, path+".any", depth)
+	case node.Not != nil:
+		return validateRuleNode(node.Not, path+".not", depth+1)
+	}
+
+	allowed, ok := ruleMetrics[node.Metric]
+	if !ok {
+		return fmt.Errorf("%s: unknown metric %q", path, node.Metric)
+	}
+	if _, ok := ruleOps[node.Op]; !ok {
+		return fmt.Errorf("%s: unknown operator %q", path, node.Op)
+	}
+
+	filter := node.Filter
+	if filter == nil {
+		return nil
+	}
+	if filter.Subject != "" && !allowed.subject {
+		return fmt.Errorf("%s: metric %s cannot be filtered by subject", path, node.Metric)
+	}
+	if filter.Grade != 0 && !allowed.grade {
+		return fmt.Errorf("%s: metric %s cannot be filtered by grade", path, node.Metric)
+	}
+	if filter.Grade < 0 || filter.Grade > 12 {
+		return fmt.Errorf("%s: grade must be between 1 and 12", path)
+	}
+	if filter.MinScore != nil && !allowed.minScore {
+		return fmt.Errorf("%s: metric %s cannot be filtered by min_score", path, node.Metric)
+	}
+	if filter.MinScore != nil && (*filter.MinScore < 0 || *filter.MinScore > 100) {
+		return fmt.Errorf("%s: min_score must be between 0 and 100", path)
+	}
+	if filter.WithinDays != 0 && !allowed.withinDays {
+		return fmt.Errorf("%s: metric %s cannot be filtered by within_days", path, node.Metric)
+	}
+	if filter.WithinDays < 0 || filter.WithinDays > maxRuleWithinDays {
+		return fmt.Errorf("%s: within_days must be between 1 and %d", path, maxRuleWithinDays)
+	}
+
+	return nil
+}
+
+func validateRuleNodes(nodes []*models.AchievementRule, path string, depth int) error {
+	if len(nodes) == 0 {
+		return fmt.Errorf("%s: needs at least one rule", path)
+	}
+	for i, child := range nodes {
+		if err := validateRuleNode(child, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleEvaluator evaluates rules for a single user, every metric is read at most once
+type ruleEvaluator struct {
+	ctx         context.Context
+	userID      uuid.UUID
+	metricsRepo achievement.MetricsRepository
+	values      map[string]*models.MetricValue
+	read        []*models.MetricValue
+}
+
+func newRuleEvaluator(ctx context.Context, userID uuid.UUID, metricsRepo achievement.MetricsRepository) *ruleEvaluator {
+	return &ruleEvaluator{
+		ctx:         ctx,
+		userID:      userID,
+		metricsRepo: metricsRepo,
+		values:      make(map[string]*models.MetricValue),
+	}
+}
+
+// evaluate short-circuits, so only the metrics needed for the outcome are read
+func (e *ruleEvaluator) evaluate(node *models.AchievementRule) (bool, error) {
+	switch {
+	case node.All != nil:
+		for _, child := range node.All {
+			ok, err := e.evaluate(child)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case node.Any != nil:
+		for _, child := range node.Any {
+			ok, err := e.evaluate(child)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case node.Not != nil:
+		ok, err := e.evaluate(node.Not)
+		return !ok && err == nil, err
+	}
+
+	value, err := e.metric(node.Metric, node.Filter)
+	if err != nil {
+		return false, err
+	}
+	return ruleOps[node.Op](value, node.Threshold), nil
+}
+
+// progress reports how close the user is to matching the rule as current and required
+// values and a ratio between 0 and 1. A condition compares its metric to the threshold,
+// all counts the conditions met and any follows its closest condition
+func (e *ruleEvaluator) progress(node *models.AchievementRule) (float64, float64, float64, error) {
+	switch {
+	case len(node.All) == 1:
+		return e.progress(node.All[0])
+	case node.All != nil:
+		met, ratios := 0, 0.0
+		for _, child := range node.All {
+			_, _, ratio, err := e.progress(child)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			if ratio == 1 {
+				met++
+			}
+			ratios += ratio
+		}
+		return float64(met), float64(len(node.All)), ratios / float64(len(node.All)), nil
+	case node.Any != nil:
+		var bestCurrent, bestRequired, bestRatio float64
+		for i, child := range node.Any {
+			current, required, ratio, err := e.progress(child)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			if i == 0 || ratio > bestRatio {
+				bestCurrent, bestRequired, bestRatio = current, required, ratio
+			}
+		}
+		return bestCurrent, bestRequired, bestRatio, nil
+	case node.Not != nil:
+		ok, err := e.evaluate(node)
+		if err != nil || !ok {
+			return 0, 1, 0, err
+		}
+		return 1, 1, 1, nil
+	}
+
+	value, err := e.metric(node.Metric, node.Filter)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if ruleOps[node.Op](value, node.Threshold) {
+		return value, node.Threshold, 1, nil
+	}
+
+	// Only lower bounds have a meaningful partial progress
+	if (node.Op == ">=" || node.Op == ">") && node.Threshold > 0 && value > 0 {
+		return value, node.Threshold, math.Min(value/node.Threshold, maxUnmetRatio), nil
+	}
+	return value, node.Threshold, 0, nil
+}
+
+func (e *ruleEvaluator) metric(metric string, filter *models.MetricFilter) (float64, error) {
+	key := metric
+	if filter != nil {
+		data, err := json.Marshal(filter)
+		if err != nil {
+			return 0, err
+		}
+		key += string(data)
+	}
+
+	if value, ok := e.values[key]; ok {
+		return value.Value, nil
+	}
+
+	value, err := e.metricsRepo.GetMetric(e.ctx, e.userID, metric, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	metricValue := &models.MetricValue{Metric: metric, Filter: filter, Value: value}
+	e.values[key] = metricValue
+	e.read = append(e.read, metricValue)
+	return value, nil
+}
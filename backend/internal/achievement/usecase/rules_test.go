@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// fakeMetrics serves metric values by metric name and counts the reads
+type fakeMetrics struct {
+	values map[string]float64
+	reads  map[string]int
+	err    error
+}
+
+func (f *fakeMetrics) GetMetric(_ context.Context, _ uuid.UUID, metric string, _ *models.MetricFilter) (float64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.reads[metric]++
+	return f.values[metric], nil
+}
+
+func (f *fakeMetrics) ListUserIDs(context.Context, int) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func newFakeMetrics(values map[string]float64) *fakeMetrics {
+	return &fakeMetrics{values: values, reads: make(map[string]int)}
+}
+
+func parseRule(t *testing.T, data string) *models.AchievementRule {
+	t.Helper()
+	rule := &models.AchievementRule{}
+	if err := json.Unmarshal([]byte(data), rule); err != nil {
+		t.Fatalf("parse rule %s: %v", data, err)
+	}
+	return rule
+}
+
+func TestValidateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr string // substring of the error, empty when the rule is valid
+	}{
+		{name: "single condition", rule: `{"metric": "xp", "op": ">=", "value": 100}`},
+		{
+			name: "nested operators with filters",
+			rule: `{"all": [{"metric": "quizzes", "filter": {"subject": "math", "min_score": 90, "within_days": 7}, "op": ">=", "value": 5},
+				{"any": [{"metric": "current_streak", "op": ">", "value": 6}, {"not": {"metric": "level", "op": "<", "value": 3}}]}]}`,
+		},
+		{name: "empty node", rule: `{}`, wantErr: "rule: exactly one of all, any, not or metric must be set"},
+		{
+			name:    "two kinds in one node",
+			rule:    `{"metric": "xp", "op": ">=", "value": 1, "not": {"metric": "xp", "op": ">=", "value": 2}}`,
+			wantErr: "exactly one of",
+		},
+		{name: "empty all", rule: `{"all": []}`, wantErr: "rule.all: needs at least one rule"},
+		{name: "unknown metric", rule: `{"metric": "karma", "op": ">=", "value": 1}`, wantErr: `unknown metric "karma"`},
+		{name: "unknown operator", rule: `{"metric": "xp", "op": "=>", "value": 1}`, wantErr: `unknown operator "=>"`},
+		{
+			name:    "error path points at the nested node",
+			rule:    `{"all": [{"metric": "xp", "op": ">=", "value": 1}, {"any": [{"metric": "xp", "op": "~", "value": 1}]}]}`,
+			wantErr: "rule.all[1].any[0]: unknown operator",
+		},
+		{
+			name:    "filter the metric does not accept",
+			rule:    `{"metric": "xp", "filter": {"subject": "math"}, "op": ">=", "value": 1}`,
+			wantErr: "metric xp cannot be filtered by subject",
+		},
+		{
+			name:    "min score on a metric without scores",
+			rule:    `{"metric": "lessons_completed", "filter": {"min_score": 50}, "op": ">=", "value": 1}`,
+			wantErr: "cannot be filtered by min_score",
+		},
+		{
+			name:    "min score out of range",
+			rule:    `{"metric": "quizzes", "filter": {"min_score": 101}, "op": ">=", "value": 1}`,
+			wantErr: "min_score must be between 0 and 100",
+		},
+		{
+			name:    "grade out of range",
+			rule:    `{"metric": "quizzes", "filter": {"grade": 13}, "op": ">=", "value": 1}`,
+			wantErr: "grade must be between 1 and 12",
+		},
+		{
+			name:    "within days too long",
+			rule:    `{"metric": "xp_earned", "filter": {"within_days": 367}, "op": ">=", "value": 1}`,
+			wantErr: "within_days must be between 1 and 366",
+		},
+		{
+			name: "nested at the maximum depth",
+			rule: `{"not": {"not": {"not": {"not": {"metric": "xp", "op": ">=", "value": 1}}}}}`,
+		},
+		{
+			name:    "nested past the maximum depth",
+			rule:    `{"all": [{"any": [{"not": {"all": [{"any": [{"metric": "xp", "op": ">=", "value": 1}]}]}}]}]}`,
+			wantErr: "nested at most 5 levels deep",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRule(parseRule(t, tt.rule))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateRule: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateRule error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleEvaluate(t *testing.T) {
+	values := map[string]float64{
+		models.MetricXP:            250,
+		models.MetricLevel:         3,
+		models.MetricCurrentStreak: 7,
+		models.MetricQuizzes:       4,
+	}
+
+	tests := []struct {
+		name      string
+		rule      string
+		want      bool
+		wantReads []string // metrics read, anything else must be short-circuited
+	}{
+		{name: "at the threshold", rule: `{"metric": "xp", "op": ">=", "value": 250}`, want: true},
+		{name: "strictly above the threshold", rule: `{"metric": "xp", "op": ">", "value": 250}`, want: false},
+		{name: "equal", rule: `{"metric": "level", "op": "==", "value": 3}`, want: true},
+		{name: "not equal", rule: `{"metric": "level", "op": "!=", "value": 3}`, want: false},
+		{name: "upper bound", rule: `{"metric": "quizzes", "op": "<=", "value": 4}`, want: true},
+		{
+			name: "all met",
+			rule: `{"all": [{"metric": "xp", "op": ">=", "value": 100}, {"metric": "current_streak", "op": ">=", "value": 7}]}`,
+			want: true,
+		},
+		{
+			name:      "all stops at the first unmet condition",
+			rule:      `{"all": [{"metric": "xp", "op": ">=", "value": 1000}, {"metric": "current_streak", "op": ">=", "value": 7}]}`,
+			want:      false,
+			wantReads: []string{models.MetricXP},
+		},
+		{
+			name:      "any stops at the first met condition",
+			rule:      `{"any": [{"metric": "xp", "op": ">=", "value": 100}, {"metric": "current_streak", "op": ">=", "value": 100}]}`,
+			want:      true,
+			wantReads: []string{models.MetricXP},
+		},
+		{
+			name: "any with nothing met",
+			rule: `{"any": [{"metric": "xp", "op": ">=", "value": 1000}, {"metric": "current_streak", "op": ">=", "value": 100}]}`,
+			want: false,
+		},
+		{name: "not", rule: `{"not": {"metric": "level", "op": ">=", "value": 5}}`, want: true},
+		{name: "double not", rule: `{"not": {"not": {"metric": "level", "op": ">=", "value": 5}}}`, want: false},
+		{
+			name: "not inside all inside any",
+			rule: `{"any": [{"metric": "quizzes", "op": ">=", "value": 10},
+				{"all": [{"metric": "xp", "op": ">=", "value": 200}, {"not": {"metric": "level", "op": ">", "value": 3}}]}]}`,
+			want: true,
+		},
+		{
+			name:      "a metric used twice is read once",
+			rule:      `{"all": [{"metric": "xp", "op": ">=", "value": 100}, {"metric": "xp", "op": "<", "value": 300}]}`,
+			want:      true,
+			wantReads: []string{models.MetricXP},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := newFakeMetrics(values)
+			evaluator := newRuleEvaluator(context.Background(), uuid.New(), metrics)
+
+			got, err := evaluator.evaluate(parseRule(t, tt.rule))
+			if err != nil {
+				t.Fatalf("evaluate: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate = %v, want %v", got, tt.want)
+			}
+
+			if tt.wantReads != nil {
+				if len(metrics.reads) != len(tt.wantReads) {
+					t.Fatalf("read metrics %v, want %v", metrics.reads, tt.wantReads)
+				}
+				for _, metric := range tt.wantReads {
+					if metrics.reads[metric] != 1 {
+						t.Errorf("metric %s read %d times, want once", metric, metrics.reads[metric])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEvaluateError(t *testing.T) {
+	repoErr := errors.New("connection reset")
+	metrics := newFakeMetrics(nil)
+	metrics.err = repoErr
+	evaluator := newRuleEvaluator(context.Background(), uuid.New(), metrics)
+
+	// A failing metric must not read as a met negated condition
+	ok, err := evaluator.evaluate(parseRule(t, `{"not": {"metric": "xp", "op": ">=", "value": 1}}`))
+	if !errors.Is(err, repoErr) || ok {
+		t.Fatalf("evaluate = %v, %v, want false and the repository error", ok, err)
+	}
+}
+
+func TestRuleProgress(t *testing.T) {
+	values := map[string]float64{
+		models.MetricXP:            50,
+		models.MetricCurrentStreak: 7,
+		models.MetricLevel:         2,
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		current  float64
+		required float64
+		ratio    float64
+	}{
+		{name: "met", rule: `{"metric": "current_streak", "op": ">=", "value": 7}`, current: 7, required: 7, ratio: 1},
+		{name: "halfway", rule: `{"metric": "xp", "op": ">=", "value": 100}`, current: 50, required: 100, ratio: 0.5},
+		{name: "unmet at the threshold stays below 100%", rule: `{"metric": "xp", "op": ">", "value": 50}`, current: 50, required: 50, ratio: 0.99},
+		{name: "upper bound has no partial progress", rule: `{"metric": "xp", "op": "<", "value": 10}`, current: 50, required: 10, ratio: 0},
+		{name: "single child all unwraps", rule: `{"all": [{"metric": "xp", "op": ">=", "value": 100}]}`, current: 50, required: 100, ratio: 0.5},
+		{
+			name:     "all counts the conditions met",
+			rule:     `{"all": [{"metric": "current_streak", "op": ">=", "value": 7}, {"metric": "xp", "op": ">=", "value": 100}]}`,
+			current:  1,
+			required: 2,
+			ratio:    0.75,
+		},
+		{
+			name:     "any follows the closest condition",
+			rule:     `{"any": [{"metric": "xp", "op": ">=", "value": 200}, {"metric": "current_streak", "op": ">=", "value": 14}]}`,
+			current:  7,
+			required: 14,
+			ratio:    0.5,
+		},
+		{name: "met not", rule: `{"not": {"metric": "level", "op": ">=", "value": 5}}`, current: 1, required: 1, ratio: 1},
+		{name: "unmet not", rule: `{"not": {"metric": "level", "op": ">=", "value": 2}}`, current: 0, required: 1, ratio: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator := newRuleEvaluator(context.Background(), uuid.New(), newFakeMetrics(values))
+			current, required, ratio, err := evaluator.progress(parseRule(t, tt.rule))
+			if err != nil {
+				t.Fatalf("progress: %v", err)
+			}
+			if current != tt.current || required != tt.required || ratio != tt.ratio {
+				t.Errorf("progress = %v of %v (%v), want %v of %v (%v)", current, required, ratio, tt.current, tt.required, tt.ratio)
+			}
+		})
+	}
+}
@@ -12,32 +12,36 @@ import (
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
+const (
+	defaultDryRunLimit  = 200
+	maxDryRunSampleSize = 50
+)
+
 type achievementUC struct {
 	achievementRepo achievement.Repository
 	logger          logger.Logger
 
-	userProgressRepo     achievement.UserProgressRepository
-	lessonProgressRepo   achievement.LessonProgressRepository
-	userQuizAttemptsRepo achievement.UserQuizAttemptsRepository
+	metricsRepo achievement.MetricsRepository
 }
 
 func NewAchievementUseCase(
 	achievementRepo achievement.Repository,
-	userProgressRepo achievement.UserProgressRepository,
-	lessonProgressRepo achievement.LessonProgressRepository,
-	userQuizAttemptsRepo achievement.UserQuizAttemptsRepository,
+	metricsRepo achievement.MetricsRepository,
 	logger logger.Logger,
 ) achievement.UseCase {
 	return &achievementUC{
-		achievementRepo:      achievementRepo,
-		userProgressRepo:     userProgressRepo,
-		lessonProgressRepo:   lessonProgressRepo,
-		userQuizAttemptsRepo: userQuizAttemptsRepo,
-		logger:               logger,
+		achievementRepo: achievementRepo,
+		metricsRepo:     metricsRepo,
+		logger:          logger,
 	}
 }
 
 func (u *achievementUC) CreateAchievement(ctx context.Context, achievement *models.Achievement) (*models.Achievement, error) {
+	if achievement.Rule != nil {
+		if err := validateRule(achievement.Rule); err != nil {
+			return nil, errors.Wrap(err, "achievementUC.CreateAchievement.validateRule")
+		}
+	}
 	return u.achievementRepo.CreateAchievement(ctx, achievement)
 }
 
@@ -50,7 +54,22 @@ func (u *achievementUC) GetAllAchievements(ctx context.Context) ([]*models.Achie
 }
 
 func (u *achievementUC) UpdateAchievement(ctx context.Context, achievement *models.Achievement) (*models.Achievement, error) {
-	return u.achievementRepo.UpdateAchievement(ctx, achievement)
+	if achievement.Rule != nil {
+		if err := validateRule(achievement.Rule); err != nil {
+			return nil, errors.Wrap(err, "achievementUC.UpdateAchievement.validateRule")
+		}
+	}
+	updatedAchievement, err := u.achievementRepo.UpdateAchievement(ctx, achievement)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached progress was computed for the previous rule
+	if err := u.achievementRepo.DeleteAchievementProgress(ctx, achievement.AchievementID); err != nil {
+		return nil, errors.Wrap(err, "achievementUC.UpdateAchievement.DeleteAchievementProgress")
+	}
+
+	return updatedAchievement, nil
 }
 
 func (u *achievementUC) DeleteAchievement(ctx context.Context, achievementID uuid.UUID) error {
@@ -84,110 +103,215 @@ func (u *achievementUC) AwardAchievementToUser(ctx context.Context, userID uuid.
 
 func (u *achievementUC) CheckAndAwardAchievements(ctx context.Context, userID uuid.UUID) error {
 
-	achievements, err := u.achievementRepo.GetAllAchievements(ctx)
+	achievements, err := u.achievementRepo.GetEnabledAchievements(ctx)
 	if err != nil {
-		return errors.Wrap(err, "achievementUC.CheckAndAwardAchievements.GetAllAchievements")
+		return errors.Wrap(err, "achievementUC.CheckAndAwardAchievements.GetEnabledAchievements")
 	}
 
+	earned, err := u.earnedAchievements(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "achievementUC.CheckAndAwardAchievements.earnedAchievements")
+	}
+
+	// Shared by all achievements so every metric is read once
+	evaluator := newRuleEvaluator(ctx, userID, u.metricsRepo)
+	progress := make([]*models.AchievementProgress, 0, len(achievements))
 	for _, achievement := range achievements {
+		if _, ok := earned[achievement.AchievementID]; ok {
+			continue
+		}
+
+		achievementProgress, err := achievementProgress(evaluator, userID, achievement)
+		if err != nil {
+			u.logger.Errorf("Error evaluating achievement %s rule: %v", achievement.AchievementID, err)
+			continue
+		}
 
-		hasAchievement, err := u.achievementRepo.CheckUserHasAchievement(ctx, userID, achievement.AchievementID)
+		matched, err := evaluator.evaluate(achievement.Rule)
 		if err != nil {
-			u.logger.Errorf("Error checking if user has achievement: %v", err)
+			u.logger.Errorf("Error evaluating achievement %s rule: %v", achievement.AchievementID, err)
 			continue
 		}
-		if hasAchievement {
+		if !matched {
+			progress = append(progress, achievementProgress)
 			continue
 		}
 
-		switch achievement.Type {
-		case "streak":
+		if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
+			u.logger.Errorf("Error awarding achievement %s: %v", achievement.AchievementID, err)
+		}
+	}
 
-			streak, err := u.userProgressRepo.GetUserStreak(ctx, userID)
-			if err != nil {
-				u.logger.Errorf("Error getting user streak: %v", err)
-				continue
-			}
-			if streak >= achievement.RequiredValue {
-				if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
-					u.logger.Errorf("Error awarding streak achievement: %v", err)
-				}
-			}
+	if err := u.achievementRepo.UpsertAchievementProgress(ctx, progress); err != nil {
+		return errors.Wrap(err, "achievementUC.CheckAndAwardAchievements.UpsertAchievementProgress")
+	}
 
-		case "quiz_score":
+	return nil
+}
 
-			highScore, err := u.userQuizAttemptsRepo.GetUserHighestQuizScore(ctx, userID)
-			if err != nil {
-				u.logger.Errorf("Error getting user quiz scores: %v", err)
-				continue
-			}
-			if highScore >= achievement.RequiredValue {
-				if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
-					u.logger.Errorf("Error awarding quiz score achievement: %v", err)
-				}
-			}
+func (u *achievementUC) GetUserAchievementStatus(ctx context.Context, userID uuid.UUID) ([]*models.UserAchievementStatus, error) {
 
-		case "subject_mastery":
+	achievements, err := u.achievementRepo.GetAllAchievements(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "achievementUC.GetUserAchievementStatus.GetAllAchievements")
+	}
 
-			subjectProgress, err := u.userProgressRepo.GetUserSubjectProgress(ctx, userID)
-			if err != nil {
-				u.logger.Errorf("Error getting user subject progress: %v", err)
-				continue
-			}
+	earned, err := u.earnedAchievements(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "achievementUC.GetUserAchievementStatus.earnedAchievements")
+	}
 
-			for _, progress := range subjectProgress {
-				if progress.ChaptersRead >= achievement.RequiredValue {
-					if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
-						u.logger.Errorf("Error awarding subject mastery achievement: %v", err)
-					}
-					break
-				}
-			}
+	cachedProgress, err := u.achievementRepo.GetAchievementProgress(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "achievementUC.GetUserAchievementStatus.GetAchievementProgress")
+	}
+	progressByID := make(map[uuid.UUID]*models.AchievementProgress, len(cachedProgress))
+	for _, progress := range cachedProgress {
+		progressByID[progress.AchievementID] = progress
+	}
 
-		case "lessons_completed":
+	rarity, err := u.achievementRepo.GetAchievementRarity(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "achievementUC.GetUserAchievementStatus.GetAchievementRarity")
+	}
+	rarityByID := make(map[uuid.UUID]*models.AchievementRarity, len(rarity))
+	for _, r := range rarity {
+		rarityByID[r.AchievementID] = r
+	}
 
-			completedLessons, err := u.lessonProgressRepo.GetUserCompletedLessonsCount(ctx, userID)
-			if err != nil {
-				u.logger.Errorf("Error getting user completed lessons: %v", err)
-				continue
-			}
-			if completedLessons >= achievement.RequiredValue {
-				if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
-					u.logger.Errorf("Error awarding lessons completed achievement: %v", err)
-				}
-			}
+	// Progress missing from the cache is computed once and stored
+	var evaluator *ruleEvaluator
+	computed := make([]*models.AchievementProgress, 0)
 
-		case "quizzes_taken":
+	statuses := make([]*models.UserAchievementStatus, 0, len(achievements))
+	for _, achievement := range achievements {
+		record, isEarned := earned[achievement.AchievementID]
+		if !isEarned && !achievement.IsEnabled {
+			continue
+		}
 
-			quizzesTaken, err := u.userQuizAttemptsRepo.GetUserQuizAttemptsCount(ctx, userID)
-			if err != nil {
-				u.logger.Errorf("Error getting user quiz attempts: %v", err)
-				continue
-			}
-			if quizzesTaken >= achievement.RequiredValue {
-				if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
-					u.logger.Errorf("Error awarding quizzes taken achievement: %v", err)
+		status := &models.UserAchievementStatus{Achievement: achievement, Earned: isEarned}
+		if isEarned {
+			status.EarnedAt = &record.EarnedAt
+		}
+		if r, ok := rarityByID[achievement.AchievementID]; ok {
+			status.EarnedBy = r.EarnedBy
+			status.Rarity = r.Rarity
+		}
+
+		if !isEarned && achievement.Rule != nil {
+			progress, ok := progressByID[achievement.AchievementID]
+			if !ok {
+				if evaluator == nil {
+					evaluator = newRuleEvaluator(ctx, userID, u.metricsRepo)
+				}
+				if progress, err = achievementProgress(evaluator, userID, achievement); err != nil {
+					return nil, errors.Wrap(err, "achievementUC.GetUserAchievementStatus.achievementProgress")
 				}
+				computed = append(computed, progress)
 			}
+			status.Progress = progress
+		}
 
-		case "xp_earned":
+		statuses = append(statuses, status)
+	}
 
-			xp, err := u.userProgressRepo.GetUserXP(ctx, userID)
-			if err != nil {
-				u.logger.Errorf("Error getting user XP: %v", err)
-				continue
-			}
-			if xp >= achievement.RequiredValue {
-				if err := u.AwardAchievementToUser(ctx, userID, achievement.AchievementID); err != nil {
-					u.logger.Errorf("Error awarding XP achievement: %v", err)
-				}
-			}
+	if err := u.achievementRepo.UpsertAchievementProgress(ctx, computed); err != nil {
+		u.logger.Errorf("Error caching achievement progress: %v", err)
+	}
+
+	return statuses, nil
+}
+
+func (u *achievementUC) earnedAchievements(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]*models.UserAchievement, error) {
+	records, err := u.achievementRepo.GetUserAchievementRecords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	earned := make(map[uuid.UUID]*models.UserAchievement, len(records))
+	for _, record := range records {
+		earned[record.AchievementID] = record
+	}
+	return earned, nil
+}
+
+func achievementProgress(evaluator *ruleEvaluator, userID uuid.UUID, achievement *models.Achievement) (*models.AchievementProgress, error) {
+	current, required, ratio, err := evaluator.progress(achievement.Rule)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AchievementProgress{
+		UserID:        userID,
+		AchievementID: achievement.AchievementID,
+		CurrentValue:  current,
+		RequiredValue: required,
+		Percentage:    int(ratio * 100),
+		UpdatedAt:     time.Now(),
+	}, nil
+}
+
+func (u *achievementUC) DryRunRule(ctx context.Context, request *models.AchievementDryRunRequest) (*models.AchievementDryRunResult, error) {
+	rule := request.Rule
+	if request.AchievementID != nil {
+		stored, err := u.achievementRepo.GetAchievementByID(ctx, *request.AchievementID)
+		if err != nil {
+			return nil, errors.Wrap(err, "achievementUC.DryRunRule.GetAchievementByID")
+		}
+		if rule == nil {
+			rule = stored.Rule
+		}
+	}
+	if rule == nil {
+		return nil, errors.New("a rule or an achievement with a rule is required")
+	}
+	if err := validateRule(rule); err != nil {
+		return nil, errors.Wrap(err, "achievementUC.DryRunRule.validateRule")
+	}
 
-		case "custom":
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultDryRunLimit
+	}
+	userIDs, err := u.metricsRepo.ListUserIDs(ctx, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "achievementUC.DryRunRule.ListUserIDs")
+	}
 
+	result := &models.AchievementDryRunResult{Matches: make([]*models.AchievementDryRunMatch, 0)}
+	for _, userID := range userIDs {
+		evaluator := newRuleEvaluator(ctx, userID, u.metricsRepo)
+		matched, err := evaluator.evaluate(rule)
+		if err != nil {
+			return nil, errors.Wrap(err, "achievementUC.DryRunRule.evaluate")
+		}
+		result.Evaluated++
+		if !matched {
 			continue
 		}
+		result.Matched++
+
+		alreadyEarned := false
+		if request.AchievementID != nil {
+			if alreadyEarned, err = u.achievementRepo.CheckUserHasAchievement(ctx, userID, *request.AchievementID); err != nil {
+				return nil, errors.Wrap(err, "achievementUC.DryRunRule.CheckUserHasAchievement")
+			}
+		}
+		if !alreadyEarned {
+			result.NewAwards++
+		}
+
+		if len(result.Matches) < maxDryRunSampleSize {
+			result.Matches = append(result.Matches, &models.AchievementDryRunMatch{
+				UserID:        userID,
+				AlreadyEarned: alreadyEarned,
+				Values:        evaluator.read,
+			})
+		}
 	}
 
-	return nil
+	if result.Evaluated > 0 {
+		result.MatchRatio = float64(result.Matched) / float64(result.Evaluated)
+	}
+
+	return result, nil
 }
@@ -0,0 +1,11 @@
+package analytics
+
+import "github.com/labstack/echo/v4"
+
+// Analytics HTTP Handlers interface
+type Handlers interface {
+	// Item analytics (Admin)
+	ListQuestionStats() echo.HandlerFunc
+	GetQuestionStats() echo.HandlerFunc
+	ComputeItemAnalytics() echo.HandlerFunc
+}
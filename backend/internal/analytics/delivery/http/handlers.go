@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/analytics"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type analyticsHandlers struct {
+	analyticsUC analytics.UseCase
+	logger      logger.Logger
+}
+
+func NewAnalyticsHandlers(analyticsUC analytics.UseCase, logger logger.Logger) analytics.Handlers {
+	return &analyticsHandlers{
+		analyticsUC: analyticsUC,
+		logger:      logger,
+	}
+}
+
+// ListQuestionStats godoc
+// @Summary List item analytics
+// @Description List computed question statistics, most suspicious items first
+// @Tags Analytics
+// @Produce json
+// @Param quiz_id query string false "Filter by quiz"
+// @Param flag query string false "Filter by flag"
+// @Param flagged query bool false "Only flagged items"
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.QuestionStatsList
+// @Router /analytics/admin/questions [get]
+func (h *analyticsHandlers) ListQuestionStats() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter := &models.QuestionStatsFilter{
+			Flag: c.QueryParam("flag"),
+		}
+
+		if quizIDParam := c.QueryParam("quiz_id"); quizIDParam != "" {
+			quizID, err := uuid.Parse(quizIDParam)
+			if err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "analyticsHandlers.ListQuestionStats.Parse"))
+			}
+			filter.QuizID = &quizID
+		}
+
+		if flaggedParam := c.QueryParam("flagged"); flaggedParam != "" {
+			flagged, err := strconv.ParseBool(flaggedParam)
+			if err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "analyticsHandlers.ListQuestionStats.ParseBool"))
+			}
+			filter.FlaggedOnly = flagged
+		}
+
+		if limitParam := c.QueryParam("limit"); limitParam != "" {
+			limit, err := strconv.Atoi(limitParam)
+			if err != nil || limit < 0 {
+				return httpErrors.NewBadRequestError(errors.New("analyticsHandlers.ListQuestionStats: invalid limit"))
+			}
+			filter.Limit = limit
+		}
+
+		if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+			offset, err := strconv.Atoi(offsetParam)
+			if err != nil || offset < 0 {
+				return httpErrors.NewBadRequestError(errors.New("analyticsHandlers.ListQuestionStats: invalid offset"))
+			}
+			filter.Offset = offset
+		}
+
+		stats, err := h.analyticsUC.ListQuestionStats(c.Request().Context(), filter)
+		if err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "analyticsHandlers.ListQuestionStats.ListQuestionStats"))
+		}
+
+		return c.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetQuestionStats godoc
+// @Summary Get item analytics for a question
+// @Tags Analytics
+// @Produce json
+// @Param question_id path string true "Question ID"
+// @Success 200 {object} models.QuestionStats
+// @Router /analytics/admin/questions/{question_id} [get]
+func (h *analyticsHandlers) GetQuestionStats() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("question_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "analyticsHandlers.GetQuestionStats.Parse"))
+		}
+
+		stats, err := h.analyticsUC.GetQuestionStats(c.Request().Context(), questionID)
+		if err != nil {
+			return httpErrors.NewNotFoundError(errors.Wrap(err, "analyticsHandlers.GetQuestionStats.GetQuestionStats"))
+		}
+
+		return c.JSON(http.StatusOK, stats)
+	}
+}
+
+// ComputeItemAnalytics godoc
+// @Summary Recompute item analytics
+// @Description Run the item analysis now instead of waiting for the background job
+// @Tags Analytics
+// @Produce json
+// @Success 200 {object} models.ItemAnalyticsReport
+// @Router /analytics/admin/recompute [post]
+func (h *analyticsHandlers) ComputeItemAnalytics() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report, err := h.analyticsUC.ComputeItemAnalytics(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "analyticsHandlers.ComputeItemAnalytics.ComputeItemAnalytics"))
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
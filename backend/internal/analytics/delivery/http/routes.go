@@ -0,0 +1,23 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/analytics"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Map analytics routes
+func MapAnalyticsRoutes(analyticsGroup *echo.Group, h analytics.Handlers, mw *middleware.MiddlewareManager) {
+	protected := analyticsGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		admin := protected.Group("/admin")
+		{
+			admin.GET("/questions", h.ListQuestionStats(), mw.RequirePermission(models.PermissionAnalyticsRead))
+			admin.GET("/questions/:question_id", h.GetQuestionStats(), mw.RequirePermission(models.PermissionAnalyticsRead))
+			admin.POST("/recompute", h.ComputeItemAnalytics(), mw.RequirePermission(models.PermissionJobsRun))
+		}
+	}
+}
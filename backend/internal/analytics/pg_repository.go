@@ -0,0 +1,24 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Analytics Repository interface
+type Repository interface {
+	// Aggregation
+	GetQuestionAggregates(ctx context.Context) ([]*models.QuestionAggregate, error)
+	GetTopWrongAnswers(ctx context.Context) ([]*models.WrongAnswerCount, error)
+
+	// Stored results
+	UpsertQuestionStats(ctx context.Context, stats *models.QuestionStats) error
+	GetQuestionStats(ctx context.Context, questionID uuid.UUID) (*models.QuestionStats, error)
+	ListQuestionStats(ctx context.Context, filter *models.QuestionStatsFilter) (*models.QuestionStatsList, error)
+
+	// Calibration
+	UpdateQuestionDifficulty(ctx context.Context, questionID uuid.UUID, difficulty string) error
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/analytics"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultStatsLimit = 50
+
+type analyticsRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewAnalyticsRepository(db *sqlx.DB, logger logger.Logger) analytics.Repository {
+	return &analyticsRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *analyticsRepo) GetQuestionAggregates(ctx context.Context) ([]*models.QuestionAggregate, error) {
+	rows, err := r.db.QueryxContext(ctx, getQuestionAggregatesQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.GetQuestionAggregates.QueryxContext")
+	}
+	defer rows.Close()
+
+	aggregates := make([]*models.QuestionAggregate, 0)
+	for rows.Next() {
+		var options pq.StringArray
+		aggregate := &models.QuestionAggregate{}
+		if err := rows.Scan(
+			&aggregate.QuestionID,
+			&aggregate.QuestionType,
+			&options,
+			&aggregate.Answer,
+			&aggregate.Difficulty,
+			&aggregate.Responses,
+			&aggregate.Correct,
+			&aggregate.Discrimination,
+			&aggregate.AvgTimeSpent,
+		); err != nil {
+			return nil, errors.Wrap(err, "analyticsRepo.GetQuestionAggregates.Scan")
+		}
+		aggregate.Options = []string(options)
+		aggregates = append(aggregates, aggregate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.GetQuestionAggregates.rows.Err")
+	}
+
+	return aggregates, nil
+}
+
+func (r *analyticsRepo) GetTopWrongAnswers(ctx context.Context) ([]*models.WrongAnswerCount, error) {
+	answers := make([]*models.WrongAnswerCount, 0)
+	if err := r.db.SelectContext(ctx, &answers, getTopWrongAnswersQuery); err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.GetTopWrongAnswers.SelectContext")
+	}
+	return answers, nil
+}
+
+func (r *analyticsRepo) UpsertQuestionStats(ctx context.Context, stats *models.QuestionStats) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		upsertQuestionStatsQuery,
+		stats.QuestionID,
+		stats.Responses,
+		stats.Correct,
+		stats.PValue,
+		stats.Discrimination,
+		stats.MostCommonWrongAnswer,
+		stats.WrongAnswerCount,
+		stats.AvgTimeSpent,
+		pq.Array(stats.Flags),
+		stats.Difficulty,
+		stats.SuggestedDifficulty,
+		stats.ComputedAt,
+	); err != nil {
+		return errors.Wrap(err, "analyticsRepo.UpsertQuestionStats.ExecContext")
+	}
+	return nil
+}
+
+func (r *analyticsRepo) GetQuestionStats(ctx context.Context, questionID uuid.UUID) (*models.QuestionStats, error) {
+	stats, err := scanQuestionStats(r.db.QueryRowxContext(ctx, getQuestionStatsQuery, questionID))
+	if err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.GetQuestionStats.Scan")
+	}
+	return stats, nil
+}
+
+func (r *analyticsRepo) ListQuestionStats(ctx context.Context, filter *models.QuestionStatsFilter) (*models.QuestionStatsList, error) {
+	limit := defaultStatsLimit
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countQuestionStatsQuery, filter.QuizID, filter.Flag, filter.FlaggedOnly); err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.ListQuestionStats.GetContext")
+	}
+
+	rows, err := r.db.QueryxContext(ctx, listQuestionStatsQuery, filter.QuizID, filter.Flag, filter.FlaggedOnly, limit, filter.Offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.ListQuestionStats.QueryxContext")
+	}
+	defer rows.Close()
+
+	list := &models.QuestionStatsList{
+		TotalCount: totalCount,
+		Stats:      make([]*models.QuestionStats, 0, limit),
+	}
+	for rows.Next() {
+		stats, err := scanQuestionStats(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "analyticsRepo.ListQuestionStats.Scan")
+		}
+		list.Stats = append(list.Stats, stats)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "analyticsRepo.ListQuestionStats.rows.Err")
+	}
+
+	return list, nil
+}
+
+func (r *analyticsRepo) UpdateQuestionDifficulty(ctx context.Context, questionID uuid.UUID, difficulty string) error {
+	if _, err := r.db.ExecContext(ctx, updateQuestionDifficultyQuery, difficulty, questionID); err != nil {
+		return errors.Wrap(err, "analyticsRepo.UpdateQuestionDifficulty.ExecContext")
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanQuestionStats(row rowScanner) (*models.QuestionStats, error) {
+	var flags pq.StringArray
+	stats := &models.QuestionStats{}
+	if err := row.Scan(
+		&stats.QuestionID,
+		&stats.Responses,
+		&stats.Correct,
+		&stats.PValue,
+		&stats.Discrimination,
+		&stats.MostCommonWrongAnswer,
+		&stats.WrongAnswerCount,
+		&stats.AvgTimeSpent,
+		&flags,
+		&stats.Difficulty,
+		&stats.SuggestedDifficulty,
+		&stats.ComputedAt,
+	); err != nil {
+		return nil, err
+	}
+	stats.Flags = []string(flags)
+	return stats, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+const (
+	// Discrimination is the point-biserial correlation between getting the item right and the attempt score
+	getQuestionAggregatesQuery = `
+		SELECT q.question_id, q.question_type, q.options, q.answer, q.difficulty,
+			COUNT(r.response_id) AS responses,
+			COUNT(r.response_id) FILTER (WHERE r.is_correct) AS correct,
+			CORR(r.is_correct::int::float8, a.score::float8) AS discrimination,
+			AVG(r.time_spent)::float8 AS avg_time_spent
+		FROM questions q
+		LEFT JOIN user_question_responses r ON r.question_id = q.question_id
+		LEFT JOIN user_quiz_attempts a ON a.attempt_id = r.attempt_id
+		GROUP BY q.question_id
+	`
+
+	getTopWrongAnswersQuery = `
+		SELECT DISTINCT ON (question_id) question_id, user_answer, COUNT(*) AS count
+		FROM user_question_responses
+		WHERE NOT is_correct
+		GROUP BY question_id, user_answer
+		ORDER BY question_id, count DESC, user_answer
+	`
+
+	upsertQuestionStatsQuery = `
+		INSERT INTO question_stats (question_id, responses, correct, p_value, discrimination,
+			most_common_wrong_answer, wrong_answer_count, avg_time_spent, flags, difficulty, suggested_difficulty, computed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (question_id) DO UPDATE SET
+			responses = EXCLUDED.responses,
+			correct = EXCLUDED.correct,
+			p_value = EXCLUDED.p_value,
+			discrimination = EXCLUDED.discrimination,
+			most_common_wrong_answer = EXCLUDED.most_common_wrong_answer,
+			wrong_answer_count = EXCLUDED.wrong_answer_count,
+			avg_time_spent = EXCLUDED.avg_time_spent,
+			flags = EXCLUDED.flags,
+			difficulty = EXCLUDED.difficulty,
+			suggested_difficulty = EXCLUDED.suggested_difficulty,
+			computed_at = EXCLUDED.computed_at
+	`
+
+	questionStatsColumns = `
+		question_id, responses, correct, p_value, discrimination, most_common_wrong_answer,
+		wrong_answer_count, avg_time_spent, flags, difficulty, suggested_difficulty, computed_at
+	`
+
+	getQuestionStatsQuery = `SELECT` + questionStatsColumns + `FROM question_stats WHERE question_id = $1`
+
+	questionStatsFilter = `
+		WHERE ($1::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM quiz_questions qq
+			WHERE qq.question_id = question_stats.question_id AND qq.quiz_id = $1
+		))
+			AND ($2 = '' OR $2 = ANY(flags))
+			AND (NOT $3 OR cardinality(flags) > 0)
+	`
+
+	listQuestionStatsQuery = `SELECT` + questionStatsColumns + `FROM question_stats` + questionStatsFilter + `
+		ORDER BY cardinality(flags) DESC, p_value ASC, responses DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	countQuestionStatsQuery = `SELECT COUNT(*) FROM question_stats` + questionStatsFilter
+
+	updateQuestionDifficultyQuery = `
+		UPDATE questions SET difficulty = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE question_id = $2
+	`
+)
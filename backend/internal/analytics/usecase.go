@@ -0,0 +1,18 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Analytics UseCase interface
+type UseCase interface {
+	// Run the item analysis over all responses and recalibrate difficulties
+	ComputeItemAnalytics(ctx context.Context) (*models.ItemAnalyticsReport, error)
+
+	GetQuestionStats(ctx context.Context, questionID uuid.UUID) (*models.QuestionStats, error)
+	ListQuestionStats(ctx context.Context, filter *models.QuestionStatsFilter) (*models.QuestionStatsList, error)
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/analytics"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultMinResponses = 20
+
+	// Classical test theory thresholds
+	tooHardPValue     = 0.2
+	tooEasyPValue     = 0.95
+	lowDiscrimination = 0.15
+	easyPValue        = 0.75
+	hardPValue        = 0.4
+	maxStatsPageSize  = 1000
+)
+
+type analyticsUC struct {
+	cfg           *config.Config
+	analyticsRepo analytics.Repository
+	logger        logger.Logger
+}
+
+func NewAnalyticsUseCase(cfg *config.Config, analyticsRepo analytics.Repository, logger logger.Logger) analytics.UseCase {
+	return &analyticsUC{
+		cfg:           cfg,
+		analyticsRepo: analyticsRepo,
+		logger:        logger,
+	}
+}
+
+func (u *analyticsUC) ComputeItemAnalytics(ctx context.Context) (*models.ItemAnalyticsReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "analyticsUC.ComputeItemAnalytics")
+	defer span.Finish()
+
+	aggregates, err := u.analyticsRepo.GetQuestionAggregates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wrongAnswers, err := u.analyticsRepo.GetTopWrongAnswers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	topWrong := make(map[uuid.UUID]*models.WrongAnswerCount, len(wrongAnswers))
+	for _, w := range wrongAnswers {
+		topWrong[w.QuestionID] = w
+	}
+
+	report := &models.ItemAnalyticsReport{ComputedAt: time.Now()}
+	for _, aggregate := range aggregates {
+		stats := u.analyzeItem(aggregate, topWrong[aggregate.QuestionID], report.ComputedAt)
+
+		// Only recalibrate items whose key looks trustworthy, a broken key would skew the p-value
+		if stats.SuggestedDifficulty != nil && *stats.SuggestedDifficulty != aggregate.Difficulty &&
+			!hasFlag(stats.Flags, models.FlagAnswerNotInOptions) && !hasFlag(stats.Flags, models.FlagPossibleKeyError) {
+			if err := u.analyticsRepo.UpdateQuestionDifficulty(ctx, aggregate.QuestionID, *stats.SuggestedDifficulty); err != nil {
+				u.logger.Errorf("failed to recalibrate difficulty for question %s: %v", aggregate.QuestionID, err)
+			} else {
+				stats.Difficulty = *stats.SuggestedDifficulty
+				report.Recalibrated++
+			}
+		}
+
+		if err := u.analyticsRepo.UpsertQuestionStats(ctx, stats); err != nil {
+			return nil, errors.Wrap(err, "analyticsUC.ComputeItemAnalytics.UpsertQuestionStats")
+		}
+
+		report.QuestionsAnalyzed++
+		if len(stats.Flags) > 0 {
+			report.Flagged++
+		}
+	}
+
+	return report, nil
+}
+
+func (u *analyticsUC) GetQuestionStats(ctx context.Context, questionID uuid.UUID) (*models.QuestionStats, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "analyticsUC.GetQuestionStats")
+	defer span.Finish()
+
+	return u.analyticsRepo.GetQuestionStats(ctx, questionID)
+}
+
+func (u *analyticsUC) ListQuestionStats(ctx context.Context, filter *models.QuestionStatsFilter) (*models.QuestionStatsList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "analyticsUC.ListQuestionStats")
+	defer span.Finish()
+
+	if filter.Limit > maxStatsPageSize {
+		filter.Limit = maxStatsPageSize
+	}
+
+	return u.analyticsRepo.ListQuestionStats(ctx, filter)
+}
+
+// analyzeItem turns raw aggregates into item statistics and flags
+func (u *analyticsUC) analyzeItem(aggregate *models.QuestionAggregate, topWrong *models.WrongAnswerCount, computedAt time.Time) *models.QuestionStats {
+	stats := &models.QuestionStats{
+		QuestionID:   aggregate.QuestionID,
+		Responses:    aggregate.Responses,
+		Correct:      aggregate.Correct,
+		AvgTimeSpent: roundPtr(aggregate.AvgTimeSpent, 2),
+		Flags:        make([]string, 0),
+		Difficulty:   aggregate.Difficulty,
+		ComputedAt:   computedAt,
+	}
+
+	if aggregate.Discrimination != nil && !math.IsNaN(*aggregate.Discrimination) {
+		stats.Discrimination = roundPtr(aggregate.Discrimination, 4)
+	}
+	if aggregate.Responses > 0 {
+		stats.PValue = math.Round(float64(aggregate.Correct)/float64(aggregate.Responses)*10000) / 10000
+	}
+	if topWrong != nil {
+		stats.MostCommonWrongAnswer = &topWrong.UserAnswer
+		stats.WrongAnswerCount = topWrong.Count
+	}
+
+	// A key missing from the options is broken regardless of how many people answered
+	if aggregate.QuestionType == "multiple_choice" && !containsString(aggregate.Options, aggregate.Answer) {
+		stats.Flags = append(stats.Flags, models.FlagAnswerNotInOptions)
+	}
+
+	if aggregate.Responses < u.minResponses() {
+		return stats
+	}
+
+	if stats.PValue < tooHardPValue {
+		stats.Flags = append(stats.Flags, models.FlagTooHard)
+	}
+	if stats.PValue > tooEasyPValue {
+		stats.Flags = append(stats.Flags, models.FlagTooEasy)
+	}
+	if stats.Discrimination != nil {
+		if *stats.Discrimination < 0 {
+			stats.Flags = append(stats.Flags, models.FlagNegativeDiscrimination)
+		} else if *stats.Discrimination < lowDiscrimination {
+			stats.Flags = append(stats.Flags, models.FlagLowDiscrimination)
+		}
+	}
+	// A single wrong answer that beats the key usually means the key is wrong
+	if topWrong != nil && topWrong.Count > aggregate.Correct {
+		stats.Flags = append(stats.Flags, models.FlagPossibleKeyError)
+	}
+
+	suggested := difficultyFromPValue(stats.PValue)
+	stats.SuggestedDifficulty = &suggested
+
+	return stats
+}
+
+func (u *analyticsUC) minResponses() int {
+	if u.cfg.Analytics.MinResponses > 0 {
+		return u.cfg.Analytics.MinResponses
+	}
+	return defaultMinResponses
+}
+
+// difficultyFromPValue maps the observed share of correct answers to a difficulty band
+func difficultyFromPValue(pValue float64) string {
+	switch {
+	case pValue >= easyPValue:
+		return "easy"
+	case pValue < hardPValue:
+		return "hard"
+	default:
+		return "medium"
+	}
+}
+
+func roundPtr(v *float64, places int) *float64 {
+	if v == nil {
+		return nil
+	}
+	pow := math.Pow(10, float64(places))
+	rounded := math.Round(*v*pow) / pow
+	return &rounded
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFlag(flags []string, flag string) bool {
+	return containsString(flags, flag)
+}
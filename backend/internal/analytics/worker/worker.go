@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/analytics"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultInterval = time.Hour
+
+// AnalyticsWorker handles periodic item analysis
+type AnalyticsWorker struct {
+	analyticsUC analytics.UseCase
+	logger      logger.Logger
+	interval    time.Duration
+	stopCh      chan struct{}
+}
+
+// NewAnalyticsWorker creates a new item analytics worker, interval is in minutes
+func NewAnalyticsWorker(analyticsUC analytics.UseCase, interval time.Duration, logger logger.Logger) *AnalyticsWorker {
+	if interval <= 0 {
+		interval = defaultInterval
+	} else {
+		interval = interval * time.Minute
+	}
+
+	return &AnalyticsWorker{
+		analyticsUC: analyticsUC,
+		logger:      logger,
+		interval:    interval,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start begins the periodic item analysis
+func (w *AnalyticsWorker) Start() {
+	w.logger.Info("Starting item analytics worker")
+
+	// Run immediately on startup
+	go w.computeItemAnalytics()
+
+	// Then run periodically
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				go w.computeItemAnalytics()
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic item analysis
+func (w *AnalyticsWorker) Stop() {
+	w.logger.Info("Stopping item analytics worker")
+	close(w.stopCh)
+}
+
+// computeItemAnalytics triggers the item analysis
+func (w *AnalyticsWorker) computeItemAnalytics() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := w.analyticsUC.ComputeItemAnalytics(ctx)
+	if err != nil {
+		w.logger.Errorf("Error computing item analytics: %v", err)
+		return
+	}
+
+	w.logger.Infof("Item analytics computed: %d questions, %d flagged, %d recalibrated",
+		report.QuestionsAnalyzed, report.Flagged, report.Recalibrated)
+}
@@ -9,6 +9,15 @@ type Handlers interface {
 	// Auth routes
 	Register() echo.HandlerFunc
 	Login() echo.HandlerFunc
+	Refresh() echo.HandlerFunc
+	Logout() echo.HandlerFunc
+	LogoutAll() echo.HandlerFunc
+
+	// Role routes
+	GetRoles() echo.HandlerFunc
+	GetUserRoles() echo.HandlerFunc
+	GrantRole() echo.HandlerFunc
+	RevokeRole() echo.HandlerFunc
 
 	// User routes
 	UpdateProfile() echo.HandlerFunc
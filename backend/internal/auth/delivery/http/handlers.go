@@ -1,6 +1,7 @@
 package http
 
 import (
+	"database/sql"
 	"net/http"
 	"strconv"
 
@@ -82,43 +83,220 @@ func (h *authHandlers) Login() echo.HandlerFunc {
 	}
 }
 
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access and refresh token, each refresh token is single use
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} models.UserWithToken
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/refresh [post]
+func (h *authHandlers) Refresh() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := &models.RefreshTokenRequest{}
+		if err := c.Bind(request); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), request); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		userWithToken, err := h.authUC.Refresh(c.Request().Context(), request.RefreshToken)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			utils.LogResponseError(c, h.logger, err)
+			return echo.NewHTTPError(http.StatusInternalServerError, httpErrors.InternalServerError.Error())
+		}
+
+		return c.JSON(http.StatusOK, userWithToken)
+	}
+}
+
 // Logout godoc
 // @Summary Logout user
-// @Description logout user removing session
+// @Description Revoke the access and refresh tokens of the current login session
 // @Tags Auth
-// @Accept  json
-// @Produce  json
-// @Success 200 {string} string	"ok"
+// @Produce json
+// @Success 204
 // @Router /auth/logout [post]
 func (h *authHandlers) Logout() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Logout")
 		defer span.Finish()
 
-		cookie, err := c.Cookie("session-id")
+		userID, err := utils.GetUserIDFromContext(c)
 		if err != nil {
-			if errors.Is(err, http.ErrNoCookie) {
-				utils.LogResponseError(c, h.logger, err)
-				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
-			}
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authHandlers.Logout.GetUserIDFromContext"))
+		}
+		claims, err := utils.GetClaimsFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authHandlers.Logout.GetClaimsFromContext"))
+		}
+		sessionID, err := uuid.Parse(claims.SessionID)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(httpErrors.InvalidJWTClaims)
+		}
+
+		if err := h.authUC.Logout(ctx, userID, sessionID); err != nil {
 			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError(err))
+			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		if err := h.sessUC.DeleteByID(ctx, cookie.Value); err != nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// LogoutAll godoc
+// @Summary Logout user on all devices
+// @Description Revoke the access and refresh tokens of every login session of the user
+// @Tags Auth
+// @Produce json
+// @Success 204
+// @Router /auth/logout/all [post]
+func (h *authHandlers) LogoutAll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.LogoutAll")
+		defer span.Finish()
+
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authHandlers.LogoutAll.GetUserIDFromContext"))
+		}
+
+		if err := h.authUC.LogoutAll(ctx, userID); err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		utils.DeleteSessionCookie(c, h.cfg.Session.Name)
+		return c.NoContent(http.StatusNoContent)
+	}
+}
 
-		return c.NoContent(http.StatusOK)
+// GetRoles godoc
+// @Summary Get roles
+// @Description The roles with the permissions they grant
+// @Tags Roles
+// @Produce json
+// @Success 200 {array} models.Role
+// @Router /auth/admin/roles [get]
+func (h *authHandlers) GetRoles() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		roles, err := h.authUC.GetRoles(c.Request().Context())
+		if err != nil {
+			return roleError(err, "authHandlers.GetRoles.GetRoles")
+		}
+
+		return c.JSON(http.StatusOK, roles)
+	}
+}
+
+// GetUserRoles godoc
+// @Summary Get user roles
+// @Description The roles granted to a user
+// @Tags Roles
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {array} models.UserRole
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/admin/users/{user_id}/roles [get]
+func (h *authHandlers) GetUserRoles() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "authHandlers.GetUserRoles.uuid.Parse"))
+		}
+
+		roles, err := h.authUC.GetUserRoles(c.Request().Context(), userID)
+		if err != nil {
+			return roleError(err, "authHandlers.GetUserRoles.GetUserRoles")
+		}
+
+		return c.JSON(http.StatusOK, roles)
+	}
+}
+
+// GrantRole godoc
+// @Summary Grant a role
+// @Description Grant a role to a user, their sessions get it on their next refresh
+// @Tags Roles
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} models.UserRole
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/admin/users/{user_id}/roles/{role} [put]
+func (h *authHandlers) GrantRole() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		adminID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authHandlers.GrantRole.GetUserIDFromContext"))
+		}
+		userID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "authHandlers.GrantRole.uuid.Parse"))
+		}
+
+		role, err := h.authUC.GrantRole(c.Request().Context(), userID, c.Param("role"), adminID)
+		if err != nil {
+			return roleError(err, "authHandlers.GrantRole.GrantRole")
+		}
+
+		return c.JSON(http.StatusOK, role)
+	}
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role
+// @Description Revoke a role of a user, their sessions lose it on their next refresh
+// @Tags Roles
+// @Param user_id path string true "User ID"
+// @Param role path string true "Role name"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/admin/users/{user_id}/roles/{role} [delete]
+func (h *authHandlers) RevokeRole() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		adminID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authHandlers.RevokeRole.GetUserIDFromContext"))
+		}
+		userID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "authHandlers.RevokeRole.uuid.Parse"))
+		}
+
+		if err := h.authUC.RevokeRole(c.Request().Context(), userID, c.Param("role"), adminID); err != nil {
+			return roleError(err, "authHandlers.RevokeRole.RevokeRole")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// roleError maps the role errors to their status
+func roleError(err error, op string) error {
+	switch {
+	case errors.Is(err, auth.ErrRoleNotFound),
+		errors.Is(err, auth.ErrUserRoleNotFound),
+		errors.Is(err, sql.ErrNoRows):
+		return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, auth.ErrRevokeOwnAdmin):
+		return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, httpErrors.PermissionDenied):
+		return httpErrors.NewForbiddenError(err.Error())
+	default:
+		return httpErrors.NewBadRequestError(errors.Wrap(err, op))
 	}
 }
 
 // UpdateProfile godoc
 // @Summary Update user profile
-// @Description Update user profile in the system
+// @Description Update user profile, by the user or a user granted the users:manage permission
 // @Tags Profile
 // @Accept json
 // @Produce json
@@ -141,6 +319,9 @@ func (h *authHandlers) UpdateProfile() echo.HandlerFunc {
 
 		updatedUser, err := h.authUC.Update(c.Request().Context(), user)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 
@@ -174,6 +355,9 @@ func (h *authHandlers) UpdateAvatar() echo.HandlerFunc {
 
 		updatedUser, err := h.authUC.UpdateAvatar(c.Request().Context(), userID, input.AvatarURL)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 
@@ -183,7 +367,7 @@ func (h *authHandlers) UpdateAvatar() echo.HandlerFunc {
 
 // GetProfile godoc
 // @Summary Get user profile
-// @Description Get user profile by ID
+// @Description Get user profile by ID, for the user or a user granted the users:read permission
 // @Tags Profile
 // @Accept json
 // @Produce json
@@ -197,8 +381,11 @@ func (h *authHandlers) GetProfile() echo.HandlerFunc {
 			return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
 		}
 
-		user, err := h.authUC.GetByID(c.Request().Context(), userID)
+		user, err := h.authUC.GetProfile(c.Request().Context(), userID)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
 
@@ -208,7 +395,7 @@ func (h *authHandlers) GetProfile() echo.HandlerFunc {
 
 // GetProgress godoc
 // @Summary Get user progress
-// @Description Get user's learning progress
+// @Description Get user's learning progress, for the user or a user granted the users:read permission
 // @Tags Progress
 // @Accept json
 // @Produce json
@@ -236,6 +423,9 @@ func (h *authHandlers) GetProgress() echo.HandlerFunc {
 
 		progress, err := h.authUC.GetUserProgress(c.Request().Context(), userID, subject, grade)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusNotFound, "Progress not found")
 		}
 
@@ -245,7 +435,7 @@ func (h *authHandlers) GetProgress() echo.HandlerFunc {
 
 // GetDailyStreak godoc
 // @Summary Get user's daily streak
-// @Description Get user's learning streak information
+// @Description Get user's learning streak information, for the user or a user granted the users:read permission
 // @Tags Progress
 // @Accept json
 // @Produce json
@@ -261,6 +451,9 @@ func (h *authHandlers) GetDailyStreak() echo.HandlerFunc {
 
 		streak, err := h.authUC.GetDailyStreak(c.Request().Context(), userID)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusNotFound, "Streak not found")
 		}
 
@@ -289,7 +482,7 @@ func (h *authHandlers) GetUserByID() echo.HandlerFunc {
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		user, err := h.authUC.GetByID(ctx, uID)
+		user, err := h.authUC.GetProfile(ctx, uID)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
@@ -5,12 +5,25 @@ import (
 
 	"github.com/AleksK1NG/api-mc/internal/auth"
 	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
 func MapAuthRoutes(authGroup *echo.Group, h auth.Handlers, mw *middleware.MiddlewareManager) {
 
 	authGroup.POST("/register", h.Register())
 	authGroup.POST("/login", h.Login())
+	authGroup.POST("/refresh", h.Refresh())
+	authGroup.POST("/logout", h.Logout(), mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	authGroup.POST("/logout/all", h.LogoutAll(), mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+
+	admin := authGroup.Group("/admin")
+	admin.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()), mw.RequirePermission(models.PermissionRolesManage))
+	{
+		admin.GET("/roles", h.GetRoles())
+		admin.GET("/users/:user_id/roles", h.GetUserRoles())
+		admin.PUT("/users/:user_id/roles/:role", h.GrantRole())
+		admin.DELETE("/users/:user_id/roles/:role", h.RevokeRole())
+	}
 
 	profile := authGroup.Group("/profile")
 	profile.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
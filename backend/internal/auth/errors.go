@@ -0,0 +1,13 @@
+package auth
+
+import "errors"
+
+// Auth errors
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session is revoked")
+	ErrTokenRevoked        = errors.New("token is revoked")
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrUserRoleNotFound    = errors.New("the user does not have the role")
+	ErrRevokeOwnAdmin      = errors.New("admins cannot revoke their own admin role")
+)
@@ -26,5 +26,22 @@ type Repository interface {
 	// Daily Streak
 	CreateDailyStreak(ctx context.Context, streak *models.DailyStreak) error
 	GetDailyStreak(ctx context.Context, userID uuid.UUID) (*models.DailyStreak, error)
-	UpdateDailyStreak(ctx context.Context, streak *models.DailyStreak) error
+
+	// Refresh Tokens
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// Marks the token used and stores its successor, ErrRefreshTokenReused when it was used or revoked meanwhile
+	RotateRefreshToken(ctx context.Context, tokenID uuid.UUID, next *models.RefreshToken) error
+	// Revoke and return the tokens that were not revoked yet
+	RevokeRefreshTokenFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) ([]*models.RefreshToken, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) ([]*models.RefreshToken, error)
+	GetLiveAccessTokens(ctx context.Context, userID uuid.UUID) ([]*models.RefreshToken, error)
+
+	// Roles
+	GetRoles(ctx context.Context) ([]*models.Role, error)
+	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.UserRole, error)
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// ErrRoleNotFound for an unknown role, granting a role the user has returns it unchanged
+	GrantRole(ctx context.Context, userID uuid.UUID, roleName string, grantedBy *uuid.UUID) (*models.UserRole, error)
+	RevokeRole(ctx context.Context, userID uuid.UUID, roleName string) error
 }
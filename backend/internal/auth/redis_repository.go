@@ -3,6 +3,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
@@ -12,4 +13,8 @@ type RedisRepository interface {
 	GetByIDCtx(ctx context.Context, key string) (*models.User, error)
 	SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error
 	DeleteUserCtx(ctx context.Context, key string) error
+
+	// JWT denylist, a token id is kept until the token expires
+	DenyTokenCtx(ctx context.Context, tokenID string, ttl time.Duration) error
+	IsTokenDeniedCtx(ctx context.Context, tokenID string) (bool, error)
 }
@@ -39,6 +39,7 @@ func (r *authRepo) Register(ctx context.Context, user *models.User) (*models.Use
 		0, // Initial XP
 		0, // Initial Streak
 		user.LastActive,
+		user.Timezone,
 	).StructScan(u); err != nil {
 		return nil, err
 	}
@@ -55,6 +56,7 @@ func (r *authRepo) Update(ctx context.Context, user *models.User) (*models.User,
 		user.LastName,
 		user.Email,
 		user.Grade,
+		user.Timezone,
 		user.UserID,
 	).StructScan(u); err != nil {
 		return nil, err
@@ -153,17 +155,188 @@ func (r *authRepo) GetDailyStreak(ctx context.Context, userID uuid.UUID) (*model
 	return streak, nil
 }
 
-// Update daily streak
-func (r *authRepo) UpdateDailyStreak(ctx context.Context, streak *models.DailyStreak) error {
-	_, err := r.db.ExecContext(
+// Create refresh token
+func (r *authRepo) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.CreateRefreshToken")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(
 		ctx,
-		updateDailyStreakQuery,
-		streak.CurrentStreak,
-		streak.MaxStreak,
-		streak.LastActivity,
-		streak.StreakID,
-	)
-	return err
+		createRefreshTokenQuery,
+		token.FamilyID,
+		token.UserID,
+		token.TokenHash,
+		token.AccessTokenID,
+		token.AccessExpiresAt,
+		token.ExpiresAt,
+	); err != nil {
+		return errors.Wrap(err, "authRepo.CreateRefreshToken.ExecContext")
+	}
+	return nil
+}
+
+// Get refresh token by hash
+func (r *authRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetRefreshToken")
+	defer span.Finish()
+
+	token := &models.RefreshToken{}
+	if err := r.db.GetContext(ctx, token, getRefreshTokenQuery, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidRefreshToken
+		}
+		return nil, errors.Wrap(err, "authRepo.GetRefreshToken.GetContext")
+	}
+	return token, nil
+}
+
+// Rotate refresh token, the used token and its successor are written together
+func (r *authRepo) RotateRefreshToken(ctx context.Context, tokenID uuid.UUID, next *models.RefreshToken) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.RotateRefreshToken")
+	defer span.Finish()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RotateRefreshToken.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	// Only one of concurrent rotations of the same token marks it used, the others are reuses
+	result, err := tx.ExecContext(ctx, useRefreshTokenQuery, tokenID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RotateRefreshToken.useRefreshToken")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RotateRefreshToken.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return auth.ErrRefreshTokenReused
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		createRefreshTokenQuery,
+		next.FamilyID,
+		next.UserID,
+		next.TokenHash,
+		next.AccessTokenID,
+		next.AccessExpiresAt,
+		next.ExpiresAt,
+	); err != nil {
+		return errors.Wrap(err, "authRepo.RotateRefreshToken.createRefreshToken")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "authRepo.RotateRefreshToken.Commit")
+	}
+	return nil
+}
+
+// Revoke the refresh tokens of a login session
+func (r *authRepo) RevokeRefreshTokenFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) ([]*models.RefreshToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.RevokeRefreshTokenFamily")
+	defer span.Finish()
+
+	revoked := make([]*models.RefreshToken, 0)
+	if err := r.db.SelectContext(ctx, &revoked, revokeRefreshTokenFamilyQuery, familyID, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.RevokeRefreshTokenFamily.SelectContext")
+	}
+	return revoked, nil
+}
+
+// Revoke the refresh tokens of every login session of the user
+func (r *authRepo) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) ([]*models.RefreshToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.RevokeUserRefreshTokens")
+	defer span.Finish()
+
+	revoked := make([]*models.RefreshToken, 0)
+	if err := r.db.SelectContext(ctx, &revoked, revokeUserRefreshTokensQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.RevokeUserRefreshTokens.SelectContext")
+	}
+	return revoked, nil
+}
+
+// Get the refresh tokens of the user issued with an access token that did not expire yet
+func (r *authRepo) GetLiveAccessTokens(ctx context.Context, userID uuid.UUID) ([]*models.RefreshToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetLiveAccessTokens")
+	defer span.Finish()
+
+	tokens := make([]*models.RefreshToken, 0)
+	if err := r.db.SelectContext(ctx, &tokens, getLiveAccessTokensQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetLiveAccessTokens.SelectContext")
+	}
+	return tokens, nil
+}
+
+// Get roles with their permissions
+func (r *authRepo) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetRoles")
+	defer span.Finish()
+
+	roles := make([]*models.Role, 0)
+	if err := r.db.SelectContext(ctx, &roles, getRolesQuery); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetRoles.SelectContext")
+	}
+	return roles, nil
+}
+
+// Get the roles granted to the user
+func (r *authRepo) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.UserRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetUserRoles")
+	defer span.Finish()
+
+	roles := make([]*models.UserRole, 0)
+	if err := r.db.SelectContext(ctx, &roles, getUserRolesQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetUserRoles.SelectContext")
+	}
+	return roles, nil
+}
+
+// Get the permissions granted to the user by their roles
+func (r *authRepo) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetUserPermissions")
+	defer span.Finish()
+
+	permissions := make([]string, 0)
+	if err := r.db.SelectContext(ctx, &permissions, getUserPermissionsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetUserPermissions.SelectContext")
+	}
+	return permissions, nil
+}
+
+// Grant a role to the user
+func (r *authRepo) GrantRole(ctx context.Context, userID uuid.UUID, roleName string, grantedBy *uuid.UUID) (*models.UserRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GrantRole")
+	defer span.Finish()
+
+	role := &models.UserRole{}
+	if err := r.db.GetContext(ctx, role, grantRoleQuery, userID, roleName, grantedBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrRoleNotFound
+		}
+		return nil, errors.Wrap(err, "authRepo.GrantRole.GetContext")
+	}
+	return role, nil
+}
+
+// Revoke a role of the user
+func (r *authRepo) RevokeRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.RevokeRole")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, revokeRoleQuery, userID, roleName)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RevokeRole.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RevokeRole.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return auth.ErrUserRoleNotFound
+	}
+	return nil
 }
 
 // Delete existing user
@@ -13,6 +13,8 @@ import (
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
+const deniedTokenPrefix = "api-auth:denied:"
+
 type authRedisRepo struct {
 	redisClient *redis.Client
 }
@@ -59,3 +61,24 @@ func (r *authRedisRepo) DeleteUserCtx(ctx context.Context, key string) error {
 	}
 	return nil
 }
+
+func (r *authRedisRepo) DenyTokenCtx(ctx context.Context, tokenID string, ttl time.Duration) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.DenyTokenCtx")
+	defer span.Finish()
+
+	if err := r.redisClient.Set(ctx, deniedTokenPrefix+tokenID, 1, ttl).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.DenyTokenCtx.redisClient.Set")
+	}
+	return nil
+}
+
+func (r *authRedisRepo) IsTokenDeniedCtx(ctx context.Context, tokenID string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.IsTokenDeniedCtx")
+	defer span.Finish()
+
+	exists, err := r.redisClient.Exists(ctx, deniedTokenPrefix+tokenID).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "authRedisRepo.IsTokenDeniedCtx.redisClient.Exists")
+	}
+	return exists > 0, nil
+}
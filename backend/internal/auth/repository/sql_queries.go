@@ -2,15 +2,16 @@ package repository
 
 const (
 	createUserQuery = `
-		INSERT INTO users (first_name, last_name, email, password, grade, avatar, xp, streak, last_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (first_name, last_name, email, password, grade, avatar, xp, streak, last_active, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE(NULLIF($10, ''), 'UTC'))
 		RETURNING *
 	`
 
 	updateUserQuery = `
 		UPDATE users
-		SET first_name = $1, last_name = $2, email = $3, grade = $4, updated_at = now()
-		WHERE user_id = $5
+		SET first_name = $1, last_name = $2, email = $3, grade = $4,
+			timezone = COALESCE(NULLIF($5, ''), timezone), updated_at = now()
+		WHERE user_id = $6
 		RETURNING *
 	`
 
@@ -54,12 +55,6 @@ const (
 		SELECT * FROM daily_streaks WHERE user_id = $1
 	`
 
-	updateDailyStreakQuery = `
-		UPDATE daily_streaks
-		SET current_streak = $1, max_streak = $2, last_activity = $3, updated_at = now()
-		WHERE streak_id = $4
-	`
-
 	deleteUserQuery = `DELETE FROM users WHERE user_id = $1`
 
 	// getUserQuery = `SELECT user_id, first_name, last_name, email, role, about, avatar, phone_number,
@@ -89,4 +84,69 @@ const (
        			 		address, city, gender, postcode, birthday, created_at, updated_at, login_date, password
 				 		FROM users 
 				 		WHERE email = $1`
+
+	createRefreshTokenQuery = `
+		INSERT INTO refresh_tokens (family_id, user_id, token_hash, access_token_id, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	getRefreshTokenQuery = `
+		SELECT * FROM refresh_tokens WHERE token_hash = $1
+	`
+
+	useRefreshTokenQuery = `
+		UPDATE refresh_tokens SET used_at = now()
+		WHERE token_id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`
+
+	revokeRefreshTokenFamilyQuery = `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING *
+	`
+
+	revokeUserRefreshTokensQuery = `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+		RETURNING *
+	`
+
+	getRolesQuery = `
+		SELECT r.role_name, r.description,
+			COALESCE(array_agg(rp.permission_name ORDER BY rp.permission_name)
+				FILTER (WHERE rp.permission_name IS NOT NULL), '{}') AS permissions
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_name = r.role_name
+		GROUP BY r.role_name, r.description
+		ORDER BY r.role_name
+	`
+
+	getUserRolesQuery = `
+		SELECT * FROM user_roles WHERE user_id = $1 ORDER BY role_name
+	`
+
+	getUserPermissionsQuery = `
+		SELECT DISTINCT rp.permission_name
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_name = ur.role_name
+		WHERE ur.user_id = $1
+		ORDER BY rp.permission_name
+	`
+
+	// Nothing is returned for an unknown role, a role granted already keeps who granted it first
+	grantRoleQuery = `
+		INSERT INTO user_roles (user_id, role_name, granted_by)
+		SELECT $1, role_name, $3 FROM roles WHERE role_name = $2
+		ON CONFLICT (user_id, role_name) DO UPDATE SET role_name = EXCLUDED.role_name
+		RETURNING *
+	`
+
+	revokeRoleQuery = `
+		DELETE FROM user_roles WHERE user_id = $1 AND role_name = $2
+	`
+
+	getLiveAccessTokensQuery = `
+		SELECT * FROM refresh_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND access_expires_at > now()
+	`
 )
@@ -0,0 +1,25 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/auth"
+	"github.com/AleksK1NG/api-mc/internal/events"
+)
+
+const subscriberName = "auth"
+
+// RegisterAuthSubscribers drops the cached user once their XP or level changed, profiles are read from the cache
+func RegisterAuthSubscribers(bus events.Bus, authUC auth.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		if err := authUC.ForgetCachedUser(ctx, event.UserID); err != nil {
+			return errors.Wrap(err, "authSubscriber.ForgetCachedUser")
+		}
+		return nil
+	},
+		events.XPAwardedType,
+		events.LevelUpType,
+	)
+}
@@ -14,12 +14,24 @@ type UseCase interface {
 	// User Authentication
 	Register(ctx context.Context, user *models.User) (*models.UserWithToken, error)
 	Login(ctx context.Context, user *models.User) (*models.UserWithToken, error)
+	// Exchanges a refresh token for a new pair, a reused token revokes its whole session
+	Refresh(ctx context.Context, refreshToken string) (*models.UserWithToken, error)
+	// Revokes the login session's refresh tokens and access tokens
+	Logout(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+	// Revokes every login session of the user
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
 
-	// User Management
+	// User Management, a profile is read by the user and the users granted users:read,
+	// updated by the user and the users granted users:manage
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	UpdateAvatar(ctx context.Context, userID uuid.UUID, avatarURL string) (*models.User, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	// Loads the user without authorization, for the middlewares and the other use cases
 	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// Drops the cached user so the next GetByID reads their current XP and level
+	ForgetCachedUser(ctx context.Context, userID uuid.UUID) error
 
 	// User Progress
 	GetUserProgress(ctx context.Context, userID uuid.UUID, subject string, grade int) (*models.UserProgress, error)
@@ -27,5 +39,10 @@ type UseCase interface {
 
 	// Daily Streak
 	GetDailyStreak(ctx context.Context, userID uuid.UUID) (*models.DailyStreak, error)
-	UpdateDailyStreak(ctx context.Context, streak *models.DailyStreak) error
+
+	// Roles, a change applies to the user's sessions from their next refresh
+	GetRoles(ctx context.Context) ([]*models.Role, error)
+	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.UserRole, error)
+	GrantRole(ctx context.Context, userID uuid.UUID, roleName string, grantedBy uuid.UUID) (*models.UserRole, error)
+	RevokeRole(ctx context.Context, userID uuid.UUID, roleName string, revokedBy uuid.UUID) error
 }
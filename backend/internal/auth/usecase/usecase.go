@@ -2,39 +2,53 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/auth"
+	"github.com/AleksK1NG/api-mc/internal/authz"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/levels"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 const (
-	basePrefix          = "api-auth:"
-	cacheDuration       = 3600
-	tokenExpirationTime = 24 * time.Hour
+	basePrefix             = "api-auth:"
+	cacheDuration          = 3600
+	tokenExpirationTime    = 24 * time.Hour
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	refreshTokenBytes      = 32
 )
 
 // Auth UseCase
 type authUC struct {
-	cfg       *config.Config
-	authRepo  auth.Repository
-	redisRepo auth.RedisRepository
-	logger    logger.Logger
+	cfg        *config.Config
+	authRepo   auth.Repository
+	redisRepo  auth.RedisRepository
+	authzUC    authz.UseCase
+	levelCurve *levels.Curve
+	logger     logger.Logger
 }
 
 // Auth UseCase constructor
-func NewAuthUseCase(cfg *config.Config, authRepo auth.Repository, redisRepo auth.RedisRepository, logger logger.Logger) auth.UseCase {
+func NewAuthUseCase(cfg *config.Config, authRepo auth.Repository, redisRepo auth.RedisRepository, authzUC authz.UseCase, levelCurve *levels.Curve, logger logger.Logger) auth.UseCase {
 	return &authUC{
-		cfg:       cfg,
-		authRepo:  authRepo,
-		redisRepo: redisRepo,
-		logger:    logger,
+		cfg:        cfg,
+		authRepo:   authRepo,
+		redisRepo:  redisRepo,
+		authzUC:    authzUC,
+		levelCurve: levelCurve,
+		logger:     logger,
 	}
 }
 
@@ -79,16 +93,14 @@ func (u *authUC) Register(ctx context.Context, user *models.User) (*models.UserW
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWTToken(createdUser, u.cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %v", err)
+	// Every user is a student, the other roles are granted by the admins
+	if _, err := u.authRepo.GrantRole(ctx, createdUser.UserID, models.RoleStudent, nil); err != nil {
+		return nil, err
 	}
 
-	return &models.UserWithToken{
-		User:  createdUser,
-		Token: token,
-	}, nil
+	createdUser.LevelProgress = u.levelCurve.Progress(createdUser.XP)
+
+	return u.createSession(ctx, createdUser)
 }
 
 // Login user
@@ -108,36 +120,178 @@ func (u *authUC) Login(ctx context.Context, user *models.User) (*models.UserWith
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWTToken(updatedUser, u.cfg)
+	updatedUser.LevelProgress = u.levelCurve.Progress(updatedUser.XP)
+
+	return u.createSession(ctx, updatedUser)
+}
+
+// Exchange a refresh token for a new token pair of the same login session
+func (u *authUC) Refresh(ctx context.Context, refreshToken string) (*models.UserWithToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Refresh")
+	defer span.Finish()
+
+	current, err := u.authRepo.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current.RevokedAt != nil || !current.ExpiresAt.After(time.Now()) {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	// A rotated token is presented again: it leaked, from this client or the one that rotated it,
+	// and the whole session is revoked
+	if current.UsedAt != nil {
+		u.revokeReusedSession(ctx, current)
+		return nil, auth.ErrRefreshTokenReused
+	}
+
+	user, err := u.authRepo.GetByID(ctx, current.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %v", err)
+		return nil, err
 	}
 
-	return &models.UserWithToken{
-		User:  updatedUser,
-		Token: token,
-	}, nil
+	userWithToken, next, err := u.newTokens(ctx, user, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authRepo.RotateRefreshToken(ctx, current.TokenID, next); err != nil {
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			u.revokeReusedSession(ctx, current)
+		}
+		return nil, err
+	}
+
+	user.LevelProgress = u.levelCurve.Progress(user.XP)
+
+	return userWithToken, nil
+}
+
+// Logout the login session of the access token
+func (u *authUC) Logout(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Logout")
+	defer span.Finish()
+
+	revoked, err := u.authRepo.RevokeRefreshTokenFamily(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	return u.denyAccessTokens(ctx, revoked)
+}
+
+// Logout every login session of the user
+func (u *authUC) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.LogoutAll")
+	defer span.Finish()
+
+	revoked, err := u.authRepo.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return u.denyAccessTokens(ctx, revoked)
+}
+
+// Get roles with their permissions
+func (u *authUC) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	return u.authRepo.GetRoles(ctx)
+}
+
+// Get the roles granted to the user
+func (u *authUC) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.UserRole, error) {
+	if _, err := u.authRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return u.authRepo.GetUserRoles(ctx, userID)
+}
+
+// Grant a role to the user
+func (u *authUC) GrantRole(ctx context.Context, userID uuid.UUID, roleName string, grantedBy uuid.UUID) (*models.UserRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GrantRole")
+	defer span.Finish()
+
+	if err := utils.CheckPermission(ctx, models.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+	if _, err := u.authRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	role, err := u.authRepo.GrantRole(ctx, userID, roleName, &grantedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Infof("Role granted, UserID: %s, Role: %s, GrantedBy: %s", userID, roleName, grantedBy)
+	u.expireAccessTokens(ctx, userID)
+
+	return role, nil
+}
+
+// Revoke a role of the user
+func (u *authUC) RevokeRole(ctx context.Context, userID uuid.UUID, roleName string, revokedBy uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.RevokeRole")
+	defer span.Finish()
+
+	if err := utils.CheckPermission(ctx, models.PermissionRolesManage); err != nil {
+		return err
+	}
+	// The platform would be left without an admin to grant the role back
+	if userID == revokedBy && roleName == models.RoleAdmin {
+		return auth.ErrRevokeOwnAdmin
+	}
+
+	if err := u.authRepo.RevokeRole(ctx, userID, roleName); err != nil {
+		return err
+	}
+
+	u.logger.Infof("Role revoked, UserID: %s, Role: %s, RevokedBy: %s", userID, roleName, revokedBy)
+	u.expireAccessTokens(ctx, userID)
+
+	return nil
+}
+
+// Check the access token id against the denylist
+func (u *authUC) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return u.redisRepo.IsTokenDeniedCtx(ctx, tokenID)
 }
 
 // Update user profile
 func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	if err := u.authorizeProfile(ctx, user.UserID, models.AccessActionUpdate, models.PermissionUsersManage); err != nil {
+		return nil, err
+	}
 	if err := user.PrepareUpdate(); err != nil {
 		return nil, err
 	}
-	return u.authRepo.Update(ctx, user)
+	updatedUser, err := u.authRepo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	updatedUser.LevelProgress = u.levelCurve.Progress(updatedUser.XP)
+
+	return updatedUser, nil
 }
 
 // Update user avatar
 func (u *authUC) UpdateAvatar(ctx context.Context, userID uuid.UUID, avatarURL string) (*models.User, error) {
+	if err := u.authorizeProfile(ctx, userID, models.AccessActionUpdate, models.PermissionUsersManage); err != nil {
+		return nil, err
+	}
 	return u.authRepo.UpdateAvatar(ctx, userID, avatarURL)
 }
 
+// Get the profile of a user, for the user and the users granted users:read
+func (u *authUC) GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	if err := u.authorizeProfile(ctx, userID, models.AccessActionRead, models.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+	return u.GetByID(ctx, userID)
+}
+
 // Get user by id
 func (u *authUC) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	// Try to get from Redis first
 	cachedUser, err := u.redisRepo.GetByIDCtx(ctx, userID.String())
 	if err == nil && cachedUser != nil {
+		cachedUser.LevelProgress = u.levelCurve.Progress(cachedUser.XP)
 		return cachedUser, nil
 	}
 
@@ -152,9 +306,15 @@ func (u *authUC) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, e
 		u.logger.Errorf("Failed to cache user: %v", err)
 	}
 
+	user.LevelProgress = u.levelCurve.Progress(user.XP)
 	return user, nil
 }
 
+// Drop the cached user
+func (u *authUC) ForgetCachedUser(ctx context.Context, userID uuid.UUID) error {
+	return u.redisRepo.DeleteUserCtx(ctx, userID.String())
+}
+
 // Get user by email
 func (u *authUC) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	return u.authRepo.GetByEmail(ctx, email)
@@ -162,6 +322,9 @@ func (u *authUC) GetByEmail(ctx context.Context, email string) (*models.User, er
 
 // Get user progress
 func (u *authUC) GetUserProgress(ctx context.Context, userID uuid.UUID, subject string, grade int) (*models.UserProgress, error) {
+	if err := u.authorizeProfile(ctx, userID, models.AccessActionRead, models.PermissionUsersRead); err != nil {
+		return nil, err
+	}
 	return u.authRepo.GetUserProgress(ctx, userID, subject, grade)
 }
 
@@ -172,17 +335,132 @@ func (u *authUC) UpdateUserProgress(ctx context.Context, progress *models.UserPr
 
 // Get daily streak
 func (u *authUC) GetDailyStreak(ctx context.Context, userID uuid.UUID) (*models.DailyStreak, error) {
+	if err := u.authorizeProfile(ctx, userID, models.AccessActionRead, models.PermissionUsersRead); err != nil {
+		return nil, err
+	}
 	return u.authRepo.GetDailyStreak(ctx, userID)
 }
 
-// Update daily streak
-func (u *authUC) UpdateDailyStreak(ctx context.Context, streak *models.DailyStreak) error {
-	if streak.CurrentStreak > streak.MaxStreak {
-		streak.MaxStreak = streak.CurrentStreak
+func (u *authUC) GenerateUserKey(userID string) string {
+	return fmt.Sprintf("%s: %s", basePrefix, userID)
+}
+
+// authorizeProfile allows the user themselves and the users granted the permission
+func (u *authUC) authorizeProfile(ctx context.Context, userID uuid.UUID, action string, permission string) error {
+	return u.authzUC.Authorize(ctx, &models.AccessRequest{
+		Action:       action,
+		ResourceType: models.ResourceProfile,
+		ResourceID:   userID.String(),
+		OwnerID:      userID,
+		Permission:   permission,
+	})
+}
+
+// createSession starts a login session, the family of the refresh tokens rotated from the first one
+func (u *authUC) createSession(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+	userWithToken, refreshToken, err := u.newTokens(ctx, user, uuid.New())
+	if err != nil {
+		return nil, err
 	}
-	return u.authRepo.UpdateDailyStreak(ctx, streak)
+	if err := u.authRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+	return userWithToken, nil
 }
 
-func (u *authUC) GenerateUserKey(userID string) string {
-	return fmt.Sprintf("%s: %s", basePrefix, userID)
+// newTokens signs an access token with the user's current roles for the login session and draws the refresh token
+// issued with it
+func (u *authUC) newTokens(ctx context.Context, user *models.User, sessionID uuid.UUID) (*models.UserWithToken, *models.RefreshToken, error) {
+	roles, err := u.authRepo.GetUserRoles(ctx, user.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user.Roles = make([]string, 0, len(roles))
+	for _, r := range roles {
+		user.Roles = append(user.Roles, r.RoleName)
+	}
+	if user.Permissions, err = u.authRepo.GetUserPermissions(ctx, user.UserID); err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := utils.GenerateJWTToken(user, sessionID, u.cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	secret := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, errors.Wrap(err, "authUC.newTokens.rand.Read")
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	refreshTTL := u.cfg.Auth.RefreshTokenTTL * time.Hour
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+
+	refreshToken := &models.RefreshToken{
+		FamilyID:        sessionID,
+		UserID:          user.UserID,
+		TokenHash:       hashRefreshToken(token),
+		AccessTokenID:   accessToken.TokenID,
+		AccessExpiresAt: accessToken.ExpiresAt,
+		ExpiresAt:       time.Now().Add(refreshTTL),
+	}
+
+	return &models.UserWithToken{
+		User:         user,
+		Token:        accessToken.Token,
+		ExpiresAt:    accessToken.ExpiresAt,
+		RefreshToken: token,
+	}, refreshToken, nil
+}
+
+// revokeReusedSession revokes the session of a reused refresh token, the error of the refresh is returned regardless
+func (u *authUC) revokeReusedSession(ctx context.Context, reused *models.RefreshToken) {
+	u.logger.Warnf("Refresh token reused, revoking the session, UserID: %s, SessionID: %s", reused.UserID, reused.FamilyID)
+
+	revoked, err := u.authRepo.RevokeRefreshTokenFamily(ctx, reused.UserID, reused.FamilyID)
+	if err != nil {
+		u.logger.Errorf("authUC.revokeReusedSession.RevokeRefreshTokenFamily, SessionID: %s, Error: %v", reused.FamilyID, err)
+		return
+	}
+	if err := u.denyAccessTokens(ctx, revoked); err != nil {
+		u.logger.Errorf("authUC.revokeReusedSession.denyAccessTokens, SessionID: %s, Error: %v", reused.FamilyID, err)
+	}
+}
+
+// expireAccessTokens denies the user's live access tokens after a role change, their clients refresh and get the new
+// roles in their claims. Refresh tokens stay valid
+func (u *authUC) expireAccessTokens(ctx context.Context, userID uuid.UUID) {
+	tokens, err := u.authRepo.GetLiveAccessTokens(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("authUC.expireAccessTokens.GetLiveAccessTokens, UserID: %s, Error: %v", userID, err)
+		return
+	}
+	if err := u.denyAccessTokens(ctx, tokens); err != nil {
+		u.logger.Errorf("authUC.expireAccessTokens.denyAccessTokens, UserID: %s, Error: %v", userID, err)
+	}
+}
+
+// denyAccessTokens puts the access tokens issued with the revoked refresh tokens on the denylist until they expire
+func (u *authUC) denyAccessTokens(ctx context.Context, revoked []*models.RefreshToken) error {
+	now := time.Now()
+	for _, t := range revoked {
+		// The token is still accepted during the second of its exp claim
+		ttl := t.AccessExpiresAt.Sub(now) + time.Second
+		if ttl <= time.Second {
+			continue
+		}
+		if err := u.redisRepo.DenyTokenCtx(ctx, t.AccessTokenID.String(), ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashRefreshToken is the stored form of a refresh token, the tokens are random so a plain SHA-256 is enough
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
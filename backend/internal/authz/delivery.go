@@ -0,0 +1,8 @@
+package authz
+
+import "github.com/labstack/echo/v4"
+
+// Authz HTTP Handlers interface
+type Handlers interface {
+	GetDenials() echo.HandlerFunc
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/authz"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type authzHandlers struct {
+	authzUC authz.UseCase
+	logger  logger.Logger
+}
+
+func NewAuthzHandlers(authzUC authz.UseCase, logger logger.Logger) authz.Handlers {
+	return &authzHandlers{
+		authzUC: authzUC,
+		logger:  logger,
+	}
+}
+
+// GetDenials godoc
+// @Summary List authorization failures
+// @Description List the audited authorization failures, newest first, optionally of a user
+// @Tags Authz
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param limit query int false "Page size (default: 50, max: 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.AuthorizationDenialList
+// @Router /authz/admin/denials [get]
+func (h *authzHandlers) GetDenials() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "authzHandlers.GetDenials.pageParams"))
+		}
+
+		var userID *uuid.UUID
+		if userParam := c.QueryParam("user_id"); userParam != "" {
+			id, err := uuid.Parse(userParam)
+			if err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "authzHandlers.GetDenials.Parse"))
+			}
+			userID = &id
+		}
+
+		list, err := h.authzUC.GetDenials(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "authzHandlers.GetDenials.GetDenials"))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+func pageParams(c echo.Context) (int, int, error) {
+	var err error
+	limit, offset := 0, 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/authz"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Map authz routes
+func MapAuthzRoutes(authzGroup *echo.Group, h authz.Handlers, mw *middleware.MiddlewareManager) {
+	protected := authzGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionAuditRead))
+		{
+			admin.GET("/denials", h.GetDenials())
+		}
+	}
+}
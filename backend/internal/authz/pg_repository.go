@@ -0,0 +1,16 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Authz Repository interface
+type Repository interface {
+	CreateDenial(ctx context.Context, denial *models.AuthorizationDenial) error
+	// Lists the denials newest first, of a user when userID is not nil
+	GetDenials(ctx context.Context, userID *uuid.UUID, limit int, offset int) (*models.AuthorizationDenialList, error)
+}
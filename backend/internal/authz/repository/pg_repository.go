@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/authz"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultDenialsLimit = 50
+
+type authzRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewAuthzRepository(db *sqlx.DB, logger logger.Logger) authz.Repository {
+	return &authzRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *authzRepo) CreateDenial(ctx context.Context, denial *models.AuthorizationDenial) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		createDenialQuery,
+		denial.UserID,
+		denial.Action,
+		denial.ResourceType,
+		denial.ResourceID,
+		denial.Permission,
+	); err != nil {
+		return errors.Wrap(err, "authzRepo.CreateDenial.ExecContext")
+	}
+	return nil
+}
+
+func (r *authzRepo) GetDenials(ctx context.Context, userID *uuid.UUID, limit int, offset int) (*models.AuthorizationDenialList, error) {
+	if limit <= 0 {
+		limit = defaultDenialsLimit
+	}
+
+	list := &models.AuthorizationDenialList{Denials: make([]*models.AuthorizationDenial, 0)}
+	if err := r.db.GetContext(ctx, &list.TotalCount, countDenialsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authzRepo.GetDenials.count")
+	}
+	if list.TotalCount == 0 {
+		return list, nil
+	}
+
+	if err := r.db.SelectContext(ctx, &list.Denials, getDenialsQuery, userID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "authzRepo.GetDenials.SelectContext")
+	}
+
+	return list, nil
+}
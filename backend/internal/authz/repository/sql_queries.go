@@ -0,0 +1,19 @@
+package repository
+
+const (
+	createDenialQuery = `
+		INSERT INTO authorization_denials (user_id, action, resource_type, resource_id, permission)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	countDenialsQuery = `
+		SELECT COUNT(*) FROM authorization_denials WHERE ($1::uuid IS NULL OR user_id = $1)
+	`
+
+	getDenialsQuery = `
+		SELECT * FROM authorization_denials
+		WHERE ($1::uuid IS NULL OR user_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+)
@@ -0,0 +1,21 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Authz UseCase interface
+type UseCase interface {
+	// Allows the owner of the resource and the users granted the permission,
+	// audits and returns httpErrors.PermissionDenied otherwise
+	Authorize(ctx context.Context, req *models.AccessRequest) error
+	// Audits a denial decided elsewhere, like the permission middleware
+	RecordDenial(ctx context.Context, denial *models.AuthorizationDenial)
+
+	// Admin operations
+	GetDenials(ctx context.Context, userID *uuid.UUID, limit int, offset int) (*models.AuthorizationDenialList, error)
+}
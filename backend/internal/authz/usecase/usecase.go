@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/internal/authz"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+const maxDenialsLimit = 100
+
+type authzUC struct {
+	authzRepo authz.Repository
+	logger    logger.Logger
+}
+
+func NewAuthzUseCase(authzRepo authz.Repository, logger logger.Logger) authz.UseCase {
+	return &authzUC{authzRepo: authzRepo, logger: logger}
+}
+
+func (u *authzUC) Authorize(ctx context.Context, req *models.AccessRequest) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authzUC.Authorize")
+	defer span.Finish()
+
+	denial := &models.AuthorizationDenial{
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Permission:   req.Permission,
+	}
+
+	// Anonymous requests reach the reads of the public routes only, they are never the owner
+	user, err := utils.GetUserFromCtx(ctx)
+	if err == nil {
+		if req.OwnerID != uuid.Nil && user.UserID == req.OwnerID {
+			return nil
+		}
+		if req.Permission != "" && user.HasPermission(req.Permission) {
+			return nil
+		}
+		denial.UserID = &user.UserID
+	}
+
+	u.RecordDenial(ctx, denial)
+	return httpErrors.PermissionDenied
+}
+
+func (u *authzUC) RecordDenial(ctx context.Context, denial *models.AuthorizationDenial) {
+	userID := "anonymous"
+	if denial.UserID != nil {
+		userID = denial.UserID.String()
+	}
+	u.logger.Warnf("Authorization denied, UserID: %s, Action: %s, Resource: %s %s, Permission: %s",
+		userID,
+		denial.Action,
+		denial.ResourceType,
+		denial.ResourceID,
+		denial.Permission,
+	)
+
+	// The request is refused either way, a failed audit does not turn the denial into a server error
+	if err := u.authzRepo.CreateDenial(ctx, denial); err != nil {
+		u.logger.Errorf("authzUC.RecordDenial.CreateDenial: %v", err)
+	}
+}
+
+func (u *authzUC) GetDenials(ctx context.Context, userID *uuid.UUID, limit int, offset int) (*models.AuthorizationDenialList, error) {
+	if limit > maxDenialsLimit {
+		limit = maxDenialsLimit
+	}
+	return u.authzRepo.GetDenials(ctx, userID, limit, offset)
+}
@@ -0,0 +1,15 @@
+package challenge
+
+import "github.com/labstack/echo/v4"
+
+// Challenge HTTP Handlers interface
+type Handlers interface {
+	CreateChallenge() echo.HandlerFunc
+	GetChallenges() echo.HandlerFunc
+	GetChallenge() echo.HandlerFunc
+	AcceptChallenge() echo.HandlerFunc
+	DeclineChallenge() echo.HandlerFunc
+	StartChallenge() echo.HandlerFunc
+	SubmitChallenge() echo.HandlerFunc
+	ExpireChallenges() echo.HandlerFunc
+}
@@ -0,0 +1,310 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/challenge"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type challengeHandlers struct {
+	challengeUC challenge.UseCase
+	logger      logger.Logger
+}
+
+func NewChallengeHandlers(challengeUC challenge.UseCase, logger logger.Logger) challenge.Handlers {
+	return &challengeHandlers{
+		challengeUC: challengeUC,
+		logger:      logger,
+	}
+}
+
+// CreateChallenge godoc
+// @Summary Challenge a user on a quiz
+// @Description Draw a question set of the quiz and challenge a user I follow to answer it, they have AcceptWindow hours to accept
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Param body body models.CreateChallengeRequest true "Opponent and quiz"
+// @Success 201 {object} models.Challenge
+// @Failure 403 {object} httpErrors.RestError
+// @Router /challenges [post]
+func (h *challengeHandlers) CreateChallenge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.CreateChallenge.GetUserIDFromContext"))
+		}
+
+		req := &models.CreateChallengeRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.CreateChallenge.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.CreateChallenge.ValidateStruct"))
+		}
+
+		created, err := h.challengeUC.CreateChallenge(c.Request().Context(), userID, req)
+		if err != nil {
+			return challengeError(err, "challengeHandlers.CreateChallenge.CreateChallenge")
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// GetChallenges godoc
+// @Summary List my challenges
+// @Description Challenges I sent or received, newest first
+// @Tags Challenges
+// @Produce json
+// @Param status query string false "pending, active, declined, expired or completed"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.ChallengeList
+// @Router /challenges [get]
+func (h *challengeHandlers) GetChallenges() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.GetChallenges.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.GetChallenges.pageParams"))
+		}
+
+		list, err := h.challengeUC.GetUserChallenges(c.Request().Context(), userID, c.QueryParam("status"), limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.GetChallenges.GetUserChallenges"))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetChallenge godoc
+// @Summary Get a challenge
+// @Description A challenge I take part in, with both players' results
+// @Tags Challenges
+// @Produce json
+// @Param challenge_id path string true "Challenge ID"
+// @Success 200 {object} models.Challenge
+// @Failure 404 {object} httpErrors.RestError
+// @Router /challenges/{challenge_id} [get]
+func (h *challengeHandlers) GetChallenge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.GetChallenge.GetUserIDFromContext"))
+		}
+
+		challengeID, err := uuid.Parse(c.Param("challenge_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.GetChallenge.uuid.Parse"))
+		}
+
+		found, err := h.challengeUC.GetChallenge(c.Request().Context(), userID, challengeID)
+		if err != nil {
+			return challengeError(err, "challengeHandlers.GetChallenge.GetChallenge")
+		}
+
+		return c.JSON(http.StatusOK, found)
+	}
+}
+
+// AcceptChallenge godoc
+// @Summary Accept a challenge
+// @Description Accept a challenge I received, both players then have PlayWindow hours to submit
+// @Tags Challenges
+// @Produce json
+// @Param challenge_id path string true "Challenge ID"
+// @Success 200 {object} models.Challenge
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /challenges/{challenge_id}/accept [post]
+func (h *challengeHandlers) AcceptChallenge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.AcceptChallenge.GetUserIDFromContext"))
+		}
+
+		challengeID, err := uuid.Parse(c.Param("challenge_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.AcceptChallenge.uuid.Parse"))
+		}
+
+		accepted, err := h.challengeUC.AcceptChallenge(c.Request().Context(), userID, challengeID)
+		if err != nil {
+			return challengeError(err, "challengeHandlers.AcceptChallenge.AcceptChallenge")
+		}
+
+		return c.JSON(http.StatusOK, accepted)
+	}
+}
+
+// DeclineChallenge godoc
+// @Summary Decline a challenge
+// @Description Decline a challenge I received
+// @Tags Challenges
+// @Produce json
+// @Param challenge_id path string true "Challenge ID"
+// @Success 200 {object} models.Challenge
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /challenges/{challenge_id}/decline [post]
+func (h *challengeHandlers) DeclineChallenge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.DeclineChallenge.GetUserIDFromContext"))
+		}
+
+		challengeID, err := uuid.Parse(c.Param("challenge_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.DeclineChallenge.uuid.Parse"))
+		}
+
+		declined, err := h.challengeUC.DeclineChallenge(c.Request().Context(), userID, challengeID)
+		if err != nil {
+			return challengeError(err, "challengeHandlers.DeclineChallenge.DeclineChallenge")
+		}
+
+		return c.JSON(http.StatusOK, declined)
+	}
+}
+
+// StartChallenge godoc
+// @Summary Start a challenge
+// @Description Get the questions of an accepted challenge, my time runs from the first call
+// @Tags Challenges
+// @Produce json
+// @Param challenge_id path string true "Challenge ID"
+// @Success 200 {object} models.ChallengeRound
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /challenges/{challenge_id}/start [post]
+func (h *challengeHandlers) StartChallenge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.StartChallenge.GetUserIDFromContext"))
+		}
+
+		challengeID, err := uuid.Parse(c.Param("challenge_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.StartChallenge.uuid.Parse"))
+		}
+
+		round, err := h.challengeUC.StartChallenge(c.Request().Context(), userID, challengeID)
+		if err != nil {
+			return challengeError(err, "challengeHandlers.StartChallenge.StartChallenge")
+		}
+
+		return c.JSON(http.StatusOK, round)
+	}
+}
+
+// SubmitChallenge godoc
+// @Summary Submit my answers to a challenge
+// @Description Grade my answers like a quiz attempt, the challenge is decided once both players submitted:
+// @Description the higher score wins, then the shorter time, and the winner gets the bonus XP
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Param challenge_id path string true "Challenge ID"
+// @Param body body models.ChallengeSubmission true "Answers"
+// @Success 200 {object} models.ChallengeOutcome
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /challenges/{challenge_id}/submit [post]
+func (h *challengeHandlers) SubmitChallenge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "challengeHandlers.SubmitChallenge.GetUserIDFromContext"))
+		}
+
+		challengeID, err := uuid.Parse(c.Param("challenge_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.SubmitChallenge.uuid.Parse"))
+		}
+
+		submission := &models.ChallengeSubmission{}
+		if err := c.Bind(submission); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.SubmitChallenge.Bind"))
+		}
+
+		outcome, err := h.challengeUC.SubmitChallenge(c.Request().Context(), userID, challengeID, submission.Answers)
+		if err != nil {
+			return challengeError(err, "challengeHandlers.SubmitChallenge.SubmitChallenge")
+		}
+
+		return c.JSON(http.StatusOK, outcome)
+	}
+}
+
+// ExpireChallenges godoc
+// @Summary Run the challenge expiry job
+// @Description Expire the challenges whose deadline passed, a player who alone submitted in time wins, the challenge worker runs it on every tick
+// @Tags Challenges
+// @Produce json
+// @Success 200 {object} models.ChallengeJobReport
+// @Router /challenges/admin/expire [post]
+func (h *challengeHandlers) ExpireChallenges() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report, err := h.challengeUC.ExpireChallenges(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "challengeHandlers.ExpireChallenges.ExpireChallenges"))
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+func challengeError(err error, op string) error {
+	switch {
+	case errors.Is(err, challenge.ErrChallengeNotFound):
+		return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, challenge.ErrNotFollowing),
+		errors.Is(err, challenge.ErrNotOpponent),
+		errors.Is(err, httpErrors.PermissionDenied):
+		return httpErrors.NewRestError(http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, challenge.ErrChallengeNotPending),
+		errors.Is(err, challenge.ErrChallengeNotActive),
+		errors.Is(err, challenge.ErrChallengeExpired),
+		errors.Is(err, challenge.ErrNotStarted),
+		errors.Is(err, challenge.ErrAlreadySubmitted):
+		return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+	default:
+		return httpErrors.NewBadRequestError(errors.Wrap(err, op))
+	}
+}
+
+func pageParams(c echo.Context) (int, int, error) {
+	var err error
+	limit, offset := 0, 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
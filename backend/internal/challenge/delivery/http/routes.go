@@ -0,0 +1,30 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/challenge"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Map challenge routes
+func MapChallengeRoutes(challengeGroup *echo.Group, h challenge.Handlers, mw *middleware.MiddlewareManager) {
+	protected := challengeGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.POST("", h.CreateChallenge())
+		protected.GET("", h.GetChallenges())
+		protected.GET("/:challenge_id", h.GetChallenge())
+		protected.POST("/:challenge_id/accept", h.AcceptChallenge())
+		protected.POST("/:challenge_id/decline", h.DeclineChallenge())
+		protected.POST("/:challenge_id/start", h.StartChallenge())
+		protected.POST("/:challenge_id/submit", h.SubmitChallenge())
+
+		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionJobsRun))
+		{
+			admin.POST("/expire", h.ExpireChallenges())
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package challenge
+
+import "errors"
+
+// Challenge errors
+var (
+	ErrChallengeSelf       = errors.New("you cannot challenge yourself")
+	ErrNotFollowing        = errors.New("you can only challenge users you follow")
+	ErrChallengeNotFound   = errors.New("challenge not found")
+	ErrNotOpponent         = errors.New("only the challenged user can answer the challenge")
+	ErrChallengeNotPending = errors.New("challenge is no longer waiting for an answer")
+	ErrChallengeNotActive  = errors.New("challenge is not being played")
+	ErrChallengeExpired    = errors.New("challenge has expired")
+	ErrNotStarted          = errors.New("start the challenge before submitting")
+	ErrAlreadySubmitted    = errors.New("you already submitted this challenge")
+)
@@ -0,0 +1,28 @@
+package challenge
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// UpdateFunc applies a change to a locked challenge and its results, both are saved unless it returns an error
+type UpdateFunc func(challenge *models.Challenge) error
+
+// Challenge Repository interface
+type Repository interface {
+	// Saves the challenge with its questions and an empty result per player
+	CreateChallenge(ctx context.Context, challenge *models.Challenge) (*models.Challenge, error)
+	// The challenge with its questions and results
+	GetChallenge(ctx context.Context, challengeID uuid.UUID) (*models.Challenge, error)
+	// Locks the challenge, applies update and saves the result in a single transaction
+	UpdateChallenge(ctx context.Context, challengeID uuid.UUID, update UpdateFunc) (*models.Challenge, error)
+	// Challenges the user sent or received, newest first, status filters them when set
+	GetUserChallenges(ctx context.Context, userID uuid.UUID, status string, limit int, offset int) (*models.ChallengeList, error)
+
+	// Pending or active challenges whose deadline passed
+	GetEndedChallengeIDs(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+}
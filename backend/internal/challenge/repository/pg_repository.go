@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/challenge"
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultPageSize = 50
+
+type challengeRepo struct {
+	db       *sqlx.DB
+	recorder events.Recorder
+	logger   logger.Logger
+}
+
+func NewChallengeRepository(db *sqlx.DB, recorder events.Recorder, logger logger.Logger) challenge.Repository {
+	return &challengeRepo{
+		db:       db,
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+func (r *challengeRepo) CreateChallenge(ctx context.Context, c *models.Challenge) (*models.Challenge, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.CreateChallenge.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	created := &models.Challenge{}
+	if err := tx.GetContext(
+		ctx,
+		created,
+		createChallengeQuery,
+		c.ChallengerID,
+		c.OpponentID,
+		c.QuizID,
+		c.Status,
+		c.BonusXP,
+		c.ExpiresAt,
+	); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.CreateChallenge.createChallenge")
+	}
+
+	for i, questionID := range c.QuestionIDs {
+		if _, err := tx.ExecContext(ctx, addChallengeQuestionQuery, created.ChallengeID, i, questionID); err != nil {
+			return nil, errors.Wrap(err, "challengeRepo.CreateChallenge.addChallengeQuestion")
+		}
+	}
+	created.QuestionIDs = c.QuestionIDs
+
+	created.Results = make([]*models.ChallengeResult, 0, 2)
+	for _, userID := range []uuid.UUID{created.ChallengerID, created.OpponentID} {
+		if _, err := tx.ExecContext(ctx, addChallengeResultQuery, created.ChallengeID, userID); err != nil {
+			return nil, errors.Wrap(err, "challengeRepo.CreateChallenge.addChallengeResult")
+		}
+		created.Results = append(created.Results, &models.ChallengeResult{ChallengeID: created.ChallengeID, UserID: userID})
+	}
+
+	if err := r.recorder.Record(ctx, tx, created.OpponentID, &events.ChallengeReceived{
+		ChallengeID:  created.ChallengeID,
+		ChallengerID: created.ChallengerID,
+		QuizID:       created.QuizID,
+	}); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.CreateChallenge.Record")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.CreateChallenge.Commit")
+	}
+
+	return created, nil
+}
+
+func (r *challengeRepo) GetChallenge(ctx context.Context, challengeID uuid.UUID) (*models.Challenge, error) {
+	c := &models.Challenge{}
+	if err := r.db.GetContext(ctx, c, getChallengeQuery, challengeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, challenge.ErrChallengeNotFound
+		}
+		return nil, errors.Wrap(err, "challengeRepo.GetChallenge.GetContext")
+	}
+
+	if err := r.loadDetails(ctx, r.db, c); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.GetChallenge.loadDetails")
+	}
+
+	return c, nil
+}
+
+func (r *challengeRepo) UpdateChallenge(ctx context.Context, challengeID uuid.UUID, update challenge.UpdateFunc) (*models.Challenge, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	c := &models.Challenge{}
+	if err := tx.GetContext(ctx, c, lockChallengeQuery, challengeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, challenge.ErrChallengeNotFound
+		}
+		return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.lockChallenge")
+	}
+
+	if err := r.loadDetails(ctx, tx, c); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.loadDetails")
+	}
+
+	status := c.Status
+	if err := update(c); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		updateChallengeQuery,
+		c.ChallengeID,
+		c.Status,
+		c.WinnerID,
+		c.ExpiresAt,
+		c.AcceptedAt,
+		c.CompletedAt,
+	); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.updateChallenge")
+	}
+
+	for _, result := range c.Results {
+		if _, err := tx.ExecContext(
+			ctx,
+			updateChallengeResultQuery,
+			c.ChallengeID,
+			result.UserID,
+			result.StartedAt,
+			result.SubmittedAt,
+			result.AttemptID,
+			result.Score,
+			result.TimeSpent,
+		); err != nil {
+			return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.updateChallengeResult")
+		}
+	}
+
+	// Both players hear of the end of the challenge, each with the other one as opponent
+	if status != c.Status && c.CompletedAt != nil {
+		players := [][2]uuid.UUID{{c.ChallengerID, c.OpponentID}, {c.OpponentID, c.ChallengerID}}
+		for _, pair := range players {
+			if err := r.recorder.Record(ctx, tx, pair[0], &events.ChallengeEnded{
+				ChallengeID: c.ChallengeID,
+				OpponentID:  pair[1],
+				Status:      c.Status,
+				WinnerID:    c.WinnerID,
+			}); err != nil {
+				return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.Record")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.UpdateChallenge.Commit")
+	}
+
+	return c, nil
+}
+
+func (r *challengeRepo) GetUserChallenges(ctx context.Context, userID uuid.UUID, status string, limit int, offset int) (*models.ChallengeList, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countUserChallengesQuery, userID, status); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.GetUserChallenges.GetContext")
+	}
+
+	challenges := make([]*models.Challenge, 0, limit)
+	if err := r.db.SelectContext(ctx, &challenges, getUserChallengesQuery, userID, status, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.GetUserChallenges.SelectContext")
+	}
+
+	for _, c := range challenges {
+		if err := r.loadDetails(ctx, r.db, c); err != nil {
+			return nil, errors.Wrap(err, "challengeRepo.GetUserChallenges.loadDetails")
+		}
+	}
+
+	return &models.ChallengeList{
+		TotalCount: totalCount,
+		Challenges: challenges,
+	}, nil
+}
+
+func (r *challengeRepo) GetEndedChallengeIDs(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+	if err := r.db.SelectContext(ctx, &ids, getEndedChallengeIDsQuery, now, limit); err != nil {
+		return nil, errors.Wrap(err, "challengeRepo.GetEndedChallengeIDs.SelectContext")
+	}
+
+	return ids, nil
+}
+
+// loadDetails loads the question ids and the results of a challenge, the challenger's result first
+func (r *challengeRepo) loadDetails(ctx context.Context, q sqlx.QueryerContext, c *models.Challenge) error {
+	c.QuestionIDs = make([]uuid.UUID, 0)
+	if err := sqlx.SelectContext(ctx, q, &c.QuestionIDs, getChallengeQuestionIDsQuery, c.ChallengeID); err != nil {
+		return errors.Wrap(err, "getChallengeQuestionIDs")
+	}
+
+	c.Results = make([]*models.ChallengeResult, 0, 2)
+	if err := sqlx.SelectContext(ctx, q, &c.Results, getChallengeResultsQuery, c.ChallengeID); err != nil {
+		return errors.Wrap(err, "getChallengeResults")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+const (
+	createChallengeQuery = `
+		INSERT INTO challenges (challenger_id, opponent_id, quiz_id, status, bonus_xp, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+
+	addChallengeQuestionQuery = `
+		INSERT INTO challenge_questions (challenge_id, position, question_id) VALUES ($1, $2, $3)
+	`
+
+	addChallengeResultQuery = `
+		INSERT INTO challenge_results (challenge_id, user_id) VALUES ($1, $2)
+	`
+
+	getChallengeQuery = `
+		SELECT * FROM challenges WHERE challenge_id = $1
+	`
+
+	lockChallengeQuery = `
+		SELECT * FROM challenges WHERE challenge_id = $1 FOR UPDATE
+	`
+
+	getChallengeQuestionIDsQuery = `
+		SELECT question_id FROM challenge_questions WHERE challenge_id = $1 ORDER BY position ASC
+	`
+
+	getChallengeResultsQuery = `
+		SELECT r.* FROM challenge_results r
+		JOIN challenges c ON c.challenge_id = r.challenge_id
+		WHERE r.challenge_id = $1
+		ORDER BY r.user_id = c.challenger_id DESC
+	`
+
+	updateChallengeQuery = `
+		UPDATE challenges
+		SET status = $2, winner_id = $3, expires_at = $4, accepted_at = $5, completed_at = $6
+		WHERE challenge_id = $1
+	`
+
+	updateChallengeResultQuery = `
+		UPDATE challenge_results
+		SET started_at = $3, submitted_at = $4, attempt_id = $5, score = $6, time_spent = $7
+		WHERE challenge_id = $1 AND user_id = $2
+	`
+
+	countUserChallengesQuery = `
+		SELECT COUNT(*) FROM challenges
+		WHERE (challenger_id = $1 OR opponent_id = $1) AND ($2 = '' OR status = $2)
+	`
+
+	getUserChallengesQuery = `
+		SELECT * FROM challenges
+		WHERE (challenger_id = $1 OR opponent_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, challenge_id
+		LIMIT $3 OFFSET $4
+	`
+
+	getEndedChallengeIDsQuery = `
+		SELECT challenge_id FROM challenges
+		WHERE status IN ('pending', 'active') AND expires_at <= $1
+		ORDER BY expires_at ASC
+		LIMIT $2
+	`
+)
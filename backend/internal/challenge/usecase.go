@@ -0,0 +1,27 @@
+package challenge
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Challenge UseCase interface
+type UseCase interface {
+	// Draws the questions and sends the challenge to a followed user
+	CreateChallenge(ctx context.Context, challengerID uuid.UUID, req *models.CreateChallengeRequest) (*models.Challenge, error)
+	GetChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.Challenge, error)
+	GetUserChallenges(ctx context.Context, userID uuid.UUID, status string, limit int, offset int) (*models.ChallengeList, error)
+	AcceptChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.Challenge, error)
+	DeclineChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.Challenge, error)
+
+	// Serves the questions and starts the player's clock, serving them again keeps the clock running
+	StartChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.ChallengeRound, error)
+	// Grades the player's answers, the second submission decides the winner
+	SubmitChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID, answers []*models.UserQuestionResponse) (*models.ChallengeOutcome, error)
+
+	// Expire the challenges whose deadline passed, a player who alone submitted in time wins
+	ExpireChallenges(ctx context.Context) (*models.ChallengeJobReport, error)
+}
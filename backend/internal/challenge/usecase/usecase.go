@@ -0,0 +1,413 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/challenge"
+	"github.com/AleksK1NG/api-mc/internal/chapter"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/social"
+	"github.com/AleksK1NG/api-mc/internal/xp"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	maxPageSize          = 100
+	expireBatchSize      = 100
+	defaultQuestionCount = 10
+	defaultAcceptWindow  = 24 * time.Hour
+	defaultPlayWindow    = 24 * time.Hour
+)
+
+// errNotEnded skips a listed challenge that was answered or settled in the meantime
+var errNotEnded = errors.New("challenge has not ended")
+
+type challengeUC struct {
+	cfg           *config.Config
+	challengeRepo challenge.Repository
+	chapterUC     chapter.UseCase
+	xpUC          xp.UseCase
+	socialUC      social.UseCase
+	questionCount int
+	acceptWindow  time.Duration
+	playWindow    time.Duration
+	logger        logger.Logger
+}
+
+// NewChallengeUseCase creates the challenge use case, the windows are configured in hours
+func NewChallengeUseCase(
+	cfg *config.Config,
+	challengeRepo challenge.Repository,
+	chapterUC chapter.UseCase,
+	xpUC xp.UseCase,
+	socialUC social.UseCase,
+	logger logger.Logger,
+) challenge.UseCase {
+	u := &challengeUC{
+		cfg:           cfg,
+		challengeRepo: challengeRepo,
+		chapterUC:     chapterUC,
+		xpUC:          xpUC,
+		socialUC:      socialUC,
+		questionCount: cfg.Challenge.QuestionCount,
+		acceptWindow:  time.Duration(cfg.Challenge.AcceptWindow) * time.Hour,
+		playWindow:    time.Duration(cfg.Challenge.PlayWindow) * time.Hour,
+		logger:        logger,
+	}
+	if u.questionCount <= 0 {
+		u.questionCount = defaultQuestionCount
+	}
+	if u.acceptWindow <= 0 {
+		u.acceptWindow = defaultAcceptWindow
+	}
+	if u.playWindow <= 0 {
+		u.playWindow = defaultPlayWindow
+	}
+
+	return u
+}
+
+func (u *challengeUC) CreateChallenge(ctx context.Context, challengerID uuid.UUID, req *models.CreateChallengeRequest) (*models.Challenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.CreateChallenge")
+	defer span.Finish()
+
+	if challengerID == req.OpponentID {
+		return nil, challenge.ErrChallengeSelf
+	}
+
+	following, err := u.socialUC.IsFollowing(ctx, challengerID, req.OpponentID)
+	if err != nil {
+		return nil, err
+	}
+	if !following {
+		return nil, challenge.ErrNotFollowing
+	}
+
+	questions, err := u.chapterUC.DrawQuizQuestions(ctx, req.QuizID, u.questionCount)
+	if err != nil {
+		return nil, err
+	}
+
+	questionIDs := make([]uuid.UUID, 0, len(questions))
+	for _, question := range questions {
+		questionIDs = append(questionIDs, question.QuestionID)
+	}
+
+	bonusXP := u.cfg.Challenge.WinnerBonusXP
+	if bonusXP < 0 {
+		bonusXP = 0
+	}
+
+	return u.challengeRepo.CreateChallenge(ctx, &models.Challenge{
+		ChallengerID: challengerID,
+		OpponentID:   req.OpponentID,
+		QuizID:       req.QuizID,
+		Status:       models.ChallengeStatusPending,
+		BonusXP:      bonusXP,
+		ExpiresAt:    time.Now().Add(u.acceptWindow),
+		QuestionIDs:  questionIDs,
+	})
+}
+
+func (u *challengeUC) GetChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.Challenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.GetChallenge")
+	defer span.Finish()
+
+	c, err := u.challengeRepo.GetChallenge(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if resultOf(c, userID) == nil {
+		return nil, challenge.ErrChallengeNotFound
+	}
+
+	return c, nil
+}
+
+func (u *challengeUC) GetUserChallenges(ctx context.Context, userID uuid.UUID, status string, limit int, offset int) (*models.ChallengeList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.GetUserChallenges")
+	defer span.Finish()
+
+	if limit > maxPageSize {
+		return nil, errors.Errorf("limit must not exceed %d", maxPageSize)
+	}
+	if offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	return u.challengeRepo.GetUserChallenges(ctx, userID, status, limit, offset)
+}
+
+func (u *challengeUC) AcceptChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.Challenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.AcceptChallenge")
+	defer span.Finish()
+
+	now := time.Now()
+	return u.challengeRepo.UpdateChallenge(ctx, challengeID, func(c *models.Challenge) error {
+		if err := answerable(c, userID, now); err != nil {
+			return err
+		}
+
+		c.Status = models.ChallengeStatusActive
+		c.AcceptedAt = &now
+		c.ExpiresAt = now.Add(u.playWindow)
+		return nil
+	})
+}
+
+func (u *challengeUC) DeclineChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.Challenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.DeclineChallenge")
+	defer span.Finish()
+
+	now := time.Now()
+	return u.challengeRepo.UpdateChallenge(ctx, challengeID, func(c *models.Challenge) error {
+		if err := answerable(c, userID, now); err != nil {
+			return err
+		}
+
+		c.Status = models.ChallengeStatusDeclined
+		c.CompletedAt = &now
+		return nil
+	})
+}
+
+func (u *challengeUC) StartChallenge(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID) (*models.ChallengeRound, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.StartChallenge")
+	defer span.Finish()
+
+	now := time.Now()
+	c, err := u.challengeRepo.UpdateChallenge(ctx, challengeID, func(c *models.Challenge) error {
+		result, err := playable(c, userID, now)
+		if err != nil {
+			return err
+		}
+
+		if result.StartedAt == nil {
+			result.StartedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := u.chapterUC.GetQuestionSet(ctx, c.QuizID, c.QuestionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	round := &models.ChallengeRound{
+		Challenge: c,
+		Questions: make([]*models.Question, 0, len(questions)),
+	}
+	for _, question := range questions {
+		served := *question
+		served.Answer = ""
+		served.Explanation = ""
+		round.Questions = append(round.Questions, &served)
+	}
+
+	return round, nil
+}
+
+func (u *challengeUC) SubmitChallenge(
+	ctx context.Context,
+	userID uuid.UUID,
+	challengeID uuid.UUID,
+	answers []*models.UserQuestionResponse,
+) (*models.ChallengeOutcome, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.SubmitChallenge")
+	defer span.Finish()
+
+	now := time.Now()
+	var attempt *models.UserQuizAttempt
+	c, err := u.challengeRepo.UpdateChallenge(ctx, challengeID, func(c *models.Challenge) error {
+		// The submission is claimed under the lock before it is graded, a concurrent submission of the same
+		// player waits for it and is refused. The deadline is the time of the submission rather than the end of the grading.
+		result, err := playable(c, userID, now)
+		if err != nil {
+			return err
+		}
+		if result.StartedAt == nil {
+			return challenge.ErrNotStarted
+		}
+
+		// Graded like a quiz attempt, with the time from the moment the questions were served
+		timeSpent := int(now.Sub(*result.StartedAt).Seconds())
+		attempt, err = u.chapterUC.SubmitQuestionSetAnswers(ctx, userID, c.QuizID, c.QuestionIDs, answers, timeSpent)
+		if err != nil {
+			return err
+		}
+
+		result.SubmittedAt = &now
+		result.AttemptID = &attempt.AttemptID
+		result.Score = &attempt.Score
+		result.TimeSpent = &attempt.TimeSpent
+
+		for _, r := range c.Results {
+			if r.SubmittedAt == nil {
+				return nil
+			}
+		}
+		settle(c, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &models.ChallengeOutcome{
+		Challenge: c,
+		Attempt:   attempt,
+	}
+	if c.Status == models.ChallengeStatusCompleted && c.WinnerID != nil {
+		award := u.awardWinner(ctx, c)
+		if *c.WinnerID == userID {
+			outcome.BonusXP = award
+		}
+	}
+
+	return outcome, nil
+}
+
+func (u *challengeUC) ExpireChallenges(ctx context.Context) (*models.ChallengeJobReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "challengeUC.ExpireChallenges")
+	defer span.Finish()
+
+	report := &models.ChallengeJobReport{}
+
+	for {
+		now := time.Now()
+		ids, err := u.challengeRepo.GetEndedChallengeIDs(ctx, now, expireBatchSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, challengeID := range ids {
+			c, err := u.challengeRepo.UpdateChallenge(ctx, challengeID, func(c *models.Challenge) error {
+				open := c.Status == models.ChallengeStatusPending || c.Status == models.ChallengeStatusActive
+				if !open || c.ExpiresAt.After(now) {
+					// Answered or settled by a request since it was listed
+					return errNotEnded
+				}
+
+				if c.Status == models.ChallengeStatusActive {
+					for _, r := range c.Results {
+						if r.SubmittedAt != nil {
+							settle(c, now)
+							return nil
+						}
+					}
+				}
+
+				c.Status = models.ChallengeStatusExpired
+				c.CompletedAt = &now
+				return nil
+			})
+			if err != nil {
+				if errors.Is(err, errNotEnded) {
+					continue
+				}
+				return report, err
+			}
+
+			if c.Status == models.ChallengeStatusCompleted {
+				report.Forfeited++
+				u.awardWinner(ctx, c)
+				continue
+			}
+			report.Expired++
+		}
+
+		if len(ids) < expireBatchSize {
+			break
+		}
+	}
+
+	return report, nil
+}
+
+// awardWinner awards the bonus XP of a completed challenge to its winner.
+// The challenge is settled either way, the ledger is keyed on the challenge so the award can be replayed
+func (u *challengeUC) awardWinner(ctx context.Context, c *models.Challenge) *models.XPAward {
+	award, err := u.xpUC.AwardChallengeWin(ctx, *c.WinnerID, c.ChallengeID, c.QuizID, c.BonusXP)
+	if err != nil {
+		u.logger.Errorf("failed to award challenge %s bonus XP to %s: %v", c.ChallengeID, *c.WinnerID, err)
+		return nil
+	}
+	return award
+}
+
+// settle completes the challenge and declares the winner among the players who submitted:
+// the higher score wins, then the shorter time, a tie on both is a draw
+func settle(c *models.Challenge, now time.Time) {
+	var winner, runnerUp *models.ChallengeResult
+	for _, r := range c.Results {
+		if r.SubmittedAt == nil {
+			continue
+		}
+		switch {
+		case winner == nil || *r.Score > *winner.Score || *r.Score == *winner.Score && *r.TimeSpent < *winner.TimeSpent:
+			winner, runnerUp = r, winner
+		case runnerUp == nil || *r.Score > *runnerUp.Score || *r.Score == *runnerUp.Score && *r.TimeSpent < *runnerUp.TimeSpent:
+			runnerUp = r
+		}
+	}
+
+	c.Status = models.ChallengeStatusCompleted
+	c.CompletedAt = &now
+	c.WinnerID = nil
+	if winner != nil && (runnerUp == nil || *winner.Score != *runnerUp.Score || *winner.TimeSpent != *runnerUp.TimeSpent) {
+		c.WinnerID = &winner.UserID
+	}
+}
+
+// answerable checks that the user can accept or decline the challenge
+func answerable(c *models.Challenge, userID uuid.UUID, now time.Time) error {
+	if resultOf(c, userID) == nil {
+		return challenge.ErrChallengeNotFound
+	}
+	if c.OpponentID != userID {
+		return challenge.ErrNotOpponent
+	}
+	if c.Status != models.ChallengeStatusPending {
+		return challenge.ErrChallengeNotPending
+	}
+	if !now.Before(c.ExpiresAt) {
+		return challenge.ErrChallengeExpired
+	}
+	return nil
+}
+
+// playable checks that the user can play the challenge and returns their result
+func playable(c *models.Challenge, userID uuid.UUID, now time.Time) (*models.ChallengeResult, error) {
+	result := resultOf(c, userID)
+	if result == nil {
+		return nil, challenge.ErrChallengeNotFound
+	}
+	if c.Status != models.ChallengeStatusActive {
+		return nil, challenge.ErrChallengeNotActive
+	}
+	if !now.Before(c.ExpiresAt) {
+		return nil, challenge.ErrChallengeExpired
+	}
+	if result.SubmittedAt != nil {
+		return nil, challenge.ErrAlreadySubmitted
+	}
+	return result, nil
+}
+
+func resultOf(c *models.Challenge, userID uuid.UUID) *models.ChallengeResult {
+	for _, r := range c.Results {
+		if r.UserID == userID {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/challenge"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultInterval = 5 * time.Minute
+
+// ChallengeWorker expires the challenges whose deadline passed and settles the forfeited ones
+type ChallengeWorker struct {
+	challengeUC challenge.UseCase
+	logger      logger.Logger
+	interval    time.Duration
+	stopCh      chan struct{}
+}
+
+// NewChallengeWorker creates a new challenge expiry worker, interval is in minutes
+func NewChallengeWorker(challengeUC challenge.UseCase, interval time.Duration, logger logger.Logger) *ChallengeWorker {
+	if interval <= 0 {
+		interval = defaultInterval
+	} else {
+		interval = interval * time.Minute
+	}
+
+	return &ChallengeWorker{
+		challengeUC: challengeUC,
+		logger:      logger,
+		interval:    interval,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start begins the periodic challenge expiry
+func (w *ChallengeWorker) Start() {
+	w.logger.Info("Starting challenge worker")
+
+	// Run immediately on startup
+	go w.expireChallenges()
+
+	// Then run periodically
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				go w.expireChallenges()
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic challenge expiry
+func (w *ChallengeWorker) Stop() {
+	w.logger.Info("Stopping challenge worker")
+	close(w.stopCh)
+}
+
+// expireChallenges triggers the challenge expiry
+func (w *ChallengeWorker) expireChallenges() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := w.challengeUC.ExpireChallenges(ctx)
+	if err != nil {
+		w.logger.Errorf("Error expiring challenges: %v", err)
+		return
+	}
+
+	w.logger.Infof("Challenges expired: %d, won by forfeit: %d", report.Expired, report.Forfeited)
+}
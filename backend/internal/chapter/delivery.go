@@ -23,9 +23,24 @@ type Handlers interface {
 	CreateCustomLesson() echo.HandlerFunc
 	GetLessonByID() echo.HandlerFunc
 
+	// Lesson progress
+	StartLesson() echo.HandlerFunc
+	RecordLessonHeartbeat() echo.HandlerFunc
+	CompleteLesson() echo.HandlerFunc
+	GetChapterProgress() echo.HandlerFunc
+
 	// Quiz Management
 	GetQuizByID() echo.HandlerFunc
 	GetQuizzesByChapter() echo.HandlerFunc
 	SubmitQuizAnswers() echo.HandlerFunc
 	GetQuestionsByQuizID() echo.HandlerFunc
+
+	// Quiz import/export
+	ImportQuiz() echo.HandlerFunc
+	ExportQuiz() echo.HandlerFunc
+
+	// Adaptive quizzes
+	StartAdaptiveQuiz() echo.HandlerFunc
+	SubmitAdaptiveAnswer() echo.HandlerFunc
+	GetAdaptiveSession() echo.HandlerFunc
 }
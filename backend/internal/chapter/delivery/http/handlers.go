@@ -2,10 +2,14 @@ package http
 
 import (
 	"bytes"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -13,9 +17,12 @@ import (
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/chapter"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/middleware"
+	"github.com/AleksK1NG/api-mc/pkg/quizformat"
 	"github.com/AleksK1NG/api-mc/pkg/response"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 // Chapter handlers
@@ -76,9 +83,22 @@ func (h *chapterHandlers) GetChapterByID() echo.HandlerFunc {
 
 		chapter, err := h.chapterUC.GetChapterByID(c.Request().Context(), chapterID)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusNotFound, "Chapter not found")
 		}
 
+		// Completion is only known for signed in users
+		if user, ok := c.Get("user").(*models.User); ok {
+			progress, err := h.chapterUC.GetChapterProgress(c.Request().Context(), user.UserID, chapterID)
+			if err != nil {
+				h.logger.Errorf("failed to get chapter progress: %v", err)
+			} else {
+				chapter.Progress = progress
+			}
+		}
+
 		return c.JSON(http.StatusOK, chapter)
 	}
 }
@@ -121,7 +141,7 @@ func (h *chapterHandlers) GetChaptersBySubject() echo.HandlerFunc {
 
 // UpdateChapter godoc
 // @Summary Update chapter
-// @Description Update chapter details
+// @Description Update chapter details, by its owner or a user granted the chapters:manage permission
 // @Tags Chapters
 // @Accept json
 // @Produce json
@@ -144,7 +164,7 @@ func (h *chapterHandlers) UpdateChapter() echo.HandlerFunc {
 
 		updatedChapter, err := h.chapterUC.UpdateChapter(c.Request().Context(), chapter)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			return chapterError(err)
 		}
 
 		return c.JSON(http.StatusOK, updatedChapter)
@@ -153,12 +173,13 @@ func (h *chapterHandlers) UpdateChapter() echo.HandlerFunc {
 
 // DeleteChapter godoc
 // @Summary Delete chapter
-// @Description Delete a chapter by ID
+// @Description Delete a chapter by ID, by its owner or a user granted the chapters:manage permission
 // @Tags Chapters
 // @Accept json
 // @Produce json
 // @Param id path string true "Chapter ID"
 // @Success 204 "No Content"
+// @Failure 403 {object} httpErrors.RestError
 // @Router /chapters/{id} [delete]
 func (h *chapterHandlers) DeleteChapter() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -168,7 +189,7 @@ func (h *chapterHandlers) DeleteChapter() echo.HandlerFunc {
 		}
 
 		if err := h.chapterUC.DeleteChapter(c.Request().Context(), chapterID); err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return chapterError(err)
 		}
 
 		return c.NoContent(http.StatusNoContent)
@@ -262,7 +283,7 @@ func (h *chapterHandlers) GenerateMemesForChapter() echo.HandlerFunc {
 
 		memes, err := h.chapterUC.GenerateMemesForChapter(c.Request().Context(), chapterID, input.Topic)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return chapterError(err)
 		}
 
 		return c.JSON(http.StatusCreated, memes)
@@ -271,7 +292,7 @@ func (h *chapterHandlers) GenerateMemesForChapter() echo.HandlerFunc {
 
 // GenerateQuizForChapter godoc
 // @Summary Generate quiz for chapter
-// @Description Generate a quiz for a chapter using AI
+// @Description Generate a quiz for a chapter using AI, by its owner or a user granted the chapters:manage permission
 // @Tags AI Generation
 // @Accept json
 // @Produce json
@@ -287,7 +308,7 @@ func (h *chapterHandlers) GenerateQuizForChapter() echo.HandlerFunc {
 
 		quiz, err := h.chapterUC.GenerateQuizForChapter(c.Request().Context(), chapterID)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return chapterError(err)
 		}
 
 		return c.JSON(http.StatusCreated, quiz)
@@ -374,6 +395,9 @@ func (h *chapterHandlers) GetLessonByID() echo.HandlerFunc {
 
 		lesson, err := h.chapterUC.GetLessonByID(c.Request().Context(), lessonID)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
 			return echo.NewHTTPError(http.StatusNotFound, "Lesson not found")
 		}
 
@@ -381,6 +405,152 @@ func (h *chapterHandlers) GetLessonByID() echo.HandlerFunc {
 	}
 }
 
+// StartLesson handles the request to start or reopen a lesson
+func (h *chapterHandlers) StartLesson() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		lessonID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid lesson ID format"))
+		}
+
+		progress, err := h.chapterUC.StartLesson(ctx, userID, lessonID)
+		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			h.logger.Errorf("failed to start lesson: %v", err)
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to start lesson"))
+		}
+
+		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
+			"lesson_progress": progress,
+		}))
+	}
+}
+
+// RecordLessonHeartbeat handles the periodic reading time report of an open lesson
+func (h *chapterHandlers) RecordLessonHeartbeat() echo.HandlerFunc {
+	type HeartbeatRequest struct {
+		Seconds int `json:"seconds"` // reading time since the previous heartbeat
+	}
+
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		lessonID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid lesson ID format"))
+		}
+
+		var req HeartbeatRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid request body"))
+		}
+		if req.Seconds <= 0 {
+			return c.JSON(http.StatusBadRequest, response.Error("seconds must be positive"))
+		}
+
+		heartbeat, err := h.chapterUC.RecordLessonHeartbeat(ctx, userID, lessonID, req.Seconds)
+		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			if errors.Is(err, chapter.ErrLessonNotStarted) {
+				return c.JSON(http.StatusConflict, response.Error(err.Error()))
+			}
+			h.logger.Errorf("failed to record lesson heartbeat: %v", err)
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to record lesson heartbeat"))
+		}
+
+		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
+			"lesson_progress":  heartbeat.Progress,
+			"reported_seconds": heartbeat.ReportedSeconds,
+			"credited_seconds": heartbeat.CreditedSeconds,
+		}))
+	}
+}
+
+// CompleteLesson handles the request to mark a lesson as completed by the authenticated user
+func (h *chapterHandlers) CompleteLesson() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		lessonID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid lesson ID format"))
+		}
+
+		progress, award, err := h.chapterUC.CompleteLesson(ctx, userID, lessonID)
+		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			if errors.Is(err, chapter.ErrLessonNotStarted) {
+				return c.JSON(http.StatusConflict, response.Error(err.Error()))
+			}
+			if errors.Is(err, chapter.ErrNotEnoughReadingTime) {
+				return c.JSON(http.StatusUnprocessableEntity, response.Error(err.Error()))
+			}
+			h.logger.Errorf("failed to complete lesson: %v", err)
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to complete lesson"))
+		}
+
+		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
+			"lesson_progress": progress,
+			"xp_award":        award,
+			"streak":          progress.Streak,
+		}))
+	}
+}
+
+// GetChapterProgress handles the request for the authenticated user's completion of a chapter
+func (h *chapterHandlers) GetChapterProgress() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		chapterID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid chapter ID format"))
+		}
+
+		progress, err := h.chapterUC.GetChapterProgress(ctx, userID, chapterID)
+		if err != nil {
+			h.logger.Errorf("failed to get chapter progress: %v", err)
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to get chapter progress"))
+		}
+
+		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
+			"progress": progress,
+		}))
+	}
+}
+
 // GetCustomLessonsByChapter godoc
 // @Summary Get custom lessons by chapter ID
 // @Description Get all custom lessons for a specific chapter
@@ -399,7 +569,7 @@ func (h *chapterHandlers) GetCustomLessonsByChapter() echo.HandlerFunc {
 
 		lessons, err := h.chapterUC.GetCustomLessonsByChapter(c.Request().Context(), chapterID)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return chapterError(err)
 		}
 
 		return c.JSON(http.StatusOK, lessons)
@@ -408,7 +578,7 @@ func (h *chapterHandlers) GetCustomLessonsByChapter() echo.HandlerFunc {
 
 // CreateCustomLesson godoc
 // @Summary Create custom lesson
-// @Description Create a custom lesson for a specific chapter
+// @Description Create a custom lesson for a chapter, by the owner of the chapter or a user granted the chapters:manage permission
 // @Tags Chapters
 // @Accept json
 // @Produce json
@@ -436,7 +606,7 @@ func (h *chapterHandlers) CreateCustomLesson() echo.HandlerFunc {
 
 		createdLesson, err := h.chapterUC.CreateCustomLesson(c.Request().Context(), lesson, user.UserID)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return chapterError(err)
 		}
 
 		return c.JSON(http.StatusCreated, createdLesson)
@@ -463,6 +633,9 @@ func (h *chapterHandlers) GetQuizByID() echo.HandlerFunc {
 		quiz, questions, err := h.chapterUC.GetQuizByID(ctx, quizID)
 		if err != nil {
 			h.logger.Errorf("failed to get quiz: %v", err)
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
 			return c.JSON(http.StatusInternalServerError, response.Error("failed to get quiz "+err.Error()))
 		}
 
@@ -502,6 +675,9 @@ func (h *chapterHandlers) GetQuizzesByChapter() echo.HandlerFunc {
 		quizzes, err := h.chapterUC.GetQuizzesByChapterID(ctx, chapterID)
 		if err != nil {
 			h.logger.Errorf("failed to get quizzes for chapter: %v", err)
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
 			return c.JSON(http.StatusInternalServerError, response.Error("failed to get quizzes "+err.Error()))
 		}
 
@@ -517,6 +693,7 @@ func (h *chapterHandlers) SubmitQuizAnswers() echo.HandlerFunc {
 	type QuestionAnswer struct {
 		QuestionID string `json:"question_id"`
 		Answer     string `json:"answer"`
+		TimeSpent  *int   `json:"time_spent"` // optional, in seconds
 	}
 
 	type SubmitQuizRequest struct {
@@ -561,23 +738,33 @@ func (h *chapterHandlers) SubmitQuizAnswers() echo.HandlerFunc {
 				return c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("invalid question_id format: %s", ans.QuestionID)))
 			}
 
+			if ans.TimeSpent != nil && *ans.TimeSpent < 0 {
+				return c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("invalid time_spent for question: %s", ans.QuestionID)))
+			}
+
 			userAnswers = append(userAnswers, &models.UserQuestionResponse{
 				QuestionID: questionID,
 				UserAnswer: ans.Answer,
+				TimeSpent:  ans.TimeSpent,
 			})
 		}
 
 		// Submit the answers
 		attempt, err := h.chapterUC.SubmitQuizAnswers(ctx, userID, quizID, userAnswers)
 		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
 			h.logger.Errorf("failed to submit quiz answers: %v", err)
 			return c.JSON(http.StatusInternalServerError, response.Error("failed to submit quiz answers"))
 		}
 
 		// Return the result
 		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
-			"attempt": attempt,
-			"score":   attempt.Score,
+			"attempt":  attempt,
+			"score":    attempt.Score,
+			"xp_award": attempt.XPAward,
+			"streak":   attempt.Streak,
 		}))
 	}
 }
@@ -602,6 +789,9 @@ func (h *chapterHandlers) GetQuestionsByQuizID() echo.HandlerFunc {
 		questions, err := h.chapterUC.GetQuestionsByQuizID(ctx, quizID)
 		if err != nil {
 			h.logger.Errorf("failed to get questions for quiz: %v", err)
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
 			return c.JSON(http.StatusInternalServerError, response.Error("failed to get questions for quiz: "+err.Error()))
 		}
 
@@ -611,3 +801,303 @@ func (h *chapterHandlers) GetQuestionsByQuizID() echo.HandlerFunc {
 		}))
 	}
 }
+
+// StartAdaptiveQuiz handles the request to start an adaptive session for a quiz
+func (h *chapterHandlers) StartAdaptiveQuiz() echo.HandlerFunc {
+	type StartAdaptiveQuizRequest struct {
+		MaxQuestions int `json:"max_questions"`
+	}
+
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		quizID, err := uuid.Parse(c.Param("quiz_id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid quiz_id format"))
+		}
+
+		// Body is optional, max_questions falls back to the default
+		var req StartAdaptiveQuizRequest
+		if c.Request().ContentLength > 0 {
+			if err := c.Bind(&req); err != nil {
+				return c.JSON(http.StatusBadRequest, response.Error("invalid request body"))
+			}
+		}
+
+		step, err := h.chapterUC.StartAdaptiveQuiz(ctx, userID, quizID, req.MaxQuestions)
+		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			h.logger.Errorf("failed to start adaptive quiz: %v", err)
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to start adaptive quiz: "+err.Error()))
+		}
+
+		return c.JSON(http.StatusCreated, response.Success(map[string]interface{}{
+			"session":       step.Session,
+			"next_question": step.NextQuestion,
+		}))
+	}
+}
+
+// SubmitAdaptiveAnswer handles the request to answer the current question of an adaptive session
+func (h *chapterHandlers) SubmitAdaptiveAnswer() echo.HandlerFunc {
+	type SubmitAdaptiveAnswerRequest struct {
+		QuestionID string `json:"question_id"`
+		Answer     string `json:"answer"`
+	}
+
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		sessionID, err := uuid.Parse(c.Param("session_id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid session_id format"))
+		}
+
+		var req SubmitAdaptiveAnswerRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid request body"))
+		}
+		if req.Answer == "" {
+			return c.JSON(http.StatusBadRequest, response.Error("answer is required"))
+		}
+
+		questionID, err := uuid.Parse(req.QuestionID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid question_id format"))
+		}
+
+		step, err := h.chapterUC.SubmitAdaptiveAnswer(ctx, userID, sessionID, questionID, req.Answer)
+		if err != nil {
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			h.logger.Errorf("failed to submit adaptive answer: %v", err)
+			return c.JSON(http.StatusBadRequest, response.Error("failed to submit adaptive answer: "+err.Error()))
+		}
+
+		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
+			"session":       step.Session,
+			"is_correct":    step.LastAnswer.IsCorrect,
+			"explanation":   step.Explanation,
+			"ability":       step.Session.Ability,
+			"next_question": step.NextQuestion,
+			"attempt":       step.Attempt,
+		}))
+	}
+}
+
+// GetAdaptiveSession handles the request to resume an adaptive session
+func (h *chapterHandlers) GetAdaptiveSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// Get user ID from context
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, response.Error("unauthorized"))
+		}
+
+		sessionID, err := uuid.Parse(c.Param("session_id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid session_id format"))
+		}
+
+		step, err := h.chapterUC.GetAdaptiveSession(ctx, userID, sessionID)
+		if err != nil {
+			h.logger.Errorf("failed to get adaptive session: %v", err)
+			return c.JSON(http.StatusNotFound, response.Error("adaptive session not found"))
+		}
+
+		return c.JSON(http.StatusOK, response.Success(map[string]interface{}{
+			"session":       step.Session,
+			"next_question": step.NextQuestion,
+		}))
+	}
+}
+
+// ImportQuiz handles the request to create a quiz for a lesson from a GIFT, QTI 2.1 or CSV file
+// @Summary Import quiz
+// @Description Import a quiz from a GIFT, QTI 2.1 (single item or zipped package) or CSV file, with a validation report
+// @Tags Quizzes
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Lesson ID"
+// @Param file formData file true "Quiz file"
+// @Param format formData string false "gift, qti or csv, inferred from the file name when empty"
+// @Param dry_run formData bool false "Validate without saving"
+// @Param skip_invalid formData bool false "Import the valid questions even when others have errors"
+// @Router /chapters/lessons/{id}/quizzes/import [post]
+func (h *chapterHandlers) ImportQuiz() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		lessonID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid lesson id format"))
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("file is required"))
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to open uploaded file"))
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to read uploaded file"))
+		}
+
+		req := &models.QuizImportRequest{
+			LessonID:    lessonID,
+			Format:      c.FormValue("format"),
+			Title:       c.FormValue("title"),
+			Description: c.FormValue("description"),
+		}
+		if req.Format == "" {
+			req.Format = formatFromFilename(file.Filename)
+		}
+		if req.DryRun, err = formBool(c, "dry_run"); err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid dry_run value"))
+		}
+		if req.SkipInvalid, err = formBool(c, "skip_invalid"); err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid skip_invalid value"))
+		}
+		if timeLimit := c.FormValue("time_limit"); timeLimit != "" {
+			limit, err := strconv.Atoi(timeLimit)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, response.Error("invalid time_limit value"))
+			}
+			req.TimeLimit = &limit
+		}
+
+		if err := utils.ValidateStruct(ctx, req); err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid import request: "+err.Error()))
+		}
+
+		result, err := h.chapterUC.ImportQuiz(ctx, req, data)
+		if err != nil {
+			h.logger.Errorf("failed to import quiz: %v", err)
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			if result != nil {
+				return c.JSON(http.StatusUnprocessableEntity, response.ErrorWithData(err.Error(), result))
+			}
+			return c.JSON(http.StatusBadRequest, response.Error("failed to import quiz: "+err.Error()))
+		}
+
+		status := http.StatusCreated
+		if req.DryRun {
+			status = http.StatusOK
+		}
+		return c.JSON(status, response.Success(map[string]interface{}{
+			"quiz":      result.Quiz,
+			"questions": result.Questions,
+			"report":    result.Report,
+		}))
+	}
+}
+
+// ExportQuiz handles the request to download a quiz as a GIFT, QTI 2.1 or CSV file
+// @Summary Export quiz
+// @Tags Quizzes
+// @Produce octet-stream
+// @Param quiz_id path string true "Quiz ID"
+// @Param format query string true "gift, qti or csv"
+// @Router /chapters/quizzes/{quiz_id}/export [get]
+func (h *chapterHandlers) ExportQuiz() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		quizID, err := uuid.Parse(c.Param("quiz_id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error("invalid quiz_id format"))
+		}
+
+		format, err := quizformat.ParseFormat(c.QueryParam("format"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.Error(err.Error()))
+		}
+
+		data, quiz, err := h.chapterUC.ExportQuiz(ctx, quizID, string(format))
+		if err != nil {
+			h.logger.Errorf("failed to export quiz: %v", err)
+			if errors.Is(err, httpErrors.PermissionDenied) {
+				return c.JSON(http.StatusForbidden, response.Error(err.Error()))
+			}
+			return c.JSON(http.StatusInternalServerError, response.Error("failed to export quiz: "+err.Error()))
+		}
+
+		filename := fmt.Sprintf("%s.%s", exportFilename(quiz.Title), format.Extension())
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return c.Blob(http.StatusOK, format.ContentType(), data)
+	}
+}
+
+// chapterError maps the errors of the chapter management to their status
+func chapterError(err error) error {
+	switch {
+	case errors.Is(err, httpErrors.PermissionDenied):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		return echo.NewHTTPError(http.StatusNotFound, "Chapter not found")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func formatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return string(quizformat.CSV)
+	case ".zip", ".xml":
+		return string(quizformat.QTI)
+	case ".gift", ".txt":
+		return string(quizformat.GIFT)
+	}
+	return ""
+}
+
+func formBool(c echo.Context, name string) (bool, error) {
+	value := c.FormValue(name)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// exportFilename keeps only characters that are safe in a Content-Disposition header
+func exportFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, title)
+	if name == "" {
+		return "quiz"
+	}
+	return name
+}
@@ -11,11 +11,11 @@ import (
 func MapChapterRoutes(chapterGroup *echo.Group, h chapter.Handlers, mw *middleware.MiddlewareManager) {
 	// Public routes
 	chapterGroup.GET("", h.GetChaptersBySubject())
-	chapterGroup.GET("/:id", h.GetChapterByID())
+	chapterGroup.GET("/:id", h.GetChapterByID(), mw.OptionalAuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
 
 	// Lesson routes
 	lessonGroup := chapterGroup.Group("/lessons")
-	lessonGroup.GET("/:id", h.GetLessonByID())
+	lessonGroup.GET("/:id", h.GetLessonByID(), mw.OptionalAuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
 
 	// Protected routes (require authentication)
 	protected := chapterGroup.Group("")
@@ -37,10 +37,25 @@ func MapChapterRoutes(chapterGroup *echo.Group, h chapter.Handlers, mw *middlewa
 		protected.GET("/:id/custom-lessons", h.GetCustomLessonsByChapter())
 		protected.POST("/:id/custom-lessons", h.CreateCustomLesson())
 
+		// Lesson progress
+		protected.POST("/lessons/:id/start", h.StartLesson())
+		protected.POST("/lessons/:id/heartbeat", h.RecordLessonHeartbeat())
+		protected.POST("/lessons/:id/complete", h.CompleteLesson())
+		protected.GET("/:id/progress", h.GetChapterProgress())
+
 		// Quiz management
 		protected.GET("/quizzes/:quiz_id", h.GetQuizByID())
 		protected.GET("/:id/quizzes", h.GetQuizzesByChapter())
 		protected.POST("/quizzes/submit", h.SubmitQuizAnswers())
 		protected.GET("/quizzes/:quiz_id/questions", h.GetQuestionsByQuizID())
+
+		// Quiz import/export
+		protected.POST("/lessons/:id/quizzes/import", h.ImportQuiz())
+		protected.GET("/quizzes/:quiz_id/export", h.ExportQuiz())
+
+		// Adaptive quizzes
+		protected.POST("/quizzes/:quiz_id/adaptive", h.StartAdaptiveQuiz())
+		protected.POST("/quizzes/adaptive/:session_id/answer", h.SubmitAdaptiveAnswer())
+		protected.GET("/quizzes/adaptive/:session_id", h.GetAdaptiveSession())
 	}
 }
@@ -0,0 +1,9 @@
+package chapter
+
+import "errors"
+
+// Lesson progress errors
+var (
+	ErrLessonNotStarted     = errors.New("lesson has not been started")
+	ErrNotEnoughReadingTime = errors.New("not enough reading time to complete the lesson")
+)
@@ -2,8 +2,10 @@ package chapter
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
@@ -23,6 +25,13 @@ type Repository interface {
 	CreateCustomLesson(ctx context.Context, lesson *models.Lesson) error
 	GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error)
 
+	// Lesson progress
+	StartLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID, startedAt time.Time) (*models.LessonProgress, error)
+	GetLessonProgress(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error)
+	RecordLessonHeartbeat(ctx context.Context, progress *models.LessonProgress, seconds int, at time.Time) (*models.LessonProgress, error)
+	CompleteLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error)
+	GetChapterProgress(ctx context.Context, userID uuid.UUID, chapterID uuid.UUID) (*models.ChapterProgress, error)
+
 	// Media operations
 	CreateLessonMedia(ctx context.Context, media *models.LessonMedia) error
 	GetLessonMediaByChapter(ctx context.Context, chapterID uuid.UUID) ([]*models.LessonMedia, error)
@@ -36,9 +45,20 @@ type Repository interface {
 	GetQuestionsByQuizID(ctx context.Context, quizID uuid.UUID) ([]*models.Question, error)
 
 	// Quiz attempt operations
-	CreateQuizAttempt(ctx context.Context, attempt *models.UserQuizAttempt) (*models.UserQuizAttempt, error)
-	CreateQuestionResponse(ctx context.Context, response *models.UserQuestionResponse) error
+	// Saves the attempt with its responses in one transaction, award applies the attempt's XP within it
+	CreateQuizAttempt(ctx context.Context, attempt *models.UserQuizAttempt, responses []*models.UserQuestionResponse, award func(tx *sqlx.Tx) error) (*models.UserQuizAttempt, error)
 	GetQuestionByID(ctx context.Context, questionID uuid.UUID) (*models.Question, error)
+	CreateQuizWithQuestions(ctx context.Context, quiz *models.Quiz, questions []*models.Question) error
+
+	// Adaptive quiz operations
+	GetUserAbility(ctx context.Context, userID uuid.UUID, subject string, grade int) (float64, int, error)
+	CreateAdaptiveSession(ctx context.Context, session *models.AdaptiveQuizSession) (*models.AdaptiveQuizSession, error)
+	GetAdaptiveSession(ctx context.Context, sessionID uuid.UUID) (*models.AdaptiveQuizSession, error)
+	UpdateAdaptiveSession(ctx context.Context, session *models.AdaptiveQuizSession) (*models.AdaptiveQuizSession, error)
+	// Records the answer and moves the user's ability in one transaction, the ability row is locked
+	// while update computes the new estimate from the current one and its answer count
+	CreateAdaptiveAnswer(ctx context.Context, userID uuid.UUID, subject string, grade int, answer *models.AdaptiveQuizAnswer, update func(ability float64, answers int) float64) error
+	GetAdaptiveAnswers(ctx context.Context, sessionID uuid.UUID) ([]*models.AdaptiveQuizAnswer, error)
 
 	// Custom content
 	GetUserCustomChapters(ctx context.Context, userID uuid.UUID) ([]*models.Chapter, error)
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +14,17 @@ import (
 	"github.com/lib/pq"
 
 	"github.com/AleksK1NG/api-mc/internal/chapter"
+	"github.com/AleksK1NG/api-mc/internal/events"
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
 type chapterRepo struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	recorder events.Recorder
 }
 
-func NewChapterRepository(db *sqlx.DB) chapter.Repository {
-	return &chapterRepo{db: db}
+func NewChapterRepository(db *sqlx.DB, recorder events.Recorder) chapter.Repository {
+	return &chapterRepo{db: db, recorder: recorder}
 }
 
 func (r *chapterRepo) CreateChapter(ctx context.Context, chapter *models.Chapter) (*models.Chapter, error) {
@@ -279,18 +283,20 @@ func (r *chapterRepo) GetQuizByChapter(ctx context.Context, chapterID uuid.UUID)
 }
 
 func (r *chapterRepo) CreateQuestion(ctx context.Context, question *models.Question) error {
-	return r.db.QueryRowxContext(
-		ctx,
-		createQuestionQuery,
-		question.QuizID,
-		question.Text,
-		question.QuestionType,
-		pq.Array(question.Options),
-		question.Answer,
-		question.Explanation,
-		question.Points,
-		question.Difficulty,
-	).StructScan(question)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createQuizQuestion(ctx, tx, question); err != nil {
+		return fmt.Errorf("failed to create question: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
 func (r *chapterRepo) GetUserCustomChapters(ctx context.Context, userID uuid.UUID) ([]*models.Chapter, error) {
@@ -310,105 +316,45 @@ func (r *chapterRepo) GetQuizByID(ctx context.Context, quizID uuid.UUID) (*model
 }
 
 func (r *chapterRepo) GetQuestionsByQuizID(ctx context.Context, quizID uuid.UUID) ([]*models.Question, error) {
-	// Instead of using SelectContext, we'll use QueryxContext and manually scan rows
-	// to properly handle the PostgreSQL array type for options
-	query := `
-		SELECT question_id, quiz_id, text, question_type, options, answer, explanation, 
-		       points, difficulty, created_at, updated_at 
-		FROM questions 
-		WHERE quiz_id = $1
-		ORDER BY created_at ASC
-	`
-
-	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, quizID)
+	rows, err := r.db.QueryxContext(ctx, getQuestionsByQuizIDQuery, quizID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get questions for quiz: %w", err)
 	}
 	defer rows.Close()
 
-	// Process each row manually
-	var questions []*models.Question
-	for rows.Next() {
-		var question models.Question
-		var optionsArray pq.StringArray // Use pq.StringArray to handle PostgreSQL array
-
-		// Scan the row into variables
-		err := rows.Scan(
-			&question.QuestionID,
-			&question.QuizID,
-			&question.Text,
-			&question.QuestionType,
-			&optionsArray, // Scan into pq.StringArray
-			&question.Answer,
-			&question.Explanation,
-			&question.Points,
-			&question.Difficulty,
-			&question.CreatedAt,
-			&question.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan question row: %w", err)
-		}
-
-		// Convert pq.StringArray to []string
-		question.Options = []string(optionsArray)
-		questions = append(questions, &question)
-	}
-
-	// Check for errors from iterating over rows
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating question rows: %w", err)
+	questions, err := scanQuestions(rows)
+	if err != nil {
+		return nil, err
 	}
 
 	return questions, nil
 }
 
 func (r *chapterRepo) GetQuestionByID(ctx context.Context, questionID uuid.UUID) (*models.Question, error) {
-	// Use a custom query instead of the predefined one to have more control
-	query := `
-		SELECT question_id, quiz_id, text, question_type, options, answer, explanation, 
-		       points, difficulty, created_at, updated_at 
-		FROM questions 
-		WHERE question_id = $1
-	`
-
-	// Execute the query
-	row := r.db.QueryRowxContext(ctx, query, questionID)
-
-	// Create variables to scan into
-	var question models.Question
-	var optionsArray pq.StringArray // Use pq.StringArray to handle PostgreSQL array
-
-	// Scan the row into variables
-	err := row.Scan(
-		&question.QuestionID,
-		&question.QuizID,
-		&question.Text,
-		&question.QuestionType,
-		&optionsArray, // Scan into pq.StringArray
-		&question.Answer,
-		&question.Explanation,
-		&question.Points,
-		&question.Difficulty,
-		&question.CreatedAt,
-		&question.UpdatedAt,
-	)
+	question, err := scanQuestion(r.db.QueryRowxContext(ctx, getQuestionByIDQuery, questionID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get question by ID: %w", err)
 	}
 
-	// Convert pq.StringArray to []string
-	question.Options = []string(optionsArray)
-
-	return &question, nil
+	return question, nil
 }
 
-func (r *chapterRepo) CreateQuizAttempt(ctx context.Context, attempt *models.UserQuizAttempt) (*models.UserQuizAttempt, error) {
+func (r *chapterRepo) CreateQuizAttempt(
+	ctx context.Context,
+	attempt *models.UserQuizAttempt,
+	responses []*models.UserQuestionResponse,
+	award func(tx *sqlx.Tx) error,
+) (*models.UserQuizAttempt, error) {
 	attempt.AttemptID = uuid.New()
 	attempt.CreatedAt = attempt.CompletedAt
 
-	if err := r.db.QueryRowxContext(
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowxContext(
 		ctx,
 		createQuizAttemptQuery,
 		attempt.UserID,
@@ -420,25 +366,40 @@ func (r *chapterRepo) CreateQuizAttempt(ctx context.Context, attempt *models.Use
 		return nil, fmt.Errorf("failed to create quiz attempt: %w", err)
 	}
 
-	return attempt, nil
-}
+	for _, response := range responses {
+		response.AttemptID = attempt.AttemptID
+		response.CreatedAt = time.Now()
+
+		if err := tx.QueryRowxContext(
+			ctx,
+			createQuestionResponseQuery,
+			response.AttemptID,
+			response.QuestionID,
+			response.UserAnswer,
+			response.IsCorrect,
+			response.TimeSpent,
+		).Scan(&response.ResponseID); err != nil {
+			return nil, fmt.Errorf("failed to create question response: %w", err)
+		}
+	}
 
-func (r *chapterRepo) CreateQuestionResponse(ctx context.Context, response *models.UserQuestionResponse) error {
-	response.ResponseID = uuid.New()
-	response.CreatedAt = time.Now()
+	if err := award(tx); err != nil {
+		return nil, fmt.Errorf("failed to award quiz XP: %w", err)
+	}
 
-	if err := r.db.QueryRowxContext(
-		ctx,
-		createQuestionResponseQuery,
-		response.AttemptID,
-		response.QuestionID,
-		response.UserAnswer,
-		response.IsCorrect,
-	).Scan(&response.ResponseID); err != nil {
-		return fmt.Errorf("failed to create question response: %w", err)
+	if err := r.recorder.Record(ctx, tx, attempt.UserID, &events.QuizSubmitted{
+		AttemptID: attempt.AttemptID,
+		QuizID:    attempt.QuizID,
+		Score:     attempt.Score,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record quiz submitted event: %w", err)
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return attempt, nil
 }
 
 func (r *chapterRepo) GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error) {
@@ -459,6 +420,121 @@ func (r *chapterRepo) GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*m
 	return lesson, nil
 }
 
+func (r *chapterRepo) StartLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID, startedAt time.Time) (*models.LessonProgress, error) {
+	progress := &models.LessonProgress{}
+	if err := r.db.QueryRowxContext(ctx, startLessonQuery, userID, lessonID, startedAt).StructScan(progress); err != nil {
+		return nil, fmt.Errorf("failed to start lesson: %w", err)
+	}
+
+	return progress, nil
+}
+
+func (r *chapterRepo) GetLessonProgress(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error) {
+	progress := &models.LessonProgress{}
+	if err := r.db.GetContext(ctx, progress, getLessonProgressQuery, userID, lessonID); err != nil {
+		return nil, fmt.Errorf("failed to get lesson progress: %w", err)
+	}
+
+	return progress, nil
+}
+
+func (r *chapterRepo) RecordLessonHeartbeat(ctx context.Context, progress *models.LessonProgress, seconds int, at time.Time) (*models.LessonProgress, error) {
+	updated := &models.LessonProgress{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		recordLessonHeartbeatQuery,
+		progress.UserID,
+		progress.LessonID,
+		seconds,
+		at,
+		progress.LastHeartbeatAt,
+	).StructScan(updated); err != nil {
+		return nil, fmt.Errorf("failed to record lesson heartbeat: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (r *chapterRepo) CompleteLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previousStatus string
+	if err := tx.GetContext(ctx, &previousStatus, lockLessonProgressStatusQuery, userID, lessonID); err != nil {
+		return nil, fmt.Errorf("failed to lock lesson progress: %w", err)
+	}
+
+	progress := &models.LessonProgress{}
+	if err := tx.QueryRowxContext(ctx, completeLessonQuery, userID, lessonID).StructScan(progress); err != nil {
+		return nil, fmt.Errorf("failed to complete lesson: %w", err)
+	}
+
+	// Only the first completion is announced
+	if previousStatus != "completed" {
+		if err := r.recorder.Record(ctx, tx, userID, &events.LessonCompleted{
+			LessonID:  lessonID,
+			TimeSpent: progress.TimeSpent,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to record lesson completed event: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, lockChapterCompletionQuery, userID, lessonID); err != nil {
+			return nil, fmt.Errorf("failed to lock chapter completion: %w", err)
+		}
+
+		var chapter struct {
+			ChapterID uuid.UUID `db:"chapter_id"`
+			Completed bool      `db:"completed"`
+		}
+		if err := tx.GetContext(ctx, &chapter, getChapterCompletionQuery, userID, lessonID); err != nil {
+			return nil, fmt.Errorf("failed to get chapter completion: %w", err)
+		}
+
+		if chapter.Completed {
+			if err := r.recorder.Record(ctx, tx, userID, &events.ChapterCompleted{ChapterID: chapter.ChapterID}); err != nil {
+				return nil, fmt.Errorf("failed to record chapter completed event: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return progress, nil
+}
+
+func (r *chapterRepo) GetChapterProgress(ctx context.Context, userID uuid.UUID, chapterID uuid.UUID) (*models.ChapterProgress, error) {
+	progress := &models.ChapterProgress{ChapterID: chapterID}
+	if err := r.db.GetContext(ctx, &progress.TotalLessons, countChapterLessonsQuery, chapterID); err != nil {
+		return nil, fmt.Errorf("failed to count chapter lessons: %w", err)
+	}
+
+	progress.Lessons = make([]*models.LessonProgress, 0)
+	if err := r.db.SelectContext(ctx, &progress.Lessons, getChapterLessonProgressQuery, chapterID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get chapter lesson progress: %w", err)
+	}
+
+	for _, lesson := range progress.Lessons {
+		progress.TimeSpent += lesson.TimeSpent
+		switch lesson.Status {
+		case "completed":
+			progress.CompletedLessons++
+		case "in_progress":
+			progress.InProgressLessons++
+		}
+	}
+
+	if progress.TotalLessons > 0 {
+		progress.CompletionPercentage = math.Round(float64(progress.CompletedLessons)*10000/float64(progress.TotalLessons)) / 100
+	}
+
+	return progress, nil
+}
+
 func (r *chapterRepo) GetQuizzesByChapterID(ctx context.Context, chapterID uuid.UUID) ([]*models.QuizWithQuestions, error) {
 	quizzes := make([]*models.Quiz, 0)
 	if err := r.db.SelectContext(ctx, &quizzes, getQuizzesByChapterIDQuery, chapterID); err != nil {
@@ -474,56 +550,20 @@ func (r *chapterRepo) GetQuizzesByChapterID(ctx context.Context, chapterID uuid.
 		quizQuestionsMap := make(map[uuid.UUID][]*models.Question)
 
 		// Get all quiz IDs
-		var quizIDs []interface{}
+		quizIDs := make([]uuid.UUID, 0, len(quizzes))
 		for _, quiz := range quizzes {
 			quizIDs = append(quizIDs, quiz.QuizID)
 		}
 
-		// Build the query with placeholders for multiple quiz IDs
-		query := fmt.Sprintf(
-			"SELECT question_id, quiz_id, text, question_type, options, answer, explanation, points, difficulty, created_at, updated_at FROM questions WHERE quiz_id IN (%s) ORDER BY created_at ASC",
-			buildPlaceholders(len(quizIDs)),
-		)
-
-		// Execute the query manually to handle the array type
-		rows, err := r.db.QueryxContext(ctx, query, quizIDs...)
+		rows, err := r.db.QueryxContext(ctx, getQuestionsByQuizIDsQuery, pq.Array(quizIDs))
 		if err != nil {
 			return nil, fmt.Errorf("failed to get questions for quizzes: %w", err)
 		}
 		defer rows.Close()
 
-		// Process each row manually
-		var allQuestions []*models.Question
-		for rows.Next() {
-			var question models.Question
-			var optionsArray pq.StringArray // Use pq.StringArray to handle PostgreSQL array
-
-			// Scan the row into variables
-			err := rows.Scan(
-				&question.QuestionID,
-				&question.QuizID,
-				&question.Text,
-				&question.QuestionType,
-				&optionsArray, // Scan into pq.StringArray
-				&question.Answer,
-				&question.Explanation,
-				&question.Points,
-				&question.Difficulty,
-				&question.CreatedAt,
-				&question.UpdatedAt,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to scan question row: %w", err)
-			}
-
-			// Convert pq.StringArray to []string
-			question.Options = []string(optionsArray)
-			allQuestions = append(allQuestions, &question)
-		}
-
-		// Check for errors from iterating over rows
-		if err := rows.Err(); err != nil {
-			return nil, fmt.Errorf("error iterating question rows: %w", err)
+		allQuestions, err := scanQuestions(rows)
+		if err != nil {
+			return nil, err
 		}
 
 		// Group questions by quiz ID
@@ -552,16 +592,237 @@ func (r *chapterRepo) GetQuizzesByChapterID(ctx context.Context, chapterID uuid.
 	return result, nil
 }
 
-// Helper function to build SQL placeholders for IN clause
-func buildPlaceholders(n int) string {
-	if n <= 0 {
-		return ""
+func (r *chapterRepo) GetUserAbility(ctx context.Context, userID uuid.UUID, subject string, grade int) (float64, int, error) {
+	var row struct {
+		Ability float64 `db:"ability"`
+		Answers int     `db:"ability_answers"`
+	}
+	if err := r.db.GetContext(ctx, &row, getUserAbilityQuery, userID, subject, grade); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("failed to get user ability: %w", err)
+	}
+	return row.Ability, row.Answers, nil
+}
+
+func (r *chapterRepo) CreateAdaptiveSession(ctx context.Context, session *models.AdaptiveQuizSession) (*models.AdaptiveQuizSession, error) {
+	s := &models.AdaptiveQuizSession{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createAdaptiveSessionQuery,
+		session.UserID,
+		session.QuizID,
+		session.Subject,
+		session.Grade,
+		session.MaxQuestions,
+		session.Ability,
+		session.CurrentQuestionID,
+	).StructScan(s); err != nil {
+		return nil, fmt.Errorf("failed to create adaptive session: %w", err)
+	}
+	return s, nil
+}
+
+func (r *chapterRepo) GetAdaptiveSession(ctx context.Context, sessionID uuid.UUID) (*models.AdaptiveQuizSession, error) {
+	s := &models.AdaptiveQuizSession{}
+	if err := r.db.GetContext(ctx, s, getAdaptiveSessionQuery, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to get adaptive session: %w", err)
+	}
+	return s, nil
+}
+
+func (r *chapterRepo) UpdateAdaptiveSession(ctx context.Context, session *models.AdaptiveQuizSession) (*models.AdaptiveQuizSession, error) {
+	s := &models.AdaptiveQuizSession{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		updateAdaptiveSessionQuery,
+		session.Status,
+		session.Answered,
+		session.Correct,
+		session.PointsEarned,
+		session.PointsPossible,
+		session.Ability,
+		session.CurrentQuestionID,
+		session.AttemptID,
+		session.CompletedAt,
+		session.SessionID,
+	).StructScan(s); err != nil {
+		return nil, fmt.Errorf("failed to update adaptive session: %w", err)
+	}
+	return s, nil
+}
+
+func (r *chapterRepo) CreateAdaptiveAnswer(
+	ctx context.Context,
+	userID uuid.UUID,
+	subject string,
+	grade int,
+	answer *models.AdaptiveQuizAnswer,
+	update func(ability float64, answers int) float64,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, createUserAbilityQuery, userID, subject, grade); err != nil {
+		return fmt.Errorf("failed to create user ability: %w", err)
+	}
+
+	// Concurrent answers in the same subject wait here, each one moves the ability the previous one left
+	var row struct {
+		Ability float64 `db:"ability"`
+		Answers int     `db:"ability_answers"`
+	}
+	if err := tx.GetContext(ctx, &row, lockUserAbilityQuery, userID, subject, grade); err != nil {
+		return fmt.Errorf("failed to lock user ability: %w", err)
+	}
+
+	answer.AbilityBefore = row.Ability
+	answer.AbilityAfter = update(row.Ability, row.Answers)
+
+	// The session's question is answered once, a concurrent answer to it fails on the unique key and leaves the ability as is
+	if err := tx.QueryRowxContext(
+		ctx,
+		createAdaptiveAnswerQuery,
+		answer.SessionID,
+		answer.QuestionID,
+		answer.UserAnswer,
+		answer.IsCorrect,
+		answer.AbilityBefore,
+		answer.AbilityAfter,
+	).Scan(&answer.AnswerID, &answer.AnsweredAt); err != nil {
+		return fmt.Errorf("failed to create adaptive answer: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, updateUserAbilityQuery, userID, subject, grade, answer.AbilityAfter); err != nil {
+		return fmt.Errorf("failed to update user ability: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *chapterRepo) GetAdaptiveAnswers(ctx context.Context, sessionID uuid.UUID) ([]*models.AdaptiveQuizAnswer, error) {
+	answers := make([]*models.AdaptiveQuizAnswer, 0)
+	if err := r.db.SelectContext(ctx, &answers, getAdaptiveAnswersQuery, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to get adaptive answers: %w", err)
+	}
+	return answers, nil
+}
+
+func (r *chapterRepo) CreateQuizWithQuestions(ctx context.Context, quiz *models.Quiz, questions []*models.Question) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowxContext(
+		ctx,
+		createQuizQuery,
+		quiz.LessonID,
+		quiz.Title,
+		quiz.Description,
+		quiz.TimeLimit,
+	).StructScan(quiz); err != nil {
+		return fmt.Errorf("failed to create quiz: %w", err)
+	}
+
+	for i, question := range questions {
+		question.QuizID = quiz.QuizID
+		if err := createQuizQuestion(ctx, tx, question); err != nil {
+			return fmt.Errorf("failed to create question %d: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quiz import: %w", err)
+	}
+
+	return nil
+}
+
+// createQuizQuestion adds a new bank item to question.QuizID. The quiz row is locked until the transaction ends,
+// so questions added at once get the next positions one after the other
+func createQuizQuestion(ctx context.Context, tx *sqlx.Tx, question *models.Question) error {
+	if _, err := tx.ExecContext(ctx, lockQuizQuery, question.QuizID); err != nil {
+		return err
+	}
+
+	return tx.QueryRowxContext(
+		ctx,
+		createQuizQuestionQuery,
+		question.QuizID,
+		question.Text,
+		question.QuestionType,
+		pq.Array(question.Options),
+		question.Answer,
+		question.Explanation,
+		question.Points,
+		question.Difficulty,
+		question.Subject,
+		question.Grade,
+		pq.Array(tagsOrEmpty(question.Tags)),
+		question.CreatedBy,
+	).Scan(&question.QuestionID, &question.Subject, &question.Grade, &question.CreatedAt, &question.UpdatedAt)
+}
+
+// scanQuestion reads a row selected with quizQuestionColumns, arrays go through pq
+func scanQuestion(row interface{ Scan(...interface{}) error }) (*models.Question, error) {
+	var question models.Question
+	var options, tags pq.StringArray
+
+	if err := row.Scan(
+		&question.QuestionID,
+		&question.QuizID,
+		&question.Text,
+		&question.QuestionType,
+		&options,
+		&question.Answer,
+		&question.Explanation,
+		&question.Points,
+		&question.Difficulty,
+		&question.Subject,
+		&question.Grade,
+		&tags,
+		&question.CreatedBy,
+		&question.CreatedAt,
+		&question.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	question.Options = []string(options)
+	question.Tags = []string(tags)
+	return &question, nil
+}
+
+func scanQuestions(rows *sqlx.Rows) ([]*models.Question, error) {
+	questions := make([]*models.Question, 0)
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan question row: %w", err)
+		}
+		questions = append(questions, question)
 	}
 
-	placeholders := make([]string, n)
-	for i := 0; i < n; i++ {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating question rows: %w", err)
 	}
 
-	return strings.Join(placeholders, ", ")
+	return questions, nil
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
 }
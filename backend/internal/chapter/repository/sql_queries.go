@@ -13,7 +13,7 @@ const (
 
 	getChaptersBySubjectQuery = `
 		SELECT * FROM chapters 
-		WHERE subject = $1 AND grade = $2
+		WHERE subject = $1 AND grade = $2 AND is_custom = false
 		ORDER BY "order" ASC
 	`
 
@@ -78,24 +78,58 @@ const (
 		ORDER BY q.created_at ASC
 	`
 
+	lockQuizQuery = `SELECT quiz_id FROM quizzes WHERE quiz_id = $1 FOR UPDATE`
+
 	getQuizByIDQuery = `
 		SELECT * FROM quizzes WHERE quiz_id = $1
 	`
 
-	createQuestionQuery = `
-		INSERT INTO questions (quiz_id, text, question_type, options, answer, explanation, points, difficulty)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING *
+	quizQuestionColumns = `
+		q.question_id, qq.quiz_id, q.text, q.question_type, q.options, q.answer, q.explanation,
+		q.points, q.difficulty, q.subject, q.grade, q.tags, q.created_by, q.created_at, q.updated_at
+	`
+
+	// Creates a bank item and appends it to the quiz, subject and grade default to the quiz's lesson.
+	// clock_timestamp keeps questions inserted in one transaction in order.
+	createQuizQuestionQuery = `
+		WITH quiz_lesson AS (
+			SELECT l.subject, l.grade FROM quizzes qz
+			JOIN lessons l ON l.lesson_id = qz.lesson_id
+			WHERE qz.quiz_id = $1
+		), inserted AS (
+			INSERT INTO questions (text, question_type, options, answer, explanation, points, difficulty,
+				subject, grade, tags, created_by, created_at, updated_at)
+			SELECT $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), ql.subject), COALESCE(NULLIF($10, 0), ql.grade),
+				$11, $12, clock_timestamp(), clock_timestamp()
+			FROM quiz_lesson ql
+			RETURNING question_id, subject, grade, created_at, updated_at
+		), linked AS (
+			INSERT INTO quiz_questions (quiz_id, question_id, position)
+			SELECT $1, question_id, COALESCE((SELECT MAX(position) FROM quiz_questions WHERE quiz_id = $1), 0) + 1
+			FROM inserted
+		)
+		SELECT question_id, subject, grade, created_at, updated_at FROM inserted
 	`
 
-	getQuestionsByQuizIDQuery = `
-		SELECT * FROM questions 
-		WHERE quiz_id = $1
-		ORDER BY created_at ASC
+	getQuestionsByQuizIDQuery = `SELECT` + quizQuestionColumns + `
+		FROM quiz_questions qq
+		JOIN questions q ON q.question_id = qq.question_id
+		WHERE qq.quiz_id = $1
+		ORDER BY qq.position ASC, qq.created_at ASC
+	`
+
+	getQuestionsByQuizIDsQuery = `SELECT` + quizQuestionColumns + `
+		FROM quiz_questions qq
+		JOIN questions q ON q.question_id = qq.question_id
+		WHERE qq.quiz_id = ANY($1::uuid[])
+		ORDER BY qq.quiz_id, qq.position ASC, qq.created_at ASC
 	`
 
 	getQuestionByIDQuery = `
-		SELECT * FROM questions WHERE question_id = $1
+		SELECT q.question_id, NULL::uuid AS quiz_id, q.text, q.question_type, q.options, q.answer, q.explanation,
+			q.points, q.difficulty, q.subject, q.grade, q.tags, q.created_by, q.created_at, q.updated_at
+		FROM questions q
+		WHERE q.question_id = $1
 	`
 
 	createQuizAttemptQuery = `
@@ -105,8 +139,8 @@ const (
 	`
 
 	createQuestionResponseQuery = `
-		INSERT INTO user_question_responses (attempt_id, question_id, user_answer, is_correct)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO user_question_responses (attempt_id, question_id, user_answer, is_correct, time_spent)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING response_id
 	`
 
@@ -119,4 +153,123 @@ const (
 	getLessonByIDQuery = `
 		SELECT * FROM lessons WHERE lesson_id = $1
 	`
+
+	// Reopening a lesson restarts the heartbeat clock but never undoes a completion
+	startLessonQuery = `
+		INSERT INTO lesson_progress (user_id, lesson_id, status, started_at, last_heartbeat_at)
+		VALUES ($1, $2, 'in_progress', $3, $3)
+		ON CONFLICT (user_id, lesson_id) DO UPDATE SET
+			status = CASE WHEN lesson_progress.status = 'completed' THEN 'completed' ELSE 'in_progress' END,
+			started_at = COALESCE(lesson_progress.started_at, EXCLUDED.started_at),
+			last_heartbeat_at = EXCLUDED.last_heartbeat_at,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`
+
+	getLessonProgressQuery = `
+		SELECT * FROM lesson_progress WHERE user_id = $1 AND lesson_id = $2
+	`
+
+	// Only applies when no other heartbeat got in since the caller read the progress
+	recordLessonHeartbeatQuery = `
+		UPDATE lesson_progress SET
+			time_spent = time_spent + $3,
+			last_heartbeat_at = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND lesson_id = $2 AND last_heartbeat_at IS NOT DISTINCT FROM $5
+		RETURNING *
+	`
+
+	// Completing again keeps the first completion time
+	lockLessonProgressStatusQuery = `
+		SELECT status FROM lesson_progress WHERE user_id = $1 AND lesson_id = $2 FOR UPDATE
+	`
+
+	completeLessonQuery = `
+		UPDATE lesson_progress SET
+			status = 'completed',
+			completed_at = COALESCE(completed_at, CURRENT_TIMESTAMP),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND lesson_id = $2
+		RETURNING *
+	`
+
+	// Serializes the completions of a user's lessons of the same chapter, so exactly one of them finishes it
+	lockChapterCompletionQuery = `
+		SELECT pg_advisory_xact_lock(hashtext($1::text || l.chapter_id::text))
+		FROM lessons l WHERE l.lesson_id = $2
+	`
+
+	getChapterCompletionQuery = `
+		SELECT l.chapter_id, NOT EXISTS (
+			SELECT 1 FROM lessons cl
+			LEFT JOIN lesson_progress lp ON lp.lesson_id = cl.lesson_id AND lp.user_id = $1
+			WHERE cl.chapter_id = l.chapter_id AND (lp.status IS NULL OR lp.status <> 'completed')
+		) AS completed
+		FROM lessons l WHERE l.lesson_id = $2
+	`
+
+	countChapterLessonsQuery = `
+		SELECT COUNT(*) FROM lessons WHERE chapter_id = $1
+	`
+
+	getChapterLessonProgressQuery = `
+		SELECT lp.* FROM lesson_progress lp
+		JOIN lessons l ON l.lesson_id = lp.lesson_id
+		WHERE l.chapter_id = $1 AND lp.user_id = $2
+		ORDER BY l."order" ASC, l.created_at ASC
+	`
+
+	getUserAbilityQuery = `
+		SELECT ability, ability_answers FROM user_progress
+		WHERE user_id = $1 AND subject = $2 AND grade = $3
+	`
+
+	createUserAbilityQuery = `
+		INSERT INTO user_progress (user_id, subject, grade)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, subject, grade) DO NOTHING
+	`
+
+	lockUserAbilityQuery = `
+		SELECT ability, ability_answers FROM user_progress
+		WHERE user_id = $1 AND subject = $2 AND grade = $3
+		FOR UPDATE
+	`
+
+	updateUserAbilityQuery = `
+		UPDATE user_progress
+		SET ability = $4, ability_answers = ability_answers + 1, updated_at = now()
+		WHERE user_id = $1 AND subject = $2 AND grade = $3
+	`
+
+	createAdaptiveSessionQuery = `
+		INSERT INTO adaptive_quiz_sessions (user_id, quiz_id, subject, grade, max_questions, ability, current_question_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *
+	`
+
+	getAdaptiveSessionQuery = `
+		SELECT * FROM adaptive_quiz_sessions WHERE session_id = $1
+	`
+
+	updateAdaptiveSessionQuery = `
+		UPDATE adaptive_quiz_sessions
+		SET status = $1, answered = $2, correct = $3, points_earned = $4, points_possible = $5,
+		    ability = $6, current_question_id = $7, attempt_id = $8, completed_at = $9, updated_at = now()
+		WHERE session_id = $10
+		RETURNING *
+	`
+
+	createAdaptiveAnswerQuery = `
+		INSERT INTO adaptive_quiz_answers (session_id, question_id, user_answer, is_correct, ability_before, ability_after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING answer_id, answered_at
+	`
+
+	getAdaptiveAnswersQuery = `
+		SELECT * FROM adaptive_quiz_answers
+		WHERE session_id = $1
+		ORDER BY answered_at ASC
+	`
 )
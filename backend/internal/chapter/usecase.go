@@ -29,11 +29,34 @@ type UseCase interface {
 	CreateCustomLesson(ctx context.Context, lesson *models.Lesson, userID uuid.UUID) (*models.Lesson, error)
 	GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error)
 
+	// Lesson progress
+	StartLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error)
+	RecordLessonHeartbeat(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID, seconds int) (*models.LessonHeartbeat, error)
+	CompleteLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, *models.XPAward, error)
+	GetChapterProgress(ctx context.Context, userID uuid.UUID, chapterID uuid.UUID) (*models.ChapterProgress, error)
+
 	// Quiz Management
 	GetQuizByID(ctx context.Context, quizID uuid.UUID) (*models.Quiz, []*models.Question, error)
 	GetQuizzesByChapterID(ctx context.Context, chapterID uuid.UUID) ([]*models.QuizWithQuestions, error)
 	SubmitQuizAnswers(ctx context.Context, userID uuid.UUID, quizID uuid.UUID, answers []*models.UserQuestionResponse) (*models.UserQuizAttempt, error)
 
+	// Question sets, drawn once and served to several users alike.
+	// Draws count random questions of the quiz with their answer keys, all of them when count is not positive
+	DrawQuizQuestions(ctx context.Context, quizID uuid.UUID, count int) ([]*models.Question, error)
+	// Grades the answers against a drawn set of the quiz's questions like SubmitQuizAnswers does against the whole quiz
+	SubmitQuestionSetAnswers(ctx context.Context, userID uuid.UUID, quizID uuid.UUID, questionIDs []uuid.UUID, answers []*models.UserQuestionResponse, timeSpent int) (*models.UserQuizAttempt, error)
+	// Serves a drawn set to the users it was drawn for, in the drawn order and without the questions deleted since
+	GetQuestionSet(ctx context.Context, quizID uuid.UUID, questionIDs []uuid.UUID) ([]*models.Question, error)
+
+	// Quiz import/export
+	ImportQuiz(ctx context.Context, req *models.QuizImportRequest, data []byte) (*models.QuizImportResult, error)
+	ExportQuiz(ctx context.Context, quizID uuid.UUID, format string) ([]byte, *models.Quiz, error)
+
+	// Adaptive quizzes
+	StartAdaptiveQuiz(ctx context.Context, userID uuid.UUID, quizID uuid.UUID, maxQuestions int) (*models.AdaptiveQuizStep, error)
+	SubmitAdaptiveAnswer(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, questionID uuid.UUID, answer string) (*models.AdaptiveQuizStep, error)
+	GetAdaptiveSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*models.AdaptiveQuizStep, error)
+
 	// Quiz operations
 	CreateQuiz(ctx context.Context, quiz *models.Quiz) error
 	GetQuizByChapter(ctx context.Context, chapterID uuid.UUID) (*models.Quiz, error)
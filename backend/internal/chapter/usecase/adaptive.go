@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+const (
+	defaultAdaptiveQuestions = 10
+	maxAdaptiveQuestions     = 50
+
+	minAbility     = -3.0
+	maxAbility     = 3.0
+	minAbilityStep = 0.2
+)
+
+// Item difficulty on the same logit scale as the ability estimate
+var difficultyRatings = map[string]float64{
+	"easy":   -1.0,
+	"medium": 0.0,
+	"hard":   1.0,
+}
+
+// expectedScore is the probability that a learner of the given ability answers an item of the given difficulty correctly
+func expectedScore(ability float64, difficulty float64) float64 {
+	return 1 / (1 + math.Exp(difficulty-ability))
+}
+
+// abilityStep shrinks the Elo K-factor as more answers back the estimate
+func abilityStep(answers int) float64 {
+	return math.Max(minAbilityStep, 1/math.Sqrt(float64(answers)+1))
+}
+
+// updateAbility applies a single Elo update for one answered item
+func updateAbility(ability float64, answers int, difficulty string, correct bool) float64 {
+	outcome := 0.0
+	if correct {
+		outcome = 1.0
+	}
+
+	next := ability + abilityStep(answers)*(outcome-expectedScore(ability, difficultyRatings[difficulty]))
+	next = math.Max(minAbility, math.Min(maxAbility, next))
+
+	return math.Round(next*1000) / 1000
+}
+
+// selectNextQuestion picks the unserved question whose difficulty is closest to the ability,
+// which is where a Rasch item is most informative. Ties are broken randomly.
+func selectNextQuestion(questions []*models.Question, served map[uuid.UUID]bool, ability float64) *models.Question {
+	candidates := make([]*models.Question, 0, len(questions))
+	for _, q := range questions {
+		if !served[q.QuestionID] {
+			candidates = append(candidates, q)
+		}
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	var next *models.Question
+	bestDistance := math.Inf(1)
+	for _, q := range candidates {
+		distance := math.Abs(difficultyRatings[q.Difficulty] - ability)
+		if distance < bestDistance {
+			next = q
+			bestDistance = distance
+		}
+	}
+
+	return next
+}
+
+// servedQuestion hides the answer key before a question is sent to the learner
+func servedQuestion(question *models.Question) *models.Question {
+	if question == nil {
+		return nil
+	}
+	q := *question
+	q.Answer = ""
+	q.Explanation = ""
+	return &q
+}
+
+func findQuestion(questions []*models.Question, questionID *uuid.UUID) *models.Question {
+	if questionID == nil {
+		return nil
+	}
+	for _, q := range questions {
+		if q.QuestionID == *questionID {
+			return q
+		}
+	}
+	return nil
+}
+
+func (u *chapterUC) StartAdaptiveQuiz(ctx context.Context, userID uuid.UUID, quizID uuid.UUID, maxQuestions int) (*models.AdaptiveQuizStep, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.StartAdaptiveQuiz")
+	defer span.Finish()
+
+	quiz, _, err := u.getReadableQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	lesson, err := u.chapterRepo.GetLessonByID(ctx, quiz.LessonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lesson for quiz: %w", err)
+	}
+
+	questions, err := u.chapterRepo.GetQuestionsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get questions for quiz: %w", err)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("quiz has no questions")
+	}
+
+	if maxQuestions <= 0 {
+		maxQuestions = defaultAdaptiveQuestions
+	}
+	if maxQuestions > maxAdaptiveQuestions {
+		maxQuestions = maxAdaptiveQuestions
+	}
+	if maxQuestions > len(questions) {
+		maxQuestions = len(questions)
+	}
+
+	ability, _, err := u.chapterRepo.GetUserAbility(ctx, userID, lesson.Subject, lesson.Grade)
+	if err != nil {
+		return nil, err
+	}
+
+	first := selectNextQuestion(questions, map[uuid.UUID]bool{}, ability)
+
+	session, err := u.chapterRepo.CreateAdaptiveSession(ctx, &models.AdaptiveQuizSession{
+		UserID:            userID,
+		QuizID:            quizID,
+		Subject:           lesson.Subject,
+		Grade:             lesson.Grade,
+		MaxQuestions:      maxQuestions,
+		Ability:           ability,
+		CurrentQuestionID: &first.QuestionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AdaptiveQuizStep{
+		Session:      session,
+		NextQuestion: servedQuestion(first),
+	}, nil
+}
+
+func (u *chapterUC) GetAdaptiveSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*models.AdaptiveQuizStep, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.GetAdaptiveSession")
+	defer span.Finish()
+
+	session, err := u.getOwnAdaptiveSession(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	step := &models.AdaptiveQuizStep{Session: session}
+	if session.CurrentQuestionID != nil {
+		question, err := u.chapterRepo.GetQuestionByID(ctx, *session.CurrentQuestionID)
+		if err != nil {
+			return nil, err
+		}
+		step.NextQuestion = servedQuestion(question)
+	}
+
+	return step, nil
+}
+
+func (u *chapterUC) SubmitAdaptiveAnswer(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, questionID uuid.UUID, userAnswer string) (*models.AdaptiveQuizStep, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.SubmitAdaptiveAnswer")
+	defer span.Finish()
+
+	session, err := u.getOwnAdaptiveSession(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "in_progress" {
+		return nil, fmt.Errorf("adaptive session is already completed")
+	}
+	if session.CurrentQuestionID == nil || *session.CurrentQuestionID != questionID {
+		return nil, fmt.Errorf("question %s is not the current question of this session", questionID)
+	}
+	if userAnswer == "" {
+		return nil, fmt.Errorf("answer is required")
+	}
+
+	// The chapter may have been made private to the user since the session started
+	if _, _, err := u.getReadableQuiz(ctx, session.QuizID); err != nil {
+		return nil, err
+	}
+
+	questions, err := u.chapterRepo.GetQuestionsByQuizID(ctx, session.QuizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get questions for quiz: %w", err)
+	}
+
+	question := findQuestion(questions, session.CurrentQuestionID)
+	if question == nil {
+		return nil, fmt.Errorf("question with ID %s not found in quiz", questionID)
+	}
+
+	isCorrect := userAnswer == question.Answer
+
+	// The stored estimate is moved rather than the session copy so concurrent sessions in the same subject compose
+	answer := &models.AdaptiveQuizAnswer{
+		SessionID:  session.SessionID,
+		QuestionID: question.QuestionID,
+		UserAnswer: userAnswer,
+		IsCorrect:  isCorrect,
+	}
+	if err := u.chapterRepo.CreateAdaptiveAnswer(ctx, userID, session.Subject, session.Grade, answer, func(ability float64, answers int) float64 {
+		return updateAbility(ability, answers, question.Difficulty, isCorrect)
+	}); err != nil {
+		return nil, err
+	}
+	newAbility := answer.AbilityAfter
+
+	session.Answered++
+	session.PointsPossible += question.Points
+	if isCorrect {
+		session.Correct++
+		session.PointsEarned += question.Points
+	}
+	session.Ability = newAbility
+
+	given, err := u.chapterRepo.GetAdaptiveAnswers(ctx, session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	served := make(map[uuid.UUID]bool, len(given))
+	for _, a := range given {
+		served[a.QuestionID] = true
+	}
+
+	step := &models.AdaptiveQuizStep{
+		LastAnswer:  answer,
+		Explanation: question.Explanation,
+	}
+
+	var next *models.Question
+	if session.Answered < session.MaxQuestions {
+		next = selectNextQuestion(questions, served, newAbility)
+	}
+
+	if next != nil {
+		session.CurrentQuestionID = &next.QuestionID
+		step.NextQuestion = servedQuestion(next)
+	} else {
+		attempt, err := u.completeAdaptiveSession(ctx, session, given)
+		if err != nil {
+			return nil, err
+		}
+		step.Attempt = attempt
+	}
+
+	updated, err := u.chapterRepo.UpdateAdaptiveSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	step.Session = updated
+
+	return step, nil
+}
+
+// completeAdaptiveSession records the finished session as a regular quiz attempt
+func (u *chapterUC) completeAdaptiveSession(ctx context.Context, session *models.AdaptiveQuizSession, answers []*models.AdaptiveQuizAnswer) (*models.UserQuizAttempt, error) {
+	now := time.Now()
+
+	attempt := &models.UserQuizAttempt{
+		UserID:      session.UserID,
+		QuizID:      session.QuizID,
+		TimeSpent:   int(now.Sub(session.StartedAt).Seconds()),
+		CompletedAt: now,
+	}
+	if session.PointsPossible > 0 {
+		attempt.Score = (session.PointsEarned * 100) / session.PointsPossible
+	}
+
+	correctAnswers := 0
+	responses := make([]*models.UserQuestionResponse, 0, len(answers))
+	for _, a := range answers {
+		if a.IsCorrect {
+			correctAnswers++
+		}
+		responses = append(responses, &models.UserQuestionResponse{
+			QuestionID: a.QuestionID,
+			UserAnswer: a.UserAnswer,
+			IsCorrect:  a.IsCorrect,
+		})
+	}
+
+	savedAttempt, err := u.saveAttempt(ctx, attempt, responses, correctAnswers)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Status = "completed"
+	session.CurrentQuestionID = nil
+	session.AttemptID = &savedAttempt.AttemptID
+	session.CompletedAt = &now
+
+	return savedAttempt, nil
+}
+
+func (u *chapterUC) getOwnAdaptiveSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*models.AdaptiveQuizSession, error) {
+	session, err := u.chapterRepo.GetAdaptiveSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, fmt.Errorf("adaptive session %s not found", sessionID)
+	}
+	return session, nil
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/internal/chapter"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+const (
+	defaultWordsPerMinute      = 200
+	defaultMaxHeartbeatSeconds = 60
+)
+
+// expectedReadingSeconds estimates how long reading the lesson content takes
+func expectedReadingSeconds(content string, wordsPerMinute int) int {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = defaultWordsPerMinute
+	}
+	words := len(strings.Fields(content))
+	return int(math.Ceil(float64(words) * 60 / float64(wordsPerMinute)))
+}
+
+// creditedSeconds caps the reported reading time to the time that actually passed since the
+// last heartbeat, so a client cannot report more reading than the wall clock allows
+func creditedSeconds(reported int, since time.Time, now time.Time, grace int, maxPerHeartbeat int) int {
+	if maxPerHeartbeat <= 0 {
+		maxPerHeartbeat = defaultMaxHeartbeatSeconds
+	}
+
+	elapsed := int(now.Sub(since).Seconds()) + grace
+	credited := reported
+	if credited > elapsed {
+		credited = elapsed
+	}
+	if credited > maxPerHeartbeat {
+		credited = maxPerHeartbeat
+	}
+	if credited < 0 {
+		credited = 0
+	}
+	return credited
+}
+
+func (u *chapterUC) StartLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.StartLesson")
+	defer span.Finish()
+
+	if _, err := u.GetLessonByID(ctx, lessonID); err != nil {
+		return nil, err
+	}
+
+	return u.chapterRepo.StartLesson(ctx, userID, lessonID, time.Now())
+}
+
+func (u *chapterUC) RecordLessonHeartbeat(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID, seconds int) (*models.LessonHeartbeat, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.RecordLessonHeartbeat")
+	defer span.Finish()
+
+	if _, err := u.GetLessonByID(ctx, lessonID); err != nil {
+		return nil, err
+	}
+
+	progress, err := u.getLessonProgress(ctx, userID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	heartbeat := &models.LessonHeartbeat{
+		Progress:        progress,
+		ReportedSeconds: seconds,
+	}
+
+	since := progress.UpdatedAt
+	if progress.LastHeartbeatAt != nil {
+		since = *progress.LastHeartbeatAt
+	}
+	now := time.Now()
+
+	credited := creditedSeconds(seconds, since, now, u.cfg.Lessons.HeartbeatGrace, u.cfg.Lessons.MaxHeartbeatSeconds)
+	if credited == 0 {
+		return heartbeat, nil
+	}
+
+	updated, err := u.chapterRepo.RecordLessonHeartbeat(ctx, progress, credited, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Another heartbeat was credited for the same interval
+		return heartbeat, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	heartbeat.Progress = updated
+	heartbeat.CreditedSeconds = credited
+	return heartbeat, nil
+}
+
+func (u *chapterUC) CompleteLesson(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, *models.XPAward, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.CompleteLesson")
+	defer span.Finish()
+
+	lesson, err := u.GetLessonByID(ctx, lessonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	progress, err := u.getLessonProgress(ctx, userID, lessonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if progress.Status != "completed" {
+		expected := expectedReadingSeconds(lesson.Content, u.cfg.Lessons.WordsPerMinute)
+		required := int(float64(expected) * u.cfg.Lessons.MinReadingRatio)
+		if required < u.cfg.Lessons.MinReadingSeconds {
+			required = u.cfg.Lessons.MinReadingSeconds
+		}
+		if progress.TimeSpent < required {
+			return nil, nil, fmt.Errorf("%w: read for %d of the required %d seconds", chapter.ErrNotEnoughReadingTime, progress.TimeSpent, required)
+		}
+
+		progress, err = u.chapterRepo.CompleteLesson(ctx, userID, lessonID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Only the first completion counts towards the streak
+		streakChange, err := u.streakUC.RecordActivity(ctx, userID, time.Now())
+		if err != nil {
+			u.logger.Errorf("failed to record streak activity: %v", err)
+		}
+		progress.Streak = streakChange
+	}
+
+	// Authors could otherwise write lessons only to complete them, their own custom lessons pay no XP
+	if lesson.IsCustom && lesson.CreatedBy == userID {
+		return progress, nil, nil
+	}
+
+	// Awarding is idempotent, completing twice does not pay twice
+	award, err := u.xpUC.AwardLessonCompletion(ctx, userID, lessonID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to award lesson XP: %w", err)
+	}
+
+	return progress, award, nil
+}
+
+func (u *chapterUC) GetChapterProgress(ctx context.Context, userID uuid.UUID, chapterID uuid.UUID) (*models.ChapterProgress, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.GetChapterProgress")
+	defer span.Finish()
+
+	return u.chapterRepo.GetChapterProgress(ctx, userID, chapterID)
+}
+
+func (u *chapterUC) getLessonProgress(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.LessonProgress, error) {
+	progress, err := u.chapterRepo.GetLessonProgress(ctx, userID, lessonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, chapter.ErrLessonNotStarted
+	}
+	if err != nil {
+		return nil, err
+	}
+	if progress.Status == "not_started" {
+		return nil, chapter.ErrLessonNotStarted
+	}
+	return progress, nil
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/quizformat"
+)
+
+const (
+	maxQuizTitleLength       = 100
+	maxQuizDescriptionLength = 500
+)
+
+func (u *chapterUC) ImportQuiz(ctx context.Context, req *models.QuizImportRequest, data []byte) (*models.QuizImportResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.ImportQuiz")
+	defer span.Finish()
+
+	format, err := quizformat.ParseFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	lesson, err := u.chapterRepo.GetLessonByID(ctx, req.LessonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lesson: %w", err)
+	}
+
+	// Importing adds a quiz to the lesson, it is up to the owner of the chapter like adding a lesson
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, lesson.ChapterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chapter: %w", err)
+	}
+	if err := u.authorizeChapter(ctx, chapter, models.AccessActionUpdate); err != nil {
+		return nil, err
+	}
+
+	doc, issues, err := quizformat.Decode(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.QuizImportReport{
+		Format: string(format),
+		DryRun: req.DryRun,
+		Total:  len(doc.Questions),
+		Issues: issues,
+	}
+
+	invalid := make(map[int]bool)
+	for _, issue := range issues {
+		if issue.Severity == quizformat.SeverityError {
+			invalid[issue.Item] = true
+		}
+	}
+
+	valid := make([]*models.Question, 0, len(doc.Questions))
+	for i, question := range doc.Questions {
+		problems := quizformat.Validate(question, i+1, doc.Positions[i])
+		report.Issues = append(report.Issues, problems...)
+		if invalid[i+1] || quizformat.HasErrors(problems) {
+			report.Skipped++
+			continue
+		}
+		valid = append(valid, question)
+	}
+	report.Imported = len(valid)
+
+	result := &models.QuizImportResult{
+		Quiz:      importedQuiz(req, doc, lesson),
+		Questions: valid,
+		Report:    report,
+	}
+
+	if len(valid) == 0 {
+		report.Imported = 0
+		return result, fmt.Errorf("no valid questions found in the %s file", format)
+	}
+	if report.Skipped > 0 && !req.SkipInvalid && !req.DryRun {
+		report.Imported = 0
+		return result, fmt.Errorf("%d of %d questions are invalid, fix them or import with skip_invalid", report.Skipped, report.Total)
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	if err := u.chapterRepo.CreateQuizWithQuestions(ctx, result.Quiz, valid); err != nil {
+		return nil, fmt.Errorf("failed to save imported quiz: %w", err)
+	}
+
+	return result, nil
+}
+
+func (u *chapterUC) ExportQuiz(ctx context.Context, quizID uuid.UUID, formatName string) ([]byte, *models.Quiz, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.ExportQuiz")
+	defer span.Finish()
+
+	format, err := quizformat.ParseFormat(formatName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quiz, _, err := u.getReadableQuiz(ctx, quizID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	questions, err := u.chapterRepo.GetQuestionsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get questions for quiz: %w", err)
+	}
+
+	data, err := quizformat.Encode(format, quiz, questions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to export quiz: %w", err)
+	}
+
+	return data, quiz, nil
+}
+
+// importedQuiz fills the quiz fields from the request, then the file, then the lesson
+func importedQuiz(req *models.QuizImportRequest, doc *quizformat.Document, lesson *models.Lesson) *models.Quiz {
+	quiz := &models.Quiz{
+		LessonID:    req.LessonID,
+		Title:       firstNonEmpty(req.Title, doc.Title, lesson.Title+" quiz"),
+		Description: firstNonEmpty(req.Description, doc.Description, fmt.Sprintf("Imported from a %s file", strings.ToUpper(req.Format))),
+		TimeLimit:   req.TimeLimit,
+	}
+	if quiz.TimeLimit == nil {
+		quiz.TimeLimit = doc.TimeLimit
+	}
+
+	quiz.Title = truncateRunes(quiz.Title, maxQuizTitleLength)
+	quiz.Description = truncateRunes(quiz.Description, maxQuizDescriptionLength)
+
+	return quiz
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -3,27 +3,36 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/authz"
 	"github.com/AleksK1NG/api-mc/internal/chapter"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+	"github.com/AleksK1NG/api-mc/internal/xp"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 type chapterUC struct {
 	cfg         *config.Config
 	chapterRepo chapter.Repository
 	aiService   chapter.AIService
+	xpUC        xp.UseCase
+	streakUC    streak.UseCase
+	authzUC     authz.UseCase
 	logger      logger.Logger
 }
 
-func NewChapterUseCase(cfg *config.Config, chapterRepo chapter.Repository, aiService chapter.AIService, logger logger.Logger) chapter.UseCase {
-	return &chapterUC{cfg: cfg, chapterRepo: chapterRepo, aiService: aiService, logger: logger}
+func NewChapterUseCase(cfg *config.Config, chapterRepo chapter.Repository, aiService chapter.AIService, xpUC xp.UseCase, streakUC streak.UseCase, authzUC authz.UseCase, logger logger.Logger) chapter.UseCase {
+	return &chapterUC{cfg: cfg, chapterRepo: chapterRepo, aiService: aiService, xpUC: xpUC, streakUC: streakUC, authzUC: authzUC, logger: logger}
 }
 
 func (u *chapterUC) CreateChapter(ctx context.Context, chapter *models.Chapter) (*models.Chapter, error) {
@@ -31,7 +40,19 @@ func (u *chapterUC) CreateChapter(ctx context.Context, chapter *models.Chapter)
 }
 
 func (u *chapterUC) GetChapterByID(ctx context.Context, chapterID uuid.UUID) (*models.Chapter, error) {
-	return u.chapterRepo.GetChapterByID(ctx, chapterID)
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Custom chapters are private to their owner
+	if chapter.IsCustom {
+		if err := u.authorizeChapter(ctx, chapter, models.AccessActionRead); err != nil {
+			return nil, err
+		}
+	}
+
+	return chapter, nil
 }
 
 func (u *chapterUC) GetChaptersBySubject(ctx context.Context, subject string, grade int) ([]*models.Chapter, error) {
@@ -39,13 +60,94 @@ func (u *chapterUC) GetChaptersBySubject(ctx context.Context, subject string, gr
 }
 
 func (u *chapterUC) UpdateChapter(ctx context.Context, chapter *models.Chapter) (*models.Chapter, error) {
+	existing, err := u.chapterRepo.GetChapterByID(ctx, chapter.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authorizeChapter(ctx, existing, models.AccessActionUpdate); err != nil {
+		return nil, err
+	}
 	return u.chapterRepo.UpdateChapter(ctx, chapter)
 }
 
 func (u *chapterUC) DeleteChapter(ctx context.Context, chapterID uuid.UUID) error {
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
+	if err != nil {
+		return err
+	}
+	if err := u.authorizeChapter(ctx, chapter, models.AccessActionDelete); err != nil {
+		return err
+	}
 	return u.chapterRepo.DeleteChapter(ctx, chapterID)
 }
 
+// authorizeChapter allows the owner of the chapter and the users granted chapters:manage
+func (u *chapterUC) authorizeChapter(ctx context.Context, chapter *models.Chapter, action string) error {
+	return u.authzUC.Authorize(ctx, &models.AccessRequest{
+		Action:       action,
+		ResourceType: models.ResourceChapter,
+		ResourceID:   chapter.ChapterID.String(),
+		OwnerID:      chapter.CreatedBy,
+		Permission:   models.PermissionChaptersManage,
+	})
+}
+
+// authorizeQuiz checks the access to a quiz against the chapter its lesson belongs to. Quizzes of a custom chapter
+// are as private as the chapter, the others can be read by anyone
+func (u *chapterUC) authorizeQuiz(ctx context.Context, quiz *models.Quiz, chapter *models.Chapter, action string) error {
+	if action == models.AccessActionRead && !chapter.IsCustom {
+		return nil
+	}
+
+	return u.authzUC.Authorize(ctx, &models.AccessRequest{
+		Action:       action,
+		ResourceType: models.ResourceQuiz,
+		ResourceID:   quiz.QuizID.String(),
+		OwnerID:      chapter.CreatedBy,
+		Permission:   models.PermissionChaptersManage,
+	})
+}
+
+// getReadableQuiz loads the quiz and its chapter once the user of the context is allowed to read it
+func (u *chapterUC) getReadableQuiz(ctx context.Context, quizID uuid.UUID) (*models.Quiz, *models.Chapter, error) {
+	quiz, err := u.chapterRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get quiz: %w", err)
+	}
+
+	chapter, err := u.quizChapter(ctx, quiz)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := u.authorizeQuiz(ctx, quiz, chapter, models.AccessActionRead); err != nil {
+		return nil, nil, err
+	}
+
+	return quiz, chapter, nil
+}
+
+// quizChapter loads the chapter of the quiz's lesson
+func (u *chapterUC) quizChapter(ctx context.Context, quiz *models.Quiz) (*models.Chapter, error) {
+	lesson, err := u.chapterRepo.GetLessonByID(ctx, quiz.LessonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lesson: %w", err)
+	}
+
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, lesson.ChapterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chapter: %w", err)
+	}
+
+	return chapter, nil
+}
+
+// managesChapter tells whether the user of the context owns the chapter or is granted chapters:manage, without
+// auditing a refusal. The answer keys of a quiz are only served to them
+func managesChapter(ctx context.Context, chapter *models.Chapter) bool {
+	user, err := utils.GetUserFromCtx(ctx)
+	return err == nil && (user.UserID == chapter.CreatedBy || user.HasPermission(models.PermissionChaptersManage))
+}
+
 func (u *chapterUC) GenerateChapterWithAI(ctx context.Context, prompt string, subject string, grade int, userID uuid.UUID, contextContent string) (*models.Chapter, error) {
 
 	chapter, err := u.aiService.GenerateChapterContent(ctx, prompt, subject, grade, contextContent)
@@ -202,6 +304,15 @@ func shouldGenerateImage(mu *sync.Mutex, imageCount *int, maxImagesPerMinute int
 
 func (u *chapterUC) GenerateMemesForChapter(ctx context.Context, chapterID uuid.UUID, topic string) ([]*models.LessonMedia, error) {
 
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chapter: %w", err)
+	}
+	// The memes are saved with the chapter's media, generating them changes the chapter like generating a quiz
+	if err := u.authorizeChapter(ctx, chapter, models.AccessActionUpdate); err != nil {
+		return nil, err
+	}
+
 	memes, err := u.aiService.GenerateMemes(ctx, topic, 1, "openai")
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate memes: %v", err)
@@ -222,6 +333,9 @@ func (u *chapterUC) GenerateQuizForChapter(ctx context.Context, chapterID uuid.U
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chapter: %v", err)
 	}
+	if err := u.authorizeChapter(ctx, chapter, models.AccessActionUpdate); err != nil {
+		return nil, err
+	}
 
 	quiz, questions, err := u.aiService.GenerateQuizContent(ctx, chapter.Description)
 	if err != nil {
@@ -262,6 +376,12 @@ func (u *chapterUC) GetCustomLessonsByChapter(ctx context.Context, chapterID uui
 		return nil, fmt.Errorf("chapter not found")
 	}
 
+	if chapter.IsCustom {
+		if err := u.authorizeChapter(ctx, chapter, models.AccessActionRead); err != nil {
+			return nil, err
+		}
+	}
+
 	lessons, err := u.chapterRepo.GetCustomLessonsByChapter(ctx, chapterID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get custom lessons: %w", err)
@@ -272,6 +392,16 @@ func (u *chapterUC) GetCustomLessonsByChapter(ctx context.Context, chapterID uui
 
 func (u *chapterUC) CreateCustomLesson(ctx context.Context, lesson *models.Lesson, userID uuid.UUID) (*models.Lesson, error) {
 
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, lesson.ChapterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chapter: %w", err)
+	}
+
+	// Adding a lesson changes the chapter, it is up to the owner of the chapter
+	if err := u.authorizeChapter(ctx, chapter, models.AccessActionUpdate); err != nil {
+		return nil, err
+	}
+
 	lesson.CreatedBy = userID
 
 	lesson.IsCustom = true
@@ -289,6 +419,23 @@ func (u *chapterUC) GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*mod
 		return nil, fmt.Errorf("failed to get lesson: %w", err)
 	}
 
+	// The lessons of a custom chapter are as private as the chapter
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, lesson.ChapterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chapter: %w", err)
+	}
+	if chapter.IsCustom {
+		if err := u.authzUC.Authorize(ctx, &models.AccessRequest{
+			Action:       models.AccessActionRead,
+			ResourceType: models.ResourceLesson,
+			ResourceID:   lessonID.String(),
+			OwnerID:      chapter.CreatedBy,
+			Permission:   models.PermissionChaptersManage,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	return lesson, nil
 }
 
@@ -296,10 +443,15 @@ func (u *chapterUC) GetQuizzesByChapterID(ctx context.Context, chapterID uuid.UU
 	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.GetQuizzesByChapterID")
 	defer span.Finish()
 
-	_, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
 	if err != nil {
 		return nil, err
 	}
+	if chapter.IsCustom {
+		if err := u.authorizeChapter(ctx, chapter, models.AccessActionRead); err != nil {
+			return nil, err
+		}
+	}
 
 	quizzes, err := u.chapterRepo.GetQuizzesByChapterID(ctx, chapterID)
 	if err != nil {
@@ -311,9 +463,9 @@ func (u *chapterUC) GetQuizzesByChapterID(ctx context.Context, chapterID uuid.UU
 
 func (u *chapterUC) GetQuizByID(ctx context.Context, quizID uuid.UUID) (*models.Quiz, []*models.Question, error) {
 
-	quiz, err := u.chapterRepo.GetQuizByID(ctx, quizID)
+	quiz, _, err := u.getReadableQuiz(ctx, quizID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get quiz: %w", err)
+		return nil, nil, err
 	}
 
 	questions, err := u.chapterRepo.GetQuestionsByQuizID(ctx, quizID)
@@ -330,9 +482,8 @@ func (u *chapterUC) GetQuizByID(ctx context.Context, quizID uuid.UUID) (*models.
 
 func (u *chapterUC) SubmitQuizAnswers(ctx context.Context, userID uuid.UUID, quizID uuid.UUID, answers []*models.UserQuestionResponse) (*models.UserQuizAttempt, error) {
 
-	_, err := u.chapterRepo.GetQuizByID(ctx, quizID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get quiz: %w", err)
+	if _, _, err := u.getReadableQuiz(ctx, quizID); err != nil {
+		return nil, err
 	}
 
 	attempt := &models.UserQuizAttempt{
@@ -348,44 +499,151 @@ func (u *chapterUC) SubmitQuizAnswers(ctx context.Context, userID uuid.UUID, qui
 		return nil, fmt.Errorf("failed to get questions for quiz: %w", err)
 	}
 
+	return u.gradeAttempt(ctx, attempt, questions, answers)
+}
+
+func (u *chapterUC) DrawQuizQuestions(ctx context.Context, quizID uuid.UUID, count int) ([]*models.Question, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.DrawQuizQuestions")
+	defer span.Finish()
+
+	if _, _, err := u.getReadableQuiz(ctx, quizID); err != nil {
+		return nil, err
+	}
+
+	// The set keeps its answer keys, the challenges and live sessions grade against them
+	questions, err := u.chapterRepo.GetQuestionsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("quiz has no questions")
+	}
+
+	rand.Shuffle(len(questions), func(i, j int) {
+		questions[i], questions[j] = questions[j], questions[i]
+	})
+	if count > 0 && count < len(questions) {
+		questions = questions[:count]
+	}
+
+	return questions, nil
+}
+
+func (u *chapterUC) SubmitQuestionSetAnswers(
+	ctx context.Context,
+	userID uuid.UUID,
+	quizID uuid.UUID,
+	questionIDs []uuid.UUID,
+	answers []*models.UserQuestionResponse,
+	timeSpent int,
+) (*models.UserQuizAttempt, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.SubmitQuestionSetAnswers")
+	defer span.Finish()
+
+	questions, err := u.quizQuestions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make([]*models.Question, 0, len(questionIDs))
+	for i := range questionIDs {
+		question := findQuestion(questions, &questionIDs[i])
+		if question == nil {
+			return nil, fmt.Errorf("question with ID %s not found in quiz", questionIDs[i])
+		}
+		set = append(set, question)
+	}
+
+	attempt := &models.UserQuizAttempt{
+		UserID:      userID,
+		QuizID:      quizID,
+		TimeSpent:   timeSpent,
+		CompletedAt: time.Now(),
+	}
+
+	return u.gradeAttempt(ctx, attempt, set, answers)
+}
+
+// gradeAttempt scores the answers against the given questions of the quiz, saves the attempt with its responses
+// and awards its XP and streak activity
+func (u *chapterUC) gradeAttempt(
+	ctx context.Context,
+	attempt *models.UserQuizAttempt,
+	questions []*models.Question,
+	answers []*models.UserQuestionResponse,
+) (*models.UserQuizAttempt, error) {
 	questionMap := make(map[string]*models.Question)
 	totalPoints := 0
 	for _, q := range questions {
+		if _, exists := questionMap[q.QuestionID.String()]; exists {
+			continue
+		}
 		questionMap[q.QuestionID.String()] = q
 		totalPoints += q.Points
 	}
 
+	// Each question counts once, a repeated answer would add its points and XP again
+	answered := make(map[string]bool, len(answers))
+	graded := make([]*models.UserQuestionResponse, 0, len(answers))
 	userPoints := 0
+	correctAnswers := 0
 	for _, answer := range answers {
-		question, exists := questionMap[answer.QuestionID.String()]
+		questionID := answer.QuestionID.String()
+		if answered[questionID] {
+			return nil, fmt.Errorf("question with ID %s is answered more than once", answer.QuestionID)
+		}
+		answered[questionID] = true
+
+		// Answers to questions outside the quiz, or outside the drawn set, are not graded
+		question, exists := questionMap[questionID]
 		if !exists {
-			return nil, fmt.Errorf("question with ID %s not found in quiz", answer.QuestionID)
+			continue
 		}
 
 		isCorrect := answer.UserAnswer == question.Answer
 		answer.IsCorrect = isCorrect
+		graded = append(graded, answer)
 
 		if isCorrect {
 			userPoints += question.Points
+			correctAnswers++
 		}
 	}
+	answers = graded
 
 	if totalPoints > 0 {
 		attempt.Score = (userPoints * 100) / totalPoints
 	}
+	if attempt.Score > 100 {
+		attempt.Score = 100
+	}
+
+	return u.saveAttempt(ctx, attempt, answers, correctAnswers)
+}
 
-	savedAttempt, err := u.chapterRepo.CreateQuizAttempt(ctx, attempt)
+// saveAttempt saves the attempt with its responses and XP in one transaction, then records its streak activity
+func (u *chapterUC) saveAttempt(
+	ctx context.Context,
+	attempt *models.UserQuizAttempt,
+	responses []*models.UserQuestionResponse,
+	correctAnswers int,
+) (*models.UserQuizAttempt, error) {
+	var award *models.XPAward
+	savedAttempt, err := u.chapterRepo.CreateQuizAttempt(ctx, attempt, responses, func(tx *sqlx.Tx) error {
+		var err error
+		award, err = u.xpUC.AwardQuizAttempt(ctx, tx, attempt, correctAnswers)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to save quiz attempt: %w", err)
 	}
+	savedAttempt.XPAward = award
 
-	for _, answer := range answers {
-		answer.AttemptID = savedAttempt.AttemptID
-		if err := u.chapterRepo.CreateQuestionResponse(ctx, answer); err != nil {
-			u.logger.Errorf("failed to save question response: %v", err)
-
-		}
+	streakChange, err := u.streakUC.RecordActivity(ctx, savedAttempt.UserID, savedAttempt.CompletedAt)
+	if err != nil {
+		u.logger.Errorf("failed to record streak activity: %v", err)
 	}
+	savedAttempt.Streak = streakChange
 
 	return savedAttempt, nil
 }
@@ -421,10 +679,15 @@ func (u *chapterUC) GetQuizByChapter(ctx context.Context, chapterID uuid.UUID) (
 	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.GetQuizByChapter")
 	defer span.Finish()
 
-	_, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
+	chapter, err := u.chapterRepo.GetChapterByID(ctx, chapterID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chapter: %w", err)
 	}
+	if chapter.IsCustom {
+		if err := u.authorizeChapter(ctx, chapter, models.AccessActionRead); err != nil {
+			return nil, err
+		}
+	}
 
 	return u.chapterRepo.GetQuizByChapter(ctx, chapterID)
 }
@@ -433,6 +696,49 @@ func (u *chapterUC) GetQuestionsByQuizID(ctx context.Context, quizID uuid.UUID)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.GetQuestionsByQuizID")
 	defer span.Finish()
 
+	_, chapter, err := u.getReadableQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := u.chapterRepo.GetQuestionsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Linked bank items keep their answer keys from the users who only take the quiz
+	if !managesChapter(ctx, chapter) {
+		for _, q := range questions {
+			q.Answer = ""
+		}
+	}
+
+	return questions, nil
+}
+
+func (u *chapterUC) GetQuestionSet(ctx context.Context, quizID uuid.UUID, questionIDs []uuid.UUID) ([]*models.Question, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "chapterUC.GetQuestionSet")
+	defer span.Finish()
+
+	questions, err := u.quizQuestions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make([]*models.Question, 0, len(questionIDs))
+	for i := range questionIDs {
+		// Deleted from the quiz since the set was drawn
+		if question := findQuestion(questions, &questionIDs[i]); question != nil {
+			set = append(set, question)
+		}
+	}
+
+	return set, nil
+}
+
+// quizQuestions loads the questions of the quiz with their answer keys. It checks no access, the callers serve
+// sets that were drawn by a user allowed to read the quiz
+func (u *chapterUC) quizQuestions(ctx context.Context, quizID uuid.UUID) ([]*models.Question, error) {
 	_, err := u.chapterRepo.GetQuizByID(ctx, quizID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get quiz: %w", err)
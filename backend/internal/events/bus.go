@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Handler consumes an event, a returned error makes the bus retry it
+type Handler func(ctx context.Context, event *Event) error
+
+// Publisher announces domain events that are not part of a database transaction
+type Publisher interface {
+	Publish(ctx context.Context, userID uuid.UUID, payload Payload) error
+}
+
+// Recorder writes an event into the outbox within the transaction of the change it describes,
+// so the event exists if and only if the change was committed
+type Recorder interface {
+	Record(ctx context.Context, tx sqlx.ExecerContext, userID uuid.UUID, payload Payload) error
+}
+
+// Bus delivers published events to the subscribers of their type
+type Bus interface {
+	Publisher
+
+	// Subscribe registers a named handler for the given event types, all subscriptions are made before Start
+	Subscribe(name string, handler Handler, types ...string)
+
+	// Dispatch runs every handler subscribed to the event once, except the subscribers in skip,
+	// and returns the outcome per subscriber. The outbox delivers its events through it
+	Dispatch(event *Event, skip map[string]bool) map[string]error
+
+	Start()
+	Stop()
+}
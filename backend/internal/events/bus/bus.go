@@ -0,0 +1,137 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+// Event bus backends
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+const (
+	defaultBufferSize     = 1024
+	defaultRetryDelay     = 500 * time.Millisecond
+	defaultHandlerTimeout = 10 * time.Second
+)
+
+// NewEventBus creates the event bus for the configured backend
+func NewEventBus(cfg config.EventsConfig, redisClient *redis.Client, logger logger.Logger) (events.Bus, error) {
+	d := newDispatcher(cfg, logger)
+
+	switch cfg.Backend {
+	case "", BackendMemory:
+		bufferSize := cfg.BufferSize
+		if bufferSize <= 0 {
+			bufferSize = defaultBufferSize
+		}
+		return newMemoryBus(d, bufferSize), nil
+	case BackendRedis:
+		if redisClient == nil {
+			return nil, errors.New("the redis events backend needs a redis client")
+		}
+		return newRedisBus(d, redisClient, cfg.Stream), nil
+	}
+
+	return nil, errors.Errorf("unknown events backend %q", cfg.Backend)
+}
+
+type subscription struct {
+	name    string
+	types   map[string]bool
+	handler events.Handler
+}
+
+func newSubscription(name string, handler events.Handler, types []string) *subscription {
+	sub := &subscription{name: name, handler: handler, types: make(map[string]bool, len(types))}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	return sub
+}
+
+// dispatcher runs the handlers of both backends, detached from the publishing request,
+// with a timeout per attempt and retries after a doubling delay
+type dispatcher struct {
+	maxRetries int
+	retryDelay time.Duration
+	timeout    time.Duration
+	stopCh     chan struct{}
+	logger     logger.Logger
+}
+
+func newDispatcher(cfg config.EventsConfig, logger logger.Logger) *dispatcher {
+	d := &dispatcher{
+		maxRetries: cfg.MaxRetries,
+		retryDelay: cfg.RetryDelay * time.Millisecond,
+		timeout:    cfg.HandlerTimeout * time.Second,
+		stopCh:     make(chan struct{}),
+		logger:     logger,
+	}
+	if d.retryDelay <= 0 {
+		d.retryDelay = defaultRetryDelay
+	}
+	if d.timeout <= 0 {
+		d.timeout = defaultHandlerTimeout
+	}
+	return d
+}
+
+// deliver hands the event to the subscriber until it succeeds, the retries run out or the bus stops
+func (d *dispatcher) deliver(sub *subscription, event *events.Event) {
+	delay := d.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := d.handle(sub, event)
+		if err == nil {
+			return
+		}
+		if attempt > d.maxRetries {
+			d.logger.Errorf("Event %s %s dropped by %s after %d attempts: %v", event.Type, event.EventID, sub.name, attempt, err)
+			return
+		}
+
+		d.logger.Warnf("Event %s %s failed in %s, attempt %d: %v", event.Type, event.EventID, sub.name, attempt, err)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-d.stopCh:
+			d.logger.Errorf("Event %s %s abandoned by %s on shutdown", event.Type, event.EventID, sub.name)
+			return
+		}
+	}
+}
+
+// dispatch runs the matching handlers once, without retries, the caller keeps track of the failures
+func (d *dispatcher) dispatch(subs []*subscription, event *events.Event, skip map[string]bool) map[string]error {
+	results := make(map[string]error)
+	for _, sub := range subs {
+		if !sub.types[event.Type] || skip[sub.name] {
+			continue
+		}
+		results[sub.name] = d.handle(sub, event)
+	}
+	return results
+}
+
+func (d *dispatcher) handle(sub *subscription, event *events.Event) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	return sub.handler(ctx, event)
+}
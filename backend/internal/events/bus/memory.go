@@ -0,0 +1,92 @@
+package bus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+)
+
+// memoryBus delivers events within the process, every subscriber consumes its own queue in order
+type memoryBus struct {
+	*dispatcher
+	bufferSize int
+	subs       []*memorySubscription
+	wg         sync.WaitGroup
+}
+
+type memorySubscription struct {
+	*subscription
+	queue chan *events.Event
+}
+
+func newMemoryBus(d *dispatcher, bufferSize int) *memoryBus {
+	return &memoryBus{dispatcher: d, bufferSize: bufferSize}
+}
+
+func (b *memoryBus) Subscribe(name string, handler events.Handler, types ...string) {
+	b.subs = append(b.subs, &memorySubscription{
+		subscription: newSubscription(name, handler, types),
+		queue:        make(chan *events.Event, b.bufferSize),
+	})
+}
+
+// Publish queues the event without waiting for the subscribers, a full queue drops it
+func (b *memoryBus) Publish(ctx context.Context, userID uuid.UUID, payload events.Payload) error {
+	event, err := events.NewEvent(userID, payload)
+	if err != nil {
+		return errors.Wrap(err, "memoryBus.Publish.NewEvent")
+	}
+
+	for _, sub := range b.subs {
+		if !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.queue <- event:
+		default:
+			b.logger.Errorf("Event %s %s dropped, the %s queue is full", event.Type, event.EventID, sub.name)
+		}
+	}
+
+	return nil
+}
+
+func (b *memoryBus) Dispatch(event *events.Event, skip map[string]bool) map[string]error {
+	subs := make([]*subscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub.subscription)
+	}
+	return b.dispatch(subs, event, skip)
+}
+
+func (b *memoryBus) Start() {
+	b.logger.Infof("Starting memory event bus with %d subscribers", len(b.subs))
+
+	for _, sub := range b.subs {
+		b.wg.Add(1)
+		go func(sub *memorySubscription) {
+			defer b.wg.Done()
+			for {
+				select {
+				case event := <-sub.queue:
+					b.deliver(sub.subscription, event)
+				case <-b.stopCh:
+					if pending := len(sub.queue); pending > 0 {
+						b.logger.Errorf("%d events for %s were not delivered before shutdown", pending, sub.name)
+					}
+					return
+				}
+			}
+		}(sub)
+	}
+}
+
+func (b *memoryBus) Stop() {
+	b.logger.Info("Stopping memory event bus")
+	close(b.stopCh)
+	b.wg.Wait()
+}
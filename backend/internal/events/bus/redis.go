@@ -0,0 +1,172 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+)
+
+const (
+	defaultStream    = "api-mc:events"
+	maxStreamLength  = 100000
+	readBatchSize    = 10
+	readBlockTimeout = 5 * time.Second
+	readErrorBackoff = time.Second
+)
+
+// redisBus delivers events through a Redis stream. Every subscriber is a consumer group,
+// so each one sees every event while the instances of the API share the work
+type redisBus struct {
+	*dispatcher
+	client   *redis.Client
+	stream   string
+	consumer string
+	subs     []*subscription
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newRedisBus(d *dispatcher, client *redis.Client, stream string) *redisBus {
+	if stream == "" {
+		stream = defaultStream
+	}
+	hostname, _ := os.Hostname()
+	return &redisBus{
+		dispatcher: d,
+		client:     client,
+		stream:     stream,
+		consumer:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+func (b *redisBus) Subscribe(name string, handler events.Handler, types ...string) {
+	b.subs = append(b.subs, newSubscription(name, handler, types))
+}
+
+func (b *redisBus) Publish(ctx context.Context, userID uuid.UUID, payload events.Payload) error {
+	event, err := events.NewEvent(userID, payload)
+	if err != nil {
+		return errors.Wrap(err, "redisBus.Publish.NewEvent")
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "redisBus.Publish.json.Marshal")
+	}
+
+	if err := b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream,
+		MaxLen: maxStreamLength,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	}).Err(); err != nil {
+		return errors.Wrap(err, "redisBus.Publish.XAdd")
+	}
+
+	return nil
+}
+
+func (b *redisBus) Dispatch(event *events.Event, skip map[string]bool) map[string]error {
+	return b.dispatch(b.subs, event, skip)
+}
+
+func (b *redisBus) Start() {
+	b.logger.Infof("Starting redis event bus on stream %s with %d subscribers", b.stream, len(b.subs))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+
+	for _, sub := range b.subs {
+		group := b.stream + ":" + sub.name
+		if err := b.client.XGroupCreateMkStream(ctx, b.stream, group, "$").Err(); err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			b.logger.Errorf("Error creating consumer group %s: %v", group, err)
+			continue
+		}
+
+		b.wg.Add(1)
+		go func(sub *subscription, group string) {
+			defer b.wg.Done()
+			b.consume(ctx, sub, group)
+		}(sub, group)
+	}
+}
+
+func (b *redisBus) Stop() {
+	b.logger.Info("Stopping redis event bus")
+	close(b.stopCh)
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+}
+
+// consume first replays the events this consumer read but did not acknowledge before a restart, then reads new ones
+func (b *redisBus) consume(ctx context.Context, sub *subscription, group string) {
+	lastID := "0"
+	for ctx.Err() == nil {
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: b.consumer,
+			Streams:  []string{b.stream, lastID},
+			Count:    readBatchSize,
+			Block:    readBlockTimeout,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.Errorf("Error reading events for %s: %v", sub.name, err)
+			select {
+			case <-time.After(readErrorBackoff):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		messages := 0
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				messages++
+				b.handleMessage(sub, message)
+				if err := b.client.XAck(ctx, b.stream, group, message.ID).Err(); err != nil {
+					b.logger.Errorf("Error acknowledging event %s for %s: %v", message.ID, sub.name, err)
+				}
+			}
+		}
+
+		if lastID == "0" && messages == 0 {
+			lastID = ">"
+		}
+	}
+}
+
+func (b *redisBus) handleMessage(sub *subscription, message redis.XMessage) {
+	data, ok := message.Values["event"].(string)
+	if !ok {
+		b.logger.Errorf("Event %s has no payload", message.ID)
+		return
+	}
+
+	event := &events.Event{}
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		b.logger.Errorf("Error decoding event %s: %v", message.ID, err)
+		return
+	}
+
+	if sub.types[event.Type] {
+		b.deliver(sub, event)
+	}
+}
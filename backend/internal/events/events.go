@@ -0,0 +1,138 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types
+const (
+	QuizSubmittedType      = "quiz.submitted"
+	LessonCompletedType    = "lesson.completed"
+	ChapterCompletedType   = "chapter.completed"
+	XPAwardedType          = "xp.awarded"
+	LevelUpType            = "xp.level_up"
+	StreakUpdatedType      = "streak.updated"
+	AchievementAwardedType = "achievement.awarded"
+	ChallengeReceivedType  = "challenge.received"
+	ChallengeEndedType     = "challenge.ended"
+)
+
+// Payload is the typed body of an event
+type Payload interface {
+	EventType() string
+}
+
+// Event is the envelope every domain event is published in
+type Event struct {
+	EventID    uuid.UUID       `json:"event_id"`
+	Type       string          `json:"type"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// NewEvent wraps a payload in an envelope for the given user
+func NewEvent(userID uuid.UUID, payload Payload) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		EventID:    uuid.New(),
+		Type:       payload.EventType(),
+		UserID:     userID,
+		Payload:    data,
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// Decode unmarshals the event payload into its typed form
+func (e *Event) Decode(payload Payload) error {
+	return json.Unmarshal(e.Payload, payload)
+}
+
+// QuizSubmitted is published once a quiz attempt, regular or adaptive, is saved
+type QuizSubmitted struct {
+	AttemptID uuid.UUID `json:"attempt_id"`
+	QuizID    uuid.UUID `json:"quiz_id"`
+	Score     int       `json:"score"`
+}
+
+func (QuizSubmitted) EventType() string { return QuizSubmittedType }
+
+// LessonCompleted is published on the first completion of a lesson
+type LessonCompleted struct {
+	LessonID  uuid.UUID `json:"lesson_id"`
+	TimeSpent int       `json:"time_spent"` // in seconds
+}
+
+func (LessonCompleted) EventType() string { return LessonCompletedType }
+
+// ChapterCompleted is published when a lesson completion finished the last open lesson of its chapter
+type ChapterCompleted struct {
+	ChapterID uuid.UUID `json:"chapter_id"`
+}
+
+func (ChapterCompleted) EventType() string { return ChapterCompletedType }
+
+// XPAwarded is published when an award added XP to the user's total
+type XPAwarded struct {
+	XPGained int    `json:"xp_gained"`
+	TotalXP  int    `json:"total_xp"`
+	Subject  string `json:"subject"`
+	Grade    int    `json:"grade"`
+}
+
+func (XPAwarded) EventType() string { return XPAwardedType }
+
+// LevelUp is published when the total XP crossed one or more level thresholds
+type LevelUp struct {
+	FromLevel     int `json:"from_level"`
+	ToLevel       int `json:"to_level"`
+	XP            int `json:"xp"`
+	RewardFreezes int `json:"reward_freezes"`
+}
+
+func (LevelUp) EventType() string { return LevelUpType }
+
+// StreakUpdated is published when the daily streak was extended, broken, repaired or kept by freezes
+type StreakUpdated struct {
+	CurrentStreak int  `json:"current_streak"`
+	MaxStreak     int  `json:"max_streak"`
+	Extended      bool `json:"extended"`
+	Broken        bool `json:"broken"`
+	Repaired      bool `json:"repaired"`
+	FreezesUsed   int  `json:"freezes_used"`
+	BrokenStreak  int  `json:"broken_streak"` // length of the streak that broke when Broken, repairable within the repair window
+}
+
+func (StreakUpdated) EventType() string { return StreakUpdatedType }
+
+// AchievementAwarded is published when a user earned an achievement
+type AchievementAwarded struct {
+	AchievementID uuid.UUID `json:"achievement_id"`
+}
+
+func (AchievementAwarded) EventType() string { return AchievementAwardedType }
+
+// ChallengeReceived is published to the opponent when a user challenged them
+type ChallengeReceived struct {
+	ChallengeID  uuid.UUID `json:"challenge_id"`
+	ChallengerID uuid.UUID `json:"challenger_id"`
+	QuizID       uuid.UUID `json:"quiz_id"`
+}
+
+func (ChallengeReceived) EventType() string { return ChallengeReceivedType }
+
+// ChallengeEnded is published to each player when their challenge was declined, expired or completed
+type ChallengeEnded struct {
+	ChallengeID uuid.UUID  `json:"challenge_id"`
+	OpponentID  uuid.UUID  `json:"opponent_id"` // the other player
+	Status      string     `json:"status"`
+	WinnerID    *uuid.UUID `json:"winner_id,omitempty"` // nil for a draw or a challenge that was not played
+}
+
+func (ChallengeEnded) EventType() string { return ChallengeEndedType }
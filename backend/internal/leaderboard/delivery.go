@@ -7,12 +7,28 @@ type Handlers interface {
 	// Get leaderboard with optional filtering
 	GetLeaderboard() echo.HandlerFunc
 
+	// Get the entries around a user
+	GetLeaderboardAroundUser() echo.HandlerFunc
+
+	// Get the closed periods and their archived standings
+	GetPeriods() echo.HandlerFunc
+	GetPeriodStandings() echo.HandlerFunc
+
+	// Get a user's rank history over the closed periods
+	GetUserRankHistory() echo.HandlerFunc
+
 	// Get a specific user's rank
 	GetUserRank() echo.HandlerFunc
 
+	// Get a user's rank on every board
+	GetUserRanks() echo.HandlerFunc
+
 	// Get top performers for a specific metric
 	GetTopPerformers() echo.HandlerFunc
 
 	// Admin endpoints
 	RecalculateRankings() echo.HandlerFunc
+	CreateClass() echo.HandlerFunc
+	AddClassMember() echo.HandlerFunc
+	RemoveClassMember() echo.HandlerFunc
 }
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -11,6 +13,7 @@ import (
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 // LeaderboardHandlers implements the leaderboard.Handlers interface
@@ -38,64 +41,264 @@ func getUserIDFromToken(c echo.Context) (uuid.UUID, bool) {
 
 // GetLeaderboard godoc
 // @Summary Get leaderboard entries
-// @Description Get leaderboard entries with optional filtering
+// @Description Get the entries of the global board, or of a subject, grade or class board.
+// @Description A daily, weekly or monthly time frame ranks the XP earned in the period in progress.
+// @Description friends=true ranks the signed in user among the users they follow
 // @Tags Leaderboard
 // @Accept json
 // @Produce json
 // @Param time_frame query string false "Time frame (daily, weekly, monthly, all-time)"
-// @Param subject query string false "Filter by subject"
-// @Param grade query int false "Filter by grade"
+// @Param subject query string false "Subject board"
+// @Param grade query int false "Grade board"
+// @Param class_id query string false "Class board"
+// @Param friends query bool false "Friends board"
 // @Param limit query int false "Number of entries to return (default: 10)"
 // @Success 200 {object} models.LeaderboardResponse
 // @Failure 400 {object} httpErrors.RestErr
+// @Failure 401 {object} httpErrors.RestErr
 // @Failure 500 {object} httpErrors.RestErr
 // @Router /leaderboard [get]
 func (h *LeaderboardHandlers) GetLeaderboard() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		// Parse query parameters
-		filter := &models.LeaderboardFilter{
-			TimeFrame: c.QueryParam("time_frame"),
-			Subject:   c.QueryParam("subject"),
+		filter, err := parseFilter(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
 		}
 
-		// Parse grade if provided
-		if gradeStr := c.QueryParam("grade"); gradeStr != "" {
-			grade, err := strconv.Atoi(gradeStr)
-			if err != nil {
-				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid grade parameter"))
+		// Get user ID from token if available (for getting user's rank)
+		userID, ok := getUserIDFromToken(c)
+		if ok {
+			filter.UserID = userID
+		}
+
+		// Get leaderboard
+		board, err := h.leaderboardUC.GetLeaderboard(c.Request().Context(), filter)
+		if err != nil {
+			if errors.Is(err, leaderboard.ErrAmbiguousScope) || errors.Is(err, leaderboard.ErrUnknownTimeFrame) {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+			}
+			if errors.Is(err, leaderboard.ErrFriendsRequireUser) {
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err.Error()))
 			}
-			filter.Grade = grade
+			h.logger.Errorf("Error getting leaderboard: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting leaderboard"))
 		}
 
-		// Parse limit if provided
-		if limitStr := c.QueryParam("limit"); limitStr != "" {
-			limit, err := strconv.Atoi(limitStr)
-			if err != nil {
-				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid limit parameter"))
+		return c.JSON(http.StatusOK, board)
+	}
+}
+
+// GetLeaderboardAroundUser godoc
+// @Summary Get the entries around a user
+// @Description Get the entries ranked just above and below a user on the global board, or on a subject, grade or class board.
+// @Description The friends board is only available around me
+// @Tags Leaderboard
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID or me"
+// @Param time_frame query string false "Time frame (daily, weekly, monthly, all-time)"
+// @Param subject query string false "Subject board"
+// @Param grade query int false "Grade board"
+// @Param class_id query string false "Class board"
+// @Param friends query bool false "Friends board"
+// @Param radius query int false "Entries above and below the user (default: 5)"
+// @Success 200 {object} models.LeaderboardResponse
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 403 {object} httpErrors.RestErr
+// @Failure 404 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/users/{user_id}/around [get]
+func (h *LeaderboardHandlers) GetLeaderboardAroundUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter, err := parseFilter(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		// Parse user ID from path
+		userIDParam := c.Param("user_id")
+		if userIDParam == "me" {
+			// Get current user's ID from token
+			userID, ok := getUserIDFromToken(c)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError("Unauthorized"))
 			}
-			filter.Limit = limit
+			userIDParam = userID.String()
 		}
 
-		// Get user ID from token if available (for getting user's rank)
+		if filter.UserID, err = uuid.Parse(userIDParam); err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid user ID"))
+		}
+
+		// Whom a user follows is private to them
+		if filter.Friends {
+			if userID, ok := getUserIDFromToken(c); !ok || userID != filter.UserID {
+				return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError("Friends leaderboard is only available around yourself"))
+			}
+		}
+
+		radius := 0
+		if radiusStr := c.QueryParam("radius"); radiusStr != "" {
+			if radius, err = strconv.Atoi(radiusStr); err != nil {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid radius parameter"))
+			}
+		}
+
+		board, err := h.leaderboardUC.GetLeaderboardAroundUser(c.Request().Context(), filter, radius)
+		if err != nil {
+			if errors.Is(err, leaderboard.ErrAmbiguousScope) || errors.Is(err, leaderboard.ErrUnknownTimeFrame) {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+			}
+			if errors.Is(err, leaderboard.ErrUserNotRanked) {
+				return c.JSON(http.StatusNotFound, httpErrors.NewNotFoundError(err.Error()))
+			}
+			h.logger.Errorf("Error getting leaderboard around user: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting leaderboard"))
+		}
+
+		return c.JSON(http.StatusOK, board)
+	}
+}
+
+// GetPeriods godoc
+// @Summary Get the closed periods
+// @Description Get the daily, weekly or monthly periods whose final standings were archived, most recent first
+// @Tags Leaderboard
+// @Accept json
+// @Produce json
+// @Param time_frame query string true "Time frame (daily, weekly, monthly)"
+// @Param limit query int false "Number of periods to return (default: 12)"
+// @Success 200 {array} models.LeaderboardPeriod
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/history [get]
+func (h *LeaderboardHandlers) GetPeriods() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter, err := parseFilter(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		periods, err := h.leaderboardUC.GetPeriods(c.Request().Context(), filter.TimeFrame, filter.Limit)
+		if err != nil {
+			if errors.Is(err, leaderboard.ErrPeriodRequired) {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+			}
+			h.logger.Errorf("Error getting leaderboard periods: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting leaderboard periods"))
+		}
+
+		return c.JSON(http.StatusOK, periods)
+	}
+}
+
+// GetPeriodStandings godoc
+// @Summary Get the final standings of a closed period
+// @Description Get the archived standings of the global board, or of a subject, grade or class board,
+// @Description for the closed period containing a date
+// @Tags Leaderboard
+// @Accept json
+// @Produce json
+// @Param date path string true "Any day of the period (YYYY-MM-DD, in the leaderboard timezone)"
+// @Param time_frame query string true "Time frame (daily, weekly, monthly)"
+// @Param subject query string false "Subject board"
+// @Param grade query int false "Grade board"
+// @Param class_id query string false "Class board"
+// @Param limit query int false "Number of entries to return (default: 10)"
+// @Success 200 {object} models.LeaderboardStandings
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 404 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/history/{date} [get]
+func (h *LeaderboardHandlers) GetPeriodStandings() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter, err := parseFilter(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		date, err := time.Parse("2006-01-02", c.Param("date"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid date, expected YYYY-MM-DD"))
+		}
+
+		// Get user ID from token if available (for getting user's standing)
 		userID, ok := getUserIDFromToken(c)
 		if ok {
 			filter.UserID = userID
 		}
 
-		// Get leaderboard
-		leaderboard, err := h.leaderboardUC.GetLeaderboard(c.Request().Context(), filter)
+		standings, err := h.leaderboardUC.GetPeriodStandings(c.Request().Context(), filter, date)
 		if err != nil {
-			h.logger.Errorf("Error getting leaderboard: %v", err)
-			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting leaderboard"))
+			if errors.Is(err, leaderboard.ErrAmbiguousScope) || errors.Is(err, leaderboard.ErrPeriodRequired) {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+			}
+			if errors.Is(err, leaderboard.ErrPeriodNotFound) {
+				return c.JSON(http.StatusNotFound, httpErrors.NewNotFoundError(err.Error()))
+			}
+			h.logger.Errorf("Error getting leaderboard standings: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting leaderboard standings"))
+		}
+
+		return c.JSON(http.StatusOK, standings)
+	}
+}
+
+// GetUserRankHistory godoc
+// @Summary Get a user's rank history
+// @Description Get a user's final rank on the global board, or on a subject, grade or class board,
+// @Description for the last closed periods, oldest first. Periods the user earned no XP in are left out
+// @Tags Leaderboard
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID or me"
+// @Param time_frame query string true "Time frame (daily, weekly, monthly)"
+// @Param subject query string false "Subject board"
+// @Param grade query int false "Grade board"
+// @Param class_id query string false "Class board"
+// @Param limit query int false "Number of periods to return (default: 12)"
+// @Success 200 {array} models.LeaderboardSnapshotEntry
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/users/{user_id}/history [get]
+func (h *LeaderboardHandlers) GetUserRankHistory() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter, err := parseFilter(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		// Parse user ID from path
+		userIDParam := c.Param("user_id")
+		if userIDParam == "me" {
+			// Get current user's ID from token
+			userID, ok := getUserIDFromToken(c)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError("Unauthorized"))
+			}
+			userIDParam = userID.String()
+		}
+
+		if filter.UserID, err = uuid.Parse(userIDParam); err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid user ID"))
 		}
 
-		return c.JSON(http.StatusOK, leaderboard)
+		history, err := h.leaderboardUC.GetUserRankHistory(c.Request().Context(), filter, filter.Limit)
+		if err != nil {
+			if errors.Is(err, leaderboard.ErrAmbiguousScope) || errors.Is(err, leaderboard.ErrPeriodRequired) {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+			}
+			h.logger.Errorf("Error getting user rank history: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting user rank history"))
+		}
+
+		return c.JSON(http.StatusOK, history)
 	}
 }
 
 // GetUserRank godoc
 // @Summary Get a user's rank
-// @Description Get a specific user's rank and stats
+// @Description Get a specific user's global rank and stats
 // @Tags Leaderboard
 // @Accept json
 // @Produce json
@@ -134,9 +337,49 @@ func (h *LeaderboardHandlers) GetUserRank() echo.HandlerFunc {
 	}
 }
 
+// GetUserRanks godoc
+// @Summary Get a user's ranks on every board
+// @Description Get a user's entry on the global board and on each subject, grade and class board they belong to
+// @Tags Leaderboard
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {array} models.LeaderboardEntry
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/users/{user_id}/boards [get]
+func (h *LeaderboardHandlers) GetUserRanks() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse user ID from path
+		userIDParam := c.Param("user_id")
+		if userIDParam == "me" {
+			// Get current user's ID from token
+			userID, ok := getUserIDFromToken(c)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError("Unauthorized"))
+			}
+			userIDParam = userID.String()
+		}
+
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid user ID"))
+		}
+
+		// Get user ranks
+		userRanks, err := h.leaderboardUC.GetUserRanks(c.Request().Context(), userID)
+		if err != nil {
+			h.logger.Errorf("Error getting user ranks: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error getting user ranks"))
+		}
+
+		return c.JSON(http.StatusOK, userRanks)
+	}
+}
+
 // GetTopPerformers godoc
 // @Summary Get top performers
-// @Description Get top performers for a specific metric
+// @Description Get top performers of the global board for a specific metric
 // @Tags Leaderboard
 // @Accept json
 // @Produce json
@@ -183,7 +426,7 @@ func (h *LeaderboardHandlers) GetTopPerformers() echo.HandlerFunc {
 
 // RecalculateRankings godoc
 // @Summary Recalculate rankings
-// @Description Recalculate all rankings (public endpoint)
+// @Description Archive the periods that ended, rebuild all entries from the XP ledger and recalculate the rankings of every board (public endpoint)
 // @Tags Leaderboard
 // @Accept json
 // @Produce json
@@ -203,3 +446,150 @@ func (h *LeaderboardHandlers) RecalculateRankings() echo.HandlerFunc {
 		})
 	}
 }
+
+// CreateClass godoc
+// @Summary Create a class
+// @Description Create a class, its members are ranked on their own board
+// @Tags Leaderboard
+// @Accept json
+// @Produce json
+// @Param class body models.Class true "Class"
+// @Success 201 {object} models.Class
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/admin/classes [post]
+func (h *LeaderboardHandlers) CreateClass() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		class := &models.Class{}
+		if err := c.Bind(class); err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("Invalid class"))
+		}
+
+		if err := utils.ValidateStruct(c.Request().Context(), class); err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		createdClass, err := h.leaderboardUC.CreateClass(c.Request().Context(), class)
+		if err != nil {
+			h.logger.Errorf("Error creating class: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error creating class"))
+		}
+
+		return c.JSON(http.StatusCreated, createdClass)
+	}
+}
+
+// AddClassMember godoc
+// @Summary Add a class member
+// @Description Add a user to a class and to its board
+// @Tags Leaderboard
+// @Param class_id path string true "Class ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/admin/classes/{class_id}/members/{user_id} [put]
+func (h *LeaderboardHandlers) AddClassMember() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		classID, userID, err := classMemberParams(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		if err := h.leaderboardUC.AddClassMember(c.Request().Context(), classID, userID); err != nil {
+			h.logger.Errorf("Error adding class member: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error adding class member"))
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// RemoveClassMember godoc
+// @Summary Remove a class member
+// @Description Remove a user from a class and from its board
+// @Tags Leaderboard
+// @Param class_id path string true "Class ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} httpErrors.RestErr
+// @Failure 404 {object} httpErrors.RestErr
+// @Failure 500 {object} httpErrors.RestErr
+// @Router /leaderboard/admin/classes/{class_id}/members/{user_id} [delete]
+func (h *LeaderboardHandlers) RemoveClassMember() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		classID, userID, err := classMemberParams(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(err.Error()))
+		}
+
+		if err := h.leaderboardUC.RemoveClassMember(c.Request().Context(), classID, userID); err != nil {
+			if errors.Is(err, leaderboard.ErrClassMemberNotFound) {
+				return c.JSON(http.StatusNotFound, httpErrors.NewNotFoundError(err.Error()))
+			}
+			h.logger.Errorf("Error removing class member: %v", err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError("Error removing class member"))
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// classMemberParams parses the class and user IDs of a class member route
+func classMemberParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	classID, err := uuid.Parse(c.Param("class_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid class ID")
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid user ID")
+	}
+	return classID, userID, nil
+}
+
+// parseFilter reads the board and time frame query parameters shared by the leaderboard routes
+func parseFilter(c echo.Context) (*models.LeaderboardFilter, error) {
+	filter := &models.LeaderboardFilter{
+		TimeFrame: c.QueryParam("time_frame"),
+		Subject:   c.QueryParam("subject"),
+	}
+
+	// Parse grade if provided
+	if gradeStr := c.QueryParam("grade"); gradeStr != "" {
+		grade, err := strconv.Atoi(gradeStr)
+		if err != nil {
+			return nil, errors.New("invalid grade parameter")
+		}
+		filter.Grade = grade
+	}
+
+	// Parse class ID if provided
+	if classIDStr := c.QueryParam("class_id"); classIDStr != "" {
+		classID, err := uuid.Parse(classIDStr)
+		if err != nil {
+			return nil, errors.New("invalid class_id parameter")
+		}
+		filter.ClassID = &classID
+	}
+
+	// Parse friends if provided
+	if friendsStr := c.QueryParam("friends"); friendsStr != "" {
+		friends, err := strconv.ParseBool(friendsStr)
+		if err != nil {
+			return nil, errors.New("invalid friends parameter")
+		}
+		filter.Friends = friends
+	}
+
+	// Parse limit if provided
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, errors.New("invalid limit parameter")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
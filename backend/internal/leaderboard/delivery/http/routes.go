@@ -5,6 +5,7 @@ import (
 
 	"github.com/AleksK1NG/api-mc/internal/leaderboard"
 	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
@@ -15,13 +16,11 @@ func MapLeaderboardRoutes(
 	mw *middleware.MiddlewareManager,
 	logger logger.Logger,
 ) {
-	// Public routes
-	leaderboardGroup.GET("", h.GetLeaderboard())
+	// Public routes, a signed in user also gets their rank and the friends board
+	leaderboardGroup.GET("", h.GetLeaderboard(), mw.OptionalAuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
 	leaderboardGroup.GET("/top", h.GetTopPerformers())
-
-	// Recalculate endpoint - this is now public and can be triggered manually if needed
-	// Note: Leaderboard is automatically recalculated every 10 minutes by the background worker
-	leaderboardGroup.POST("/recalculate", h.RecalculateRankings())
+	leaderboardGroup.GET("/history", h.GetPeriods())
+	leaderboardGroup.GET("/history/:date", h.GetPeriodStandings())
 
 	// Protected routes (require authentication)
 	protected := leaderboardGroup.Group("")
@@ -29,44 +28,18 @@ func MapLeaderboardRoutes(
 	{
 		// User routes
 		protected.GET("/users/:user_id", h.GetUserRank())
+		protected.GET("/users/:user_id/boards", h.GetUserRanks())
+		protected.GET("/users/:user_id/around", h.GetLeaderboardAroundUser())
+		protected.GET("/users/:user_id/history", h.GetUserRankHistory())
 
 		// Admin routes
+		// Note: Leaderboard is automatically reconciled every Leaderboard.ReconcileInterval minutes by the background worker
 		admin := protected.Group("/admin")
 		{
-			admin.POST("/recalculate", h.RecalculateRankings())
+			admin.POST("/recalculate", h.RecalculateRankings(), mw.RequirePermission(models.PermissionJobsRun))
+			admin.POST("/classes", h.CreateClass(), mw.RequirePermission(models.PermissionClassesManage))
+			admin.PUT("/classes/:class_id/members/:user_id", h.AddClassMember(), mw.RequirePermission(models.PermissionClassesManage))
+			admin.DELETE("/classes/:class_id/members/:user_id", h.RemoveClassMember(), mw.RequirePermission(models.PermissionClassesManage))
 		}
 	}
 }
-
-// RegisterLeaderboardMiddleware registers the leaderboard tracking middleware
-func RegisterLeaderboardMiddleware(
-	e *echo.Echo,
-	leaderboardUC leaderboard.UseCase,
-	logger logger.Logger,
-) {
-	// Create the leaderboard tracking middleware
-	leaderboardMiddleware := LeaderboardTrackingMiddleware(leaderboardUC, logger)
-
-	// Apply the middleware to relevant endpoints
-	// Auth endpoints (profile updates)
-	if authGroup := e.Group("/auth"); authGroup != nil {
-		authGroup.Use(leaderboardMiddleware)
-	}
-
-	// Lesson endpoints
-	if lessonGroup := e.Group("/lessons"); lessonGroup != nil {
-		lessonGroup.Use(leaderboardMiddleware)
-	}
-
-	// Quiz endpoints
-	if quizGroup := e.Group("/quizzes"); quizGroup != nil {
-		quizGroup.Use(leaderboardMiddleware)
-	}
-
-	// Chapter endpoints
-	if chapterGroup := e.Group("/chapters"); chapterGroup != nil {
-		chapterGroup.Use(leaderboardMiddleware)
-	}
-
-	logger.Info("Leaderboard tracking middleware registered")
-}
@@ -0,0 +1,14 @@
+package leaderboard
+
+import "errors"
+
+// Leaderboard filter, period and class errors
+var (
+	ErrUnknownTimeFrame    = errors.New("time_frame must be daily, weekly, monthly or all-time")
+	ErrPeriodRequired      = errors.New("time_frame must be daily, weekly or monthly")
+	ErrPeriodNotFound      = errors.New("no archived standings for this period")
+	ErrAmbiguousScope      = errors.New("only one of subject, grade and class_id can be set")
+	ErrClassMemberNotFound = errors.New("user is not a member of the class")
+	ErrUserNotRanked       = errors.New("user is not on this leaderboard")
+	ErrFriendsRequireUser  = errors.New("sign in to see the friends leaderboard")
+)
@@ -8,14 +8,70 @@ import (
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
+// Repository serves the boards, implemented by Postgres and by Redis sorted sets.
+// A filter with a Period reads the board of that open period instead of the all-time board
 type Repository interface {
 	GetLeaderboard(ctx context.Context, filter *models.LeaderboardFilter) (*models.LeaderboardResponse, error)
 
+	// GetLeaderboardAroundUser returns the entries up to radius ranks above and below filter.UserID
+	GetLeaderboardAroundUser(ctx context.Context, filter *models.LeaderboardFilter, radius int) (*models.LeaderboardResponse, error)
+
 	GetUserRank(ctx context.Context, userID uuid.UUID) (*models.LeaderboardEntry, error)
 
-	UpdateUserStats(ctx context.Context, entry *models.LeaderboardEntry) error
+	// GetUserRanks returns the user's entry on every board they belong to
+	GetUserRanks(ctx context.Context, userID uuid.UUID) ([]*models.LeaderboardEntry, error)
+
+	// SyncUserEntries recomputes the user's entries from the XP ledger
+	SyncUserEntries(ctx context.Context, userID uuid.UUID) error
+
+	// RebuildEntries recomputes every entry from the XP ledger and drops the boards users left
+	RebuildEntries(ctx context.Context) error
 
 	RecalculateRankings(ctx context.Context) error
 
+	// SyncUserPeriodEntries recomputes the user's entries on the boards of an open period from the XP ledger
+	SyncUserPeriodEntries(ctx context.Context, userID uuid.UUID, period *models.LeaderboardPeriod) error
+
+	// RebuildPeriodEntries recomputes every entry of an open period from the XP ledger
+	RebuildPeriodEntries(ctx context.Context, period *models.LeaderboardPeriod) error
+
+	// GetOpenPeriods lists the periods that have entries and are not archived yet
+	GetOpenPeriods(ctx context.Context) ([]*models.LeaderboardPeriod, error)
+
+	// ClosePeriod archives the final standings of every board of a period and drops its entries,
+	// it returns false when the period was archived already
+	ClosePeriod(ctx context.Context, period *models.LeaderboardPeriod) (bool, error)
+
+	// GetClosedPeriods lists the archived periods of a kind, most recent first
+	GetClosedPeriods(ctx context.Context, period string, limit int) ([]*models.LeaderboardPeriod, error)
+
+	// GetPeriodStandings returns the archived standings of the filter's board for a closed period
+	GetPeriodStandings(ctx context.Context, period *models.LeaderboardPeriod, filter *models.LeaderboardFilter) (*models.LeaderboardStandings, error)
+
+	// GetUserRankHistory returns the user's final standings on the filter's board for the last closed periods, oldest first
+	GetUserRankHistory(ctx context.Context, period string, filter *models.LeaderboardFilter, limit int) ([]*models.LeaderboardSnapshotEntry, error)
+
 	GetTopPerformers(ctx context.Context, metric string, limit int) ([]*models.LeaderboardEntry, error)
+
+	// Classes
+	CreateClass(ctx context.Context, class *models.Class) (*models.Class, error)
+	AddClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error
+	RemoveClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error
+}
+
+// SourceRepository is the Postgres source of truth the Redis boards are built from
+type SourceRepository interface {
+	Repository
+
+	// GetBoards lists every board that has entries, the boards of the period when it is not nil
+	GetBoards(ctx context.Context, period *models.LeaderboardPeriod) ([]*models.LeaderboardBoard, error)
+
+	// GetBoardEntries returns every entry of a board, without the user details
+	GetBoardEntries(ctx context.Context, board *models.LeaderboardBoard) ([]*models.LeaderboardEntry, error)
+
+	// GetEntriesByUserIDs returns the entries of the given users on a board
+	GetEntriesByUserIDs(ctx context.Context, board *models.LeaderboardBoard, userIDs []uuid.UUID) ([]*models.LeaderboardEntry, error)
+
+	// GetUserPeriodEntries returns the user's entry on every board of an open period
+	GetUserPeriodEntries(ctx context.Context, userID uuid.UUID, period *models.LeaderboardPeriod) ([]*models.LeaderboardEntry, error)
 }
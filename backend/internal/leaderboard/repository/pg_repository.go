@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/AleksK1NG/api-mc/internal/leaderboard"
 	"github.com/AleksK1NG/api-mc/internal/models"
@@ -19,16 +21,69 @@ type PostgresRepository struct {
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository for leaderboard
-func NewPostgresRepository(db *sqlx.DB, logger logger.Logger) leaderboard.Repository {
+func NewPostgresRepository(db *sqlx.DB, logger logger.Logger) leaderboard.SourceRepository {
 	return &PostgresRepository{
 		db:     db,
 		logger: logger,
 	}
 }
 
-// GetLeaderboard retrieves leaderboard entries with optional filtering
+// boardQueries are the queries serving a board: the all-time board or the board of an open period,
+// either whole or narrowed to the user's friends. They all take the board arguments first, followed by their own
+type boardQueries struct {
+	entries   string
+	count     string
+	userEntry string
+	window    string
+	args      []interface{}
+}
+
+func queriesFor(filter *models.LeaderboardFilter) *boardQueries {
+	switch {
+	case filter.Friends && filter.Period == nil:
+		return &boardQueries{
+			entries:   getFriendsLeaderboardQuery,
+			count:     countFriendsLeaderboardQuery,
+			userEntry: getUserFriendsBoardEntryQuery,
+			window:    getFriendsLeaderboardWindowQuery,
+			args:      []interface{}{filter.Scope, filter.ScopeKey, filter.UserID},
+		}
+	case filter.Friends:
+		return &boardQueries{
+			entries:   getPeriodFriendsLeaderboardQuery,
+			count:     countPeriodFriendsLeaderboardQuery,
+			userEntry: getUserPeriodFriendsBoardEntryQuery,
+			window:    getPeriodFriendsLeaderboardWindowQuery,
+			args: []interface{}{
+				filter.Period.Period, filter.Period.PeriodStart, filter.Scope, filter.ScopeKey, filter.UserID,
+			},
+		}
+	case filter.Period == nil:
+		return &boardQueries{
+			entries:   getLeaderboardQuery,
+			count:     countLeaderboardBaseQuery,
+			userEntry: getUserBoardEntryQuery,
+			window:    getLeaderboardWindowQuery,
+			args:      []interface{}{filter.Scope, filter.ScopeKey},
+		}
+	default:
+		return &boardQueries{
+			entries:   getPeriodLeaderboardQuery,
+			count:     countPeriodLeaderboardQuery,
+			userEntry: getUserPeriodBoardEntryQuery,
+			window:    getPeriodLeaderboardWindowQuery,
+			args:      []interface{}{filter.Period.Period, filter.Period.PeriodStart, filter.Scope, filter.ScopeKey},
+		}
+	}
+}
+
+func (q *boardQueries) with(args ...interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(q.args)+len(args)), q.args...), args...)
+}
+
+// GetLeaderboard retrieves the entries of the board chosen by the filter
 func (r *PostgresRepository) GetLeaderboard(ctx context.Context, filter *models.LeaderboardFilter) (*models.LeaderboardResponse, error) {
-	var entries []*models.LeaderboardEntry
+	entries := make([]*models.LeaderboardEntry, 0)
 	var totalEntries int
 
 	// Set default limit if not specified
@@ -37,54 +92,16 @@ func (r *PostgresRepository) GetLeaderboard(ctx context.Context, filter *models.
 		limit = filter.Limit
 	}
 
-	// Build query based on filters
-	query := getLeaderboardBaseQuery
-	countQuery := countLeaderboardBaseQuery
-	args := []interface{}{}
-
-	// Add filters
-	if filter.Subject != "" {
-		query += ` AND le.subject = $` + fmt.Sprintf("%d", len(args)+1)
-		countQuery += ` AND le.subject = $` + fmt.Sprintf("%d", len(args)+1)
-		args = append(args, filter.Subject)
-	}
-
-	if filter.Grade > 0 {
-		query += ` AND le.grade = $` + fmt.Sprintf("%d", len(args)+1)
-		countQuery += ` AND le.grade = $` + fmt.Sprintf("%d", len(args)+1)
-		args = append(args, filter.Grade)
-	}
-
-	// Add time frame filter if specified
-	if filter.TimeFrame != "" {
-		var timeCondition string
-		switch filter.TimeFrame {
-		case "daily":
-			timeCondition = dailyTimeFrameCondition
-		case "weekly":
-			timeCondition = weeklyTimeFrameCondition
-		case "monthly":
-			timeCondition = monthlyTimeFrameCondition
-		}
-
-		if timeCondition != "" {
-			query += " AND " + timeCondition
-			countQuery += " AND " + timeCondition
-		}
-	}
-
-	// Add order by and limit
-	query += ` ORDER BY le.rank ASC LIMIT $` + fmt.Sprintf("%d", len(args)+1)
-	args = append(args, limit)
+	queries := queriesFor(filter)
 
 	// Execute query
-	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+	if err := r.db.SelectContext(ctx, &entries, queries.entries, queries.with(limit)...); err != nil {
 		r.logger.Errorf("Error getting leaderboard entries: %v", err)
 		return nil, err
 	}
 
 	// Get total count
-	if err := r.db.GetContext(ctx, &totalEntries, countQuery, args[:len(args)-1]...); err != nil {
+	if err := r.db.GetContext(ctx, &totalEntries, queries.count, queries.args...); err != nil {
 		r.logger.Errorf("Error getting total leaderboard entries count: %v", err)
 		return nil, err
 	}
@@ -92,24 +109,72 @@ func (r *PostgresRepository) GetLeaderboard(ctx context.Context, filter *models.
 	// Get user rank if requested
 	var userRank *models.LeaderboardEntry
 	if filter.UserID != uuid.Nil {
-		if err := r.db.GetContext(ctx, &userRank, getUserRankQuery, filter.UserID); err != nil {
+		entry := &models.LeaderboardEntry{}
+		if err := r.db.GetContext(ctx, entry, queries.userEntry, queries.with(filter.UserID)...); err != nil {
 			r.logger.Warnf("User %s not found in leaderboard: %v", filter.UserID, err)
 			// Not returning error as this is not a critical failure
+		} else {
+			userRank = entry
 		}
 	}
 
 	return &models.LeaderboardResponse{
+		Scope:        filter.Scope,
+		ScopeKey:     filter.ScopeKey,
+		Period:       filter.Period,
+		Friends:      filter.Friends,
 		Entries:      entries,
 		TotalEntries: totalEntries,
 		UserRank:     userRank,
 	}, nil
 }
 
-// GetUserRank retrieves a specific user's rank and stats
+// GetLeaderboardAroundUser retrieves the entries within radius ranks of the filter's user
+func (r *PostgresRepository) GetLeaderboardAroundUser(ctx context.Context, filter *models.LeaderboardFilter, radius int) (*models.LeaderboardResponse, error) {
+	queries := queriesFor(filter)
+
+	userRank := &models.LeaderboardEntry{}
+	if err := r.db.GetContext(ctx, userRank, queries.userEntry, queries.with(filter.UserID)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, leaderboard.ErrUserNotRanked
+		}
+		r.logger.Errorf("Error getting user rank: %v", err)
+		return nil, err
+	}
+
+	var totalEntries int
+	if err := r.db.GetContext(ctx, &totalEntries, queries.count, queries.args...); err != nil {
+		r.logger.Errorf("Error getting total leaderboard entries count: %v", err)
+		return nil, err
+	}
+
+	entries := make([]*models.LeaderboardEntry, 0, 2*radius+1)
+	if err := r.db.SelectContext(
+		ctx,
+		&entries,
+		queries.window,
+		queries.with(userRank.Rank-radius, userRank.Rank+radius)...,
+	); err != nil {
+		r.logger.Errorf("Error getting leaderboard window: %v", err)
+		return nil, err
+	}
+
+	return &models.LeaderboardResponse{
+		Scope:        filter.Scope,
+		ScopeKey:     filter.ScopeKey,
+		Period:       filter.Period,
+		Friends:      filter.Friends,
+		Entries:      entries,
+		TotalEntries: totalEntries,
+		UserRank:     userRank,
+	}, nil
+}
+
+// GetUserRank retrieves a specific user's global rank and stats
 func (r *PostgresRepository) GetUserRank(ctx context.Context, userID uuid.UUID) (*models.LeaderboardEntry, error) {
 	var entry models.LeaderboardEntry
 
-	if err := r.db.GetContext(ctx, &entry, getUserRankQuery, userID); err != nil {
+	if err := r.db.GetContext(ctx, &entry, getUserBoardRankQuery, userID, models.LeaderboardScopeGlobal, ""); err != nil {
 		r.logger.Errorf("Error getting user rank: %v", err)
 		return nil, err
 	}
@@ -117,32 +182,52 @@ func (r *PostgresRepository) GetUserRank(ctx context.Context, userID uuid.UUID)
 	return &entry, nil
 }
 
-// UpdateUserStats updates a user's leaderboard entry
-func (r *PostgresRepository) UpdateUserStats(ctx context.Context, entry *models.LeaderboardEntry) error {
-	if entry.EntryID == uuid.Nil {
-		entry.EntryID = uuid.New()
+// GetUserRanks retrieves a user's entry on every board they belong to
+func (r *PostgresRepository) GetUserRanks(ctx context.Context, userID uuid.UUID) ([]*models.LeaderboardEntry, error) {
+	entries := make([]*models.LeaderboardEntry, 0)
+
+	if err := r.db.SelectContext(ctx, &entries, getUserRanksQuery, userID); err != nil {
+		r.logger.Errorf("Error getting user ranks: %v", err)
+		return nil, err
 	}
 
-	row := r.db.QueryRowContext(
-		ctx,
-		updateUserStatsQuery,
-		entry.EntryID,
-		entry.UserID,
-		entry.XP,
-		entry.Level,
-		entry.Streak,
-		entry.Rank,
-	)
-
-	if err := row.Scan(&entry.EntryID, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
-		r.logger.Errorf("Error updating user stats: %v", err)
+	return entries, nil
+}
+
+// SyncUserEntries recomputes a user's entries from the XP ledger
+func (r *PostgresRepository) SyncUserEntries(ctx context.Context, userID uuid.UUID) error {
+	return r.syncEntries(ctx, &userID)
+}
+
+// RebuildEntries recomputes the entries of every user from the XP ledger
+func (r *PostgresRepository) RebuildEntries(ctx context.Context) error {
+	return r.syncEntries(ctx, nil)
+}
+
+// syncEntries upserts the scoped entries of a user, or of all users when userID is nil,
+// and deletes the entries of boards they no longer belong to
+func (r *PostgresRepository) syncEntries(ctx context.Context, userID *uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Errorf("Error starting leaderboard sync: %v", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	if _, err := tx.ExecContext(ctx, upsertScopedEntriesQuery, userID); err != nil {
+		r.logger.Errorf("Error upserting leaderboard entries: %v", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteStaleEntriesQuery, userID); err != nil {
+		r.logger.Errorf("Error deleting stale leaderboard entries: %v", err)
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// RecalculateRankings recalculates all rankings
+// RecalculateRankings recalculates the rankings of every board
 func (r *PostgresRepository) RecalculateRankings(ctx context.Context) error {
 	_, err := r.db.ExecContext(ctx, recalculateRankingsQuery)
 	if err != nil {
@@ -153,9 +238,196 @@ func (r *PostgresRepository) RecalculateRankings(ctx context.Context) error {
 	return nil
 }
 
-// GetTopPerformers retrieves top performers for a specific metric
+// SyncUserPeriodEntries recomputes a user's entries of an open period from the XP ledger
+func (r *PostgresRepository) SyncUserPeriodEntries(ctx context.Context, userID uuid.UUID, period *models.LeaderboardPeriod) error {
+	return r.syncPeriodEntries(ctx, period, &userID)
+}
+
+// RebuildPeriodEntries recomputes every entry of an open period from the XP ledger
+func (r *PostgresRepository) RebuildPeriodEntries(ctx context.Context, period *models.LeaderboardPeriod) error {
+	return r.syncPeriodEntries(ctx, period, nil)
+}
+
+func (r *PostgresRepository) syncPeriodEntries(ctx context.Context, period *models.LeaderboardPeriod, userID *uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Errorf("Error starting leaderboard period sync: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replacePeriodEntries(ctx, tx, period, userID); err != nil {
+		r.logger.Errorf("Error syncing leaderboard period entries: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replacePeriodEntries deletes the period entries of a user, or of all users when userID is nil,
+// and inserts them again from the XP ledger, which also drops the boards they left
+func replacePeriodEntries(ctx context.Context, tx *sqlx.Tx, period *models.LeaderboardPeriod, userID *uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, deletePeriodEntriesQuery, period.Period, period.PeriodStart, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, insertPeriodEntriesQuery, userID, period.PeriodStart, period.PeriodEnd, period.Period)
+	return err
+}
+
+// GetOpenPeriods lists the periods that have entries and are not archived yet
+func (r *PostgresRepository) GetOpenPeriods(ctx context.Context) ([]*models.LeaderboardPeriod, error) {
+	periods := make([]*models.LeaderboardPeriod, 0)
+	if err := r.db.SelectContext(ctx, &periods, getOpenPeriodsQuery); err != nil {
+		r.logger.Errorf("Error getting open leaderboard periods: %v", err)
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+// ClosePeriod rebuilds the entries of a period from the XP ledger one last time, archives the final standings
+// of its boards and drops the entries. Entries written for a period archived already are dropped without archiving
+func (r *PostgresRepository) ClosePeriod(ctx context.Context, period *models.LeaderboardPeriod) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Errorf("Error starting leaderboard period close: %v", err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, insertClosedPeriodQuery, period.Period, period.PeriodStart, period.PeriodEnd)
+	if err != nil {
+		r.logger.Errorf("Error closing leaderboard period: %v", err)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	closed := rowsAffected > 0
+
+	if closed {
+		if err := replacePeriodEntries(ctx, tx, period, nil); err != nil {
+			r.logger.Errorf("Error rebuilding leaderboard period entries: %v", err)
+			return false, err
+		}
+
+		if _, err := tx.ExecContext(ctx, archivePeriodStandingsQuery, period.Period, period.PeriodStart); err != nil {
+			r.logger.Errorf("Error archiving leaderboard period standings: %v", err)
+			return false, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, deletePeriodEntriesQuery, period.Period, period.PeriodStart, nil); err != nil {
+		r.logger.Errorf("Error deleting leaderboard period entries: %v", err)
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return closed, nil
+}
+
+// GetClosedPeriods lists the archived periods of a kind, most recent first
+func (r *PostgresRepository) GetClosedPeriods(ctx context.Context, period string, limit int) ([]*models.LeaderboardPeriod, error) {
+	periods := make([]*models.LeaderboardPeriod, 0)
+	if err := r.db.SelectContext(ctx, &periods, getClosedPeriodsQuery, period, limit); err != nil {
+		r.logger.Errorf("Error getting closed leaderboard periods: %v", err)
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+// GetPeriodStandings retrieves the archived standings of the filter's board for a closed period
+func (r *PostgresRepository) GetPeriodStandings(
+	ctx context.Context,
+	period *models.LeaderboardPeriod,
+	filter *models.LeaderboardFilter,
+) (*models.LeaderboardStandings, error) {
+	closedPeriod := &models.LeaderboardPeriod{}
+	if err := r.db.GetContext(ctx, closedPeriod, getClosedPeriodQuery, period.Period, period.PeriodStart); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, leaderboard.ErrPeriodNotFound
+		}
+		r.logger.Errorf("Error getting closed leaderboard period: %v", err)
+		return nil, err
+	}
+
+	// Set default limit if not specified
+	limit := 10
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	args := []interface{}{period.Period, period.PeriodStart, filter.Scope, filter.ScopeKey}
+
+	entries := make([]*models.LeaderboardSnapshotEntry, 0)
+	if err := r.db.SelectContext(ctx, &entries, getSnapshotQuery, append(args, limit)...); err != nil {
+		r.logger.Errorf("Error getting leaderboard snapshot: %v", err)
+		return nil, err
+	}
+
+	var totalEntries int
+	if err := r.db.GetContext(ctx, &totalEntries, countSnapshotQuery, args...); err != nil {
+		r.logger.Errorf("Error getting leaderboard snapshot count: %v", err)
+		return nil, err
+	}
+
+	// Get user standing if requested
+	var userRank *models.LeaderboardSnapshotEntry
+	if filter.UserID != uuid.Nil {
+		entry := &models.LeaderboardSnapshotEntry{}
+		if err := r.db.GetContext(ctx, entry, getUserSnapshotQuery, append(args, filter.UserID)...); err != nil {
+			r.logger.Warnf("User %s not found in leaderboard snapshot: %v", filter.UserID, err)
+			// Not returning error as this is not a critical failure
+		} else {
+			userRank = entry
+		}
+	}
+
+	return &models.LeaderboardStandings{
+		Period:       closedPeriod,
+		Scope:        filter.Scope,
+		ScopeKey:     filter.ScopeKey,
+		Entries:      entries,
+		TotalEntries: totalEntries,
+		UserRank:     userRank,
+	}, nil
+}
+
+// GetUserRankHistory retrieves a user's archived standings on the filter's board for the last closed periods
+func (r *PostgresRepository) GetUserRankHistory(
+	ctx context.Context,
+	period string,
+	filter *models.LeaderboardFilter,
+	limit int,
+) ([]*models.LeaderboardSnapshotEntry, error) {
+	entries := make([]*models.LeaderboardSnapshotEntry, 0)
+	if err := r.db.SelectContext(
+		ctx,
+		&entries,
+		getUserRankHistoryQuery,
+		filter.UserID,
+		period,
+		filter.Scope,
+		filter.ScopeKey,
+		limit,
+	); err != nil {
+		r.logger.Errorf("Error getting user rank history: %v", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetTopPerformers retrieves top performers of the global board for a specific metric
 func (r *PostgresRepository) GetTopPerformers(ctx context.Context, metric string, limit int) ([]*models.LeaderboardEntry, error) {
-	var entries []*models.LeaderboardEntry
+	entries := make([]*models.LeaderboardEntry, 0)
 
 	// Set default limit if not specified
 	if limit <= 0 {
@@ -182,3 +454,132 @@ func (r *PostgresRepository) GetTopPerformers(ctx context.Context, metric string
 
 	return entries, nil
 }
+
+// CreateClass creates a class for the class boards
+func (r *PostgresRepository) CreateClass(ctx context.Context, class *models.Class) (*models.Class, error) {
+	created := &models.Class{}
+	if err := r.db.QueryRowxContext(ctx, createClassQuery, class.Name, class.Grade).StructScan(created); err != nil {
+		r.logger.Errorf("Error creating class: %v", err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// AddClassMember adds a user to a class, adding an existing member is a no-op
+func (r *PostgresRepository) AddClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, addClassMemberQuery, classID, userID); err != nil {
+		r.logger.Errorf("Error adding class member: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveClassMember removes a user from a class
+func (r *PostgresRepository) RemoveClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, removeClassMemberQuery, classID, userID)
+	if err != nil {
+		r.logger.Errorf("Error removing class member: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return leaderboard.ErrClassMemberNotFound
+	}
+
+	return nil
+}
+
+// GetBoards lists every board that has entries, the boards of the period when it is not nil
+func (r *PostgresRepository) GetBoards(ctx context.Context, period *models.LeaderboardPeriod) ([]*models.LeaderboardBoard, error) {
+	boards := make([]*models.LeaderboardBoard, 0)
+
+	var err error
+	if period == nil {
+		err = r.db.SelectContext(ctx, &boards, getBoardsQuery)
+	} else {
+		err = r.db.SelectContext(ctx, &boards, getPeriodBoardsQuery, period.Period, period.PeriodStart)
+	}
+	if err != nil {
+		r.logger.Errorf("Error getting leaderboard boards: %v", err)
+		return nil, err
+	}
+
+	for _, board := range boards {
+		board.Period = period
+	}
+
+	return boards, nil
+}
+
+// GetBoardEntries retrieves every entry of a board
+func (r *PostgresRepository) GetBoardEntries(ctx context.Context, board *models.LeaderboardBoard) ([]*models.LeaderboardEntry, error) {
+	entries := make([]*models.LeaderboardEntry, 0)
+
+	var err error
+	if board.Period == nil {
+		err = r.db.SelectContext(ctx, &entries, getBoardEntriesQuery, board.Scope, board.ScopeKey)
+	} else {
+		err = r.db.SelectContext(
+			ctx,
+			&entries,
+			getPeriodBoardEntriesQuery,
+			board.Period.Period,
+			board.Period.PeriodStart,
+			board.Scope,
+			board.ScopeKey,
+		)
+	}
+	if err != nil {
+		r.logger.Errorf("Error getting board entries: %v", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetEntriesByUserIDs retrieves the entries of the given users on a board
+func (r *PostgresRepository) GetEntriesByUserIDs(ctx context.Context, board *models.LeaderboardBoard, userIDs []uuid.UUID) ([]*models.LeaderboardEntry, error) {
+	entries := make([]*models.LeaderboardEntry, 0, len(userIDs))
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+
+	var err error
+	if board.Period == nil {
+		err = r.db.SelectContext(ctx, &entries, getEntriesByUserIDsQuery, board.Scope, board.ScopeKey, pq.Array(userIDs))
+	} else {
+		err = r.db.SelectContext(
+			ctx,
+			&entries,
+			getPeriodEntriesByUserIDsQuery,
+			board.Period.Period,
+			board.Period.PeriodStart,
+			board.Scope,
+			board.ScopeKey,
+			pq.Array(userIDs),
+		)
+	}
+	if err != nil {
+		r.logger.Errorf("Error getting board entries by user IDs: %v", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetUserPeriodEntries retrieves a user's entry on every board of an open period
+func (r *PostgresRepository) GetUserPeriodEntries(ctx context.Context, userID uuid.UUID, period *models.LeaderboardPeriod) ([]*models.LeaderboardEntry, error) {
+	entries := make([]*models.LeaderboardEntry, 0)
+	if err := r.db.SelectContext(ctx, &entries, getUserPeriodEntriesQuery, period.Period, period.PeriodStart, userID); err != nil {
+		r.logger.Errorf("Error getting user period entries: %v", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,548 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/leaderboard"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	boardKeyPrefix   = "leaderboard:"
+	boardRegistryKey = "leaderboard:boards" // every board key, stale boards are dropped on reconciliation
+	rebuildKeySuffix = ":rebuild"
+	rebuildChunkSize = 1000
+	periodBoardTTL   = 24 * time.Hour // period boards outlive their period until it is archived and reconciled
+
+	// Scores pack XP, streak and level so the sorted sets order like the Postgres ranking
+	xpWeight     = 1e7
+	streakWeight = 1e3
+	maxStreak    = 9999
+	maxLevel     = 999
+)
+
+// RedisRepository serves the boards from Redis sorted sets, one per board with the user ids as members.
+// Postgres stays the source of truth: entries are written there first and the sorted sets mirror them
+type RedisRepository struct {
+	redisClient *redis.Client
+	pgRepo      leaderboard.SourceRepository
+	logger      logger.Logger
+}
+
+// NewRedisRepository creates a new Redis sorted-set repository for leaderboard
+func NewRedisRepository(redisClient *redis.Client, pgRepo leaderboard.SourceRepository, logger logger.Logger) leaderboard.Repository {
+	return &RedisRepository{
+		redisClient: redisClient,
+		pgRepo:      pgRepo,
+		logger:      logger,
+	}
+}
+
+// GetLeaderboard retrieves the top of a board, the all-time board or the board of an open period.
+// Friends boards are ranked per user and served by Postgres
+func (r *RedisRepository) GetLeaderboard(ctx context.Context, filter *models.LeaderboardFilter) (*models.LeaderboardResponse, error) {
+	if filter.Friends {
+		return r.pgRepo.GetLeaderboard(ctx, filter)
+	}
+
+	// Set default limit if not specified
+	limit := 10
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	board := filterBoard(filter)
+	key := boardKey(board)
+
+	totalEntries, err := r.redisClient.ZCard(ctx, key).Result()
+	if err != nil {
+		r.logger.Errorf("Error getting leaderboard size: %v", err)
+		return nil, err
+	}
+
+	members, err := r.redisClient.ZRevRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		r.logger.Errorf("Error getting leaderboard entries: %v", err)
+		return nil, err
+	}
+
+	entries, err := r.hydrate(ctx, board, members, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get user rank if requested
+	var userRank *models.LeaderboardEntry
+	if filter.UserID != uuid.Nil {
+		if userRank, err = r.boardEntry(ctx, board, filter.UserID); err != nil {
+			r.logger.Warnf("User %s not found in leaderboard: %v", filter.UserID, err)
+			// Not returning error as this is not a critical failure
+			userRank = nil
+		}
+	}
+
+	return &models.LeaderboardResponse{
+		Scope:        board.Scope,
+		ScopeKey:     board.ScopeKey,
+		Period:       board.Period,
+		Entries:      entries,
+		TotalEntries: int(totalEntries),
+		UserRank:     userRank,
+	}, nil
+}
+
+// GetLeaderboardAroundUser retrieves the entries within radius ranks of the filter's user
+func (r *RedisRepository) GetLeaderboardAroundUser(ctx context.Context, filter *models.LeaderboardFilter, radius int) (*models.LeaderboardResponse, error) {
+	if filter.Friends {
+		return r.pgRepo.GetLeaderboardAroundUser(ctx, filter, radius)
+	}
+
+	board := filterBoard(filter)
+	key := boardKey(board)
+
+	position, err := r.redisClient.ZRevRank(ctx, key, filter.UserID.String()).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, leaderboard.ErrUserNotRanked
+		}
+		r.logger.Errorf("Error getting user rank: %v", err)
+		return nil, err
+	}
+
+	start := position - int64(radius)
+	if start < 0 {
+		start = 0
+	}
+	members, err := r.redisClient.ZRevRange(ctx, key, start, position+int64(radius)).Result()
+	if err != nil {
+		r.logger.Errorf("Error getting leaderboard window: %v", err)
+		return nil, err
+	}
+
+	totalEntries, err := r.redisClient.ZCard(ctx, key).Result()
+	if err != nil {
+		r.logger.Errorf("Error getting leaderboard size: %v", err)
+		return nil, err
+	}
+
+	entries, err := r.hydrate(ctx, board, members, int(start)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var userRank *models.LeaderboardEntry
+	for _, entry := range entries {
+		if entry.UserID == filter.UserID {
+			userRank = entry
+		}
+	}
+
+	return &models.LeaderboardResponse{
+		Scope:        board.Scope,
+		ScopeKey:     board.ScopeKey,
+		Period:       board.Period,
+		Entries:      entries,
+		TotalEntries: int(totalEntries),
+		UserRank:     userRank,
+	}, nil
+}
+
+// GetUserRank retrieves a specific user's global rank and stats
+func (r *RedisRepository) GetUserRank(ctx context.Context, userID uuid.UUID) (*models.LeaderboardEntry, error) {
+	entry, err := r.pgRepo.GetUserRank(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.applyRanks(ctx, []*models.LeaderboardEntry{entry}); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetUserRanks retrieves a user's entry on every board they belong to
+func (r *RedisRepository) GetUserRanks(ctx context.Context, userID uuid.UUID) ([]*models.LeaderboardEntry, error) {
+	entries, err := r.pgRepo.GetUserRanks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.applyRanks(ctx, entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// SyncUserEntries recomputes a user's entries in Postgres and re-scores them on their boards.
+// Scores are set rather than incremented, so a replayed event never counts twice
+func (r *RedisRepository) SyncUserEntries(ctx context.Context, userID uuid.UUID) error {
+	previous, err := r.pgRepo.GetUserRanks(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.pgRepo.SyncUserEntries(ctx, userID); err != nil {
+		return err
+	}
+
+	current, err := r.pgRepo.GetUserRanks(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return r.rescoreUser(ctx, userID, nil, previous, current)
+}
+
+// SyncUserPeriodEntries recomputes a user's entries of an open period in Postgres and re-scores them on the period boards
+func (r *RedisRepository) SyncUserPeriodEntries(ctx context.Context, userID uuid.UUID, period *models.LeaderboardPeriod) error {
+	previous, err := r.pgRepo.GetUserPeriodEntries(ctx, userID, period)
+	if err != nil {
+		return err
+	}
+
+	if err := r.pgRepo.SyncUserPeriodEntries(ctx, userID, period); err != nil {
+		return err
+	}
+
+	current, err := r.pgRepo.GetUserPeriodEntries(ctx, userID, period)
+	if err != nil {
+		return err
+	}
+
+	return r.rescoreUser(ctx, userID, period, previous, current)
+}
+
+// rescoreUser sets the user's scores on the boards of their current entries and removes them from the boards they left
+func (r *RedisRepository) rescoreUser(
+	ctx context.Context,
+	userID uuid.UUID,
+	period *models.LeaderboardPeriod,
+	previous []*models.LeaderboardEntry,
+	current []*models.LeaderboardEntry,
+) error {
+	member := userID.String()
+	kept := make(map[string]bool, len(current))
+
+	pipe := r.redisClient.TxPipeline()
+	for _, entry := range current {
+		key := boardKey(&models.LeaderboardBoard{Scope: entry.Scope, ScopeKey: entry.ScopeKey, Period: period})
+		kept[key] = true
+		pipe.ZAdd(ctx, key, &redis.Z{Score: entryScore(entry), Member: member})
+		pipe.SAdd(ctx, boardRegistryKey, key)
+		if period != nil {
+			pipe.ExpireAt(ctx, key, period.PeriodEnd.Add(periodBoardTTL))
+		}
+	}
+	// Boards the user left, e.g. after a grade or class change
+	for _, entry := range previous {
+		key := boardKey(&models.LeaderboardBoard{Scope: entry.Scope, ScopeKey: entry.ScopeKey, Period: period})
+		if !kept[key] {
+			pipe.ZRem(ctx, key, member)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Errorf("Error updating user leaderboard scores: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RebuildEntries recomputes the entries of every user in Postgres
+func (r *RedisRepository) RebuildEntries(ctx context.Context) error {
+	return r.pgRepo.RebuildEntries(ctx)
+}
+
+// RecalculateRankings ranks the Postgres entries and reconciles every sorted set with them, the boards of the
+// open periods included. Each board is built under a temporary key and renamed over the live one,
+// so readers never see a partial board
+func (r *RedisRepository) RecalculateRankings(ctx context.Context) error {
+	if err := r.pgRepo.RecalculateRankings(ctx); err != nil {
+		return err
+	}
+
+	boards, err := r.pgRepo.GetBoards(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	periods, err := r.pgRepo.GetOpenPeriods(ctx)
+	if err != nil {
+		return err
+	}
+	for _, period := range periods {
+		periodBoards, err := r.pgRepo.GetBoards(ctx, period)
+		if err != nil {
+			return err
+		}
+		boards = append(boards, periodBoards...)
+	}
+
+	live := make(map[string]bool, len(boards))
+	for _, board := range boards {
+		key := boardKey(board)
+		live[key] = true
+
+		if err := r.rebuildBoard(ctx, board, key); err != nil {
+			r.logger.Errorf("Error rebuilding leaderboard %s: %v", key, err)
+			return err
+		}
+	}
+
+	// Drop the boards that no longer have entries, and the boards of the archived periods
+	registered, err := r.redisClient.SMembers(ctx, boardRegistryKey).Result()
+	if err != nil {
+		r.logger.Errorf("Error getting leaderboard registry: %v", err)
+		return err
+	}
+	for _, key := range registered {
+		if live[key] {
+			continue
+		}
+		if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+			r.logger.Errorf("Error deleting stale leaderboard %s: %v", key, err)
+			return err
+		}
+		if err := r.redisClient.SRem(ctx, boardRegistryKey, key).Err(); err != nil {
+			r.logger.Errorf("Error unregistering stale leaderboard %s: %v", key, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RedisRepository) rebuildBoard(ctx context.Context, board *models.LeaderboardBoard, key string) error {
+	entries, err := r.pgRepo.GetBoardEntries(ctx, board)
+	if err != nil {
+		return err
+	}
+
+	rebuildKey := key + rebuildKeySuffix
+	if err := r.redisClient.Del(ctx, rebuildKey).Err(); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(entries); start += rebuildChunkSize {
+		end := start + rebuildChunkSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		members := make([]*redis.Z, 0, end-start)
+		for _, entry := range entries[start:end] {
+			members = append(members, &redis.Z{Score: entryScore(entry), Member: entry.UserID.String()})
+		}
+		if err := r.redisClient.ZAdd(ctx, rebuildKey, members...).Err(); err != nil {
+			return err
+		}
+	}
+
+	if len(entries) == 0 {
+		return r.redisClient.Del(ctx, key).Err()
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Rename(ctx, rebuildKey, key)
+	pipe.SAdd(ctx, boardRegistryKey, key)
+	if board.Period != nil {
+		pipe.ExpireAt(ctx, key, board.Period.PeriodEnd.Add(periodBoardTTL))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RebuildPeriodEntries recomputes every entry of an open period in Postgres
+func (r *RedisRepository) RebuildPeriodEntries(ctx context.Context, period *models.LeaderboardPeriod) error {
+	return r.pgRepo.RebuildPeriodEntries(ctx, period)
+}
+
+// GetOpenPeriods lists the periods that have entries and are not archived yet
+func (r *RedisRepository) GetOpenPeriods(ctx context.Context) ([]*models.LeaderboardPeriod, error) {
+	return r.pgRepo.GetOpenPeriods(ctx)
+}
+
+// ClosePeriod archives a period in Postgres, its boards are dropped on the next reconciliation
+func (r *RedisRepository) ClosePeriod(ctx context.Context, period *models.LeaderboardPeriod) (bool, error) {
+	return r.pgRepo.ClosePeriod(ctx, period)
+}
+
+// GetClosedPeriods lists the archived periods of a kind, most recent first
+func (r *RedisRepository) GetClosedPeriods(ctx context.Context, period string, limit int) ([]*models.LeaderboardPeriod, error) {
+	return r.pgRepo.GetClosedPeriods(ctx, period, limit)
+}
+
+// GetPeriodStandings retrieves the archived standings of a closed period, snapshots are served by Postgres
+func (r *RedisRepository) GetPeriodStandings(
+	ctx context.Context,
+	period *models.LeaderboardPeriod,
+	filter *models.LeaderboardFilter,
+) (*models.LeaderboardStandings, error) {
+	return r.pgRepo.GetPeriodStandings(ctx, period, filter)
+}
+
+// GetUserRankHistory retrieves a user's archived standings, snapshots are served by Postgres
+func (r *RedisRepository) GetUserRankHistory(
+	ctx context.Context,
+	period string,
+	filter *models.LeaderboardFilter,
+	limit int,
+) ([]*models.LeaderboardSnapshotEntry, error) {
+	return r.pgRepo.GetUserRankHistory(ctx, period, filter, limit)
+}
+
+// GetTopPerformers retrieves top performers of the global board, streak and level are served by Postgres
+func (r *RedisRepository) GetTopPerformers(ctx context.Context, metric string, limit int) ([]*models.LeaderboardEntry, error) {
+	if metric == "streak" || metric == "level" {
+		return r.pgRepo.GetTopPerformers(ctx, metric, limit)
+	}
+
+	// Set default limit if not specified
+	if limit <= 0 {
+		limit = 10
+	}
+
+	board := &models.LeaderboardBoard{Scope: models.LeaderboardScopeGlobal}
+	members, err := r.redisClient.ZRevRange(ctx, boardKey(board), 0, int64(limit-1)).Result()
+	if err != nil {
+		r.logger.Errorf("Error getting top performers: %v", err)
+		return nil, err
+	}
+
+	return r.hydrate(ctx, board, members, 1)
+}
+
+// CreateClass creates a class for the class boards
+func (r *RedisRepository) CreateClass(ctx context.Context, class *models.Class) (*models.Class, error) {
+	return r.pgRepo.CreateClass(ctx, class)
+}
+
+// AddClassMember adds a user to a class, their boards are re-scored when the user is synced
+func (r *RedisRepository) AddClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error {
+	return r.pgRepo.AddClassMember(ctx, classID, userID)
+}
+
+// RemoveClassMember removes a user from a class, their boards are re-scored when the user is synced
+func (r *RedisRepository) RemoveClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error {
+	return r.pgRepo.RemoveClassMember(ctx, classID, userID)
+}
+
+// boardEntry retrieves a user's entry on a board with its live rank
+func (r *RedisRepository) boardEntry(ctx context.Context, board *models.LeaderboardBoard, userID uuid.UUID) (*models.LeaderboardEntry, error) {
+	position, err := r.redisClient.ZRevRank(ctx, boardKey(board), userID.String()).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, leaderboard.ErrUserNotRanked
+		}
+		return nil, err
+	}
+
+	entries, err := r.hydrate(ctx, board, []string{userID.String()}, int(position)+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, leaderboard.ErrUserNotRanked
+	}
+
+	return entries[0], nil
+}
+
+// hydrate loads the Postgres entries of the given members in order, ranked from firstRank.
+// Members without an entry, e.g. deleted users not reconciled yet, are skipped
+func (r *RedisRepository) hydrate(ctx context.Context, board *models.LeaderboardBoard, members []string, firstRank int) ([]*models.LeaderboardEntry, error) {
+	userIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		userID, err := uuid.Parse(member)
+		if err != nil {
+			r.logger.Warnf("Invalid leaderboard member %q: %v", member, err)
+			continue
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	found, err := r.pgRepo.GetEntriesByUserIDs(ctx, board, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUserID := make(map[uuid.UUID]*models.LeaderboardEntry, len(found))
+	for _, entry := range found {
+		byUserID[entry.UserID] = entry
+	}
+
+	entries := make([]*models.LeaderboardEntry, 0, len(members))
+	for i, member := range members {
+		userID, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		entry, ok := byUserID[userID]
+		if !ok {
+			continue
+		}
+		entry.Rank = firstRank + i
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// applyRanks replaces the reconciled Postgres ranks of the entries with their live ranks
+func (r *RedisRepository) applyRanks(ctx context.Context, entries []*models.LeaderboardEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	pipe := r.redisClient.Pipeline()
+	ranks := make([]*redis.IntCmd, len(entries))
+	for i, entry := range entries {
+		key := boardKey(&models.LeaderboardBoard{Scope: entry.Scope, ScopeKey: entry.ScopeKey})
+		ranks[i] = pipe.ZRevRank(ctx, key, entry.UserID.String())
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		r.logger.Errorf("Error getting user ranks: %v", err)
+		return err
+	}
+
+	for i, entry := range entries {
+		position, err := ranks[i].Result()
+		if err != nil {
+			// Not on the board yet, keep the reconciled rank
+			continue
+		}
+		entry.Rank = int(position) + 1
+	}
+
+	return nil
+}
+
+// boardKey is leaderboard:{scope}:{scope_key} for the all-time boards and
+// leaderboard:{period}:{period start unix}:{scope}:{scope_key} for the period boards
+func boardKey(board *models.LeaderboardBoard) string {
+	if board.Period != nil {
+		return boardKeyPrefix + board.Period.Period + ":" + strconv.FormatInt(board.Period.PeriodStart.Unix(), 10) + ":" +
+			board.Scope + ":" + board.ScopeKey
+	}
+	return boardKeyPrefix + board.Scope + ":" + board.ScopeKey
+}
+
+func filterBoard(filter *models.LeaderboardFilter) *models.LeaderboardBoard {
+	return &models.LeaderboardBoard{Scope: filter.Scope, ScopeKey: filter.ScopeKey, Period: filter.Period}
+}
+
+// entryScore orders by XP, then streak, then level, equal scores are ordered by user id
+func entryScore(entry *models.LeaderboardEntry) float64 {
+	streak := math.Min(math.Max(float64(entry.Streak), 0), maxStreak)
+	level := math.Min(math.Max(float64(entry.Level), 0), maxLevel)
+	return float64(entry.XP)*xpWeight + streak*streakWeight + level
+}
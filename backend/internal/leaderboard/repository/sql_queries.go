@@ -3,111 +3,458 @@ package repository
 // SQL queries for leaderboard operations
 
 const (
-	// Create leaderboard table
-	// createLeaderboardTableQuery = `
-	// 	CREATE TABLE IF NOT EXISTS leaderboard_entries (
-	// 		entry_id UUID PRIMARY KEY,
-	// 		user_id UUID NOT NULL UNIQUE REFERENCES users(user_id) ON DELETE CASCADE,
-	// 		xp INT NOT NULL DEFAULT 0,
-	// 		level INT NOT NULL DEFAULT 1,
-	// 		streak INT NOT NULL DEFAULT 0,
-	// 		rank INT NOT NULL DEFAULT 0,
-	// 		subject VARCHAR(50),
-	// 		grade INT,
-	// 		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-	// 		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-	// 	);
-
-	// 	CREATE INDEX IF NOT EXISTS idx_leaderboard_rank ON leaderboard_entries(rank);
-	// 	CREATE INDEX IF NOT EXISTS idx_leaderboard_xp ON leaderboard_entries(xp);
-	// 	CREATE INDEX IF NOT EXISTS idx_leaderboard_subject ON leaderboard_entries(subject);
-	// 	CREATE INDEX IF NOT EXISTS idx_leaderboard_grade ON leaderboard_entries(grade);
-	// `
-
 	// Base leaderboard query for selecting entries
 	baseLeaderboardQuery = `
-		SELECT 
-			le.entry_id, le.user_id, u.username, u.avatar_url, 
-			le.xp, le.level, le.streak, le.rank, 
+		SELECT
+			le.entry_id, le.user_id, u.first_name, u.avatar,
+			le.scope, le.scope_key, le.xp, le.level, le.streak, le.rank,
 			le.created_at, le.updated_at
 		FROM leaderboard_entries le
 		JOIN users u ON le.user_id = u.user_id
 	`
 
-	// Get leaderboard entries with filtering
+	// Get leaderboard entries of a board
 	getLeaderboardBaseQuery = baseLeaderboardQuery + `
-		WHERE 1=1
+		WHERE le.scope = $1 AND le.scope_key = $2
+	`
+
+	// Get the top entries of a board
+	getLeaderboardQuery = getLeaderboardBaseQuery + `
+		ORDER BY le.rank ASC
+		LIMIT $3
 	`
 
-	// Count leaderboard entries with filtering
+	// Count leaderboard entries of a board
 	countLeaderboardBaseQuery = `
 		SELECT COUNT(*) FROM leaderboard_entries le
-		WHERE 1=1
+		WHERE le.scope = $1 AND le.scope_key = $2
 	`
 
-	// Get user rank
-	getUserRankQuery = baseLeaderboardQuery + `
+	// Get user rank on a board
+	getUserBoardRankQuery = baseLeaderboardQuery + `
+		WHERE le.user_id = $1 AND le.scope = $2 AND le.scope_key = $3
+	`
+
+	// Get user entry on a board, with the board first like the other board queries
+	getUserBoardEntryQuery = getLeaderboardBaseQuery + `
+		AND le.user_id = $3
+	`
+
+	// Get user rank on every board
+	getUserRanksQuery = baseLeaderboardQuery + `
 		WHERE le.user_id = $1
+		ORDER BY le.scope, le.scope_key
+	`
+
+	// Every board a user belongs to with the XP gained within it, $1 limits it to a user, NULL for all users.
+	// Global and grade boards rank the total XP, subject boards the XP earned in the subject
+	scopedEntriesCTE = `
+		WITH totals AS (
+			SELECT
+				u.user_id, u.grade, u.level,
+				COALESCE(ds.current_streak, 0) AS streak,
+				COALESCE((SELECT SUM(t.amount) FROM xp_transactions t WHERE t.user_id = u.user_id), 0) AS xp
+			FROM users u
+			LEFT JOIN daily_streaks ds ON ds.user_id = u.user_id
+			WHERE ($1::uuid IS NULL OR u.user_id = $1)
+		),
+		scoped AS (
+			SELECT user_id, 'global' AS scope, '' AS scope_key, xp, level, streak
+			FROM totals
+			UNION ALL
+			SELECT tt.user_id, 'subject', t.subject, SUM(t.amount), tt.level, tt.streak
+			FROM totals tt
+			JOIN xp_transactions t ON t.user_id = tt.user_id
+			GROUP BY tt.user_id, t.subject, tt.level, tt.streak
+			UNION ALL
+			SELECT user_id, 'grade', grade::text, xp, level, streak
+			FROM totals
+			UNION ALL
+			SELECT tt.user_id, 'class', cm.class_id::text, tt.xp, tt.level, tt.streak
+			FROM totals tt
+			JOIN class_members cm ON cm.user_id = tt.user_id
+		)
+	`
+
+	// Upsert the scoped entries, unchanged entries keep their updated_at
+	upsertScopedEntriesQuery = scopedEntriesCTE + `
+		INSERT INTO leaderboard_entries (user_id, scope, scope_key, xp, level, streak)
+		SELECT user_id, scope, scope_key, xp, level, streak FROM scoped
+		ON CONFLICT (user_id, scope, scope_key) DO UPDATE SET
+			xp = EXCLUDED.xp,
+			level = EXCLUDED.level,
+			streak = EXCLUDED.streak,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE (leaderboard_entries.xp, leaderboard_entries.level, leaderboard_entries.streak)
+			IS DISTINCT FROM (EXCLUDED.xp, EXCLUDED.level, EXCLUDED.streak)
 	`
 
-	// Update user stats
-	updateUserStatsQuery = `
-		INSERT INTO leaderboard_entries (
-			entry_id, user_id, xp, level, streak, rank, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, NOW(), NOW()
-		) ON CONFLICT (user_id) DO UPDATE SET
-			xp = $3,
-			level = $4,
-			streak = $5,
-			updated_at = NOW()
-		RETURNING entry_id, created_at, updated_at
+	// Delete the entries of boards the users left, e.g. after a grade or class change
+	deleteStaleEntriesQuery = scopedEntriesCTE + `
+		DELETE FROM leaderboard_entries le
+		WHERE ($1::uuid IS NULL OR le.user_id = $1)
+		AND NOT EXISTS (
+			SELECT 1 FROM scoped s
+			WHERE s.user_id = le.user_id AND s.scope = le.scope AND s.scope_key = le.scope_key
+		)
 	`
 
-	// Recalculate rankings
+	// Recalculate rankings within every board, ties are broken by user id in the order Redis uses for equal scores
 	recalculateRankingsQuery = `
 		WITH ranked_users AS (
 			SELECT
 				entry_id,
-				user_id,
-				xp,
-				level,
-				streak,
-				ROW_NUMBER() OVER (ORDER BY xp DESC, streak DESC, level DESC) as new_rank
+				ROW_NUMBER() OVER (
+					PARTITION BY scope, scope_key
+					ORDER BY xp DESC, streak DESC, level DESC, user_id DESC
+				) as new_rank
 			FROM leaderboard_entries
 		)
 		UPDATE leaderboard_entries le
 		SET rank = ru.new_rank
 		FROM ranked_users ru
-		WHERE le.entry_id = ru.entry_id
+		WHERE le.entry_id = ru.entry_id AND le.rank <> ru.new_rank
+	`
+
+	// Get the entries of a board within a rank window
+	getLeaderboardWindowQuery = getLeaderboardBaseQuery + `
+		AND le.rank BETWEEN $3 AND $4
+		ORDER BY le.rank ASC
+	`
+
+	// Every board that has entries
+	getBoardsQuery = `
+		SELECT DISTINCT scope, scope_key FROM leaderboard_entries
+	`
+
+	// Every entry of a board, without the user details
+	getBoardEntriesQuery = `
+		SELECT entry_id, user_id, scope, scope_key, xp, level, streak, rank, created_at, updated_at
+		FROM leaderboard_entries
+		WHERE scope = $1 AND scope_key = $2
+	`
+
+	// The entries of the given users on a board
+	getEntriesByUserIDsQuery = getLeaderboardBaseQuery + `
+		AND le.user_id = ANY($3::uuid[])
+	`
+
+	// Every board of a period that has entries
+	getPeriodBoardsQuery = `
+		SELECT DISTINCT scope, scope_key FROM leaderboard_period_entries
+		WHERE period = $1 AND period_start = $2
 	`
 
-	// Time frame conditions
-	dailyTimeFrameCondition   = "le.updated_at > NOW() - INTERVAL '1 day'"
-	weeklyTimeFrameCondition  = "le.updated_at > NOW() - INTERVAL '7 days'"
-	monthlyTimeFrameCondition = "le.updated_at > NOW() - INTERVAL '30 days'"
+	// Every board a user belongs to with the XP gained within it during the period [$2, $3),
+	// $1 limits it to a user, NULL for all users. Users who earned no XP in the period are on no board
+	periodScopedEntriesCTE = `
+		WITH period_xp AS (
+			SELECT t.user_id, t.subject, SUM(t.amount) AS xp
+			FROM xp_transactions t
+			WHERE t.created_at >= $2 AND t.created_at < $3
+			AND ($1::uuid IS NULL OR t.user_id = $1)
+			GROUP BY t.user_id, t.subject
+		),
+		totals AS (
+			SELECT p.user_id, u.grade, SUM(p.xp) AS xp
+			FROM period_xp p
+			JOIN users u ON u.user_id = p.user_id
+			GROUP BY p.user_id, u.grade
+		),
+		scoped AS (
+			SELECT user_id, 'global' AS scope, '' AS scope_key, xp
+			FROM totals
+			UNION ALL
+			SELECT user_id, 'subject', subject, xp
+			FROM period_xp
+			UNION ALL
+			SELECT user_id, 'grade', grade::text, xp
+			FROM totals
+			UNION ALL
+			SELECT tt.user_id, 'class', cm.class_id::text, tt.xp
+			FROM totals tt
+			JOIN class_members cm ON cm.user_id = tt.user_id
+		)
+	`
+
+	// Insert the scoped entries of a period, the previous entries are deleted first
+	insertPeriodEntriesQuery = periodScopedEntriesCTE + `
+		INSERT INTO leaderboard_period_entries (period, period_start, period_end, user_id, scope, scope_key, xp)
+		SELECT $4, $2, $3, user_id, scope, scope_key, xp FROM scoped
+	`
+
+	// Delete the entries of a period, of a user when $3 is not NULL
+	deletePeriodEntriesQuery = `
+		DELETE FROM leaderboard_period_entries
+		WHERE period = $1 AND period_start = $2
+		AND ($3::uuid IS NULL OR user_id = $3)
+	`
+
+	// Periods that have entries and are not archived yet
+	getOpenPeriodsQuery = `
+		SELECT DISTINCT period, period_start, period_end FROM leaderboard_period_entries
+		ORDER BY period_start ASC
+	`
+
+	// Base period query for selecting entries, streak and level are the current ones and only break ties
+	basePeriodEntriesQuery = `
+		SELECT
+			pe.user_id, u.first_name, u.avatar,
+			pe.scope, pe.scope_key, pe.xp, u.level,
+			COALESCE(ds.current_streak, 0) AS streak,
+			pe.updated_at
+		FROM leaderboard_period_entries pe
+		JOIN users u ON pe.user_id = u.user_id
+		LEFT JOIN daily_streaks ds ON ds.user_id = pe.user_id
+	`
+
+	// Every entry of a period board
+	getPeriodBoardEntriesQuery = basePeriodEntriesQuery + `
+		WHERE pe.period = $1 AND pe.period_start = $2 AND pe.scope = $3 AND pe.scope_key = $4
+	`
+
+	// The entries of the given users on a period board
+	getPeriodEntriesByUserIDsQuery = getPeriodBoardEntriesQuery + `
+		AND pe.user_id = ANY($5::uuid[])
+	`
+
+	// A user's entry on every board of a period
+	getUserPeriodEntriesQuery = basePeriodEntriesQuery + `
+		WHERE pe.period = $1 AND pe.period_start = $2 AND pe.user_id = $3
+		ORDER BY pe.scope, pe.scope_key
+	`
+
+	// Period boards are ranked when read, in the order of the all-time boards
+	rankedPeriodBoardQuery = `
+		SELECT * FROM (
+			SELECT
+				e.*,
+				ROW_NUMBER() OVER (ORDER BY e.xp DESC, e.streak DESC, e.level DESC, e.user_id DESC) AS rank
+			FROM (` + getPeriodBoardEntriesQuery + `) e
+		) ranked
+	`
+
+	// Get the top entries of a period board
+	getPeriodLeaderboardQuery = rankedPeriodBoardQuery + `
+		ORDER BY rank ASC
+		LIMIT $5
+	`
+
+	// Count the entries of a period board
+	countPeriodLeaderboardQuery = `
+		SELECT COUNT(*) FROM leaderboard_period_entries
+		WHERE period = $1 AND period_start = $2 AND scope = $3 AND scope_key = $4
+	`
+
+	// Get user entry on a period board
+	getUserPeriodBoardEntryQuery = rankedPeriodBoardQuery + `
+		WHERE user_id = $5
+	`
+
+	// Get the entries of a period board within a rank window
+	getPeriodLeaderboardWindowQuery = rankedPeriodBoardQuery + `
+		WHERE rank BETWEEN $5 AND $6
+		ORDER BY rank ASC
+	`
+
+	// Friends boards rank the user and the users they follow among themselves, a followed user can opt out
+	// with show_on_leaderboards. The user is the board argument after the board, $3 on the all-time boards
+	rankedFriendsBoardQuery = `
+		SELECT * FROM (
+			SELECT
+				e.entry_id, e.user_id, e.first_name, e.avatar, e.scope, e.scope_key, e.xp, e.level, e.streak,
+				ROW_NUMBER() OVER (ORDER BY e.xp DESC, e.streak DESC, e.level DESC, e.user_id DESC) AS rank,
+				e.created_at, e.updated_at
+			FROM (` + getLeaderboardBaseQuery + `) e
+			WHERE e.user_id = $3 OR e.user_id IN (
+				SELECT f.followee_id FROM follows f
+				LEFT JOIN privacy_settings ps ON ps.user_id = f.followee_id
+				WHERE f.follower_id = $3 AND f.status = 'accepted' AND COALESCE(ps.show_on_leaderboards, true)
+			)
+		) ranked
+	`
+
+	// Get the top entries of a friends board
+	getFriendsLeaderboardQuery = rankedFriendsBoardQuery + `
+		ORDER BY rank ASC
+		LIMIT $4
+	`
+
+	// Count the entries of a friends board
+	countFriendsLeaderboardQuery = `
+		SELECT COUNT(*) FROM (` + rankedFriendsBoardQuery + `) c
+	`
+
+	// Get user entry on a friends board
+	getUserFriendsBoardEntryQuery = rankedFriendsBoardQuery + `
+		WHERE user_id = $4
+	`
+
+	// Get the entries of a friends board within a rank window
+	getFriendsLeaderboardWindowQuery = rankedFriendsBoardQuery + `
+		WHERE rank BETWEEN $4 AND $5
+		ORDER BY rank ASC
+	`
+
+	// Friends boards of a period, the user is $5
+	rankedPeriodFriendsBoardQuery = `
+		SELECT * FROM (
+			SELECT
+				e.*,
+				ROW_NUMBER() OVER (ORDER BY e.xp DESC, e.streak DESC, e.level DESC, e.user_id DESC) AS rank
+			FROM (` + getPeriodBoardEntriesQuery + `) e
+			WHERE e.user_id = $5 OR e.user_id IN (
+				SELECT f.followee_id FROM follows f
+				LEFT JOIN privacy_settings ps ON ps.user_id = f.followee_id
+				WHERE f.follower_id = $5 AND f.status = 'accepted' AND COALESCE(ps.show_on_leaderboards, true)
+			)
+		) ranked
+	`
+
+	// Get the top entries of a period friends board
+	getPeriodFriendsLeaderboardQuery = rankedPeriodFriendsBoardQuery + `
+		ORDER BY rank ASC
+		LIMIT $6
+	`
+
+	// Count the entries of a period friends board
+	countPeriodFriendsLeaderboardQuery = `
+		SELECT COUNT(*) FROM (` + rankedPeriodFriendsBoardQuery + `) c
+	`
+
+	// Get user entry on a period friends board
+	getUserPeriodFriendsBoardEntryQuery = rankedPeriodFriendsBoardQuery + `
+		WHERE user_id = $6
+	`
+
+	// Get the entries of a period friends board within a rank window
+	getPeriodFriendsLeaderboardWindowQuery = rankedPeriodFriendsBoardQuery + `
+		WHERE rank BETWEEN $6 AND $7
+		ORDER BY rank ASC
+	`
+
+	// Mark a period closed, a period is only archived once
+	insertClosedPeriodQuery = `
+		INSERT INTO leaderboard_periods (period, period_start, period_end)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (period, period_start) DO NOTHING
+	`
+
+	// Archive the final standings of every board of a period
+	archivePeriodStandingsQuery = `
+		INSERT INTO leaderboard_snapshots (period, period_start, user_id, scope, scope_key, xp, rank, board_size)
+		SELECT
+			pe.period, pe.period_start, pe.user_id, pe.scope, pe.scope_key, pe.xp,
+			ROW_NUMBER() OVER (
+				PARTITION BY pe.scope, pe.scope_key
+				ORDER BY pe.xp DESC, COALESCE(ds.current_streak, 0) DESC, u.level DESC, pe.user_id DESC
+			),
+			COUNT(*) OVER (PARTITION BY pe.scope, pe.scope_key)
+		FROM leaderboard_period_entries pe
+		JOIN users u ON pe.user_id = u.user_id
+		LEFT JOIN daily_streaks ds ON ds.user_id = pe.user_id
+		WHERE pe.period = $1 AND pe.period_start = $2
+	`
+
+	// Closed periods of a kind, most recent first
+	getClosedPeriodsQuery = `
+		SELECT period, period_start, period_end, closed_at FROM leaderboard_periods
+		WHERE period = $1
+		ORDER BY period_start DESC
+		LIMIT $2
+	`
+
+	// A closed period
+	getClosedPeriodQuery = `
+		SELECT period, period_start, period_end, closed_at FROM leaderboard_periods
+		WHERE period = $1 AND period_start = $2
+	`
+
+	// Base snapshot query for selecting archived standings
+	baseSnapshotQuery = `
+		SELECT
+			s.period, s.period_start, p.period_end, s.user_id, u.first_name, u.avatar,
+			s.scope, s.scope_key, s.xp, s.rank, s.board_size
+		FROM leaderboard_snapshots s
+		JOIN leaderboard_periods p ON p.period = s.period AND p.period_start = s.period_start
+		JOIN users u ON s.user_id = u.user_id
+	`
+
+	// Get the archived standings of a board
+	getSnapshotBoardQuery = baseSnapshotQuery + `
+		WHERE s.period = $1 AND s.period_start = $2 AND s.scope = $3 AND s.scope_key = $4
+	`
+
+	// Get the top archived standings of a board
+	getSnapshotQuery = getSnapshotBoardQuery + `
+		ORDER BY s.rank ASC
+		LIMIT $5
+	`
+
+	// Get a user's archived standing on a board
+	getUserSnapshotQuery = getSnapshotBoardQuery + `
+		AND s.user_id = $5
+	`
+
+	// Count the archived standings of a board
+	countSnapshotQuery = `
+		SELECT COUNT(*) FROM leaderboard_snapshots
+		WHERE period = $1 AND period_start = $2 AND scope = $3 AND scope_key = $4
+	`
+
+	// A user's archived standings on a board for the last closed periods, oldest first
+	getUserRankHistoryQuery = `
+		SELECT * FROM (` + baseSnapshotQuery + `
+			WHERE s.user_id = $1 AND s.period = $2 AND s.scope = $3 AND s.scope_key = $4
+			ORDER BY s.period_start DESC
+			LIMIT $5
+		) history
+		ORDER BY period_start ASC
+	`
+
+	// Top performers are taken from the global board
+	getTopPerformersBaseQuery = baseLeaderboardQuery + `
+		WHERE le.scope = 'global'
+	`
 
 	// Get top performers by XP
-	getTopPerformersByXPQuery = baseLeaderboardQuery + `
+	getTopPerformersByXPQuery = getTopPerformersBaseQuery + `
 		ORDER BY le.xp DESC
 		LIMIT $1
 	`
 
 	// Get top performers by streak
-	getTopPerformersByStreakQuery = baseLeaderboardQuery + `
+	getTopPerformersByStreakQuery = getTopPerformersBaseQuery + `
 		ORDER BY le.streak DESC
 		LIMIT $1
 	`
 
 	// Get top performers by level
-	getTopPerformersByLevelQuery = baseLeaderboardQuery + `
+	getTopPerformersByLevelQuery = getTopPerformersBaseQuery + `
 		ORDER BY le.level DESC
 		LIMIT $1
 	`
 
 	// Get top performers by rank
-	getTopPerformersByRankQuery = baseLeaderboardQuery + `
+	getTopPerformersByRankQuery = getTopPerformersBaseQuery + `
 		ORDER BY le.rank ASC
 		LIMIT $1
 	`
+
+	// Classes
+	createClassQuery = `
+		INSERT INTO classes (name, grade)
+		VALUES ($1, $2)
+		RETURNING *
+	`
+
+	addClassMemberQuery = `
+		INSERT INTO class_members (class_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (class_id, user_id) DO NOTHING
+	`
+
+	removeClassMemberQuery = `
+		DELETE FROM class_members WHERE class_id = $1 AND user_id = $2
+	`
 )
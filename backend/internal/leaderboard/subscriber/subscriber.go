@@ -0,0 +1,26 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/leaderboard"
+)
+
+const subscriberName = "leaderboard"
+
+// RegisterLeaderboardSubscribers syncs a user's leaderboard entry whenever their XP, level or streak changes
+func RegisterLeaderboardSubscribers(bus events.Bus, leaderboardUC leaderboard.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		if err := leaderboardUC.SyncUserStats(ctx, event.UserID); err != nil {
+			return errors.Wrap(err, "leaderboardSubscriber.SyncUserStats")
+		}
+		return nil
+	},
+		events.XPAwardedType,
+		events.LevelUpType,
+		events.StreakUpdatedType,
+	)
+}
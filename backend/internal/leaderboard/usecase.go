@@ -11,35 +11,46 @@ import (
 
 // UseCase interface for leaderboard operations
 type UseCase interface {
-	// Get leaderboard entries with optional filtering
+	// Get a board's entries, the board is chosen by the subject, grade or class filter
 	GetLeaderboard(ctx context.Context, filter *models.LeaderboardFilter) (*models.LeaderboardResponse, error)
 
-	// Get a specific user's rank and stats
+	// Get the entries around a user on the board chosen by the filter
+	GetLeaderboardAroundUser(ctx context.Context, filter *models.LeaderboardFilter, radius int) (*models.LeaderboardResponse, error)
+
+	// Get the daily, weekly or monthly period in progress, in the leaderboard timezone
+	GetCurrentPeriod(timeFrame string) (*models.LeaderboardPeriod, error)
+
+	// Get the closed periods of a time frame, most recent first
+	GetPeriods(ctx context.Context, timeFrame string, limit int) ([]*models.LeaderboardPeriod, error)
+
+	// Get the archived final standings of a board for the closed period containing date
+	GetPeriodStandings(ctx context.Context, filter *models.LeaderboardFilter, date time.Time) (*models.LeaderboardStandings, error)
+
+	// Get a user's final rank on a board for the last closed periods of the filter's time frame
+	GetUserRankHistory(ctx context.Context, filter *models.LeaderboardFilter, limit int) ([]*models.LeaderboardSnapshotEntry, error)
+
+	// Get a specific user's global rank and stats
 	GetUserRank(ctx context.Context, userID uuid.UUID) (*models.LeaderboardEntry, error)
 
-	// Update a user's leaderboard entry (typically called when user earns XP, etc.)
-	UpdateUserStats(ctx context.Context, entry *models.LeaderboardEntry) error
+	// Get a user's rank on every board they belong to
+	GetUserRanks(ctx context.Context, userID uuid.UUID) ([]*models.LeaderboardEntry, error)
 
-	// Recalculate all rankings (typically run as a scheduled job)
+	// Archive the periods that ended, rebuild all entries from the XP ledger and recalculate the rankings
+	// (typically run as a scheduled job)
 	RecalculateRankings(ctx context.Context) error
 
-	// Get top performers for a specific metric (XP, streak, etc.)
+	// Get top performers of the global board for a specific metric (XP, streak, etc.)
 	GetTopPerformers(ctx context.Context, metric string, limit int) ([]*models.LeaderboardEntry, error)
 
-	// Sync a user's stats from various repositories to the leaderboard
+	// Sync a user's entries from the XP ledger, typically called when the user earns XP.
+	// Only the Redis backend re-ranks right away, Postgres ranks are refreshed by RecalculateRankings
 	SyncUserStats(ctx context.Context, userID uuid.UUID) error
 
+	// Class boards
+	CreateClass(ctx context.Context, class *models.Class) (*models.Class, error)
+	AddClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error
+	RemoveClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error
+
 	// Get the context timeout duration for leaderboard operations
 	GetContextTimeout() time.Duration
 }
-
-// UserProgressRepository provides access to user progress data
-type UserProgressRepository interface {
-	GetUserXP(ctx context.Context, userID uuid.UUID) (int, error)
-	GetUserLevel(ctx context.Context, userID uuid.UUID) (int, error)
-}
-
-// UserStreakRepository provides access to user streak data
-type UserStreakRepository interface {
-	GetUserStreak(ctx context.Context, userID uuid.UUID) (int, error)
-}
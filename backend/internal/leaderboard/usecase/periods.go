@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// periodKinds are the period boards every user is synced to
+var periodKinds = []string{
+	models.LeaderboardPeriodDaily,
+	models.LeaderboardPeriodWeekly,
+	models.LeaderboardPeriodMonthly,
+}
+
+// periodAt returns the period of the given kind containing t, periods start at midnight in loc
+// and weeks on Monday, so a period is 23 or 25 hours longer or shorter across a DST change
+func periodAt(kind string, t time.Time, loc *time.Location) *models.LeaderboardPeriod {
+	local := t.In(loc)
+	year, month, day := local.Date()
+
+	var start, end time.Time
+	switch kind {
+	case models.LeaderboardPeriodWeekly:
+		offset := (int(local.Weekday()) + 6) % 7
+		start = time.Date(year, month, day-offset, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 7)
+	case models.LeaderboardPeriodMonthly:
+		start = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	default:
+		start = time.Date(year, month, day, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 1)
+	}
+
+	return &models.LeaderboardPeriod{
+		Period:      kind,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+}
+
+// isPeriodKind reports whether the time frame is a daily, weekly or monthly period
+func isPeriodKind(timeFrame string) bool {
+	for _, kind := range periodKinds {
+		if kind == timeFrame {
+			return true
+		}
+	}
+	return false
+}
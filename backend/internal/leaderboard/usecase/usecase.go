@@ -2,131 +2,297 @@ package usecase
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/leaderboard"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
+const (
+	defaultAroundRadius    = 5
+	defaultMaxAroundRadius = 25
+	defaultHistoryLimit    = 12
+	defaultMaxHistoryLimit = 52
+)
+
 type LeaderboardUseCase struct {
-	leaderboardRepo  leaderboard.Repository
-	userProgressRepo leaderboard.UserProgressRepository
-	userStreakRepo   leaderboard.UserStreakRepository
-	logger           logger.Logger
+	cfg             *config.Config
+	leaderboardRepo leaderboard.Repository
+	location        *time.Location // period boards reset at midnight in this timezone
+	logger          logger.Logger
 }
 
 func NewLeaderboardUseCase(
+	cfg *config.Config,
 	leaderboardRepo leaderboard.Repository,
-	userProgressRepo leaderboard.UserProgressRepository,
-	userStreakRepo leaderboard.UserStreakRepository,
 	logger logger.Logger,
 ) leaderboard.UseCase {
-	return &LeaderboardUseCase{
-		leaderboardRepo:  leaderboardRepo,
-		userProgressRepo: userProgressRepo,
-		userStreakRepo:   userStreakRepo,
-		logger:           logger,
+	location := time.UTC
+	if cfg.Leaderboard.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Leaderboard.Timezone)
+		if err != nil {
+			logger.Errorf("Invalid leaderboard timezone %q, using UTC: %v", cfg.Leaderboard.Timezone, err)
+		} else {
+			location = loc
+		}
 	}
-}
 
-func NewLeaderboardUseCaseWithoutDeps(
-	leaderboardRepo leaderboard.Repository,
-	logger logger.Logger,
-) leaderboard.UseCase {
 	return &LeaderboardUseCase{
-		leaderboardRepo:  leaderboardRepo,
-		userProgressRepo: nil,
-		userStreakRepo:   nil,
-		logger:           logger,
+		cfg:             cfg,
+		leaderboardRepo: leaderboardRepo,
+		location:        location,
+		logger:          logger,
 	}
 }
 
 func (u *LeaderboardUseCase) GetLeaderboard(ctx context.Context, filter *models.LeaderboardFilter) (*models.LeaderboardResponse, error) {
+	if err := u.resolveBoard(filter); err != nil {
+		return nil, err
+	}
 	return u.leaderboardRepo.GetLeaderboard(ctx, filter)
 }
 
+func (u *LeaderboardUseCase) GetLeaderboardAroundUser(ctx context.Context, filter *models.LeaderboardFilter, radius int) (*models.LeaderboardResponse, error) {
+	if err := u.resolveBoard(filter); err != nil {
+		return nil, err
+	}
+
+	maxRadius := u.cfg.Leaderboard.MaxAroundRadius
+	if maxRadius <= 0 {
+		maxRadius = defaultMaxAroundRadius
+	}
+	if radius <= 0 {
+		radius = defaultAroundRadius
+	}
+	if radius > maxRadius {
+		radius = maxRadius
+	}
+
+	return u.leaderboardRepo.GetLeaderboardAroundUser(ctx, filter, radius)
+}
+
+// GetCurrentPeriod returns the daily, weekly or monthly period in progress
+func (u *LeaderboardUseCase) GetCurrentPeriod(timeFrame string) (*models.LeaderboardPeriod, error) {
+	if !isPeriodKind(timeFrame) {
+		return nil, leaderboard.ErrPeriodRequired
+	}
+	return periodAt(timeFrame, time.Now(), u.location), nil
+}
+
+// GetPeriods lists the closed periods of a time frame, most recent first
+func (u *LeaderboardUseCase) GetPeriods(ctx context.Context, timeFrame string, limit int) ([]*models.LeaderboardPeriod, error) {
+	if !isPeriodKind(timeFrame) {
+		return nil, leaderboard.ErrPeriodRequired
+	}
+	return u.leaderboardRepo.GetClosedPeriods(ctx, timeFrame, u.historyLimit(limit))
+}
+
+// GetPeriodStandings returns the archived standings of the closed period containing date, a day in the leaderboard timezone
+func (u *LeaderboardUseCase) GetPeriodStandings(
+	ctx context.Context,
+	filter *models.LeaderboardFilter,
+	date time.Time,
+) (*models.LeaderboardStandings, error) {
+	if !isPeriodKind(filter.TimeFrame) {
+		return nil, leaderboard.ErrPeriodRequired
+	}
+	if err := resolveScope(filter); err != nil {
+		return nil, err
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, u.location)
+	return u.leaderboardRepo.GetPeriodStandings(ctx, periodAt(filter.TimeFrame, day, u.location), filter)
+}
+
+// GetUserRankHistory returns the user's final rank on the filter's board for the last closed periods, oldest first.
+// Periods the user earned no XP in are not part of the history
+func (u *LeaderboardUseCase) GetUserRankHistory(
+	ctx context.Context,
+	filter *models.LeaderboardFilter,
+	limit int,
+) ([]*models.LeaderboardSnapshotEntry, error) {
+	if !isPeriodKind(filter.TimeFrame) {
+		return nil, leaderboard.ErrPeriodRequired
+	}
+	if err := resolveScope(filter); err != nil {
+		return nil, err
+	}
+
+	return u.leaderboardRepo.GetUserRankHistory(ctx, filter.TimeFrame, filter, u.historyLimit(limit))
+}
+
 func (u *LeaderboardUseCase) GetUserRank(ctx context.Context, userID uuid.UUID) (*models.LeaderboardEntry, error) {
 	return u.leaderboardRepo.GetUserRank(ctx, userID)
 }
 
-func (u *LeaderboardUseCase) UpdateUserStats(ctx context.Context, entry *models.LeaderboardEntry) error {
-	return u.leaderboardRepo.UpdateUserStats(ctx, entry)
+func (u *LeaderboardUseCase) GetUserRanks(ctx context.Context, userID uuid.UUID) ([]*models.LeaderboardEntry, error) {
+	return u.leaderboardRepo.GetUserRanks(ctx, userID)
 }
 
+// RecalculateRankings archives the periods that ended and rebuilds every entry, the open periods' included,
+// from the XP ledger before ranking, so boards stay correct when a user changes grade or class
 func (u *LeaderboardUseCase) RecalculateRankings(ctx context.Context) error {
+	if err := u.closeEndedPeriods(ctx); err != nil {
+		return err
+	}
+
+	if err := u.leaderboardRepo.RebuildEntries(ctx); err != nil {
+		return err
+	}
+
+	for _, period := range u.currentPeriods() {
+		if err := u.leaderboardRepo.RebuildPeriodEntries(ctx, period); err != nil {
+			return err
+		}
+	}
+
 	return u.leaderboardRepo.RecalculateRankings(ctx)
 }
 
+// closeEndedPeriods archives the final standings of every period that has entries and ended
+func (u *LeaderboardUseCase) closeEndedPeriods(ctx context.Context) error {
+	periods, err := u.leaderboardRepo.GetOpenPeriods(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, period := range periods {
+		if period.PeriodEnd.After(now) {
+			continue
+		}
+
+		closed, err := u.leaderboardRepo.ClosePeriod(ctx, period)
+		if err != nil {
+			return err
+		}
+		if closed {
+			u.logger.Infof("Archived %s leaderboard standings of %s", period.Period, period.PeriodStart.In(u.location).Format("2006-01-02"))
+		}
+	}
+
+	return nil
+}
+
 func (u *LeaderboardUseCase) GetTopPerformers(ctx context.Context, metric string, limit int) ([]*models.LeaderboardEntry, error) {
 	return u.leaderboardRepo.GetTopPerformers(ctx, metric, limit)
 }
 
-// SyncUserStats syncs a user's stats from various repositories to the leaderboard
-// This is a helper method that can be called when user stats change
+// SyncUserStats syncs a user's entries on every board, all-time and of the current periods, from the XP ledger
+// This is a helper method that can be called when user stats change, it never re-ranks the whole table:
+// the Redis boards are re-scored incrementally and the Postgres ranks are refreshed by the reconciliation job
 func (u *LeaderboardUseCase) SyncUserStats(ctx context.Context, userID uuid.UUID) error {
-	// Default values
-	xp := 0
-	level := 1
-	streak := 0
-
-	// Get user XP if repository is available
-	if u.userProgressRepo != nil {
-		userXP, err := u.userProgressRepo.GetUserXP(ctx, userID)
-		if err != nil {
-			u.logger.Warnf("Error getting user XP: %v", err)
-		} else {
-			xp = userXP
-		}
+	if err := u.leaderboardRepo.SyncUserEntries(ctx, userID); err != nil {
+		u.logger.Errorf("Error syncing user leaderboard entries: %v", err)
+		return err
 	}
 
-	// Get user level if repository is available
-	if u.userProgressRepo != nil {
-		userLevel, err := u.userProgressRepo.GetUserLevel(ctx, userID)
-		if err != nil {
-			u.logger.Warnf("Error getting user level: %v", err)
-		} else {
-			level = userLevel
+	for _, period := range u.currentPeriods() {
+		if err := u.leaderboardRepo.SyncUserPeriodEntries(ctx, userID, period); err != nil {
+			u.logger.Errorf("Error syncing user %s leaderboard entries: %v", period.Period, err)
+			return err
 		}
 	}
 
-	// Get user streak if repository is available
-	if u.userStreakRepo != nil {
-		userStreak, err := u.userStreakRepo.GetUserStreak(ctx, userID)
-		if err != nil {
-			u.logger.Warnf("Error getting user streak: %v", err)
-		} else {
-			streak = userStreak
-		}
-	}
+	return nil
+}
 
-	// Create or update leaderboard entry
-	entry := &models.LeaderboardEntry{
-		UserID: userID,
-		XP:     xp,
-		Level:  level,
-		Streak: streak,
-		// Rank will be updated by RecalculateRankings
-	}
+func (u *LeaderboardUseCase) CreateClass(ctx context.Context, class *models.Class) (*models.Class, error) {
+	return u.leaderboardRepo.CreateClass(ctx, class)
+}
 
-	// Update user stats
-	if err := u.leaderboardRepo.UpdateUserStats(ctx, entry); err != nil {
-		u.logger.Errorf("Error updating user stats: %v", err)
+// AddClassMember adds a user to a class and puts them on the class board right away
+func (u *LeaderboardUseCase) AddClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error {
+	if err := u.leaderboardRepo.AddClassMember(ctx, classID, userID); err != nil {
 		return err
 	}
+	return u.SyncUserStats(ctx, userID)
+}
 
-	// Recalculate rankings
-	if err := u.leaderboardRepo.RecalculateRankings(ctx); err != nil {
-		u.logger.Errorf("Error recalculating rankings: %v", err)
+// RemoveClassMember removes a user from a class and from its board
+func (u *LeaderboardUseCase) RemoveClassMember(ctx context.Context, classID uuid.UUID, userID uuid.UUID) error {
+	if err := u.leaderboardRepo.RemoveClassMember(ctx, classID, userID); err != nil {
 		return err
 	}
-
-	return nil
+	return u.SyncUserStats(ctx, userID)
 }
 
 // GetContextTimeout returns the context timeout duration for leaderboard operations
 func (u *LeaderboardUseCase) GetContextTimeout() time.Duration {
 	return 30 * time.Second
 }
+
+// currentPeriods returns the daily, weekly and monthly periods in progress
+func (u *LeaderboardUseCase) currentPeriods() []*models.LeaderboardPeriod {
+	now := time.Now()
+	periods := make([]*models.LeaderboardPeriod, 0, len(periodKinds))
+	for _, kind := range periodKinds {
+		periods = append(periods, periodAt(kind, now, u.location))
+	}
+	return periods
+}
+
+// resolveBoard picks the board of the filter and, for a daily, weekly or monthly time frame, the period in progress.
+// A friends board is the board narrowed to the filter's user and the users they follow
+func (u *LeaderboardUseCase) resolveBoard(filter *models.LeaderboardFilter) error {
+	if err := resolveScope(filter); err != nil {
+		return err
+	}
+	if filter.Friends && filter.UserID == uuid.Nil {
+		return leaderboard.ErrFriendsRequireUser
+	}
+
+	switch {
+	case filter.TimeFrame == "" || filter.TimeFrame == models.LeaderboardPeriodAllTime:
+		filter.Period = nil
+	case isPeriodKind(filter.TimeFrame):
+		filter.Period = periodAt(filter.TimeFrame, time.Now(), u.location)
+	default:
+		return leaderboard.ErrUnknownTimeFrame
+	}
+
+	return nil
+}
+
+func (u *LeaderboardUseCase) historyLimit(limit int) int {
+	maxLimit := u.cfg.Leaderboard.HistoryLimit
+	if maxLimit <= 0 {
+		maxLimit = defaultMaxHistoryLimit
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit
+}
+
+// resolveScope picks the board of the filter, the global board when no subject, grade or class is set
+func resolveScope(filter *models.LeaderboardFilter) error {
+	filter.Scope, filter.ScopeKey = models.LeaderboardScopeGlobal, ""
+
+	set := 0
+	if filter.Subject != "" {
+		filter.Scope, filter.ScopeKey = models.LeaderboardScopeSubject, filter.Subject
+		set++
+	}
+	if filter.Grade > 0 {
+		filter.Scope, filter.ScopeKey = models.LeaderboardScopeGrade, strconv.Itoa(filter.Grade)
+		set++
+	}
+	if filter.ClassID != nil {
+		filter.Scope, filter.ScopeKey = models.LeaderboardScopeClass, filter.ClassID.String()
+		set++
+	}
+	if set > 1 {
+		return leaderboard.ErrAmbiguousScope
+	}
+
+	return nil
+}
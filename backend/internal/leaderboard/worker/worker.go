@@ -5,23 +5,41 @@ import (
 	"time"
 
 	"github.com/AleksK1NG/api-mc/internal/leaderboard"
+	"github.com/AleksK1NG/api-mc/internal/league"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
-// LeaderboardWorker handles periodic leaderboard recalculation
+const defaultInterval = 10 * time.Minute
+
+// LeaderboardWorker handles periodic leaderboard reconciliation: the periods that ended are archived,
+// the entries are rebuilt from the XP ledger, re-ranked in Postgres and the Redis boards are reconciled with them.
+// The weekly leagues are run on the same schedule
 type LeaderboardWorker struct {
 	leaderboardUC leaderboard.UseCase
+	leagueUC      league.UseCase
 	logger        logger.Logger
 	interval      time.Duration
 	stopCh        chan struct{}
 }
 
-// NewLeaderboardWorker creates a new leaderboard worker
-func NewLeaderboardWorker(leaderboardUC leaderboard.UseCase, logger logger.Logger) *LeaderboardWorker {
+// NewLeaderboardWorker creates a new leaderboard worker, interval is in minutes. leagueUC may be nil
+func NewLeaderboardWorker(
+	leaderboardUC leaderboard.UseCase,
+	leagueUC league.UseCase,
+	interval time.Duration,
+	logger logger.Logger,
+) *LeaderboardWorker {
+	if interval <= 0 {
+		interval = defaultInterval
+	} else {
+		interval = interval * time.Minute
+	}
+
 	return &LeaderboardWorker{
 		leaderboardUC: leaderboardUC,
+		leagueUC:      leagueUC,
 		logger:        logger,
-		interval:      10 * time.Minute, // Recalculate every 10 minutes
+		interval:      interval,
 		stopCh:        make(chan struct{}),
 	}
 }
@@ -67,4 +85,30 @@ func (w *LeaderboardWorker) recalculateLeaderboard() {
 	}
 
 	w.logger.Info("Leaderboard rankings recalculated successfully")
+
+	w.runLeagues(ctx)
+}
+
+// runLeagues closes the league cohorts of the weeks that ended and places this week's new players
+func (w *LeaderboardWorker) runLeagues(ctx context.Context) {
+	if w.leagueUC == nil {
+		return
+	}
+
+	report, err := w.leagueUC.RunLeagues(ctx)
+	if err != nil {
+		w.logger.Errorf("Error running leagues: %v", err)
+		return
+	}
+
+	if report.Assigned > 0 || report.CohortsClosed > 0 {
+		w.logger.Infof(
+			"Leagues: assigned %d users, opened %d cohorts, closed %d cohorts, promoted %d, relegated %d",
+			report.Assigned,
+			report.CohortsOpened,
+			report.CohortsClosed,
+			report.Promoted,
+			report.Relegated,
+		)
+	}
 }
@@ -0,0 +1,11 @@
+package league
+
+import "github.com/labstack/echo/v4"
+
+// League HTTP Handlers interface
+type Handlers interface {
+	GetTiers() echo.HandlerFunc
+	GetMyLeague() echo.HandlerFunc
+	GetCohortStandings() echo.HandlerFunc
+	RunLeagues() echo.HandlerFunc
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/league"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type leagueHandlers struct {
+	leagueUC league.UseCase
+	logger   logger.Logger
+}
+
+func NewLeagueHandlers(leagueUC league.UseCase, logger logger.Logger) league.Handlers {
+	return &leagueHandlers{
+		leagueUC: leagueUC,
+		logger:   logger,
+	}
+}
+
+// GetTiers godoc
+// @Summary Get the league tiers
+// @Description League tiers from the lowest, new players start in the first one
+// @Tags Leagues
+// @Produce json
+// @Success 200 {array} models.LeagueTier
+// @Router /leagues/tiers [get]
+func (h *leagueHandlers) GetTiers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, h.leagueUC.GetTiers())
+	}
+}
+
+// GetMyLeague godoc
+// @Summary Get my league
+// @Description Standings of my cohort this week ranked by weekly XP, with the promotion and relegation zones
+// @Tags Leagues
+// @Produce json
+// @Success 200 {object} models.LeagueStandings
+// @Failure 404 {object} httpErrors.RestError
+// @Router /leagues/me [get]
+func (h *leagueHandlers) GetMyLeague() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "leagueHandlers.GetMyLeague.GetUserIDFromContext"))
+		}
+
+		standings, err := h.leagueUC.GetUserLeague(c.Request().Context(), userID)
+		if err != nil {
+			if errors.Is(err, league.ErrNotInLeague) {
+				return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "leagueHandlers.GetMyLeague.GetUserLeague"))
+		}
+
+		return c.JSON(http.StatusOK, standings)
+	}
+}
+
+// GetCohortStandings godoc
+// @Summary Get a cohort's standings
+// @Description Standings of a league cohort ranked by weekly XP, final once the cohort closed
+// @Tags Leagues
+// @Produce json
+// @Param cohort_id path string true "Cohort ID"
+// @Success 200 {object} models.LeagueStandings
+// @Failure 404 {object} httpErrors.RestError
+// @Router /leagues/cohorts/{cohort_id} [get]
+func (h *leagueHandlers) GetCohortStandings() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "leagueHandlers.GetCohortStandings.GetUserIDFromContext"))
+		}
+
+		cohortID, err := uuid.Parse(c.Param("cohort_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "leagueHandlers.GetCohortStandings.uuid.Parse"))
+		}
+
+		standings, err := h.leagueUC.GetCohortStandings(c.Request().Context(), cohortID, userID)
+		if err != nil {
+			if errors.Is(err, league.ErrCohortNotFound) {
+				return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "leagueHandlers.GetCohortStandings.GetCohortStandings"))
+		}
+
+		return c.JSON(http.StatusOK, standings)
+	}
+}
+
+// RunLeagues godoc
+// @Summary Run the league job
+// @Description Close the cohorts of the weeks that ended and place this week's new players, the leaderboard worker runs it on every tick
+// @Tags Leagues
+// @Produce json
+// @Success 200 {object} models.LeagueJobReport
+// @Router /leagues/admin/run [post]
+func (h *leagueHandlers) RunLeagues() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report, err := h.leagueUC.RunLeagues(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "leagueHandlers.RunLeagues.RunLeagues"))
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
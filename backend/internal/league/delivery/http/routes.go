@@ -0,0 +1,27 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/league"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Map league routes
+func MapLeagueRoutes(leagueGroup *echo.Group, h league.Handlers, mw *middleware.MiddlewareManager) {
+	leagueGroup.GET("/tiers", h.GetTiers())
+
+	protected := leagueGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("/me", h.GetMyLeague())
+		protected.GET("/cohorts/:cohort_id", h.GetCohortStandings())
+
+		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionJobsRun))
+		{
+			admin.POST("/run", h.RunLeagues())
+		}
+	}
+}
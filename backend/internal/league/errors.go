@@ -0,0 +1,9 @@
+package league
+
+import "errors"
+
+// League errors
+var (
+	ErrNotInLeague    = errors.New("earn XP this week to join a league")
+	ErrCohortNotFound = errors.New("league cohort not found")
+)
@@ -0,0 +1,35 @@
+package league
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// AssignFunc places the users who earned XP this week into the week's cohorts that still have room.
+// It returns the cohorts that got new members, a cohort with a nil CohortID is created
+type AssignFunc func(candidates []*models.LeagueCandidate, cohorts []*models.LeagueCohort) []*models.LeagueCohort
+
+// SettleFunc sets the outcome of every member of a cohort ranked by weekly XP
+type SettleFunc func(cohort *models.LeagueCohort, members []*models.LeagueMember)
+
+// League Repository interface
+type Repository interface {
+	// Locks the week's assignment, places the users who earned XP and have no league with assign and saves the placements
+	AssignUsers(ctx context.Context, week *models.LeaderboardPeriod, assign AssignFunc) ([]*models.LeagueCohort, error)
+
+	// Cohorts whose week ended before now and that are not closed yet
+	GetEndedCohorts(ctx context.Context, now time.Time, limit int) ([]*models.LeagueCohort, error)
+
+	// Locks an open cohort, settles it and applies the promotions and relegations in a single transaction.
+	// It returns nil when the cohort was closed already
+	CloseCohort(ctx context.Context, cohortID uuid.UUID, settle SettleFunc) ([]*models.LeagueMember, error)
+
+	GetCohort(ctx context.Context, cohortID uuid.UUID) (*models.LeagueCohort, error)
+	// Cohort members ranked by the XP they earned during the week
+	GetStandings(ctx context.Context, cohortID uuid.UUID) ([]*models.LeagueMember, error)
+	GetUserCohort(ctx context.Context, userID uuid.UUID, weekStart time.Time) (*models.LeagueCohort, error)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/league"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type leagueRepo struct {
+	db      *sqlx.DB
+	maxTier int
+	logger  logger.Logger
+}
+
+// NewLeagueRepository creates the league repository, maxTier is the highest configured tier
+func NewLeagueRepository(db *sqlx.DB, maxTier int, logger logger.Logger) league.Repository {
+	return &leagueRepo{
+		db:      db,
+		maxTier: maxTier,
+		logger:  logger,
+	}
+}
+
+func (r *leagueRepo) AssignUsers(ctx context.Context, week *models.LeaderboardPeriod, assign league.AssignFunc) ([]*models.LeagueCohort, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.AssignUsers.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, lockAssignmentQuery); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.AssignUsers.lockAssignment")
+	}
+
+	candidates := make([]*models.LeagueCandidate, 0)
+	if err := tx.SelectContext(ctx, &candidates, getCandidatesQuery, week.PeriodStart, week.PeriodEnd, r.maxTier); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.AssignUsers.getCandidates")
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	cohorts := make([]*models.LeagueCohort, 0)
+	if err := tx.SelectContext(ctx, &cohorts, getWeekCohortsQuery, week.PeriodStart); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.AssignUsers.getWeekCohorts")
+	}
+
+	placed := assign(candidates, cohorts)
+	for _, cohort := range placed {
+		if cohort.CohortID == uuid.Nil {
+			if err := tx.GetContext(ctx, &cohort.CohortID, createCohortQuery, cohort.Tier, week.PeriodStart, week.PeriodEnd); err != nil {
+				return nil, errors.Wrap(err, "leagueRepo.AssignUsers.createCohort")
+			}
+			cohort.WeekStart, cohort.WeekEnd = week.PeriodStart, week.PeriodEnd
+		}
+
+		for _, userID := range cohort.NewMembers {
+			if _, err := tx.ExecContext(ctx, addMemberQuery, cohort.CohortID, userID, week.PeriodStart); err != nil {
+				return nil, errors.Wrap(err, "leagueRepo.AssignUsers.addMember")
+			}
+			if _, err := tx.ExecContext(ctx, ensureUserLeagueQuery, userID); err != nil {
+				return nil, errors.Wrap(err, "leagueRepo.AssignUsers.ensureUserLeague")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.AssignUsers.Commit")
+	}
+
+	return placed, nil
+}
+
+func (r *leagueRepo) GetEndedCohorts(ctx context.Context, now time.Time, limit int) ([]*models.LeagueCohort, error) {
+	cohorts := make([]*models.LeagueCohort, 0)
+	if err := r.db.SelectContext(ctx, &cohorts, getEndedCohortsQuery, now, limit); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.GetEndedCohorts.SelectContext")
+	}
+
+	return cohorts, nil
+}
+
+func (r *leagueRepo) CloseCohort(ctx context.Context, cohortID uuid.UUID, settle league.SettleFunc) ([]*models.LeagueMember, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.CloseCohort.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	cohort := &models.LeagueCohort{}
+	if err := tx.GetContext(ctx, cohort, lockOpenCohortQuery, cohortID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "leagueRepo.CloseCohort.lockOpenCohort")
+	}
+
+	members := make([]*models.LeagueMember, 0)
+	if err := tx.SelectContext(ctx, &members, getStandingsQuery, cohortID); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.CloseCohort.getStandings")
+	}
+
+	settle(cohort, members)
+
+	for _, member := range members {
+		if _, err := tx.ExecContext(ctx, settleMemberQuery, cohortID, member.UserID, member.XP, member.Rank, member.Outcome); err != nil {
+			return nil, errors.Wrap(err, "leagueRepo.CloseCohort.settleMember")
+		}
+
+		tier := cohort.Tier
+		switch {
+		case member.Outcome == nil:
+		case *member.Outcome == models.LeagueOutcomePromoted:
+			tier++
+		case *member.Outcome == models.LeagueOutcomeRelegated:
+			tier--
+		}
+		if _, err := tx.ExecContext(ctx, updateUserTierQuery, member.UserID, tier); err != nil {
+			return nil, errors.Wrap(err, "leagueRepo.CloseCohort.updateUserTier")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, closeCohortQuery, cohortID); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.CloseCohort.closeCohort")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.CloseCohort.Commit")
+	}
+
+	return members, nil
+}
+
+func (r *leagueRepo) GetCohort(ctx context.Context, cohortID uuid.UUID) (*models.LeagueCohort, error) {
+	cohort := &models.LeagueCohort{}
+	if err := r.db.GetContext(ctx, cohort, getCohortQuery, cohortID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, league.ErrCohortNotFound
+		}
+		return nil, errors.Wrap(err, "leagueRepo.GetCohort.GetContext")
+	}
+
+	return cohort, nil
+}
+
+func (r *leagueRepo) GetStandings(ctx context.Context, cohortID uuid.UUID) ([]*models.LeagueMember, error) {
+	members := make([]*models.LeagueMember, 0)
+	if err := r.db.SelectContext(ctx, &members, getStandingsQuery, cohortID); err != nil {
+		return nil, errors.Wrap(err, "leagueRepo.GetStandings.SelectContext")
+	}
+
+	return members, nil
+}
+
+func (r *leagueRepo) GetUserCohort(ctx context.Context, userID uuid.UUID, weekStart time.Time) (*models.LeagueCohort, error) {
+	cohort := &models.LeagueCohort{}
+	if err := r.db.GetContext(ctx, cohort, getUserCohortQuery, userID, weekStart); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, league.ErrNotInLeague
+		}
+		return nil, errors.Wrap(err, "leagueRepo.GetUserCohort.GetContext")
+	}
+
+	return cohort, nil
+}
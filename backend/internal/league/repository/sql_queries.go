@@ -0,0 +1,113 @@
+package repository
+
+const (
+	// Serializes the assignment runs of all instances, released on commit
+	lockAssignmentQuery = `SELECT pg_advisory_xact_lock(hashtext('league_assignment'))`
+
+	// Users who earned XP during the week and have no cohort for it, in the order they started playing.
+	// Tiers above the highest configured one, e.g. after the tiers were cut, are clamped to it
+	getCandidatesQuery = `
+		SELECT t.user_id, LEAST(COALESCE(ul.tier, 0), $3) AS tier
+		FROM xp_transactions t
+		LEFT JOIN user_leagues ul ON ul.user_id = t.user_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		AND NOT EXISTS (
+			SELECT 1 FROM league_members m WHERE m.user_id = t.user_id AND m.week_start = $1
+		)
+		GROUP BY t.user_id, ul.tier
+		ORDER BY MIN(t.created_at) ASC
+	`
+
+	getWeekCohortsQuery = `
+		SELECT c.cohort_id, c.tier, c.week_start, c.week_end, c.created_at, c.closed_at, COUNT(m.user_id) AS size
+		FROM league_cohorts c
+		LEFT JOIN league_members m ON m.cohort_id = c.cohort_id
+		WHERE c.week_start = $1
+		GROUP BY c.cohort_id
+		ORDER BY c.created_at ASC
+	`
+
+	createCohortQuery = `
+		INSERT INTO league_cohorts (tier, week_start, week_end)
+		VALUES ($1, $2, $3)
+		RETURNING cohort_id
+	`
+
+	addMemberQuery = `
+		INSERT INTO league_members (cohort_id, user_id, week_start)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	ensureUserLeagueQuery = `
+		INSERT INTO user_leagues (user_id)
+		VALUES ($1)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+
+	getEndedCohortsQuery = `
+		SELECT c.cohort_id, c.tier, c.week_start, c.week_end, c.created_at, c.closed_at,
+			(SELECT COUNT(*) FROM league_members m WHERE m.cohort_id = c.cohort_id) AS size
+		FROM league_cohorts c
+		WHERE c.closed_at IS NULL AND c.week_end <= $1
+		ORDER BY c.week_end ASC
+		LIMIT $2
+	`
+
+	getCohortQuery = `
+		SELECT c.cohort_id, c.tier, c.week_start, c.week_end, c.created_at, c.closed_at,
+			(SELECT COUNT(*) FROM league_members m WHERE m.cohort_id = c.cohort_id) AS size
+		FROM league_cohorts c
+		WHERE c.cohort_id = $1
+	`
+
+	lockOpenCohortQuery = getCohortQuery + `
+		AND c.closed_at IS NULL
+		FOR UPDATE
+	`
+
+	// Members ranked by weekly XP, ties go to who joined first. Closed cohorts keep their final XP
+	getStandingsQuery = `
+		SELECT
+			s.*,
+			ROW_NUMBER() OVER (ORDER BY s.xp DESC, s.joined_at ASC, s.user_id DESC) AS rank
+		FROM (
+			SELECT
+				m.cohort_id, m.user_id, u.first_name, u.avatar, m.joined_at, m.outcome,
+				COALESCE(m.xp, (
+					SELECT COALESCE(SUM(t.amount), 0) FROM xp_transactions t
+					WHERE t.user_id = m.user_id AND t.created_at >= c.week_start AND t.created_at < c.week_end
+				)) AS xp
+			FROM league_members m
+			JOIN league_cohorts c ON c.cohort_id = m.cohort_id
+			JOIN users u ON u.user_id = m.user_id
+			WHERE m.cohort_id = $1
+		) s
+		ORDER BY rank ASC
+	`
+
+	settleMemberQuery = `
+		UPDATE league_members
+		SET xp = $3, final_rank = $4, outcome = $5
+		WHERE cohort_id = $1 AND user_id = $2
+	`
+
+	// Promotions and relegations move a tier from the cohort's, a user keeps a single tier row
+	updateUserTierQuery = `
+		INSERT INTO user_leagues (user_id, tier)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET tier = EXCLUDED.tier, updated_at = CURRENT_TIMESTAMP
+	`
+
+	closeCohortQuery = `
+		UPDATE league_cohorts SET closed_at = CURRENT_TIMESTAMP WHERE cohort_id = $1
+	`
+
+	getUserCohortQuery = `
+		SELECT c.cohort_id, c.tier, c.week_start, c.week_end, c.created_at, c.closed_at,
+			(SELECT COUNT(*) FROM league_members cm WHERE cm.cohort_id = c.cohort_id) AS size
+		FROM league_members m
+		JOIN league_cohorts c ON c.cohort_id = m.cohort_id
+		WHERE m.user_id = $1 AND m.week_start = $2
+	`
+)
@@ -0,0 +1,20 @@
+package league
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// League UseCase interface
+type UseCase interface {
+	GetTiers() []*models.LeagueTier
+	// Standings of the user's cohort this week
+	GetUserLeague(ctx context.Context, userID uuid.UUID) (*models.LeagueStandings, error)
+	GetCohortStandings(ctx context.Context, cohortID uuid.UUID, userID uuid.UUID) (*models.LeagueStandings, error)
+
+	// Close the cohorts of the weeks that ended, then place this week's new players into cohorts
+	RunLeagues(ctx context.Context) (*models.LeagueJobReport, error)
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/leaderboard"
+	"github.com/AleksK1NG/api-mc/internal/league"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultCohortSize    = 30
+	defaultPromoteCount  = 7
+	defaultRelegateCount = 5
+	closeBatchSize       = 100
+)
+
+var defaultTiers = []string{"bronze", "silver", "gold"}
+
+type leagueUC struct {
+	cfg           *config.Config
+	leagueRepo    league.Repository
+	leaderboardUC leaderboard.UseCase
+	tiers         []string
+	cohortSize    int
+	promoteCount  int
+	relegateCount int
+	logger        logger.Logger
+}
+
+// NewLeagueUseCase creates the league use case, weeks are the weekly periods of the leaderboard
+func NewLeagueUseCase(
+	cfg *config.Config,
+	leagueRepo league.Repository,
+	leaderboardUC leaderboard.UseCase,
+	logger logger.Logger,
+) league.UseCase {
+	u := &leagueUC{
+		cfg:           cfg,
+		leagueRepo:    leagueRepo,
+		leaderboardUC: leaderboardUC,
+		tiers:         Tiers(cfg.League),
+		cohortSize:    cfg.League.CohortSize,
+		promoteCount:  cfg.League.PromoteCount,
+		relegateCount: cfg.League.RelegateCount,
+		logger:        logger,
+	}
+	if u.cohortSize <= 0 {
+		u.cohortSize = defaultCohortSize
+	}
+	if u.promoteCount <= 0 {
+		u.promoteCount = defaultPromoteCount
+	}
+	if u.relegateCount <= 0 {
+		u.relegateCount = defaultRelegateCount
+	}
+
+	return u
+}
+
+// Tiers returns the configured tier names from the lowest, or the default tiers when none are configured
+func Tiers(cfg config.LeagueConfig) []string {
+	if len(cfg.Tiers) == 0 {
+		return defaultTiers
+	}
+	return cfg.Tiers
+}
+
+func (u *leagueUC) GetTiers() []*models.LeagueTier {
+	tiers := make([]*models.LeagueTier, 0, len(u.tiers))
+	for i, name := range u.tiers {
+		tiers = append(tiers, &models.LeagueTier{Tier: i, Name: name})
+	}
+	return tiers
+}
+
+func (u *leagueUC) GetUserLeague(ctx context.Context, userID uuid.UUID) (*models.LeagueStandings, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "leagueUC.GetUserLeague")
+	defer span.Finish()
+
+	week, err := u.leaderboardUC.GetCurrentPeriod(models.LeaderboardPeriodWeekly)
+	if err != nil {
+		return nil, err
+	}
+
+	cohort, err := u.leagueRepo.GetUserCohort(ctx, userID, week.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.standings(ctx, cohort, userID)
+}
+
+func (u *leagueUC) GetCohortStandings(ctx context.Context, cohortID uuid.UUID, userID uuid.UUID) (*models.LeagueStandings, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "leagueUC.GetCohortStandings")
+	defer span.Finish()
+
+	cohort, err := u.leagueRepo.GetCohort(ctx, cohortID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.standings(ctx, cohort, userID)
+}
+
+func (u *leagueUC) standings(ctx context.Context, cohort *models.LeagueCohort, userID uuid.UUID) (*models.LeagueStandings, error) {
+	members, err := u.leagueRepo.GetStandings(ctx, cohort.CohortID)
+	if err != nil {
+		return nil, err
+	}
+
+	promote, relegate := u.zones(cohort.Tier, len(members))
+
+	var userRank *models.LeagueMember
+	for _, member := range members {
+		switch {
+		case member.Rank <= promote && member.XP > 0:
+			member.Zone = models.LeagueZonePromotion
+		case member.Rank > len(members)-relegate:
+			member.Zone = models.LeagueZoneRelegation
+		}
+		if member.UserID == userID {
+			userRank = member
+		}
+	}
+
+	return &models.LeagueStandings{
+		Cohort:        cohort,
+		TierName:      u.tierName(cohort.Tier),
+		PromoteCount:  promote,
+		RelegateCount: relegate,
+		Members:       members,
+		UserRank:      userRank,
+	}, nil
+}
+
+func (u *leagueUC) RunLeagues(ctx context.Context) (*models.LeagueJobReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "leagueUC.RunLeagues")
+	defer span.Finish()
+
+	report := &models.LeagueJobReport{}
+
+	for {
+		cohorts, err := u.leagueRepo.GetEndedCohorts(ctx, time.Now(), closeBatchSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, cohort := range cohorts {
+			members, err := u.leagueRepo.CloseCohort(ctx, cohort.CohortID, u.settle)
+			if err != nil {
+				return report, err
+			}
+			if members == nil {
+				// Closed by another instance
+				continue
+			}
+
+			report.CohortsClosed++
+			for _, member := range members {
+				switch *member.Outcome {
+				case models.LeagueOutcomePromoted:
+					report.Promoted++
+				case models.LeagueOutcomeRelegated:
+					report.Relegated++
+				}
+			}
+		}
+
+		if len(cohorts) < closeBatchSize {
+			break
+		}
+	}
+
+	week, err := u.leaderboardUC.GetCurrentPeriod(models.LeaderboardPeriodWeekly)
+	if err != nil {
+		return report, err
+	}
+
+	placed, err := u.leagueRepo.AssignUsers(ctx, week, u.assign)
+	if err != nil {
+		return report, err
+	}
+	for _, cohort := range placed {
+		// Cohorts opened by this run only hold the new members
+		if cohort.Size == len(cohort.NewMembers) {
+			report.CohortsOpened++
+		}
+		report.Assigned += len(cohort.NewMembers)
+	}
+
+	return report, nil
+}
+
+// assign fills the week's cohorts of each user's tier in the order they were opened, and opens a cohort when they are full
+func (u *leagueUC) assign(candidates []*models.LeagueCandidate, cohorts []*models.LeagueCohort) []*models.LeagueCohort {
+	placed := make([]*models.LeagueCohort, 0)
+	for _, candidate := range candidates {
+		cohort := u.openCohort(cohorts, candidate.Tier)
+		if cohort == nil {
+			cohort = &models.LeagueCohort{Tier: candidate.Tier}
+			cohorts = append(cohorts, cohort)
+		}
+
+		if len(cohort.NewMembers) == 0 {
+			placed = append(placed, cohort)
+		}
+		cohort.NewMembers = append(cohort.NewMembers, candidate.UserID)
+		cohort.Size++
+	}
+
+	return placed
+}
+
+func (u *leagueUC) openCohort(cohorts []*models.LeagueCohort, tier int) *models.LeagueCohort {
+	for _, cohort := range cohorts {
+		if cohort.Tier == tier && cohort.ClosedAt == nil && cohort.Size < u.cohortSize {
+			return cohort
+		}
+	}
+	return nil
+}
+
+// settle promotes the promotion zone and relegates the relegation zone of a ranked cohort.
+// Nobody is promoted out of the highest tier or relegated out of the lowest, and a user without XP is never promoted
+func (u *leagueUC) settle(cohort *models.LeagueCohort, members []*models.LeagueMember) {
+	promote, relegate := u.zones(cohort.Tier, len(members))
+
+	for _, member := range members {
+		outcome := models.LeagueOutcomeStayed
+		switch {
+		case member.Rank <= promote && member.XP > 0:
+			outcome = models.LeagueOutcomePromoted
+		case member.Rank > len(members)-relegate:
+			outcome = models.LeagueOutcomeRelegated
+		}
+		member.Outcome = &outcome
+	}
+}
+
+// zones returns how many ranks are promoted and relegated in a cohort of the tier with size members.
+// The configured counts are for a full cohort and scale down with smaller ones, the zones never overlap
+func (u *leagueUC) zones(tier int, size int) (int, int) {
+	promote := (u.promoteCount*size + u.cohortSize/2) / u.cohortSize
+	relegate := (u.relegateCount*size + u.cohortSize/2) / u.cohortSize
+
+	if tier >= len(u.tiers)-1 {
+		promote = 0
+	}
+	if tier <= 0 {
+		relegate = 0
+	}
+	if promote+relegate > size {
+		relegate = size - promote
+	}
+
+	return promote, relegate
+}
+
+func (u *leagueUC) tierName(tier int) string {
+	if tier < 0 || tier >= len(u.tiers) {
+		return ""
+	}
+	return u.tiers[tier]
+}
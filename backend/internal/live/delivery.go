@@ -0,0 +1,12 @@
+package live
+
+import "github.com/labstack/echo/v4"
+
+// Live quiz HTTP Handlers interface
+type Handlers interface {
+	CreateSession() echo.HandlerFunc
+	GetSessionByPIN() echo.HandlerFunc
+	GetResults() echo.HandlerFunc
+	// Upgrades to the WebSocket the host and the players play the session over
+	Play() echo.HandlerFunc
+}
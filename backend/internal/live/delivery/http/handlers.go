@@ -0,0 +1,169 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+
+	"github.com/AleksK1NG/api-mc/internal/live"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type liveHandlers struct {
+	liveUC live.UseCase
+	hub    *hub
+	logger logger.Logger
+}
+
+func NewLiveHandlers(liveUC live.UseCase, logger logger.Logger) live.Handlers {
+	return &liveHandlers{
+		liveUC: liveUC,
+		hub:    newHub(liveUC, logger),
+		logger: logger,
+	}
+}
+
+// CreateSession godoc
+// @Summary Host a live quiz session
+// @Description Draw the questions of a quiz and open a lobby the players join with the returned PIN
+// @Tags Live
+// @Accept json
+// @Produce json
+// @Param body body models.CreateLiveSessionRequest true "Quiz, question count and countdown"
+// @Success 201 {object} models.LiveSession
+// @Router /live/sessions [post]
+func (h *liveHandlers) CreateSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "liveHandlers.CreateSession.GetUserIDFromContext"))
+		}
+
+		req := &models.CreateLiveSessionRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "liveHandlers.CreateSession.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "liveHandlers.CreateSession.ValidateStruct"))
+		}
+
+		session, err := h.liveUC.CreateSession(c.Request().Context(), userID, req)
+		if err != nil {
+			return liveError(err, "liveHandlers.CreateSession.CreateSession")
+		}
+
+		return c.JSON(http.StatusCreated, session)
+	}
+}
+
+// GetSessionByPIN godoc
+// @Summary Find a live session by its PIN
+// @Description The session behind a PIN, to check it before joining
+// @Tags Live
+// @Produce json
+// @Param pin path string true "Session PIN"
+// @Success 200 {object} models.LiveSession
+// @Failure 404 {object} httpErrors.RestError
+// @Router /live/sessions/pin/{pin} [get]
+func (h *liveHandlers) GetSessionByPIN() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		session, err := h.liveUC.GetSessionByPIN(c.Request().Context(), c.Param("pin"))
+		if err != nil {
+			return liveError(err, "liveHandlers.GetSessionByPIN.GetSessionByPIN")
+		}
+
+		return c.JSON(http.StatusOK, session)
+	}
+}
+
+// GetResults godoc
+// @Summary Get the results of a finished live session
+// @Description Final ranks, points and quiz attempts of the players, for the host and the players
+// @Tags Live
+// @Produce json
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} models.LiveSessionResults
+// @Failure 404 {object} httpErrors.RestError
+// @Router /live/sessions/{session_id}/results [get]
+func (h *liveHandlers) GetResults() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "liveHandlers.GetResults.GetUserIDFromContext"))
+		}
+
+		sessionID, err := uuid.Parse(c.Param("session_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "liveHandlers.GetResults.uuid.Parse"))
+		}
+
+		results, err := h.liveUC.GetResults(c.Request().Context(), userID, sessionID)
+		if err != nil {
+			return liveError(err, "liveHandlers.GetResults.GetResults")
+		}
+
+		return c.JSON(http.StatusOK, results)
+	}
+}
+
+// Play godoc
+// @Summary Play a live session over a WebSocket
+// @Description Upgrade to the WebSocket of the session behind the PIN. The host runs the session, every other user joins it as a player
+// @Description under the nickname, their first name by default. See internal/live/README.md for the messages
+// @Tags Live
+// @Param pin path string true "Session PIN"
+// @Param nickname query string false "Nickname shown in the standings"
+// @Success 101
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /live/play/{pin} [get]
+func (h *liveHandlers) Play() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, ok := c.Get("user").(*models.User)
+		if !ok {
+			return httpErrors.NewUnauthorizedError(httpErrors.Unauthorized)
+		}
+
+		session, err := h.liveUC.GetSessionByPIN(c.Request().Context(), c.Param("pin"))
+		if err != nil {
+			return liveError(err, "liveHandlers.Play.GetSessionByPIN")
+		}
+
+		host := session.HostID == user.UserID
+		if !host {
+			if _, err := h.liveUC.Join(c.Request().Context(), session.SessionID, user, c.QueryParam("nickname")); err != nil {
+				return liveError(err, "liveHandlers.Play.Join")
+			}
+		}
+
+		websocket.Handler(func(ws *websocket.Conn) {
+			h.hub.serve(ws, session.SessionID, user.UserID, host)
+		}).ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+}
+
+func liveError(err error, op string) error {
+	switch {
+	case errors.Is(err, live.ErrSessionNotFound),
+		errors.Is(err, live.ErrResultsNotFound):
+		return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, live.ErrNotHost),
+		errors.Is(err, live.ErrHostCannotPlay),
+		errors.Is(err, httpErrors.PermissionDenied):
+		return httpErrors.NewRestError(http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, live.ErrSessionFull),
+		errors.Is(err, live.ErrSessionFinished),
+		errors.Is(err, live.ErrNoPINAvailable):
+		return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+	default:
+		return httpErrors.NewBadRequestError(errors.Wrap(err, op))
+	}
+}
@@ -0,0 +1,258 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+
+	"github.com/AleksK1NG/api-mc/internal/live"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	clientBufferSize = 64
+	messageTimeout   = 2 * time.Minute // finishing a session saves the attempts of every player
+	deliveryTimeout  = 5 * time.Second
+)
+
+// hub connects the WebSockets of this instance to the sessions they play. Every session with a client here has
+// a room subscribed to the session's messages, whichever instance published them, and fanning them out to its clients
+type hub struct {
+	liveUC live.UseCase
+	logger logger.Logger
+	mu     sync.Mutex
+	rooms  map[uuid.UUID]*room
+}
+
+type room struct {
+	clients map[*client]struct{}
+	cancel  context.CancelFunc
+	timer   *time.Timer // closes the question in progress once its countdown and grace ran out
+}
+
+type client struct {
+	userID uuid.UUID
+	host   bool
+	send   chan *models.LiveMessage
+}
+
+func newHub(liveUC live.UseCase, logger logger.Logger) *hub {
+	return &hub{
+		liveUC: liveUC,
+		logger: logger,
+		rooms:  make(map[uuid.UUID]*room),
+	}
+}
+
+// serve plays the session over the socket until either side closes it
+func (h *hub) serve(ws *websocket.Conn, sessionID uuid.UUID, userID uuid.UUID, host bool) {
+	defer ws.Close()
+
+	c := &client{
+		userID: userID,
+		host:   host,
+		send:   make(chan *models.LiveMessage, clientBufferSize),
+	}
+
+	if err := h.join(sessionID, c); err != nil {
+		h.logger.Errorf("hub.serve.join: %v", err)
+		return
+	}
+
+	// Sent once subscribed, so the state and the messages that follow it leave no gap
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	state, err := h.liveUC.GetState(ctx, sessionID, userID)
+	cancel()
+	if err != nil {
+		h.leave(sessionID, c)
+		h.logger.Errorf("hub.serve.GetState: %v", err)
+		return
+	}
+	h.reply(c, models.LiveMessageState, state)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.write(ws, sessionID, c)
+	}()
+
+	for {
+		message := &models.LiveMessage{}
+		if err := websocket.JSON.Receive(ws, message); err != nil {
+			break
+		}
+		h.handle(sessionID, c, message)
+	}
+
+	h.leave(sessionID, c)
+	<-done
+}
+
+// write delivers the client's messages, the reveal and the final standings with the player's own result
+func (h *hub) write(ws *websocket.Conn, sessionID uuid.UUID, c *client) {
+	for message := range c.send {
+		if !c.host {
+			ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+			personal, err := h.liveUC.ForPlayer(ctx, sessionID, c.userID, message)
+			cancel()
+			if err != nil {
+				h.logger.Errorf("hub.write.ForPlayer: %v", err)
+			} else {
+				message = personal
+			}
+		}
+
+		if err := websocket.JSON.Send(ws, message); err != nil {
+			// Unblocks the reader, the client leaves and its channel is closed
+			ws.Close()
+			continue
+		}
+		if message.Type == models.LiveMessageFinished {
+			ws.Close()
+		}
+	}
+}
+
+// handle runs a client's message, a rejected message is answered with an error
+func (h *hub) handle(sessionID uuid.UUID, c *client, message *models.LiveMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), messageTimeout)
+	defer cancel()
+
+	var err error
+	switch message.Type {
+	case models.LiveMessageStart:
+		err = h.liveUC.Start(ctx, c.userID, sessionID)
+	case models.LiveMessageNext:
+		err = h.liveUC.Next(ctx, c.userID, sessionID)
+	case models.LiveMessageEnd:
+		err = h.liveUC.End(ctx, c.userID, sessionID)
+	case models.LiveMessageAnswer:
+		req := &models.LiveAnswerRequest{}
+		if err = json.Unmarshal(message.Data, req); err != nil {
+			break
+		}
+
+		if _, err = h.liveUC.Answer(ctx, c.userID, sessionID, req); err != nil {
+			break
+		}
+		// The score is revealed with the question
+		h.reply(c, models.LiveMessageAnswered, req)
+	default:
+		err = live.ErrUnknownMessage
+	}
+
+	if err != nil {
+		h.reply(c, models.LiveMessageError, &models.LiveError{Message: err.Error()})
+	}
+}
+
+// reply sends a message to the client alone
+func (h *hub) reply(c *client, messageType string, data interface{}) {
+	message, err := models.NewLiveMessage(messageType, data)
+	if err != nil {
+		h.logger.Errorf("hub.reply.NewLiveMessage: %v", err)
+		return
+	}
+
+	select {
+	case c.send <- message:
+	default:
+		h.logger.Errorf("hub.reply: client %s is too slow, message dropped", c.userID)
+	}
+}
+
+// join adds the client to the session's room, the first client of this instance subscribes the room
+func (h *hub) join(sessionID uuid.UUID, c *client) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[sessionID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		messages, err := h.liveUC.Subscribe(ctx, sessionID)
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		r = &room{
+			clients: make(map[*client]struct{}),
+			cancel:  cancel,
+		}
+		h.rooms[sessionID] = r
+		go h.broadcast(sessionID, r, messages)
+	}
+
+	r.clients[c] = struct{}{}
+	return nil
+}
+
+// leave removes the client, the last client of this instance unsubscribes the room
+func (h *hub) leave(sessionID uuid.UUID, c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[sessionID]
+	if !ok {
+		return
+	}
+	if _, ok := r.clients[c]; !ok {
+		return
+	}
+
+	delete(r.clients, c)
+	close(c.send)
+
+	if len(r.clients) == 0 {
+		r.cancel()
+		if r.timer != nil {
+			r.timer.Stop()
+		}
+		delete(h.rooms, sessionID)
+	}
+}
+
+// broadcast fans the session's messages out to the room's clients until the room is unsubscribed.
+// Every instance with clients in the session closes each question when its time is up, the first one to do it wins
+func (h *hub) broadcast(sessionID uuid.UUID, r *room, messages <-chan *models.LiveMessage) {
+	for message := range messages {
+		h.mu.Lock()
+		if message.Type == models.LiveMessageQuestion {
+			h.scheduleClose(sessionID, r, message)
+		}
+		for c := range r.clients {
+			select {
+			case c.send <- message:
+			default:
+				h.logger.Errorf("hub.broadcast: client %s is too slow, message dropped", c.userID)
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+// scheduleClose replaces the room's timer with one closing the question of the message
+func (h *hub) scheduleClose(sessionID uuid.UUID, r *room, message *models.LiveMessage) {
+	question := &models.LiveQuestion{}
+	if err := json.Unmarshal(message.Data, question); err != nil {
+		h.logger.Errorf("hub.scheduleClose.json.Unmarshal: %v", err)
+		return
+	}
+
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(time.Until(question.Deadline)+live.AnswerGrace, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		defer cancel()
+
+		if err := h.liveUC.CloseQuestion(ctx, sessionID, question.Index); err != nil {
+			h.logger.Errorf("hub.scheduleClose.CloseQuestion: %v", err)
+		}
+	})
+}
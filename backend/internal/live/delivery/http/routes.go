@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/live"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+)
+
+// Map live quiz routes
+func MapLiveRoutes(liveGroup *echo.Group, h live.Handlers, mw *middleware.MiddlewareManager) {
+	protected := liveGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.POST("/sessions", h.CreateSession())
+		protected.GET("/sessions/pin/:pin", h.GetSessionByPIN())
+		protected.GET("/sessions/:session_id/results", h.GetResults())
+
+		// Browsers cannot set headers on a WebSocket, the jwt-token cookie authenticates it
+		protected.GET("/play/:pin", h.Play())
+	}
+}
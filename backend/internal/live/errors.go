@@ -0,0 +1,20 @@
+package live
+
+import "errors"
+
+// Live session errors
+var (
+	ErrSessionNotFound = errors.New("live session not found")
+	ErrNotHost         = errors.New("only the host can run the session")
+	ErrHostCannotPlay  = errors.New("the host cannot play their own session")
+	ErrNotPlayer       = errors.New("join the session to answer")
+	ErrSessionFull     = errors.New("live session is full")
+	ErrSessionFinished = errors.New("live session has finished")
+	ErrAlreadyStarted  = errors.New("live session has already started")
+	ErrNoQuestionOpen  = errors.New("no question is open")
+	ErrWrongQuestion   = errors.New("the question is no longer open")
+	ErrAlreadyAnswered = errors.New("you already answered this question")
+	ErrNoPINAvailable  = errors.New("no free PIN available, try again")
+	ErrResultsNotFound = errors.New("live session results not found")
+	ErrUnknownMessage  = errors.New("unknown message type")
+)
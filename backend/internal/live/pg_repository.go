@@ -0,0 +1,15 @@
+package live
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Repository keeps the results of the finished sessions
+type Repository interface {
+	SaveResults(ctx context.Context, results *models.LiveSessionResults) error
+	GetResults(ctx context.Context, sessionID uuid.UUID) (*models.LiveSessionResults, error)
+}
@@ -0,0 +1,46 @@
+package live
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// UpdateFunc applies a change to a session, it is saved unless it returns an error
+type UpdateFunc func(session *models.LiveSession) error
+
+// StateRepository keeps the state of the sessions in progress in Redis, shared by every API instance.
+// The state of a session expires once it has not changed for the configured SessionTTL
+type StateRepository interface {
+	// Saves a new session and its drawn questions, answer keys included, false when the PIN is taken
+	CreateSession(ctx context.Context, session *models.LiveSession, questions []*models.Question) (bool, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*models.LiveSession, error)
+	GetSessionIDByPIN(ctx context.Context, pin string) (uuid.UUID, error)
+	// Applies update to the session, retried when another instance changed the session in the meantime
+	UpdateSession(ctx context.Context, sessionID uuid.UUID, update UpdateFunc) (*models.LiveSession, error)
+	// The drawn question at index, with its answer key
+	GetQuestion(ctx context.Context, sessionID uuid.UUID, index int) (*models.Question, error)
+
+	// Adds the player, false when they already joined
+	AddPlayer(ctx context.Context, sessionID uuid.UUID, player *models.LivePlayer) (bool, error)
+	GetPlayer(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LivePlayer, error)
+	GetPlayers(ctx context.Context, sessionID uuid.UUID) ([]*models.LivePlayer, error)
+	CountPlayers(ctx context.Context, sessionID uuid.UUID) (int, error)
+
+	// Records the player's first answer to the question at index and adds its points, false when they already answered
+	SaveAnswer(ctx context.Context, sessionID uuid.UUID, index int, answer *models.LiveAnswer) (bool, error)
+	GetAnswer(ctx context.Context, sessionID uuid.UUID, index int, userID uuid.UUID) (*models.LiveAnswer, error)
+	GetAnswers(ctx context.Context, sessionID uuid.UUID, index int) ([]*models.LiveAnswer, error)
+	CountAnswers(ctx context.Context, sessionID uuid.UUID, index int) (int, error)
+
+	// Players by points, limit 0 returns all of them
+	GetStandings(ctx context.Context, sessionID uuid.UUID, limit int) ([]*models.LiveStanding, error)
+	GetStanding(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LiveStanding, error)
+
+	// Publishes a message to the clients of the session on every instance
+	Publish(ctx context.Context, sessionID uuid.UUID, message *models.LiveMessage) error
+	// Receives the messages published to the session until ctx is done
+	Subscribe(ctx context.Context, sessionID uuid.UUID) (<-chan *models.LiveMessage, error)
+}
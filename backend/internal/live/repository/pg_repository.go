@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/live"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type liveRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewLiveRepository(db *sqlx.DB, logger logger.Logger) live.Repository {
+	return &liveRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *liveRepo) SaveResults(ctx context.Context, results *models.LiveSessionResults) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "liveRepo.SaveResults.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	s := results.Session
+	if _, err := tx.ExecContext(
+		ctx,
+		createLiveSessionQuery,
+		s.SessionID,
+		s.QuizID,
+		s.HostID,
+		s.PIN,
+		s.QuestionCount,
+		s.PlayerCount,
+		s.StartedAt,
+		s.FinishedAt,
+	); err != nil {
+		return errors.Wrap(err, "liveRepo.SaveResults.createLiveSession")
+	}
+
+	for _, result := range results.Results {
+		if _, err := tx.ExecContext(
+			ctx,
+			createLiveResultQuery,
+			s.SessionID,
+			result.UserID,
+			result.Nickname,
+			result.Rank,
+			result.Points,
+			result.CorrectAnswers,
+			result.Answered,
+			result.AttemptID,
+		); err != nil {
+			return errors.Wrap(err, "liveRepo.SaveResults.createLiveResult")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "liveRepo.SaveResults.Commit")
+	}
+
+	return nil
+}
+
+func (r *liveRepo) GetResults(ctx context.Context, sessionID uuid.UUID) (*models.LiveSessionResults, error) {
+	session := &models.LiveSessionSummary{}
+	if err := r.db.GetContext(ctx, session, getLiveSessionQuery, sessionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, live.ErrResultsNotFound
+		}
+		return nil, errors.Wrap(err, "liveRepo.GetResults.GetContext")
+	}
+
+	results := make([]*models.LiveResult, 0, session.PlayerCount)
+	if err := r.db.SelectContext(ctx, &results, getLiveResultsQuery, sessionID); err != nil {
+		return nil, errors.Wrap(err, "liveRepo.GetResults.SelectContext")
+	}
+
+	return &models.LiveSessionResults{
+		Session: session,
+		Results: results,
+	}, nil
+}
@@ -0,0 +1,514 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/live"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	pinKeyPrefix        = "live:pin:"
+	sessionKeyPrefix    = "live:session:"
+	defaultSessionTTL   = 4 * time.Hour
+	maxUpdateRetries    = 10
+	subscribeBufferSize = 64
+)
+
+type liveStateRepo struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+	logger      logger.Logger
+}
+
+// NewLiveStateRepository creates the Redis repository of the sessions in progress, their keys expire ttl after their last change
+func NewLiveStateRepository(redisClient *redis.Client, ttl time.Duration, logger logger.Logger) live.StateRepository {
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
+	}
+	return &liveStateRepo{
+		redisClient: redisClient,
+		ttl:         ttl,
+		logger:      logger,
+	}
+}
+
+func (r *liveStateRepo) CreateSession(ctx context.Context, session *models.LiveSession, questions []*models.Question) (bool, error) {
+	reserved, err := r.redisClient.SetNX(ctx, pinKey(session.PIN), session.SessionID.String(), r.ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.CreateSession.SetNX")
+	}
+	if !reserved {
+		return false, nil
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.CreateSession.json.Marshal")
+	}
+
+	encoded := make([]interface{}, 0, len(questions))
+	for _, question := range questions {
+		q, err := json.Marshal(question)
+		if err != nil {
+			return false, errors.Wrap(err, "liveStateRepo.CreateSession.json.Marshal")
+		}
+		encoded = append(encoded, q)
+	}
+
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(session.SessionID), data, r.ttl)
+		pipe.RPush(ctx, questionsKey(session.SessionID), encoded...)
+		pipe.Expire(ctx, questionsKey(session.SessionID), r.ttl)
+		return nil
+	}); err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.CreateSession.TxPipelined")
+	}
+
+	return true, nil
+}
+
+func (r *liveStateRepo) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.LiveSession, error) {
+	data, err := r.redisClient.Get(ctx, sessionKey(sessionID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, live.ErrSessionNotFound
+		}
+		return nil, errors.Wrap(err, "liveStateRepo.GetSession.Get")
+	}
+
+	session := &models.LiveSession{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetSession.json.Unmarshal")
+	}
+
+	return session, nil
+}
+
+func (r *liveStateRepo) GetSessionIDByPIN(ctx context.Context, pin string) (uuid.UUID, error) {
+	id, err := r.redisClient.Get(ctx, pinKey(pin)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return uuid.Nil, live.ErrSessionNotFound
+		}
+		return uuid.Nil, errors.Wrap(err, "liveStateRepo.GetSessionIDByPIN.Get")
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "liveStateRepo.GetSessionIDByPIN.uuid.Parse")
+	}
+
+	return sessionID, nil
+}
+
+func (r *liveStateRepo) UpdateSession(ctx context.Context, sessionID uuid.UUID, update live.UpdateFunc) (*models.LiveSession, error) {
+	key := sessionKey(sessionID)
+
+	var updated *models.LiveSession
+	txf := func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return live.ErrSessionNotFound
+			}
+			return errors.Wrap(err, "liveStateRepo.UpdateSession.Get")
+		}
+
+		session := &models.LiveSession{}
+		if err := json.Unmarshal(data, session); err != nil {
+			return errors.Wrap(err, "liveStateRepo.UpdateSession.json.Unmarshal")
+		}
+
+		if err := update(session); err != nil {
+			return err
+		}
+
+		if data, err = json.Marshal(session); err != nil {
+			return errors.Wrap(err, "liveStateRepo.UpdateSession.json.Marshal")
+		}
+
+		// Fails with redis.TxFailedErr when the session changed since it was read
+		if _, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, r.ttl)
+			pipe.Expire(ctx, pinKey(session.PIN), r.ttl)
+			pipe.Expire(ctx, questionsKey(sessionID), r.ttl)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		updated = session
+		return nil
+	}
+
+	for i := 0; i < maxUpdateRetries; i++ {
+		err := r.redisClient.Watch(ctx, txf, key)
+		if err == nil {
+			return updated, nil
+		}
+		if !errors.Is(err, redis.TxFailedErr) {
+			return nil, err
+		}
+	}
+
+	return nil, errors.New("liveStateRepo.UpdateSession: too many concurrent updates")
+}
+
+func (r *liveStateRepo) GetQuestion(ctx context.Context, sessionID uuid.UUID, index int) (*models.Question, error) {
+	data, err := r.redisClient.LIndex(ctx, questionsKey(sessionID), int64(index)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, live.ErrSessionNotFound
+		}
+		return nil, errors.Wrap(err, "liveStateRepo.GetQuestion.LIndex")
+	}
+
+	question := &models.Question{}
+	if err := json.Unmarshal(data, question); err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetQuestion.json.Unmarshal")
+	}
+
+	return question, nil
+}
+
+func (r *liveStateRepo) AddPlayer(ctx context.Context, sessionID uuid.UUID, player *models.LivePlayer) (bool, error) {
+	data, err := json.Marshal(player)
+	if err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.AddPlayer.json.Marshal")
+	}
+
+	added, err := r.redisClient.HSetNX(ctx, playersKey(sessionID), player.UserID.String(), data).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.AddPlayer.HSetNX")
+	}
+
+	// Players are on the standings from the start, with no points
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAddNX(ctx, pointsKey(sessionID), &redis.Z{Member: player.UserID.String()})
+		pipe.Expire(ctx, playersKey(sessionID), r.ttl)
+		pipe.Expire(ctx, pointsKey(sessionID), r.ttl)
+		return nil
+	}); err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.AddPlayer.TxPipelined")
+	}
+
+	return added, nil
+}
+
+func (r *liveStateRepo) GetPlayer(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LivePlayer, error) {
+	data, err := r.redisClient.HGet(ctx, playersKey(sessionID), userID.String()).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, live.ErrNotPlayer
+		}
+		return nil, errors.Wrap(err, "liveStateRepo.GetPlayer.HGet")
+	}
+
+	player := &models.LivePlayer{}
+	if err := json.Unmarshal(data, player); err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetPlayer.json.Unmarshal")
+	}
+
+	return player, nil
+}
+
+func (r *liveStateRepo) GetPlayers(ctx context.Context, sessionID uuid.UUID) ([]*models.LivePlayer, error) {
+	values, err := r.redisClient.HVals(ctx, playersKey(sessionID)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetPlayers.HVals")
+	}
+
+	players := make([]*models.LivePlayer, 0, len(values))
+	for _, value := range values {
+		player := &models.LivePlayer{}
+		if err := json.Unmarshal([]byte(value), player); err != nil {
+			return nil, errors.Wrap(err, "liveStateRepo.GetPlayers.json.Unmarshal")
+		}
+		players = append(players, player)
+	}
+
+	return players, nil
+}
+
+func (r *liveStateRepo) CountPlayers(ctx context.Context, sessionID uuid.UUID) (int, error) {
+	count, err := r.redisClient.HLen(ctx, playersKey(sessionID)).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "liveStateRepo.CountPlayers.HLen")
+	}
+
+	return int(count), nil
+}
+
+func (r *liveStateRepo) SaveAnswer(ctx context.Context, sessionID uuid.UUID, index int, answer *models.LiveAnswer) (bool, error) {
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.SaveAnswer.json.Marshal")
+	}
+
+	key := answersKey(sessionID, index)
+	saved, err := r.redisClient.HSetNX(ctx, key, answer.UserID.String(), data).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.SaveAnswer.HSetNX")
+	}
+	if !saved {
+		return false, nil
+	}
+
+	// Only the first answer gets here, so the points are added once
+	member := answer.UserID.String()
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Expire(ctx, key, r.ttl)
+		pipe.ZIncrBy(ctx, pointsKey(sessionID), float64(answer.Points), member)
+		pipe.Expire(ctx, pointsKey(sessionID), r.ttl)
+		if answer.IsCorrect {
+			pipe.HIncrBy(ctx, correctKey(sessionID), member, 1)
+			pipe.Expire(ctx, correctKey(sessionID), r.ttl)
+		}
+		return nil
+	}); err != nil {
+		return false, errors.Wrap(err, "liveStateRepo.SaveAnswer.TxPipelined")
+	}
+
+	return true, nil
+}
+
+func (r *liveStateRepo) GetAnswer(ctx context.Context, sessionID uuid.UUID, index int, userID uuid.UUID) (*models.LiveAnswer, error) {
+	data, err := r.redisClient.HGet(ctx, answersKey(sessionID, index), userID.String()).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "liveStateRepo.GetAnswer.HGet")
+	}
+
+	answer := &models.LiveAnswer{}
+	if err := json.Unmarshal(data, answer); err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetAnswer.json.Unmarshal")
+	}
+
+	return answer, nil
+}
+
+func (r *liveStateRepo) GetAnswers(ctx context.Context, sessionID uuid.UUID, index int) ([]*models.LiveAnswer, error) {
+	values, err := r.redisClient.HVals(ctx, answersKey(sessionID, index)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetAnswers.HVals")
+	}
+
+	answers := make([]*models.LiveAnswer, 0, len(values))
+	for _, value := range values {
+		answer := &models.LiveAnswer{}
+		if err := json.Unmarshal([]byte(value), answer); err != nil {
+			return nil, errors.Wrap(err, "liveStateRepo.GetAnswers.json.Unmarshal")
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, nil
+}
+
+func (r *liveStateRepo) CountAnswers(ctx context.Context, sessionID uuid.UUID, index int) (int, error) {
+	count, err := r.redisClient.HLen(ctx, answersKey(sessionID, index)).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "liveStateRepo.CountAnswers.HLen")
+	}
+
+	return int(count), nil
+}
+
+func (r *liveStateRepo) GetStandings(ctx context.Context, sessionID uuid.UUID, limit int) ([]*models.LiveStanding, error) {
+	stop := int64(limit - 1)
+	if limit <= 0 {
+		stop = -1
+	}
+
+	scores, err := r.redisClient.ZRevRangeWithScores(ctx, pointsKey(sessionID), 0, stop).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStandings.ZRevRangeWithScores")
+	}
+	if len(scores) == 0 {
+		return []*models.LiveStanding{}, nil
+	}
+
+	members := make([]string, 0, len(scores))
+	for _, score := range scores {
+		members = append(members, score.Member.(string))
+	}
+
+	players, err := r.redisClient.HMGet(ctx, playersKey(sessionID), members...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStandings.HMGet")
+	}
+	correct, err := r.redisClient.HMGet(ctx, correctKey(sessionID), members...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStandings.HMGet")
+	}
+
+	standings := make([]*models.LiveStanding, 0, len(scores))
+	for i, score := range scores {
+		standing, err := newStanding(members[i], i+1, score.Score, players[i], correct[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "liveStateRepo.GetStandings.newStanding")
+		}
+		standings = append(standings, standing)
+	}
+
+	return standings, nil
+}
+
+func (r *liveStateRepo) GetStanding(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LiveStanding, error) {
+	member := userID.String()
+
+	rank, err := r.redisClient.ZRevRank(ctx, pointsKey(sessionID), member).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, live.ErrNotPlayer
+		}
+		return nil, errors.Wrap(err, "liveStateRepo.GetStanding.ZRevRank")
+	}
+
+	score, err := r.redisClient.ZScore(ctx, pointsKey(sessionID), member).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStanding.ZScore")
+	}
+	player, err := r.redisClient.HMGet(ctx, playersKey(sessionID), member).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStanding.HMGet")
+	}
+	correct, err := r.redisClient.HMGet(ctx, correctKey(sessionID), member).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStanding.HMGet")
+	}
+
+	standing, err := newStanding(member, int(rank)+1, score, player[0], correct[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "liveStateRepo.GetStanding.newStanding")
+	}
+
+	return standing, nil
+}
+
+func (r *liveStateRepo) Publish(ctx context.Context, sessionID uuid.UUID, message *models.LiveMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "liveStateRepo.Publish.json.Marshal")
+	}
+
+	if err := r.redisClient.Publish(ctx, channelName(sessionID), data).Err(); err != nil {
+		return errors.Wrap(err, "liveStateRepo.Publish.Publish")
+	}
+
+	return nil
+}
+
+func (r *liveStateRepo) Subscribe(ctx context.Context, sessionID uuid.UUID) (<-chan *models.LiveMessage, error) {
+	pubsub := r.redisClient.Subscribe(ctx, channelName(sessionID))
+	// Wait for the confirmation, so nothing published after Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, errors.Wrap(err, "liveStateRepo.Subscribe.Receive")
+	}
+
+	messages := make(chan *models.LiveMessage, subscribeBufferSize)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				message := &models.LiveMessage{}
+				if err := json.Unmarshal([]byte(msg.Payload), message); err != nil {
+					r.logger.Errorf("liveStateRepo.Subscribe: invalid message on %s: %v", msg.Channel, err)
+					continue
+				}
+
+				select {
+				case messages <- message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// newStanding builds a standing from the HMGET values of the player and their correct answers
+func newStanding(member string, rank int, points float64, player interface{}, correct interface{}) (*models.LiveStanding, error) {
+	userID, err := uuid.Parse(member)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := &models.LiveStanding{
+		Rank:   rank,
+		UserID: userID,
+		Points: int(points),
+	}
+
+	if data, ok := player.(string); ok {
+		p := &models.LivePlayer{}
+		if err := json.Unmarshal([]byte(data), p); err != nil {
+			return nil, err
+		}
+		standing.Nickname = p.Nickname
+	}
+	if count, ok := correct.(string); ok {
+		if standing.CorrectAnswers, err = strconv.Atoi(count); err != nil {
+			return nil, err
+		}
+	}
+
+	return standing, nil
+}
+
+func pinKey(pin string) string {
+	return pinKeyPrefix + pin
+}
+
+func sessionKey(sessionID uuid.UUID) string {
+	return sessionKeyPrefix + sessionID.String()
+}
+
+func questionsKey(sessionID uuid.UUID) string {
+	return sessionKey(sessionID) + ":questions"
+}
+
+func playersKey(sessionID uuid.UUID) string {
+	return sessionKey(sessionID) + ":players"
+}
+
+func pointsKey(sessionID uuid.UUID) string {
+	return sessionKey(sessionID) + ":points"
+}
+
+func correctKey(sessionID uuid.UUID) string {
+	return sessionKey(sessionID) + ":correct"
+}
+
+func answersKey(sessionID uuid.UUID, index int) string {
+	return fmt.Sprintf("%s:answers:%d", sessionKey(sessionID), index)
+}
+
+func channelName(sessionID uuid.UUID) string {
+	return sessionKey(sessionID) + ":messages"
+}
@@ -0,0 +1,23 @@
+package repository
+
+const (
+	createLiveSessionQuery = `
+		INSERT INTO live_sessions (session_id, quiz_id, host_id, pin, question_count, player_count, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (session_id) DO NOTHING
+	`
+
+	createLiveResultQuery = `
+		INSERT INTO live_session_results (session_id, user_id, nickname, rank, points, correct_answers, answered, attempt_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (session_id, user_id) DO NOTHING
+	`
+
+	getLiveSessionQuery = `
+		SELECT * FROM live_sessions WHERE session_id = $1
+	`
+
+	getLiveResultsQuery = `
+		SELECT * FROM live_session_results WHERE session_id = $1 ORDER BY rank ASC
+	`
+)
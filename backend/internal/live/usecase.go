@@ -0,0 +1,44 @@
+package live
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// AnswerGrace is how long after the deadline of a question an answer is still accepted,
+// it covers the latency of the players' connections. A question closes once its grace is over
+const AnswerGrace = time.Second
+
+// Live quiz UseCase interface
+type UseCase interface {
+	// Draws the questions of the quiz and opens the lobby under a new PIN
+	CreateSession(ctx context.Context, hostID uuid.UUID, req *models.CreateLiveSessionRequest) (*models.LiveSession, error)
+	GetSessionByPIN(ctx context.Context, pin string) (*models.LiveSession, error)
+	// Adds the user to the session, joining again keeps their points
+	Join(ctx context.Context, sessionID uuid.UUID, user *models.User, nickname string) (*models.LivePlayer, error)
+	// The session as seen by the host or a player
+	GetState(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LiveState, error)
+	GetStanding(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LiveStanding, error)
+
+	// Host actions
+	Start(ctx context.Context, hostID uuid.UUID, sessionID uuid.UUID) error
+	Next(ctx context.Context, hostID uuid.UUID, sessionID uuid.UUID) error
+	End(ctx context.Context, hostID uuid.UUID, sessionID uuid.UUID) error
+
+	// Scores the player's answer by correctness and speed, the question closes early once every player answered
+	Answer(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req *models.LiveAnswerRequest) (*models.LiveAnswer, error)
+	// Closes the question at index once its countdown ran out, a no-op when it is already closed
+	CloseQuestion(ctx context.Context, sessionID uuid.UUID, index int) error
+
+	// Receives the messages of the session until ctx is done
+	Subscribe(ctx context.Context, sessionID uuid.UUID) (<-chan *models.LiveMessage, error)
+	// Adds the player's own standing and answer to a reveal or finished message, other messages are returned as they are
+	ForPlayer(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, message *models.LiveMessage) (*models.LiveMessage, error)
+
+	// Final results of a finished session, for its host and players
+	GetResults(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*models.LiveSessionResults, error)
+}
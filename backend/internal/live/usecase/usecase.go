@@ -0,0 +1,690 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/chapter"
+	"github.com/AleksK1NG/api-mc/internal/live"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultQuestionDuration = 20 * time.Second
+	defaultMaxPoints        = 1000
+	defaultMaxPlayers       = 200
+	defaultStandingsSize    = 10
+	pinAttempts             = 10
+	maxNicknameLength       = 30
+)
+
+// errAlreadyDone skips a transition another instance or request already made
+var errAlreadyDone = errors.New("live session already moved on")
+
+type liveUC struct {
+	cfg              *config.Config
+	liveRepo         live.Repository
+	stateRepo        live.StateRepository
+	chapterUC        chapter.UseCase
+	questionDuration time.Duration
+	maxPoints        int
+	maxPlayers       int
+	standingsSize    int
+	logger           logger.Logger
+}
+
+// NewLiveUseCase creates the live quiz use case, the state of the sessions in progress is kept by stateRepo
+func NewLiveUseCase(
+	cfg *config.Config,
+	liveRepo live.Repository,
+	stateRepo live.StateRepository,
+	chapterUC chapter.UseCase,
+	logger logger.Logger,
+) live.UseCase {
+	u := &liveUC{
+		cfg:              cfg,
+		liveRepo:         liveRepo,
+		stateRepo:        stateRepo,
+		chapterUC:        chapterUC,
+		questionDuration: cfg.Live.QuestionDuration * time.Second,
+		maxPoints:        cfg.Live.MaxPoints,
+		maxPlayers:       cfg.Live.MaxPlayers,
+		standingsSize:    cfg.Live.StandingsSize,
+		logger:           logger,
+	}
+	if u.questionDuration <= 0 {
+		u.questionDuration = defaultQuestionDuration
+	}
+	if u.maxPoints <= 0 {
+		u.maxPoints = defaultMaxPoints
+	}
+	if u.maxPlayers <= 0 {
+		u.maxPlayers = defaultMaxPlayers
+	}
+	if u.standingsSize <= 0 {
+		u.standingsSize = defaultStandingsSize
+	}
+
+	return u
+}
+
+func (u *liveUC) CreateSession(ctx context.Context, hostID uuid.UUID, req *models.CreateLiveSessionRequest) (*models.LiveSession, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.CreateSession")
+	defer span.Finish()
+
+	count := req.QuestionCount
+	if count <= 0 {
+		count = u.cfg.Live.QuestionCount
+	}
+	duration := time.Duration(req.QuestionDuration) * time.Second
+	if duration <= 0 {
+		duration = u.questionDuration
+	}
+
+	questions, err := u.chapterUC.DrawQuizQuestions(ctx, req.QuizID, count)
+	if err != nil {
+		return nil, err
+	}
+
+	questionIDs := make([]uuid.UUID, 0, len(questions))
+	for _, question := range questions {
+		questionIDs = append(questionIDs, question.QuestionID)
+	}
+
+	session := &models.LiveSession{
+		SessionID:        uuid.New(),
+		QuizID:           req.QuizID,
+		HostID:           hostID,
+		Status:           models.LiveStatusLobby,
+		QuestionIDs:      questionIDs,
+		QuestionIndex:    -1,
+		QuestionDuration: int(duration / time.Second),
+		CreatedAt:        time.Now(),
+	}
+
+	for i := 0; i < pinAttempts; i++ {
+		session.PIN = fmt.Sprintf("%06d", rand.Intn(1000000))
+
+		created, err := u.stateRepo.CreateSession(ctx, session, questions)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return session, nil
+		}
+	}
+
+	return nil, live.ErrNoPINAvailable
+}
+
+func (u *liveUC) GetSessionByPIN(ctx context.Context, pin string) (*models.LiveSession, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.GetSessionByPIN")
+	defer span.Finish()
+
+	sessionID, err := u.stateRepo.GetSessionIDByPIN(ctx, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.stateRepo.GetSession(ctx, sessionID)
+}
+
+func (u *liveUC) Join(ctx context.Context, sessionID uuid.UUID, user *models.User, nickname string) (*models.LivePlayer, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.Join")
+	defer span.Finish()
+
+	session, err := u.stateRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.HostID == user.UserID {
+		return nil, live.ErrHostCannotPlay
+	}
+	if session.Status == models.LiveStatusFinished {
+		return nil, live.ErrSessionFinished
+	}
+
+	// Joining again, e.g. after a dropped connection, keeps the player's points
+	player, err := u.stateRepo.GetPlayer(ctx, sessionID, user.UserID)
+	if err == nil {
+		return player, nil
+	}
+	if !errors.Is(err, live.ErrNotPlayer) {
+		return nil, err
+	}
+
+	players, err := u.stateRepo.CountPlayers(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if players >= u.maxPlayers {
+		return nil, live.ErrSessionFull
+	}
+
+	player = &models.LivePlayer{
+		UserID:   user.UserID,
+		Nickname: cleanNickname(nickname, user.FirstName),
+		JoinedAt: time.Now(),
+	}
+	added, err := u.stateRepo.AddPlayer(ctx, sessionID, player)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		// Joined from another connection in the meantime
+		return u.stateRepo.GetPlayer(ctx, sessionID, user.UserID)
+	}
+
+	u.publish(ctx, sessionID, models.LiveMessagePlayerJoined, player)
+	return player, nil
+}
+
+func (u *liveUC) GetState(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LiveState, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.GetState")
+	defer span.Finish()
+
+	session, err := u.stateRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &models.LiveState{Session: session}
+	if session.HostID != userID {
+		if state.You, err = u.stateRepo.GetStanding(ctx, sessionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if state.Players, err = u.stateRepo.CountPlayers(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	switch session.Status {
+	case models.LiveStatusQuestion:
+		if state.Question, err = u.liveQuestion(ctx, session); err != nil {
+			return nil, err
+		}
+		answer, err := u.stateRepo.GetAnswer(ctx, sessionID, session.QuestionIndex, userID)
+		if err != nil {
+			return nil, err
+		}
+		state.Answered = answer != nil
+	case models.LiveStatusReveal, models.LiveStatusFinished:
+		if state.Standings, err = u.stateRepo.GetStandings(ctx, sessionID, u.standingsSize); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+func (u *liveUC) GetStanding(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) (*models.LiveStanding, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.GetStanding")
+	defer span.Finish()
+
+	return u.stateRepo.GetStanding(ctx, sessionID, userID)
+}
+
+func (u *liveUC) Start(ctx context.Context, hostID uuid.UUID, sessionID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.Start")
+	defer span.Finish()
+
+	session, err := u.stateRepo.UpdateSession(ctx, sessionID, func(s *models.LiveSession) error {
+		if s.HostID != hostID {
+			return live.ErrNotHost
+		}
+		if s.Status != models.LiveStatusLobby {
+			return live.ErrAlreadyStarted
+		}
+
+		now := time.Now()
+		s.StartedAt = &now
+		openQuestion(s, 0, now)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.publishQuestion(ctx, session)
+}
+
+func (u *liveUC) Next(ctx context.Context, hostID uuid.UUID, sessionID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.Next")
+	defer span.Finish()
+
+	session, err := u.stateRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.HostID != hostID {
+		return live.ErrNotHost
+	}
+
+	switch session.Status {
+	case models.LiveStatusLobby:
+		return u.Start(ctx, hostID, sessionID)
+	case models.LiveStatusQuestion:
+		// Skips the rest of the countdown
+		return u.CloseQuestion(ctx, sessionID, session.QuestionIndex)
+	case models.LiveStatusReveal:
+		if session.QuestionIndex+1 >= len(session.QuestionIDs) {
+			return u.finish(ctx, sessionID)
+		}
+	default:
+		return live.ErrSessionFinished
+	}
+
+	index := session.QuestionIndex + 1
+	session, err = u.stateRepo.UpdateSession(ctx, sessionID, func(s *models.LiveSession) error {
+		if s.Status != models.LiveStatusReveal || s.QuestionIndex != index-1 {
+			return errAlreadyDone
+		}
+
+		openQuestion(s, index, time.Now())
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyDone) {
+			return nil
+		}
+		return err
+	}
+
+	return u.publishQuestion(ctx, session)
+}
+
+func (u *liveUC) End(ctx context.Context, hostID uuid.UUID, sessionID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.End")
+	defer span.Finish()
+
+	session, err := u.stateRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.HostID != hostID {
+		return live.ErrNotHost
+	}
+	if session.Status == models.LiveStatusFinished {
+		return live.ErrSessionFinished
+	}
+
+	return u.finish(ctx, sessionID)
+}
+
+func (u *liveUC) Answer(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req *models.LiveAnswerRequest) (*models.LiveAnswer, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.Answer")
+	defer span.Finish()
+
+	now := time.Now()
+
+	session, err := u.stateRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.LiveStatusQuestion {
+		return nil, live.ErrNoQuestionOpen
+	}
+	if req.QuestionIndex != session.QuestionIndex || now.After(session.QuestionDeadline.Add(live.AnswerGrace)) {
+		return nil, live.ErrWrongQuestion
+	}
+
+	if _, err := u.stateRepo.GetPlayer(ctx, sessionID, userID); err != nil {
+		return nil, err
+	}
+
+	question, err := u.stateRepo.GetQuestion(ctx, sessionID, session.QuestionIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(session.QuestionDuration) * time.Second
+	elapsed := now.Sub(*session.QuestionStartedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if elapsed > duration {
+		elapsed = duration
+	}
+
+	answer := &models.LiveAnswer{
+		UserID:     userID,
+		QuestionID: question.QuestionID,
+		Answer:     req.Answer,
+		IsCorrect:  req.Answer == question.Answer,
+		Elapsed:    int(elapsed / time.Millisecond),
+		AnsweredAt: now,
+	}
+	if answer.IsCorrect {
+		answer.Points = u.points(elapsed, duration)
+	}
+
+	saved, err := u.stateRepo.SaveAnswer(ctx, sessionID, session.QuestionIndex, answer)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, live.ErrAlreadyAnswered
+	}
+
+	answered, err := u.stateRepo.CountAnswers(ctx, sessionID, session.QuestionIndex)
+	if err != nil {
+		return nil, err
+	}
+	players, err := u.stateRepo.CountPlayers(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.publish(ctx, sessionID, models.LiveMessageAnswerCount, &models.LiveAnswerCount{
+		Index:    session.QuestionIndex,
+		Answered: answered,
+		Players:  players,
+	})
+
+	// Nobody is left to answer, the question closes before its countdown ran out
+	if answered >= players {
+		if err := u.CloseQuestion(ctx, sessionID, session.QuestionIndex); err != nil {
+			u.logger.Errorf("liveUC.Answer.CloseQuestion: %v", err)
+		}
+	}
+
+	return answer, nil
+}
+
+func (u *liveUC) CloseQuestion(ctx context.Context, sessionID uuid.UUID, index int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.CloseQuestion")
+	defer span.Finish()
+
+	session, err := u.stateRepo.UpdateSession(ctx, sessionID, func(s *models.LiveSession) error {
+		if s.Status != models.LiveStatusQuestion || s.QuestionIndex != index {
+			return errAlreadyDone
+		}
+
+		s.Status = models.LiveStatusReveal
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyDone) {
+			return nil
+		}
+		return err
+	}
+
+	question, err := u.stateRepo.GetQuestion(ctx, sessionID, index)
+	if err != nil {
+		return err
+	}
+	answers, err := u.stateRepo.GetAnswers(ctx, sessionID, index)
+	if err != nil {
+		return err
+	}
+	standings, err := u.stateRepo.GetStandings(ctx, sessionID, u.standingsSize)
+	if err != nil {
+		return err
+	}
+
+	reveal := &models.LiveReveal{
+		Index:       index,
+		Total:       len(session.QuestionIDs),
+		QuestionID:  question.QuestionID,
+		Answer:      question.Answer,
+		Explanation: question.Explanation,
+		Answered:    len(answers),
+		Standings:   standings,
+	}
+	for _, answer := range answers {
+		if answer.IsCorrect {
+			reveal.CorrectCount++
+		}
+	}
+
+	u.publish(ctx, sessionID, models.LiveMessageReveal, reveal)
+	return nil
+}
+
+func (u *liveUC) Subscribe(ctx context.Context, sessionID uuid.UUID) (<-chan *models.LiveMessage, error) {
+	return u.stateRepo.Subscribe(ctx, sessionID)
+}
+
+func (u *liveUC) ForPlayer(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, message *models.LiveMessage) (*models.LiveMessage, error) {
+	switch message.Type {
+	case models.LiveMessageReveal:
+		reveal := &models.LiveReveal{}
+		if err := json.Unmarshal(message.Data, reveal); err != nil {
+			return nil, errors.Wrap(err, "liveUC.ForPlayer.json.Unmarshal")
+		}
+
+		var err error
+		if reveal.You, err = u.stateRepo.GetStanding(ctx, sessionID, userID); err != nil {
+			return nil, err
+		}
+		if reveal.YourAnswer, err = u.stateRepo.GetAnswer(ctx, sessionID, reveal.Index, userID); err != nil {
+			return nil, err
+		}
+		return models.NewLiveMessage(message.Type, reveal)
+	case models.LiveMessageFinished:
+		final := &models.LiveFinal{}
+		if err := json.Unmarshal(message.Data, final); err != nil {
+			return nil, errors.Wrap(err, "liveUC.ForPlayer.json.Unmarshal")
+		}
+
+		var err error
+		if final.You, err = u.stateRepo.GetStanding(ctx, sessionID, userID); err != nil {
+			return nil, err
+		}
+		return models.NewLiveMessage(message.Type, final)
+	default:
+		return message, nil
+	}
+}
+
+func (u *liveUC) GetResults(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*models.LiveSessionResults, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "liveUC.GetResults")
+	defer span.Finish()
+
+	results, err := u.liveRepo.GetResults(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if results.Session.HostID == userID {
+		return results, nil
+	}
+	for _, result := range results.Results {
+		if result.UserID == userID {
+			return results, nil
+		}
+	}
+
+	return nil, live.ErrResultsNotFound
+}
+
+// finish closes the session, broadcasts the final standings and saves every player's result as a quiz attempt
+// of the questions played. Only the request that finished the session saves the results
+func (u *liveUC) finish(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := u.stateRepo.UpdateSession(ctx, sessionID, func(s *models.LiveSession) error {
+		if s.Status == models.LiveStatusFinished {
+			return errAlreadyDone
+		}
+
+		now := time.Now()
+		s.Status = models.LiveStatusFinished
+		s.FinishedAt = &now
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyDone) {
+			return nil
+		}
+		return err
+	}
+
+	standings, err := u.stateRepo.GetStandings(ctx, sessionID, 0)
+	if err != nil {
+		return err
+	}
+
+	top := standings
+	if len(top) > u.standingsSize {
+		top = top[:u.standingsSize]
+	}
+	u.publish(ctx, sessionID, models.LiveMessageFinished, &models.LiveFinal{Standings: top})
+
+	return u.saveResults(ctx, session, standings)
+}
+
+func (u *liveUC) saveResults(ctx context.Context, session *models.LiveSession, standings []*models.LiveStanding) error {
+	played := session.QuestionIndex + 1
+	questionIDs := session.QuestionIDs[:played]
+
+	responses := make(map[uuid.UUID][]*models.UserQuestionResponse)
+	timeSpent := make(map[uuid.UUID]int)
+	for index := 0; index < played; index++ {
+		answers, err := u.stateRepo.GetAnswers(ctx, session.SessionID, index)
+		if err != nil {
+			return err
+		}
+		for _, answer := range answers {
+			seconds := answer.Elapsed / 1000
+			responses[answer.UserID] = append(responses[answer.UserID], &models.UserQuestionResponse{
+				QuestionID: answer.QuestionID,
+				UserAnswer: answer.Answer,
+				TimeSpent:  &seconds,
+			})
+			timeSpent[answer.UserID] += seconds
+		}
+	}
+
+	results := make([]*models.LiveResult, 0, len(standings))
+	for _, standing := range standings {
+		result := &models.LiveResult{
+			SessionID:      session.SessionID,
+			UserID:         standing.UserID,
+			Nickname:       standing.Nickname,
+			Rank:           standing.Rank,
+			Points:         standing.Points,
+			CorrectAnswers: standing.CorrectAnswers,
+			Answered:       len(responses[standing.UserID]),
+		}
+
+		// Graded against the questions played, the ones the player did not answer count as wrong
+		if result.Answered > 0 {
+			attempt, err := u.chapterUC.SubmitQuestionSetAnswers(
+				ctx,
+				standing.UserID,
+				session.QuizID,
+				questionIDs,
+				responses[standing.UserID],
+				timeSpent[standing.UserID],
+			)
+			if err != nil {
+				u.logger.Errorf("failed to save live session %s attempt of %s: %v", session.SessionID, standing.UserID, err)
+			} else {
+				result.AttemptID = &attempt.AttemptID
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	startedAt := session.CreatedAt
+	if session.StartedAt != nil {
+		startedAt = *session.StartedAt
+	}
+
+	return u.liveRepo.SaveResults(ctx, &models.LiveSessionResults{
+		Session: &models.LiveSessionSummary{
+			SessionID:     session.SessionID,
+			QuizID:        session.QuizID,
+			HostID:        session.HostID,
+			PIN:           session.PIN,
+			QuestionCount: played,
+			PlayerCount:   len(standings),
+			StartedAt:     startedAt,
+			FinishedAt:    *session.FinishedAt,
+		},
+		Results: results,
+	})
+}
+
+// points scores a correct answer by speed: MaxPoints when instant, down to half of it at the deadline
+func (u *liveUC) points(elapsed time.Duration, duration time.Duration) int {
+	return u.maxPoints - int(int64(u.maxPoints)*int64(elapsed)/(2*int64(duration)))
+}
+
+func (u *liveUC) liveQuestion(ctx context.Context, session *models.LiveSession) (*models.LiveQuestion, error) {
+	question, err := u.stateRepo.GetQuestion(ctx, session.SessionID, session.QuestionIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	served := *question
+	served.Answer = ""
+	served.Explanation = ""
+
+	return &models.LiveQuestion{
+		Index:     session.QuestionIndex,
+		Total:     len(session.QuestionIDs),
+		Question:  &served,
+		Duration:  session.QuestionDuration,
+		StartedAt: *session.QuestionStartedAt,
+		Deadline:  *session.QuestionDeadline,
+	}, nil
+}
+
+func (u *liveUC) publishQuestion(ctx context.Context, session *models.LiveSession) error {
+	question, err := u.liveQuestion(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	u.publish(ctx, session.SessionID, models.LiveMessageQuestion, question)
+	return nil
+}
+
+// publish broadcasts a message to the clients of the session, a lost message is caught up by the next state
+func (u *liveUC) publish(ctx context.Context, sessionID uuid.UUID, messageType string, data interface{}) {
+	message, err := models.NewLiveMessage(messageType, data)
+	if err != nil {
+		u.logger.Errorf("liveUC.publish.NewLiveMessage: %v", err)
+		return
+	}
+
+	if err := u.stateRepo.Publish(ctx, sessionID, message); err != nil {
+		u.logger.Errorf("liveUC.publish.Publish: %v", err)
+	}
+}
+
+// openQuestion starts the countdown of the question at index
+func openQuestion(s *models.LiveSession, index int, now time.Time) {
+	deadline := now.Add(time.Duration(s.QuestionDuration) * time.Second)
+	s.Status = models.LiveStatusQuestion
+	s.QuestionIndex = index
+	s.QuestionStartedAt = &now
+	s.QuestionDeadline = &deadline
+}
+
+// cleanNickname trims the chosen nickname to its maximum length, the user's first name is used when none is chosen
+func cleanNickname(nickname string, firstName string) string {
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" {
+		nickname = firstName
+	}
+
+	runes := []rune(nickname)
+	if len(runes) > maxNicknameLength {
+		runes = runes[:maxNicknameLength]
+	}
+	return string(runes)
+}
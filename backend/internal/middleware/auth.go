@@ -114,16 +114,66 @@ func (mw *MiddlewareManager) AuthJWTMiddleware(authUC auth.UseCase, cfg *config.
 	}
 }
 
-// Admin role
-// func (mw *MiddlewareManager) AdminMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-// 	return func(c echo.Context) error {
-// 		user, ok := c.Get("user").(*models.User)
-// 		if !ok || *user.Role != "admin" {
-// 			return c.JSON(http.StatusForbidden, httpErrors.NewUnauthorizedError(httpErrors.PermissionDenied))
-// 		}
-// 		return next(c)
-// 	}
-// }
+// Optional JWT auth, sets the user when a valid token is sent and lets anonymous requests through
+func (mw *MiddlewareManager) OptionalAuthJWTMiddleware(authUC auth.UseCase, cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var tokenString string
+			if bearerHeader := c.Request().Header.Get("Authorization"); bearerHeader != "" {
+				if headerParts := strings.Split(bearerHeader, " "); len(headerParts) == 2 {
+					tokenString = headerParts[1]
+				}
+			} else if cookie, err := c.Cookie("jwt-token"); err == nil {
+				tokenString = cookie.Value
+			}
+
+			if tokenString != "" {
+				if err := mw.validateJWTToken(tokenString, authUC, c, cfg); err != nil {
+					mw.logger.Infof("optional auth middleware, continuing anonymously: %s", err.Error())
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Permission based auth middleware, using the permissions of the JWT claims, after AuthJWTMiddleware
+func (mw *MiddlewareManager) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := utils.GetClaimsFromContext(c)
+			if err != nil {
+				mw.logger.Errorf("Error c.Get(claims) RequestID: %s, ERROR: %s,", utils.GetRequestID(c), "invalid claims ctx")
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			}
+
+			for _, p := range claims.Permissions {
+				if p == permission {
+					return next(c)
+				}
+			}
+
+			mw.logger.Errorf("RequirePermission RequestID: %s, UserID: %s, Permission: %s, ERROR: %s,",
+				utils.GetRequestID(c),
+				claims.ID,
+				permission,
+				"permission denied",
+			)
+			denial := &models.AuthorizationDenial{
+				Action:       c.Request().Method,
+				ResourceType: models.ResourceRoute,
+				ResourceID:   c.Path(),
+				Permission:   permission,
+			}
+			if userID, err := uuid.Parse(claims.ID); err == nil {
+				denial.UserID = &userID
+			}
+			mw.authzUC.RecordDenial(c.Request().Context(), denial)
+			return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
+		}
+	}
+}
 
 // Role based auth middleware, using ctx user
 func (mw *MiddlewareManager) OwnerOrAdminMiddleware() echo.MiddlewareFunc {
@@ -150,43 +200,13 @@ func (mw *MiddlewareManager) OwnerOrAdminMiddleware() echo.MiddlewareFunc {
 	}
 }
 
-// Role based auth middleware, using ctx user
-// func (mw *MiddlewareManager) RoleBasedAuthMiddleware(roles []string) echo.MiddlewareFunc {
-// 	return func(next echo.HandlerFunc) echo.HandlerFunc {
-// 		return func(c echo.Context) error {
-// 			user, ok := c.Get("user").(*models.User)
-// 			if !ok {
-// 				mw.logger.Errorf("Error c.Get(user) RequestID: %s, UserID: %s, ERROR: %s,",
-// 					utils.GetRequestID(c),
-// 					user.UserID.String(),
-// 					"invalid user ctx",
-// 				)
-// 				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
-// 			}
-
-// 			for _, role := range roles {
-// 				if role == *user.Role {
-// 					return next(c)
-// 				}
-// 			}
-
-// 			mw.logger.Errorf("Error c.Get(user) RequestID: %s, UserID: %s, ERROR: %s,",
-// 				utils.GetRequestID(c),
-// 				user.UserID.String(),
-// 				"invalid user ctx",
-// 			)
-
-// 			return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
-// 		}
-// 	}
-// }
-
 func (mw *MiddlewareManager) validateJWTToken(tokenString string, authUC auth.UseCase, c echo.Context, cfg *config.Config) error {
 	if tokenString == "" {
 		return httpErrors.InvalidJWTToken
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+	claims := &utils.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signin method %v", token.Header["alg"])
 		}
@@ -201,28 +221,40 @@ func (mw *MiddlewareManager) validateJWTToken(tokenString string, authUC auth.Us
 		return httpErrors.InvalidJWTToken
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, ok := claims["id"].(string)
-		if !ok {
-			return httpErrors.InvalidJWTClaims
-		}
-
-		userUUID, err := uuid.Parse(userID)
-		if err != nil {
-			return err
-		}
+	// Tokens without an id cannot be revoked, they are not accepted
+	if claims.Id == "" {
+		return httpErrors.InvalidJWTClaims
+	}
 
-		u, err := authUC.GetByID(c.Request().Context(), userUUID)
-		if err != nil {
-			return err
-		}
+	revoked, err := authUC.IsTokenRevoked(c.Request().Context(), claims.Id)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return auth.ErrTokenRevoked
+	}
 
-		c.Set("user", u)
+	userUUID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return err
+	}
 
-		ctx := context.WithValue(c.Request().Context(), utils.UserCtxKey{}, u)
-		// req := c.Request().WithContext(ctx)
-		c.SetRequest(c.Request().WithContext(ctx))
+	u, err := authUC.GetByID(c.Request().Context(), userUUID)
+	if err != nil {
+		return err
 	}
+
+	// The roles are the ones of the token, a change applies from the next refresh
+	u.Roles = claims.Roles
+	u.Permissions = claims.Permissions
+
+	c.Set("user", u)
+	c.Set("claims", claims)
+
+	ctx := context.WithValue(c.Request().Context(), utils.UserCtxKey{}, u)
+	// req := c.Request().WithContext(ctx)
+	c.SetRequest(c.Request().WithContext(ctx))
+
 	return nil
 }
 
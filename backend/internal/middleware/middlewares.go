@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/auth"
+	"github.com/AleksK1NG/api-mc/internal/authz"
 	"github.com/AleksK1NG/api-mc/internal/session"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
@@ -11,14 +12,15 @@ import (
 type MiddlewareManager struct {
 	sessUC  session.UCSession
 	authUC  auth.UseCase
+	authzUC authz.UseCase
 	cfg     *config.Config
 	origins []string
 	logger  logger.Logger
 }
 
 // Middleware manager constructor
-func NewMiddlewareManager(sessUC session.UCSession, authUC auth.UseCase, cfg *config.Config, origins []string, logger logger.Logger) *MiddlewareManager {
-	return &MiddlewareManager{sessUC: sessUC, authUC: authUC, cfg: cfg, origins: origins, logger: logger}
+func NewMiddlewareManager(sessUC session.UCSession, authUC auth.UseCase, authzUC authz.UseCase, cfg *config.Config, origins []string, logger logger.Logger) *MiddlewareManager {
+	return &MiddlewareManager{sessUC: sessUC, authUC: authUC, authzUC: authzUC, cfg: cfg, origins: origins, logger: logger}
 }
 
 // Get auth use case
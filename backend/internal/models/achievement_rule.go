@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Achievement rule metrics
+const (
+	MetricXP                     = "xp"
+	MetricLevel                  = "level"
+	MetricCurrentStreak          = "current_streak"
+	MetricMaxStreak              = "max_streak"
+	MetricXPEarned               = "xp_earned"         // XP from the ledger, filterable by subject and time
+	MetricLessonsCompleted       = "lessons_completed" // completed lessons
+	MetricQuizAttempts           = "quiz_attempts"     // submitted attempts
+	MetricQuizzes                = "quizzes"           // distinct quizzes with a matching attempt
+	MetricBestQuizScore          = "best_quiz_score"
+	MetricAvgQuizScore           = "avg_quiz_score"
+	MetricChaptersRead           = "chapters_read"             // summed over the matching subjects
+	MetricTopSubjectChaptersRead = "top_subject_chapters_read" // chapters read in the user's strongest subject
+)
+
+// AchievementRule is a boolean expression over the achievement metrics.
+// A node is either a combinator (all, any, not) or a condition comparing a metric to a value.
+//
+//	{"all": [{"metric": "quizzes", "filter": {"subject": "math", "min_score": 90, "within_days": 7}, "op": ">=", "value": 5}]}
+type AchievementRule struct {
+	All       []*AchievementRule `json:"all,omitempty"`
+	Any       []*AchievementRule `json:"any,omitempty"`
+	Not       *AchievementRule   `json:"not,omitempty"`
+	Metric    string             `json:"metric,omitempty"`
+	Filter    *MetricFilter      `json:"filter,omitempty"`
+	Op        string             `json:"op,omitempty"`    // one of >=, >, <=, <, ==, !=
+	Threshold float64            `json:"value,omitempty"` // value the metric is compared against
+}
+
+// Scan the jsonb rule column
+func (r *AchievementRule) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported achievement rule type %T", src)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// Value stores the rule as jsonb
+func (r *AchievementRule) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// MetricFilter narrows a metric, which filters a metric accepts depends on the metric
+type MetricFilter struct {
+	Subject    string `json:"subject,omitempty"`
+	Grade      int    `json:"grade,omitempty"`
+	MinScore   *int   `json:"min_score,omitempty"`   // only attempts scoring at least this much
+	WithinDays int    `json:"within_days,omitempty"` // only activity of the last N days
+}
+
+// MetricValue is a metric read while evaluating a rule
+type MetricValue struct {
+	Metric string        `json:"metric"`
+	Filter *MetricFilter `json:"filter,omitempty"`
+	Value  float64       `json:"value"`
+}
+
+// AchievementDryRunRequest evaluates a rule, or the rule of a stored achievement, against existing users
+type AchievementDryRunRequest struct {
+	AchievementID *uuid.UUID       `json:"achievement_id"`
+	Rule          *AchievementRule `json:"rule"`
+	Limit         int              `json:"limit" validate:"gte=0,lte=1000"` // users to evaluate
+}
+
+// AchievementDryRunMatch is a user the rule would award
+type AchievementDryRunMatch struct {
+	UserID        uuid.UUID      `json:"user_id"`
+	AlreadyEarned bool           `json:"already_earned"`
+	Values        []*MetricValue `json:"values"`
+}
+
+// AchievementDryRunResult is the outcome of a dry-run, nothing is awarded
+type AchievementDryRunResult struct {
+	Evaluated  int                       `json:"evaluated"`
+	Matched    int                       `json:"matched"`
+	NewAwards  int                       `json:"new_awards"` // matches that do not have the achievement yet
+	MatchRatio float64                   `json:"match_ratio"`
+	Matches    []*AchievementDryRunMatch `json:"matches"` // capped sample
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Item analytics flags
+const (
+	FlagTooHard                = "too_hard"
+	FlagTooEasy                = "too_easy"
+	FlagNegativeDiscrimination = "negative_discrimination"
+	FlagLowDiscrimination      = "low_discrimination"
+	FlagAnswerNotInOptions     = "answer_not_in_options"
+	FlagPossibleKeyError       = "possible_key_error"
+)
+
+// QuestionAggregate holds the raw response aggregates for a single question
+type QuestionAggregate struct {
+	QuestionID     uuid.UUID `json:"question_id" db:"question_id"`
+	QuestionType   string    `json:"question_type" db:"question_type"`
+	Options        []string  `json:"options" db:"options"`
+	Answer         string    `json:"answer" db:"answer"`
+	Difficulty     string    `json:"difficulty" db:"difficulty"`
+	Responses      int       `json:"responses" db:"responses"`
+	Correct        int       `json:"correct" db:"correct"`
+	Discrimination *float64  `json:"discrimination" db:"discrimination"`
+	AvgTimeSpent   *float64  `json:"avg_time_spent" db:"avg_time_spent"`
+}
+
+// WrongAnswerCount is the most frequently chosen wrong answer for a question
+type WrongAnswerCount struct {
+	QuestionID uuid.UUID `json:"question_id" db:"question_id"`
+	UserAnswer string    `json:"user_answer" db:"user_answer"`
+	Count      int       `json:"count" db:"count"`
+}
+
+// QuestionStats is the computed item analysis for a question
+type QuestionStats struct {
+	QuestionID            uuid.UUID `json:"question_id" db:"question_id"`
+	Responses             int       `json:"responses" db:"responses"`
+	Correct               int       `json:"correct" db:"correct"`
+	PValue                float64   `json:"p_value" db:"p_value"`               // share of correct responses
+	Discrimination        *float64  `json:"discrimination" db:"discrimination"` // point-biserial correlation with the attempt score
+	MostCommonWrongAnswer *string   `json:"most_common_wrong_answer" db:"most_common_wrong_answer"`
+	WrongAnswerCount      int       `json:"wrong_answer_count" db:"wrong_answer_count"`
+	AvgTimeSpent          *float64  `json:"avg_time_spent" db:"avg_time_spent"` // in seconds
+	Flags                 []string  `json:"flags" db:"flags"`
+	Difficulty            string    `json:"difficulty" db:"difficulty"`
+	SuggestedDifficulty   *string   `json:"suggested_difficulty" db:"suggested_difficulty"`
+	ComputedAt            time.Time `json:"computed_at" db:"computed_at"`
+}
+
+// QuestionStatsFilter narrows the item analytics listing
+type QuestionStatsFilter struct {
+	QuizID      *uuid.UUID `json:"quiz_id"`
+	Flag        string     `json:"flag"`
+	FlaggedOnly bool       `json:"flagged_only"`
+	Limit       int        `json:"limit"`
+	Offset      int        `json:"offset"`
+}
+
+// QuestionStatsList is a page of item analytics
+type QuestionStatsList struct {
+	TotalCount int              `json:"total_count"`
+	Stats      []*QuestionStats `json:"stats"`
+}
+
+// ItemAnalyticsReport summarizes a single analytics run
+type ItemAnalyticsReport struct {
+	QuestionsAnalyzed int       `json:"questions_analyzed"`
+	Flagged           int       `json:"flagged"`
+	Recalibrated      int       `json:"recalibrated"`
+	ComputedAt        time.Time `json:"computed_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions of the authorization checks
+const (
+	AccessActionRead   = "read"
+	AccessActionCreate = "create"
+	AccessActionUpdate = "update"
+	AccessActionDelete = "delete"
+	AccessActionUse    = "use"
+)
+
+// Resources of the authorization checks, a denied route is audited with its path
+const (
+	ResourceChapter  = "chapter"
+	ResourceLesson   = "lesson"
+	ResourceProfile  = "profile"
+	ResourceQuestion = "question"
+	ResourceQuiz     = "quiz"
+	ResourceRoute    = "route"
+)
+
+// AccessRequest asks whether the user of the context may act on a resource,
+// the owner is allowed as well as the users granted the permission
+type AccessRequest struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	OwnerID      uuid.UUID // uuid.Nil when the resource has no owner
+	Permission   string
+}
+
+// AuthorizationDenial is an audited authorization failure
+type AuthorizationDenial struct {
+	DenialID     uuid.UUID  `json:"denial_id" db:"denial_id"`
+	UserID       *uuid.UUID `json:"user_id,omitempty" db:"user_id"` // nil for anonymous requests
+	Action       string     `json:"action" db:"action"`
+	ResourceType string     `json:"resource_type" db:"resource_type"`
+	ResourceID   string     `json:"resource_id" db:"resource_id"`
+	Permission   string     `json:"permission" db:"permission"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AuthorizationDenialList is a page of audited authorization failures
+type AuthorizationDenialList struct {
+	TotalCount int                    `json:"total_count"`
+	Denials    []*AuthorizationDenial `json:"denials"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Challenge statuses
+const (
+	ChallengeStatusPending   = "pending"   // waiting for the opponent to accept
+	ChallengeStatusActive    = "active"    // accepted, the players answer the questions
+	ChallengeStatusDeclined  = "declined"  // declined by the opponent
+	ChallengeStatusExpired   = "expired"   // not accepted in time, or nobody submitted in time
+	ChallengeStatusCompleted = "completed" // both submitted, or one forfeited
+)
+
+// Challenge is a head-to-head quiz between two users on the same drawn questions
+type Challenge struct {
+	ChallengeID  uuid.UUID  `json:"challenge_id" db:"challenge_id"`
+	ChallengerID uuid.UUID  `json:"challenger_id" db:"challenger_id"`
+	OpponentID   uuid.UUID  `json:"opponent_id" db:"opponent_id"`
+	QuizID       uuid.UUID  `json:"quiz_id" db:"quiz_id"`
+	Status       string     `json:"status" db:"status"`
+	BonusXP      int        `json:"bonus_xp" db:"bonus_xp"`             // awarded to the winner
+	WinnerID     *uuid.UUID `json:"winner_id,omitempty" db:"winner_id"` // nil for a draw or an unfinished challenge
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`         // deadline to accept while pending, to submit once active
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	AcceptedAt   *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+
+	QuestionIDs []uuid.UUID        `json:"-" db:"-"` // in the order they are served
+	Results     []*ChallengeResult `json:"results" db:"-"`
+}
+
+// ChallengeResult is a player's run of a challenge, timed from the moment the questions were served
+type ChallengeResult struct {
+	ChallengeID uuid.UUID  `json:"-" db:"challenge_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	AttemptID   *uuid.UUID `json:"attempt_id,omitempty" db:"attempt_id"`
+	Score       *int       `json:"score,omitempty" db:"score"`
+	TimeSpent   *int       `json:"time_spent,omitempty" db:"time_spent"` // in seconds
+}
+
+// CreateChallengeRequest challenges a followed user on a quiz
+type CreateChallengeRequest struct {
+	OpponentID uuid.UUID `json:"opponent_id" validate:"required"`
+	QuizID     uuid.UUID `json:"quiz_id" validate:"required"`
+}
+
+// ChallengeRound is the question set served to a player, without the answer keys
+type ChallengeRound struct {
+	Challenge *Challenge  `json:"challenge"`
+	Questions []*Question `json:"questions"`
+}
+
+// ChallengeSubmission is a player's answers to the question set
+type ChallengeSubmission struct {
+	Answers []*UserQuestionResponse `json:"answers"`
+}
+
+// ChallengeOutcome is the result of a submission, the challenge is completed once both players submitted
+type ChallengeOutcome struct {
+	Challenge *Challenge       `json:"challenge"`
+	Attempt   *UserQuizAttempt `json:"attempt"`
+	BonusXP   *XPAward         `json:"bonus_xp,omitempty"` // set when the submission won the challenge
+}
+
+// ChallengeList represents a page of challenges
+type ChallengeList struct {
+	TotalCount int          `json:"total_count"`
+	Challenges []*Challenge `json:"challenges"`
+}
+
+// ChallengeJobReport summarizes a single run of the challenge expiry job
+type ChallengeJobReport struct {
+	Expired   int `json:"expired"`   // pending or unplayed challenges closed without a winner
+	Forfeited int `json:"forfeited"` // won by the only player who submitted in time
+}
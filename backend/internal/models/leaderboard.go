@@ -6,13 +6,48 @@ import (
 	"github.com/google/uuid"
 )
 
+// Leaderboard scopes, every user has an entry on each board they belong to
+const (
+	LeaderboardScopeGlobal  = "global"
+	LeaderboardScopeSubject = "subject" // XP earned in the subject, keyed by subject
+	LeaderboardScopeGrade   = "grade"   // students of the grade, keyed by grade
+	LeaderboardScopeClass   = "class"   // members of the class, keyed by class id
+)
+
+// Leaderboard periods, a period board ranks the XP earned within the period and resets at its boundary
+const (
+	LeaderboardPeriodDaily   = "daily"
+	LeaderboardPeriodWeekly  = "weekly" // weeks start on Monday
+	LeaderboardPeriodMonthly = "monthly"
+	LeaderboardPeriodAllTime = "all-time"
+)
+
+// LeaderboardPeriod is a daily, weekly or monthly window, bounded at midnight in the leaderboard timezone
+type LeaderboardPeriod struct {
+	Period      string     `json:"period" db:"period"`
+	PeriodStart time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end" db:"period_end"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty" db:"closed_at"` // set once the final standings are archived
+}
+
+// LeaderboardBoard identifies a board by its scope and scope key
+type LeaderboardBoard struct {
+	Scope    string `json:"scope" db:"scope"`
+	ScopeKey string `json:"scope_key" db:"scope_key"`
+
+	// Open period of a period board, nil for the all-time board
+	Period *LeaderboardPeriod `json:"-" db:"-"`
+}
+
 // LeaderboardEntry represents a single entry in the leaderboard
 type LeaderboardEntry struct {
 	EntryID   uuid.UUID `json:"entry_id" db:"entry_id" validate:"omitempty"`
 	UserID    uuid.UUID `json:"user_id" db:"user_id" validate:"required"`
-	Username  string    `json:"username" db:"username" validate:"required"`
-	AvatarURL string    `json:"avatar_url" db:"avatar_url"`
-	XP        int       `json:"xp" db:"xp"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	Avatar    *string   `json:"avatar,omitempty" db:"avatar"`
+	Scope     string    `json:"scope" db:"scope"`
+	ScopeKey  string    `json:"scope_key,omitempty" db:"scope_key"`
+	XP        int       `json:"xp" db:"xp"` // XP gained within the scope
 	Level     int       `json:"level" db:"level"`
 	Streak    int       `json:"streak" db:"streak"`
 	Rank      int       `json:"rank" db:"rank"`
@@ -22,16 +57,62 @@ type LeaderboardEntry struct {
 
 // LeaderboardFilter represents filter options for leaderboard queries
 type LeaderboardFilter struct {
-	TimeFrame string    `json:"time_frame"` // daily, weekly, monthly, all-time
-	Subject   string    `json:"subject"`    // filter by subject
-	Grade     int       `json:"grade"`      // filter by grade
-	Limit     int       `json:"limit"`      // number of entries to return
-	UserID    uuid.UUID `json:"user_id"`    // to get a specific user's rank
+	TimeFrame string     `json:"time_frame"` // daily, weekly, monthly, all-time
+	Subject   string     `json:"subject"`    // subject board
+	Grade     int        `json:"grade"`      // grade board
+	ClassID   *uuid.UUID `json:"class_id"`   // class board
+	Limit     int        `json:"limit"`      // number of entries to return
+	UserID    uuid.UUID  `json:"user_id"`    // to get a specific user's rank
+	Friends   bool       `json:"friends"`    // rank the user among the users they follow
+
+	// Board resolved from Subject, Grade and ClassID, the global board when none is set
+	Scope    string `json:"-"`
+	ScopeKey string `json:"-"`
+
+	// Period resolved from TimeFrame, nil for all-time
+	Period *LeaderboardPeriod `json:"-"`
 }
 
 // LeaderboardResponse represents the response for leaderboard queries
 type LeaderboardResponse struct {
+	Scope        string              `json:"scope"`
+	ScopeKey     string              `json:"scope_key,omitempty"`
+	Period       *LeaderboardPeriod  `json:"period,omitempty"`  // open period of a time frame board
+	Friends      bool                `json:"friends,omitempty"` // ranked among the user and the users they follow
 	Entries      []*LeaderboardEntry `json:"entries"`
 	TotalEntries int                 `json:"total_entries"`
 	UserRank     *LeaderboardEntry   `json:"user_rank,omitempty"` // Current user's rank if requested
 }
+
+// LeaderboardSnapshotEntry is a user's final standing on a board of a closed period
+type LeaderboardSnapshotEntry struct {
+	Period      string    `json:"period" db:"period"`
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time `json:"period_end" db:"period_end"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	FirstName   string    `json:"first_name" db:"first_name"`
+	Avatar      *string   `json:"avatar,omitempty" db:"avatar"`
+	Scope       string    `json:"scope" db:"scope"`
+	ScopeKey    string    `json:"scope_key,omitempty" db:"scope_key"`
+	XP          int       `json:"xp" db:"xp"` // XP gained within the scope during the period
+	Rank        int       `json:"rank" db:"rank"`
+	BoardSize   int       `json:"board_size" db:"board_size"` // entries on the board when the period closed
+}
+
+// LeaderboardStandings represents the archived final standings of a board for a closed period
+type LeaderboardStandings struct {
+	Period       *LeaderboardPeriod          `json:"period"`
+	Scope        string                      `json:"scope"`
+	ScopeKey     string                      `json:"scope_key,omitempty"`
+	Entries      []*LeaderboardSnapshotEntry `json:"entries"`
+	TotalEntries int                         `json:"total_entries"`
+	UserRank     *LeaderboardSnapshotEntry   `json:"user_rank,omitempty"` // Current user's standing if requested
+}
+
+// Class groups students for a class leaderboard
+type Class struct {
+	ClassID   uuid.UUID `json:"class_id" db:"class_id" validate:"omitempty"`
+	Name      string    `json:"name" db:"name" validate:"required,lte=100"`
+	Grade     int       `json:"grade" db:"grade" validate:"required,gte=1,lte=12"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
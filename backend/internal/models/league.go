@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// League outcomes, applied to the members' tiers when the week closes
+const (
+	LeagueOutcomePromoted  = "promoted"
+	LeagueOutcomeStayed    = "stayed"
+	LeagueOutcomeRelegated = "relegated"
+)
+
+// League zones of the standings, the ranks promoted or relegated if the week closed now
+const (
+	LeagueZonePromotion  = "promotion"
+	LeagueZoneRelegation = "relegation"
+)
+
+// LeagueTier is a league tier, Tier indexes the configured tiers from the lowest
+type LeagueTier struct {
+	Tier int    `json:"tier"`
+	Name string `json:"name"`
+}
+
+// LeagueCohort is a weekly group of users of the same tier ranked by the XP they earn during the week
+type LeagueCohort struct {
+	CohortID  uuid.UUID  `json:"cohort_id" db:"cohort_id"`
+	Tier      int        `json:"tier" db:"tier"`
+	WeekStart time.Time  `json:"week_start" db:"week_start"`
+	WeekEnd   time.Time  `json:"week_end" db:"week_end"`
+	Size      int        `json:"size" db:"size"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+
+	// Users placed in the cohort by an assignment run, the cohort is created when CohortID is nil
+	NewMembers []uuid.UUID `json:"-" db:"-"`
+}
+
+// LeagueCandidate is a user who earned XP this week and has no league yet
+type LeagueCandidate struct {
+	UserID uuid.UUID `db:"user_id"`
+	Tier   int       `db:"tier"`
+}
+
+// LeagueMember is a user's standing in a cohort
+type LeagueMember struct {
+	CohortID  uuid.UUID `json:"-" db:"cohort_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	Avatar    *string   `json:"avatar,omitempty" db:"avatar"`
+	XP        int       `json:"xp" db:"xp"` // XP earned during the week
+	Rank      int       `json:"rank" db:"rank"`
+	Zone      string    `json:"zone,omitempty" db:"-"`
+	Outcome   *string   `json:"outcome,omitempty" db:"outcome"` // set once the cohort closed
+	JoinedAt  time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// LeagueStandings represents a cohort ranked by weekly XP
+type LeagueStandings struct {
+	Cohort        *LeagueCohort   `json:"cohort"`
+	TierName      string          `json:"tier_name"`
+	PromoteCount  int             `json:"promote_count"`  // ranks in the promotion zone
+	RelegateCount int             `json:"relegate_count"` // ranks in the relegation zone
+	Members       []*LeagueMember `json:"members"`
+	UserRank      *LeagueMember   `json:"user_rank,omitempty"` // Current user's standing if requested
+}
+
+// LeagueJobReport summarizes a single league run
+type LeagueJobReport struct {
+	Assigned      int `json:"assigned"`
+	CohortsOpened int `json:"cohorts_opened"`
+	CohortsClosed int `json:"cohorts_closed"`
+	Promoted      int `json:"promoted"`
+	Relegated     int `json:"relegated"`
+}
@@ -47,6 +47,8 @@ type Chapter struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 	Lessons     []*Lesson `json:"lessons,omitempty" db:"-"`
+	// Set for authenticated requests
+	Progress *ChapterProgress `json:"progress,omitempty" db:"-"`
 }
 
 // LessonList represents a paginated list of lessons
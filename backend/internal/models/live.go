@@ -0,0 +1,190 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Live session statuses
+const (
+	LiveStatusLobby    = "lobby"    // players join with the PIN
+	LiveStatusQuestion = "question" // a question is counting down
+	LiveStatusReveal   = "reveal"   // the answer and the standings of the last question are shown
+	LiveStatusFinished = "finished" // the results are saved
+)
+
+// Live messages sent by the server
+const (
+	LiveMessageState        = "state"         // the session as seen by the receiver, sent on connect
+	LiveMessagePlayerJoined = "player_joined" // a player joined the session
+	LiveMessageQuestion     = "question"      // a question started counting down
+	LiveMessageAnswered     = "answered"      // the receiver's answer was recorded, its score is revealed with the question
+	LiveMessageAnswerCount  = "answer_count"  // how many players answered the question in progress
+	LiveMessageReveal       = "reveal"        // the question closed, with its answer and the standings
+	LiveMessageFinished     = "finished"      // the session finished, with the final standings
+	LiveMessageError        = "error"         // the receiver's last message was rejected
+)
+
+// Live messages sent by the clients
+const (
+	LiveMessageStart  = "start"  // host: start the first question
+	LiveMessageNext   = "next"   // host: close the question, or start the next one after the reveal
+	LiveMessageEnd    = "end"    // host: finish the session now
+	LiveMessageAnswer = "answer" // player: answer the question in progress
+)
+
+// LiveSession is the state of a live quiz game, kept in Redis and shared by every API instance serving the game
+type LiveSession struct {
+	SessionID         uuid.UUID   `json:"session_id"`
+	PIN               string      `json:"pin"`
+	QuizID            uuid.UUID   `json:"quiz_id"`
+	HostID            uuid.UUID   `json:"host_id"`
+	Status            string      `json:"status"`
+	QuestionIDs       []uuid.UUID `json:"question_ids"`
+	QuestionIndex     int         `json:"question_index"`    // question in progress or last revealed, -1 in the lobby
+	QuestionDuration  int         `json:"question_duration"` // in seconds
+	QuestionStartedAt *time.Time  `json:"question_started_at,omitempty"`
+	QuestionDeadline  *time.Time  `json:"question_deadline,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	StartedAt         *time.Time  `json:"started_at,omitempty"`
+	FinishedAt        *time.Time  `json:"finished_at,omitempty"`
+}
+
+// CreateLiveSessionRequest starts a live session of a quiz, the configured defaults apply to the unset fields
+type CreateLiveSessionRequest struct {
+	QuizID           uuid.UUID `json:"quiz_id" validate:"required"`
+	QuestionCount    int       `json:"question_count" validate:"omitempty,gte=1,lte=100"`
+	QuestionDuration int       `json:"question_duration" validate:"omitempty,gte=5,lte=300"` // in seconds
+}
+
+// LivePlayer is a player who joined a live session
+type LivePlayer struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Nickname string    `json:"nickname"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// LiveAnswer is a player's answer to a question of a live session, only the first answer counts
+type LiveAnswer struct {
+	UserID     uuid.UUID `json:"user_id"`
+	QuestionID uuid.UUID `json:"question_id"`
+	Answer     string    `json:"answer"`
+	IsCorrect  bool      `json:"is_correct"`
+	Points     int       `json:"points"`
+	Elapsed    int       `json:"elapsed"` // in milliseconds from the start of the question
+	AnsweredAt time.Time `json:"answered_at"`
+}
+
+// LiveStanding is a player's place in a live session
+type LiveStanding struct {
+	Rank           int       `json:"rank"`
+	UserID         uuid.UUID `json:"user_id"`
+	Nickname       string    `json:"nickname"`
+	Points         int       `json:"points"`
+	CorrectAnswers int       `json:"correct_answers"`
+}
+
+// LiveQuestion is a question pushed to the players, without its answer key
+type LiveQuestion struct {
+	Index     int       `json:"index"`
+	Total     int       `json:"total"`
+	Question  *Question `json:"question"`
+	Duration  int       `json:"duration"` // in seconds
+	StartedAt time.Time `json:"started_at"`
+	Deadline  time.Time `json:"deadline"`
+}
+
+// LiveAnswerRequest is a player's answer to the question in progress
+type LiveAnswerRequest struct {
+	QuestionIndex int    `json:"question_index"`
+	Answer        string `json:"answer"`
+}
+
+// LiveAnswerCount tells the host how many players answered the question in progress
+type LiveAnswerCount struct {
+	Index    int `json:"index"`
+	Answered int `json:"answered"`
+	Players  int `json:"players"`
+}
+
+// LiveReveal closes a question with its answer key and the standings after it
+type LiveReveal struct {
+	Index        int             `json:"index"`
+	Total        int             `json:"total"`
+	QuestionID   uuid.UUID       `json:"question_id"`
+	Answer       string          `json:"answer"`
+	Explanation  string          `json:"explanation"`
+	Answered     int             `json:"answered"`
+	CorrectCount int             `json:"correct_count"`
+	Standings    []*LiveStanding `json:"standings"`             // top StandingsSize players
+	You          *LiveStanding   `json:"you,omitempty"`         // set per player when the message is delivered
+	YourAnswer   *LiveAnswer     `json:"your_answer,omitempty"` // set per player when the message is delivered
+}
+
+// LiveFinal closes a session with its final standings
+type LiveFinal struct {
+	Standings []*LiveStanding `json:"standings"`     // top StandingsSize players
+	You       *LiveStanding   `json:"you,omitempty"` // set per player when the message is delivered
+}
+
+// LiveError rejects the last message of a client
+type LiveError struct {
+	Message string `json:"message"`
+}
+
+// LiveState is a session as seen by one of its clients, sent when they connect or reconnect
+type LiveState struct {
+	Session   *LiveSession    `json:"session"`
+	Players   int             `json:"players"`
+	Question  *LiveQuestion   `json:"question,omitempty"` // set while a question is counting down
+	Answered  bool            `json:"answered"`           // whether the receiver answered the question in progress
+	Standings []*LiveStanding `json:"standings,omitempty"`
+	You       *LiveStanding   `json:"you,omitempty"`
+}
+
+// LiveMessage is a message of the live session protocol, Data depends on the type
+type LiveMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// NewLiveMessage creates a message of the type with its data
+func NewLiveMessage(messageType string, data interface{}) (*LiveMessage, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &LiveMessage{Type: messageType, Data: raw}, nil
+}
+
+// LiveResult is a player's final result of a live session, saved with the quiz attempt it was graded as
+type LiveResult struct {
+	SessionID      uuid.UUID  `json:"-" db:"session_id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Nickname       string     `json:"nickname" db:"nickname"`
+	Rank           int        `json:"rank" db:"rank"`
+	Points         int        `json:"points" db:"points"`
+	CorrectAnswers int        `json:"correct_answers" db:"correct_answers"`
+	Answered       int        `json:"answered" db:"answered"`
+	AttemptID      *uuid.UUID `json:"attempt_id,omitempty" db:"attempt_id"` // nil when the player answered nothing
+}
+
+// LiveSessionSummary is a finished live session as saved in Postgres
+type LiveSessionSummary struct {
+	SessionID     uuid.UUID `json:"session_id" db:"session_id"`
+	QuizID        uuid.UUID `json:"quiz_id" db:"quiz_id"`
+	HostID        uuid.UUID `json:"host_id" db:"host_id"`
+	PIN           string    `json:"pin" db:"pin"`
+	QuestionCount int       `json:"question_count" db:"question_count"` // questions played
+	PlayerCount   int       `json:"player_count" db:"player_count"`
+	StartedAt     time.Time `json:"started_at" db:"started_at"`
+	FinishedAt    time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// LiveSessionResults are the final results of a live session, best first
+type LiveSessionResults struct {
+	Session *LiveSessionSummary `json:"session"`
+	Results []*LiveResult       `json:"results"`
+}
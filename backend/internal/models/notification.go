@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// Notification types
+const (
+	NotificationAchievementEarned = "achievement_earned"
+	NotificationLevelUp           = "level_up"
+	NotificationStreakLost        = "streak_lost"
+	NotificationChallengeReceived = "challenge_received"
+	NotificationChallengeEnded    = "challenge_ended"
+	NotificationStreakReminder    = "streak_reminder"
+)
+
+// Notification delivery channels besides the in-app inbox
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelPush  = "push"
+)
+
+// Notification delivery statuses
+const (
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"  // gave up after the last attempt
+	DeliveryStatusSkipped = "skipped" // the user's preferences changed before it was due
+)
+
+// Notification is an entry of a user's inbox
+type Notification struct {
+	NotificationID uuid.UUID      `json:"notification_id" db:"notification_id"`
+	UserID         uuid.UUID      `json:"user_id" db:"user_id"`
+	Type           string         `json:"type" db:"type"`
+	Title          string         `json:"title" db:"title"`
+	Body           string         `json:"body" db:"body"`
+	Data           types.JSONText `json:"data" db:"data"`       // ids of what the notification is about
+	EventID        *uuid.UUID     `json:"-" db:"event_id"`      // domain event the notification was created for
+	ReadAt         *time.Time     `json:"read_at" db:"read_at"` // nil while unread
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NotificationList is a page of a user's inbox
+type NotificationList struct {
+	TotalCount    int             `json:"total_count"`
+	UnreadCount   int             `json:"unread_count"`
+	Notifications []*Notification `json:"notifications"`
+}
+
+// NotificationPreferences controls how a user is notified outside the app, the inbox always gets every notification
+type NotificationPreferences struct {
+	UserID             uuid.UUID      `json:"-" db:"user_id"`
+	EmailEnabled       bool           `json:"email_enabled" db:"email_enabled"`
+	PushEnabled        bool           `json:"push_enabled" db:"push_enabled"`
+	MutedTypes         pq.StringArray `json:"muted_types" db:"muted_types"` // types never sent by email or push
+	QuietHoursEnabled  bool           `json:"quiet_hours_enabled" db:"quiet_hours_enabled"`
+	QuietHoursStart    int            `json:"quiet_hours_start" db:"quiet_hours_start"` // local hour email and push are held from
+	QuietHoursEnd      int            `json:"quiet_hours_end" db:"quiet_hours_end"`     // local hour they are sent again
+	StreakReminders    bool           `json:"streak_reminders_enabled" db:"streak_reminders_enabled"`
+	StreakReminderHour int            `json:"streak_reminder_hour" db:"streak_reminder_hour"` // local hour a streak at risk is reminded from
+	UpdatedAt          time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationPreferencesUpdate changes the preferences that are set
+type NotificationPreferencesUpdate struct {
+	EmailEnabled       *bool     `json:"email_enabled"`
+	PushEnabled        *bool     `json:"push_enabled"`
+	MutedTypes         *[]string `json:"muted_types" validate:"omitempty,dive,oneof=achievement_earned level_up streak_lost challenge_received challenge_ended streak_reminder"`
+	QuietHoursEnabled  *bool     `json:"quiet_hours_enabled"`
+	QuietHoursStart    *int      `json:"quiet_hours_start" validate:"omitempty,gte=0,lte=23"`
+	QuietHoursEnd      *int      `json:"quiet_hours_end" validate:"omitempty,gte=0,lte=23"`
+	StreakReminders    *bool     `json:"streak_reminders_enabled"`
+	StreakReminderHour *int      `json:"streak_reminder_hour" validate:"omitempty,gte=0,lte=23"`
+}
+
+// PushSubscription is a browser's web push subscription, as returned by PushManager.subscribe
+type PushSubscription struct {
+	SubscriptionID uuid.UUID     `json:"subscription_id" db:"subscription_id"`
+	UserID         uuid.UUID     `json:"-" db:"user_id"`
+	Endpoint       string        `json:"endpoint" db:"endpoint" validate:"required,url,lte=2048"`
+	PushKeys       `json:"keys"` // embedded so sqlx scans the key columns into it
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+}
+
+// PushKeys are the keys the push payload is encrypted with, base64url encoded
+type PushKeys struct {
+	P256dh string `json:"p256dh" db:"p256dh" validate:"required,lte=100"`
+	Auth   string `json:"auth" db:"auth" validate:"required,lte=50"`
+}
+
+// PushUnsubscribe is the subscription a browser unsubscribed from
+type PushUnsubscribe struct {
+	Endpoint string `json:"endpoint" validate:"required,url,lte=2048"`
+}
+
+// NotificationRecipient is who a delivery is sent to
+type NotificationRecipient struct {
+	UserID            uuid.UUID           `json:"user_id" db:"user_id"`
+	Email             string              `json:"email" db:"email"`
+	FirstName         string              `json:"first_name" db:"first_name"`
+	PushSubscriptions []*PushSubscription `json:"push_subscriptions" db:"-"`
+}
+
+// NotificationDelivery is a notification sent, or waiting to be sent, by email or push
+type NotificationDelivery struct {
+	DeliveryID     uuid.UUID  `json:"delivery_id" db:"delivery_id"`
+	NotificationID uuid.UUID  `json:"notification_id" db:"notification_id"`
+	Channel        string     `json:"channel" db:"channel"`
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	LastError      *string    `json:"last_error,omitempty" db:"last_error"`
+	SendAfter      time.Time  `json:"send_after" db:"send_after"`
+	SentAt         *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NotificationJobReport summarizes a single delivery run
+type NotificationJobReport struct {
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusDead      = "dead" // out of attempts, waits for a replay
+)
+
+// OutboxEvent is a domain event recorded in the transaction of the change it describes
+type OutboxEvent struct {
+	EventID       uuid.UUID         `json:"event_id" db:"event_id"`
+	EventType     string            `json:"event_type" db:"event_type"`
+	UserID        uuid.UUID         `json:"user_id" db:"user_id"`
+	Payload       types.JSONText    `json:"payload" db:"payload"`
+	Status        string            `json:"status" db:"status"`
+	Attempts      int               `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string           `json:"last_error,omitempty" db:"last_error"`
+	OccurredAt    time.Time         `json:"occurred_at" db:"occurred_at"`
+	DeliveredAt   *time.Time        `json:"delivered_at,omitempty" db:"delivered_at"`
+	Deliveries    []*OutboxDelivery `json:"deliveries,omitempty" db:"-"`
+}
+
+// OutboxDelivery records that a subscriber handled an event
+type OutboxDelivery struct {
+	EventID     uuid.UUID `json:"-" db:"event_id"`
+	Subscriber  string    `json:"subscriber" db:"subscriber"`
+	DeliveredAt time.Time `json:"delivered_at" db:"delivered_at"`
+}
+
+// OutboxEventList is a page of outbox events
+type OutboxEventList struct {
+	TotalCount int            `json:"total_count"`
+	Events     []*OutboxEvent `json:"events"`
+}
+
+// OutboxDispatchReport summarizes a dispatch run of the outbox worker
+type OutboxDispatchReport struct {
+	Claimed      int `json:"claimed"`
+	Delivered    int `json:"delivered"`
+	Retried      int `json:"retried"`
+	DeadLettered int `json:"dead_lettered"`
+}
+
+// OutboxReplayResult is the number of dead events put back in the queue
+type OutboxReplayResult struct {
+	Replayed int64 `json:"replayed"`
+}
@@ -15,31 +15,57 @@ type UserProgress struct {
 	ChaptersRead int       `json:"chapters_read" db:"chapters_read"`
 	QuizzesTaken int       `json:"quizzes_taken" db:"quizzes_taken"`
 	AvgScore     float64   `json:"avg_score" db:"avg_score"`
+	Ability      float64   `json:"ability" db:"ability"`                 // Elo ability estimate on the logit scale
+	AbilityCount int       `json:"ability_answers" db:"ability_answers"` // number of adaptive answers behind the estimate
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // LessonProgress tracks progress in individual lessons
 type LessonProgress struct {
-	LessonProgressID uuid.UUID  `json:"lesson_progress_id" db:"lesson_progress_id" validate:"omitempty"`
-	UserID           uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
-	LessonID         uuid.UUID  `json:"lesson_id" db:"lesson_id" validate:"required"`
-	Status           string     `json:"status" db:"status" validate:"required,oneof=not_started in_progress completed"`
-	CompletedAt      *time.Time `json:"completed_at,omitempty" db:"completed_at"`
-	TimeSpent        int        `json:"time_spent" db:"time_spent"` // in seconds
-	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	LessonProgressID uuid.UUID     `json:"lesson_progress_id" db:"lesson_progress_id" validate:"omitempty"`
+	UserID           uuid.UUID     `json:"user_id" db:"user_id" validate:"required"`
+	LessonID         uuid.UUID     `json:"lesson_id" db:"lesson_id" validate:"required"`
+	Status           string        `json:"status" db:"status" validate:"required,oneof=not_started in_progress completed"`
+	CompletedAt      *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+	TimeSpent        int           `json:"time_spent" db:"time_spent"` // in seconds
+	StartedAt        *time.Time    `json:"started_at,omitempty" db:"started_at"`
+	LastHeartbeatAt  *time.Time    `json:"last_heartbeat_at,omitempty" db:"last_heartbeat_at"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at" db:"updated_at"`
+	Streak           *StreakChange `json:"streak,omitempty" db:"-"` // set when the lesson gets completed
+}
+
+// LessonHeartbeat is the outcome of a reading time heartbeat
+type LessonHeartbeat struct {
+	Progress        *LessonProgress `json:"progress"`
+	ReportedSeconds int             `json:"reported_seconds"`
+	CreditedSeconds int             `json:"credited_seconds"` // capped to what the time since the last heartbeat allows
+}
+
+// ChapterProgress is a user's completion of the lessons in a chapter
+type ChapterProgress struct {
+	ChapterID            uuid.UUID         `json:"chapter_id"`
+	TotalLessons         int               `json:"total_lessons"`
+	CompletedLessons     int               `json:"completed_lessons"`
+	InProgressLessons    int               `json:"in_progress_lessons"`
+	TimeSpent            int               `json:"time_spent"` // in seconds
+	CompletionPercentage float64           `json:"completion_percentage"`
+	Lessons              []*LessonProgress `json:"lessons"`
 }
 
 // Achievement represents user achievements and badges
 type Achievement struct {
-	AchievementID uuid.UUID `json:"achievement_id" db:"achievement_id" validate:"omitempty"`
-	Title         string    `json:"title" db:"title" validate:"required,lte=100"`
-	Description   string    `json:"description" db:"description" validate:"required,lte=500"`
-	Type          string    `json:"type" db:"type" validate:"required,oneof=streak quiz_score subject_mastery custom"`
-	RequiredValue int       `json:"required_value" db:"required_value" validate:"required,gt=0"`
-	IconURL       string    `json:"icon_url" db:"icon_url" validate:"required,url"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	AchievementID uuid.UUID        `json:"achievement_id" db:"achievement_id" validate:"omitempty"`
+	Title         string           `json:"title" db:"title" validate:"required,lte=100"`
+	Description   string           `json:"description" db:"description" validate:"required,lte=500"`
+	Type          string           `json:"type" db:"type" validate:"required,lte=30"` // free-form category, awarding is driven by Rule
+	RequiredValue int              `json:"required_value" db:"required_value" validate:"gte=0"`
+	IconURL       string           `json:"icon_url" db:"icon_url" validate:"required,url"`
+	Rule          *AchievementRule `json:"rule" db:"rule"` // achievements without a rule are only awarded manually
+	IsEnabled     bool             `json:"is_enabled" db:"is_enabled"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at" db:"updated_at"`
 }
 
 // UserAchievement tracks achievements earned by users
@@ -51,15 +77,48 @@ type UserAchievement struct {
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 }
 
+// AchievementProgress is a user's cached progress toward a locked achievement.
+// For a single condition rule the values are the metric and its threshold,
+// for composite rules they count the conditions met
+type AchievementProgress struct {
+	UserID        uuid.UUID `json:"-" db:"user_id"`
+	AchievementID uuid.UUID `json:"-" db:"achievement_id"`
+	CurrentValue  float64   `json:"current_value" db:"current_value"`
+	RequiredValue float64   `json:"required_value" db:"required_value"`
+	Percentage    int       `json:"percentage" db:"percentage"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AchievementRarity is how many users earned an achievement
+type AchievementRarity struct {
+	AchievementID uuid.UUID `json:"achievement_id" db:"achievement_id"`
+	EarnedBy      int       `json:"earned_by" db:"earned_by"`
+	Rarity        float64   `json:"rarity" db:"rarity"` // percentage of all users
+}
+
+// UserAchievementStatus is an earned or locked achievement as seen by a user
+type UserAchievementStatus struct {
+	*Achievement
+	Earned   bool                 `json:"earned"`
+	EarnedAt *time.Time           `json:"earned_at,omitempty"`
+	Progress *AchievementProgress `json:"progress"` // nil for achievements that are only awarded manually
+	EarnedBy int                  `json:"earned_by"`
+	Rarity   float64              `json:"rarity"`
+}
+
 // DailyStreak tracks user's daily learning streaks
 type DailyStreak struct {
-	StreakID      uuid.UUID `json:"streak_id" db:"streak_id" validate:"omitempty"`
-	UserID        uuid.UUID `json:"user_id" db:"user_id" validate:"required"`
-	CurrentStreak int       `json:"current_streak" db:"current_streak"`
-	MaxStreak     int       `json:"max_streak" db:"max_streak"`
-	LastActivity  time.Time `json:"last_activity" db:"last_activity"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	StreakID         uuid.UUID  `json:"streak_id" db:"streak_id" validate:"omitempty"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
+	CurrentStreak    int        `json:"current_streak" db:"current_streak"`
+	MaxStreak        int        `json:"max_streak" db:"max_streak"`
+	LastActivity     time.Time  `json:"last_activity" db:"last_activity"`
+	LastActivityDate *time.Time `json:"last_activity_date" db:"last_activity_date"` // local day in the user's timezone, covered by activity or a freeze
+	Freezes          int        `json:"freezes" db:"freezes"`
+	BrokenStreak     int        `json:"broken_streak" db:"broken_streak"`
+	BrokenOn         *time.Time `json:"broken_on" db:"broken_on"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // ProgressList represents a paginated list of user progress records
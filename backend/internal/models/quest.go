@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Quest periods, days start at midnight in the user's timezone and weeks on Monday
+const (
+	QuestPeriodDaily  = "daily"
+	QuestPeriodWeekly = "weekly"
+)
+
+// Quest metrics, the activity a quest counts
+const (
+	QuestMetricLessonsCompleted = "lessons_completed"
+	QuestMetricQuizzesCompleted = "quizzes_completed"
+)
+
+// Quest rewards
+const (
+	QuestRewardXP     = "xp"
+	QuestRewardFreeze = "freeze"
+)
+
+// Quest statuses
+const (
+	QuestStatusActive    = "active"
+	QuestStatusCompleted = "completed"
+	QuestStatusExpired   = "expired"
+)
+
+// QuestTemplate is the blueprint users' quests are generated from
+type QuestTemplate struct {
+	TemplateID   uuid.UUID `json:"template_id" db:"template_id" validate:"omitempty"`
+	Title        string    `json:"title" db:"title" validate:"required,lte=100"`
+	Period       string    `json:"period" db:"period" validate:"required,oneof=daily weekly"`
+	Metric       string    `json:"metric" db:"metric" validate:"required,oneof=lessons_completed quizzes_completed"`
+	Target       int       `json:"target" db:"target" validate:"required,gte=1"`
+	Subject      *string   `json:"subject,omitempty" db:"subject" validate:"omitempty,lte=50"`            // any subject when not set
+	MinScore     *int      `json:"min_score,omitempty" db:"min_score" validate:"omitempty,gte=0,lte=100"` // quizzes only
+	RewardType   string    `json:"reward_type" db:"reward_type" validate:"required,oneof=xp freeze"`
+	RewardAmount int       `json:"reward_amount" db:"reward_amount" validate:"required,gte=1"` // XP or streak freezes
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Quest is a user's quest of a period, with the details of its template
+type Quest struct {
+	QuestID       uuid.UUID  `json:"quest_id" db:"quest_id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	TemplateID    uuid.UUID  `json:"template_id" db:"template_id"`
+	PeriodStart   time.Time  `json:"period_start" db:"period_start"` // local day the period starts on
+	StartsAt      time.Time  `json:"starts_at" db:"starts_at"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	Progress      int        `json:"progress" db:"progress"`
+	Target        int        `json:"target" db:"target"`
+	Status        string     `json:"status" db:"status"`
+	RewardSubject *string    `json:"-" db:"reward_subject"`
+	RewardGrade   *int       `json:"-" db:"reward_grade"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	RewardedAt    *time.Time `json:"rewarded_at,omitempty" db:"rewarded_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+
+	Title        string  `json:"title" db:"title"`
+	Period       string  `json:"period" db:"period"`
+	Metric       string  `json:"metric" db:"metric"`
+	Subject      *string `json:"subject,omitempty" db:"subject"`
+	MinScore     *int    `json:"min_score,omitempty" db:"min_score"`
+	RewardType   string  `json:"reward_type" db:"reward_type"`
+	RewardAmount int     `json:"reward_amount" db:"reward_amount"`
+}
+
+// QuestActivity is a lesson completion or quiz submission counted towards the user's quests
+type QuestActivity struct {
+	EventID  uuid.UUID `json:"event_id"`
+	Metric   string    `json:"metric"`
+	SourceID uuid.UUID `json:"source_id"` // lesson or quiz
+	Score    int       `json:"score"`     // quizzes only
+	Subject  string    `json:"subject"`
+	Grade    int       `json:"grade"`
+	At       time.Time `json:"at"`
+}
+
+// QuestBoard is the quests of a user's current day and week
+type QuestBoard struct {
+	Daily  []*Quest `json:"daily"`
+	Weekly []*Quest `json:"weekly"`
+}
+
+// QuestJobReport summarizes a single quest job run
+type QuestJobReport struct {
+	Expired  int `json:"expired"`
+	Rewarded int `json:"rewarded"` // completed quests whose reward failed before and was paid now
+}
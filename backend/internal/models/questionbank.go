@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuestionBankFilter narrows a question bank search, every set field must match
+type QuestionBankFilter struct {
+	Subject      string   `json:"subject" validate:"omitempty,lte=50"`
+	Grade        int      `json:"grade" validate:"omitempty,gte=1,lte=12"`
+	Difficulty   string   `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	QuestionType string   `json:"question_type" validate:"omitempty,oneof=multiple_choice true_false open_ended"`
+	Tags         []string `json:"tags"`  // items must carry all of them
+	Query        string   `json:"query"` // case-insensitive match on the question text
+	Limit        int      `json:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset       int      `json:"offset" validate:"omitempty,gte=0"`
+}
+
+// QuestionBankList is a page of question bank items
+type QuestionBankList struct {
+	TotalCount int         `json:"total_count"`
+	Questions  []*Question `json:"questions"`
+}
+
+// QuizQuestion links a bank item into a quiz at a position
+type QuizQuestion struct {
+	QuizID     uuid.UUID `json:"quiz_id" db:"quiz_id" validate:"required"`
+	QuestionID uuid.UUID `json:"question_id" db:"question_id" validate:"required"`
+	Position   int       `json:"position" db:"position" validate:"omitempty,gte=1"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -17,30 +17,36 @@ type Quiz struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// Question represents a single quiz question
+// Question represents a question bank item, quizzes reference it through quiz_questions
 type Question struct {
-	QuestionID   uuid.UUID `json:"question_id" db:"question_id" validate:"omitempty"`
-	QuizID       uuid.UUID `json:"quiz_id" db:"quiz_id" validate:"required"`
-	Text         string    `json:"text" db:"text" validate:"required"`
-	QuestionType string    `json:"question_type" db:"question_type" validate:"required,oneof=multiple_choice true_false open_ended"`
-	Options      []string  `json:"options" db:"options" validate:"required_if=QuestionType multiple_choice"`
-	Answer       string    `json:"answer" db:"answer" validate:"required"`
-	Explanation  string    `json:"explanation" db:"explanation" validate:"required"`
-	Points       int       `json:"points" db:"points" validate:"required,gte=1"`
-	Difficulty   string    `json:"difficulty" db:"difficulty" validate:"required,oneof=easy medium hard"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	QuestionID   uuid.UUID  `json:"question_id" db:"question_id" validate:"omitempty"`
+	QuizID       uuid.UUID  `json:"quiz_id" db:"quiz_id" validate:"omitempty"` // quiz the question is served in, empty outside a quiz
+	Text         string     `json:"text" db:"text" validate:"required"`
+	QuestionType string     `json:"question_type" db:"question_type" validate:"required,oneof=multiple_choice true_false open_ended"`
+	Options      []string   `json:"options" db:"options" validate:"required_if=QuestionType multiple_choice"`
+	Answer       string     `json:"answer" db:"answer" validate:"required"`
+	Explanation  string     `json:"explanation" db:"explanation" validate:"required"`
+	Points       int        `json:"points" db:"points" validate:"required,gte=1"`
+	Difficulty   string     `json:"difficulty" db:"difficulty" validate:"required,oneof=easy medium hard"`
+	Subject      string     `json:"subject" db:"subject" validate:"omitempty,lte=50"`
+	Grade        int        `json:"grade" db:"grade" validate:"omitempty,gte=1,lte=12"`
+	Tags         []string   `json:"tags" db:"tags" validate:"omitempty,dive,required,lte=50"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // UserQuizAttempt tracks a user's attempt at a quiz
 type UserQuizAttempt struct {
-	AttemptID   uuid.UUID `json:"attempt_id" db:"attempt_id" validate:"omitempty"`
-	UserID      uuid.UUID `json:"user_id" db:"user_id" validate:"required"`
-	QuizID      uuid.UUID `json:"quiz_id" db:"quiz_id" validate:"required"`
-	Score       int       `json:"score" db:"score" validate:"required,gte=0"`
-	TimeSpent   int       `json:"time_spent" db:"time_spent" validate:"required,gte=0"` // in seconds
-	CompletedAt time.Time `json:"completed_at" db:"completed_at"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	AttemptID   uuid.UUID     `json:"attempt_id" db:"attempt_id" validate:"omitempty"`
+	UserID      uuid.UUID     `json:"user_id" db:"user_id" validate:"required"`
+	QuizID      uuid.UUID     `json:"quiz_id" db:"quiz_id" validate:"required"`
+	Score       int           `json:"score" db:"score" validate:"required,gte=0"`
+	TimeSpent   int           `json:"time_spent" db:"time_spent" validate:"required,gte=0"` // in seconds
+	CompletedAt time.Time     `json:"completed_at" db:"completed_at"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	XPAward     *XPAward      `json:"xp_award,omitempty" db:"-"` // set when the attempt is submitted
+	Streak      *StreakChange `json:"streak,omitempty" db:"-"`   // set when the attempt is submitted
 }
 
 // UserQuestionResponse tracks a user's response to a specific question
@@ -50,6 +56,7 @@ type UserQuestionResponse struct {
 	QuestionID uuid.UUID `json:"question_id" db:"question_id" validate:"required"`
 	UserAnswer string    `json:"user_answer" db:"user_answer" validate:"required"`
 	IsCorrect  bool      `json:"is_correct" db:"is_correct"`
+	TimeSpent  *int      `json:"time_spent,omitempty" db:"time_spent" validate:"omitempty,gte=0"` // in seconds
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -90,3 +97,82 @@ type QuizWithQuestions struct {
 	Quiz
 	Questions []*Question `json:"questions"`
 }
+
+// AdaptiveQuizSession tracks a quiz served one question at a time with difficulty picked from the user's ability
+type AdaptiveQuizSession struct {
+	SessionID         uuid.UUID  `json:"session_id" db:"session_id" validate:"omitempty"`
+	UserID            uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
+	QuizID            uuid.UUID  `json:"quiz_id" db:"quiz_id" validate:"required"`
+	Subject           string     `json:"subject" db:"subject"`
+	Grade             int        `json:"grade" db:"grade"`
+	Status            string     `json:"status" db:"status" validate:"required,oneof=in_progress completed"`
+	MaxQuestions      int        `json:"max_questions" db:"max_questions"`
+	Answered          int        `json:"answered" db:"answered"`
+	Correct           int        `json:"correct" db:"correct"`
+	PointsEarned      int        `json:"points_earned" db:"points_earned"`
+	PointsPossible    int        `json:"points_possible" db:"points_possible"`
+	Ability           float64    `json:"ability" db:"ability"`
+	CurrentQuestionID *uuid.UUID `json:"current_question_id,omitempty" db:"current_question_id"`
+	AttemptID         *uuid.UUID `json:"attempt_id,omitempty" db:"attempt_id"`
+	StartedAt         time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AdaptiveQuizAnswer records a single answer given during an adaptive session
+type AdaptiveQuizAnswer struct {
+	AnswerID      uuid.UUID `json:"answer_id" db:"answer_id" validate:"omitempty"`
+	SessionID     uuid.UUID `json:"session_id" db:"session_id" validate:"required"`
+	QuestionID    uuid.UUID `json:"question_id" db:"question_id" validate:"required"`
+	UserAnswer    string    `json:"user_answer" db:"user_answer" validate:"required"`
+	IsCorrect     bool      `json:"is_correct" db:"is_correct"`
+	AbilityBefore float64   `json:"ability_before" db:"ability_before"`
+	AbilityAfter  float64   `json:"ability_after" db:"ability_after"`
+	AnsweredAt    time.Time `json:"answered_at" db:"answered_at"`
+}
+
+// AdaptiveQuizStep is returned after starting an adaptive session or answering one of its questions
+type AdaptiveQuizStep struct {
+	Session      *AdaptiveQuizSession `json:"session"`
+	LastAnswer   *AdaptiveQuizAnswer  `json:"last_answer,omitempty"`
+	Explanation  string               `json:"explanation,omitempty"`
+	NextQuestion *Question            `json:"next_question,omitempty"`
+	Attempt      *UserQuizAttempt     `json:"attempt,omitempty"`
+}
+
+// QuizImportIssue is a problem found in one question of an imported file
+type QuizImportIssue struct {
+	Item     int    `json:"item"`               // 1-based question number in the file, 0 for the whole file
+	Position int    `json:"position,omitempty"` // line in GIFT and CSV files, item number in QTI packages
+	Severity string `json:"severity"`           // error or warning
+	Message  string `json:"message"`
+}
+
+// QuizImportReport summarizes the validation of an imported file
+type QuizImportReport struct {
+	Format   string             `json:"format"`
+	DryRun   bool               `json:"dry_run"`
+	Total    int                `json:"total"`
+	Imported int                `json:"imported"`
+	Skipped  int                `json:"skipped"`
+	Issues   []*QuizImportIssue `json:"issues"`
+}
+
+// QuizImportRequest carries the quiz fields a file format may not provide
+type QuizImportRequest struct {
+	LessonID    uuid.UUID `json:"lesson_id" validate:"required"`
+	Format      string    `json:"format" validate:"required,oneof=gift qti csv"`
+	Title       string    `json:"title" validate:"omitempty,lte=100"`
+	Description string    `json:"description" validate:"omitempty,lte=500"`
+	TimeLimit   *int      `json:"time_limit" validate:"omitempty,gte=0"`
+	DryRun      bool      `json:"dry_run"`
+	// Import the valid questions even when others have errors
+	SkipInvalid bool `json:"skip_invalid"`
+}
+
+// QuizImportResult is the created quiz, or the preview of it on a dry run, with its report
+type QuizImportResult struct {
+	Quiz      *Quiz             `json:"quiz,omitempty"`
+	Questions []*Question       `json:"questions"`
+	Report    *QuizImportReport `json:"report"`
+}
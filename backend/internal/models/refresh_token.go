@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a stored refresh token, only the hash of the token is kept
+type RefreshToken struct {
+	TokenID         uuid.UUID  `json:"token_id" db:"token_id"`
+	FamilyID        uuid.UUID  `json:"family_id" db:"family_id"` // the login session, shared by every rotation
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash       string     `json:"-" db:"token_hash"`
+	AccessTokenID   uuid.UUID  `json:"access_token_id" db:"access_token_id"` // jti of the access token issued with it
+	AccessExpiresAt time.Time  `json:"access_expires_at" db:"access_expires_at"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt          *time.Time `json:"used_at,omitempty" db:"used_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RefreshTokenRequest exchanges a refresh token for a new token pair
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreakAtRisk is a user with a running streak who has not been active on their local day yet
+type StreakAtRisk struct {
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	CurrentStreak int       `json:"current_streak" db:"current_streak"`
+	LocalDate     time.Time `json:"local_date" db:"local_date"` // the user's local day, at midnight UTC like the postgres DATE values
+}
+
+// ReminderJobReport summarizes a single streak reminder run
+type ReminderJobReport struct {
+	AtRisk      int `json:"at_risk"`
+	Sent        int `json:"sent"`
+	RateLimited int `json:"rate_limited"` // left for the next run by the global rate limit
+}
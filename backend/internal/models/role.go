@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Roles seeded by the migrations
+const (
+	RoleAdmin   = "admin"
+	RoleTeacher = "teacher"
+	RoleStudent = "student"
+	RoleParent  = "parent"
+)
+
+// Permissions seeded by the migrations, granted to the users through their roles
+const (
+	PermissionRolesManage        = "roles:manage"
+	PermissionAchievementsManage = "achievements:manage"
+	PermissionChaptersManage     = "chapters:manage"
+	PermissionClassesManage      = "classes:manage"
+	PermissionAnalyticsRead      = "analytics:read"
+	PermissionQuestsManage       = "quests:manage"
+	PermissionEventsManage       = "events:manage"
+	PermissionJobsRun            = "jobs:run"
+	PermissionUsersRead          = "users:read"
+	PermissionUsersManage        = "users:manage"
+	PermissionAuditRead          = "audit:read"
+	PermissionQuestionsManage    = "questions:manage"
+)
+
+// Role with the permissions it grants
+type Role struct {
+	RoleName    string         `json:"role_name" db:"role_name"`
+	Description string         `json:"description" db:"description"`
+	Permissions pq.StringArray `json:"permissions" db:"permissions"`
+}
+
+// UserRole is a role granted to a user
+type UserRole struct {
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	RoleName  string     `json:"role_name" db:"role_name"`
+	GrantedBy *uuid.UUID `json:"granted_by,omitempty" db:"granted_by"` // nil when granted by the platform
+	GrantedAt time.Time  `json:"granted_at" db:"granted_at"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow statuses, a follow of a private account is pending until the followee accepts it
+const (
+	FollowStatusPending  = "pending"
+	FollowStatusAccepted = "accepted"
+)
+
+// Activity types of the feed
+const (
+	ActivityAchievementEarned = "achievement_earned"
+	ActivityChapterCompleted  = "chapter_completed"
+)
+
+// PrivacySettings controls who may follow a user and what their followers see
+type PrivacySettings struct {
+	UserID             uuid.UUID `json:"-" db:"user_id"`
+	PrivateAccount     bool      `json:"private_account" db:"private_account"`           // follows need to be accepted
+	ShareActivity      bool      `json:"share_activity" db:"share_activity"`             // followers see the activities in their feed
+	ShowOnLeaderboards bool      `json:"show_on_leaderboards" db:"show_on_leaderboards"` // followers see the user on their friends leaderboard
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PrivacySettingsUpdate changes the settings that are set
+type PrivacySettingsUpdate struct {
+	PrivateAccount     *bool `json:"private_account"`
+	ShareActivity      *bool `json:"share_activity"`
+	ShowOnLeaderboards *bool `json:"show_on_leaderboards"`
+}
+
+// Follow is a follow of one user by another, with the other user's profile
+type Follow struct {
+	FollowerID uuid.UUID  `json:"follower_id" db:"follower_id"`
+	FolloweeID uuid.UUID  `json:"followee_id" db:"followee_id"`
+	Status     string     `json:"status" db:"status"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+
+	// The follower in a list of followers or requests, the followee in a list of follows
+	FirstName string  `json:"first_name" db:"first_name"`
+	Avatar    *string `json:"avatar,omitempty" db:"avatar"`
+}
+
+// FollowList represents a page of follows
+type FollowList struct {
+	TotalCount int       `json:"total_count"`
+	Follows    []*Follow `json:"follows"`
+}
+
+// Block is a user blocked by the current user
+type Block struct {
+	BlockedID uuid.UUID `json:"blocked_id" db:"blocked_id"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	Avatar    *string   `json:"avatar,omitempty" db:"avatar"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Activity is an entry of the feed, an achievement earned or a chapter completed by a followed user
+type Activity struct {
+	ActivityID uuid.UUID `json:"activity_id" db:"activity_id"`
+	EventID    uuid.UUID `json:"-" db:"event_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	FirstName  string    `json:"first_name" db:"first_name"`
+	Avatar     *string   `json:"avatar,omitempty" db:"avatar"`
+	Type       string    `json:"type" db:"type"`
+	SubjectID  uuid.UUID `json:"subject_id" db:"subject_id"` // the achievement or chapter
+	Title      string    `json:"title" db:"title"`           // its title
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// ActivityFeed represents a page of the activity feed, newest first
+type ActivityFeed struct {
+	TotalCount int         `json:"total_count"`
+	Activities []*Activity `json:"activities"`
+}
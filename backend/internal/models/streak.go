@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreakDay is a local calendar day on which a user kept their streak
+type StreakDay struct {
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	ActivityDate time.Time `json:"activity_date" db:"activity_date"`
+	Activities   int       `json:"activities" db:"activities"`
+	Frozen       bool      `json:"frozen" db:"frozen"` // covered by a streak freeze instead of activity
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StreakChange is the outcome of applying activity, a repair or the lapse check to a streak
+type StreakChange struct {
+	Streak       *DailyStreak `json:"streak"`
+	Extended     bool         `json:"extended"` // the current streak grew by a day
+	FreezesUsed  int          `json:"freezes_used"`
+	FreezeEarned bool         `json:"freeze_earned"`
+	Broken       bool         `json:"broken"`
+	Repaired     bool         `json:"repaired"`
+	DailyGoal    *DailyGoal   `json:"daily_goal,omitempty"`
+	ActivityDay  *time.Time   `json:"-"` // local day the activity is counted on
+	FrozenDays   []time.Time  `json:"-"` // local days covered by freezes
+}
+
+// StreakStatus is a user's streak as of their current local day
+type StreakStatus struct {
+	Streak          *DailyStreak `json:"streak"`
+	Timezone        string       `json:"timezone"`
+	Today           time.Time    `json:"today"`
+	ActiveToday     bool         `json:"active_today"`
+	AtRisk          bool         `json:"at_risk"` // the streak lapses at the end of today without activity or a freeze
+	RepairAvailable bool         `json:"repair_available"`
+	RepairDeadline  *time.Time   `json:"repair_deadline,omitempty"` // last local day a repair is accepted
+	Days            []*StreakDay `json:"days"`                      // recent streak calendar, newest first
+	DailyGoal       *DailyGoal   `json:"daily_goal"`
+}
+
+// DailyGoal is a user's personal daily XP goal and the XP they earned on a local day.
+// Without a goal any qualifying activity counts the day, with one the day counts once the goal is met.
+type DailyGoal struct {
+	DailyXPGoal int  `json:"daily_xp_goal"` // 0 when no goal is set
+	XPEarned    int  `json:"xp_earned"`
+	Met         bool `json:"met"`
+}
+
+// DailyGoalRequest sets the daily XP goal, 0 removes it
+type DailyGoalRequest struct {
+	DailyXPGoal int `json:"daily_xp_goal" validate:"gte=0,lte=1000"`
+}
+
+// StreakJobReport summarizes a single lapsed streaks run
+type StreakJobReport struct {
+	Checked int `json:"checked"`
+	Broken  int `json:"broken"`
+	Frozen  int `json:"frozen"` // streaks kept alive by freezes
+}
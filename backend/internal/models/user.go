@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -10,19 +11,27 @@ import (
 
 // User model for the learning platform
 type User struct {
-	UserID     uuid.UUID `json:"user_id" db:"user_id" validate:"omitempty"`
-	FirstName  string    `json:"first_name" db:"first_name" validate:"required,lte=30"`
-	LastName   string    `json:"last_name" db:"last_name" validate:"required,lte=30"`
-	Email      string    `json:"email" db:"email" validate:"required,lte=60,email"`
-	Password   string    `json:"password,omitempty" db:"password" validate:"required,gte=6"`
-	Grade      int       `json:"grade" db:"grade" validate:"required,gte=1,lte=12"`
-	Avatar     *string   `json:"avatar,omitempty" db:"avatar" validate:"omitempty,lte=512,url"`
-	XP         int       `json:"xp" db:"xp"`
-	Streak     int       `json:"streak" db:"streak"`
-	LastActive time.Time `json:"last_active" db:"last_active"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
-	LoginDate  time.Time `json:"login_date" db:"login_date"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id" validate:"omitempty"`
+	FirstName   string    `json:"first_name" db:"first_name" validate:"required,lte=30"`
+	LastName    string    `json:"last_name" db:"last_name" validate:"required,lte=30"`
+	Email       string    `json:"email" db:"email" validate:"required,lte=60,email"`
+	Password    string    `json:"password,omitempty" db:"password" validate:"required,gte=6"`
+	Grade       int       `json:"grade" db:"grade" validate:"required,gte=1,lte=12"`
+	Avatar      *string   `json:"avatar,omitempty" db:"avatar" validate:"omitempty,lte=512,url"`
+	XP          int       `json:"xp" db:"xp"`
+	Streak      int       `json:"streak" db:"streak"`
+	Timezone    string    `json:"timezone" db:"timezone" validate:"omitempty,lte=64"`
+	Level       int       `json:"level" db:"level"`
+	DailyXPGoal int       `json:"daily_xp_goal" db:"daily_xp_goal"`
+	LastActive  time.Time `json:"last_active" db:"last_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	LoginDate   time.Time `json:"login_date" db:"login_date"`
+	// Derived from XP on read
+	LevelProgress *LevelProgress `json:"level_progress,omitempty" db:"-"`
+	// Granted through user_roles, carried in the JWT claims
+	Roles       []string `json:"roles,omitempty" db:"-"`
+	Permissions []string `json:"permissions,omitempty" db:"-"`
 }
 
 // Hash user password with bcrypt
@@ -43,6 +52,16 @@ func (u *User) ComparePasswords(password string) error {
 	return nil
 }
 
+// Check one of the user's roles grants the permission
+func (u *User) HasPermission(permission string) bool {
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 // Sanitize user password
 func (u *User) SanitizePassword() {
 	u.Password = ""
@@ -60,12 +79,28 @@ func (u *User) PrepareCreate() error {
 	u.XP = 0
 	u.Streak = 0
 	u.LastActive = time.Now()
-	return nil
+	return u.prepareTimezone()
 }
 
 // Prepare user for update
 func (u *User) PrepareUpdate() error {
 	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+	return u.prepareTimezone()
+}
+
+// Check the user timezone is a known IANA name, empty keeps the current one
+func (u *User) prepareTimezone() error {
+	u.Timezone = strings.TrimSpace(u.Timezone)
+	if u.Timezone == "" {
+		return nil
+	}
+	// "Local" is the server zone and unknown to postgres
+	if u.Timezone == "Local" {
+		return fmt.Errorf("invalid timezone %q", u.Timezone)
+	}
+	if _, err := time.LoadLocation(u.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", u.Timezone, err)
+	}
 	return nil
 }
 
@@ -81,6 +116,8 @@ type UsersList struct {
 
 // UserWithToken represents a user with their authentication token
 type UserWithToken struct {
-	User  *User  `json:"user"`
-	Token string `json:"token"`
+	User         *User     `json:"user"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`    // expiry of the access token
+	RefreshToken string    `json:"refresh_token"` // single use, exchanged for a new pair by /auth/refresh
 }
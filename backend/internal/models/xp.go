@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// XP ledger reasons
+const (
+	XPReasonCorrectAnswers  = "correct_answers"
+	XPReasonQuizCompleted   = "quiz_completed"
+	XPReasonFirstTry        = "first_try"
+	XPReasonPerfectScore    = "perfect_score"
+	XPReasonLessonCompleted = "lesson_completed"
+	XPReasonChallengeWon    = "challenge_won"
+	XPReasonQuestReward     = "quest_reward"
+)
+
+// XPTransaction is a single entry of a user's XP ledger.
+// A (user, reason, source) triple is only ever awarded once.
+type XPTransaction struct {
+	TransactionID uuid.UUID `json:"transaction_id" db:"transaction_id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Amount        int       `json:"amount" db:"amount"`
+	Reason        string    `json:"reason" db:"reason"`
+	SourceID      uuid.UUID `json:"source_id" db:"source_id"` // attempt, quiz, lesson, challenge or quest the XP was earned on
+	Subject       string    `json:"subject" db:"subject"`
+	Grade         int       `json:"grade" db:"grade"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// XPEvent is a learning event to apply to the ledger and the subject progress in one transaction
+type XPEvent struct {
+	UserID       uuid.UUID        `json:"user_id"`
+	Subject      string           `json:"subject"`
+	Grade        int              `json:"grade"`
+	Transactions []*XPTransaction `json:"transactions"`
+}
+
+// XPAward is the outcome of applying an event
+type XPAward struct {
+	XPGained     int              `json:"xp_gained"`
+	TotalXP      int              `json:"total_xp"`
+	Transactions []*XPTransaction `json:"transactions"` // only the entries that were new
+	Progress     *UserProgress    `json:"progress"`
+	Level        *LevelProgress   `json:"level,omitempty"`
+	LevelUp      *LevelUpEvent    `json:"level_up,omitempty"` // set when the award crossed a level threshold
+}
+
+// XPTransactionList is a page of a user's XP ledger
+type XPTransactionList struct {
+	TotalCount   int              `json:"total_count"`
+	Transactions []*XPTransaction `json:"transactions"`
+}
+
+// LevelProgress is where a user stands on the level curve
+type LevelProgress struct {
+	Level         int `json:"level"`
+	XP            int `json:"xp"`
+	LevelXP       int `json:"level_xp"`      // total XP the current level starts at
+	NextLevelXP   int `json:"next_level_xp"` // total XP the next level starts at
+	XPIntoLevel   int `json:"xp_into_level"`
+	XPToNextLevel int `json:"xp_to_next_level"`
+}
+
+// LevelUpEvent is recorded when a user's XP crosses one or more level thresholds
+type LevelUpEvent struct {
+	EventID       uuid.UUID `json:"event_id" db:"event_id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	FromLevel     int       `json:"from_level" db:"from_level"`
+	ToLevel       int       `json:"to_level" db:"to_level"`
+	XP            int       `json:"xp" db:"xp"`                         // total XP when the level was reached
+	RewardFreezes int       `json:"reward_freezes" db:"reward_freezes"` // streak freezes granted
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// LevelUpEventList is a page of a user's level-ups
+type LevelUpEventList struct {
+	TotalCount int             `json:"total_count"`
+	Events     []*LevelUpEvent `json:"events"`
+}
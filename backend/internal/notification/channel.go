@@ -0,0 +1,16 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Channel sends notifications outside the app, like email or web push
+type Channel interface {
+	// Name is the channel of the deliveries the channel sends
+	Name() string
+	// Enabled reports whether the channel is configured, deliveries are only scheduled for enabled channels
+	Enabled() bool
+	Send(ctx context.Context, recipient *models.NotificationRecipient, notification *models.Notification) error
+}
@@ -0,0 +1,118 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultSMTPPort = 25
+
+type emailChannel struct {
+	cfg    *config.Config
+	logger logger.Logger
+}
+
+// NewEmailChannel creates the channel sending notifications by email through the configured SMTP server
+func NewEmailChannel(cfg *config.Config, logger logger.Logger) notification.Channel {
+	return &emailChannel{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (c *emailChannel) Name() string {
+	return models.NotificationChannelEmail
+}
+
+func (c *emailChannel) Enabled() bool {
+	return c.cfg.Notifications.SMTPHost != ""
+}
+
+func (c *emailChannel) Send(ctx context.Context, recipient *models.NotificationRecipient, n *models.Notification) error {
+	host := c.cfg.Notifications.SMTPHost
+	port := c.cfg.Notifications.SMTPPort
+	if port <= 0 {
+		port = defaultSMTPPort
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return errors.Wrap(err, "emailChannel.Send.DialContext")
+	}
+	// The SMTP client has no context, the whole conversation is bounded by the context deadline instead
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return errors.Wrap(err, "emailChannel.Send.SetDeadline")
+		}
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "emailChannel.Send.NewClient")
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return errors.Wrap(err, "emailChannel.Send.StartTLS")
+		}
+	}
+	if c.cfg.Notifications.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", c.cfg.Notifications.SMTPUsername, c.cfg.Notifications.SMTPPassword, host)
+		if err := client.Auth(auth); err != nil {
+			return errors.Wrap(err, "emailChannel.Send.Auth")
+		}
+	}
+
+	if err := client.Mail(c.cfg.Notifications.EmailFrom); err != nil {
+		return errors.Wrap(err, "emailChannel.Send.Mail")
+	}
+	if err := client.Rcpt(recipient.Email); err != nil {
+		return errors.Wrap(err, "emailChannel.Send.Rcpt")
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "emailChannel.Send.Data")
+	}
+	if _, err := w.Write(c.message(recipient, n)); err != nil {
+		return errors.Wrap(err, "emailChannel.Send.Write")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "emailChannel.Send.Close")
+	}
+
+	return client.Quit()
+}
+
+// message is the plain text email of the notification
+func (c *emailChannel) message(recipient *models.NotificationRecipient, n *models.Notification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", c.cfg.Notifications.EmailFrom)
+	fmt.Fprintf(&buf, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	fmt.Fprintf(&buf, "Hi %s,\r\n\r\n%s\r\n", recipient.FirstName, n.Body)
+	return buf.Bytes()
+}
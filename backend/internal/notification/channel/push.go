@@ -0,0 +1,299 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultPushTTL = 24 * time.Hour
+	pushTimeout    = 30 * time.Second
+	vapidTokenTTL  = 12 * time.Hour
+	recordSize     = 4096
+)
+
+type pushChannel struct {
+	cfg              *config.Config
+	notificationRepo notification.Repository
+	privateKey       *ecdsa.PrivateKey // nil when web push is not configured
+	httpClient       *http.Client
+	logger           logger.Logger
+}
+
+// NewPushChannel creates the channel sending notifications by web push, encrypted as in RFC 8291 and
+// authenticated with the VAPID keys of RFC 8292. The subscriptions the push services report gone are deleted
+func NewPushChannel(cfg *config.Config, notificationRepo notification.Repository, logger logger.Logger) (notification.Channel, error) {
+	c := &pushChannel{
+		cfg:              cfg,
+		notificationRepo: notificationRepo,
+		httpClient:       &http.Client{Timeout: pushTimeout},
+		logger:           logger,
+	}
+
+	if cfg.Notifications.VAPIDPublicKey == "" {
+		return c, nil
+	}
+
+	privateKey, err := parseVAPIDKeys(cfg.Notifications.VAPIDPublicKey, cfg.Notifications.VAPIDPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewPushChannel.parseVAPIDKeys")
+	}
+	c.privateKey = privateKey
+
+	return c, nil
+}
+
+func (c *pushChannel) Name() string {
+	return models.NotificationChannelPush
+}
+
+func (c *pushChannel) Enabled() bool {
+	return c.privateKey != nil
+}
+
+func (c *pushChannel) Send(ctx context.Context, recipient *models.NotificationRecipient, n *models.Notification) error {
+	if !c.Enabled() {
+		return notification.ErrPushDisabled
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "pushChannel.Send.json.Marshal")
+	}
+
+	// Every browser of the user gets the notification, the ones that failed fail the delivery
+	failures := make([]string, 0)
+	for _, subscription := range recipient.PushSubscriptions {
+		err := c.push(ctx, subscription, payload)
+		switch {
+		case err == nil:
+		case errors.Is(err, notification.ErrSubscriptionExpired):
+			if err := c.notificationRepo.DeleteExpiredPushSubscription(ctx, subscription.Endpoint); err != nil {
+				c.logger.Errorf("pushChannel.Send.DeleteExpiredPushSubscription, SubscriptionID: %s, Error: %v", subscription.SubscriptionID, err)
+			}
+		default:
+			failures = append(failures, fmt.Sprintf("%s: %v", subscription.SubscriptionID, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("pushChannel.Send: %s", strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// push sends the encrypted payload to a subscription's push service
+func (c *pushChannel) push(ctx context.Context, subscription *models.PushSubscription, payload []byte) error {
+	body, err := encryptPayload(subscription.P256dh, subscription.Auth, payload)
+	if err != nil {
+		return errors.Wrap(err, "encryptPayload")
+	}
+
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil {
+		return errors.Wrap(err, "url.Parse")
+	}
+	token, err := c.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return errors.Wrap(err, "vapidToken")
+	}
+
+	ttl := c.cfg.Notifications.PushTTL * time.Second
+	if ttl <= 0 {
+		ttl = defaultPushTTL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "http.NewRequestWithContext")
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, c.cfg.Notifications.VAPIDPublicKey))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "httpClient.Do")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return notification.ErrSubscriptionExpired
+	case resp.StatusCode >= 300:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.Errorf("push service responded %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// vapidToken is the ES256 JWT identifying the server to the push service of audience
+func (c *pushChannel) vapidToken(audience string) (string, error) {
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": c.cfg.Notifications.VAPIDSubject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, c.privateKey, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	// JWS signatures are the fixed size r and s, not ASN.1
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// encryptPayload encrypts payload for the subscription keys in a single aes128gcm record, RFC 8291
+func encryptPayload(p256dh string, authSecret string, payload []byte) ([]byte, error) {
+	uaPublic, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, errors.Wrap(err, "p256dh")
+	}
+	auth, err := decodeBase64URL(authSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "auth")
+	}
+
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewPublicKey")
+	}
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateKey")
+	}
+	asPublic := asKey.PublicKey().Bytes()
+
+	sharedSecret, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "ECDH")
+	}
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublic...), asPublic...)
+	ikm, err := expand(hkdf.Extract(sha256.New, sharedSecret, auth), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "rand.Read")
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, errors.Wrap(err, "aes.NewCipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "cipher.NewGCM")
+	}
+
+	// The padding delimiter of the last record, the tag and the header must fit the record size
+	if len(payload)+1+gcm.Overhead() > recordSize {
+		return nil, errors.Errorf("payload of %d bytes is too large", len(payload))
+	}
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func expand(prk []byte, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, errors.Wrap(err, "hkdf.Expand")
+	}
+	return out, nil
+}
+
+// parseVAPIDKeys parses the base64url VAPID key pair and checks the public key matches the private one
+func parseVAPIDKeys(publicKey string, privateKey string) (*ecdsa.PrivateKey, error) {
+	d, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "private key")
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "private key")
+	}
+
+	public, err := decodeBase64URL(publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "public key")
+	}
+	if !bytes.Equal(public, key.PublicKey().Bytes()) {
+		return nil, errors.New("the public key does not match the private key")
+	}
+
+	// Uncompressed point, 0x04 followed by X and Y
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}, nil
+}
+
+// decodeBase64URL decodes the base64url keys of the browsers, padded or not
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
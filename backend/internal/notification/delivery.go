@@ -0,0 +1,17 @@
+package notification
+
+import "github.com/labstack/echo/v4"
+
+// Notification HTTP Handlers interface
+type Handlers interface {
+	GetNotifications() echo.HandlerFunc
+	MarkRead() echo.HandlerFunc
+	MarkAllRead() echo.HandlerFunc
+	Stream() echo.HandlerFunc
+	GetPreferences() echo.HandlerFunc
+	UpdatePreferences() echo.HandlerFunc
+	GetPushPublicKey() echo.HandlerFunc
+	SavePushSubscription() echo.HandlerFunc
+	DeletePushSubscription() echo.HandlerFunc
+	ProcessDeliveries() echo.HandlerFunc
+}
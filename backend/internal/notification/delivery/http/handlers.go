@@ -0,0 +1,376 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+const defaultHeartbeat = 25 * time.Second
+
+type notificationHandlers struct {
+	cfg            *config.Config
+	notificationUC notification.UseCase
+	logger         logger.Logger
+}
+
+func NewNotificationHandlers(cfg *config.Config, notificationUC notification.UseCase, logger logger.Logger) notification.Handlers {
+	return &notificationHandlers{
+		cfg:            cfg,
+		notificationUC: notificationUC,
+		logger:         logger,
+	}
+}
+
+// GetNotifications godoc
+// @Summary Get my notifications
+// @Description My inbox, newest first, with the total and unread counts
+// @Tags Notifications
+// @Produce json
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param offset query int false "Notifications to skip"
+// @Param unread query bool false "Only the unread notifications"
+// @Success 200 {object} models.NotificationList
+// @Router /notifications [get]
+func (h *notificationHandlers) GetNotifications() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.GetNotifications.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.GetNotifications.pageParams"))
+		}
+		unreadOnly := false
+		if unreadParam := c.QueryParam("unread"); unreadParam != "" {
+			if unreadOnly, err = strconv.ParseBool(unreadParam); err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.GetNotifications.ParseBool"))
+			}
+		}
+
+		list, err := h.notificationUC.GetNotifications(c.Request().Context(), userID, unreadOnly, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.GetNotifications.GetNotifications"))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// MarkRead godoc
+// @Summary Mark a notification read
+// @Tags Notifications
+// @Param notification_id path string true "Notification ID"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /notifications/{notification_id}/read [post]
+func (h *notificationHandlers) MarkRead() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.MarkRead.GetUserIDFromContext"))
+		}
+
+		notificationID, err := uuid.Parse(c.Param("notification_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.MarkRead.uuid.Parse"))
+		}
+
+		if err := h.notificationUC.MarkRead(c.Request().Context(), userID, notificationID); err != nil {
+			return notificationError(err, "notificationHandlers.MarkRead.MarkRead")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// MarkAllRead godoc
+// @Summary Mark all my notifications read
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Router /notifications/read [post]
+func (h *notificationHandlers) MarkAllRead() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.MarkAllRead.GetUserIDFromContext"))
+		}
+
+		marked, err := h.notificationUC.MarkAllRead(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.MarkAllRead.MarkAllRead"))
+		}
+
+		return c.JSON(http.StatusOK, map[string]int{"marked": marked})
+	}
+}
+
+// Stream godoc
+// @Summary Stream my new notifications
+// @Description Server-Sent Events, each new notification is a "notification" event with the notification as data.
+// @Description EventSource sends the jwt-token cookie, comment lines keep the idle stream open
+// @Tags Notifications
+// @Produce text/event-stream
+// @Success 200
+// @Router /notifications/stream [get]
+func (h *notificationHandlers) Stream() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.Stream.GetUserIDFromContext"))
+		}
+
+		ctx, cancel := context.WithCancel(c.Request().Context())
+		defer cancel()
+
+		notifications, err := h.notificationUC.Subscribe(ctx, userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.Stream.Subscribe"))
+		}
+
+		// The server write timeout would cut the stream
+		if err := http.NewResponseController(c.Response()).SetWriteDeadline(time.Time{}); err != nil {
+			h.logger.Warnf("notificationHandlers.Stream.SetWriteDeadline: %v", err)
+		}
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set("Cache-Control", "no-cache")
+		res.Header().Set("Connection", "keep-alive")
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+		res.Flush()
+
+		heartbeat := h.cfg.Notifications.StreamHeartbeat * time.Second
+		if heartbeat <= 0 {
+			heartbeat = defaultHeartbeat
+		}
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case n, ok := <-notifications:
+				if !ok {
+					return nil
+				}
+				data, err := json.Marshal(n)
+				if err != nil {
+					h.logger.Errorf("notificationHandlers.Stream.json.Marshal: %v", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(res, "id: %s\nevent: notification\ndata: %s\n\n", n.NotificationID, data); err != nil {
+					return nil
+				}
+				res.Flush()
+			case <-ticker.C:
+				if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+					return nil
+				}
+				res.Flush()
+			}
+		}
+	}
+}
+
+// GetPreferences godoc
+// @Summary Get my notification preferences
+// @Description Which notifications I get by email and push, and my quiet hours
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} models.NotificationPreferences
+// @Router /notifications/preferences [get]
+func (h *notificationHandlers) GetPreferences() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.GetPreferences.GetUserIDFromContext"))
+		}
+
+		preferences, err := h.notificationUC.GetPreferences(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.GetPreferences.GetPreferences"))
+		}
+
+		return c.JSON(http.StatusOK, preferences)
+	}
+}
+
+// UpdatePreferences godoc
+// @Summary Update my notification preferences
+// @Description Change the preferences that are set. Quiet hours are local hours of my timezone, email and push
+// @Description created during them are sent when they end
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param body body models.NotificationPreferencesUpdate true "Preferences to change"
+// @Success 200 {object} models.NotificationPreferences
+// @Router /notifications/preferences [put]
+func (h *notificationHandlers) UpdatePreferences() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.UpdatePreferences.GetUserIDFromContext"))
+		}
+
+		update := &models.NotificationPreferencesUpdate{}
+		if err := c.Bind(update); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.UpdatePreferences.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), update); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.UpdatePreferences.ValidateStruct"))
+		}
+
+		preferences, err := h.notificationUC.UpdatePreferences(c.Request().Context(), userID, update)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.UpdatePreferences.UpdatePreferences"))
+		}
+
+		return c.JSON(http.StatusOK, preferences)
+	}
+}
+
+// GetPushPublicKey godoc
+// @Summary Get the web push public key
+// @Description The VAPID applicationServerKey browsers subscribe with, empty when web push is not configured
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /notifications/push/key [get]
+func (h *notificationHandlers) GetPushPublicKey() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"public_key": h.notificationUC.GetPushPublicKey()})
+	}
+}
+
+// SavePushSubscription godoc
+// @Summary Subscribe this browser to web push
+// @Description The JSON of the browser's PushSubscription, an endpoint subscribed before moves to me
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param body body models.PushSubscription true "Push subscription"
+// @Success 201 {object} models.PushSubscription
+// @Failure 501 {object} httpErrors.RestError
+// @Router /notifications/push/subscriptions [post]
+func (h *notificationHandlers) SavePushSubscription() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.SavePushSubscription.GetUserIDFromContext"))
+		}
+
+		subscription := &models.PushSubscription{}
+		if err := c.Bind(subscription); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.SavePushSubscription.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), subscription); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.SavePushSubscription.ValidateStruct"))
+		}
+		subscription.UserID = userID
+
+		saved, err := h.notificationUC.SavePushSubscription(c.Request().Context(), subscription)
+		if err != nil {
+			return notificationError(err, "notificationHandlers.SavePushSubscription.SavePushSubscription")
+		}
+
+		return c.JSON(http.StatusCreated, saved)
+	}
+}
+
+// DeletePushSubscription godoc
+// @Summary Unsubscribe a browser from web push
+// @Tags Notifications
+// @Accept json
+// @Param body body models.PushUnsubscribe true "Endpoint of the subscription"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /notifications/push/subscriptions [delete]
+func (h *notificationHandlers) DeletePushSubscription() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "notificationHandlers.DeletePushSubscription.GetUserIDFromContext"))
+		}
+
+		unsubscribe := &models.PushUnsubscribe{}
+		if err := c.Bind(unsubscribe); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.DeletePushSubscription.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), unsubscribe); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.DeletePushSubscription.ValidateStruct"))
+		}
+
+		if err := h.notificationUC.DeletePushSubscription(c.Request().Context(), userID, unsubscribe.Endpoint); err != nil {
+			return notificationError(err, "notificationHandlers.DeletePushSubscription.DeletePushSubscription")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ProcessDeliveries godoc
+// @Summary Run the notification delivery job
+// @Description Send a batch of the due email and push deliveries, the notification worker runs it on every poll
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} models.NotificationJobReport
+// @Router /notifications/admin/process [post]
+func (h *notificationHandlers) ProcessDeliveries() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report, err := h.notificationUC.ProcessDeliveries(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "notificationHandlers.ProcessDeliveries.ProcessDeliveries"))
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+// notificationError maps the notification errors to their status
+func notificationError(err error, op string) error {
+	switch {
+	case errors.Is(err, notification.ErrNotificationNotFound),
+		errors.Is(err, notification.ErrSubscriptionNotFound):
+		return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, notification.ErrPushDisabled):
+		return httpErrors.NewRestError(http.StatusNotImplemented, err.Error(), nil)
+	default:
+		return httpErrors.NewBadRequestError(errors.Wrap(err, op))
+	}
+}
+
+func pageParams(c echo.Context) (int, int, error) {
+	var err error
+	limit, offset := 0, 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
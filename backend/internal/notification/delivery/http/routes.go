@@ -0,0 +1,34 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+)
+
+// Map notification routes
+func MapNotificationRoutes(notificationGroup *echo.Group, h notification.Handlers, mw *middleware.MiddlewareManager) {
+	// Public routes
+	notificationGroup.GET("/push/key", h.GetPushPublicKey())
+
+	protected := notificationGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("", h.GetNotifications())
+		protected.GET("/stream", h.Stream())
+		protected.POST("/read", h.MarkAllRead())
+		protected.POST("/:notification_id/read", h.MarkRead())
+		protected.GET("/preferences", h.GetPreferences())
+		protected.PUT("/preferences", h.UpdatePreferences())
+		protected.POST("/push/subscriptions", h.SavePushSubscription())
+		protected.DELETE("/push/subscriptions", h.DeletePushSubscription())
+
+		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionJobsRun))
+		{
+			admin.POST("/process", h.ProcessDeliveries())
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package notification
+
+import "errors"
+
+// Notification errors
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrSubscriptionNotFound = errors.New("push subscription not found")
+	ErrPushDisabled         = errors.New("web push is not configured")
+	ErrSubscriptionExpired  = errors.New("push subscription expired")
+	ErrQuietHoursEmpty      = errors.New("quiet hours must not start and end at the same hour")
+)
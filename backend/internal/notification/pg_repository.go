@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Notification Repository interface
+type Repository interface {
+	// Saves the notification with its deliveries, false when a notification for its event already exists
+	CreateNotification(ctx context.Context, notification *models.Notification, channels []string, sendAfter time.Time) (*models.Notification, bool, error)
+	GetNotificationByID(ctx context.Context, notificationID uuid.UUID) (*models.Notification, error)
+	GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int, offset int) (*models.NotificationList, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// The user's preferences, the defaults when they never changed them
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error)
+	UpsertPreferences(ctx context.Context, preferences *models.NotificationPreferences) (*models.NotificationPreferences, error)
+	GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error)
+
+	// Saves the subscription, an endpoint subscribed before moves to the user
+	SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) (*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error
+	// Deletes a subscription the push service reported gone, whoever it belongs to
+	DeleteExpiredPushSubscription(ctx context.Context, endpoint string) error
+	CountPushSubscriptions(ctx context.Context, userID uuid.UUID) (int, error)
+	// The user's email address and push subscriptions
+	GetRecipient(ctx context.Context, userID uuid.UUID) (*models.NotificationRecipient, error)
+
+	// Names the notifications mention
+	GetAchievementTitle(ctx context.Context, achievementID uuid.UUID) (string, error)
+	GetUserFirstName(ctx context.Context, userID uuid.UUID) (string, error)
+	GetQuizTitle(ctx context.Context, quizID uuid.UUID) (string, error)
+
+	// Claims up to limit pending deliveries due now, hidden from other workers for lease
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationDelivery, error)
+	MarkDeliverySent(ctx context.Context, deliveryID uuid.UUID) error
+	// Records a failed attempt or a skipped delivery, it is retried at sendAfter while status is pending
+	MarkDeliveryFailed(ctx context.Context, deliveryID uuid.UUID, status string, lastError string, sendAfter time.Time) error
+}
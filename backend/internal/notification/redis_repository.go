@@ -0,0 +1,16 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Broker streams the new notifications to the user's connections on every API instance
+type Broker interface {
+	Publish(ctx context.Context, notification *models.Notification) error
+	// Receives the notifications published to the user until ctx is done
+	Subscribe(ctx context.Context, userID uuid.UUID) (<-chan *models.Notification, error)
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultPageSize = 50
+
+type notificationRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewNotificationRepository(db *sqlx.DB, logger logger.Logger) notification.Repository {
+	return &notificationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *notificationRepo) CreateNotification(
+	ctx context.Context,
+	n *models.Notification,
+	channels []string,
+	sendAfter time.Time,
+) (*models.Notification, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "notificationRepo.CreateNotification.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	created := &models.Notification{}
+	if err := tx.GetContext(
+		ctx,
+		created,
+		createNotificationQuery,
+		n.UserID,
+		n.Type,
+		n.Title,
+		n.Body,
+		n.Data,
+		n.EventID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, "notificationRepo.CreateNotification.createNotification")
+	}
+
+	for _, channel := range channels {
+		if _, err := tx.ExecContext(ctx, createDeliveryQuery, created.NotificationID, channel, sendAfter); err != nil {
+			return nil, false, errors.Wrap(err, "notificationRepo.CreateNotification.createDelivery")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, errors.Wrap(err, "notificationRepo.CreateNotification.Commit")
+	}
+
+	return created, true, nil
+}
+
+func (r *notificationRepo) GetNotificationByID(ctx context.Context, notificationID uuid.UUID) (*models.Notification, error) {
+	n := &models.Notification{}
+	if err := r.db.GetContext(ctx, n, getNotificationByIDQuery, notificationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notification.ErrNotificationNotFound
+		}
+		return nil, errors.Wrap(err, "notificationRepo.GetNotificationByID.GetContext")
+	}
+	return n, nil
+}
+
+func (r *notificationRepo) GetNotifications(
+	ctx context.Context,
+	userID uuid.UUID,
+	unreadOnly bool,
+	limit int,
+	offset int,
+) (*models.NotificationList, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	list := &models.NotificationList{}
+	if err := r.db.QueryRowxContext(ctx, countNotificationsQuery, userID).Scan(&list.TotalCount, &list.UnreadCount); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.GetNotifications.Scan")
+	}
+
+	list.Notifications = make([]*models.Notification, 0, limit)
+	if err := r.db.SelectContext(ctx, &list.Notifications, getNotificationsQuery, userID, unreadOnly, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.GetNotifications.SelectContext")
+	}
+
+	return list, nil
+}
+
+func (r *notificationRepo) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, markReadQuery, notificationID, userID)
+	if err != nil {
+		return errors.Wrap(err, "notificationRepo.MarkRead.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "notificationRepo.MarkRead.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return notification.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	result, err := r.db.ExecContext(ctx, markAllReadQuery, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "notificationRepo.MarkAllRead.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "notificationRepo.MarkAllRead.RowsAffected")
+	}
+	return int(rowsAffected), nil
+}
+
+func (r *notificationRepo) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	preferences := &models.NotificationPreferences{}
+	if err := r.db.GetContext(ctx, preferences, getPreferencesQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.GetPreferences.GetContext")
+	}
+	return preferences, nil
+}
+
+func (r *notificationRepo) UpsertPreferences(ctx context.Context, p *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	preferences := &models.NotificationPreferences{}
+	if err := r.db.GetContext(
+		ctx,
+		preferences,
+		upsertPreferencesQuery,
+		p.UserID,
+		p.EmailEnabled,
+		p.PushEnabled,
+		p.MutedTypes,
+		p.QuietHoursEnabled,
+		p.QuietHoursStart,
+		p.QuietHoursEnd,
+		p.StreakReminders,
+		p.StreakReminderHour,
+	); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.UpsertPreferences.GetContext")
+	}
+	return preferences, nil
+}
+
+func (r *notificationRepo) GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error) {
+	var timezone string
+	if err := r.db.GetContext(ctx, &timezone, getUserTimezoneQuery, userID); err != nil {
+		return "", errors.Wrap(err, "notificationRepo.GetUserTimezone.GetContext")
+	}
+	return timezone, nil
+}
+
+func (r *notificationRepo) SavePushSubscription(ctx context.Context, s *models.PushSubscription) (*models.PushSubscription, error) {
+	saved := &models.PushSubscription{}
+	if err := r.db.GetContext(ctx, saved, savePushSubscriptionQuery, s.UserID, s.Endpoint, s.P256dh, s.Auth); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.SavePushSubscription.GetContext")
+	}
+	return saved, nil
+}
+
+func (r *notificationRepo) DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	result, err := r.db.ExecContext(ctx, deletePushSubscriptionQuery, userID, endpoint)
+	if err != nil {
+		return errors.Wrap(err, "notificationRepo.DeletePushSubscription.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "notificationRepo.DeletePushSubscription.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return notification.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepo) DeleteExpiredPushSubscription(ctx context.Context, endpoint string) error {
+	if _, err := r.db.ExecContext(ctx, deleteExpiredPushSubscriptionQuery, endpoint); err != nil {
+		return errors.Wrap(err, "notificationRepo.DeleteExpiredPushSubscription.ExecContext")
+	}
+	return nil
+}
+
+func (r *notificationRepo) CountPushSubscriptions(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, countPushSubscriptionsQuery, userID); err != nil {
+		return 0, errors.Wrap(err, "notificationRepo.CountPushSubscriptions.GetContext")
+	}
+	return count, nil
+}
+
+func (r *notificationRepo) GetRecipient(ctx context.Context, userID uuid.UUID) (*models.NotificationRecipient, error) {
+	recipient := &models.NotificationRecipient{}
+	if err := r.db.GetContext(ctx, recipient, getRecipientQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.GetRecipient.GetContext")
+	}
+
+	recipient.PushSubscriptions = make([]*models.PushSubscription, 0)
+	if err := r.db.SelectContext(ctx, &recipient.PushSubscriptions, getPushSubscriptionsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.GetRecipient.getPushSubscriptions")
+	}
+
+	return recipient, nil
+}
+
+func (r *notificationRepo) GetAchievementTitle(ctx context.Context, achievementID uuid.UUID) (string, error) {
+	var title string
+	if err := r.db.GetContext(ctx, &title, getAchievementTitleQuery, achievementID); err != nil {
+		return "", errors.Wrap(err, "notificationRepo.GetAchievementTitle.GetContext")
+	}
+	return title, nil
+}
+
+func (r *notificationRepo) GetUserFirstName(ctx context.Context, userID uuid.UUID) (string, error) {
+	var firstName string
+	if err := r.db.GetContext(ctx, &firstName, getUserFirstNameQuery, userID); err != nil {
+		return "", errors.Wrap(err, "notificationRepo.GetUserFirstName.GetContext")
+	}
+	return firstName, nil
+}
+
+func (r *notificationRepo) GetQuizTitle(ctx context.Context, quizID uuid.UUID) (string, error) {
+	var title string
+	if err := r.db.GetContext(ctx, &title, getQuizTitleQuery, quizID); err != nil {
+		return "", errors.Wrap(err, "notificationRepo.GetQuizTitle.GetContext")
+	}
+	return title, nil
+}
+
+func (r *notificationRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationDelivery, error) {
+	claimed := make([]*models.NotificationDelivery, 0, limit)
+	if err := r.db.SelectContext(ctx, &claimed, claimDueDeliveriesQuery, limit, lease.Seconds()); err != nil {
+		return nil, errors.Wrap(err, "notificationRepo.ClaimDueDeliveries.SelectContext")
+	}
+	return claimed, nil
+}
+
+func (r *notificationRepo) MarkDeliverySent(ctx context.Context, deliveryID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, markDeliverySentQuery, deliveryID); err != nil {
+		return errors.Wrap(err, "notificationRepo.MarkDeliverySent.ExecContext")
+	}
+	return nil
+}
+
+func (r *notificationRepo) MarkDeliveryFailed(
+	ctx context.Context,
+	deliveryID uuid.UUID,
+	status string,
+	lastError string,
+	sendAfter time.Time,
+) error {
+	if _, err := r.db.ExecContext(ctx, markDeliveryFailedQuery, deliveryID, status, lastError, sendAfter); err != nil {
+		return errors.Wrap(err, "notificationRepo.MarkDeliveryFailed.ExecContext")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	channelPrefix       = "notifications:"
+	subscribeBufferSize = 16
+)
+
+type notificationBroker struct {
+	redisClient *redis.Client
+	logger      logger.Logger
+}
+
+// NewNotificationBroker creates the Redis pub/sub broker of the new notifications, one channel per user
+func NewNotificationBroker(redisClient *redis.Client, logger logger.Logger) notification.Broker {
+	return &notificationBroker{
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+func (b *notificationBroker) Publish(ctx context.Context, n *models.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "notificationBroker.Publish.json.Marshal")
+	}
+
+	if err := b.redisClient.Publish(ctx, channelName(n.UserID), data).Err(); err != nil {
+		return errors.Wrap(err, "notificationBroker.Publish.Publish")
+	}
+
+	return nil
+}
+
+func (b *notificationBroker) Subscribe(ctx context.Context, userID uuid.UUID) (<-chan *models.Notification, error) {
+	pubsub := b.redisClient.Subscribe(ctx, channelName(userID))
+	// Wait for the confirmation, so nothing published after Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, errors.Wrap(err, "notificationBroker.Subscribe.Receive")
+	}
+
+	notifications := make(chan *models.Notification, subscribeBufferSize)
+	go func() {
+		defer close(notifications)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				n := &models.Notification{}
+				if err := json.Unmarshal([]byte(msg.Payload), n); err != nil {
+					b.logger.Errorf("notificationBroker.Subscribe: invalid notification on %s: %v", msg.Channel, err)
+					continue
+				}
+
+				select {
+				case notifications <- n:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return notifications, nil
+}
+
+func channelName(userID uuid.UUID) string {
+	return channelPrefix + userID.String()
+}
@@ -0,0 +1,154 @@
+package repository
+
+const (
+	// Nothing is returned when a notification for the event already exists
+	createNotificationQuery = `
+		INSERT INTO notifications (user_id, type, title, body, data, event_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (event_id) DO NOTHING
+		RETURNING *
+	`
+
+	createDeliveryQuery = `
+		INSERT INTO notification_deliveries (notification_id, channel, send_after)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (notification_id, channel) DO NOTHING
+	`
+
+	getNotificationByIDQuery = `
+		SELECT * FROM notifications WHERE notification_id = $1
+	`
+
+	countNotificationsQuery = `
+		SELECT COUNT(*) AS total_count, COUNT(*) FILTER (WHERE read_at IS NULL) AS unread_count
+		FROM notifications
+		WHERE user_id = $1
+	`
+
+	getNotificationsQuery = `
+		SELECT * FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	// A notification read before keeps its read_at
+	markReadQuery = `
+		UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE notification_id = $1 AND user_id = $2
+	`
+
+	markAllReadQuery = `
+		UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+
+	// Users without preferences have the defaults of notification_preferences
+	getPreferencesQuery = `
+		SELECT
+			u.user_id,
+			COALESCE(np.email_enabled, true) AS email_enabled,
+			COALESCE(np.push_enabled, true) AS push_enabled,
+			COALESCE(np.muted_types, '{}') AS muted_types,
+			COALESCE(np.quiet_hours_enabled, false) AS quiet_hours_enabled,
+			COALESCE(np.quiet_hours_start, 21) AS quiet_hours_start,
+			COALESCE(np.quiet_hours_end, 7) AS quiet_hours_end,
+			COALESCE(np.streak_reminders_enabled, true) AS streak_reminders_enabled,
+			COALESCE(np.streak_reminder_hour, 19) AS streak_reminder_hour,
+			COALESCE(np.updated_at, u.created_at) AS updated_at
+		FROM users u
+		LEFT JOIN notification_preferences np ON np.user_id = u.user_id
+		WHERE u.user_id = $1
+	`
+
+	upsertPreferencesQuery = `
+		INSERT INTO notification_preferences (
+			user_id, email_enabled, push_enabled, muted_types, quiet_hours_enabled, quiet_hours_start, quiet_hours_end,
+			streak_reminders_enabled, streak_reminder_hour
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email_enabled = EXCLUDED.email_enabled,
+			push_enabled = EXCLUDED.push_enabled,
+			muted_types = EXCLUDED.muted_types,
+			quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			streak_reminders_enabled = EXCLUDED.streak_reminders_enabled,
+			streak_reminder_hour = EXCLUDED.streak_reminder_hour,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`
+
+	getUserTimezoneQuery = `
+		SELECT timezone FROM users WHERE user_id = $1
+	`
+
+	// A browser resubscribing, possibly after another user logged in on it, keeps its endpoint
+	savePushSubscriptionQuery = `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth
+		RETURNING *
+	`
+
+	deletePushSubscriptionQuery = `
+		DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2
+	`
+
+	deleteExpiredPushSubscriptionQuery = `
+		DELETE FROM push_subscriptions WHERE endpoint = $1
+	`
+
+	countPushSubscriptionsQuery = `
+		SELECT COUNT(*) FROM push_subscriptions WHERE user_id = $1
+	`
+
+	getRecipientQuery = `
+		SELECT user_id, email, first_name FROM users WHERE user_id = $1
+	`
+
+	getPushSubscriptionsQuery = `
+		SELECT * FROM push_subscriptions WHERE user_id = $1 ORDER BY created_at
+	`
+
+	getAchievementTitleQuery = `
+		SELECT title FROM achievements WHERE achievement_id = $1
+	`
+
+	getUserFirstNameQuery = `
+		SELECT first_name FROM users WHERE user_id = $1
+	`
+
+	getQuizTitleQuery = `
+		SELECT title FROM quizzes WHERE quiz_id = $1
+	`
+
+	claimDueDeliveriesQuery = `
+		UPDATE notification_deliveries
+		SET send_after = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE delivery_id IN (
+			SELECT delivery_id FROM notification_deliveries
+			WHERE status = 'pending' AND send_after <= CURRENT_TIMESTAMP
+			ORDER BY send_after
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	markDeliverySentQuery = `
+		UPDATE notification_deliveries
+		SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = CURRENT_TIMESTAMP
+		WHERE delivery_id = $1
+	`
+
+	markDeliveryFailedQuery = `
+		UPDATE notification_deliveries
+		SET status = $2, attempts = attempts + 1, last_error = $3, send_after = $4
+		WHERE delivery_id = $1
+	`
+)
@@ -0,0 +1,86 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+)
+
+const subscriberName = "notifications"
+
+// RegisterNotificationSubscribers notifies users of their achievements, level ups, lost streaks and challenges
+func RegisterNotificationSubscribers(bus events.Bus, notificationUC notification.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		switch event.Type {
+		case events.AchievementAwardedType:
+			payload := &events.AchievementAwarded{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.Decode")
+			}
+			if err := notificationUC.NotifyAchievementEarned(ctx, event.UserID, event.EventID, payload.AchievementID); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.NotifyAchievementEarned")
+			}
+		case events.LevelUpType:
+			payload := &events.LevelUp{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.Decode")
+			}
+			if err := notificationUC.NotifyLevelUp(ctx, event.UserID, event.EventID, payload.ToLevel, payload.RewardFreezes); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.NotifyLevelUp")
+			}
+		case events.StreakUpdatedType:
+			payload := &events.StreakUpdated{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.Decode")
+			}
+			// Only a streak that broke is news, a streak of a day is not worth a notification
+			if !payload.Broken || payload.BrokenStreak < 2 {
+				return nil
+			}
+			if err := notificationUC.NotifyStreakLost(ctx, event.UserID, event.EventID, payload.BrokenStreak); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.NotifyStreakLost")
+			}
+		case events.ChallengeReceivedType:
+			payload := &events.ChallengeReceived{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.Decode")
+			}
+			if err := notificationUC.NotifyChallengeReceived(
+				ctx,
+				event.UserID,
+				event.EventID,
+				payload.ChallengeID,
+				payload.ChallengerID,
+				payload.QuizID,
+			); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.NotifyChallengeReceived")
+			}
+		case events.ChallengeEndedType:
+			payload := &events.ChallengeEnded{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.Decode")
+			}
+			if err := notificationUC.NotifyChallengeEnded(
+				ctx,
+				event.UserID,
+				event.EventID,
+				payload.ChallengeID,
+				payload.OpponentID,
+				payload.Status,
+				payload.WinnerID,
+			); err != nil {
+				return errors.Wrap(err, "notificationSubscriber.NotifyChallengeEnded")
+			}
+		}
+		return nil
+	},
+		events.AchievementAwardedType,
+		events.LevelUpType,
+		events.StreakUpdatedType,
+		events.ChallengeReceivedType,
+		events.ChallengeEndedType,
+	)
+}
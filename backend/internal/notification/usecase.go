@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Notification UseCase interface
+type UseCase interface {
+	// Saves the notification to the inbox, streams it to the user's open connections and schedules its
+	// email and push deliveries according to the user's preferences. A notification for an event that
+	// already notified is dropped and nil is returned
+	Notify(ctx context.Context, notification *models.Notification) (*models.Notification, error)
+
+	// Notifications of the domain events, eventID is their idempotency key
+	NotifyAchievementEarned(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, achievementID uuid.UUID) error
+	NotifyLevelUp(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, level int, rewardFreezes int) error
+	NotifyStreakLost(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, brokenStreak int) error
+	NotifyChallengeReceived(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, challengeID uuid.UUID, challengerID uuid.UUID, quizID uuid.UUID) error
+	NotifyChallengeEnded(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, challengeID uuid.UUID, opponentID uuid.UUID, status string, winnerID *uuid.UUID) error
+
+	GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int, offset int) (*models.NotificationList, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error
+	// Marks every unread notification of the user read and returns how many there were
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+	// Receives the user's new notifications until ctx is done
+	Subscribe(ctx context.Context, userID uuid.UUID) (<-chan *models.Notification, error)
+
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, update *models.NotificationPreferencesUpdate) (*models.NotificationPreferences, error)
+
+	// The VAPID public key browsers subscribe with, empty when web push is not configured
+	GetPushPublicKey() string
+	SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) (*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error
+
+	// Sends the due email and push deliveries, retrying the failed ones until MaxAttempts
+	ProcessDeliveries(ctx context.Context) (*models.NotificationJobReport, error)
+}
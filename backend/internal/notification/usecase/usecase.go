@@ -0,0 +1,510 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultBatchSize   = 50
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = 30 * time.Second
+	maxRetryDelay      = time.Hour
+	maxPageSize        = 100
+
+	// Claimed deliveries are hidden from other workers for this long, so a crashed worker's batch is picked up again
+	claimLease = 5 * time.Minute
+)
+
+type notificationUC struct {
+	cfg              *config.Config
+	notificationRepo notification.Repository
+	broker           notification.Broker
+	channels         map[string]notification.Channel
+	logger           logger.Logger
+}
+
+func NewNotificationUseCase(
+	cfg *config.Config,
+	notificationRepo notification.Repository,
+	broker notification.Broker,
+	channels []notification.Channel,
+	logger logger.Logger,
+) notification.UseCase {
+	byName := make(map[string]notification.Channel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+
+	return &notificationUC{
+		cfg:              cfg,
+		notificationRepo: notificationRepo,
+		broker:           broker,
+		channels:         byName,
+		logger:           logger,
+	}
+}
+
+func (u *notificationUC) Notify(ctx context.Context, n *models.Notification) (*models.Notification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.Notify")
+	defer span.Finish()
+
+	preferences, err := u.notificationRepo.GetPreferences(ctx, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := u.notificationRepo.CountPushSubscriptions(ctx, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]string, 0, len(u.channels))
+	for name := range u.channels {
+		if u.wants(preferences, name, n.Type, subscriptions) {
+			channels = append(channels, name)
+		}
+	}
+
+	// Email and push wait for the end of the quiet hours, the inbox and the streams do not
+	now := time.Now()
+	sendAfter := now
+	if preferences.QuietHoursEnabled && len(channels) > 0 {
+		timezone, err := u.notificationRepo.GetUserTimezone(ctx, n.UserID)
+		if err != nil {
+			return nil, err
+		}
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, errors.Wrap(err, "notificationUC.Notify.LoadLocation")
+		}
+		sendAfter = quietHoursEnd(now, loc, preferences.QuietHoursStart, preferences.QuietHoursEnd)
+	}
+
+	created, ok, err := u.notificationRepo.CreateNotification(ctx, n, channels, sendAfter)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	// The notification is in the inbox already, a stream that misses it catches up on the next list
+	if err := u.broker.Publish(ctx, created); err != nil {
+		u.logger.Errorf("notificationUC.Notify.Publish, NotificationID: %s, Error: %v", created.NotificationID, err)
+	}
+
+	return created, nil
+}
+
+func (u *notificationUC) NotifyAchievementEarned(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, achievementID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.NotifyAchievementEarned")
+	defer span.Finish()
+
+	title, err := u.notificationRepo.GetAchievementTitle(ctx, achievementID)
+	if err != nil {
+		return err
+	}
+
+	return u.notify(ctx, userID, eventID, models.NotificationAchievementEarned,
+		"Achievement unlocked",
+		fmt.Sprintf("You earned the %q achievement.", title),
+		map[string]interface{}{"achievement_id": achievementID},
+	)
+}
+
+func (u *notificationUC) NotifyLevelUp(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, level int, rewardFreezes int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.NotifyLevelUp")
+	defer span.Finish()
+
+	body := fmt.Sprintf("You reached level %d.", level)
+	if rewardFreezes > 0 {
+		body = fmt.Sprintf("You reached level %d and earned %d streak freezes.", level, rewardFreezes)
+	}
+
+	return u.notify(ctx, userID, eventID, models.NotificationLevelUp,
+		fmt.Sprintf("Level %d", level),
+		body,
+		map[string]interface{}{"level": level, "reward_freezes": rewardFreezes},
+	)
+}
+
+func (u *notificationUC) NotifyStreakLost(ctx context.Context, userID uuid.UUID, eventID uuid.UUID, brokenStreak int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.NotifyStreakLost")
+	defer span.Finish()
+
+	body := fmt.Sprintf("Your %d day streak ended.", brokenStreak)
+	if u.cfg.Streak.RepairWindowDays > 0 {
+		body = fmt.Sprintf("Your %d day streak ended. You can still repair it within %d days.", brokenStreak, u.cfg.Streak.RepairWindowDays)
+	}
+
+	return u.notify(ctx, userID, eventID, models.NotificationStreakLost,
+		"Streak lost",
+		body,
+		map[string]interface{}{"broken_streak": brokenStreak},
+	)
+}
+
+func (u *notificationUC) NotifyChallengeReceived(
+	ctx context.Context,
+	userID uuid.UUID,
+	eventID uuid.UUID,
+	challengeID uuid.UUID,
+	challengerID uuid.UUID,
+	quizID uuid.UUID,
+) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.NotifyChallengeReceived")
+	defer span.Finish()
+
+	challenger, err := u.notificationRepo.GetUserFirstName(ctx, challengerID)
+	if err != nil {
+		return err
+	}
+	quiz, err := u.notificationRepo.GetQuizTitle(ctx, quizID)
+	if err != nil {
+		return err
+	}
+
+	return u.notify(ctx, userID, eventID, models.NotificationChallengeReceived,
+		"New challenge",
+		fmt.Sprintf("%s challenged you on %q.", challenger, quiz),
+		map[string]interface{}{"challenge_id": challengeID, "challenger_id": challengerID, "quiz_id": quizID},
+	)
+}
+
+func (u *notificationUC) NotifyChallengeEnded(
+	ctx context.Context,
+	userID uuid.UUID,
+	eventID uuid.UUID,
+	challengeID uuid.UUID,
+	opponentID uuid.UUID,
+	status string,
+	winnerID *uuid.UUID,
+) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.NotifyChallengeEnded")
+	defer span.Finish()
+
+	opponent, err := u.notificationRepo.GetUserFirstName(ctx, opponentID)
+	if err != nil {
+		return err
+	}
+
+	var body string
+	switch {
+	case status == models.ChallengeStatusDeclined:
+		body = fmt.Sprintf("Your challenge with %s was declined.", opponent)
+	case status == models.ChallengeStatusExpired:
+		body = fmt.Sprintf("Your challenge with %s expired.", opponent)
+	case winnerID == nil:
+		body = fmt.Sprintf("Your challenge with %s ended in a draw.", opponent)
+	case *winnerID == userID:
+		body = fmt.Sprintf("You won your challenge against %s.", opponent)
+	default:
+		body = fmt.Sprintf("%s won your challenge.", opponent)
+	}
+
+	return u.notify(ctx, userID, eventID, models.NotificationChallengeEnded,
+		"Challenge ended",
+		body,
+		map[string]interface{}{"challenge_id": challengeID, "opponent_id": opponentID, "status": status, "winner_id": winnerID},
+	)
+}
+
+func (u *notificationUC) GetNotifications(
+	ctx context.Context,
+	userID uuid.UUID,
+	unreadOnly bool,
+	limit int,
+	offset int,
+) (*models.NotificationList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.GetNotifications")
+	defer span.Finish()
+
+	if limit > maxPageSize {
+		return nil, errors.Errorf("limit must not exceed %d", maxPageSize)
+	}
+	if offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	return u.notificationRepo.GetNotifications(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (u *notificationUC) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.MarkRead")
+	defer span.Finish()
+
+	return u.notificationRepo.MarkRead(ctx, userID, notificationID)
+}
+
+func (u *notificationUC) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.MarkAllRead")
+	defer span.Finish()
+
+	return u.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (u *notificationUC) Subscribe(ctx context.Context, userID uuid.UUID) (<-chan *models.Notification, error) {
+	return u.broker.Subscribe(ctx, userID)
+}
+
+func (u *notificationUC) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.GetPreferences")
+	defer span.Finish()
+
+	return u.notificationRepo.GetPreferences(ctx, userID)
+}
+
+func (u *notificationUC) UpdatePreferences(
+	ctx context.Context,
+	userID uuid.UUID,
+	update *models.NotificationPreferencesUpdate,
+) (*models.NotificationPreferences, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.UpdatePreferences")
+	defer span.Finish()
+
+	preferences, err := u.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.EmailEnabled != nil {
+		preferences.EmailEnabled = *update.EmailEnabled
+	}
+	if update.PushEnabled != nil {
+		preferences.PushEnabled = *update.PushEnabled
+	}
+	if update.MutedTypes != nil {
+		preferences.MutedTypes = append(pq.StringArray{}, *update.MutedTypes...)
+	}
+	if update.QuietHoursEnabled != nil {
+		preferences.QuietHoursEnabled = *update.QuietHoursEnabled
+	}
+	if update.QuietHoursStart != nil {
+		preferences.QuietHoursStart = *update.QuietHoursStart
+	}
+	if update.QuietHoursEnd != nil {
+		preferences.QuietHoursEnd = *update.QuietHoursEnd
+	}
+	if update.StreakReminders != nil {
+		preferences.StreakReminders = *update.StreakReminders
+	}
+	if update.StreakReminderHour != nil {
+		preferences.StreakReminderHour = *update.StreakReminderHour
+	}
+
+	if preferences.QuietHoursEnabled && preferences.QuietHoursStart == preferences.QuietHoursEnd {
+		return nil, notification.ErrQuietHoursEmpty
+	}
+
+	return u.notificationRepo.UpsertPreferences(ctx, preferences)
+}
+
+func (u *notificationUC) GetPushPublicKey() string {
+	if channel, ok := u.channels[models.NotificationChannelPush]; !ok || !channel.Enabled() {
+		return ""
+	}
+	return u.cfg.Notifications.VAPIDPublicKey
+}
+
+func (u *notificationUC) SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) (*models.PushSubscription, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.SavePushSubscription")
+	defer span.Finish()
+
+	if u.GetPushPublicKey() == "" {
+		return nil, notification.ErrPushDisabled
+	}
+
+	return u.notificationRepo.SavePushSubscription(ctx, subscription)
+}
+
+func (u *notificationUC) DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.DeletePushSubscription")
+	defer span.Finish()
+
+	return u.notificationRepo.DeletePushSubscription(ctx, userID, endpoint)
+}
+
+func (u *notificationUC) ProcessDeliveries(ctx context.Context) (*models.NotificationJobReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "notificationUC.ProcessDeliveries")
+	defer span.Finish()
+
+	batchSize := u.cfg.Notifications.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	claimed, err := u.notificationRepo.ClaimDueDeliveries(ctx, batchSize, claimLease)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.NotificationJobReport{}
+	for _, delivery := range claimed {
+		status, err := u.deliver(ctx, delivery)
+		if err != nil {
+			u.logger.Errorf("notificationUC.ProcessDeliveries.deliver, DeliveryID: %s, Error: %v", delivery.DeliveryID, err)
+			continue
+		}
+
+		switch status {
+		case models.DeliveryStatusSent:
+			report.Sent++
+		case models.DeliveryStatusFailed:
+			report.Failed++
+		case models.DeliveryStatusSkipped:
+			report.Skipped++
+		default:
+			report.Retried++
+		}
+	}
+
+	return report, nil
+}
+
+// deliver sends a claimed delivery through its channel and returns its new status
+func (u *notificationUC) deliver(ctx context.Context, delivery *models.NotificationDelivery) (string, error) {
+	n, err := u.notificationRepo.GetNotificationByID(ctx, delivery.NotificationID)
+	if err != nil {
+		return "", err
+	}
+	recipient, err := u.notificationRepo.GetRecipient(ctx, n.UserID)
+	if err != nil {
+		return "", err
+	}
+	preferences, err := u.notificationRepo.GetPreferences(ctx, n.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	// The preferences may have changed while the delivery waited for the quiet hours to end
+	channel, ok := u.channels[delivery.Channel]
+	if !ok || !u.wants(preferences, delivery.Channel, n.Type, len(recipient.PushSubscriptions)) {
+		reason := "disabled by the user's preferences"
+		if err := u.notificationRepo.MarkDeliveryFailed(ctx, delivery.DeliveryID, models.DeliveryStatusSkipped, reason, time.Now()); err != nil {
+			return "", err
+		}
+		return models.DeliveryStatusSkipped, nil
+	}
+
+	sendErr := channel.Send(ctx, recipient, n)
+	if sendErr == nil {
+		if err := u.notificationRepo.MarkDeliverySent(ctx, delivery.DeliveryID); err != nil {
+			return "", err
+		}
+		return models.DeliveryStatusSent, nil
+	}
+
+	maxAttempts := u.cfg.Notifications.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	attempts := delivery.Attempts + 1
+	status := models.DeliveryStatusPending
+	if attempts >= maxAttempts {
+		status = models.DeliveryStatusFailed
+		u.logger.Errorf("Notification delivery %s (%s) failed after %d attempts: %v", delivery.DeliveryID, delivery.Channel, attempts, sendErr)
+	}
+
+	if err := u.notificationRepo.MarkDeliveryFailed(ctx, delivery.DeliveryID, status, sendErr.Error(), time.Now().Add(u.retryDelay(attempts))); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// notify saves the notification of a domain event, the event redelivered does not notify again
+func (u *notificationUC) notify(
+	ctx context.Context,
+	userID uuid.UUID,
+	eventID uuid.UUID,
+	notificationType string,
+	title string,
+	body string,
+	data map[string]interface{},
+) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "notificationUC.notify.json.Marshal")
+	}
+
+	_, err = u.Notify(ctx, &models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Body:    body,
+		Data:    encoded,
+		EventID: &eventID,
+	})
+	return err
+}
+
+// wants reports whether the user's preferences let the channel send a notification of the type
+func (u *notificationUC) wants(preferences *models.NotificationPreferences, channel string, notificationType string, subscriptions int) bool {
+	if c, ok := u.channels[channel]; !ok || !c.Enabled() {
+		return false
+	}
+	for _, muted := range preferences.MutedTypes {
+		if muted == notificationType {
+			return false
+		}
+	}
+
+	switch channel {
+	case models.NotificationChannelEmail:
+		return preferences.EmailEnabled
+	case models.NotificationChannelPush:
+		return preferences.PushEnabled && subscriptions > 0
+	default:
+		return true
+	}
+}
+
+// retryDelay backs off exponentially, the first retry waits RetryDelay
+func (u *notificationUC) retryDelay(attempts int) time.Duration {
+	delay := u.cfg.Notifications.RetryDelay * time.Second
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// quietHoursEnd is the instant the quiet hours containing t end, or t when t is outside of them.
+// The hours are local to loc and wrap around midnight when start is after end
+func quietHoursEnd(t time.Time, loc *time.Location, start int, end int) time.Time {
+	local := t.In(loc)
+	hour := local.Hour()
+
+	quiet := start <= hour && hour < end
+	if start > end {
+		quiet = hour >= start || hour < end
+	}
+	if !quiet {
+		return t
+	}
+
+	y, m, d := local.Date()
+	endsAt := time.Date(y, m, d, end, 0, 0, 0, loc)
+	if !endsAt.After(local) {
+		endsAt = time.Date(y, m, d+1, end, 0, 0, 0, loc)
+	}
+	return endsAt
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+// NotificationWorker sends the due email and push deliveries
+type NotificationWorker struct {
+	notificationUC notification.UseCase
+	logger         logger.Logger
+	interval       time.Duration
+	stopCh         chan struct{}
+}
+
+// NewNotificationWorker creates a new notification delivery worker, interval is in seconds
+func NewNotificationWorker(notificationUC notification.UseCase, interval time.Duration, logger logger.Logger) *NotificationWorker {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	} else {
+		interval = interval * time.Second
+	}
+
+	return &NotificationWorker{
+		notificationUC: notificationUC,
+		logger:         logger,
+		interval:       interval,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start begins polling the due deliveries
+func (w *NotificationWorker) Start() {
+	w.logger.Info("Starting notification worker")
+
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		// Polls run one at a time, a slow SMTP server delays the next poll rather than piling them up
+		w.processDeliveries()
+		for {
+			select {
+			case <-ticker.C:
+				w.processDeliveries()
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts polling the due deliveries
+func (w *NotificationWorker) Stop() {
+	w.logger.Info("Stopping notification worker")
+	close(w.stopCh)
+}
+
+// processDeliveries sends a batch of due deliveries
+func (w *NotificationWorker) processDeliveries() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	report, err := w.notificationUC.ProcessDeliveries(ctx)
+	if err != nil {
+		w.logger.Errorf("Error processing notification deliveries: %v", err)
+		return
+	}
+
+	if report.Sent+report.Retried+report.Failed+report.Skipped > 0 {
+		w.logger.Infof("Notification deliveries sent: %d, retried: %d, failed: %d, skipped: %d",
+			report.Sent, report.Retried, report.Failed, report.Skipped)
+	}
+}
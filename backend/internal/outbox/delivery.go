@@ -0,0 +1,11 @@
+package outbox
+
+import "github.com/labstack/echo/v4"
+
+// Outbox HTTP Handlers interface
+type Handlers interface {
+	GetEvents() echo.HandlerFunc
+	GetEventByID() echo.HandlerFunc
+	ReplayEvent() echo.HandlerFunc
+	ReplayDeadEvents() echo.HandlerFunc
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/outbox"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type outboxHandlers struct {
+	outboxUC outbox.UseCase
+	logger   logger.Logger
+}
+
+func NewOutboxHandlers(outboxUC outbox.UseCase, logger logger.Logger) outbox.Handlers {
+	return &outboxHandlers{
+		outboxUC: outboxUC,
+		logger:   logger,
+	}
+}
+
+// GetEvents godoc
+// @Summary List outbox events
+// @Description List the outbox events, newest first, optionally filtered by status
+// @Tags Outbox
+// @Produce json
+// @Param status query string false "pending, delivered or dead"
+// @Param limit query int false "Page size (default: 50, max: 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.OutboxEventList
+// @Router /outbox/admin/events [get]
+func (h *outboxHandlers) GetEvents() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "outboxHandlers.GetEvents.pageParams"))
+		}
+
+		list, err := h.outboxUC.GetEvents(c.Request().Context(), c.QueryParam("status"), limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "outboxHandlers.GetEvents.GetEvents"))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetEventByID godoc
+// @Summary Get an outbox event
+// @Description Get an outbox event with the subscribers that already handled it
+// @Tags Outbox
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {object} models.OutboxEvent
+// @Failure 404 {object} httpErrors.RestError
+// @Router /outbox/admin/events/{id} [get]
+func (h *outboxHandlers) GetEventByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eventID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "outboxHandlers.GetEventByID.Parse"))
+		}
+
+		event, err := h.outboxUC.GetEventByID(c.Request().Context(), eventID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return httpErrors.NewNotFoundError(errors.Wrap(err, "outboxHandlers.GetEventByID.GetEventByID"))
+			}
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "outboxHandlers.GetEventByID.GetEventByID"))
+		}
+
+		return c.JSON(http.StatusOK, event)
+	}
+}
+
+// ReplayEvent godoc
+// @Summary Replay a dead outbox event
+// @Description Put a dead-lettered event back in the queue, subscribers that already handled it are skipped
+// @Tags Outbox
+// @Param id path string true "Event ID"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /outbox/admin/events/{id}/replay [post]
+func (h *outboxHandlers) ReplayEvent() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eventID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "outboxHandlers.ReplayEvent.Parse"))
+		}
+
+		if err := h.outboxUC.ReplayEvent(c.Request().Context(), eventID); err != nil {
+			if errors.Is(err, outbox.ErrEventNotDead) {
+				return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				return httpErrors.NewNotFoundError(errors.Wrap(err, "outboxHandlers.ReplayEvent.ReplayEvent"))
+			}
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "outboxHandlers.ReplayEvent.ReplayEvent"))
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ReplayDeadEvents godoc
+// @Summary Replay all dead outbox events
+// @Description Put every dead-lettered event back in the queue
+// @Tags Outbox
+// @Produce json
+// @Success 200 {object} models.OutboxReplayResult
+// @Router /outbox/admin/replay [post]
+func (h *outboxHandlers) ReplayDeadEvents() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		result, err := h.outboxUC.ReplayDeadEvents(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "outboxHandlers.ReplayDeadEvents.ReplayDeadEvents"))
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+func pageParams(c echo.Context) (int, int, error) {
+	var err error
+	limit, offset := 0, 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
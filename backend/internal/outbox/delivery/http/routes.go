@@ -0,0 +1,25 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/outbox"
+)
+
+// Map outbox routes
+func MapOutboxRoutes(outboxGroup *echo.Group, h outbox.Handlers, mw *middleware.MiddlewareManager) {
+	protected := outboxGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionEventsManage))
+		{
+			admin.GET("/events", h.GetEvents())
+			admin.GET("/events/:id", h.GetEventByID())
+			admin.POST("/events/:id/replay", h.ReplayEvent())
+			admin.POST("/replay", h.ReplayDeadEvents())
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package outbox
+
+import "errors"
+
+// Outbox replay errors
+var ErrEventNotDead = errors.New("only dead events can be replayed")
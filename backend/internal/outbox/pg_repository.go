@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Outbox Repository interface
+type Repository interface {
+	events.Recorder
+
+	// ClaimDue leases due pending events so concurrent workers skip them until the lease expires
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+	GetDeliveries(ctx context.Context, eventID uuid.UUID) ([]*models.OutboxDelivery, error)
+	CreateDelivery(ctx context.Context, eventID uuid.UUID, subscriber string) error
+	MarkDelivered(ctx context.Context, eventID uuid.UUID) error
+	MarkFailed(ctx context.Context, eventID uuid.UUID, status string, lastError string, nextAttemptAt time.Time) error
+	PurgeDelivered(ctx context.Context, before time.Time) (int64, error)
+
+	GetEvents(ctx context.Context, status string, limit int, offset int) (*models.OutboxEventList, error)
+	GetEventByID(ctx context.Context, eventID uuid.UUID) (*models.OutboxEvent, error)
+	ReplayEvent(ctx context.Context, eventID uuid.UUID) (bool, error)
+	ReplayDeadEvents(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/outbox"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultEventsLimit = 50
+
+type outboxRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewOutboxRepository(db *sqlx.DB, logger logger.Logger) outbox.Repository {
+	return &outboxRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *outboxRepo) Record(ctx context.Context, tx sqlx.ExecerContext, userID uuid.UUID, payload events.Payload) error {
+	event, err := events.NewEvent(userID, payload)
+	if err != nil {
+		return errors.Wrap(err, "outboxRepo.Record.NewEvent")
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		recordEventQuery,
+		event.EventID,
+		event.Type,
+		event.UserID,
+		string(event.Payload),
+		event.OccurredAt,
+	); err != nil {
+		return errors.Wrap(err, "outboxRepo.Record.ExecContext")
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	claimed := make([]*models.OutboxEvent, 0, limit)
+	if err := r.db.SelectContext(ctx, &claimed, claimDueEventsQuery, limit, lease.Seconds()); err != nil {
+		return nil, errors.Wrap(err, "outboxRepo.ClaimDue.SelectContext")
+	}
+	return claimed, nil
+}
+
+func (r *outboxRepo) GetDeliveries(ctx context.Context, eventID uuid.UUID) ([]*models.OutboxDelivery, error) {
+	deliveries := make([]*models.OutboxDelivery, 0)
+	if err := r.db.SelectContext(ctx, &deliveries, getDeliveriesQuery, eventID); err != nil {
+		return nil, errors.Wrap(err, "outboxRepo.GetDeliveries.SelectContext")
+	}
+	return deliveries, nil
+}
+
+func (r *outboxRepo) CreateDelivery(ctx context.Context, eventID uuid.UUID, subscriber string) error {
+	if _, err := r.db.ExecContext(ctx, createDeliveryQuery, eventID, subscriber); err != nil {
+		return errors.Wrap(err, "outboxRepo.CreateDelivery.ExecContext")
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkDelivered(ctx context.Context, eventID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, markDeliveredQuery, eventID); err != nil {
+		return errors.Wrap(err, "outboxRepo.MarkDelivered.ExecContext")
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, eventID uuid.UUID, status string, lastError string, nextAttemptAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, markFailedQuery, eventID, status, lastError, nextAttemptAt); err != nil {
+		return errors.Wrap(err, "outboxRepo.MarkFailed.ExecContext")
+	}
+	return nil
+}
+
+func (r *outboxRepo) PurgeDelivered(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, purgeDeliveredQuery, before)
+	if err != nil {
+		return 0, errors.Wrap(err, "outboxRepo.PurgeDelivered.ExecContext")
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "outboxRepo.PurgeDelivered.RowsAffected")
+	}
+	return purged, nil
+}
+
+func (r *outboxRepo) GetEvents(ctx context.Context, status string, limit int, offset int) (*models.OutboxEventList, error) {
+	if limit <= 0 {
+		limit = defaultEventsLimit
+	}
+
+	list := &models.OutboxEventList{Events: make([]*models.OutboxEvent, 0)}
+	if err := r.db.GetContext(ctx, &list.TotalCount, countEventsQuery, status); err != nil {
+		return nil, errors.Wrap(err, "outboxRepo.GetEvents.count")
+	}
+	if list.TotalCount == 0 {
+		return list, nil
+	}
+
+	if err := r.db.SelectContext(ctx, &list.Events, getEventsQuery, status, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "outboxRepo.GetEvents.SelectContext")
+	}
+
+	return list, nil
+}
+
+func (r *outboxRepo) GetEventByID(ctx context.Context, eventID uuid.UUID) (*models.OutboxEvent, error) {
+	event := &models.OutboxEvent{}
+	if err := r.db.GetContext(ctx, event, getEventByIDQuery, eventID); err != nil {
+		return nil, errors.Wrap(err, "outboxRepo.GetEventByID.GetContext")
+	}
+	return event, nil
+}
+
+func (r *outboxRepo) ReplayEvent(ctx context.Context, eventID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, replayEventQuery, eventID)
+	if err != nil {
+		return false, errors.Wrap(err, "outboxRepo.ReplayEvent.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "outboxRepo.ReplayEvent.RowsAffected")
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *outboxRepo) ReplayDeadEvents(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, replayDeadEventsQuery)
+	if err != nil {
+		return 0, errors.Wrap(err, "outboxRepo.ReplayDeadEvents.ExecContext")
+	}
+	replayed, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "outboxRepo.ReplayDeadEvents.RowsAffected")
+	}
+	return replayed, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+const (
+	recordEventQuery = `
+		INSERT INTO outbox_events (event_id, event_type, user_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	claimDueEventsQuery = `
+		UPDATE outbox_events
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE event_id IN (
+			SELECT event_id FROM outbox_events
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	getDeliveriesQuery = `
+		SELECT * FROM outbox_deliveries WHERE event_id = $1 ORDER BY delivered_at
+	`
+
+	createDeliveryQuery = `
+		INSERT INTO outbox_deliveries (event_id, subscriber)
+		VALUES ($1, $2)
+		ON CONFLICT (event_id, subscriber) DO NOTHING
+	`
+
+	markDeliveredQuery = `
+		UPDATE outbox_events
+		SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+		WHERE event_id = $1
+	`
+
+	markFailedQuery = `
+		UPDATE outbox_events
+		SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+		WHERE event_id = $1
+	`
+
+	purgeDeliveredQuery = `
+		DELETE FROM outbox_events WHERE status = 'delivered' AND delivered_at < $1
+	`
+
+	countEventsQuery = `
+		SELECT COUNT(*) FROM outbox_events WHERE ($1 = '' OR status = $1)
+	`
+
+	getEventsQuery = `
+		SELECT * FROM outbox_events
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY occurred_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	getEventByIDQuery = `
+		SELECT * FROM outbox_events WHERE event_id = $1
+	`
+
+	replayEventQuery = `
+		UPDATE outbox_events
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE event_id = $1 AND status = 'dead'
+	`
+
+	replayDeadEventsQuery = `
+		UPDATE outbox_events
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE status = 'dead'
+	`
+)
@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Outbox UseCase interface
+type UseCase interface {
+	// Worker operations
+	DispatchDue(ctx context.Context) (*models.OutboxDispatchReport, error)
+	PurgeDelivered(ctx context.Context) (int64, error)
+
+	// Admin operations
+	GetEvents(ctx context.Context, status string, limit int, offset int) (*models.OutboxEventList, error)
+	GetEventByID(ctx context.Context, eventID uuid.UUID) (*models.OutboxEvent, error)
+	ReplayEvent(ctx context.Context, eventID uuid.UUID) error
+	ReplayDeadEvents(ctx context.Context) (*models.OutboxReplayResult, error)
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/outbox"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultBatchSize     = 50
+	defaultMaxAttempts   = 8
+	defaultRetryDelay    = 500 * time.Millisecond
+	defaultRetentionDays = 7
+	maxRetryDelay        = time.Hour
+	maxEventsLimit       = 100
+
+	// Claimed events are hidden from other workers for this long, so a crashed worker's batch is picked up again
+	claimLease = 5 * time.Minute
+)
+
+type outboxUC struct {
+	cfg        *config.Config
+	outboxRepo outbox.Repository
+	bus        events.Bus
+	logger     logger.Logger
+}
+
+func NewOutboxUseCase(cfg *config.Config, outboxRepo outbox.Repository, bus events.Bus, logger logger.Logger) outbox.UseCase {
+	return &outboxUC{
+		cfg:        cfg,
+		outboxRepo: outboxRepo,
+		bus:        bus,
+		logger:     logger,
+	}
+}
+
+func (u *outboxUC) DispatchDue(ctx context.Context) (*models.OutboxDispatchReport, error) {
+	batchSize := u.cfg.Events.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	claimed, err := u.outboxRepo.ClaimDue(ctx, batchSize, claimLease)
+	if err != nil {
+		return nil, errors.Wrap(err, "outboxUC.DispatchDue.ClaimDue")
+	}
+
+	report := &models.OutboxDispatchReport{Claimed: len(claimed)}
+	for _, outboxEvent := range claimed {
+		status, err := u.dispatchEvent(ctx, outboxEvent)
+		if err != nil {
+			u.logger.Errorf("Error dispatching outbox event %s: %v", outboxEvent.EventID, err)
+			continue
+		}
+
+		switch status {
+		case models.OutboxStatusDelivered:
+			report.Delivered++
+		case models.OutboxStatusDead:
+			report.DeadLettered++
+		default:
+			report.Retried++
+		}
+	}
+
+	return report, nil
+}
+
+// dispatchEvent delivers an event to the subscribers that have not handled it yet and returns its new status
+func (u *outboxUC) dispatchEvent(ctx context.Context, outboxEvent *models.OutboxEvent) (string, error) {
+	// Subscribers that handled a previous attempt are the idempotency keys of the event
+	deliveries, err := u.outboxRepo.GetDeliveries(ctx, outboxEvent.EventID)
+	if err != nil {
+		return "", errors.Wrap(err, "outboxUC.dispatchEvent.GetDeliveries")
+	}
+	skip := make(map[string]bool, len(deliveries))
+	for _, delivery := range deliveries {
+		skip[delivery.Subscriber] = true
+	}
+
+	event := &events.Event{
+		EventID:    outboxEvent.EventID,
+		Type:       outboxEvent.EventType,
+		UserID:     outboxEvent.UserID,
+		Payload:    []byte(outboxEvent.Payload),
+		OccurredAt: outboxEvent.OccurredAt,
+	}
+
+	failures := make([]string, 0)
+	for subscriber, handlerErr := range u.bus.Dispatch(event, skip) {
+		if handlerErr != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", subscriber, handlerErr))
+			continue
+		}
+		if err := u.outboxRepo.CreateDelivery(ctx, outboxEvent.EventID, subscriber); err != nil {
+			return "", errors.Wrap(err, "outboxUC.dispatchEvent.CreateDelivery")
+		}
+	}
+
+	if len(failures) == 0 {
+		if err := u.outboxRepo.MarkDelivered(ctx, outboxEvent.EventID); err != nil {
+			return "", errors.Wrap(err, "outboxUC.dispatchEvent.MarkDelivered")
+		}
+		return models.OutboxStatusDelivered, nil
+	}
+	sort.Strings(failures)
+
+	maxAttempts := u.cfg.Events.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	attempts := outboxEvent.Attempts + 1
+	status := models.OutboxStatusPending
+	if attempts >= maxAttempts {
+		status = models.OutboxStatusDead
+		u.logger.Errorf("Outbox event %s (%s) dead-lettered after %d attempts", event.EventID, event.Type, attempts)
+	}
+
+	nextAttemptAt := time.Now().Add(u.retryDelay(attempts))
+	if err := u.outboxRepo.MarkFailed(ctx, outboxEvent.EventID, status, strings.Join(failures, "; "), nextAttemptAt); err != nil {
+		return "", errors.Wrap(err, "outboxUC.dispatchEvent.MarkFailed")
+	}
+
+	return status, nil
+}
+
+// retryDelay backs off exponentially, the first retry waits RetryDelay
+func (u *outboxUC) retryDelay(attempts int) time.Duration {
+	delay := u.cfg.Events.RetryDelay * time.Millisecond
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+func (u *outboxUC) PurgeDelivered(ctx context.Context) (int64, error) {
+	retentionDays := u.cfg.Events.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = defaultRetentionDays
+	}
+	return u.outboxRepo.PurgeDelivered(ctx, time.Now().AddDate(0, 0, -retentionDays))
+}
+
+func (u *outboxUC) GetEvents(ctx context.Context, status string, limit int, offset int) (*models.OutboxEventList, error) {
+	switch status {
+	case "", models.OutboxStatusPending, models.OutboxStatusDelivered, models.OutboxStatusDead:
+	default:
+		return nil, errors.Errorf("unknown outbox status %q", status)
+	}
+	if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+	return u.outboxRepo.GetEvents(ctx, status, limit, offset)
+}
+
+func (u *outboxUC) GetEventByID(ctx context.Context, eventID uuid.UUID) (*models.OutboxEvent, error) {
+	event, err := u.outboxRepo.GetEventByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Deliveries, err = u.outboxRepo.GetDeliveries(ctx, eventID); err != nil {
+		return nil, errors.Wrap(err, "outboxUC.GetEventByID.GetDeliveries")
+	}
+
+	return event, nil
+}
+
+func (u *outboxUC) ReplayEvent(ctx context.Context, eventID uuid.UUID) error {
+	replayed, err := u.outboxRepo.ReplayEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if replayed {
+		return nil
+	}
+
+	// Tell a missing event apart from one that is not dead
+	if _, err := u.outboxRepo.GetEventByID(ctx, eventID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.Wrap(err, "outboxUC.ReplayEvent.GetEventByID")
+		}
+		return err
+	}
+	return outbox.ErrEventNotDead
+}
+
+func (u *outboxUC) ReplayDeadEvents(ctx context.Context) (*models.OutboxReplayResult, error) {
+	replayed, err := u.outboxRepo.ReplayDeadEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if replayed > 0 {
+		u.logger.Infof("Replaying %d dead outbox events", replayed)
+	}
+	return &models.OutboxReplayResult{Replayed: replayed}, nil
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/outbox"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	defaultPollInterval = time.Second
+	purgeInterval       = time.Hour
+)
+
+// OutboxWorker delivers the due outbox events to the bus subscribers and purges the delivered ones
+type OutboxWorker struct {
+	outboxUC outbox.UseCase
+	logger   logger.Logger
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewOutboxWorker creates a new outbox dispatcher worker, interval is in milliseconds
+func NewOutboxWorker(outboxUC outbox.UseCase, interval time.Duration, logger logger.Logger) *OutboxWorker {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	} else {
+		interval = interval * time.Millisecond
+	}
+
+	return &OutboxWorker{
+		outboxUC: outboxUC,
+		logger:   logger,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins polling the outbox
+func (w *OutboxWorker) Start() {
+	w.logger.Info("Starting outbox worker")
+
+	ticker := time.NewTicker(w.interval)
+	purgeTicker := time.NewTicker(purgeInterval)
+	go func() {
+		// Polls run one at a time so an event is never dispatched twice by the same instance
+		w.dispatchDue()
+		for {
+			select {
+			case <-ticker.C:
+				w.dispatchDue()
+			case <-purgeTicker.C:
+				w.purgeDelivered()
+			case <-w.stopCh:
+				ticker.Stop()
+				purgeTicker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts polling the outbox
+func (w *OutboxWorker) Stop() {
+	w.logger.Info("Stopping outbox worker")
+	close(w.stopCh)
+}
+
+// dispatchDue drains the due events, it polls again right away while every claimed event was delivered
+func (w *OutboxWorker) dispatchDue() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		report, err := w.outboxUC.DispatchDue(ctx)
+		cancel()
+		if err != nil {
+			w.logger.Errorf("Error dispatching outbox events: %v", err)
+			return
+		}
+		if report.Claimed == 0 {
+			return
+		}
+
+		if report.Retried > 0 || report.DeadLettered > 0 {
+			w.logger.Infof("Outbox events claimed: %d, delivered: %d, retried: %d, dead-lettered: %d",
+				report.Claimed, report.Delivered, report.Retried, report.DeadLettered)
+		}
+		if report.Delivered < report.Claimed {
+			return
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		default:
+		}
+	}
+}
+
+// purgeDelivered removes the delivered events past the retention period
+func (w *OutboxWorker) purgeDelivered() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	purged, err := w.outboxUC.PurgeDelivered(ctx)
+	if err != nil {
+		w.logger.Errorf("Error purging delivered outbox events: %v", err)
+		return
+	}
+	if purged > 0 {
+		w.logger.Infof("Delivered outbox events purged: %d", purged)
+	}
+}
@@ -0,0 +1,12 @@
+package quest
+
+import "github.com/labstack/echo/v4"
+
+// Quest HTTP Handlers interface
+type Handlers interface {
+	GetQuests() echo.HandlerFunc
+	CreateTemplate() echo.HandlerFunc
+	UpdateTemplate() echo.HandlerFunc
+	GetTemplates() echo.HandlerFunc
+	ProcessQuests() echo.HandlerFunc
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/quest"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type questHandlers struct {
+	questUC quest.UseCase
+	logger  logger.Logger
+}
+
+func NewQuestHandlers(questUC quest.UseCase, logger logger.Logger) quest.Handlers {
+	return &questHandlers{
+		questUC: questUC,
+		logger:  logger,
+	}
+}
+
+// GetQuests godoc
+// @Summary Get my quests
+// @Description The quests of my current day and week, drawn from the active templates on first access
+// @Tags Quests
+// @Produce json
+// @Success 200 {object} models.QuestBoard
+// @Router /quests [get]
+func (h *questHandlers) GetQuests() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "questHandlers.GetQuests.GetUserIDFromContext"))
+		}
+
+		board, err := h.questUC.GetQuests(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.GetQuests.GetQuests"))
+		}
+
+		return c.JSON(http.StatusOK, board)
+	}
+}
+
+// CreateTemplate godoc
+// @Summary Create a quest template
+// @Description Users draw their next quests from the active templates of each period
+// @Tags Quests
+// @Accept json
+// @Produce json
+// @Param body body models.QuestTemplate true "Quest template"
+// @Success 201 {object} models.QuestTemplate
+// @Router /quests/admin/templates [post]
+func (h *questHandlers) CreateTemplate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		template := &models.QuestTemplate{}
+		if err := c.Bind(template); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.CreateTemplate.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), template); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.CreateTemplate.ValidateStruct"))
+		}
+
+		created, err := h.questUC.CreateTemplate(c.Request().Context(), template)
+		if err != nil {
+			return questError(err, "questHandlers.CreateTemplate.CreateTemplate")
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// UpdateTemplate godoc
+// @Summary Update a quest template
+// @Description Changes apply to the quests drawn afterwards, is_active false stops drawing the template
+// @Tags Quests
+// @Accept json
+// @Produce json
+// @Param template_id path string true "Template ID"
+// @Param body body models.QuestTemplate true "Quest template"
+// @Success 200 {object} models.QuestTemplate
+// @Failure 404 {object} httpErrors.RestError
+// @Router /quests/admin/templates/{template_id} [put]
+func (h *questHandlers) UpdateTemplate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		templateID, err := uuid.Parse(c.Param("template_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.UpdateTemplate.uuid.Parse"))
+		}
+
+		template := &models.QuestTemplate{}
+		if err := c.Bind(template); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.UpdateTemplate.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), template); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.UpdateTemplate.ValidateStruct"))
+		}
+		template.TemplateID = templateID
+
+		updated, err := h.questUC.UpdateTemplate(c.Request().Context(), template)
+		if err != nil {
+			return questError(err, "questHandlers.UpdateTemplate.UpdateTemplate")
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
+
+// GetTemplates godoc
+// @Summary List the quest templates
+// @Description Every template, active or not, daily ones first
+// @Tags Quests
+// @Produce json
+// @Success 200 {array} models.QuestTemplate
+// @Router /quests/admin/templates [get]
+func (h *questHandlers) GetTemplates() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		templates, err := h.questUC.GetTemplates(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.GetTemplates.GetTemplates"))
+		}
+
+		return c.JSON(http.StatusOK, templates)
+	}
+}
+
+// ProcessQuests godoc
+// @Summary Run the quest job
+// @Description Expire the quests whose period ended and pay the rewards that failed, the quest worker runs it on every tick
+// @Tags Quests
+// @Produce json
+// @Success 200 {object} models.QuestJobReport
+// @Router /quests/admin/process [post]
+func (h *questHandlers) ProcessQuests() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report, err := h.questUC.ProcessQuests(c.Request().Context())
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questHandlers.ProcessQuests.ProcessQuests"))
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+func questError(err error, op string) error {
+	switch {
+	case errors.Is(err, quest.ErrTemplateNotFound):
+		return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+	default:
+		return httpErrors.NewBadRequestError(errors.Wrap(err, op))
+	}
+}
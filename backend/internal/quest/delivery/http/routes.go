@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/quest"
+)
+
+// Map quest routes
+func MapQuestRoutes(questGroup *echo.Group, h quest.Handlers, mw *middleware.MiddlewareManager) {
+	protected := questGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("", h.GetQuests())
+
+		admin := protected.Group("/admin")
+		{
+			admin.GET("/templates", h.GetTemplates(), mw.RequirePermission(models.PermissionQuestsManage))
+			admin.POST("/templates", h.CreateTemplate(), mw.RequirePermission(models.PermissionQuestsManage))
+			admin.PUT("/templates/:template_id", h.UpdateTemplate(), mw.RequirePermission(models.PermissionQuestsManage))
+			admin.POST("/process", h.ProcessQuests(), mw.RequirePermission(models.PermissionJobsRun))
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package quest
+
+import "errors"
+
+// Quest errors
+var (
+	ErrTemplateNotFound = errors.New("quest template not found")
+	ErrMinScoreMetric   = errors.New("min_score only applies to quizzes_completed quests")
+)
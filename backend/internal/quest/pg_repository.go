@@ -0,0 +1,37 @@
+package quest
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Quest Repository interface
+type Repository interface {
+	// Templates
+	CreateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error)
+	UpdateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error)
+	GetTemplates(ctx context.Context) ([]*models.QuestTemplate, error)
+
+	// Activity context
+	GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error)
+	GetQuizSubject(ctx context.Context, quizID uuid.UUID) (string, int, error)
+	GetLessonSubject(ctx context.Context, lessonID uuid.UUID) (string, int, error)
+
+	// Draws up to count active templates of the period for the user, only the first call for a period does
+	GenerateQuests(ctx context.Context, userID uuid.UUID, period string, periodStart time.Time, startsAt time.Time, expiresAt time.Time, count int) error
+	// Quests whose period contains at
+	GetUserQuests(ctx context.Context, userID uuid.UUID, at time.Time) ([]*models.Quest, error)
+	// Counts the activity on the matching active quests, at most once per quest, and returns the quests it completed
+	AdvanceQuests(ctx context.Context, userID uuid.UUID, activity *models.QuestActivity) ([]*models.Quest, error)
+
+	// Completed quests whose reward was not paid yet
+	GetUnrewardedQuests(ctx context.Context, limit int) ([]*models.Quest, error)
+	// Marks the quest rewarded and grants the freezes, capped by maxFreezes, reports false when it already was
+	RewardQuest(ctx context.Context, quest *models.Quest, freezes int, maxFreezes int) (bool, error)
+	// Expires the active quests whose period ended before now
+	ExpireQuests(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/quest"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultUnrewardedLimit = 100
+
+type questRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewQuestRepository(db *sqlx.DB, logger logger.Logger) quest.Repository {
+	return &questRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *questRepo) CreateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error) {
+	created := &models.QuestTemplate{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createTemplateQuery,
+		template.Title,
+		template.Period,
+		template.Metric,
+		template.Target,
+		template.Subject,
+		template.MinScore,
+		template.RewardType,
+		template.RewardAmount,
+		template.IsActive,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "questRepo.CreateTemplate.StructScan")
+	}
+	return created, nil
+}
+
+func (r *questRepo) UpdateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error) {
+	updated := &models.QuestTemplate{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		updateTemplateQuery,
+		template.TemplateID,
+		template.Title,
+		template.Period,
+		template.Metric,
+		template.Target,
+		template.Subject,
+		template.MinScore,
+		template.RewardType,
+		template.RewardAmount,
+		template.IsActive,
+	).StructScan(updated); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, quest.ErrTemplateNotFound
+		}
+		return nil, errors.Wrap(err, "questRepo.UpdateTemplate.StructScan")
+	}
+	return updated, nil
+}
+
+func (r *questRepo) GetTemplates(ctx context.Context) ([]*models.QuestTemplate, error) {
+	templates := make([]*models.QuestTemplate, 0)
+	if err := r.db.SelectContext(ctx, &templates, getTemplatesQuery); err != nil {
+		return nil, errors.Wrap(err, "questRepo.GetTemplates.SelectContext")
+	}
+	return templates, nil
+}
+
+func (r *questRepo) GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error) {
+	var timezone string
+	if err := r.db.GetContext(ctx, &timezone, getUserTimezoneQuery, userID); err != nil {
+		return "", errors.Wrap(err, "questRepo.GetUserTimezone.GetContext")
+	}
+	return timezone, nil
+}
+
+func (r *questRepo) GetQuizSubject(ctx context.Context, quizID uuid.UUID) (string, int, error) {
+	var subject string
+	var grade int
+	if err := r.db.QueryRowxContext(ctx, getQuizSubjectQuery, quizID).Scan(&subject, &grade); err != nil {
+		return "", 0, errors.Wrap(err, "questRepo.GetQuizSubject.Scan")
+	}
+	return subject, grade, nil
+}
+
+func (r *questRepo) GetLessonSubject(ctx context.Context, lessonID uuid.UUID) (string, int, error) {
+	var subject string
+	var grade int
+	if err := r.db.QueryRowxContext(ctx, getLessonSubjectQuery, lessonID).Scan(&subject, &grade); err != nil {
+		return "", 0, errors.Wrap(err, "questRepo.GetLessonSubject.Scan")
+	}
+	return subject, grade, nil
+}
+
+func (r *questRepo) GenerateQuests(
+	ctx context.Context,
+	userID uuid.UUID,
+	period string,
+	periodStart time.Time,
+	startsAt time.Time,
+	expiresAt time.Time,
+	count int,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "questRepo.GenerateQuests.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, createQuestPeriodQuery, userID, period, periodStart)
+	if err != nil {
+		return errors.Wrap(err, "questRepo.GenerateQuests.createQuestPeriod")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "questRepo.GenerateQuests.RowsAffected")
+	}
+	// Already generated, possibly by a concurrent request waiting on the same period row
+	if rowsAffected == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, createQuestsQuery, userID, period, periodStart, startsAt, expiresAt, count); err != nil {
+		return errors.Wrap(err, "questRepo.GenerateQuests.createQuests")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "questRepo.GenerateQuests.Commit")
+	}
+
+	return nil
+}
+
+func (r *questRepo) GetUserQuests(ctx context.Context, userID uuid.UUID, at time.Time) ([]*models.Quest, error) {
+	quests := make([]*models.Quest, 0)
+	if err := r.db.SelectContext(ctx, &quests, getUserQuestsQuery, userID, at); err != nil {
+		return nil, errors.Wrap(err, "questRepo.GetUserQuests.SelectContext")
+	}
+	return quests, nil
+}
+
+func (r *questRepo) AdvanceQuests(ctx context.Context, userID uuid.UUID, activity *models.QuestActivity) ([]*models.Quest, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "questRepo.AdvanceQuests.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	quests := make([]*models.Quest, 0)
+	if err := tx.SelectContext(
+		ctx,
+		&quests,
+		lockMatchingQuestsQuery,
+		userID,
+		activity.At,
+		activity.Metric,
+		activity.Subject,
+		activity.Score,
+	); err != nil {
+		return nil, errors.Wrap(err, "questRepo.AdvanceQuests.lockMatchingQuests")
+	}
+
+	completed := make([]*models.Quest, 0)
+	for _, q := range quests {
+		result, err := tx.ExecContext(ctx, createQuestEventQuery, q.QuestID, activity.EventID)
+		if err != nil {
+			return nil, errors.Wrap(err, "questRepo.AdvanceQuests.createQuestEvent")
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, errors.Wrap(err, "questRepo.AdvanceQuests.RowsAffected")
+		}
+		// Already counted when the event was delivered before
+		if rowsAffected == 0 {
+			continue
+		}
+
+		q.Progress++
+		if q.Progress >= q.Target {
+			completedAt := activity.At
+			q.Status = models.QuestStatusCompleted
+			q.CompletedAt = &completedAt
+			q.RewardSubject = &activity.Subject
+			q.RewardGrade = &activity.Grade
+			completed = append(completed, q)
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			updateQuestProgressQuery,
+			q.QuestID,
+			q.Progress,
+			q.Status,
+			q.CompletedAt,
+			q.RewardSubject,
+			q.RewardGrade,
+		); err != nil {
+			return nil, errors.Wrap(err, "questRepo.AdvanceQuests.updateQuestProgress")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "questRepo.AdvanceQuests.Commit")
+	}
+
+	return completed, nil
+}
+
+func (r *questRepo) GetUnrewardedQuests(ctx context.Context, limit int) ([]*models.Quest, error) {
+	if limit <= 0 {
+		limit = defaultUnrewardedLimit
+	}
+
+	quests := make([]*models.Quest, 0)
+	if err := r.db.SelectContext(ctx, &quests, getUnrewardedQuestsQuery, limit); err != nil {
+		return nil, errors.Wrap(err, "questRepo.GetUnrewardedQuests.SelectContext")
+	}
+	return quests, nil
+}
+
+func (r *questRepo) RewardQuest(ctx context.Context, q *models.Quest, freezes int, maxFreezes int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "questRepo.RewardQuest.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, markQuestRewardedQuery, q.QuestID)
+	if err != nil {
+		return false, errors.Wrap(err, "questRepo.RewardQuest.markQuestRewarded")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "questRepo.RewardQuest.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if freezes > 0 {
+		if _, err := tx.ExecContext(ctx, ensureStreakQuery, q.UserID); err != nil {
+			return false, errors.Wrap(err, "questRepo.RewardQuest.ensureStreak")
+		}
+		if _, err := tx.ExecContext(ctx, addStreakFreezesQuery, freezes, maxFreezes, q.UserID); err != nil {
+			return false, errors.Wrap(err, "questRepo.RewardQuest.addStreakFreezes")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.Wrap(err, "questRepo.RewardQuest.Commit")
+	}
+
+	return true, nil
+}
+
+func (r *questRepo) ExpireQuests(ctx context.Context, now time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, expireQuestsQuery, now)
+	if err != nil {
+		return 0, errors.Wrap(err, "questRepo.ExpireQuests.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "questRepo.ExpireQuests.RowsAffected")
+	}
+	return int(rowsAffected), nil
+}
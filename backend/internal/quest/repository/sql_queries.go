@@ -0,0 +1,114 @@
+package repository
+
+const (
+	createTemplateQuery = `
+		INSERT INTO quest_templates (title, period, metric, target, subject, min_score, reward_type, reward_amount, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING *
+	`
+
+	updateTemplateQuery = `
+		UPDATE quest_templates
+		SET title = $2, period = $3, metric = $4, target = $5, subject = $6, min_score = $7,
+			reward_type = $8, reward_amount = $9, is_active = $10, updated_at = CURRENT_TIMESTAMP
+		WHERE template_id = $1
+		RETURNING *
+	`
+
+	getTemplatesQuery = `SELECT * FROM quest_templates ORDER BY period, created_at, template_id`
+
+	getUserTimezoneQuery = `SELECT timezone FROM users WHERE user_id = $1`
+
+	getQuizSubjectQuery = `
+		SELECT l.subject, l.grade FROM quizzes qz
+		JOIN lessons l ON l.lesson_id = qz.lesson_id
+		WHERE qz.quiz_id = $1
+	`
+
+	getLessonSubjectQuery = `SELECT subject, grade FROM lessons WHERE lesson_id = $1`
+
+	createQuestPeriodQuery = `
+		INSERT INTO user_quest_periods (user_id, period, period_start)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, period, period_start) DO NOTHING
+	`
+
+	// Every user gets their own random draw of the active templates
+	createQuestsQuery = `
+		INSERT INTO user_quests (user_id, template_id, period_start, starts_at, expires_at, target)
+		SELECT $1, template_id, $3, $4, $5, target
+		FROM quest_templates
+		WHERE is_active AND period = $2
+		ORDER BY random()
+		LIMIT $6
+		ON CONFLICT (user_id, template_id, period_start) DO NOTHING
+	`
+
+	questColumns = `
+		uq.*, t.title, t.period, t.metric, t.subject, t.min_score, t.reward_type, t.reward_amount
+	`
+
+	getUserQuestsQuery = `
+		SELECT ` + questColumns + `
+		FROM user_quests uq
+		JOIN quest_templates t ON t.template_id = uq.template_id
+		WHERE uq.user_id = $1 AND uq.starts_at <= $2 AND uq.expires_at > $2
+		ORDER BY uq.created_at, t.title
+	`
+
+	// Quests of the period containing the activity that it counts towards
+	lockMatchingQuestsQuery = `
+		SELECT ` + questColumns + `
+		FROM user_quests uq
+		JOIN quest_templates t ON t.template_id = uq.template_id
+		WHERE uq.user_id = $1 AND uq.status = 'active' AND uq.starts_at <= $2 AND uq.expires_at > $2
+			AND t.metric = $3
+			AND (t.subject IS NULL OR t.subject = $4)
+			AND (t.min_score IS NULL OR t.min_score <= $5)
+		ORDER BY uq.quest_id
+		FOR UPDATE OF uq
+	`
+
+	createQuestEventQuery = `
+		INSERT INTO user_quest_events (quest_id, event_id)
+		VALUES ($1, $2)
+		ON CONFLICT (quest_id, event_id) DO NOTHING
+	`
+
+	updateQuestProgressQuery = `
+		UPDATE user_quests
+		SET progress = $2, status = $3, completed_at = $4, reward_subject = $5, reward_grade = $6
+		WHERE quest_id = $1
+	`
+
+	getUnrewardedQuestsQuery = `
+		SELECT ` + questColumns + `
+		FROM user_quests uq
+		JOIN quest_templates t ON t.template_id = uq.template_id
+		WHERE uq.status = 'completed' AND uq.rewarded_at IS NULL
+		ORDER BY uq.completed_at
+		LIMIT $1
+	`
+
+	markQuestRewardedQuery = `
+		UPDATE user_quests SET rewarded_at = CURRENT_TIMESTAMP
+		WHERE quest_id = $1 AND rewarded_at IS NULL
+	`
+
+	// Users who never kept a streak have no row yet
+	ensureStreakQuery = `
+		INSERT INTO daily_streaks (user_id, current_streak, max_streak, last_activity)
+		VALUES ($1, 0, 0, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+
+	addStreakFreezesQuery = `
+		UPDATE daily_streaks SET freezes = LEAST(freezes + $1, $2), updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $3 AND freezes < $2
+	`
+
+	expireQuestsQuery = `
+		UPDATE user_quests SET status = 'expired'
+		WHERE status = 'active' AND expires_at <= $1
+	`
+)
@@ -0,0 +1,48 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/quest"
+)
+
+const subscriberName = "quests"
+
+// RegisterQuestSubscribers counts the lessons users complete and the quizzes they submit towards their quests
+func RegisterQuestSubscribers(bus events.Bus, questUC quest.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		activity := &models.QuestActivity{
+			EventID: event.EventID,
+			At:      event.OccurredAt,
+		}
+
+		switch event.Type {
+		case events.LessonCompletedType:
+			payload := &events.LessonCompleted{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "questSubscriber.Decode")
+			}
+			activity.Metric, activity.SourceID = models.QuestMetricLessonsCompleted, payload.LessonID
+		case events.QuizSubmittedType:
+			payload := &events.QuizSubmitted{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "questSubscriber.Decode")
+			}
+			activity.Metric, activity.SourceID, activity.Score = models.QuestMetricQuizzesCompleted, payload.QuizID, payload.Score
+		default:
+			return nil
+		}
+
+		if _, err := questUC.RecordActivity(ctx, event.UserID, activity); err != nil {
+			return errors.Wrap(err, "questSubscriber.RecordActivity")
+		}
+		return nil
+	},
+		events.LessonCompletedType,
+		events.QuizSubmittedType,
+	)
+}
@@ -0,0 +1,24 @@
+package quest
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Quest UseCase interface
+type UseCase interface {
+	// The quests of the user's current day and week, generated on first access
+	GetQuests(ctx context.Context, userID uuid.UUID) (*models.QuestBoard, error)
+	// Counts a lesson completion or quiz submission towards the quests of its period and rewards the ones it completed
+	RecordActivity(ctx context.Context, userID uuid.UUID, activity *models.QuestActivity) ([]*models.Quest, error)
+
+	CreateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error)
+	UpdateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error)
+	GetTemplates(ctx context.Context) ([]*models.QuestTemplate, error)
+
+	// Expire the quests whose period ended and pay the rewards that failed
+	ProcessQuests(ctx context.Context) (*models.QuestJobReport, error)
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/quest"
+	"github.com/AleksK1NG/api-mc/internal/xp"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const rewardBatchSize = 100
+
+type questUC struct {
+	cfg       *config.Config
+	questRepo quest.Repository
+	xpUC      xp.UseCase
+	logger    logger.Logger
+}
+
+func NewQuestUseCase(cfg *config.Config, questRepo quest.Repository, xpUC xp.UseCase, logger logger.Logger) quest.UseCase {
+	return &questUC{
+		cfg:       cfg,
+		questRepo: questRepo,
+		xpUC:      xpUC,
+		logger:    logger,
+	}
+}
+
+func (u *questUC) GetQuests(ctx context.Context, userID uuid.UUID) (*models.QuestBoard, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questUC.GetQuests")
+	defer span.Finish()
+
+	now := time.Now()
+	if err := u.generateQuests(ctx, userID, now); err != nil {
+		return nil, err
+	}
+
+	quests, err := u.questRepo.GetUserQuests(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &models.QuestBoard{
+		Daily:  make([]*models.Quest, 0, u.cfg.Quests.DailyCount),
+		Weekly: make([]*models.Quest, 0, u.cfg.Quests.WeeklyCount),
+	}
+	for _, q := range quests {
+		if q.Period == models.QuestPeriodWeekly {
+			board.Weekly = append(board.Weekly, q)
+		} else {
+			board.Daily = append(board.Daily, q)
+		}
+	}
+
+	return board, nil
+}
+
+func (u *questUC) RecordActivity(ctx context.Context, userID uuid.UUID, activity *models.QuestActivity) ([]*models.Quest, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questUC.RecordActivity")
+	defer span.Finish()
+
+	var err error
+	switch activity.Metric {
+	case models.QuestMetricLessonsCompleted:
+		activity.Subject, activity.Grade, err = u.questRepo.GetLessonSubject(ctx, activity.SourceID)
+	case models.QuestMetricQuizzesCompleted:
+		activity.Subject, activity.Grade, err = u.questRepo.GetQuizSubject(ctx, activity.SourceID)
+	default:
+		return nil, errors.Errorf("unknown quest metric %q", activity.Metric)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The activity may be the user's first of the period, their quests are drawn before it counts
+	if err := u.generateQuests(ctx, userID, activity.At); err != nil {
+		return nil, err
+	}
+
+	completed, err := u.questRepo.AdvanceQuests(ctx, userID, activity)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed reward is paid by the next quest job, the quest stays completed
+	for _, q := range completed {
+		if err := u.reward(ctx, q); err != nil {
+			u.logger.Errorf("questUC.RecordActivity.reward, QuestID: %s, Error: %v", q.QuestID, err)
+		}
+	}
+
+	return completed, nil
+}
+
+func (u *questUC) CreateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questUC.CreateTemplate")
+	defer span.Finish()
+
+	if template.MinScore != nil && template.Metric != models.QuestMetricQuizzesCompleted {
+		return nil, quest.ErrMinScoreMetric
+	}
+
+	return u.questRepo.CreateTemplate(ctx, template)
+}
+
+func (u *questUC) UpdateTemplate(ctx context.Context, template *models.QuestTemplate) (*models.QuestTemplate, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questUC.UpdateTemplate")
+	defer span.Finish()
+
+	if template.MinScore != nil && template.Metric != models.QuestMetricQuizzesCompleted {
+		return nil, quest.ErrMinScoreMetric
+	}
+
+	return u.questRepo.UpdateTemplate(ctx, template)
+}
+
+func (u *questUC) GetTemplates(ctx context.Context) ([]*models.QuestTemplate, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questUC.GetTemplates")
+	defer span.Finish()
+
+	return u.questRepo.GetTemplates(ctx)
+}
+
+func (u *questUC) ProcessQuests(ctx context.Context) (*models.QuestJobReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questUC.ProcessQuests")
+	defer span.Finish()
+
+	report := &models.QuestJobReport{}
+
+	expired, err := u.questRepo.ExpireQuests(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	report.Expired = expired
+
+	for {
+		quests, err := u.questRepo.GetUnrewardedQuests(ctx, rewardBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		rewarded := 0
+		for _, q := range quests {
+			if err := u.reward(ctx, q); err != nil {
+				u.logger.Errorf("questUC.ProcessQuests.reward, QuestID: %s, Error: %v", q.QuestID, err)
+				continue
+			}
+			rewarded++
+		}
+		report.Rewarded += rewarded
+
+		// Stop on the last page, or when nothing could be rewarded to not loop on failing quests
+		if len(quests) < rewardBatchSize || rewarded == 0 {
+			return report, nil
+		}
+	}
+}
+
+// generateQuests draws the user's quests of the day and the week containing at, in the user's timezone
+func (u *questUC) generateQuests(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	timezone, err := u.questRepo.GetUserTimezone(ctx, userID)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return errors.Wrap(err, "questUC.generateQuests.LoadLocation")
+	}
+
+	counts := map[string]int{
+		models.QuestPeriodDaily:  u.cfg.Quests.DailyCount,
+		models.QuestPeriodWeekly: u.cfg.Quests.WeeklyCount,
+	}
+	for _, period := range []string{models.QuestPeriodDaily, models.QuestPeriodWeekly} {
+		if counts[period] <= 0 {
+			continue
+		}
+
+		periodStart, startsAt, expiresAt := periodBounds(period, at, loc)
+		if err := u.questRepo.GenerateQuests(ctx, userID, period, periodStart, startsAt, expiresAt, counts[period]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reward pays a completed quest's reward, the XP ledger is idempotent so an XP reward is never paid twice
+func (u *questUC) reward(ctx context.Context, q *models.Quest) error {
+	freezes := 0
+	switch q.RewardType {
+	case models.QuestRewardXP:
+		if q.RewardSubject == nil || q.RewardGrade == nil {
+			return errors.New("completed quest has no reward subject")
+		}
+		if _, err := u.xpUC.AwardQuestReward(ctx, q.UserID, q.QuestID, *q.RewardSubject, *q.RewardGrade, q.RewardAmount); err != nil {
+			return err
+		}
+	case models.QuestRewardFreeze:
+		freezes = q.RewardAmount
+	}
+
+	now := time.Now()
+	if _, err := u.questRepo.RewardQuest(ctx, q, freezes, u.cfg.Streak.MaxFreezes); err != nil {
+		return err
+	}
+	q.RewardedAt = &now
+
+	return nil
+}
+
+// periodBounds is the local day a period containing t starts on, at midnight UTC like the postgres DATE values,
+// and the instants the period starts and ends at in loc. Weeks start on Monday.
+func periodBounds(period string, t time.Time, loc *time.Location) (time.Time, time.Time, time.Time) {
+	y, m, d := t.In(loc).Date()
+	startsAt := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	days := 1
+	if period == models.QuestPeriodWeekly {
+		startsAt = startsAt.AddDate(0, 0, -(int(startsAt.Weekday())+6)%7)
+		days = 7
+	}
+
+	y, m, d = startsAt.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), startsAt, startsAt.AddDate(0, 0, days)
+}
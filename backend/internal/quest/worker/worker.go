@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/quest"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultInterval = 15 * time.Minute
+
+// QuestWorker expires the quests whose period ended and pays the rewards that failed
+type QuestWorker struct {
+	questUC  quest.UseCase
+	logger   logger.Logger
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewQuestWorker creates a new quest worker, interval is in minutes
+func NewQuestWorker(questUC quest.UseCase, interval time.Duration, logger logger.Logger) *QuestWorker {
+	if interval <= 0 {
+		interval = defaultInterval
+	} else {
+		interval = interval * time.Minute
+	}
+
+	return &QuestWorker{
+		questUC:  questUC,
+		logger:   logger,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic quest processing
+func (w *QuestWorker) Start() {
+	w.logger.Info("Starting quest worker")
+
+	// Run immediately on startup
+	go w.processQuests()
+
+	// Then run periodically
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				go w.processQuests()
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic quest processing
+func (w *QuestWorker) Stop() {
+	w.logger.Info("Stopping quest worker")
+	close(w.stopCh)
+}
+
+// processQuests triggers the quest job
+func (w *QuestWorker) processQuests() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := w.questUC.ProcessQuests(ctx)
+	if err != nil {
+		w.logger.Errorf("Error processing quests: %v", err)
+		return
+	}
+
+	w.logger.Infof("Quests expired: %d, rewards paid: %d", report.Expired, report.Rewarded)
+}
@@ -0,0 +1,16 @@
+package questionbank
+
+import "github.com/labstack/echo/v4"
+
+// Question bank HTTP Handlers interface
+type Handlers interface {
+	SearchQuestions() echo.HandlerFunc
+	CreateQuestion() echo.HandlerFunc
+	GetQuestionByID() echo.HandlerFunc
+	UpdateQuestion() echo.HandlerFunc
+	DeleteQuestion() echo.HandlerFunc
+
+	GetQuizzesForQuestion() echo.HandlerFunc
+	AddQuestionToQuiz() echo.HandlerFunc
+	RemoveQuestionFromQuiz() echo.HandlerFunc
+}
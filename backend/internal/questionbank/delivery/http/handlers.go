@@ -0,0 +1,309 @@
+package http
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/questionbank"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type questionBankHandlers struct {
+	questionBankUC questionbank.UseCase
+	logger         logger.Logger
+}
+
+func NewQuestionBankHandlers(questionBankUC questionbank.UseCase, logger logger.Logger) questionbank.Handlers {
+	return &questionBankHandlers{
+		questionBankUC: questionBankUC,
+		logger:         logger,
+	}
+}
+
+// SearchQuestions godoc
+// @Summary Search the question bank
+// @Description Filter bank items by subject, grade, difficulty, type, tags and text, answers are shown to their authors and question managers only
+// @Tags Question Bank
+// @Produce json
+// @Param subject query string false "Subject"
+// @Param grade query int false "Grade"
+// @Param difficulty query string false "easy, medium or hard"
+// @Param question_type query string false "multiple_choice, true_false or open_ended"
+// @Param tags query string false "Comma separated tags, items must carry all of them"
+// @Param q query string false "Text search"
+// @Param limit query int false "Page size (default: 20)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.QuestionBankList
+// @Router /questions [get]
+func (h *questionBankHandlers) SearchQuestions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter := &models.QuestionBankFilter{
+			Subject:      c.QueryParam("subject"),
+			Difficulty:   c.QueryParam("difficulty"),
+			QuestionType: c.QueryParam("question_type"),
+			Query:        c.QueryParam("q"),
+		}
+
+		if tagsParam := c.QueryParam("tags"); tagsParam != "" {
+			filter.Tags = strings.Split(tagsParam, ",")
+		}
+
+		if gradeParam := c.QueryParam("grade"); gradeParam != "" {
+			grade, err := strconv.Atoi(gradeParam)
+			if err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.SearchQuestions.Atoi"))
+			}
+			filter.Grade = grade
+		}
+
+		if limitParam := c.QueryParam("limit"); limitParam != "" {
+			limit, err := strconv.Atoi(limitParam)
+			if err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.SearchQuestions.Atoi"))
+			}
+			filter.Limit = limit
+		}
+
+		if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+			offset, err := strconv.Atoi(offsetParam)
+			if err != nil {
+				return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.SearchQuestions.Atoi"))
+			}
+			filter.Offset = offset
+		}
+
+		list, err := h.questionBankUC.SearchQuestions(c.Request().Context(), filter)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.SearchQuestions.SearchQuestions"))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// CreateQuestion godoc
+// @Summary Create a question bank item
+// @Tags Question Bank
+// @Accept json
+// @Produce json
+// @Param question body models.Question true "Question"
+// @Success 201 {object} models.Question
+// @Router /questions [post]
+func (h *questionBankHandlers) CreateQuestion() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "questionBankHandlers.CreateQuestion.GetUserIDFromContext"))
+		}
+
+		question := &models.Question{}
+		if err := c.Bind(question); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.CreateQuestion.Bind"))
+		}
+
+		created, err := h.questionBankUC.CreateQuestion(c.Request().Context(), userID, question)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.CreateQuestion.CreateQuestion"))
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// GetQuestionByID godoc
+// @Summary Get a question bank item
+// @Tags Question Bank
+// @Produce json
+// @Param id path string true "Question ID"
+// @Success 200 {object} models.Question
+// @Router /questions/{id} [get]
+func (h *questionBankHandlers) GetQuestionByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.GetQuestionByID.Parse"))
+		}
+
+		question, err := h.questionBankUC.GetQuestionByID(c.Request().Context(), questionID)
+		if err != nil {
+			return httpErrors.NewNotFoundError(errors.Wrap(err, "questionBankHandlers.GetQuestionByID.GetQuestionByID"))
+		}
+
+		return c.JSON(http.StatusOK, question)
+	}
+}
+
+// UpdateQuestion godoc
+// @Summary Update a question bank item
+// @Description Every quiz that uses the item serves the updated version, by its author or a user granted questions:manage
+// @Tags Question Bank
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param question body models.Question true "Question"
+// @Success 200 {object} map[string]interface{}
+// @Router /questions/{id} [put]
+func (h *questionBankHandlers) UpdateQuestion() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.UpdateQuestion.Parse"))
+		}
+
+		question := &models.Question{}
+		if err := c.Bind(question); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.UpdateQuestion.Bind"))
+		}
+		question.QuestionID = questionID
+
+		updated, links, err := h.questionBankUC.UpdateQuestion(c.Request().Context(), question)
+		if err != nil {
+			if errors.Cause(err) == httpErrors.PermissionDenied {
+				return httpErrors.NewForbiddenError(errors.Wrap(err, "questionBankHandlers.UpdateQuestion.UpdateQuestion"))
+			}
+			if errors.Cause(err) == sql.ErrNoRows {
+				return httpErrors.NewNotFoundError(errors.Wrap(err, "questionBankHandlers.UpdateQuestion.UpdateQuestion"))
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.UpdateQuestion.UpdateQuestion"))
+		}
+
+		quizIDs := make([]uuid.UUID, 0, len(links))
+		for _, link := range links {
+			quizIDs = append(quizIDs, link.QuizID)
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"question":         updated,
+			"affected_quizzes": quizIDs,
+		})
+	}
+}
+
+// DeleteQuestion godoc
+// @Summary Delete a question bank item
+// @Description Only items that no quiz uses can be deleted, by their author or a user granted questions:manage
+// @Tags Question Bank
+// @Param id path string true "Question ID"
+// @Success 204
+// @Router /questions/{id} [delete]
+func (h *questionBankHandlers) DeleteQuestion() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.DeleteQuestion.Parse"))
+		}
+
+		if err := h.questionBankUC.DeleteQuestion(c.Request().Context(), questionID); err != nil {
+			if errors.Cause(err) == httpErrors.PermissionDenied {
+				return httpErrors.NewForbiddenError(errors.Wrap(err, "questionBankHandlers.DeleteQuestion.DeleteQuestion"))
+			}
+			if errors.Cause(err) == sql.ErrNoRows {
+				return httpErrors.NewNotFoundError(errors.Wrap(err, "questionBankHandlers.DeleteQuestion.DeleteQuestion"))
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.DeleteQuestion.DeleteQuestion"))
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// GetQuizzesForQuestion godoc
+// @Summary List the quizzes using a question bank item
+// @Tags Question Bank
+// @Produce json
+// @Param id path string true "Question ID"
+// @Success 200 {array} models.QuizQuestion
+// @Router /questions/{id}/quizzes [get]
+func (h *questionBankHandlers) GetQuizzesForQuestion() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.GetQuizzesForQuestion.Parse"))
+		}
+
+		links, err := h.questionBankUC.GetQuizzesForQuestion(c.Request().Context(), questionID)
+		if err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "questionBankHandlers.GetQuizzesForQuestion.GetQuizzesForQuestion"))
+		}
+
+		return c.JSON(http.StatusOK, links)
+	}
+}
+
+// AddQuestionToQuiz godoc
+// @Summary Add a question bank item to a quiz
+// @Description Without a position the item is appended to the quiz, requires editing the quiz's chapter
+// @Tags Question Bank
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param link body models.QuizQuestion true "Quiz and optional position"
+// @Success 201 {object} models.QuizQuestion
+// @Router /questions/{id}/quizzes [post]
+func (h *questionBankHandlers) AddQuestionToQuiz() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.AddQuestionToQuiz.Parse"))
+		}
+
+		link := &models.QuizQuestion{}
+		if err := c.Bind(link); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.AddQuestionToQuiz.Bind"))
+		}
+		link.QuestionID = questionID
+
+		added, err := h.questionBankUC.AddQuestionToQuiz(c.Request().Context(), link)
+		if err != nil {
+			if errors.Cause(err) == httpErrors.PermissionDenied {
+				return httpErrors.NewForbiddenError(errors.Wrap(err, "questionBankHandlers.AddQuestionToQuiz.AddQuestionToQuiz"))
+			}
+			if errors.Cause(err) == sql.ErrNoRows {
+				return httpErrors.NewNotFoundError(errors.Wrap(err, "questionBankHandlers.AddQuestionToQuiz.AddQuestionToQuiz"))
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.AddQuestionToQuiz.AddQuestionToQuiz"))
+		}
+
+		return c.JSON(http.StatusCreated, added)
+	}
+}
+
+// RemoveQuestionFromQuiz godoc
+// @Summary Remove a question bank item from a quiz
+// @Description The item stays in the bank, requires editing the quiz's chapter
+// @Tags Question Bank
+// @Param id path string true "Question ID"
+// @Param quiz_id path string true "Quiz ID"
+// @Success 204
+// @Router /questions/{id}/quizzes/{quiz_id} [delete]
+func (h *questionBankHandlers) RemoveQuestionFromQuiz() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		questionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.RemoveQuestionFromQuiz.Parse"))
+		}
+
+		quizID, err := uuid.Parse(c.Param("quiz_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "questionBankHandlers.RemoveQuestionFromQuiz.Parse"))
+		}
+
+		if err := h.questionBankUC.RemoveQuestionFromQuiz(c.Request().Context(), quizID, questionID); err != nil {
+			if errors.Cause(err) == httpErrors.PermissionDenied {
+				return httpErrors.NewForbiddenError(errors.Wrap(err, "questionBankHandlers.RemoveQuestionFromQuiz.RemoveQuestionFromQuiz"))
+			}
+			return httpErrors.NewNotFoundError(errors.Wrap(err, "questionBankHandlers.RemoveQuestionFromQuiz.RemoveQuestionFromQuiz"))
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/questionbank"
+)
+
+// Map question bank routes
+func MapQuestionBankRoutes(questionGroup *echo.Group, h questionbank.Handlers, mw *middleware.MiddlewareManager) {
+	protected := questionGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("", h.SearchQuestions())
+		protected.POST("", h.CreateQuestion())
+		protected.GET("/:id", h.GetQuestionByID())
+		protected.PUT("/:id", h.UpdateQuestion())
+		protected.DELETE("/:id", h.DeleteQuestion())
+
+		protected.GET("/:id/quizzes", h.GetQuizzesForQuestion())
+		protected.POST("/:id/quizzes", h.AddQuestionToQuiz())
+		protected.DELETE("/:id/quizzes/:quiz_id", h.RemoveQuestionFromQuiz())
+	}
+}
@@ -0,0 +1,26 @@
+package questionbank
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Question bank Repository interface
+type Repository interface {
+	// Bank items
+	CreateQuestion(ctx context.Context, question *models.Question) (*models.Question, error)
+	GetQuestionByID(ctx context.Context, questionID uuid.UUID) (*models.Question, error)
+	UpdateQuestion(ctx context.Context, question *models.Question) (*models.Question, error)
+	DeleteQuestion(ctx context.Context, questionID uuid.UUID) error
+	SearchQuestions(ctx context.Context, filter *models.QuestionBankFilter) (*models.QuestionBankList, error)
+
+	// Quiz membership
+	GetQuizzesForQuestion(ctx context.Context, questionID uuid.UUID) ([]*models.QuizQuestion, error)
+	AddQuestionToQuiz(ctx context.Context, link *models.QuizQuestion) (*models.QuizQuestion, error)
+	RemoveQuestionFromQuiz(ctx context.Context, quizID uuid.UUID, questionID uuid.UUID) error
+	// The chapter of the quiz's lesson, whose owner edits the quiz
+	GetQuizChapter(ctx context.Context, quizID uuid.UUID) (*models.Chapter, error)
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/questionbank"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultSearchLimit = 20
+
+type questionBankRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewQuestionBankRepository(db *sqlx.DB, logger logger.Logger) questionbank.Repository {
+	return &questionBankRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *questionBankRepo) CreateQuestion(ctx context.Context, question *models.Question) (*models.Question, error) {
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createQuestionQuery,
+		question.Text,
+		question.QuestionType,
+		pq.Array(question.Options),
+		question.Answer,
+		question.Explanation,
+		question.Points,
+		question.Difficulty,
+		question.Subject,
+		question.Grade,
+		pq.Array(question.Tags),
+		question.CreatedBy,
+	).Scan(&question.QuestionID, &question.CreatedAt, &question.UpdatedAt); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.CreateQuestion.Scan")
+	}
+	return question, nil
+}
+
+func (r *questionBankRepo) GetQuestionByID(ctx context.Context, questionID uuid.UUID) (*models.Question, error) {
+	question, err := scanQuestion(r.db.QueryRowxContext(ctx, getQuestionByIDQuery, questionID))
+	if err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.GetQuestionByID.Scan")
+	}
+	return question, nil
+}
+
+func (r *questionBankRepo) UpdateQuestion(ctx context.Context, question *models.Question) (*models.Question, error) {
+	if err := r.db.QueryRowxContext(
+		ctx,
+		updateQuestionQuery,
+		question.Text,
+		question.QuestionType,
+		pq.Array(question.Options),
+		question.Answer,
+		question.Explanation,
+		question.Points,
+		question.Difficulty,
+		question.Subject,
+		question.Grade,
+		pq.Array(question.Tags),
+		question.QuestionID,
+	).Scan(&question.CreatedBy, &question.CreatedAt, &question.UpdatedAt); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.UpdateQuestion.Scan")
+	}
+	return question, nil
+}
+
+func (r *questionBankRepo) DeleteQuestion(ctx context.Context, questionID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, deleteQuestionQuery, questionID)
+	if err != nil {
+		return errors.Wrap(err, "questionBankRepo.DeleteQuestion.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "questionBankRepo.DeleteQuestion.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "questionBankRepo.DeleteQuestion.rowsAffected")
+	}
+	return nil
+}
+
+func (r *questionBankRepo) SearchQuestions(ctx context.Context, filter *models.QuestionBankFilter) (*models.QuestionBankList, error) {
+	limit := defaultSearchLimit
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	tags := pq.Array(filter.Tags)
+	if filter.Tags == nil {
+		tags = pq.Array([]string{})
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(
+		ctx,
+		&totalCount,
+		countQuestionsQuery,
+		filter.Subject,
+		filter.Grade,
+		filter.Difficulty,
+		filter.QuestionType,
+		tags,
+		filter.Query,
+	); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.SearchQuestions.GetContext")
+	}
+
+	rows, err := r.db.QueryxContext(
+		ctx,
+		searchQuestionsQuery,
+		filter.Subject,
+		filter.Grade,
+		filter.Difficulty,
+		filter.QuestionType,
+		tags,
+		filter.Query,
+		limit,
+		filter.Offset,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.SearchQuestions.QueryxContext")
+	}
+	defer rows.Close()
+
+	list := &models.QuestionBankList{
+		TotalCount: totalCount,
+		Questions:  make([]*models.Question, 0, limit),
+	}
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "questionBankRepo.SearchQuestions.Scan")
+		}
+		list.Questions = append(list.Questions, question)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.SearchQuestions.rows.Err")
+	}
+
+	return list, nil
+}
+
+func (r *questionBankRepo) GetQuizzesForQuestion(ctx context.Context, questionID uuid.UUID) ([]*models.QuizQuestion, error) {
+	links := make([]*models.QuizQuestion, 0)
+	if err := r.db.SelectContext(ctx, &links, getQuizzesForQuestionQuery, questionID); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.GetQuizzesForQuestion.SelectContext")
+	}
+	return links, nil
+}
+
+func (r *questionBankRepo) AddQuestionToQuiz(ctx context.Context, link *models.QuizQuestion) (*models.QuizQuestion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.AddQuestionToQuiz.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	// Links added at once take the next positions one after the other
+	if _, err := tx.ExecContext(ctx, lockQuizQuery, link.QuizID); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.AddQuestionToQuiz.lockQuiz")
+	}
+
+	added := &models.QuizQuestion{}
+	if err := tx.QueryRowxContext(
+		ctx,
+		addQuestionToQuizQuery,
+		link.QuizID,
+		link.QuestionID,
+		link.Position,
+	).StructScan(added); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.AddQuestionToQuiz.StructScan")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.AddQuestionToQuiz.Commit")
+	}
+	return added, nil
+}
+
+func (r *questionBankRepo) RemoveQuestionFromQuiz(ctx context.Context, quizID uuid.UUID, questionID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, removeQuestionFromQuizQuery, quizID, questionID)
+	if err != nil {
+		return errors.Wrap(err, "questionBankRepo.RemoveQuestionFromQuiz.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "questionBankRepo.RemoveQuestionFromQuiz.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "questionBankRepo.RemoveQuestionFromQuiz.rowsAffected")
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanQuestion(row rowScanner) (*models.Question, error) {
+	var options, tags pq.StringArray
+	question := &models.Question{}
+	if err := row.Scan(
+		&question.QuestionID,
+		&question.QuizID,
+		&question.Text,
+		&question.QuestionType,
+		&options,
+		&question.Answer,
+		&question.Explanation,
+		&question.Points,
+		&question.Difficulty,
+		&question.Subject,
+		&question.Grade,
+		&tags,
+		&question.CreatedBy,
+		&question.CreatedAt,
+		&question.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	question.Options = []string(options)
+	question.Tags = []string(tags)
+	return question, nil
+}
+
+func (r *questionBankRepo) GetQuizChapter(ctx context.Context, quizID uuid.UUID) (*models.Chapter, error) {
+	chapter := &models.Chapter{}
+	if err := r.db.GetContext(ctx, chapter, getQuizChapterQuery, quizID); err != nil {
+		return nil, errors.Wrap(err, "questionBankRepo.GetQuizChapter.GetContext")
+	}
+	return chapter, nil
+}
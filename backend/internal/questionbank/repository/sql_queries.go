@@ -0,0 +1,78 @@
+package repository
+
+const (
+	// Bank items are read outside any quiz, so quiz_id is always empty
+	bankQuestionColumns = `
+		q.question_id, NULL::uuid AS quiz_id, q.text, q.question_type, q.options, q.answer, q.explanation,
+		q.points, q.difficulty, q.subject, q.grade, q.tags, q.created_by, q.created_at, q.updated_at
+	`
+
+	createQuestionQuery = `
+		INSERT INTO questions (text, question_type, options, answer, explanation, points, difficulty,
+			subject, grade, tags, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING question_id, created_at, updated_at
+	`
+
+	getQuestionByIDQuery = `SELECT` + bankQuestionColumns + `FROM questions q WHERE q.question_id = $1`
+
+	updateQuestionQuery = `
+		UPDATE questions SET
+			text = $1,
+			question_type = $2,
+			options = $3,
+			answer = $4,
+			explanation = $5,
+			points = $6,
+			difficulty = $7,
+			subject = $8,
+			grade = $9,
+			tags = $10,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE question_id = $11
+		RETURNING created_by, created_at, updated_at
+	`
+
+	deleteQuestionQuery = `DELETE FROM questions WHERE question_id = $1`
+
+	questionBankFilter = `
+		WHERE ($1 = '' OR q.subject = $1)
+			AND ($2 = 0 OR q.grade = $2)
+			AND ($3 = '' OR q.difficulty = $3)
+			AND ($4 = '' OR q.question_type = $4)
+			AND (cardinality($5::text[]) = 0 OR q.tags @> $5::text[])
+			AND ($6 = '' OR q.text ILIKE '%' || $6 || '%')
+	`
+
+	searchQuestionsQuery = `SELECT` + bankQuestionColumns + `FROM questions q` + questionBankFilter + `
+		ORDER BY q.updated_at DESC, q.question_id
+		LIMIT $7 OFFSET $8
+	`
+
+	countQuestionsQuery = `SELECT COUNT(*) FROM questions q` + questionBankFilter
+
+	getQuizzesForQuestionQuery = `
+		SELECT quiz_id, question_id, position, created_at
+		FROM quiz_questions
+		WHERE question_id = $1
+		ORDER BY created_at ASC
+	`
+
+	lockQuizQuery = `SELECT quiz_id FROM quizzes WHERE quiz_id = $1 FOR UPDATE`
+
+	// Without a position the item goes to the end of the quiz
+	addQuestionToQuizQuery = `
+		INSERT INTO quiz_questions (quiz_id, question_id, position)
+		VALUES ($1, $2, COALESCE(NULLIF($3, 0), (SELECT COALESCE(MAX(position), 0) + 1 FROM quiz_questions WHERE quiz_id = $1)))
+		RETURNING quiz_id, question_id, position, created_at
+	`
+
+	removeQuestionFromQuizQuery = `DELETE FROM quiz_questions WHERE quiz_id = $1 AND question_id = $2`
+
+	getQuizChapterQuery = `
+		SELECT c.* FROM quizzes qz
+		JOIN lessons l ON l.lesson_id = qz.lesson_id
+		JOIN chapters c ON c.chapter_id = l.chapter_id
+		WHERE qz.quiz_id = $1
+	`
+)
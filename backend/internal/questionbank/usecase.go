@@ -0,0 +1,23 @@
+package questionbank
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Question bank UseCase interface
+type UseCase interface {
+	CreateQuestion(ctx context.Context, userID uuid.UUID, question *models.Question) (*models.Question, error)
+	GetQuestionByID(ctx context.Context, questionID uuid.UUID) (*models.Question, error)
+	// Quizzes reference bank items, so an update shows up in every quiz using the item
+	UpdateQuestion(ctx context.Context, question *models.Question) (*models.Question, []*models.QuizQuestion, error)
+	DeleteQuestion(ctx context.Context, questionID uuid.UUID) error
+	SearchQuestions(ctx context.Context, filter *models.QuestionBankFilter) (*models.QuestionBankList, error)
+
+	GetQuizzesForQuestion(ctx context.Context, questionID uuid.UUID) ([]*models.QuizQuestion, error)
+	AddQuestionToQuiz(ctx context.Context, link *models.QuizQuestion) (*models.QuizQuestion, error)
+	RemoveQuestionFromQuiz(ctx context.Context, quizID uuid.UUID, questionID uuid.UUID) error
+}
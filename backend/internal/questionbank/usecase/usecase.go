@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/authz"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/questionbank"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/quizformat"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type questionBankUC struct {
+	questionBankRepo questionbank.Repository
+	authzUC          authz.UseCase
+	logger           logger.Logger
+}
+
+func NewQuestionBankUseCase(questionBankRepo questionbank.Repository, authzUC authz.UseCase, logger logger.Logger) questionbank.UseCase {
+	return &questionBankUC{
+		questionBankRepo: questionBankRepo,
+		authzUC:          authzUC,
+		logger:           logger,
+	}
+}
+
+func (u *questionBankUC) CreateQuestion(ctx context.Context, userID uuid.UUID, question *models.Question) (*models.Question, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.CreateQuestion")
+	defer span.Finish()
+
+	if err := u.prepareQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	question.QuizID = uuid.Nil
+	question.CreatedBy = &userID
+
+	return u.questionBankRepo.CreateQuestion(ctx, question)
+}
+
+func (u *questionBankUC) GetQuestionByID(ctx context.Context, questionID uuid.UUID) (*models.Question, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.GetQuestionByID")
+	defer span.Finish()
+
+	question, err := u.questionBankRepo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	u.hideAnswer(ctx, question)
+
+	return question, nil
+}
+
+func (u *questionBankUC) UpdateQuestion(ctx context.Context, question *models.Question) (*models.Question, []*models.QuizQuestion, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.UpdateQuestion")
+	defer span.Finish()
+
+	existing, err := u.questionBankRepo.GetQuestionByID(ctx, question.QuestionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The change reaches every quiz using the item, only its author and the question managers make it
+	if err := u.authorizeQuestion(ctx, existing, models.AccessActionUpdate); err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.prepareQuestion(ctx, question); err != nil {
+		return nil, nil, err
+	}
+	question.QuizID = uuid.Nil
+
+	updated, err := u.questionBankRepo.UpdateQuestion(ctx, question)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	links, err := u.questionBankRepo.GetQuizzesForQuestion(ctx, question.QuestionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, links, nil
+}
+
+func (u *questionBankUC) DeleteQuestion(ctx context.Context, questionID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.DeleteQuestion")
+	defer span.Finish()
+
+	question, err := u.questionBankRepo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return err
+	}
+	if err := u.authorizeQuestion(ctx, question, models.AccessActionDelete); err != nil {
+		return err
+	}
+
+	links, err := u.questionBankRepo.GetQuizzesForQuestion(ctx, questionID)
+	if err != nil {
+		return err
+	}
+	if len(links) > 0 {
+		return errors.Errorf("question is used by %d quiz(zes), remove it from them first", len(links))
+	}
+
+	return u.questionBankRepo.DeleteQuestion(ctx, questionID)
+}
+
+func (u *questionBankUC) SearchQuestions(ctx context.Context, filter *models.QuestionBankFilter) (*models.QuestionBankList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.SearchQuestions")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, filter); err != nil {
+		return nil, errors.Wrap(err, "questionBankUC.SearchQuestions.ValidateStruct")
+	}
+	filter.Tags = normalizeTags(filter.Tags)
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	list, err := u.questionBankRepo.SearchQuestions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, question := range list.Questions {
+		u.hideAnswer(ctx, question)
+	}
+
+	return list, nil
+}
+
+func (u *questionBankUC) GetQuizzesForQuestion(ctx context.Context, questionID uuid.UUID) ([]*models.QuizQuestion, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.GetQuizzesForQuestion")
+	defer span.Finish()
+
+	return u.questionBankRepo.GetQuizzesForQuestion(ctx, questionID)
+}
+
+func (u *questionBankUC) AddQuestionToQuiz(ctx context.Context, link *models.QuizQuestion) (*models.QuizQuestion, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.AddQuestionToQuiz")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, link); err != nil {
+		return nil, errors.Wrap(err, "questionBankUC.AddQuestionToQuiz.ValidateStruct")
+	}
+	// A linked item is served with the quiz, its answer key included, so it takes both the item and the quiz
+	question, err := u.questionBankRepo.GetQuestionByID(ctx, link.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authorizeQuestion(ctx, question, models.AccessActionUse); err != nil {
+		return nil, err
+	}
+	if err := u.authorizeQuiz(ctx, link.QuizID); err != nil {
+		return nil, err
+	}
+
+	return u.questionBankRepo.AddQuestionToQuiz(ctx, link)
+}
+
+func (u *questionBankUC) RemoveQuestionFromQuiz(ctx context.Context, quizID uuid.UUID, questionID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "questionBankUC.RemoveQuestionFromQuiz")
+	defer span.Finish()
+
+	if err := u.authorizeQuiz(ctx, quizID); err != nil {
+		return err
+	}
+
+	return u.questionBankRepo.RemoveQuestionFromQuiz(ctx, quizID, questionID)
+}
+
+// authorizeQuestion allows the author of the bank item and the users granted questions:manage
+func (u *questionBankUC) authorizeQuestion(ctx context.Context, question *models.Question, action string) error {
+	return u.authzUC.Authorize(ctx, &models.AccessRequest{
+		Action:       action,
+		ResourceType: models.ResourceQuestion,
+		ResourceID:   question.QuestionID.String(),
+		OwnerID:      questionAuthor(question),
+		Permission:   models.PermissionQuestionsManage,
+	})
+}
+
+// authorizeQuiz allows the users who may edit the quiz's chapter to change its questions
+func (u *questionBankUC) authorizeQuiz(ctx context.Context, quizID uuid.UUID) error {
+	chapter, err := u.questionBankRepo.GetQuizChapter(ctx, quizID)
+	if err != nil {
+		return err
+	}
+	return u.authzUC.Authorize(ctx, &models.AccessRequest{
+		Action:       models.AccessActionUpdate,
+		ResourceType: models.ResourceQuiz,
+		ResourceID:   quizID.String(),
+		OwnerID:      chapter.CreatedBy,
+		Permission:   models.PermissionChaptersManage,
+	})
+}
+
+// hideAnswer clears the answer key of the bank items the user of the context did not write, unless they manage questions
+func (u *questionBankUC) hideAnswer(ctx context.Context, question *models.Question) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err == nil && (user.UserID == questionAuthor(question) || user.HasPermission(models.PermissionQuestionsManage)) {
+		return
+	}
+	question.Answer = ""
+}
+
+// questionAuthor is uuid.Nil for the items imported without an author
+func questionAuthor(question *models.Question) uuid.UUID {
+	if question.CreatedBy == nil {
+		return uuid.Nil
+	}
+	return *question.CreatedBy
+}
+
+// prepareQuestion applies the same rules as quiz imports, bank items also need a subject and grade
+func (u *questionBankUC) prepareQuestion(ctx context.Context, question *models.Question) error {
+	for _, issue := range quizformat.Validate(question, 1, 0) {
+		if issue.Severity == quizformat.SeverityError {
+			return errors.New(issue.Message)
+		}
+	}
+
+	question.Subject = strings.TrimSpace(question.Subject)
+	if question.Subject == "" {
+		return errors.New("subject is required")
+	}
+	if question.Grade == 0 {
+		return errors.New("grade is required")
+	}
+	question.Tags = normalizeTags(question.Tags)
+
+	if err := utils.ValidateStruct(ctx, question); err != nil {
+		return errors.Wrap(err, "questionBankUC.prepareQuestion.ValidateStruct")
+	}
+	return nil
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags so searches match regardless of spelling
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,8 @@
+package reminder
+
+import "github.com/labstack/echo/v4"
+
+// Reminder HTTP Handlers interface
+type Handlers interface {
+	SendStreakReminders() echo.HandlerFunc
+}
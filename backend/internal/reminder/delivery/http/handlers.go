@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/reminder"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type reminderHandlers struct {
+	reminderUC reminder.UseCase
+	logger     logger.Logger
+}
+
+func NewReminderHandlers(reminderUC reminder.UseCase, logger logger.Logger) reminder.Handlers {
+	return &reminderHandlers{
+		reminderUC: reminderUC,
+		logger:     logger,
+	}
+}
+
+// SendStreakReminders godoc
+// @Summary Run the streak reminder job
+// @Description Remind the users whose streak is at risk, the reminder worker runs it periodically
+// @Tags Reminders
+// @Produce json
+// @Success 200 {object} models.ReminderJobReport
+// @Failure 409 {object} httpErrors.RestError
+// @Router /reminders/admin/process [post]
+func (h *reminderHandlers) SendStreakReminders() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report, err := h.reminderUC.SendStreakReminders(c.Request().Context())
+		if err != nil {
+			if errors.Is(err, reminder.ErrJobLocked) {
+				return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "reminderHandlers.SendStreakReminders.SendStreakReminders"))
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
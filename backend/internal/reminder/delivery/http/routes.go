@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/reminder"
+)
+
+// Map reminder routes
+func MapReminderRoutes(reminderGroup *echo.Group, h reminder.Handlers, mw *middleware.MiddlewareManager) {
+	protected := reminderGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		admin := protected.Group("/admin")
+		admin.Use(mw.RequirePermission(models.PermissionJobsRun))
+		{
+			admin.POST("/process", h.SendStreakReminders())
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package reminder
+
+import "errors"
+
+// Reminder errors
+var (
+	ErrJobLocked = errors.New("the streak reminder job is running on another instance")
+)
@@ -0,0 +1,22 @@
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Reminder Repository interface
+type Repository interface {
+	// Users with a running streak and no activity on their local day, past their reminder hour, who were not
+	// reminded that day, have not opted out and got fewer than maxPerWeek reminders over the last 7 days
+	GetStreaksAtRisk(ctx context.Context, maxPerWeek int, limit int) ([]*models.StreakAtRisk, error)
+	// Records the reminder of the user's local day, false when they were reminded already
+	CreateReminder(ctx context.Context, userID uuid.UUID, localDate time.Time) (bool, error)
+	SetReminderNotification(ctx context.Context, userID uuid.UUID, localDate time.Time, notificationID uuid.UUID) error
+	// Drops a reminder that could not be sent, so the next run sends it
+	DeleteReminder(ctx context.Context, userID uuid.UUID, localDate time.Time) error
+}
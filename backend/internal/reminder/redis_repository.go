@@ -0,0 +1,16 @@
+package reminder
+
+import (
+	"context"
+	"time"
+)
+
+// LockRepository coordinates the reminder jobs of the API instances through Redis
+type LockRepository interface {
+	// Takes the named lock for ttl, false when another instance holds it. The token releases it
+	AcquireLock(ctx context.Context, name string, ttl time.Duration) (string, bool, error)
+	// Releases the lock if the token still holds it, a lock that expired and was taken by another instance is kept
+	ReleaseLock(ctx context.Context, name string, token string) error
+	// Counts a send against the limit of the current minute shared by every instance, false once it is reached
+	AllowSend(ctx context.Context, name string, perMinute int) (bool, error)
+}
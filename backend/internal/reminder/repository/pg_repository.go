@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/reminder"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+type reminderRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewReminderRepository(db *sqlx.DB, logger logger.Logger) reminder.Repository {
+	return &reminderRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *reminderRepo) GetStreaksAtRisk(ctx context.Context, maxPerWeek int, limit int) ([]*models.StreakAtRisk, error) {
+	streaks := make([]*models.StreakAtRisk, 0, limit)
+	if err := r.db.SelectContext(ctx, &streaks, getStreaksAtRiskQuery, maxPerWeek, limit); err != nil {
+		return nil, errors.Wrap(err, "reminderRepo.GetStreaksAtRisk.SelectContext")
+	}
+	return streaks, nil
+}
+
+func (r *reminderRepo) CreateReminder(ctx context.Context, userID uuid.UUID, localDate time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, createReminderQuery, userID, localDate)
+	if err != nil {
+		return false, errors.Wrap(err, "reminderRepo.CreateReminder.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "reminderRepo.CreateReminder.RowsAffected")
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *reminderRepo) SetReminderNotification(ctx context.Context, userID uuid.UUID, localDate time.Time, notificationID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, setReminderNotificationQuery, userID, localDate, notificationID); err != nil {
+		return errors.Wrap(err, "reminderRepo.SetReminderNotification.ExecContext")
+	}
+	return nil
+}
+
+func (r *reminderRepo) DeleteReminder(ctx context.Context, userID uuid.UUID, localDate time.Time) error {
+	if _, err := r.db.ExecContext(ctx, deleteReminderQuery, userID, localDate); err != nil {
+		return errors.Wrap(err, "reminderRepo.DeleteReminder.ExecContext")
+	}
+	return nil
+}
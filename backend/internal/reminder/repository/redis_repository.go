@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/reminder"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	lockKeyPrefix = "reminders:lock:"
+	rateKeyPrefix = "reminders:rate:"
+)
+
+// Deletes the lock only while it holds the token, so an instance never releases a lock another one took after it expired
+var releaseLockScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`)
+
+type reminderLockRepo struct {
+	redisClient *redis.Client
+	logger      logger.Logger
+}
+
+func NewReminderLockRepository(redisClient *redis.Client, logger logger.Logger) reminder.LockRepository {
+	return &reminderLockRepo{
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+func (r *reminderLockRepo) AcquireLock(ctx context.Context, name string, ttl time.Duration) (string, bool, error) {
+	token := uuid.New().String()
+	acquired, err := r.redisClient.SetNX(ctx, lockKeyPrefix+name, token, ttl).Result()
+	if err != nil {
+		return "", false, errors.Wrap(err, "reminderLockRepo.AcquireLock.SetNX")
+	}
+	return token, acquired, nil
+}
+
+func (r *reminderLockRepo) ReleaseLock(ctx context.Context, name string, token string) error {
+	if err := releaseLockScript.Run(ctx, r.redisClient, []string{lockKeyPrefix + name}, token).Err(); err != nil {
+		return errors.Wrap(err, "reminderLockRepo.ReleaseLock.Run")
+	}
+	return nil
+}
+
+func (r *reminderLockRepo) AllowSend(ctx context.Context, name string, perMinute int) (bool, error) {
+	key := fmt.Sprintf("%s%s:%d", rateKeyPrefix, name, time.Now().Unix()/60)
+
+	var incr *redis.IntCmd
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, 2*time.Minute)
+		return nil
+	}); err != nil {
+		return false, errors.Wrap(err, "reminderLockRepo.AllowSend.TxPipelined")
+	}
+
+	return incr.Val() <= int64(perMinute), nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+const (
+	// The streak is at risk when its last counted day is the local yesterday: today is not covered yet, and a streak
+	// whose yesterday was missed is broken or frozen by the streak job instead. Users without preferences have the
+	// defaults of notification_preferences
+	getStreaksAtRiskQuery = `
+		SELECT ds.user_id, ds.current_streak, l.local_date
+		FROM daily_streaks ds
+		JOIN users u ON u.user_id = ds.user_id
+		CROSS JOIN LATERAL (
+			SELECT (CURRENT_TIMESTAMP AT TIME ZONE u.timezone) AS local_time,
+				(CURRENT_TIMESTAMP AT TIME ZONE u.timezone)::date AS local_date
+		) l
+		LEFT JOIN notification_preferences np ON np.user_id = ds.user_id
+		WHERE ds.current_streak > 0
+			AND ds.last_activity_date = l.local_date - 1
+			AND (ds.last_activity AT TIME ZONE u.timezone)::date < l.local_date
+			AND COALESCE(np.streak_reminders_enabled, true)
+			AND EXTRACT(HOUR FROM l.local_time) >= COALESCE(np.streak_reminder_hour, 19)
+			AND NOT EXISTS (
+				SELECT 1 FROM streak_reminders sr
+				WHERE sr.user_id = ds.user_id AND sr.reminder_date = l.local_date
+			)
+			AND (
+				SELECT COUNT(*) FROM streak_reminders sr
+				WHERE sr.user_id = ds.user_id AND sr.created_at > CURRENT_TIMESTAMP - INTERVAL '7 days'
+			) < $1
+		ORDER BY ds.current_streak DESC
+		LIMIT $2
+	`
+
+	createReminderQuery = `
+		INSERT INTO streak_reminders (user_id, reminder_date)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, reminder_date) DO NOTHING
+	`
+
+	setReminderNotificationQuery = `
+		UPDATE streak_reminders SET notification_id = $3 WHERE user_id = $1 AND reminder_date = $2
+	`
+
+	deleteReminderQuery = `
+		DELETE FROM streak_reminders WHERE user_id = $1 AND reminder_date = $2
+	`
+)
@@ -0,0 +1,14 @@
+package reminder
+
+import (
+	"context"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Reminder UseCase interface
+type UseCase interface {
+	// Reminds the users whose streak is at risk, once per local day from their preferred hour.
+	// Only one instance runs it at a time, the others get ErrJobLocked
+	SendStreakReminders(ctx context.Context) (*models.ReminderJobReport, error)
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/notification"
+	"github.com/AleksK1NG/api-mc/internal/reminder"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	streakReminderJob = "streak_reminders"
+
+	defaultBatchSize     = 500
+	defaultMaxPerWeek    = 4
+	defaultRatePerMinute = 200
+	defaultLockTTL       = 10 * time.Minute
+)
+
+type reminderUC struct {
+	cfg            *config.Config
+	reminderRepo   reminder.Repository
+	lockRepo       reminder.LockRepository
+	notificationUC notification.UseCase
+	logger         logger.Logger
+}
+
+func NewReminderUseCase(
+	cfg *config.Config,
+	reminderRepo reminder.Repository,
+	lockRepo reminder.LockRepository,
+	notificationUC notification.UseCase,
+	logger logger.Logger,
+) reminder.UseCase {
+	return &reminderUC{
+		cfg:            cfg,
+		reminderRepo:   reminderRepo,
+		lockRepo:       lockRepo,
+		notificationUC: notificationUC,
+		logger:         logger,
+	}
+}
+
+func (u *reminderUC) SendStreakReminders(ctx context.Context) (*models.ReminderJobReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "reminderUC.SendStreakReminders")
+	defer span.Finish()
+
+	lockTTL := u.cfg.Reminders.LockTTL * time.Second
+	if lockTTL <= 0 {
+		lockTTL = defaultLockTTL
+	}
+	token, acquired, err := u.lockRepo.AcquireLock(ctx, streakReminderJob, lockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, reminder.ErrJobLocked
+	}
+	defer func() {
+		// The job context may be done already, the release must still reach Redis
+		if err := u.lockRepo.ReleaseLock(context.Background(), streakReminderJob, token); err != nil {
+			u.logger.Errorf("reminderUC.SendStreakReminders.ReleaseLock, Error: %v", err)
+		}
+	}()
+
+	batchSize := u.cfg.Reminders.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	maxPerWeek := u.cfg.Reminders.MaxPerWeek
+	if maxPerWeek <= 0 {
+		maxPerWeek = defaultMaxPerWeek
+	}
+	ratePerMinute := u.cfg.Reminders.RatePerMinute
+	if ratePerMinute <= 0 {
+		ratePerMinute = defaultRatePerMinute
+	}
+
+	streaks, err := u.reminderRepo.GetStreaksAtRisk(ctx, maxPerWeek, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReminderJobReport{AtRisk: len(streaks)}
+	for i, s := range streaks {
+		allowed, err := u.lockRepo.AllowSend(ctx, streakReminderJob, ratePerMinute)
+		if err != nil {
+			return nil, err
+		}
+		// The longest streaks come first, the rest wait for the next run
+		if !allowed {
+			report.RateLimited = len(streaks) - i
+			break
+		}
+
+		sent, err := u.remind(ctx, s)
+		if err != nil {
+			u.logger.Errorf("reminderUC.SendStreakReminders.remind, UserID: %s, Error: %v", s.UserID, err)
+			continue
+		}
+		if sent {
+			report.Sent++
+		}
+	}
+
+	return report, nil
+}
+
+// remind sends the streak reminder of the user's local day, false when it was sent already
+func (u *reminderUC) remind(ctx context.Context, s *models.StreakAtRisk) (bool, error) {
+	// The reminder row is taken before sending, so two runs racing past an expired lock send it once
+	created, err := u.reminderRepo.CreateReminder(ctx, s.UserID, s.LocalDate)
+	if err != nil {
+		return false, err
+	}
+	if !created {
+		return false, nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"current_streak": s.CurrentStreak})
+	if err != nil {
+		return false, errors.Wrap(err, "reminderUC.remind.json.Marshal")
+	}
+
+	n, err := u.notificationUC.Notify(ctx, &models.Notification{
+		UserID: s.UserID,
+		Type:   models.NotificationStreakReminder,
+		Title:  "Keep your streak alive",
+		Body:   fmt.Sprintf("Your %d day streak ends at midnight. Complete a lesson or a quiz today to keep it.", s.CurrentStreak),
+		Data:   data,
+	})
+	if err != nil {
+		if err := u.reminderRepo.DeleteReminder(ctx, s.UserID, s.LocalDate); err != nil {
+			u.logger.Errorf("reminderUC.remind.DeleteReminder, UserID: %s, Error: %v", s.UserID, err)
+		}
+		return false, err
+	}
+
+	if n != nil {
+		if err := u.reminderRepo.SetReminderNotification(ctx, s.UserID, s.LocalDate, n.NotificationID); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/reminder"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultInterval = 15 * time.Minute
+
+// ReminderWorker reminds the users whose streak is at risk. Every instance runs it, the Redis lock lets one send
+type ReminderWorker struct {
+	reminderUC reminder.UseCase
+	logger     logger.Logger
+	interval   time.Duration
+	stopCh     chan struct{}
+}
+
+// NewReminderWorker creates a new streak reminder worker, interval is in minutes
+func NewReminderWorker(reminderUC reminder.UseCase, interval time.Duration, logger logger.Logger) *ReminderWorker {
+	if interval <= 0 {
+		interval = defaultInterval
+	} else {
+		interval = interval * time.Minute
+	}
+
+	return &ReminderWorker{
+		reminderUC: reminderUC,
+		logger:     logger,
+		interval:   interval,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start begins the periodic streak reminders
+func (w *ReminderWorker) Start() {
+	w.logger.Info("Starting reminder worker")
+
+	// Run immediately on startup
+	go w.sendReminders()
+
+	// Then run periodically
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				go w.sendReminders()
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic streak reminders
+func (w *ReminderWorker) Stop() {
+	w.logger.Info("Stopping reminder worker")
+	close(w.stopCh)
+}
+
+// sendReminders triggers the streak reminder job
+func (w *ReminderWorker) sendReminders() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := w.reminderUC.SendStreakReminders(ctx)
+	if err != nil {
+		if errors.Is(err, reminder.ErrJobLocked) {
+			w.logger.Debug("Streak reminders are running on another instance")
+			return
+		}
+		w.logger.Errorf("Error sending streak reminders: %v", err)
+		return
+	}
+
+	w.logger.Infof("Streaks at risk: %d, reminders sent: %d, rate limited: %d", report.AtRisk, report.Sent, report.RateLimited)
+}
@@ -3,9 +3,12 @@ package server
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/AleksK1NG/api-mc/docs"
+	"github.com/AleksK1NG/api-mc/internal/notification"
 	"github.com/AleksK1NG/api-mc/pkg/csrf"
+	"github.com/AleksK1NG/api-mc/pkg/levels"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -15,10 +18,23 @@ import (
 
 	achievementHttp "github.com/AleksK1NG/api-mc/internal/achievement/delivery/http"
 	achievementRepository "github.com/AleksK1NG/api-mc/internal/achievement/repository"
+	achievementSubscriber "github.com/AleksK1NG/api-mc/internal/achievement/subscriber"
 	achievementUseCase "github.com/AleksK1NG/api-mc/internal/achievement/usecase"
+	analyticsHttp "github.com/AleksK1NG/api-mc/internal/analytics/delivery/http"
+	analyticsRepository "github.com/AleksK1NG/api-mc/internal/analytics/repository"
+	analyticsUseCase "github.com/AleksK1NG/api-mc/internal/analytics/usecase"
+	analyticsWorker "github.com/AleksK1NG/api-mc/internal/analytics/worker"
 	authHttp "github.com/AleksK1NG/api-mc/internal/auth/delivery/http"
 	authRepository "github.com/AleksK1NG/api-mc/internal/auth/repository"
+	authSubscriber "github.com/AleksK1NG/api-mc/internal/auth/subscriber"
 	authUseCase "github.com/AleksK1NG/api-mc/internal/auth/usecase"
+	authzHttp "github.com/AleksK1NG/api-mc/internal/authz/delivery/http"
+	authzRepository "github.com/AleksK1NG/api-mc/internal/authz/repository"
+	authzUseCase "github.com/AleksK1NG/api-mc/internal/authz/usecase"
+	challengeHttp "github.com/AleksK1NG/api-mc/internal/challenge/delivery/http"
+	challengeRepository "github.com/AleksK1NG/api-mc/internal/challenge/repository"
+	challengeUseCase "github.com/AleksK1NG/api-mc/internal/challenge/usecase"
+	challengeWorker "github.com/AleksK1NG/api-mc/internal/challenge/worker"
 	chapterHttp "github.com/AleksK1NG/api-mc/internal/chapter/delivery/http"
 	chapterRepository "github.com/AleksK1NG/api-mc/internal/chapter/repository"
 	chapterService "github.com/AleksK1NG/api-mc/internal/chapter/service"
@@ -27,10 +43,50 @@ import (
 	chatbotRepository "github.com/AleksK1NG/api-mc/internal/chatbot/repository"
 	chatbotService "github.com/AleksK1NG/api-mc/internal/chatbot/service"
 	chatbotUseCase "github.com/AleksK1NG/api-mc/internal/chatbot/usecase"
+	eventBus "github.com/AleksK1NG/api-mc/internal/events/bus"
 	leaderboardHttp "github.com/AleksK1NG/api-mc/internal/leaderboard/delivery/http"
+	leaderboardSubscriber "github.com/AleksK1NG/api-mc/internal/leaderboard/subscriber"
+	leagueHttp "github.com/AleksK1NG/api-mc/internal/league/delivery/http"
+	liveHttp "github.com/AleksK1NG/api-mc/internal/live/delivery/http"
+	liveRepository "github.com/AleksK1NG/api-mc/internal/live/repository"
+	liveUseCase "github.com/AleksK1NG/api-mc/internal/live/usecase"
 	apiMiddlewares "github.com/AleksK1NG/api-mc/internal/middleware"
+	notificationChannel "github.com/AleksK1NG/api-mc/internal/notification/channel"
+	notificationHttp "github.com/AleksK1NG/api-mc/internal/notification/delivery/http"
+	notificationRepository "github.com/AleksK1NG/api-mc/internal/notification/repository"
+	notificationSubscriber "github.com/AleksK1NG/api-mc/internal/notification/subscriber"
+	notificationUseCase "github.com/AleksK1NG/api-mc/internal/notification/usecase"
+	notificationWorker "github.com/AleksK1NG/api-mc/internal/notification/worker"
+	outboxHttp "github.com/AleksK1NG/api-mc/internal/outbox/delivery/http"
+	outboxRepository "github.com/AleksK1NG/api-mc/internal/outbox/repository"
+	outboxUseCase "github.com/AleksK1NG/api-mc/internal/outbox/usecase"
+	outboxWorker "github.com/AleksK1NG/api-mc/internal/outbox/worker"
+	questHttp "github.com/AleksK1NG/api-mc/internal/quest/delivery/http"
+	questRepository "github.com/AleksK1NG/api-mc/internal/quest/repository"
+	questSubscriber "github.com/AleksK1NG/api-mc/internal/quest/subscriber"
+	questUseCase "github.com/AleksK1NG/api-mc/internal/quest/usecase"
+	questWorker "github.com/AleksK1NG/api-mc/internal/quest/worker"
+	questionBankHttp "github.com/AleksK1NG/api-mc/internal/questionbank/delivery/http"
+	questionBankRepository "github.com/AleksK1NG/api-mc/internal/questionbank/repository"
+	questionBankUseCase "github.com/AleksK1NG/api-mc/internal/questionbank/usecase"
+	reminderHttp "github.com/AleksK1NG/api-mc/internal/reminder/delivery/http"
+	reminderRepository "github.com/AleksK1NG/api-mc/internal/reminder/repository"
+	reminderUseCase "github.com/AleksK1NG/api-mc/internal/reminder/usecase"
+	reminderWorker "github.com/AleksK1NG/api-mc/internal/reminder/worker"
 	sessionRepository "github.com/AleksK1NG/api-mc/internal/session/repository"
 	"github.com/AleksK1NG/api-mc/internal/session/usecase"
+	socialHttp "github.com/AleksK1NG/api-mc/internal/social/delivery/http"
+	socialRepository "github.com/AleksK1NG/api-mc/internal/social/repository"
+	socialSubscriber "github.com/AleksK1NG/api-mc/internal/social/subscriber"
+	socialUseCase "github.com/AleksK1NG/api-mc/internal/social/usecase"
+	streakHttp "github.com/AleksK1NG/api-mc/internal/streak/delivery/http"
+	streakRepository "github.com/AleksK1NG/api-mc/internal/streak/repository"
+	streakSubscriber "github.com/AleksK1NG/api-mc/internal/streak/subscriber"
+	streakUseCase "github.com/AleksK1NG/api-mc/internal/streak/usecase"
+	streakWorker "github.com/AleksK1NG/api-mc/internal/streak/worker"
+	xpHttp "github.com/AleksK1NG/api-mc/internal/xp/delivery/http"
+	xpRepository "github.com/AleksK1NG/api-mc/internal/xp/repository"
+	xpUseCase "github.com/AleksK1NG/api-mc/internal/xp/usecase"
 	"github.com/AleksK1NG/api-mc/pkg/metric"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
@@ -47,16 +103,29 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 		s.cfg.Metrics.ServiceName,
 	)
 
-	// Init repositories
+	// Init repositories, the outbox repository records the domain events of the others
+	outboxRepo := outboxRepository.NewOutboxRepository(s.db, s.logger)
 	aRepo := authRepository.NewAuthRepository(s.db)
 	sRepo := sessionRepository.NewSessionRepository(s.redisClient, s.cfg)
 	authRedisRepo := authRepository.NewAuthRedisRepository(s.redisClient)
-	chapterRepo := chapterRepository.NewChapterRepository(s.db)
-	achievementRepo := achievementRepository.NewAchievementRepository(s.db, s.logger)
-	userProgressRepo := achievementRepository.NewUserProgressRepository(s.db, s.logger)
-	lessonProgressRepo := achievementRepository.NewLessonProgressRepository(s.db, s.logger)
-	userQuizAttemptsRepo := achievementRepository.NewUserQuizAttemptsRepository(s.db, s.logger)
+	chapterRepo := chapterRepository.NewChapterRepository(s.db, outboxRepo)
+	achievementRepo := achievementRepository.NewAchievementRepository(s.db, outboxRepo, s.logger)
+	achievementMetricsRepo := achievementRepository.NewMetricsRepository(s.db, s.logger)
 	chatbotRepo := chatbotRepository.NewChatbotRepository(s.db)
+	analyticsRepo := analyticsRepository.NewAnalyticsRepository(s.db, s.logger)
+	questionBankRepo := questionBankRepository.NewQuestionBankRepository(s.db, s.logger)
+	xpRepo := xpRepository.NewXPRepository(s.db, outboxRepo, s.logger)
+	streakRepo := streakRepository.NewStreakRepository(s.db, outboxRepo, s.logger)
+	socialRepo := socialRepository.NewSocialRepository(s.db, s.logger)
+	challengeRepo := challengeRepository.NewChallengeRepository(s.db, outboxRepo, s.logger)
+	liveRepo := liveRepository.NewLiveRepository(s.db, s.logger)
+	questRepo := questRepository.NewQuestRepository(s.db, s.logger)
+	liveStateRepo := liveRepository.NewLiveStateRepository(s.redisClient, s.cfg.Live.SessionTTL*time.Hour, s.logger)
+	notificationRepo := notificationRepository.NewNotificationRepository(s.db, s.logger)
+	notificationBroker := notificationRepository.NewNotificationBroker(s.redisClient, s.logger)
+	reminderRepo := reminderRepository.NewReminderRepository(s.db, s.logger)
+	reminderLockRepo := reminderRepository.NewReminderLockRepository(s.redisClient, s.logger)
+	authzRepo := authzRepository.NewAuthzRepository(s.db, s.logger)
 
 	// Init AI service
 	aiService, err := chapterService.NewAIService(s.cfg, s.logger)
@@ -70,26 +139,86 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 		return err
 	}
 
-	// Init useCases
-	authUC := authUseCase.NewAuthUseCase(s.cfg, aRepo, authRedisRepo, s.logger)
+	// Init notification channels, a channel that is not configured sends nothing
+	pushChannel, err := notificationChannel.NewPushChannel(s.cfg, notificationRepo, s.logger)
+	if err != nil {
+		return err
+	}
+	notificationChannels := []notification.Channel{
+		notificationChannel.NewEmailChannel(s.cfg, s.logger),
+		pushChannel,
+	}
+
+	// Init level curve
+	levelCurve, err := levels.NewCurve(s.cfg.Levels.Thresholds)
+	if err != nil {
+		return err
+	}
+
+	// Init event bus, it is started with the workers once the subscribers are registered
+	s.eventBus, err = eventBus.NewEventBus(s.cfg.Events, s.redisClient, s.logger)
+	if err != nil {
+		return err
+	}
+
+	// Init useCases, the authz use case checks the access of the others
+	authzUC := authzUseCase.NewAuthzUseCase(authzRepo, s.logger)
+	authUC := authUseCase.NewAuthUseCase(s.cfg, aRepo, authRedisRepo, authzUC, levelCurve, s.logger)
 	sessUC := usecase.NewSessionUseCase(sRepo, s.cfg)
-	chapterUC := chapterUseCase.NewChapterUseCase(s.cfg, chapterRepo, aiService, s.logger)
-	achievementUC := achievementUseCase.NewAchievementUseCase(
-		achievementRepo,
-		userProgressRepo,
-		lessonProgressRepo,
-		userQuizAttemptsRepo,
-		s.logger,
-	)
+	xpUC := xpUseCase.NewXPUseCase(s.cfg, xpRepo, levelCurve, s.logger)
+	streakUC := streakUseCase.NewStreakUseCase(s.cfg, streakRepo, s.logger)
+	chapterUC := chapterUseCase.NewChapterUseCase(s.cfg, chapterRepo, aiService, xpUC, streakUC, authzUC, s.logger)
+	achievementUC := achievementUseCase.NewAchievementUseCase(achievementRepo, achievementMetricsRepo, s.logger)
 	chatbotUC := chatbotUseCase.NewChatbotUseCase(s.cfg, chatbotRepo, chatbotAIService, s.logger)
+	analyticsUC := analyticsUseCase.NewAnalyticsUseCase(s.cfg, analyticsRepo, s.logger)
+	questionBankUC := questionBankUseCase.NewQuestionBankUseCase(questionBankRepo, authzUC, s.logger)
+	outboxUC := outboxUseCase.NewOutboxUseCase(s.cfg, outboxRepo, s.eventBus, s.logger)
+	socialUC := socialUseCase.NewSocialUseCase(socialRepo, s.logger)
+	challengeUC := challengeUseCase.NewChallengeUseCase(s.cfg, challengeRepo, chapterUC, xpUC, socialUC, s.logger)
+	liveUC := liveUseCase.NewLiveUseCase(s.cfg, liveRepo, liveStateRepo, chapterUC, s.logger)
+	questUC := questUseCase.NewQuestUseCase(s.cfg, questRepo, xpUC, s.logger)
+	notificationUC := notificationUseCase.NewNotificationUseCase(s.cfg, notificationRepo, notificationBroker, notificationChannels, s.logger)
+	reminderUC := reminderUseCase.NewReminderUseCase(s.cfg, reminderRepo, reminderLockRepo, notificationUC, s.logger)
+
+	// Init event subscribers
+	authSubscriber.RegisterAuthSubscribers(s.eventBus, authUC)
+	achievementSubscriber.RegisterAchievementSubscribers(s.eventBus, achievementUC)
+	socialSubscriber.RegisterSocialSubscribers(s.eventBus, socialUC)
+	streakSubscriber.RegisterStreakSubscribers(s.eventBus, streakUC)
+	questSubscriber.RegisterQuestSubscribers(s.eventBus, questUC)
+	notificationSubscriber.RegisterNotificationSubscribers(s.eventBus, notificationUC)
+	if s.leaderboardUC != nil {
+		leaderboardSubscriber.RegisterLeaderboardSubscribers(s.eventBus, s.leaderboardUC)
+	}
+
+	// Init workers
+	s.analyticsWorker = analyticsWorker.NewAnalyticsWorker(analyticsUC, s.cfg.Analytics.JobInterval, s.logger)
+	s.streakWorker = streakWorker.NewStreakWorker(streakUC, s.cfg.Streak.JobInterval, s.logger)
+	s.outboxWorker = outboxWorker.NewOutboxWorker(outboxUC, s.cfg.Events.PollInterval, s.logger)
+	s.challengeWorker = challengeWorker.NewChallengeWorker(challengeUC, s.cfg.Challenge.JobInterval, s.logger)
+	s.questWorker = questWorker.NewQuestWorker(questUC, s.cfg.Quests.JobInterval, s.logger)
+	s.notificationWorker = notificationWorker.NewNotificationWorker(notificationUC, s.cfg.Notifications.PollInterval, s.logger)
+	s.reminderWorker = reminderWorker.NewReminderWorker(reminderUC, s.cfg.Reminders.JobInterval, s.logger)
 
 	// Init handlers
 	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, sessUC, s.logger)
 	chapterHandlers := chapterHttp.NewChapterHandlers(s.cfg, chapterUC, s.logger)
 	achievementHandlers := achievementHttp.NewAchievementHandlers(achievementUC, s.logger)
 	chatbotHandlers := chatbotHttp.NewChatbotHandlers(s.cfg, chatbotUC, s.logger)
+	analyticsHandlers := analyticsHttp.NewAnalyticsHandlers(analyticsUC, s.logger)
+	questionBankHandlers := questionBankHttp.NewQuestionBankHandlers(questionBankUC, s.logger)
+	xpHandlers := xpHttp.NewXPHandlers(xpUC, s.logger)
+	streakHandlers := streakHttp.NewStreakHandlers(streakUC, s.logger)
+	outboxHandlers := outboxHttp.NewOutboxHandlers(outboxUC, s.logger)
+	socialHandlers := socialHttp.NewSocialHandlers(socialUC, s.logger)
+	challengeHandlers := challengeHttp.NewChallengeHandlers(challengeUC, s.logger)
+	liveHandlers := liveHttp.NewLiveHandlers(liveUC, s.logger)
+	questHandlers := questHttp.NewQuestHandlers(questUC, s.logger)
+	notificationHandlers := notificationHttp.NewNotificationHandlers(s.cfg, notificationUC, s.logger)
+	reminderHandlers := reminderHttp.NewReminderHandlers(reminderUC, s.logger)
+	authzHandlers := authzHttp.NewAuthzHandlers(authzUC, s.logger)
 
-	mw := apiMiddlewares.NewMiddlewareManager(sessUC, authUC, s.cfg, []string{"*"}, s.logger)
+	mw := apiMiddlewares.NewMiddlewareManager(sessUC, authUC, authzUC, s.cfg, []string{"*"}, s.logger)
 
 	e.Use(mw.RequestLoggerMiddleware)
 
@@ -115,7 +244,9 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
 		Level: 5,
 		Skipper: func(c echo.Context) bool {
-			return strings.Contains(c.Request().URL.Path, "swagger")
+			// Event streams are flushed as they go, gzip would hold them back
+			return strings.Contains(c.Request().URL.Path, "swagger") ||
+				strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream")
 		},
 	}))
 	e.Use(middleware.Secure())
@@ -131,21 +262,42 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	chapterGroup := v1.Group("/chapters")
 	achievementGroup := v1.Group("/achievements")
 	leaderboardGroup := v1.Group("/leaderboard")
+	leagueGroup := v1.Group("/leagues")
 	chatbotGroup := v1.Group("/chatbot")
+	analyticsGroup := v1.Group("/analytics")
+	questionGroup := v1.Group("/questions")
+	xpGroup := v1.Group("/xp")
+	streakGroup := v1.Group("/streak")
+	outboxGroup := v1.Group("/outbox")
+	socialGroup := v1.Group("/social")
+	challengeGroup := v1.Group("/challenges")
+	liveGroup := v1.Group("/live")
+	questGroup := v1.Group("/quests")
+	notificationGroup := v1.Group("/notifications")
+	reminderGroup := v1.Group("/reminders")
+	authzGroup := v1.Group("/authz")
 
 	// Map routes
 	authHttp.MapAuthRoutes(authGroup, authHandlers, mw)
 	chapterHttp.MapChapterRoutes(chapterGroup, chapterHandlers, mw)
 	achievementHttp.MapAchievementRoutes(achievementGroup, achievementHandlers, mw, achievementUC, s.logger)
 	chatbotHttp.MapChatbotRoutes(chatbotGroup, chatbotHandlers, mw)
+	analyticsHttp.MapAnalyticsRoutes(analyticsGroup, analyticsHandlers, mw)
+	questionBankHttp.MapQuestionBankRoutes(questionGroup, questionBankHandlers, mw)
+	xpHttp.MapXPRoutes(xpGroup, xpHandlers, mw)
+	streakHttp.MapStreakRoutes(streakGroup, streakHandlers, mw)
+	outboxHttp.MapOutboxRoutes(outboxGroup, outboxHandlers, mw)
+	socialHttp.MapSocialRoutes(socialGroup, socialHandlers, mw)
+	challengeHttp.MapChallengeRoutes(challengeGroup, challengeHandlers, mw)
+	liveHttp.MapLiveRoutes(liveGroup, liveHandlers, mw)
+	questHttp.MapQuestRoutes(questGroup, questHandlers, mw)
+	notificationHttp.MapNotificationRoutes(notificationGroup, notificationHandlers, mw)
+	reminderHttp.MapReminderRoutes(reminderGroup, reminderHandlers, mw)
+	authzHttp.MapAuthzRoutes(authzGroup, authzHandlers, mw)
 
-	// Register achievement middleware for automatic achievement checking
-	achievementHttp.RegisterAchievementMiddleware(e, achievementUC, s.logger)
-
-	// Register leaderboard middleware for automatic leaderboard tracking
 	if s.leaderboardUC != nil {
-		leaderboardHttp.RegisterLeaderboardMiddleware(e, s.leaderboardUC, s.logger)
 		leaderboardHttp.MapLeaderboardRoutes(leaderboardGroup, s.leaderboardHandlers, mw, s.logger)
+		leagueHttp.MapLeagueRoutes(leagueGroup, s.leagueHandlers, mw)
 	}
 
 	health.GET("", func(c echo.Context) error {
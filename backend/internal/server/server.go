@@ -16,9 +16,21 @@ import (
 
 	"github.com/AleksK1NG/api-mc/config"
 	_ "github.com/AleksK1NG/api-mc/docs"
+	analyticsWorker "github.com/AleksK1NG/api-mc/internal/analytics/worker"
+	challengeWorker "github.com/AleksK1NG/api-mc/internal/challenge/worker"
+	"github.com/AleksK1NG/api-mc/internal/events"
 	"github.com/AleksK1NG/api-mc/internal/leaderboard"
 	leaderboardHttp "github.com/AleksK1NG/api-mc/internal/leaderboard/delivery/http"
 	"github.com/AleksK1NG/api-mc/internal/leaderboard/worker"
+	"github.com/AleksK1NG/api-mc/internal/league"
+	leagueHttp "github.com/AleksK1NG/api-mc/internal/league/delivery/http"
+	leagueRepository "github.com/AleksK1NG/api-mc/internal/league/repository"
+	leagueUseCase "github.com/AleksK1NG/api-mc/internal/league/usecase"
+	notificationWorker "github.com/AleksK1NG/api-mc/internal/notification/worker"
+	outboxWorker "github.com/AleksK1NG/api-mc/internal/outbox/worker"
+	questWorker "github.com/AleksK1NG/api-mc/internal/quest/worker"
+	reminderWorker "github.com/AleksK1NG/api-mc/internal/reminder/worker"
+	streakWorker "github.com/AleksK1NG/api-mc/internal/streak/worker"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
@@ -39,6 +51,15 @@ type Server struct {
 	leaderboardWorker   *worker.LeaderboardWorker
 	leaderboardUC       leaderboard.UseCase
 	leaderboardHandlers leaderboard.Handlers
+	leagueHandlers      league.Handlers
+	analyticsWorker     *analyticsWorker.AnalyticsWorker
+	streakWorker        *streakWorker.StreakWorker
+	eventBus            events.Bus
+	outboxWorker        *outboxWorker.OutboxWorker
+	challengeWorker     *challengeWorker.ChallengeWorker
+	questWorker         *questWorker.QuestWorker
+	notificationWorker  *notificationWorker.NotificationWorker
+	reminderWorker      *reminderWorker.ReminderWorker
 }
 
 // NewServer New Server constructor
@@ -52,12 +73,17 @@ func NewServer(cfg *config.Config, db *sqlx.DB, redisClient *redis.Client, logge
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 	}))
 
-	// Initialize leaderboard worker if leaderboardUC is provided
+	// Initialize leaderboard worker and the leagues built on the leaderboard if leaderboardUC is provided
 	var leaderboardWorker *worker.LeaderboardWorker
 	var leaderboardHandlers leaderboard.Handlers
+	var leagueHandlers league.Handlers
 	if leaderboardUC != nil {
-		leaderboardWorker = worker.NewLeaderboardWorker(leaderboardUC, logger)
+		leagueRepo := leagueRepository.NewLeagueRepository(db, len(leagueUseCase.Tiers(cfg.League))-1, logger)
+		leagueUC := leagueUseCase.NewLeagueUseCase(cfg, leagueRepo, leaderboardUC, logger)
+
+		leaderboardWorker = worker.NewLeaderboardWorker(leaderboardUC, leagueUC, cfg.Leaderboard.ReconcileInterval, logger)
 		leaderboardHandlers = leaderboardHttp.NewLeaderboardHandlers(leaderboardUC, logger)
+		leagueHandlers = leagueHttp.NewLeagueHandlers(leagueUC, logger)
 	}
 
 	return &Server{
@@ -69,6 +95,7 @@ func NewServer(cfg *config.Config, db *sqlx.DB, redisClient *redis.Client, logge
 		leaderboardWorker:   leaderboardWorker,
 		leaderboardUC:       leaderboardUC,
 		leaderboardHandlers: leaderboardHandlers,
+		leagueHandlers:      leagueHandlers,
 	}
 }
 
@@ -98,12 +125,60 @@ func (s *Server) Run() error {
 			}
 		}()
 
+		// Start delivering domain events, the bus is created in MapHandlers
+		if s.eventBus != nil {
+			s.eventBus.Start()
+			defer s.eventBus.Stop()
+		}
+
+		// Deliver the outbox through the bus, it is stopped before the bus
+		if s.outboxWorker != nil {
+			s.outboxWorker.Start()
+			defer s.outboxWorker.Stop()
+		}
+
 		// Initialize and start the leaderboard worker
 		if s.leaderboardWorker != nil {
 			s.leaderboardWorker.Start()
 			defer s.leaderboardWorker.Stop()
 		}
 
+		// Start the item analytics worker, it is created in MapHandlers
+		if s.analyticsWorker != nil {
+			s.analyticsWorker.Start()
+			defer s.analyticsWorker.Stop()
+		}
+
+		// Start the lapsed streaks worker, it is created in MapHandlers
+		if s.streakWorker != nil {
+			s.streakWorker.Start()
+			defer s.streakWorker.Stop()
+		}
+
+		// Start the challenge expiry worker, it is created in MapHandlers
+		if s.challengeWorker != nil {
+			s.challengeWorker.Start()
+			defer s.challengeWorker.Stop()
+		}
+
+		// Start the quest worker, it is created in MapHandlers
+		if s.questWorker != nil {
+			s.questWorker.Start()
+			defer s.questWorker.Stop()
+		}
+
+		// Start the notification worker, it is created in MapHandlers
+		if s.notificationWorker != nil {
+			s.notificationWorker.Start()
+			defer s.notificationWorker.Stop()
+		}
+
+		// Start the streak reminder worker, it is created in MapHandlers
+		if s.reminderWorker != nil {
+			s.reminderWorker.Start()
+			defer s.reminderWorker.Stop()
+		}
+
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
@@ -123,12 +198,60 @@ func (s *Server) Run() error {
 		}
 	}()
 
+	// Start delivering domain events, the bus is created in MapHandlers
+	if s.eventBus != nil {
+		s.eventBus.Start()
+		defer s.eventBus.Stop()
+	}
+
+	// Deliver the outbox through the bus, it is stopped before the bus
+	if s.outboxWorker != nil {
+		s.outboxWorker.Start()
+		defer s.outboxWorker.Stop()
+	}
+
 	// Initialize and start the leaderboard worker
 	if s.leaderboardWorker != nil {
 		s.leaderboardWorker.Start()
 		defer s.leaderboardWorker.Stop()
 	}
 
+	// Start the item analytics worker, it is created in MapHandlers
+	if s.analyticsWorker != nil {
+		s.analyticsWorker.Start()
+		defer s.analyticsWorker.Stop()
+	}
+
+	// Start the lapsed streaks worker, it is created in MapHandlers
+	if s.streakWorker != nil {
+		s.streakWorker.Start()
+		defer s.streakWorker.Stop()
+	}
+
+	// Start the challenge expiry worker, it is created in MapHandlers
+	if s.challengeWorker != nil {
+		s.challengeWorker.Start()
+		defer s.challengeWorker.Stop()
+	}
+
+	// Start the quest worker, it is created in MapHandlers
+	if s.questWorker != nil {
+		s.questWorker.Start()
+		defer s.questWorker.Stop()
+	}
+
+	// Start the notification worker, it is created in MapHandlers
+	if s.notificationWorker != nil {
+		s.notificationWorker.Start()
+		defer s.notificationWorker.Stop()
+	}
+
+	// Start the streak reminder worker, it is created in MapHandlers
+	if s.reminderWorker != nil {
+		s.reminderWorker.Start()
+		defer s.reminderWorker.Stop()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
@@ -0,0 +1,23 @@
+package social
+
+import "github.com/labstack/echo/v4"
+
+// Social HTTP Handlers interface
+type Handlers interface {
+	GetPrivacySettings() echo.HandlerFunc
+	UpdatePrivacySettings() echo.HandlerFunc
+
+	Follow() echo.HandlerFunc
+	Unfollow() echo.HandlerFunc
+	GetFollowing() echo.HandlerFunc
+	GetFollowers() echo.HandlerFunc
+	RemoveFollower() echo.HandlerFunc
+	GetFollowRequests() echo.HandlerFunc
+	AcceptFollowRequest() echo.HandlerFunc
+
+	Block() echo.HandlerFunc
+	Unblock() echo.HandlerFunc
+	GetBlocks() echo.HandlerFunc
+
+	GetFeed() echo.HandlerFunc
+}
@@ -0,0 +1,428 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/social"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type socialHandlers struct {
+	socialUC social.UseCase
+	logger   logger.Logger
+}
+
+func NewSocialHandlers(socialUC social.UseCase, logger logger.Logger) social.Handlers {
+	return &socialHandlers{
+		socialUC: socialUC,
+		logger:   logger,
+	}
+}
+
+// GetPrivacySettings godoc
+// @Summary Get my privacy settings
+// @Description Whether follows need my approval and what my followers see
+// @Tags Social
+// @Produce json
+// @Success 200 {object} models.PrivacySettings
+// @Router /social/privacy [get]
+func (h *socialHandlers) GetPrivacySettings() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.GetPrivacySettings.GetUserIDFromContext"))
+		}
+
+		settings, err := h.socialUC.GetPrivacySettings(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetPrivacySettings.GetPrivacySettings"))
+		}
+
+		return c.JSON(http.StatusOK, settings)
+	}
+}
+
+// UpdatePrivacySettings godoc
+// @Summary Update my privacy settings
+// @Description Change the settings that are set, making the account public accepts the pending follow requests
+// @Tags Social
+// @Accept json
+// @Produce json
+// @Param body body models.PrivacySettingsUpdate true "Settings to change"
+// @Success 200 {object} models.PrivacySettings
+// @Router /social/privacy [put]
+func (h *socialHandlers) UpdatePrivacySettings() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.UpdatePrivacySettings.GetUserIDFromContext"))
+		}
+
+		update := &models.PrivacySettingsUpdate{}
+		if err := c.Bind(update); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.UpdatePrivacySettings.Bind"))
+		}
+
+		settings, err := h.socialUC.UpdatePrivacySettings(c.Request().Context(), userID, update)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.UpdatePrivacySettings.UpdatePrivacySettings"))
+		}
+
+		return c.JSON(http.StatusOK, settings)
+	}
+}
+
+// Follow godoc
+// @Summary Follow a user
+// @Description Follow a user, the follow is pending until they accept it when their account is private
+// @Tags Social
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} models.Follow
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /social/following/{user_id} [put]
+func (h *socialHandlers) Follow() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.Follow.GetUserIDFromContext"))
+		}
+
+		followeeID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.Follow.uuid.Parse"))
+		}
+
+		follow, err := h.socialUC.Follow(c.Request().Context(), userID, followeeID)
+		if err != nil {
+			return socialError(err, "socialHandlers.Follow.Follow")
+		}
+
+		return c.JSON(http.StatusOK, follow)
+	}
+}
+
+// Unfollow godoc
+// @Summary Unfollow a user
+// @Description Stop following a user or cancel the follow request
+// @Tags Social
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /social/following/{user_id} [delete]
+func (h *socialHandlers) Unfollow() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.Unfollow.GetUserIDFromContext"))
+		}
+
+		followeeID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.Unfollow.uuid.Parse"))
+		}
+
+		if err := h.socialUC.Unfollow(c.Request().Context(), userID, followeeID); err != nil {
+			return socialError(err, "socialHandlers.Unfollow.Unfollow")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// GetFollowing godoc
+// @Summary Get the users I follow
+// @Description Users I follow or asked to follow, newest first
+// @Tags Social
+// @Produce json
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.FollowList
+// @Router /social/following [get]
+func (h *socialHandlers) GetFollowing() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.GetFollowing.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFollowing.pageParams"))
+		}
+
+		following, err := h.socialUC.GetFollowing(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFollowing.GetFollowing"))
+		}
+
+		return c.JSON(http.StatusOK, following)
+	}
+}
+
+// GetFollowers godoc
+// @Summary Get my followers
+// @Description Users following me, newest first
+// @Tags Social
+// @Produce json
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.FollowList
+// @Router /social/followers [get]
+func (h *socialHandlers) GetFollowers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.GetFollowers.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFollowers.pageParams"))
+		}
+
+		followers, err := h.socialUC.GetFollowers(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFollowers.GetFollowers"))
+		}
+
+		return c.JSON(http.StatusOK, followers)
+	}
+}
+
+// RemoveFollower godoc
+// @Summary Remove a follower
+// @Description Remove a follower, or decline their follow request
+// @Tags Social
+// @Param user_id path string true "Follower ID"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /social/followers/{user_id} [delete]
+func (h *socialHandlers) RemoveFollower() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.RemoveFollower.GetUserIDFromContext"))
+		}
+
+		followerID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.RemoveFollower.uuid.Parse"))
+		}
+
+		if err := h.socialUC.RemoveFollower(c.Request().Context(), userID, followerID); err != nil {
+			return socialError(err, "socialHandlers.RemoveFollower.RemoveFollower")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// GetFollowRequests godoc
+// @Summary Get my follow requests
+// @Description Pending follow requests of users who want to follow me, newest first
+// @Tags Social
+// @Produce json
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.FollowList
+// @Router /social/requests [get]
+func (h *socialHandlers) GetFollowRequests() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.GetFollowRequests.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFollowRequests.pageParams"))
+		}
+
+		requests, err := h.socialUC.GetFollowRequests(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFollowRequests.GetFollowRequests"))
+		}
+
+		return c.JSON(http.StatusOK, requests)
+	}
+}
+
+// AcceptFollowRequest godoc
+// @Summary Accept a follow request
+// @Description Accept the pending follow request of a user
+// @Tags Social
+// @Produce json
+// @Param user_id path string true "Follower ID"
+// @Success 200 {object} models.Follow
+// @Failure 404 {object} httpErrors.RestError
+// @Router /social/requests/{user_id}/accept [post]
+func (h *socialHandlers) AcceptFollowRequest() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.AcceptFollowRequest.GetUserIDFromContext"))
+		}
+
+		followerID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.AcceptFollowRequest.uuid.Parse"))
+		}
+
+		follow, err := h.socialUC.AcceptFollowRequest(c.Request().Context(), userID, followerID)
+		if err != nil {
+			return socialError(err, "socialHandlers.AcceptFollowRequest.AcceptFollowRequest")
+		}
+
+		return c.JSON(http.StatusOK, follow)
+	}
+}
+
+// Block godoc
+// @Summary Block a user
+// @Description Block a user, the follows between us are removed and they cannot follow me again
+// @Tags Social
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /social/blocks/{user_id} [put]
+func (h *socialHandlers) Block() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.Block.GetUserIDFromContext"))
+		}
+
+		blockedID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.Block.uuid.Parse"))
+		}
+
+		if err := h.socialUC.Block(c.Request().Context(), userID, blockedID); err != nil {
+			return socialError(err, "socialHandlers.Block.Block")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// Unblock godoc
+// @Summary Unblock a user
+// @Description Unblock a user, the follows removed by the block are not restored
+// @Tags Social
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /social/blocks/{user_id} [delete]
+func (h *socialHandlers) Unblock() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.Unblock.GetUserIDFromContext"))
+		}
+
+		blockedID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.Unblock.uuid.Parse"))
+		}
+
+		if err := h.socialUC.Unblock(c.Request().Context(), userID, blockedID); err != nil {
+			return socialError(err, "socialHandlers.Unblock.Unblock")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// GetBlocks godoc
+// @Summary Get the users I blocked
+// @Description Users I blocked, newest first
+// @Tags Social
+// @Produce json
+// @Success 200 {array} models.Block
+// @Router /social/blocks [get]
+func (h *socialHandlers) GetBlocks() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.GetBlocks.GetUserIDFromContext"))
+		}
+
+		blocks, err := h.socialUC.GetBlocks(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetBlocks.GetBlocks"))
+		}
+
+		return c.JSON(http.StatusOK, blocks)
+	}
+}
+
+// GetFeed godoc
+// @Summary Get my activity feed
+// @Description Achievements earned and chapters completed by the users I follow, newest first
+// @Tags Social
+// @Produce json
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.ActivityFeed
+// @Router /social/feed [get]
+func (h *socialHandlers) GetFeed() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "socialHandlers.GetFeed.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFeed.pageParams"))
+		}
+
+		feed, err := h.socialUC.GetFeed(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "socialHandlers.GetFeed.GetFeed"))
+		}
+
+		return c.JSON(http.StatusOK, feed)
+	}
+}
+
+// socialError maps the social graph errors to their status
+func socialError(err error, op string) error {
+	switch {
+	case errors.Is(err, social.ErrUserNotFound),
+		errors.Is(err, social.ErrRequestNotFound),
+		errors.Is(err, social.ErrFollowNotFound),
+		errors.Is(err, social.ErrBlockNotFound):
+		return httpErrors.NewRestError(http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, social.ErrBlocked):
+		return httpErrors.NewRestError(http.StatusForbidden, err.Error(), nil)
+	default:
+		return httpErrors.NewBadRequestError(errors.Wrap(err, op))
+	}
+}
+
+func pageParams(c echo.Context) (int, int, error) {
+	var err error
+	limit, offset := 0, 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/social"
+)
+
+// Map social routes
+func MapSocialRoutes(socialGroup *echo.Group, h social.Handlers, mw *middleware.MiddlewareManager) {
+	protected := socialGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("/privacy", h.GetPrivacySettings())
+		protected.PUT("/privacy", h.UpdatePrivacySettings())
+
+		protected.GET("/following", h.GetFollowing())
+		protected.PUT("/following/:user_id", h.Follow())
+		protected.DELETE("/following/:user_id", h.Unfollow())
+
+		protected.GET("/followers", h.GetFollowers())
+		protected.DELETE("/followers/:user_id", h.RemoveFollower())
+
+		protected.GET("/requests", h.GetFollowRequests())
+		protected.POST("/requests/:user_id/accept", h.AcceptFollowRequest())
+		protected.DELETE("/requests/:user_id", h.RemoveFollower())
+
+		protected.GET("/blocks", h.GetBlocks())
+		protected.PUT("/blocks/:user_id", h.Block())
+		protected.DELETE("/blocks/:user_id", h.Unblock())
+
+		protected.GET("/feed", h.GetFeed())
+	}
+}
@@ -0,0 +1,14 @@
+package social
+
+import "errors"
+
+// Social graph errors
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrFollowSelf      = errors.New("you cannot follow yourself")
+	ErrBlockSelf       = errors.New("you cannot block yourself")
+	ErrBlocked         = errors.New("you cannot follow this user")
+	ErrRequestNotFound = errors.New("follow request not found")
+	ErrFollowNotFound  = errors.New("follow not found")
+	ErrBlockNotFound   = errors.New("block not found")
+)
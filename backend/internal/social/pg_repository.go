@@ -0,0 +1,39 @@
+package social
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Social Repository interface
+type Repository interface {
+	// Settings of an existing user, the defaults until they change them
+	GetPrivacySettings(ctx context.Context, userID uuid.UUID) (*models.PrivacySettings, error)
+	// Saves the settings, making an account public accepts its pending follow requests
+	UpdatePrivacySettings(ctx context.Context, settings *models.PrivacySettings) (*models.PrivacySettings, error)
+
+	// Follows a user, pending until accepted when their account is private. Rejected when either user blocked the other
+	Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (*models.Follow, error)
+	// Removes a follow or a follow request, reports whether there was one
+	DeleteFollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error)
+	AcceptFollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (*models.Follow, error)
+	// Users following the user with the given status, with the followers' profiles
+	GetFollowers(ctx context.Context, userID uuid.UUID, status string, limit int, offset int) (*models.FollowList, error)
+	// Whether the follower's follow of the followee was accepted
+	IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error)
+	// Users the user follows or asked to follow, with the followees' profiles
+	GetFollowing(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error)
+
+	// Blocks a user and removes the follows between both users
+	Block(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) (bool, error)
+	GetBlocks(ctx context.Context, userID uuid.UUID) ([]*models.Block, error)
+
+	// Saves an activity once per event, reports whether it was new
+	CreateActivity(ctx context.Context, activity *models.Activity) (bool, error)
+	// Activities of the users the user follows who share them, newest first
+	GetFeed(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.ActivityFeed, error)
+}
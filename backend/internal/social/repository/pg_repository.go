@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/social"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultPageSize = 50
+
+type socialRepo struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewSocialRepository(db *sqlx.DB, logger logger.Logger) social.Repository {
+	return &socialRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *socialRepo) GetPrivacySettings(ctx context.Context, userID uuid.UUID) (*models.PrivacySettings, error) {
+	settings := &models.PrivacySettings{}
+	if err := r.db.GetContext(ctx, settings, getPrivacySettingsQuery, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, social.ErrUserNotFound
+		}
+		return nil, errors.Wrap(err, "socialRepo.GetPrivacySettings.GetContext")
+	}
+
+	return settings, nil
+}
+
+func (r *socialRepo) UpdatePrivacySettings(ctx context.Context, settings *models.PrivacySettings) (*models.PrivacySettings, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "socialRepo.UpdatePrivacySettings.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	saved := &models.PrivacySettings{}
+	if err := tx.GetContext(
+		ctx,
+		saved,
+		upsertPrivacySettingsQuery,
+		settings.UserID,
+		settings.PrivateAccount,
+		settings.ShareActivity,
+		settings.ShowOnLeaderboards,
+	); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.UpdatePrivacySettings.upsertPrivacySettings")
+	}
+
+	if !saved.PrivateAccount {
+		if _, err := tx.ExecContext(ctx, acceptPendingFollowsQuery, settings.UserID); err != nil {
+			return nil, errors.Wrap(err, "socialRepo.UpdatePrivacySettings.acceptPendingFollows")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.UpdatePrivacySettings.Commit")
+	}
+
+	return saved, nil
+}
+
+func (r *socialRepo) Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (*models.Follow, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "socialRepo.Follow.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, lockPairQuery, followerID, followeeID); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.Follow.lockPair")
+	}
+
+	var blocked bool
+	if err := tx.GetContext(ctx, &blocked, isBlockedQuery, followerID, followeeID); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.Follow.isBlocked")
+	}
+	if blocked {
+		return nil, social.ErrBlocked
+	}
+
+	settings := &models.PrivacySettings{}
+	if err := tx.GetContext(ctx, settings, getPrivacySettingsQuery, followeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, social.ErrUserNotFound
+		}
+		return nil, errors.Wrap(err, "socialRepo.Follow.getPrivacySettings")
+	}
+
+	status := models.FollowStatusAccepted
+	if settings.PrivateAccount {
+		status = models.FollowStatusPending
+	}
+
+	follow := &models.Follow{}
+	if err := tx.GetContext(ctx, follow, createFollowQuery, followerID, followeeID, status); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.Follow.createFollow")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.Follow.Commit")
+	}
+
+	return follow, nil
+}
+
+func (r *socialRepo) DeleteFollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, deleteFollowQuery, followerID, followeeID)
+	if err != nil {
+		return false, errors.Wrap(err, "socialRepo.DeleteFollow.ExecContext")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "socialRepo.DeleteFollow.RowsAffected")
+	}
+
+	return rows > 0, nil
+}
+
+func (r *socialRepo) AcceptFollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (*models.Follow, error) {
+	follow := &models.Follow{}
+	if err := r.db.GetContext(ctx, follow, acceptFollowQuery, followerID, followeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, social.ErrRequestNotFound
+		}
+		return nil, errors.Wrap(err, "socialRepo.AcceptFollow.GetContext")
+	}
+
+	return follow, nil
+}
+
+func (r *socialRepo) GetFollowers(ctx context.Context, userID uuid.UUID, status string, limit int, offset int) (*models.FollowList, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countFollowersQuery, userID, status); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetFollowers.GetContext")
+	}
+
+	follows := make([]*models.Follow, 0, limit)
+	if err := r.db.SelectContext(ctx, &follows, getFollowersQuery, userID, status, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetFollowers.SelectContext")
+	}
+
+	return &models.FollowList{
+		TotalCount: totalCount,
+		Follows:    follows,
+	}, nil
+}
+
+func (r *socialRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	var following bool
+	if err := r.db.GetContext(ctx, &following, isFollowingQuery, followerID, followeeID); err != nil {
+		return false, errors.Wrap(err, "socialRepo.IsFollowing.GetContext")
+	}
+
+	return following, nil
+}
+
+func (r *socialRepo) GetFollowing(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countFollowingQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetFollowing.GetContext")
+	}
+
+	follows := make([]*models.Follow, 0, limit)
+	if err := r.db.SelectContext(ctx, &follows, getFollowingQuery, userID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetFollowing.SelectContext")
+	}
+
+	return &models.FollowList{
+		TotalCount: totalCount,
+		Follows:    follows,
+	}, nil
+}
+
+func (r *socialRepo) Block(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "socialRepo.Block.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, lockPairQuery, blockerID, blockedID); err != nil {
+		return errors.Wrap(err, "socialRepo.Block.lockPair")
+	}
+
+	if _, err := tx.ExecContext(ctx, createBlockQuery, blockerID, blockedID); err != nil {
+		return errors.Wrap(err, "socialRepo.Block.createBlock")
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteFollowsBetweenQuery, blockerID, blockedID); err != nil {
+		return errors.Wrap(err, "socialRepo.Block.deleteFollowsBetween")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "socialRepo.Block.Commit")
+	}
+
+	return nil
+}
+
+func (r *socialRepo) Unblock(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, deleteBlockQuery, blockerID, blockedID)
+	if err != nil {
+		return false, errors.Wrap(err, "socialRepo.Unblock.ExecContext")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "socialRepo.Unblock.RowsAffected")
+	}
+
+	return rows > 0, nil
+}
+
+func (r *socialRepo) GetBlocks(ctx context.Context, userID uuid.UUID) ([]*models.Block, error) {
+	blocks := make([]*models.Block, 0)
+	if err := r.db.SelectContext(ctx, &blocks, getBlocksQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetBlocks.SelectContext")
+	}
+
+	return blocks, nil
+}
+
+func (r *socialRepo) CreateActivity(ctx context.Context, activity *models.Activity) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		createActivityQuery,
+		activity.EventID,
+		activity.UserID,
+		activity.Type,
+		activity.SubjectID,
+		activity.OccurredAt,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "socialRepo.CreateActivity.ExecContext")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "socialRepo.CreateActivity.RowsAffected")
+	}
+
+	return rows > 0, nil
+}
+
+func (r *socialRepo) GetFeed(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.ActivityFeed, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countFeedQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetFeed.GetContext")
+	}
+
+	activities := make([]*models.Activity, 0, limit)
+	if err := r.db.SelectContext(ctx, &activities, getFeedQuery, userID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "socialRepo.GetFeed.SelectContext")
+	}
+
+	return &models.ActivityFeed{
+		TotalCount: totalCount,
+		Activities: activities,
+	}, nil
+}
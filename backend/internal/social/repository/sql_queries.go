@@ -0,0 +1,151 @@
+package repository
+
+const (
+	// Users without settings have the defaults of privacy_settings
+	getPrivacySettingsQuery = `
+		SELECT
+			u.user_id,
+			COALESCE(ps.private_account, true) AS private_account,
+			COALESCE(ps.share_activity, true) AS share_activity,
+			COALESCE(ps.show_on_leaderboards, true) AS show_on_leaderboards,
+			COALESCE(ps.updated_at, u.created_at) AS updated_at
+		FROM users u
+		LEFT JOIN privacy_settings ps ON ps.user_id = u.user_id
+		WHERE u.user_id = $1
+	`
+
+	upsertPrivacySettingsQuery = `
+		INSERT INTO privacy_settings (user_id, private_account, share_activity, show_on_leaderboards)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			private_account = EXCLUDED.private_account,
+			share_activity = EXCLUDED.share_activity,
+			show_on_leaderboards = EXCLUDED.show_on_leaderboards,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING user_id, private_account, share_activity, show_on_leaderboards, updated_at
+	`
+
+	acceptPendingFollowsQuery = `
+		UPDATE follows SET status = 'accepted', accepted_at = CURRENT_TIMESTAMP
+		WHERE followee_id = $1 AND status = 'pending'
+	`
+
+	// Serializes the follows and blocks between two users, whatever their order
+	lockPairQuery = `
+		SELECT pg_advisory_xact_lock(hashtext(LEAST($1::text, $2::text) || GREATEST($1::text, $2::text)))
+	`
+
+	isBlockedQuery = `
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`
+
+	// Following again keeps the existing follow, with the followee's profile
+	createFollowQuery = `
+		WITH f AS (
+			INSERT INTO follows (follower_id, followee_id, status, accepted_at)
+			VALUES ($1, $2, $3, CASE WHEN $3 = 'accepted' THEN CURRENT_TIMESTAMP END)
+			ON CONFLICT (follower_id, followee_id) DO UPDATE SET status = follows.status
+			RETURNING *
+		)
+		SELECT f.*, u.first_name, u.avatar FROM f
+		JOIN users u ON u.user_id = f.followee_id
+	`
+
+	deleteFollowQuery = `
+		DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2
+	`
+
+	// Accepts a pending follow, with the follower's profile
+	acceptFollowQuery = `
+		WITH f AS (
+			UPDATE follows SET status = 'accepted', accepted_at = CURRENT_TIMESTAMP
+			WHERE follower_id = $1 AND followee_id = $2 AND status = 'pending'
+			RETURNING *
+		)
+		SELECT f.*, u.first_name, u.avatar FROM f
+		JOIN users u ON u.user_id = f.follower_id
+	`
+
+	countFollowersQuery = `
+		SELECT COUNT(*) FROM follows WHERE followee_id = $1 AND status = $2
+	`
+
+	getFollowersQuery = `
+		SELECT f.*, u.first_name, u.avatar FROM follows f
+		JOIN users u ON u.user_id = f.follower_id
+		WHERE f.followee_id = $1 AND f.status = $2
+		ORDER BY f.created_at DESC, f.follower_id
+		LIMIT $3 OFFSET $4
+	`
+
+	isFollowingQuery = `
+		SELECT EXISTS (
+			SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2 AND status = 'accepted'
+		)
+	`
+
+	countFollowingQuery = `
+		SELECT COUNT(*) FROM follows WHERE follower_id = $1
+	`
+
+	getFollowingQuery = `
+		SELECT f.*, u.first_name, u.avatar FROM follows f
+		JOIN users u ON u.user_id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC, f.followee_id
+		LIMIT $2 OFFSET $3
+	`
+
+	createBlockQuery = `
+		INSERT INTO blocks (blocker_id, blocked_id) VALUES ($1, $2)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+	`
+
+	deleteFollowsBetweenQuery = `
+		DELETE FROM follows
+		WHERE (follower_id = $1 AND followee_id = $2) OR (follower_id = $2 AND followee_id = $1)
+	`
+
+	deleteBlockQuery = `
+		DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2
+	`
+
+	getBlocksQuery = `
+		SELECT b.blocked_id, u.first_name, u.avatar, b.created_at FROM blocks b
+		JOIN users u ON u.user_id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC
+	`
+
+	createActivityQuery = `
+		INSERT INTO activities (event_id, user_id, type, subject_id, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO NOTHING
+	`
+
+	// Activities of the accepted followees who share them, blocks remove the follows.
+	// The title is the achievement's or the chapter's
+	feedBaseQuery = `
+		FROM activities a
+		JOIN follows f ON f.followee_id = a.user_id AND f.follower_id = $1 AND f.status = 'accepted'
+		JOIN users u ON u.user_id = a.user_id
+		LEFT JOIN privacy_settings ps ON ps.user_id = a.user_id
+		LEFT JOIN achievements ach ON a.type = 'achievement_earned' AND ach.achievement_id = a.subject_id
+		LEFT JOIN chapters ch ON a.type = 'chapter_completed' AND ch.chapter_id = a.subject_id
+		WHERE COALESCE(ps.share_activity, true)
+	`
+
+	countFeedQuery = `SELECT COUNT(*) ` + feedBaseQuery
+
+	getFeedQuery = `
+		SELECT
+			a.activity_id, a.event_id, a.user_id, u.first_name, u.avatar, a.type, a.subject_id,
+			COALESCE(ach.title, ch.title, '') AS title, a.occurred_at
+		` + feedBaseQuery + `
+		ORDER BY a.occurred_at DESC, a.activity_id
+		LIMIT $2 OFFSET $3
+	`
+)
@@ -0,0 +1,49 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/social"
+)
+
+const subscriberName = "social"
+
+// RegisterSocialSubscribers adds the achievements users earn and the chapters they complete to their followers' feed
+func RegisterSocialSubscribers(bus events.Bus, socialUC social.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		activity := &models.Activity{
+			EventID:    event.EventID,
+			UserID:     event.UserID,
+			OccurredAt: event.OccurredAt,
+		}
+
+		switch event.Type {
+		case events.AchievementAwardedType:
+			payload := &events.AchievementAwarded{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "socialSubscriber.Decode")
+			}
+			activity.Type, activity.SubjectID = models.ActivityAchievementEarned, payload.AchievementID
+		case events.ChapterCompletedType:
+			payload := &events.ChapterCompleted{}
+			if err := event.Decode(payload); err != nil {
+				return errors.Wrap(err, "socialSubscriber.Decode")
+			}
+			activity.Type, activity.SubjectID = models.ActivityChapterCompleted, payload.ChapterID
+		default:
+			return nil
+		}
+
+		if err := socialUC.RecordActivity(ctx, activity); err != nil {
+			return errors.Wrap(err, "socialSubscriber.RecordActivity")
+		}
+		return nil
+	},
+		events.AchievementAwardedType,
+		events.ChapterCompletedType,
+	)
+}
@@ -0,0 +1,35 @@
+package social
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Social UseCase interface
+type UseCase interface {
+	GetPrivacySettings(ctx context.Context, userID uuid.UUID) (*models.PrivacySettings, error)
+	UpdatePrivacySettings(ctx context.Context, userID uuid.UUID, update *models.PrivacySettingsUpdate) (*models.PrivacySettings, error)
+
+	Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (*models.Follow, error)
+	// Stops following a user or cancels the follow request
+	Unfollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error
+	AcceptFollowRequest(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) (*models.Follow, error)
+	// Declines a follow request or removes a follower
+	RemoveFollower(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) error
+	GetFollowers(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error)
+	GetFollowRequests(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error)
+	GetFollowing(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error)
+	// Whether the follower follows the followee, a pending request does not count
+	IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error)
+
+	Block(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) error
+	GetBlocks(ctx context.Context, userID uuid.UUID) ([]*models.Block, error)
+
+	// Adds an earned achievement or a completed chapter to the followers' feed
+	RecordActivity(ctx context.Context, activity *models.Activity) error
+	GetFeed(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.ActivityFeed, error)
+}
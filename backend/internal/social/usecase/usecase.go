@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/social"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const maxPageSize = 100
+
+type socialUC struct {
+	socialRepo social.Repository
+	logger     logger.Logger
+}
+
+func NewSocialUseCase(socialRepo social.Repository, logger logger.Logger) social.UseCase {
+	return &socialUC{
+		socialRepo: socialRepo,
+		logger:     logger,
+	}
+}
+
+func (u *socialUC) GetPrivacySettings(ctx context.Context, userID uuid.UUID) (*models.PrivacySettings, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.GetPrivacySettings")
+	defer span.Finish()
+
+	return u.socialRepo.GetPrivacySettings(ctx, userID)
+}
+
+func (u *socialUC) UpdatePrivacySettings(
+	ctx context.Context,
+	userID uuid.UUID,
+	update *models.PrivacySettingsUpdate,
+) (*models.PrivacySettings, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.UpdatePrivacySettings")
+	defer span.Finish()
+
+	settings, err := u.socialRepo.GetPrivacySettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.PrivateAccount != nil {
+		settings.PrivateAccount = *update.PrivateAccount
+	}
+	if update.ShareActivity != nil {
+		settings.ShareActivity = *update.ShareActivity
+	}
+	if update.ShowOnLeaderboards != nil {
+		settings.ShowOnLeaderboards = *update.ShowOnLeaderboards
+	}
+
+	return u.socialRepo.UpdatePrivacySettings(ctx, settings)
+}
+
+func (u *socialUC) Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (*models.Follow, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.Follow")
+	defer span.Finish()
+
+	if followerID == followeeID {
+		return nil, social.ErrFollowSelf
+	}
+
+	return u.socialRepo.Follow(ctx, followerID, followeeID)
+}
+
+func (u *socialUC) Unfollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.Unfollow")
+	defer span.Finish()
+
+	deleted, err := u.socialRepo.DeleteFollow(ctx, followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return social.ErrFollowNotFound
+	}
+
+	return nil
+}
+
+func (u *socialUC) AcceptFollowRequest(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) (*models.Follow, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.AcceptFollowRequest")
+	defer span.Finish()
+
+	return u.socialRepo.AcceptFollow(ctx, followerID, userID)
+}
+
+func (u *socialUC) RemoveFollower(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.RemoveFollower")
+	defer span.Finish()
+
+	deleted, err := u.socialRepo.DeleteFollow(ctx, followerID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return social.ErrFollowNotFound
+	}
+
+	return nil
+}
+
+func (u *socialUC) GetFollowers(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.GetFollowers")
+	defer span.Finish()
+
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	return u.socialRepo.GetFollowers(ctx, userID, models.FollowStatusAccepted, limit, offset)
+}
+
+func (u *socialUC) GetFollowRequests(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.GetFollowRequests")
+	defer span.Finish()
+
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	return u.socialRepo.GetFollowers(ctx, userID, models.FollowStatusPending, limit, offset)
+}
+
+func (u *socialUC) GetFollowing(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.FollowList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.GetFollowing")
+	defer span.Finish()
+
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	return u.socialRepo.GetFollowing(ctx, userID, limit, offset)
+}
+
+func (u *socialUC) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.IsFollowing")
+	defer span.Finish()
+
+	return u.socialRepo.IsFollowing(ctx, followerID, followeeID)
+}
+
+func (u *socialUC) Block(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.Block")
+	defer span.Finish()
+
+	if userID == blockedID {
+		return social.ErrBlockSelf
+	}
+
+	// Only existing users can be blocked
+	if _, err := u.socialRepo.GetPrivacySettings(ctx, blockedID); err != nil {
+		return err
+	}
+
+	return u.socialRepo.Block(ctx, userID, blockedID)
+}
+
+func (u *socialUC) Unblock(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.Unblock")
+	defer span.Finish()
+
+	deleted, err := u.socialRepo.Unblock(ctx, userID, blockedID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return social.ErrBlockNotFound
+	}
+
+	return nil
+}
+
+func (u *socialUC) GetBlocks(ctx context.Context, userID uuid.UUID) ([]*models.Block, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.GetBlocks")
+	defer span.Finish()
+
+	return u.socialRepo.GetBlocks(ctx, userID)
+}
+
+func (u *socialUC) RecordActivity(ctx context.Context, activity *models.Activity) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.RecordActivity")
+	defer span.Finish()
+
+	created, err := u.socialRepo.CreateActivity(ctx, activity)
+	if err != nil {
+		return err
+	}
+	if !created {
+		u.logger.Debugf("socialUC.RecordActivity: activity of event %s already recorded", activity.EventID)
+	}
+
+	return nil
+}
+
+func (u *socialUC) GetFeed(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.ActivityFeed, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "socialUC.GetFeed")
+	defer span.Finish()
+
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	return u.socialRepo.GetFeed(ctx, userID, limit, offset)
+}
+
+func validatePage(limit int, offset int) error {
+	if limit > maxPageSize {
+		return errors.Errorf("limit must not exceed %d", maxPageSize)
+	}
+	if offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package streak
+
+import "github.com/labstack/echo/v4"
+
+// Streak HTTP Handlers interface
+type Handlers interface {
+	GetStreak() echo.HandlerFunc
+	RepairStreak() echo.HandlerFunc
+	SetDailyGoal() echo.HandlerFunc
+}
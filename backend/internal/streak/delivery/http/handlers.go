@@ -0,0 +1,109 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type streakHandlers struct {
+	streakUC streak.UseCase
+	logger   logger.Logger
+}
+
+func NewStreakHandlers(streakUC streak.UseCase, logger logger.Logger) streak.Handlers {
+	return &streakHandlers{
+		streakUC: streakUC,
+		logger:   logger,
+	}
+}
+
+// GetStreak godoc
+// @Summary Get my daily streak
+// @Description Current streak, freezes, repair window and the last 30 days, as of the user's local day
+// @Tags Streak
+// @Produce json
+// @Success 200 {object} models.StreakStatus
+// @Router /streak [get]
+func (h *streakHandlers) GetStreak() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "streakHandlers.GetStreak.GetUserIDFromContext"))
+		}
+
+		status, err := h.streakUC.GetStreak(c.Request().Context(), userID)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "streakHandlers.GetStreak.GetStreak"))
+		}
+
+		return c.JSON(http.StatusOK, status)
+	}
+}
+
+// RepairStreak godoc
+// @Summary Repair my broken streak
+// @Description Restore the last broken streak while the repair window is open
+// @Tags Streak
+// @Produce json
+// @Success 200 {object} models.StreakChange
+// @Failure 409 {object} httpErrors.RestError
+// @Router /streak/repair [post]
+func (h *streakHandlers) RepairStreak() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "streakHandlers.RepairStreak.GetUserIDFromContext"))
+		}
+
+		change, err := h.streakUC.RepairStreak(c.Request().Context(), userID)
+		if err != nil {
+			if errors.Is(err, streak.ErrNothingToRepair) || errors.Is(err, streak.ErrRepairWindowExpired) {
+				return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+			}
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "streakHandlers.RepairStreak.RepairStreak"))
+		}
+
+		return c.JSON(http.StatusOK, change)
+	}
+}
+
+// SetDailyGoal godoc
+// @Summary Set my daily XP goal
+// @Description With a goal a day only counts towards the streak once the XP earned that day meets it, 0 removes the goal
+// @Tags Streak
+// @Accept json
+// @Produce json
+// @Param body body models.DailyGoalRequest true "Daily XP goal"
+// @Success 200 {object} models.DailyGoal
+// @Router /streak/goal [put]
+func (h *streakHandlers) SetDailyGoal() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "streakHandlers.SetDailyGoal.GetUserIDFromContext"))
+		}
+
+		req := &models.DailyGoalRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "streakHandlers.SetDailyGoal.Bind"))
+		}
+		if err := utils.ValidateStruct(c.Request().Context(), req); err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "streakHandlers.SetDailyGoal.ValidateStruct"))
+		}
+
+		goal, err := h.streakUC.SetDailyGoal(c.Request().Context(), userID, req.DailyXPGoal)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "streakHandlers.SetDailyGoal.SetDailyGoal"))
+		}
+
+		return c.JSON(http.StatusOK, goal)
+	}
+}
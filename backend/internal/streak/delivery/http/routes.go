@@ -0,0 +1,19 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+)
+
+// Map streak routes
+func MapStreakRoutes(streakGroup *echo.Group, h streak.Handlers, mw *middleware.MiddlewareManager) {
+	protected := streakGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("", h.GetStreak())
+		protected.POST("/repair", h.RepairStreak())
+		protected.PUT("/goal", h.SetDailyGoal())
+	}
+}
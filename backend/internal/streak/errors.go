@@ -0,0 +1,9 @@
+package streak
+
+import "errors"
+
+// Streak repair errors
+var (
+	ErrNothingToRepair     = errors.New("there is no broken streak to repair")
+	ErrRepairWindowExpired = errors.New("the repair window for the broken streak has passed")
+)
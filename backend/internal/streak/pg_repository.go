@@ -0,0 +1,35 @@
+package streak
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// UpdateFunc applies a change to a locked streak, loc is the user's timezone.
+// Returning a nil change leaves the streak untouched.
+type UpdateFunc func(streak *models.DailyStreak, loc *time.Location) (*models.StreakChange, error)
+
+// Streak Repository interface
+type Repository interface {
+	// Locks the user's streak, applies update and saves the result in a single transaction
+	UpdateStreak(ctx context.Context, userID uuid.UUID, update UpdateFunc) (*models.StreakChange, error)
+
+	GetStreak(ctx context.Context, userID uuid.UUID) (*models.DailyStreak, error)
+	// Current streak length, also serves the leaderboard
+	GetUserStreak(ctx context.Context, userID uuid.UUID) (int, error)
+	GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error)
+	GetStreakDays(ctx context.Context, userID uuid.UUID, from time.Time) ([]*models.StreakDay, error)
+
+	// Daily XP goal, 0 when the user set none
+	GetDailyXPGoal(ctx context.Context, userID uuid.UUID) (int, error)
+	UpdateDailyXPGoal(ctx context.Context, userID uuid.UUID, goal int) error
+	// XP the user earned in [from, to)
+	GetXPEarned(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) (int, error)
+
+	// Users with a running streak whose last covered day is before their local yesterday
+	GetLapsedStreakUserIDs(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultLapsedLimit = 500
+
+type streakRepo struct {
+	db       *sqlx.DB
+	recorder events.Recorder
+	logger   logger.Logger
+}
+
+func NewStreakRepository(db *sqlx.DB, recorder events.Recorder, logger logger.Logger) streak.Repository {
+	return &streakRepo{
+		db:       db,
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+func (r *streakRepo) UpdateStreak(ctx context.Context, userID uuid.UUID, update streak.UpdateFunc) (*models.StreakChange, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, ensureStreakQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.ensureStreak")
+	}
+
+	current := &models.DailyStreak{}
+	if err := tx.GetContext(ctx, current, lockStreakQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.lockStreak")
+	}
+
+	var timezone string
+	if err := tx.GetContext(ctx, &timezone, getUserTimezoneQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.getUserTimezone")
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.LoadLocation")
+	}
+
+	change, err := update(current, loc)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return &models.StreakChange{Streak: current}, nil
+	}
+
+	saved := &models.DailyStreak{}
+	if err := tx.QueryRowxContext(
+		ctx,
+		updateStreakQuery,
+		current.CurrentStreak,
+		current.MaxStreak,
+		current.LastActivity,
+		current.LastActivityDate,
+		current.Freezes,
+		current.BrokenStreak,
+		current.BrokenOn,
+		current.StreakID,
+	).StructScan(saved); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.updateStreak")
+	}
+
+	if _, err := tx.ExecContext(ctx, updateUserStreakQuery, saved.CurrentStreak, userID); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.updateUserStreak")
+	}
+
+	for _, day := range change.FrozenDays {
+		if _, err := tx.ExecContext(ctx, createFrozenDayQuery, userID, day); err != nil {
+			return nil, errors.Wrap(err, "streakRepo.UpdateStreak.createFrozenDay")
+		}
+	}
+	if change.ActivityDay != nil {
+		if _, err := tx.ExecContext(ctx, upsertActivityDayQuery, userID, *change.ActivityDay); err != nil {
+			return nil, errors.Wrap(err, "streakRepo.UpdateStreak.upsertActivityDay")
+		}
+	}
+
+	// An activity on a day that already counted changes nothing worth announcing
+	if change.Extended || change.Broken || change.Repaired || change.FreezesUsed > 0 {
+		if err := r.recorder.Record(ctx, tx, userID, &events.StreakUpdated{
+			CurrentStreak: saved.CurrentStreak,
+			MaxStreak:     saved.MaxStreak,
+			Extended:      change.Extended,
+			Broken:        change.Broken,
+			Repaired:      change.Repaired,
+			FreezesUsed:   change.FreezesUsed,
+			BrokenStreak:  saved.BrokenStreak,
+		}); err != nil {
+			return nil, errors.Wrap(err, "streakRepo.UpdateStreak.Record")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.UpdateStreak.Commit")
+	}
+
+	change.Streak = saved
+	return change, nil
+}
+
+func (r *streakRepo) GetStreak(ctx context.Context, userID uuid.UUID) (*models.DailyStreak, error) {
+	current := &models.DailyStreak{}
+	if err := r.db.GetContext(ctx, current, getStreakQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.GetStreak.GetContext")
+	}
+	return current, nil
+}
+
+func (r *streakRepo) GetUserStreak(ctx context.Context, userID uuid.UUID) (int, error) {
+	var currentStreak int
+	if err := r.db.GetContext(ctx, &currentStreak, getCurrentStreakQuery, userID); err != nil {
+		return 0, errors.Wrap(err, "streakRepo.GetUserStreak.GetContext")
+	}
+	return currentStreak, nil
+}
+
+func (r *streakRepo) GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error) {
+	var timezone string
+	if err := r.db.GetContext(ctx, &timezone, getUserTimezoneQuery, userID); err != nil {
+		return "", errors.Wrap(err, "streakRepo.GetUserTimezone.GetContext")
+	}
+	return timezone, nil
+}
+
+func (r *streakRepo) GetStreakDays(ctx context.Context, userID uuid.UUID, from time.Time) ([]*models.StreakDay, error) {
+	days := make([]*models.StreakDay, 0)
+	if err := r.db.SelectContext(ctx, &days, getStreakDaysQuery, userID, from); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.GetStreakDays.SelectContext")
+	}
+	return days, nil
+}
+
+func (r *streakRepo) GetDailyXPGoal(ctx context.Context, userID uuid.UUID) (int, error) {
+	var goal int
+	if err := r.db.GetContext(ctx, &goal, getDailyXPGoalQuery, userID); err != nil {
+		return 0, errors.Wrap(err, "streakRepo.GetDailyXPGoal.GetContext")
+	}
+	return goal, nil
+}
+
+func (r *streakRepo) UpdateDailyXPGoal(ctx context.Context, userID uuid.UUID, goal int) error {
+	if _, err := r.db.ExecContext(ctx, updateDailyXPGoalQuery, goal, userID); err != nil {
+		return errors.Wrap(err, "streakRepo.UpdateDailyXPGoal.ExecContext")
+	}
+	return nil
+}
+
+func (r *streakRepo) GetXPEarned(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) (int, error) {
+	var xp int
+	if err := r.db.GetContext(ctx, &xp, getXPEarnedQuery, userID, from, to); err != nil {
+		return 0, errors.Wrap(err, "streakRepo.GetXPEarned.GetContext")
+	}
+	return xp, nil
+}
+
+func (r *streakRepo) GetLapsedStreakUserIDs(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	if limit <= 0 {
+		limit = defaultLapsedLimit
+	}
+
+	userIDs := make([]uuid.UUID, 0)
+	if err := r.db.SelectContext(ctx, &userIDs, getLapsedStreakUserIDsQuery, limit); err != nil {
+		return nil, errors.Wrap(err, "streakRepo.GetLapsedStreakUserIDs.SelectContext")
+	}
+	return userIDs, nil
+}
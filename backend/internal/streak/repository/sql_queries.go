@@ -0,0 +1,68 @@
+package repository
+
+const (
+	// Users created before the streak engine may have no row yet
+	ensureStreakQuery = `
+		INSERT INTO daily_streaks (user_id, current_streak, max_streak, last_activity)
+		VALUES ($1, 0, 0, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+
+	lockStreakQuery = `SELECT * FROM daily_streaks WHERE user_id = $1 FOR UPDATE`
+
+	getStreakQuery = `SELECT * FROM daily_streaks WHERE user_id = $1`
+
+	getCurrentStreakQuery = `SELECT current_streak FROM daily_streaks WHERE user_id = $1`
+
+	getUserTimezoneQuery = `SELECT timezone FROM users WHERE user_id = $1`
+
+	updateStreakQuery = `
+		UPDATE daily_streaks
+		SET current_streak = $1, max_streak = $2, last_activity = $3, last_activity_date = $4,
+			freezes = $5, broken_streak = $6, broken_on = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE streak_id = $8
+		RETURNING *
+	`
+
+	updateUserStreakQuery = `UPDATE users SET streak = $1 WHERE user_id = $2`
+
+	upsertActivityDayQuery = `
+		INSERT INTO streak_days (user_id, activity_date, activities)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (user_id, activity_date) DO UPDATE SET
+			activities = streak_days.activities + 1,
+			frozen = false,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	createFrozenDayQuery = `
+		INSERT INTO streak_days (user_id, activity_date, frozen)
+		VALUES ($1, $2, true)
+		ON CONFLICT (user_id, activity_date) DO NOTHING
+	`
+
+	getStreakDaysQuery = `
+		SELECT * FROM streak_days
+		WHERE user_id = $1 AND activity_date >= $2
+		ORDER BY activity_date DESC
+	`
+
+	getDailyXPGoalQuery = `SELECT daily_xp_goal FROM users WHERE user_id = $1`
+
+	updateDailyXPGoalQuery = `UPDATE users SET daily_xp_goal = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2`
+
+	getXPEarnedQuery = `
+		SELECT COALESCE(SUM(amount), 0) FROM xp_transactions
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+	`
+
+	getLapsedStreakUserIDsQuery = `
+		SELECT ds.user_id
+		FROM daily_streaks ds
+		JOIN users u ON u.user_id = ds.user_id
+		WHERE ds.current_streak > 0
+			AND ds.last_activity_date < (CURRENT_TIMESTAMP AT TIME ZONE u.timezone)::date - 1
+		ORDER BY ds.last_activity_date
+		LIMIT $1
+	`
+)
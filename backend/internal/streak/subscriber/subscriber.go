@@ -0,0 +1,24 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+)
+
+const subscriberName = "streak"
+
+// RegisterStreakSubscribers counts the day towards the streak of a user with a daily XP goal once awarded XP meets it
+func RegisterStreakSubscribers(bus events.Bus, streakUC streak.UseCase) {
+	bus.Subscribe(subscriberName, func(ctx context.Context, event *events.Event) error {
+		if _, err := streakUC.RecordXP(ctx, event.UserID, event.OccurredAt); err != nil {
+			return errors.Wrap(err, "streakSubscriber.RecordXP")
+		}
+		return nil
+	},
+		events.XPAwardedType,
+	)
+}
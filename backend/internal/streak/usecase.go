@@ -0,0 +1,27 @@
+package streak
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Streak UseCase interface
+type UseCase interface {
+	// Count a qualifying activity (lesson completion, quiz submit) on the user's local day of at,
+	// once the XP earned that day meets the user's daily goal when they set one
+	RecordActivity(ctx context.Context, userID uuid.UUID, at time.Time) (*models.StreakChange, error)
+	// Count the day of at when XP awarded then completed the user's daily goal, nil for users without a goal
+	RecordXP(ctx context.Context, userID uuid.UUID, at time.Time) (*models.StreakChange, error)
+	GetStreak(ctx context.Context, userID uuid.UUID) (*models.StreakStatus, error)
+	// Restore the last broken streak within the repair window
+	RepairStreak(ctx context.Context, userID uuid.UUID) (*models.StreakChange, error)
+	// Set the daily XP goal, 0 removes it, and get the progress on the user's local day
+	SetDailyGoal(ctx context.Context, userID uuid.UUID, goal int) (*models.DailyGoal, error)
+
+	// Break or freeze the streaks whose users missed their local yesterday
+	BreakLapsedStreaks(ctx context.Context) (*models.StreakJobReport, error)
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+)
+
+// localDay is the calendar day of t in loc, at midnight UTC like the postgres DATE values
+func localDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// catchUp covers the days missed between the last covered day and yesterday with freezes,
+// or breaks the streak when there are not enough of them. Freezes are never spent on a streak that breaks anyway.
+func catchUp(current *models.DailyStreak, today time.Time, change *models.StreakChange) {
+	if current.CurrentStreak == 0 || current.LastActivityDate == nil {
+		return
+	}
+
+	missed := daysBetween(*current.LastActivityDate, today) - 1
+	if missed <= 0 {
+		return
+	}
+
+	if missed <= current.Freezes {
+		for i := 1; i <= missed; i++ {
+			change.FrozenDays = append(change.FrozenDays, current.LastActivityDate.AddDate(0, 0, i))
+		}
+		current.Freezes -= missed
+		change.FreezesUsed += missed
+
+		yesterday := today.AddDate(0, 0, -1)
+		current.LastActivityDate = &yesterday
+		return
+	}
+
+	brokenOn := current.LastActivityDate.AddDate(0, 0, 1)
+	current.BrokenStreak = current.CurrentStreak
+	current.BrokenOn = &brokenOn
+	current.CurrentStreak = 0
+	change.Broken = true
+}
+
+// applyActivity counts a qualifying activity on today, the streak grows at most once per local day
+func applyActivity(cfg config.StreakConfig, current *models.DailyStreak, today time.Time, at time.Time) *models.StreakChange {
+	change := &models.StreakChange{ActivityDay: &today}
+	catchUp(current, today, change)
+	current.LastActivity = at
+
+	// A timezone change can move today before the last covered day, it counts as the same day
+	if current.CurrentStreak > 0 && current.LastActivityDate != nil && !current.LastActivityDate.Before(today) {
+		return change
+	}
+
+	current.CurrentStreak++
+	current.LastActivityDate = &today
+	if current.CurrentStreak > current.MaxStreak {
+		current.MaxStreak = current.CurrentStreak
+	}
+	change.Extended = true
+
+	if cfg.FreezeEveryDays > 0 && current.CurrentStreak%cfg.FreezeEveryDays == 0 && current.Freezes < cfg.MaxFreezes {
+		current.Freezes++
+		change.FreezeEarned = true
+	}
+
+	return change
+}
+
+// repairDeadline is the last local day the broken streak can be repaired on
+func repairDeadline(cfg config.StreakConfig, current *models.DailyStreak) *time.Time {
+	if current.BrokenStreak == 0 || current.BrokenOn == nil {
+		return nil
+	}
+	deadline := current.BrokenOn.AddDate(0, 0, cfg.RepairWindowDays)
+	return &deadline
+}
+
+// applyRepair restores the broken streak, activity since the break continues the restored streak
+func applyRepair(cfg config.StreakConfig, current *models.DailyStreak, today time.Time) (*models.StreakChange, error) {
+	change := &models.StreakChange{}
+	catchUp(current, today, change)
+
+	deadline := repairDeadline(cfg, current)
+	if deadline == nil {
+		return nil, streak.ErrNothingToRepair
+	}
+	if today.After(*deadline) {
+		return nil, streak.ErrRepairWindowExpired
+	}
+
+	if current.CurrentStreak == 0 {
+		// Bridge the missed days so that activity today extends the restored streak
+		yesterday := today.AddDate(0, 0, -1)
+		current.LastActivityDate = &yesterday
+	}
+	current.CurrentStreak += current.BrokenStreak
+	current.BrokenStreak = 0
+	current.BrokenOn = nil
+	if current.CurrentStreak > current.MaxStreak {
+		current.MaxStreak = current.CurrentStreak
+	}
+	change.Repaired = true
+
+	return change, nil
+}
+
+// applyLapse settles the missed days without activity, nil when there is nothing to settle
+func applyLapse(current *models.DailyStreak, today time.Time) *models.StreakChange {
+	change := &models.StreakChange{}
+	catchUp(current, today, change)
+	if !change.Broken && change.FreezesUsed == 0 {
+		return nil
+	}
+	return change
+}
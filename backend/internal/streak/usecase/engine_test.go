@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // the zones below must load on hosts without a zoneinfo database
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+)
+
+var testStreakConfig = config.StreakConfig{FreezeEveryDays: 7, MaxFreezes: 2, RepairWindowDays: 3}
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func day(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func dayPtr(s string) *time.Time {
+	d := day(s)
+	return &d
+}
+
+func TestLocalDay(t *testing.T) {
+	tests := []struct {
+		name     string
+		at       string
+		timezone string
+		want     string
+	}{
+		{name: "utc", at: "2026-03-10T12:00:00Z", timezone: "UTC", want: "2026-03-10"},
+		{name: "before local midnight west of utc", at: "2026-03-11T03:59:59Z", timezone: "America/New_York", want: "2026-03-10"},
+		{name: "at local midnight west of utc", at: "2026-03-11T04:00:00Z", timezone: "America/New_York", want: "2026-03-11"},
+		{name: "east of utc is already tomorrow", at: "2026-03-10T15:00:00Z", timezone: "Asia/Tokyo", want: "2026-03-11"},
+		{name: "half hour offset", at: "2026-03-10T18:29:59Z", timezone: "Asia/Kolkata", want: "2026-03-10"},
+		{name: "half hour offset after midnight", at: "2026-03-10T18:30:00Z", timezone: "Asia/Kolkata", want: "2026-03-11"},
+		{name: "spring forward day", at: "2026-03-08T12:00:00Z", timezone: "America/New_York", want: "2026-03-08"},
+		{name: "last second of the short day", at: "2026-03-09T03:59:59Z", timezone: "America/New_York", want: "2026-03-08"},
+		{name: "last second of the long day", at: "2026-11-02T04:59:59Z", timezone: "America/New_York", want: "2026-11-01"},
+		{name: "year rollover", at: "2026-12-31T23:30:00Z", timezone: "Europe/Berlin", want: "2027-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := localDay(at, mustLocation(t, tt.timezone))
+			if !got.Equal(day(tt.want)) {
+				t.Errorf("localDay(%s, %s) = %s, want %s", tt.at, tt.timezone, got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
+
+func TestDaysBetweenAcrossDST(t *testing.T) {
+	// Local days are UTC midnights, so a 23 or 25 hour local day still counts as one
+	ny := mustLocation(t, "America/New_York")
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want int
+	}{
+		{
+			name: "spring forward",
+			from: localDay(time.Date(2026, 3, 7, 23, 0, 0, 0, ny), ny),
+			to:   localDay(time.Date(2026, 3, 9, 0, 30, 0, 0, ny), ny),
+			want: 2,
+		},
+		{
+			name: "fall back",
+			from: localDay(time.Date(2026, 10, 31, 23, 0, 0, 0, ny), ny),
+			to:   localDay(time.Date(2026, 11, 2, 0, 30, 0, 0, ny), ny),
+			want: 2,
+		},
+		{name: "same day", from: day("2026-03-08"), to: day("2026-03-08"), want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := daysBetween(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: daysBetween = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyActivity(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      models.DailyStreak
+		today        string
+		wantStreak   int
+		wantFreezes  int
+		wantExtended bool
+		wantBroken   bool
+		wantUsed     int
+		wantEarned   bool
+	}{
+		{
+			name:         "first activity",
+			current:      models.DailyStreak{},
+			today:        "2026-03-08",
+			wantStreak:   1,
+			wantExtended: true,
+		},
+		{
+			name:       "same day counts once",
+			current:    models.DailyStreak{CurrentStreak: 3, MaxStreak: 3, LastActivityDate: dayPtr("2026-03-08")},
+			today:      "2026-03-08",
+			wantStreak: 3,
+		},
+		{
+			name:       "timezone change back to yesterday counts as the same day",
+			current:    models.DailyStreak{CurrentStreak: 3, MaxStreak: 3, LastActivityDate: dayPtr("2026-03-08")},
+			today:      "2026-03-07",
+			wantStreak: 3,
+		},
+		{
+			name:         "next day across midnight",
+			current:      models.DailyStreak{CurrentStreak: 3, MaxStreak: 3, LastActivityDate: dayPtr("2026-12-31")},
+			today:        "2027-01-01",
+			wantStreak:   4,
+			wantExtended: true,
+		},
+		{
+			name:         "missed day covered by a freeze",
+			current:      models.DailyStreak{CurrentStreak: 3, MaxStreak: 3, Freezes: 1, LastActivityDate: dayPtr("2026-03-08")},
+			today:        "2026-03-10",
+			wantStreak:   4,
+			wantExtended: true,
+			wantUsed:     1,
+		},
+		{
+			name:         "not enough freezes keeps them and breaks",
+			current:      models.DailyStreak{CurrentStreak: 3, MaxStreak: 3, Freezes: 1, LastActivityDate: dayPtr("2026-03-08")},
+			today:        "2026-03-11",
+			wantStreak:   1,
+			wantFreezes:  1,
+			wantExtended: true,
+			wantBroken:   true,
+		},
+		{
+			name:         "freeze earned on the seventh day",
+			current:      models.DailyStreak{CurrentStreak: 6, MaxStreak: 6, LastActivityDate: dayPtr("2026-03-07")},
+			today:        "2026-03-08",
+			wantStreak:   7,
+			wantFreezes:  1,
+			wantExtended: true,
+			wantEarned:   true,
+		},
+		{
+			name:         "no freeze past the maximum",
+			current:      models.DailyStreak{CurrentStreak: 13, MaxStreak: 13, Freezes: 2, LastActivityDate: dayPtr("2026-03-07")},
+			today:        "2026-03-08",
+			wantStreak:   14,
+			wantFreezes:  2,
+			wantExtended: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current
+			change := applyActivity(testStreakConfig, &current, day(tt.today), time.Now())
+
+			if current.CurrentStreak != tt.wantStreak {
+				t.Errorf("streak = %d, want %d", current.CurrentStreak, tt.wantStreak)
+			}
+			if current.Freezes != tt.wantFreezes {
+				t.Errorf("freezes = %d, want %d", current.Freezes, tt.wantFreezes)
+			}
+			if change.Extended != tt.wantExtended {
+				t.Errorf("extended = %v, want %v", change.Extended, tt.wantExtended)
+			}
+			if change.Broken != tt.wantBroken {
+				t.Errorf("broken = %v, want %v", change.Broken, tt.wantBroken)
+			}
+			if change.FreezesUsed != tt.wantUsed || len(change.FrozenDays) != tt.wantUsed {
+				t.Errorf("freezes used = %d over %d days, want %d", change.FreezesUsed, len(change.FrozenDays), tt.wantUsed)
+			}
+			if change.FreezeEarned != tt.wantEarned {
+				t.Errorf("freeze earned = %v, want %v", change.FreezeEarned, tt.wantEarned)
+			}
+			if current.MaxStreak < current.CurrentStreak {
+				t.Errorf("max streak %d is below the current streak %d", current.MaxStreak, current.CurrentStreak)
+			}
+		})
+	}
+}
+
+func TestStreakAcrossDSTInUserTimezone(t *testing.T) {
+	// One activity every local evening over the spring forward and fall back nights keeps the streak going
+	ny := mustLocation(t, "America/New_York")
+	for _, start := range []time.Time{
+		time.Date(2026, 3, 6, 23, 30, 0, 0, ny),
+		time.Date(2026, 10, 30, 23, 30, 0, 0, ny),
+	} {
+		current := &models.DailyStreak{}
+		for i := 0; i < 5; i++ {
+			at := start.AddDate(0, 0, i)
+			change := applyActivity(testStreakConfig, current, localDay(at, ny), at)
+			if !change.Extended || change.Broken || change.FreezesUsed != 0 {
+				t.Fatalf("activity on %s: extended %v, broken %v, freezes used %d",
+					at.Format(time.RFC3339), change.Extended, change.Broken, change.FreezesUsed)
+			}
+		}
+		if current.CurrentStreak != 5 {
+			t.Errorf("streak from %s = %d, want 5", start.Format("2006-01-02"), current.CurrentStreak)
+		}
+	}
+}
+
+func TestApplyRepair(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    models.DailyStreak
+		today      string
+		wantErr    error
+		wantStreak int
+	}{
+		{
+			name:    "nothing broken",
+			current: models.DailyStreak{CurrentStreak: 2, LastActivityDate: dayPtr("2026-03-08")},
+			today:   "2026-03-08",
+			wantErr: streak.ErrNothingToRepair,
+		},
+		{
+			name:       "last day of the window",
+			current:    models.DailyStreak{BrokenStreak: 10, BrokenOn: dayPtr("2026-03-05")},
+			today:      "2026-03-08",
+			wantStreak: 10,
+		},
+		{
+			name:    "day after the window",
+			current: models.DailyStreak{BrokenStreak: 10, BrokenOn: dayPtr("2026-03-05")},
+			today:   "2026-03-09",
+			wantErr: streak.ErrRepairWindowExpired,
+		},
+		{
+			name: "activity since the break continues the restored streak",
+			current: models.DailyStreak{
+				CurrentStreak: 2, LastActivityDate: dayPtr("2026-03-07"), BrokenStreak: 10, BrokenOn: dayPtr("2026-03-05"),
+			},
+			today:      "2026-03-08",
+			wantStreak: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current
+			change, err := applyRepair(testStreakConfig, &current, day(tt.today))
+			if err != tt.wantErr {
+				t.Fatalf("applyRepair error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !change.Repaired {
+				t.Errorf("repaired = false, want true")
+			}
+			if current.CurrentStreak != tt.wantStreak {
+				t.Errorf("streak = %d, want %d", current.CurrentStreak, tt.wantStreak)
+			}
+			if current.BrokenStreak != 0 || current.BrokenOn != nil {
+				t.Errorf("broken streak %d on %v was not cleared", current.BrokenStreak, current.BrokenOn)
+			}
+
+			// Activity on the repair day extends the restored streak instead of starting over
+			next := applyActivity(testStreakConfig, &current, day(tt.today), time.Now())
+			if next.Broken {
+				t.Errorf("activity after the repair broke the streak")
+			}
+		})
+	}
+}
+
+func TestApplyLapse(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    models.DailyStreak
+		today      string
+		wantNil    bool
+		wantBroken bool
+		wantUsed   int
+	}{
+		{
+			name:    "active yesterday",
+			current: models.DailyStreak{CurrentStreak: 4, LastActivityDate: dayPtr("2026-03-07")},
+			today:   "2026-03-08",
+			wantNil: true,
+		},
+		{
+			name:     "one day missed with a freeze",
+			current:  models.DailyStreak{CurrentStreak: 4, Freezes: 2, LastActivityDate: dayPtr("2026-03-06")},
+			today:    "2026-03-08",
+			wantUsed: 1,
+		},
+		{
+			name:       "one day missed without freezes",
+			current:    models.DailyStreak{CurrentStreak: 4, LastActivityDate: dayPtr("2026-03-06")},
+			today:      "2026-03-08",
+			wantBroken: true,
+		},
+		{
+			name:    "no streak to lapse",
+			current: models.DailyStreak{LastActivityDate: dayPtr("2026-01-01")},
+			today:   "2026-03-08",
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current
+			change := applyLapse(&current, day(tt.today))
+			if (change == nil) != tt.wantNil {
+				t.Fatalf("applyLapse = %+v, want nil %v", change, tt.wantNil)
+			}
+			if change == nil {
+				return
+			}
+			if change.Broken != tt.wantBroken {
+				t.Errorf("broken = %v, want %v", change.Broken, tt.wantBroken)
+			}
+			if change.FreezesUsed != tt.wantUsed {
+				t.Errorf("freezes used = %d, want %d", change.FreezesUsed, tt.wantUsed)
+			}
+			if tt.wantBroken && (current.BrokenOn == nil || !current.BrokenOn.Equal(current.LastActivityDate.AddDate(0, 0, 1))) {
+				t.Errorf("broken on = %v, want the day after %v", current.BrokenOn, current.LastActivityDate)
+			}
+		})
+	}
+}
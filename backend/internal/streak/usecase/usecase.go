@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/streak"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	streakHistoryDays = 30
+	lapsedBatchSize   = 500
+)
+
+type streakUC struct {
+	cfg        *config.Config
+	streakRepo streak.Repository
+	logger     logger.Logger
+}
+
+func NewStreakUseCase(cfg *config.Config, streakRepo streak.Repository, logger logger.Logger) streak.UseCase {
+	return &streakUC{
+		cfg:        cfg,
+		streakRepo: streakRepo,
+		logger:     logger,
+	}
+}
+
+func (u *streakUC) RecordActivity(ctx context.Context, userID uuid.UUID, at time.Time) (*models.StreakChange, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "streakUC.RecordActivity")
+	defer span.Finish()
+
+	loc, err := u.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	goal, err := u.dailyGoal(ctx, userID, loc, at)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := u.streakRepo.UpdateStreak(ctx, userID, func(current *models.DailyStreak, loc *time.Location) (*models.StreakChange, error) {
+		// The XP awarded later on completes the goal, the xp.awarded subscriber counts the day then
+		if !goal.Met {
+			return nil, nil
+		}
+		return applyActivity(u.cfg.Streak, current, localDay(at, loc), at), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	change.DailyGoal = goal
+	return change, nil
+}
+
+func (u *streakUC) RecordXP(ctx context.Context, userID uuid.UUID, at time.Time) (*models.StreakChange, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "streakUC.RecordXP")
+	defer span.Finish()
+
+	// Without a goal only learning activity counts, XP from a won challenge or a quest reward does not
+	goal, err := u.streakRepo.GetDailyXPGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if goal == 0 {
+		return nil, nil
+	}
+
+	return u.RecordActivity(ctx, userID, at)
+}
+
+func (u *streakUC) SetDailyGoal(ctx context.Context, userID uuid.UUID, goal int) (*models.DailyGoal, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "streakUC.SetDailyGoal")
+	defer span.Finish()
+
+	if err := u.streakRepo.UpdateDailyXPGoal(ctx, userID, goal); err != nil {
+		return nil, err
+	}
+
+	loc, err := u.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.dailyGoal(ctx, userID, loc, time.Now())
+}
+
+func (u *streakUC) GetStreak(ctx context.Context, userID uuid.UUID) (*models.StreakStatus, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "streakUC.GetStreak")
+	defer span.Finish()
+
+	timezone, err := u.streakRepo.GetUserTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Wrap(err, "streakUC.GetStreak.LoadLocation")
+	}
+
+	current, err := u.streakRepo.GetStreak(ctx, userID)
+	if err != nil {
+		if errors.Cause(err) != sql.ErrNoRows {
+			return nil, err
+		}
+		current = &models.DailyStreak{UserID: userID}
+	}
+
+	// Settle the missed days in memory so the status is right before the lapse job has run
+	today := localDay(time.Now(), loc)
+	catchUp(current, today, &models.StreakChange{})
+
+	days, err := u.streakRepo.GetStreakDays(ctx, userID, today.AddDate(0, 0, -streakHistoryDays+1))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.StreakStatus{
+		Streak:   current,
+		Timezone: timezone,
+		Today:    today,
+		Days:     days,
+	}
+	if current.CurrentStreak > 0 && current.LastActivityDate != nil {
+		status.ActiveToday = !current.LastActivityDate.Before(today)
+		status.AtRisk = !status.ActiveToday
+	}
+	if deadline := repairDeadline(u.cfg.Streak, current); deadline != nil && !today.After(*deadline) {
+		status.RepairAvailable = true
+		status.RepairDeadline = deadline
+	}
+
+	status.DailyGoal, err = u.dailyGoal(ctx, userID, loc, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+func (u *streakUC) RepairStreak(ctx context.Context, userID uuid.UUID) (*models.StreakChange, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "streakUC.RepairStreak")
+	defer span.Finish()
+
+	return u.streakRepo.UpdateStreak(ctx, userID, func(current *models.DailyStreak, loc *time.Location) (*models.StreakChange, error) {
+		return applyRepair(u.cfg.Streak, current, localDay(time.Now(), loc))
+	})
+}
+
+func (u *streakUC) BreakLapsedStreaks(ctx context.Context) (*models.StreakJobReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "streakUC.BreakLapsedStreaks")
+	defer span.Finish()
+
+	report := &models.StreakJobReport{}
+	for {
+		userIDs, err := u.streakRepo.GetLapsedStreakUserIDs(ctx, lapsedBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		settled := 0
+		for _, userID := range userIDs {
+			report.Checked++
+			change, err := u.streakRepo.UpdateStreak(ctx, userID, func(current *models.DailyStreak, loc *time.Location) (*models.StreakChange, error) {
+				return applyLapse(current, localDay(time.Now(), loc)), nil
+			})
+			if err != nil {
+				u.logger.Errorf("streakUC.BreakLapsedStreaks.UpdateStreak, UserID: %s, Error: %v", userID, err)
+				continue
+			}
+
+			switch {
+			case change.Broken:
+				report.Broken++
+				settled++
+			case change.FreezesUsed > 0:
+				report.Frozen++
+				settled++
+			}
+		}
+
+		// Stop on the last page, or when nothing could be settled to not loop on failing users
+		if len(userIDs) < lapsedBatchSize || settled == 0 {
+			return report, nil
+		}
+	}
+}
+
+func (u *streakUC) userLocation(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	timezone, err := u.streakRepo.GetUserTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Wrap(err, "streakUC.userLocation.LoadLocation")
+	}
+	return loc, nil
+}
+
+// dailyGoal is the user's goal with the XP they earned on the local day of at, a day without a goal is always met
+func (u *streakUC) dailyGoal(ctx context.Context, userID uuid.UUID, loc *time.Location, at time.Time) (*models.DailyGoal, error) {
+	target, err := u.streakRepo.GetDailyXPGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	y, m, d := at.In(loc).Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	earned, err := u.streakRepo.GetXPEarned(ctx, userID, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DailyGoal{
+		DailyXPGoal: target,
+		XPEarned:    earned,
+		Met:         earned >= target,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/streak"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultInterval = time.Hour
+
+// StreakWorker breaks the lapsed streaks, it runs hourly by default so every timezone's midnight is covered
+type StreakWorker struct {
+	streakUC streak.UseCase
+	logger   logger.Logger
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewStreakWorker creates a new lapsed streaks worker, interval is in minutes
+func NewStreakWorker(streakUC streak.UseCase, interval time.Duration, logger logger.Logger) *StreakWorker {
+	if interval <= 0 {
+		interval = defaultInterval
+	} else {
+		interval = interval * time.Minute
+	}
+
+	return &StreakWorker{
+		streakUC: streakUC,
+		logger:   logger,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic lapsed streaks check
+func (w *StreakWorker) Start() {
+	w.logger.Info("Starting streak worker")
+
+	// Run immediately on startup
+	go w.breakLapsedStreaks()
+
+	// Then run periodically
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				go w.breakLapsedStreaks()
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic lapsed streaks check
+func (w *StreakWorker) Stop() {
+	w.logger.Info("Stopping streak worker")
+	close(w.stopCh)
+}
+
+// breakLapsedStreaks triggers the lapsed streaks check
+func (w *StreakWorker) breakLapsedStreaks() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := w.streakUC.BreakLapsedStreaks(ctx)
+	if err != nil {
+		w.logger.Errorf("Error breaking lapsed streaks: %v", err)
+		return
+	}
+
+	w.logger.Infof("Lapsed streaks checked: %d, broken: %d, kept by freezes: %d",
+		report.Checked, report.Broken, report.Frozen)
+}
@@ -0,0 +1,9 @@
+package xp
+
+import "github.com/labstack/echo/v4"
+
+// XP HTTP Handlers interface
+type Handlers interface {
+	GetUserTransactions() echo.HandlerFunc
+	GetUserLevelUps() echo.HandlerFunc
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/xp"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+type xpHandlers struct {
+	xpUC   xp.UseCase
+	logger logger.Logger
+}
+
+func NewXPHandlers(xpUC xp.UseCase, logger logger.Logger) xp.Handlers {
+	return &xpHandlers{
+		xpUC:   xpUC,
+		logger: logger,
+	}
+}
+
+// GetUserTransactions godoc
+// @Summary Get my XP ledger
+// @Description List the XP transactions of the authenticated user, newest first
+// @Tags XP
+// @Produce json
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.XPTransactionList
+// @Router /xp/transactions [get]
+func (h *xpHandlers) GetUserTransactions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "xpHandlers.GetUserTransactions.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "xpHandlers.GetUserTransactions.pageParams"))
+		}
+
+		transactions, err := h.xpUC.GetUserTransactions(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "xpHandlers.GetUserTransactions.GetUserTransactions"))
+		}
+
+		return c.JSON(http.StatusOK, transactions)
+	}
+}
+
+// GetUserLevelUps godoc
+// @Summary Get my level-ups
+// @Description List the level-ups of the authenticated user with their rewards, newest first
+// @Tags XP
+// @Produce json
+// @Param limit query int false "Page size (default: 50)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} models.LevelUpEventList
+// @Router /xp/level-ups [get]
+func (h *xpHandlers) GetUserLevelUps() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := utils.GetUserIDFromContext(c)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(errors.Wrap(err, "xpHandlers.GetUserLevelUps.GetUserIDFromContext"))
+		}
+
+		limit, offset, err := pageParams(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "xpHandlers.GetUserLevelUps.pageParams"))
+		}
+
+		events, err := h.xpUC.GetUserLevelUps(c.Request().Context(), userID, limit, offset)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.Wrap(err, "xpHandlers.GetUserLevelUps.GetUserLevelUps"))
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
+
+func pageParams(c echo.Context) (int, int, error) {
+	var err error
+	limit, offset := 0, 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/xp"
+)
+
+// Map XP routes
+func MapXPRoutes(xpGroup *echo.Group, h xp.Handlers, mw *middleware.MiddlewareManager) {
+	protected := xpGroup.Group("")
+	protected.Use(mw.AuthJWTMiddleware(mw.GetAuthUseCase(), mw.GetConfig()))
+	{
+		protected.GET("/transactions", h.GetUserTransactions())
+		protected.GET("/level-ups", h.GetUserLevelUps())
+	}
+}
@@ -0,0 +1,35 @@
+package xp
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// XP Repository interface
+type Repository interface {
+	// Event context
+	GetQuizSubject(ctx context.Context, quizID uuid.UUID) (string, int, error)
+	GetLessonSubject(ctx context.Context, lessonID uuid.UUID) (string, int, error)
+	// Counts within the transaction that saved the attempt
+	CountQuizAttempts(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, quizID uuid.UUID) (int, error)
+
+	// Applies the ledger entries, users.xp, user_progress and the level up they lead to in a single transaction.
+	// The level up grants its reward freezes, capped by maxFreezes.
+	ApplyEvent(ctx context.Context, event *models.XPEvent, levelUp LevelUpFunc, maxFreezes int) (*models.XPAward, error)
+	// ApplyEvent within the transaction of the change that earned the XP, committed or rolled back with it
+	ApplyEventTx(ctx context.Context, tx *sqlx.Tx, event *models.XPEvent, levelUp LevelUpFunc, maxFreezes int) (*models.XPAward, error)
+
+	GetTransactions(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.XPTransactionList, error)
+
+	// Levels, GetUserXP and GetUserLevel also serve the leaderboard
+	GetUserXP(ctx context.Context, userID uuid.UUID) (int, error)
+	GetUserLevel(ctx context.Context, userID uuid.UUID) (int, error)
+	GetLevelUpEvents(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.LevelUpEventList, error)
+}
+
+// LevelUpFunc returns the level up from the stored level that the total XP reaches, nil when it reaches none
+type LevelUpFunc func(totalXP int, level int) *models.LevelUpEvent
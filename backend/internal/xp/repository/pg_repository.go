@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/events"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/xp"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const defaultTransactionsLimit = 50
+
+type xpRepo struct {
+	db       *sqlx.DB
+	recorder events.Recorder
+	logger   logger.Logger
+}
+
+func NewXPRepository(db *sqlx.DB, recorder events.Recorder, logger logger.Logger) xp.Repository {
+	return &xpRepo{
+		db:       db,
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+func (r *xpRepo) GetQuizSubject(ctx context.Context, quizID uuid.UUID) (string, int, error) {
+	var subject string
+	var grade int
+	if err := r.db.QueryRowxContext(ctx, getQuizSubjectQuery, quizID).Scan(&subject, &grade); err != nil {
+		return "", 0, errors.Wrap(err, "xpRepo.GetQuizSubject.Scan")
+	}
+	return subject, grade, nil
+}
+
+func (r *xpRepo) GetLessonSubject(ctx context.Context, lessonID uuid.UUID) (string, int, error) {
+	var subject string
+	var grade int
+	if err := r.db.QueryRowxContext(ctx, getLessonSubjectQuery, lessonID).Scan(&subject, &grade); err != nil {
+		return "", 0, errors.Wrap(err, "xpRepo.GetLessonSubject.Scan")
+	}
+	return subject, grade, nil
+}
+
+func (r *xpRepo) CountQuizAttempts(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, quizID uuid.UUID) (int, error) {
+	var count int
+	if err := tx.GetContext(ctx, &count, countQuizAttemptsQuery, userID, quizID); err != nil {
+		return 0, errors.Wrap(err, "xpRepo.CountQuizAttempts.GetContext")
+	}
+	return count, nil
+}
+
+func (r *xpRepo) ApplyEvent(ctx context.Context, event *models.XPEvent, levelUp xp.LevelUpFunc, maxFreezes int) (*models.XPAward, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "xpRepo.ApplyEvent.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	award, err := r.ApplyEventTx(ctx, tx, event, levelUp, maxFreezes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.ApplyEvent.Commit")
+	}
+
+	return award, nil
+}
+
+func (r *xpRepo) ApplyEventTx(ctx context.Context, tx *sqlx.Tx, event *models.XPEvent, levelUp xp.LevelUpFunc, maxFreezes int) (*models.XPAward, error) {
+	// Users without a streak row get no freezes for their level ups, they count as holding the most already
+	var freezes int
+	err := tx.GetContext(ctx, &freezes, lockStreakFreezesQuery, event.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "xpRepo.ApplyEventTx.lockStreakFreezes")
+	}
+	if err == sql.ErrNoRows {
+		freezes = maxFreezes
+	}
+
+	var level int
+	award := &models.XPAward{Transactions: make([]*models.XPTransaction, 0, len(event.Transactions))}
+	if err := tx.QueryRowxContext(ctx, lockUserXPQuery, event.UserID).Scan(&award.TotalXP, &level); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.ApplyEventTx.lockUserXP")
+	}
+
+	for _, transaction := range event.Transactions {
+		transaction.UserID = event.UserID
+		transaction.Subject = event.Subject
+		transaction.Grade = event.Grade
+
+		err := tx.QueryRowxContext(
+			ctx,
+			createXPTransactionQuery,
+			transaction.UserID,
+			transaction.Amount,
+			transaction.Reason,
+			transaction.SourceID,
+			transaction.Subject,
+			transaction.Grade,
+		).Scan(&transaction.TransactionID, &transaction.CreatedAt)
+		if err == sql.ErrNoRows {
+			// Already awarded for this source
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "xpRepo.ApplyEventTx.createXPTransaction")
+		}
+
+		award.XPGained += transaction.Amount
+		award.Transactions = append(award.Transactions, transaction)
+	}
+
+	if award.XPGained > 0 {
+		if err := tx.GetContext(ctx, &award.TotalXP, addUserXPQuery, award.XPGained, event.UserID); err != nil {
+			return nil, errors.Wrap(err, "xpRepo.ApplyEventTx.addUserXP")
+		}
+
+		if err := r.recorder.Record(ctx, tx, event.UserID, &events.XPAwarded{
+			XPGained: award.XPGained,
+			TotalXP:  award.TotalXP,
+			Subject:  event.Subject,
+			Grade:    event.Grade,
+		}); err != nil {
+			return nil, errors.Wrap(err, "xpRepo.ApplyEventTx.Record")
+		}
+	}
+
+	// The level up is part of the award, it cannot be lost once the XP is committed
+	if levelUpEvent := levelUp(award.TotalXP, level); levelUpEvent != nil {
+		levelUpEvent.UserID = event.UserID
+		if err := r.createLevelUp(ctx, tx, levelUpEvent, freezes, maxFreezes); err != nil {
+			return nil, err
+		}
+		award.LevelUp = levelUpEvent
+	}
+
+	progress := &models.UserProgress{}
+	if err := tx.QueryRowxContext(ctx, upsertUserProgressQuery, event.UserID, event.Subject, event.Grade).StructScan(progress); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.ApplyEventTx.upsertUserProgress")
+	}
+	award.Progress = progress
+
+	return award, nil
+}
+
+func (r *xpRepo) GetTransactions(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.XPTransactionList, error) {
+	if limit <= 0 {
+		limit = defaultTransactionsLimit
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countXPTransactionsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.GetTransactions.GetContext")
+	}
+
+	transactions := make([]*models.XPTransaction, 0, limit)
+	if err := r.db.SelectContext(ctx, &transactions, getXPTransactionsQuery, userID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.GetTransactions.SelectContext")
+	}
+
+	return &models.XPTransactionList{
+		TotalCount:   totalCount,
+		Transactions: transactions,
+	}, nil
+}
+
+func (r *xpRepo) GetUserXP(ctx context.Context, userID uuid.UUID) (int, error) {
+	var xp int
+	if err := r.db.GetContext(ctx, &xp, getUserXPQuery, userID); err != nil {
+		return 0, errors.Wrap(err, "xpRepo.GetUserXP.GetContext")
+	}
+	return xp, nil
+}
+
+func (r *xpRepo) GetUserLevel(ctx context.Context, userID uuid.UUID) (int, error) {
+	var level int
+	if err := r.db.GetContext(ctx, &level, getUserLevelQuery, userID); err != nil {
+		return 0, errors.Wrap(err, "xpRepo.GetUserLevel.GetContext")
+	}
+	return level, nil
+}
+
+func (r *xpRepo) GetLevelUpEvents(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.LevelUpEventList, error) {
+	if limit <= 0 {
+		limit = defaultTransactionsLimit
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countLevelUpEventsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.GetLevelUpEvents.GetContext")
+	}
+
+	events := make([]*models.LevelUpEvent, 0, limit)
+	if err := r.db.SelectContext(ctx, &events, getLevelUpEventsQuery, userID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "xpRepo.GetLevelUpEvents.SelectContext")
+	}
+
+	return &models.LevelUpEventList{
+		TotalCount: totalCount,
+		Events:     events,
+	}, nil
+}
+
+// createLevelUp moves the user to event.ToLevel within the transaction that locked their streak freezes and user row
+func (r *xpRepo) createLevelUp(ctx context.Context, tx *sqlx.Tx, event *models.LevelUpEvent, freezes int, maxFreezes int) error {
+	if freezes >= maxFreezes {
+		event.RewardFreezes = 0
+	} else if event.RewardFreezes > maxFreezes-freezes {
+		event.RewardFreezes = maxFreezes - freezes
+	}
+
+	if _, err := tx.ExecContext(ctx, updateUserLevelQuery, event.ToLevel, event.UserID); err != nil {
+		return errors.Wrap(err, "xpRepo.createLevelUp.updateUserLevel")
+	}
+
+	if event.RewardFreezes > 0 {
+		if _, err := tx.ExecContext(ctx, addStreakFreezesQuery, event.RewardFreezes, event.UserID); err != nil {
+			return errors.Wrap(err, "xpRepo.createLevelUp.addStreakFreezes")
+		}
+	}
+
+	if err := tx.QueryRowxContext(
+		ctx,
+		createLevelUpEventQuery,
+		event.UserID,
+		event.FromLevel,
+		event.ToLevel,
+		event.XP,
+		event.RewardFreezes,
+	).Scan(&event.EventID, &event.CreatedAt); err != nil {
+		return errors.Wrap(err, "xpRepo.createLevelUp.createLevelUpEvent")
+	}
+
+	if err := r.recorder.Record(ctx, tx, event.UserID, &events.LevelUp{
+		FromLevel:     event.FromLevel,
+		ToLevel:       event.ToLevel,
+		XP:            event.XP,
+		RewardFreezes: event.RewardFreezes,
+	}); err != nil {
+		return errors.Wrap(err, "xpRepo.createLevelUp.Record")
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+const (
+	getQuizSubjectQuery = `
+		SELECT l.subject, l.grade FROM quizzes qz
+		JOIN lessons l ON l.lesson_id = qz.lesson_id
+		WHERE qz.quiz_id = $1
+	`
+
+	getLessonSubjectQuery = `SELECT subject, grade FROM lessons WHERE lesson_id = $1`
+
+	countQuizAttemptsQuery = `SELECT COUNT(*) FROM user_quiz_attempts WHERE user_id = $1 AND quiz_id = $2`
+
+	// Locking the user row serializes concurrent events of the same user
+	lockUserXPQuery = `SELECT xp, level FROM users WHERE user_id = $1 FOR UPDATE`
+
+	createXPTransactionQuery = `
+		INSERT INTO xp_transactions (user_id, amount, reason, source_id, subject, grade)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, reason, source_id) DO NOTHING
+		RETURNING transaction_id, created_at
+	`
+
+	addUserXPQuery = `
+		UPDATE users SET xp = xp + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2
+		RETURNING xp
+	`
+
+	// Progress is recomputed from attempts and lesson progress, so replays and missed events heal themselves.
+	// A chapter counts as read once every lesson in it is completed.
+	upsertUserProgressQuery = `
+		WITH attempts AS (
+			SELECT COUNT(*) AS quizzes_taken, COALESCE(ROUND(AVG(a.score), 2), 0) AS avg_score
+			FROM user_quiz_attempts a
+			JOIN quizzes qz ON qz.quiz_id = a.quiz_id
+			JOIN lessons l ON l.lesson_id = qz.lesson_id
+			WHERE a.user_id = $1 AND l.subject = $2 AND l.grade = $3
+		), chapters_read AS (
+			SELECT COUNT(*) AS chapters_read
+			FROM chapters c
+			WHERE c.subject = $2 AND c.grade = $3
+				AND EXISTS (SELECT 1 FROM lessons l WHERE l.chapter_id = c.chapter_id)
+				AND NOT EXISTS (
+					SELECT 1 FROM lessons l
+					LEFT JOIN lesson_progress lp
+						ON lp.lesson_id = l.lesson_id AND lp.user_id = $1 AND lp.status = 'completed'
+					WHERE l.chapter_id = c.chapter_id AND lp.lesson_progress_id IS NULL
+				)
+		)
+		INSERT INTO user_progress (user_id, subject, grade, chapters_read, quizzes_taken, avg_score)
+		SELECT $1, $2, $3, cr.chapters_read, a.quizzes_taken, a.avg_score
+		FROM attempts a, chapters_read cr
+		ON CONFLICT (user_id, subject, grade) DO UPDATE SET
+			chapters_read = EXCLUDED.chapters_read,
+			quizzes_taken = EXCLUDED.quizzes_taken,
+			avg_score = EXCLUDED.avg_score,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`
+
+	getXPTransactionsQuery = `
+		SELECT transaction_id, user_id, amount, reason, source_id, subject, grade, created_at
+		FROM xp_transactions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, transaction_id
+		LIMIT $2 OFFSET $3
+	`
+
+	countXPTransactionsQuery = `SELECT COUNT(*) FROM xp_transactions WHERE user_id = $1`
+
+	getUserXPQuery = `SELECT xp FROM users WHERE user_id = $1`
+
+	getUserLevelQuery = `SELECT level FROM users WHERE user_id = $1`
+
+	// Streak rows are locked before the user row, in the same order as the streak engine
+	lockStreakFreezesQuery = `SELECT freezes FROM daily_streaks WHERE user_id = $1 FOR UPDATE`
+
+	addStreakFreezesQuery = `
+		UPDATE daily_streaks SET freezes = freezes + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2
+	`
+
+	updateUserLevelQuery = `
+		UPDATE users SET level = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2
+	`
+
+	createLevelUpEventQuery = `
+		INSERT INTO level_up_events (user_id, from_level, to_level, xp, reward_freezes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING event_id, created_at
+	`
+
+	getLevelUpEventsQuery = `
+		SELECT event_id, user_id, from_level, to_level, xp, reward_freezes, created_at
+		FROM level_up_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC, event_id
+		LIMIT $2 OFFSET $3
+	`
+
+	countLevelUpEventsQuery = `SELECT COUNT(*) FROM level_up_events WHERE user_id = $1`
+)
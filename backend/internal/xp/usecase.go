@@ -0,0 +1,25 @@
+package xp
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// XP UseCase interface
+type UseCase interface {
+	// Award XP for a quiz attempt and refresh the subject progress, within the transaction saving the attempt
+	AwardQuizAttempt(ctx context.Context, tx *sqlx.Tx, attempt *models.UserQuizAttempt, correctAnswers int) (*models.XPAward, error)
+	// Award XP for a completed lesson and refresh the subject progress
+	AwardLessonCompletion(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.XPAward, error)
+	// Award the winner's bonus of a quiz challenge, in the subject of the quiz
+	AwardChallengeWin(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID, quizID uuid.UUID, amount int) (*models.XPAward, error)
+	// Award the XP reward of a completed quest, in the subject of the activity that completed it
+	AwardQuestReward(ctx context.Context, userID uuid.UUID, questID uuid.UUID, subject string, grade int, amount int) (*models.XPAward, error)
+
+	GetUserTransactions(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.XPTransactionList, error)
+	GetUserLevelUps(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.LevelUpEventList, error)
+}
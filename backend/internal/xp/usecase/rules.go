@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// quizOutcome is everything the quiz rules may look at
+type quizOutcome struct {
+	Attempt        *models.UserQuizAttempt
+	CorrectAnswers int
+	AttemptNumber  int // 1 for the user's first attempt at the quiz
+}
+
+// quizRule awards XP for one reason. The source decides how often the rule can pay out, every rule uses the
+// quiz ID so retaking a quiz earns nothing the first paid attempt did not.
+type quizRule struct {
+	reason string
+	source func(o *quizOutcome) uuid.UUID
+	amount func(cfg config.XPConfig, o *quizOutcome) int
+}
+
+func perQuiz(o *quizOutcome) uuid.UUID { return o.Attempt.QuizID }
+
+var quizRules = []quizRule{
+	{
+		reason: models.XPReasonCorrectAnswers,
+		source: perQuiz,
+		amount: func(cfg config.XPConfig, o *quizOutcome) int {
+			return cfg.CorrectAnswer * o.CorrectAnswers
+		},
+	},
+	{
+		reason: models.XPReasonQuizCompleted,
+		source: perQuiz,
+		amount: func(cfg config.XPConfig, o *quizOutcome) int {
+			return cfg.QuizCompletion
+		},
+	},
+	{
+		reason: models.XPReasonFirstTry,
+		source: perQuiz,
+		amount: func(cfg config.XPConfig, o *quizOutcome) int {
+			if o.AttemptNumber != 1 || o.Attempt.Score < cfg.FirstTryMinScore {
+				return 0
+			}
+			return cfg.FirstTryBonus
+		},
+	},
+	{
+		reason: models.XPReasonPerfectScore,
+		source: perQuiz,
+		amount: func(cfg config.XPConfig, o *quizOutcome) int {
+			if o.Attempt.Score < 100 {
+				return 0
+			}
+			return cfg.PerfectScore
+		},
+	},
+}
+
+// evaluateQuizRules returns the ledger entries a quiz attempt earns
+func evaluateQuizRules(cfg config.XPConfig, o *quizOutcome) []*models.XPTransaction {
+	transactions := make([]*models.XPTransaction, 0, len(quizRules))
+	for _, rule := range quizRules {
+		amount := rule.amount(cfg, o)
+		if amount <= 0 {
+			continue
+		}
+		transactions = append(transactions, &models.XPTransaction{
+			Amount:   amount,
+			Reason:   rule.reason,
+			SourceID: rule.source(o),
+		})
+	}
+	return transactions
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+func TestEvaluateQuizRules(t *testing.T) {
+	cfg := config.XPConfig{
+		CorrectAnswer:    2,
+		QuizCompletion:   10,
+		FirstTryBonus:    15,
+		FirstTryMinScore: 80,
+		PerfectScore:     25,
+	}
+
+	tests := []struct {
+		name          string
+		score         int
+		correct       int
+		attemptNumber int
+		want          map[string]int
+	}{
+		{
+			name:          "nothing correct still completes",
+			score:         0,
+			correct:       0,
+			attemptNumber: 1,
+			want:          map[string]int{models.XPReasonQuizCompleted: 10},
+		},
+		{
+			name:          "first try just below the minimum score",
+			score:         79,
+			correct:       4,
+			attemptNumber: 1,
+			want: map[string]int{
+				models.XPReasonCorrectAnswers: 8,
+				models.XPReasonQuizCompleted:  10,
+			},
+		},
+		{
+			name:          "first try at the minimum score",
+			score:         80,
+			correct:       4,
+			attemptNumber: 1,
+			want: map[string]int{
+				models.XPReasonCorrectAnswers: 8,
+				models.XPReasonQuizCompleted:  10,
+				models.XPReasonFirstTry:       15,
+			},
+		},
+		{
+			name:          "second try never earns the first try bonus",
+			score:         90,
+			correct:       9,
+			attemptNumber: 2,
+			want: map[string]int{
+				models.XPReasonCorrectAnswers: 18,
+				models.XPReasonQuizCompleted:  10,
+			},
+		},
+		{
+			name:          "just below a perfect score",
+			score:         99,
+			correct:       9,
+			attemptNumber: 3,
+			want: map[string]int{
+				models.XPReasonCorrectAnswers: 18,
+				models.XPReasonQuizCompleted:  10,
+			},
+		},
+		{
+			name:          "perfect first try earns everything",
+			score:         100,
+			correct:       5,
+			attemptNumber: 1,
+			want: map[string]int{
+				models.XPReasonCorrectAnswers: 10,
+				models.XPReasonQuizCompleted:  10,
+				models.XPReasonFirstTry:       15,
+				models.XPReasonPerfectScore:   25,
+			},
+		},
+		{
+			name:          "perfect retake",
+			score:         100,
+			correct:       5,
+			attemptNumber: 4,
+			want: map[string]int{
+				models.XPReasonCorrectAnswers: 10,
+				models.XPReasonQuizCompleted:  10,
+				models.XPReasonPerfectScore:   25,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quizID := uuid.New()
+			outcome := &quizOutcome{
+				Attempt:        &models.UserQuizAttempt{AttemptID: uuid.New(), QuizID: quizID, Score: tt.score},
+				CorrectAnswers: tt.correct,
+				AttemptNumber:  tt.attemptNumber,
+			}
+
+			transactions := evaluateQuizRules(cfg, outcome)
+			if len(transactions) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %d: %v", len(transactions), len(tt.want), reasons(transactions))
+			}
+			for _, tx := range transactions {
+				want, ok := tt.want[tx.Reason]
+				if !ok {
+					t.Errorf("unexpected %s transaction", tx.Reason)
+					continue
+				}
+				if tx.Amount != want {
+					t.Errorf("%s amount = %d, want %d", tx.Reason, tx.Amount, want)
+				}
+				// Every rule pays out once per quiz, so a retake cannot earn a reason twice
+				if tx.SourceID != quizID {
+					t.Errorf("%s source = %s, want the quiz %s", tx.Reason, tx.SourceID, quizID)
+				}
+			}
+		})
+	}
+}
+
+func reasons(transactions []*models.XPTransaction) []string {
+	out := make([]string, 0, len(transactions))
+	for _, tx := range transactions {
+		out = append(out, tx.Reason)
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/xp"
+	"github.com/AleksK1NG/api-mc/pkg/levels"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const maxTransactionsPageSize = 100
+
+type xpUC struct {
+	cfg        *config.Config
+	xpRepo     xp.Repository
+	levelCurve *levels.Curve
+	logger     logger.Logger
+}
+
+func NewXPUseCase(cfg *config.Config, xpRepo xp.Repository, levelCurve *levels.Curve, logger logger.Logger) xp.UseCase {
+	return &xpUC{
+		cfg:        cfg,
+		xpRepo:     xpRepo,
+		levelCurve: levelCurve,
+		logger:     logger,
+	}
+}
+
+func (u *xpUC) AwardQuizAttempt(ctx context.Context, tx *sqlx.Tx, attempt *models.UserQuizAttempt, correctAnswers int) (*models.XPAward, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "xpUC.AwardQuizAttempt")
+	defer span.Finish()
+
+	subject, grade, err := u.xpRepo.GetQuizSubject(ctx, attempt.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The attempt is already saved in the transaction, so it is included in the count
+	attempts, err := u.xpRepo.CountQuizAttempts(ctx, tx, attempt.UserID, attempt.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	award, err := u.xpRepo.ApplyEventTx(ctx, tx, &models.XPEvent{
+		UserID:  attempt.UserID,
+		Subject: subject,
+		Grade:   grade,
+		Transactions: evaluateQuizRules(u.cfg.XP, &quizOutcome{
+			Attempt:        attempt,
+			CorrectAnswers: correctAnswers,
+			AttemptNumber:  attempts,
+		}),
+	}, u.levelUp, u.cfg.Streak.MaxFreezes)
+	if err != nil {
+		return nil, err
+	}
+
+	award.Level = u.levelCurve.Progress(award.TotalXP)
+	return award, nil
+}
+
+func (u *xpUC) AwardLessonCompletion(ctx context.Context, userID uuid.UUID, lessonID uuid.UUID) (*models.XPAward, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "xpUC.AwardLessonCompletion")
+	defer span.Finish()
+
+	subject, grade, err := u.xpRepo.GetLessonSubject(ctx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*models.XPTransaction, 0, 1)
+	if u.cfg.XP.LessonCompletion > 0 {
+		transactions = append(transactions, &models.XPTransaction{
+			Amount:   u.cfg.XP.LessonCompletion,
+			Reason:   models.XPReasonLessonCompleted,
+			SourceID: lessonID,
+		})
+	}
+
+	award, err := u.applyEvent(ctx, &models.XPEvent{
+		UserID:       userID,
+		Subject:      subject,
+		Grade:        grade,
+		Transactions: transactions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return award, nil
+}
+
+func (u *xpUC) AwardChallengeWin(ctx context.Context, userID uuid.UUID, challengeID uuid.UUID, quizID uuid.UUID, amount int) (*models.XPAward, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "xpUC.AwardChallengeWin")
+	defer span.Finish()
+
+	subject, grade, err := u.xpRepo.GetQuizSubject(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*models.XPTransaction, 0, 1)
+	if amount > 0 {
+		transactions = append(transactions, &models.XPTransaction{
+			Amount:   amount,
+			Reason:   models.XPReasonChallengeWon,
+			SourceID: challengeID,
+		})
+	}
+
+	award, err := u.applyEvent(ctx, &models.XPEvent{
+		UserID:       userID,
+		Subject:      subject,
+		Grade:        grade,
+		Transactions: transactions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return award, nil
+}
+
+func (u *xpUC) AwardQuestReward(ctx context.Context, userID uuid.UUID, questID uuid.UUID, subject string, grade int, amount int) (*models.XPAward, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "xpUC.AwardQuestReward")
+	defer span.Finish()
+
+	transactions := make([]*models.XPTransaction, 0, 1)
+	if amount > 0 {
+		transactions = append(transactions, &models.XPTransaction{
+			Amount:   amount,
+			Reason:   models.XPReasonQuestReward,
+			SourceID: questID,
+		})
+	}
+
+	award, err := u.applyEvent(ctx, &models.XPEvent{
+		UserID:       userID,
+		Subject:      subject,
+		Grade:        grade,
+		Transactions: transactions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return award, nil
+}
+
+func (u *xpUC) GetUserTransactions(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.XPTransactionList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "xpUC.GetUserTransactions")
+	defer span.Finish()
+
+	if limit > maxTransactionsPageSize {
+		return nil, errors.Errorf("limit must not exceed %d", maxTransactionsPageSize)
+	}
+	if offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	return u.xpRepo.GetTransactions(ctx, userID, limit, offset)
+}
+
+func (u *xpUC) GetUserLevelUps(ctx context.Context, userID uuid.UUID, limit int, offset int) (*models.LevelUpEventList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "xpUC.GetUserLevelUps")
+	defer span.Finish()
+
+	if limit > maxTransactionsPageSize {
+		return nil, errors.Errorf("limit must not exceed %d", maxTransactionsPageSize)
+	}
+	if offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	return u.xpRepo.GetLevelUpEvents(ctx, userID, limit, offset)
+}
+
+// applyEvent applies the event with the level up it leads to and sets the level progress on the award
+func (u *xpUC) applyEvent(ctx context.Context, event *models.XPEvent) (*models.XPAward, error) {
+	award, err := u.xpRepo.ApplyEvent(ctx, event, u.levelUp, u.cfg.Streak.MaxFreezes)
+	if err != nil {
+		return nil, err
+	}
+
+	award.Level = u.levelCurve.Progress(award.TotalXP)
+	return award, nil
+}
+
+// levelUp moves the stored level to the one reached with the total XP, rewarding the freezes of every level gained
+func (u *xpUC) levelUp(totalXP int, current int) *models.LevelUpEvent {
+	level := u.levelCurve.Level(totalXP)
+	if level <= current {
+		return nil
+	}
+
+	return &models.LevelUpEvent{
+		FromLevel:     current,
+		ToLevel:       level,
+		XP:            totalXP,
+		RewardFreezes: (level - current) * u.cfg.Levels.FreezesPerLevel,
+	}
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS adaptive_quiz_answers CASCADE;
+DROP TABLE IF EXISTS adaptive_quiz_sessions CASCADE;
+
+ALTER TABLE user_progress
+DROP COLUMN ability_answers,
+DROP COLUMN ability;
@@ -0,0 +1,40 @@
+ALTER TABLE user_progress
+ADD COLUMN ability         DECIMAL(6,3) NOT NULL DEFAULT 0.000,
+ADD COLUMN ability_answers INTEGER      NOT NULL DEFAULT 0;
+
+CREATE TABLE adaptive_quiz_sessions
+(
+    session_id          UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id             UUID                    NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    quiz_id             UUID                    NOT NULL REFERENCES quizzes(quiz_id) ON DELETE CASCADE,
+    subject             VARCHAR(50)             NOT NULL CHECK (subject <> ''),
+    grade               INTEGER                 NOT NULL CHECK (grade >= 1 AND grade <= 12),
+    status              VARCHAR(11)             NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+    max_questions       INTEGER                 NOT NULL CHECK (max_questions >= 1),
+    answered            INTEGER                 NOT NULL DEFAULT 0,
+    correct             INTEGER                 NOT NULL DEFAULT 0,
+    points_earned       INTEGER                 NOT NULL DEFAULT 0,
+    points_possible     INTEGER                 NOT NULL DEFAULT 0,
+    ability             DECIMAL(6,3)            NOT NULL DEFAULT 0.000,
+    current_question_id UUID                    REFERENCES questions(question_id) ON DELETE SET NULL,
+    attempt_id          UUID                    REFERENCES user_quiz_attempts(attempt_id) ON DELETE SET NULL,
+    started_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at        TIMESTAMP WITH TIME ZONE,
+    updated_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE adaptive_quiz_answers
+(
+    answer_id      UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    session_id     UUID                    NOT NULL REFERENCES adaptive_quiz_sessions(session_id) ON DELETE CASCADE,
+    question_id    UUID                    NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    user_answer    TEXT                    NOT NULL CHECK (user_answer <> ''),
+    is_correct     BOOLEAN                 NOT NULL,
+    ability_before DECIMAL(6,3)            NOT NULL,
+    ability_after  DECIMAL(6,3)            NOT NULL,
+    answered_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (session_id, question_id)
+);
+
+CREATE INDEX idx_adaptive_quiz_sessions_user_id ON adaptive_quiz_sessions(user_id);
+CREATE INDEX idx_adaptive_quiz_answers_session_id ON adaptive_quiz_answers(session_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_question_responses_question_id;
+DROP TABLE IF EXISTS question_stats CASCADE;
+
+ALTER TABLE user_question_responses
+DROP COLUMN IF EXISTS time_spent;
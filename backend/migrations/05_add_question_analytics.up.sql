@@ -0,0 +1,23 @@
+ALTER TABLE user_question_responses
+ADD COLUMN time_spent INTEGER CHECK (time_spent >= 0); -- in seconds, NULL when the client did not report it
+
+CREATE TABLE question_stats
+(
+    question_id              UUID PRIMARY KEY        REFERENCES questions(question_id) ON DELETE CASCADE,
+    quiz_id                  UUID                    NOT NULL REFERENCES quizzes(quiz_id) ON DELETE CASCADE,
+    responses                INTEGER                 NOT NULL DEFAULT 0,
+    correct                  INTEGER                 NOT NULL DEFAULT 0,
+    p_value                  DECIMAL(5,4)            NOT NULL DEFAULT 0,
+    discrimination           DECIMAL(5,4),
+    most_common_wrong_answer TEXT,
+    wrong_answer_count       INTEGER                 NOT NULL DEFAULT 0,
+    avg_time_spent           DECIMAL(10,2),
+    flags                    TEXT[]                  NOT NULL DEFAULT '{}',
+    difficulty               VARCHAR(6)              NOT NULL CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    suggested_difficulty     VARCHAR(6)              CHECK (suggested_difficulty IN ('easy', 'medium', 'hard')),
+    computed_at              TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_question_stats_quiz_id ON question_stats(quiz_id);
+CREATE INDEX idx_question_stats_flags ON question_stats USING GIN (flags);
+CREATE INDEX idx_user_question_responses_question_id ON user_question_responses(question_id);
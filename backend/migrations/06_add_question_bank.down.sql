@@ -0,0 +1,35 @@
+DROP INDEX IF EXISTS idx_questions_tags;
+DROP INDEX IF EXISTS idx_questions_subject_grade;
+
+ALTER TABLE questions
+ADD COLUMN quiz_id UUID REFERENCES quizzes(quiz_id) ON DELETE CASCADE;
+
+-- A question shared by several quizzes goes back to the first one that used it
+UPDATE questions q
+SET quiz_id = first_link.quiz_id
+FROM (
+    SELECT DISTINCT ON (question_id) question_id, quiz_id
+    FROM quiz_questions
+    ORDER BY question_id, created_at
+) first_link
+WHERE first_link.question_id = q.question_id;
+
+-- Bank items that are in no quiz cannot be represented without the join table
+DELETE FROM questions WHERE quiz_id IS NULL;
+
+ALTER TABLE questions ALTER COLUMN quiz_id SET NOT NULL;
+CREATE INDEX idx_questions_quiz_id ON questions(quiz_id);
+
+ALTER TABLE question_stats ADD COLUMN quiz_id UUID REFERENCES quizzes(quiz_id) ON DELETE CASCADE;
+UPDATE question_stats s SET quiz_id = q.quiz_id FROM questions q WHERE q.question_id = s.question_id;
+ALTER TABLE question_stats ALTER COLUMN quiz_id SET NOT NULL;
+CREATE INDEX idx_question_stats_quiz_id ON question_stats(quiz_id);
+
+DROP TABLE IF EXISTS quiz_questions CASCADE;
+
+ALTER TABLE questions
+DROP CONSTRAINT IF EXISTS questions_subject_check,
+DROP COLUMN created_by,
+DROP COLUMN tags,
+DROP COLUMN grade,
+DROP COLUMN subject;
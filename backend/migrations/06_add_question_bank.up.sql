@@ -0,0 +1,38 @@
+ALTER TABLE questions
+ADD COLUMN subject    VARCHAR(50),
+ADD COLUMN grade      INTEGER CHECK (grade >= 1 AND grade <= 12),
+ADD COLUMN tags       TEXT[]                  NOT NULL DEFAULT '{}',
+ADD COLUMN created_by UUID                    REFERENCES users(user_id) ON DELETE SET NULL;
+
+UPDATE questions q
+SET subject = l.subject, grade = l.grade
+FROM quizzes qz
+JOIN lessons l ON l.lesson_id = qz.lesson_id
+WHERE qz.quiz_id = q.quiz_id;
+
+ALTER TABLE questions
+ALTER COLUMN subject SET NOT NULL,
+ALTER COLUMN grade SET NOT NULL,
+ADD CONSTRAINT questions_subject_check CHECK (subject <> '');
+
+CREATE TABLE quiz_questions
+(
+    quiz_id     UUID                    NOT NULL REFERENCES quizzes(quiz_id) ON DELETE CASCADE,
+    question_id UUID                    NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    position    INTEGER                 NOT NULL CHECK (position >= 1),
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (quiz_id, question_id)
+);
+
+INSERT INTO quiz_questions (quiz_id, question_id, position, created_at)
+SELECT quiz_id, question_id, ROW_NUMBER() OVER (PARTITION BY quiz_id ORDER BY created_at, question_id), created_at
+FROM questions;
+
+-- Questions outlive their quizzes now, membership lives in quiz_questions
+ALTER TABLE question_stats DROP COLUMN quiz_id;
+DROP INDEX IF EXISTS idx_questions_quiz_id;
+ALTER TABLE questions DROP COLUMN quiz_id;
+
+CREATE INDEX idx_quiz_questions_question_id ON quiz_questions(question_id);
+CREATE INDEX idx_questions_subject_grade ON questions(subject, grade, difficulty);
+CREATE INDEX idx_questions_tags ON questions USING GIN (tags);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_quiz_attempts_user_id_quiz_id;
+DROP INDEX IF EXISTS idx_xp_transactions_user_id_created_at;
+DROP TABLE IF EXISTS xp_transactions CASCADE;
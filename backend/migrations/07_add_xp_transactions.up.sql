@@ -0,0 +1,15 @@
+CREATE TABLE xp_transactions
+(
+    transaction_id UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id        UUID                    NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    amount         INTEGER                 NOT NULL CHECK (amount > 0),
+    reason         VARCHAR(30)             NOT NULL CHECK (reason IN ('correct_answers', 'quiz_completed', 'first_try', 'perfect_score', 'lesson_completed')),
+    source_id      UUID                    NOT NULL, -- attempt, quiz or lesson the XP was earned on
+    subject        VARCHAR(50)             NOT NULL CHECK (subject <> ''),
+    grade          INTEGER                 NOT NULL CHECK (grade >= 1 AND grade <= 12),
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, reason, source_id) -- replaying an event never awards twice
+);
+
+CREATE INDEX idx_xp_transactions_user_id_created_at ON xp_transactions(user_id, created_at DESC);
+CREATE INDEX idx_user_quiz_attempts_user_id_quiz_id ON user_quiz_attempts(user_id, quiz_id);
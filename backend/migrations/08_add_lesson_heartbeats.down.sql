@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_lesson_progress_lesson_id;
+
+ALTER TABLE lesson_progress
+DROP COLUMN IF EXISTS last_heartbeat_at,
+DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,7 @@
+ALTER TABLE lesson_progress
+ADD COLUMN started_at        TIMESTAMP WITH TIME ZONE,
+ADD COLUMN last_heartbeat_at TIMESTAMP WITH TIME ZONE; -- reading time is only credited up to the time since the last heartbeat
+
+UPDATE lesson_progress SET started_at = created_at WHERE status <> 'not_started';
+
+CREATE INDEX idx_lesson_progress_lesson_id ON lesson_progress(lesson_id);
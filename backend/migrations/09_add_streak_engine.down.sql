@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_daily_streaks_last_activity_date;
+DROP TABLE IF EXISTS streak_days CASCADE;
+
+ALTER TABLE daily_streaks
+DROP COLUMN IF EXISTS broken_on,
+DROP COLUMN IF EXISTS broken_streak,
+DROP COLUMN IF EXISTS freezes,
+DROP COLUMN IF EXISTS last_activity_date;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,23 @@
+ALTER TABLE users
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC'; -- IANA name, streak days follow the user's calendar
+
+ALTER TABLE daily_streaks
+ADD COLUMN last_activity_date DATE,                    -- last local day covered by activity or a freeze
+ADD COLUMN freezes            INTEGER NOT NULL DEFAULT 0 CHECK (freezes >= 0),
+ADD COLUMN broken_streak      INTEGER NOT NULL DEFAULT 0, -- length of the last broken streak, restorable within the repair window
+ADD COLUMN broken_on          DATE;                    -- first local day that was missed
+
+UPDATE daily_streaks SET last_activity_date = last_activity::date WHERE current_streak > 0;
+
+CREATE TABLE streak_days
+(
+    user_id       UUID                    NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    activity_date DATE                    NOT NULL,
+    activities    INTEGER                 NOT NULL DEFAULT 0,
+    frozen        BOOLEAN                 NOT NULL DEFAULT false, -- covered by a streak freeze instead of activity
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, activity_date)
+);
+
+CREATE INDEX idx_daily_streaks_last_activity_date ON daily_streaks(last_activity_date) WHERE current_streak > 0;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_level_up_events_user_id_created_at;
+DROP TABLE IF EXISTS level_up_events CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS level;
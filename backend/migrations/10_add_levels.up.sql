@@ -0,0 +1,16 @@
+ALTER TABLE users
+ADD COLUMN level INTEGER NOT NULL DEFAULT 1 CHECK (level >= 1); -- derived from xp by the configured level curve, catches up on the next XP award
+
+CREATE TABLE level_up_events
+(
+    event_id       UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id        UUID                    NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    from_level     INTEGER                 NOT NULL,
+    to_level       INTEGER                 NOT NULL,
+    xp             INTEGER                 NOT NULL, -- total XP when the level was reached
+    reward_freezes INTEGER                 NOT NULL DEFAULT 0 CHECK (reward_freezes >= 0),
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (to_level > from_level)
+);
+
+CREATE INDEX idx_level_up_events_user_id_created_at ON level_up_events(user_id, created_at DESC);
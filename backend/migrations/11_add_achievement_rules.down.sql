@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_achievements_enabled;
+
+ALTER TABLE achievements
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS is_enabled,
+DROP COLUMN IF EXISTS rule;
+
+DELETE FROM achievements WHERE type NOT IN ('streak', 'quiz_score', 'subject_mastery', 'custom') OR required_value = 0;
+
+ALTER TABLE achievements DROP CONSTRAINT IF EXISTS achievements_required_value_check;
+ALTER TABLE achievements ALTER COLUMN required_value DROP DEFAULT;
+ALTER TABLE achievements ADD CONSTRAINT achievements_required_value_check CHECK (required_value > 0);
+
+ALTER TABLE achievements DROP CONSTRAINT IF EXISTS achievements_type_check;
+ALTER TABLE achievements ALTER COLUMN type TYPE VARCHAR(15);
+ALTER TABLE achievements ADD CONSTRAINT achievements_type_check CHECK (type IN ('streak', 'quiz_score', 'subject_mastery', 'custom'));
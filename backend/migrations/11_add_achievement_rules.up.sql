@@ -0,0 +1,32 @@
+-- type becomes a free-form category, awarding is driven by the rule
+ALTER TABLE achievements DROP CONSTRAINT IF EXISTS achievements_type_check;
+ALTER TABLE achievements ALTER COLUMN type TYPE VARCHAR(30);
+ALTER TABLE achievements ADD CONSTRAINT achievements_type_check CHECK (type <> '');
+
+ALTER TABLE achievements DROP CONSTRAINT IF EXISTS achievements_required_value_check;
+ALTER TABLE achievements ALTER COLUMN required_value SET DEFAULT 0;
+ALTER TABLE achievements ADD CONSTRAINT achievements_required_value_check CHECK (required_value >= 0);
+
+ALTER TABLE achievements
+ADD COLUMN rule       JSONB,                                  -- NULL means the achievement is only awarded manually
+ADD COLUMN is_enabled BOOLEAN                 NOT NULL DEFAULT true,
+ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- The former hardcoded types become single condition rules
+UPDATE achievements
+SET rule = jsonb_build_object(
+    'metric', CASE type
+        WHEN 'streak' THEN 'current_streak'
+        WHEN 'quiz_score' THEN 'best_quiz_score'
+        WHEN 'subject_mastery' THEN 'top_subject_chapters_read'
+        WHEN 'lessons_completed' THEN 'lessons_completed'
+        WHEN 'quizzes_taken' THEN 'quiz_attempts'
+        WHEN 'xp_earned' THEN 'xp'
+    END,
+    'op', '>=',
+    'value', required_value
+)
+WHERE type IN ('streak', 'quiz_score', 'subject_mastery', 'lessons_completed', 'quizzes_taken', 'xp_earned');
+
+-- Only enabled achievements with a rule are evaluated automatically
+CREATE INDEX idx_achievements_enabled ON achievements(created_at) WHERE is_enabled AND rule IS NOT NULL;
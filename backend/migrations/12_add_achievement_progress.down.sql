@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_achievements_achievement_id;
+DROP TABLE IF EXISTS achievement_progress;
@@ -0,0 +1,16 @@
+-- Cached progress toward locked achievements, refreshed whenever the user's achievements are evaluated
+CREATE TABLE achievement_progress
+(
+    user_id        UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    achievement_id UUID                     NOT NULL REFERENCES achievements(achievement_id) ON DELETE CASCADE,
+    current_value  DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    required_value DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    percentage     INTEGER                  NOT NULL DEFAULT 0 CHECK (percentage BETWEEN 0 AND 100),
+    updated_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+CREATE INDEX idx_achievement_progress_achievement_id ON achievement_progress(achievement_id);
+
+-- Rarity counts earners per achievement
+CREATE INDEX IF NOT EXISTS idx_user_achievements_achievement_id ON user_achievements(achievement_id);
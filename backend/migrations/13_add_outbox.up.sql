@@ -0,0 +1,26 @@
+-- Domain events recorded in the transaction of the change they describe, delivered by the outbox worker
+CREATE TABLE outbox_events
+(
+    event_id        UUID PRIMARY KEY,
+    event_type      VARCHAR(50)              NOT NULL,
+    user_id         UUID                     NOT NULL, -- no foreign key, events outlive deleted users
+    payload         JSONB                    NOT NULL,
+    status          VARCHAR(20)              NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP, -- also leases claimed events
+    last_error      TEXT,
+    occurred_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at    TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_due ON outbox_events(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_outbox_events_status_occurred_at ON outbox_events(status, occurred_at DESC);
+
+-- Idempotency keys, a subscriber that handled an event is skipped when the event is retried or replayed
+CREATE TABLE outbox_deliveries
+(
+    event_id     UUID                     NOT NULL REFERENCES outbox_events(event_id) ON DELETE CASCADE,
+    subscriber   VARCHAR(50)              NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, subscriber)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_xp_transactions_user_id_subject;
+
+DROP TABLE IF EXISTS leaderboard_entries;
+DROP TABLE IF EXISTS class_members;
+DROP TABLE IF EXISTS classes;
@@ -0,0 +1,43 @@
+-- Classes group students for the class leaderboards
+CREATE TABLE classes
+(
+    class_id   UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    name       VARCHAR(100)             NOT NULL CHECK (name <> ''),
+    grade      INTEGER                  NOT NULL CHECK (grade >= 1 AND grade <= 12),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE class_members
+(
+    class_id  UUID                     NOT NULL REFERENCES classes(class_id) ON DELETE CASCADE,
+    user_id   UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (class_id, user_id)
+);
+
+CREATE INDEX idx_class_members_user_id ON class_members(user_id);
+
+-- Entries are derived from the XP ledger and rebuilt by the leaderboard worker,
+-- a table created by hand from the old commented-out schema is replaced
+DROP TABLE IF EXISTS leaderboard_entries;
+
+-- One entry per user and board: global, per subject, per grade and per class
+CREATE TABLE leaderboard_entries
+(
+    entry_id   UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id    UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    scope      VARCHAR(10)              NOT NULL CHECK (scope IN ('global', 'subject', 'grade', 'class')),
+    scope_key  VARCHAR(64)              NOT NULL DEFAULT '', -- subject, grade or class id, empty for the global board
+    xp         INTEGER                  NOT NULL DEFAULT 0,  -- XP gained within the scope
+    level      INTEGER                  NOT NULL DEFAULT 1,
+    streak     INTEGER                  NOT NULL DEFAULT 0,
+    rank       INTEGER                  NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, scope, scope_key)
+);
+
+CREATE INDEX idx_leaderboard_entries_scope_rank ON leaderboard_entries(scope, scope_key, rank);
+
+-- Subject boards sum the ledger per subject
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_user_id_subject ON xp_transactions(user_id, subject);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_xp_transactions_created_at;
+
+DROP TABLE IF EXISTS leaderboard_snapshots;
+DROP TABLE IF EXISTS leaderboard_periods;
+DROP TABLE IF EXISTS leaderboard_period_entries;
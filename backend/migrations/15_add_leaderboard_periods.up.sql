@@ -0,0 +1,46 @@
+-- XP gained per board within the open daily, weekly and monthly periods, rebuilt from the XP ledger
+CREATE TABLE leaderboard_period_entries
+(
+    period       VARCHAR(10)              NOT NULL CHECK (period IN ('daily', 'weekly', 'monthly')),
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL, -- boundaries in the configured leaderboard timezone
+    period_end   TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id      UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    scope        VARCHAR(10)              NOT NULL CHECK (scope IN ('global', 'subject', 'grade', 'class')),
+    scope_key    VARCHAR(64)              NOT NULL DEFAULT '',
+    xp           INTEGER                  NOT NULL CHECK (xp > 0),
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (period, period_start, scope, scope_key, user_id)
+);
+
+CREATE INDEX idx_leaderboard_period_entries_user_id ON leaderboard_period_entries(user_id, period, period_start);
+
+-- Closed periods, a period is archived once
+CREATE TABLE leaderboard_periods
+(
+    period       VARCHAR(10)              NOT NULL CHECK (period IN ('daily', 'weekly', 'monthly')),
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end   TIMESTAMP WITH TIME ZONE NOT NULL,
+    closed_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (period, period_start)
+);
+
+-- Final standings of every board of a closed period
+CREATE TABLE leaderboard_snapshots
+(
+    period       VARCHAR(10)              NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id      UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    scope        VARCHAR(10)              NOT NULL,
+    scope_key    VARCHAR(64)              NOT NULL DEFAULT '',
+    xp           INTEGER                  NOT NULL,
+    rank         INTEGER                  NOT NULL,
+    board_size   INTEGER                  NOT NULL, -- entries on the board when the period closed
+    PRIMARY KEY (period, period_start, scope, scope_key, user_id),
+    FOREIGN KEY (period, period_start) REFERENCES leaderboard_periods(period, period_start) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_leaderboard_snapshots_board_rank ON leaderboard_snapshots(period, period_start, scope, scope_key, rank);
+CREATE INDEX idx_leaderboard_snapshots_user_id ON leaderboard_snapshots(user_id, period, scope, scope_key, period_start DESC);
+
+-- Period boards sum the ledger within the period
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_created_at ON xp_transactions(created_at);
@@ -0,0 +1,34 @@
+-- League tier of every user who joined a league, an index into League.Tiers, 0 is the lowest
+CREATE TABLE user_leagues
+(
+    user_id    UUID PRIMARY KEY         NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    tier       SMALLINT                 NOT NULL DEFAULT 0 CHECK (tier >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Weekly cohorts of up to League.CohortSize users of the same tier, weeks are the weekly leaderboard periods
+CREATE TABLE league_cohorts
+(
+    cohort_id  UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    tier       SMALLINT                 NOT NULL CHECK (tier >= 0),
+    week_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    week_end   TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at  TIMESTAMP WITH TIME ZONE -- set once promotions and relegations are applied
+);
+
+CREATE INDEX idx_league_cohorts_week_start_tier ON league_cohorts(week_start, tier);
+CREATE INDEX idx_league_cohorts_open ON league_cohorts(week_end) WHERE closed_at IS NULL;
+
+CREATE TABLE league_members
+(
+    cohort_id  UUID                     NOT NULL REFERENCES league_cohorts(cohort_id) ON DELETE CASCADE,
+    user_id    UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    week_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    joined_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    xp         INTEGER, -- final weekly XP, rank and outcome are set when the cohort closes
+    final_rank INTEGER,
+    outcome    VARCHAR(10) CHECK (outcome IN ('promoted', 'stayed', 'relegated')),
+    PRIMARY KEY (cohort_id, user_id),
+    UNIQUE (user_id, week_start) -- one league per user and week
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS activities;
+DROP TABLE IF EXISTS privacy_settings;
+DROP TABLE IF EXISTS blocks;
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,48 @@
+-- Follow graph, a follow of a private account stays pending until the followee accepts it
+CREATE TABLE follows
+(
+    follower_id UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    followee_id UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    status      VARCHAR(10)              NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted')),
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX idx_follows_followee_status ON follows(followee_id, status);
+
+-- A block removes the follows in both directions and rejects new ones
+CREATE TABLE blocks
+(
+    blocker_id UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    blocked_id UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX idx_blocks_blocked_id ON blocks(blocked_id);
+
+-- Users without a row have the defaults
+CREATE TABLE privacy_settings
+(
+    user_id              UUID PRIMARY KEY         NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    private_account      BOOLEAN                  NOT NULL DEFAULT true, -- follows need to be accepted
+    share_activity       BOOLEAN                  NOT NULL DEFAULT true, -- followers see the user's activities in their feed
+    show_on_leaderboards BOOLEAN                  NOT NULL DEFAULT true, -- followers see the user on their friends leaderboard
+    updated_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Activities shown in the followers' feed, written by the social subscriber from the domain events
+CREATE TABLE activities
+(
+    activity_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    event_id    UUID UNIQUE              NOT NULL, -- the event the activity was written from
+    user_id     UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    type        VARCHAR(20)              NOT NULL CHECK (type IN ('achievement_earned', 'chapter_completed')),
+    subject_id  UUID                     NOT NULL, -- the achievement or chapter
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_activities_user_id_occurred_at ON activities(user_id, occurred_at DESC);
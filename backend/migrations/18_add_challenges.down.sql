@@ -0,0 +1,10 @@
+DELETE FROM xp_transactions WHERE reason = 'challenge_won';
+
+ALTER TABLE xp_transactions DROP CONSTRAINT IF EXISTS xp_transactions_reason_check;
+
+ALTER TABLE xp_transactions ADD CONSTRAINT xp_transactions_reason_check
+    CHECK (reason IN ('correct_answers', 'quiz_completed', 'first_try', 'perfect_score', 'lesson_completed'));
+
+DROP TABLE IF EXISTS challenge_results;
+DROP TABLE IF EXISTS challenge_questions;
+DROP TABLE IF EXISTS challenges;
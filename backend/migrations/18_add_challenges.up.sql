@@ -0,0 +1,48 @@
+-- Head-to-head quiz challenges, both players answer the same questions drawn from the quiz.
+-- expires_at is the deadline to accept while pending and to submit once active
+CREATE TABLE challenges
+(
+    challenge_id  UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    challenger_id UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    opponent_id   UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    quiz_id       UUID                     NOT NULL REFERENCES quizzes(quiz_id) ON DELETE CASCADE,
+    status        VARCHAR(10)              NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'declined', 'expired', 'completed')),
+    bonus_xp      INTEGER                  NOT NULL DEFAULT 0 CHECK (bonus_xp >= 0),
+    winner_id     UUID REFERENCES users(user_id) ON DELETE SET NULL, -- NULL for a draw
+    expires_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at   TIMESTAMP WITH TIME ZONE,
+    completed_at  TIMESTAMP WITH TIME ZONE, -- set once declined, expired or completed
+    CHECK (challenger_id <> opponent_id)
+);
+
+CREATE INDEX idx_challenges_challenger_id ON challenges(challenger_id, created_at DESC);
+CREATE INDEX idx_challenges_opponent_id ON challenges(opponent_id, created_at DESC);
+CREATE INDEX idx_challenges_open ON challenges(expires_at) WHERE status IN ('pending', 'active');
+
+-- The drawn questions in the order they are served
+CREATE TABLE challenge_questions
+(
+    challenge_id UUID    NOT NULL REFERENCES challenges(challenge_id) ON DELETE CASCADE,
+    position     INTEGER NOT NULL,
+    question_id  UUID    NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    PRIMARY KEY (challenge_id, position)
+);
+
+-- Each player's run, timed from the moment the questions were served to the submission
+CREATE TABLE challenge_results
+(
+    challenge_id UUID                     NOT NULL REFERENCES challenges(challenge_id) ON DELETE CASCADE,
+    user_id      UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    started_at   TIMESTAMP WITH TIME ZONE,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    attempt_id   UUID REFERENCES user_quiz_attempts(attempt_id) ON DELETE SET NULL,
+    score        INTEGER,
+    time_spent   INTEGER, -- in seconds
+    PRIMARY KEY (challenge_id, user_id)
+);
+
+ALTER TABLE xp_transactions DROP CONSTRAINT IF EXISTS xp_transactions_reason_check;
+
+ALTER TABLE xp_transactions ADD CONSTRAINT xp_transactions_reason_check
+    CHECK (reason IN ('correct_answers', 'quiz_completed', 'first_try', 'perfect_score', 'lesson_completed', 'challenge_won'));
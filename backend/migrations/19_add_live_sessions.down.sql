@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS live_session_results;
+DROP TABLE IF EXISTS live_sessions;
@@ -0,0 +1,30 @@
+-- Finished live quiz sessions, the state of a game in progress lives in Redis
+CREATE TABLE live_sessions
+(
+    session_id     UUID PRIMARY KEY         NOT NULL,
+    quiz_id        UUID                     NOT NULL REFERENCES quizzes(quiz_id) ON DELETE CASCADE,
+    host_id        UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    pin            VARCHAR(10)              NOT NULL,
+    question_count INTEGER                  NOT NULL, -- questions played
+    player_count   INTEGER                  NOT NULL,
+    started_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at    TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_live_sessions_host_id ON live_sessions(host_id, finished_at DESC);
+
+-- Each player's final result, graded as a quiz attempt of the questions played
+CREATE TABLE live_session_results
+(
+    session_id      UUID        NOT NULL REFERENCES live_sessions(session_id) ON DELETE CASCADE,
+    user_id         UUID        NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    nickname        VARCHAR(30) NOT NULL,
+    rank            INTEGER     NOT NULL,
+    points          INTEGER     NOT NULL,
+    correct_answers INTEGER     NOT NULL,
+    answered        INTEGER     NOT NULL,
+    attempt_id      UUID REFERENCES user_quiz_attempts(attempt_id) ON DELETE SET NULL, -- NULL when nothing was answered
+    PRIMARY KEY (session_id, user_id)
+);
+
+CREATE INDEX idx_live_session_results_user_id ON live_session_results(user_id);
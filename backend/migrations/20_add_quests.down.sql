@@ -0,0 +1,13 @@
+DELETE FROM xp_transactions WHERE reason = 'quest_reward';
+
+ALTER TABLE xp_transactions DROP CONSTRAINT IF EXISTS xp_transactions_reason_check;
+
+ALTER TABLE xp_transactions ADD CONSTRAINT xp_transactions_reason_check
+    CHECK (reason IN ('correct_answers', 'quiz_completed', 'first_try', 'perfect_score', 'lesson_completed', 'challenge_won'));
+
+ALTER TABLE users DROP COLUMN IF EXISTS daily_xp_goal;
+
+DROP TABLE IF EXISTS user_quest_events;
+DROP TABLE IF EXISTS user_quests;
+DROP TABLE IF EXISTS user_quest_periods;
+DROP TABLE IF EXISTS quest_templates;
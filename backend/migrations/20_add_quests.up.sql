@@ -0,0 +1,80 @@
+-- Quests are generated per user and period from the active templates.
+-- A template counts lesson completions or quiz submissions, subject and min_score narrow down which ones
+CREATE TABLE quest_templates
+(
+    template_id   UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    title         VARCHAR(100)             NOT NULL CHECK (title <> ''),
+    period        VARCHAR(10)              NOT NULL CHECK (period IN ('daily', 'weekly')),
+    metric        VARCHAR(20)              NOT NULL CHECK (metric IN ('lessons_completed', 'quizzes_completed')),
+    target        INTEGER                  NOT NULL CHECK (target > 0),
+    subject       VARCHAR(50), -- NULL for any subject
+    min_score     INTEGER CHECK (min_score BETWEEN 0 AND 100), -- quizzes only
+    reward_type   VARCHAR(10)              NOT NULL CHECK (reward_type IN ('xp', 'freeze')),
+    reward_amount INTEGER                  NOT NULL CHECK (reward_amount > 0),
+    is_active     BOOLEAN                  NOT NULL DEFAULT true,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (min_score IS NULL OR metric = 'quizzes_completed')
+);
+
+INSERT INTO quest_templates (title, period, metric, target, subject, min_score, reward_type, reward_amount)
+VALUES ('Complete 2 lessons', 'daily', 'lessons_completed', 2, NULL, NULL, 'xp', 20),
+       ('Finish a quiz', 'daily', 'quizzes_completed', 1, NULL, NULL, 'xp', 15),
+       ('Score 80% or more on a quiz', 'daily', 'quizzes_completed', 1, NULL, 80, 'xp', 25),
+       ('Score 80% or more on a math quiz', 'daily', 'quizzes_completed', 1, 'math', 80, 'xp', 30),
+       ('Finish 3 quizzes', 'daily', 'quizzes_completed', 3, NULL, NULL, 'xp', 40),
+       ('Complete 10 lessons', 'weekly', 'lessons_completed', 10, NULL, NULL, 'freeze', 1),
+       ('Finish 10 quizzes', 'weekly', 'quizzes_completed', 10, NULL, NULL, 'xp', 100),
+       ('Score 100% on 3 quizzes', 'weekly', 'quizzes_completed', 3, NULL, 100, 'freeze', 1),
+       ('Complete 5 math lessons', 'weekly', 'lessons_completed', 5, 'math', NULL, 'xp', 80);
+
+-- A user's quests of a period are generated once, by whoever inserts the period first
+CREATE TABLE user_quest_periods
+(
+    user_id      UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    period       VARCHAR(10)              NOT NULL,
+    period_start DATE                     NOT NULL, -- local day the period starts on, Monday for weeks
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, period, period_start)
+);
+
+-- starts_at and expires_at bound the period in the user's timezone when the quest was generated
+CREATE TABLE user_quests
+(
+    quest_id       UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id        UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    template_id    UUID                     NOT NULL REFERENCES quest_templates(template_id) ON DELETE CASCADE,
+    period_start   DATE                     NOT NULL,
+    starts_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    progress       INTEGER                  NOT NULL DEFAULT 0,
+    target         INTEGER                  NOT NULL CHECK (target > 0),
+    status         VARCHAR(10)              NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'expired')),
+    reward_subject VARCHAR(50), -- subject and grade of the activity that completed the quest, XP rewards are earned in it
+    reward_grade   INTEGER,
+    completed_at   TIMESTAMP WITH TIME ZONE,
+    rewarded_at    TIMESTAMP WITH TIME ZONE,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, template_id, period_start)
+);
+
+CREATE INDEX idx_user_quests_user_id ON user_quests(user_id, expires_at DESC);
+CREATE INDEX idx_user_quests_active ON user_quests(expires_at) WHERE status = 'active';
+CREATE INDEX idx_user_quests_unrewarded ON user_quests(completed_at) WHERE status = 'completed' AND rewarded_at IS NULL;
+
+-- The events a quest counted, so a redelivered event never counts twice
+CREATE TABLE user_quest_events
+(
+    quest_id   UUID                     NOT NULL REFERENCES user_quests(quest_id) ON DELETE CASCADE,
+    event_id   UUID                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (quest_id, event_id)
+);
+
+ALTER TABLE users
+ADD COLUMN daily_xp_goal INTEGER NOT NULL DEFAULT 0 CHECK (daily_xp_goal >= 0); -- 0 means any qualifying activity keeps the streak
+
+ALTER TABLE xp_transactions DROP CONSTRAINT IF EXISTS xp_transactions_reason_check;
+
+ALTER TABLE xp_transactions ADD CONSTRAINT xp_transactions_reason_check
+    CHECK (reason IN ('correct_answers', 'quiz_completed', 'first_try', 'perfect_score', 'lesson_completed', 'challenge_won', 'quest_reward'));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,59 @@
+-- In-app inbox, every notification is also pushed to the user's open streams
+CREATE TABLE notifications
+(
+    notification_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id         UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    type            VARCHAR(30)              NOT NULL CHECK (type <> ''),
+    title           VARCHAR(200)             NOT NULL,
+    body            TEXT                     NOT NULL,
+    data            JSONB                    NOT NULL DEFAULT '{}',
+    event_id        UUID UNIQUE, -- domain event the notification was created for, so a redelivered event notifies once
+    read_at         TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Users without preferences get the defaults
+CREATE TABLE notification_preferences
+(
+    user_id             UUID PRIMARY KEY         NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    email_enabled       BOOLEAN                  NOT NULL DEFAULT true,
+    push_enabled        BOOLEAN                  NOT NULL DEFAULT true,
+    muted_types         TEXT[]                   NOT NULL DEFAULT '{}', -- types never sent by email or push
+    quiet_hours_enabled BOOLEAN                  NOT NULL DEFAULT false,
+    quiet_hours_start   SMALLINT                 NOT NULL DEFAULT 21 CHECK (quiet_hours_start BETWEEN 0 AND 23), -- local hour
+    quiet_hours_end     SMALLINT                 NOT NULL DEFAULT 7 CHECK (quiet_hours_end BETWEEN 0 AND 23),
+    updated_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Web push subscriptions of the user's browsers
+CREATE TABLE push_subscriptions
+(
+    subscription_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id         UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    endpoint        TEXT                     NOT NULL UNIQUE,
+    p256dh          VARCHAR(100)             NOT NULL,
+    auth            VARCHAR(50)              NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_push_subscriptions_user_id ON push_subscriptions(user_id);
+
+-- A notification sent by email or push, send_after is the end of the quiet hours it was created in
+CREATE TABLE notification_deliveries
+(
+    delivery_id     UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    notification_id UUID                     NOT NULL REFERENCES notifications(notification_id) ON DELETE CASCADE,
+    channel         VARCHAR(20)              NOT NULL,
+    status          VARCHAR(10)              NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed', 'skipped')),
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    send_after      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at         TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (notification_id, channel)
+);
+
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries(send_after) WHERE status = 'pending';
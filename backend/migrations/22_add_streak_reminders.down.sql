@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS streak_reminders;
+
+ALTER TABLE notification_preferences
+DROP COLUMN IF EXISTS streak_reminder_hour,
+DROP COLUMN IF EXISTS streak_reminders_enabled;
@@ -0,0 +1,15 @@
+ALTER TABLE notification_preferences
+ADD COLUMN streak_reminders_enabled BOOLEAN  NOT NULL DEFAULT true,
+ADD COLUMN streak_reminder_hour     SMALLINT NOT NULL DEFAULT 19 CHECK (streak_reminder_hour BETWEEN 0 AND 23); -- local hour
+
+-- One reminder per user and local day, also counts the reminders of the weekly limit
+CREATE TABLE streak_reminders
+(
+    user_id         UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reminder_date   DATE                     NOT NULL, -- local day the streak was at risk
+    notification_id UUID REFERENCES notifications(notification_id) ON DELETE SET NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, reminder_date)
+);
+
+CREATE INDEX idx_streak_reminders_created_at ON streak_reminders(user_id, created_at);
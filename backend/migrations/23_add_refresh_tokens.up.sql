@@ -0,0 +1,17 @@
+-- Rotating refresh tokens, a family is the chain of tokens of one login and every rotation stays in it
+CREATE TABLE refresh_tokens
+(
+    token_id          UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    family_id         UUID                     NOT NULL, -- the login session, the sid claim of its access tokens
+    user_id           UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    token_hash        VARCHAR(64)              NOT NULL UNIQUE, -- hex SHA-256, the token itself is never stored
+    access_token_id   UUID                     NOT NULL, -- jti of the access token issued with it
+    access_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at        TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at           TIMESTAMP WITH TIME ZONE, -- rotated, presenting it again is a reuse
+    revoked_at        TIMESTAMP WITH TIME ZONE,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id) WHERE revoked_at IS NULL;
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id) WHERE revoked_at IS NULL;
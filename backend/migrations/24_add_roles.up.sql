@@ -0,0 +1,54 @@
+CREATE TABLE roles
+(
+    role_name   VARCHAR(32) PRIMARY KEY NOT NULL,
+    description TEXT                    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE permissions
+(
+    permission_name VARCHAR(64) PRIMARY KEY NOT NULL,
+    description     TEXT                    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions
+(
+    role_name       VARCHAR(32) NOT NULL REFERENCES roles(role_name) ON DELETE CASCADE,
+    permission_name VARCHAR(64) NOT NULL REFERENCES permissions(permission_name) ON DELETE CASCADE,
+    PRIMARY KEY (role_name, permission_name)
+);
+
+CREATE TABLE user_roles
+(
+    user_id    UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role_name  VARCHAR(32)              NOT NULL REFERENCES roles(role_name) ON DELETE CASCADE,
+    granted_by UUID REFERENCES users(user_id) ON DELETE SET NULL, -- NULL when granted by the platform
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_name)
+);
+
+INSERT INTO roles (role_name, description)
+VALUES ('admin', 'Manages the platform, its content and the roles of the users'),
+       ('teacher', 'Manages chapters and classes and reads the question analytics'),
+       ('student', 'Learns, every user is a student when they register'),
+       ('parent', 'Follows the learning of their children');
+
+INSERT INTO permissions (permission_name, description)
+VALUES ('roles:manage', 'Grant and revoke the roles of the users'),
+       ('achievements:manage', 'Create, update, delete and award achievements'),
+       ('chapters:manage', 'Delete any chapter'),
+       ('classes:manage', 'Create classes and manage their members'),
+       ('analytics:read', 'Read the question analytics'),
+       ('quests:manage', 'Create and update the quest templates'),
+       ('events:manage', 'Read and replay the outbox events'),
+       ('jobs:run', 'Run the background jobs on demand');
+
+INSERT INTO role_permissions (role_name, permission_name)
+SELECT 'admin', permission_name FROM permissions;
+
+INSERT INTO role_permissions (role_name, permission_name)
+VALUES ('teacher', 'chapters:manage'),
+       ('teacher', 'classes:manage'),
+       ('teacher', 'analytics:read');
+
+INSERT INTO user_roles (user_id, role_name)
+SELECT user_id, 'student' FROM users;
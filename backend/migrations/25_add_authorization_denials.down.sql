@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE permission_name IN ('users:read', 'users:manage', 'audit:read');
+
+UPDATE permissions SET description = 'Delete any chapter' WHERE permission_name = 'chapters:manage';
+
+DROP TABLE IF EXISTS authorization_denials;
@@ -0,0 +1,27 @@
+-- Audit trail of the refused requests, by the use cases and the permission middleware
+CREATE TABLE authorization_denials
+(
+    denial_id     UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id       UUID REFERENCES users(user_id) ON DELETE SET NULL, -- NULL for anonymous requests
+    action        VARCHAR(32)              NOT NULL,
+    resource_type VARCHAR(32)              NOT NULL,
+    resource_id   VARCHAR(250)             NOT NULL DEFAULT '',
+    permission    VARCHAR(64)              NOT NULL DEFAULT '', -- permission that would have granted the access
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_authorization_denials_created_at ON authorization_denials(created_at DESC);
+CREATE INDEX idx_authorization_denials_user_id ON authorization_denials(user_id, created_at DESC);
+
+INSERT INTO permissions (permission_name, description)
+VALUES ('users:read', 'Read the profile, progress and streak of any user'),
+       ('users:manage', 'Update the profile of any user'),
+       ('audit:read', 'Read the audited authorization failures');
+
+UPDATE permissions SET description = 'Update and delete any chapter and add lessons to it' WHERE permission_name = 'chapters:manage';
+
+INSERT INTO role_permissions (role_name, permission_name)
+VALUES ('admin', 'users:read'),
+       ('admin', 'users:manage'),
+       ('admin', 'audit:read'),
+       ('teacher', 'users:read');
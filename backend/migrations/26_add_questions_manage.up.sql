@@ -0,0 +1,6 @@
+INSERT INTO permissions (permission_name, description)
+VALUES ('questions:manage', 'Update and delete any question of the bank and read its answer');
+
+INSERT INTO role_permissions (role_name, permission_name)
+VALUES ('admin', 'questions:manage'),
+       ('teacher', 'questions:manage');
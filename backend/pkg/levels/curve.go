@@ -0,0 +1,80 @@
+package levels
+
+import (
+	"fmt"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Curve maps total XP to levels. Everyone starts at level 1 with 0 XP,
+// thresholds[i] is the total XP needed to reach level i+2 and past the
+// last threshold every level costs the last step again.
+type Curve struct {
+	thresholds []int
+	step       int
+}
+
+// NewCurve validates the thresholds, they must be positive and strictly increasing
+func NewCurve(thresholds []int) (*Curve, error) {
+	if len(thresholds) == 0 {
+		return nil, fmt.Errorf("level curve needs at least one threshold")
+	}
+
+	prev := 0
+	for i, threshold := range thresholds {
+		if threshold <= prev {
+			return nil, fmt.Errorf("level %d threshold %d must be greater than %d", i+2, threshold, prev)
+		}
+		prev = threshold
+	}
+
+	step := thresholds[0]
+	if n := len(thresholds); n > 1 {
+		step = thresholds[n-1] - thresholds[n-2]
+	}
+
+	return &Curve{thresholds: thresholds, step: step}, nil
+}
+
+// MinXP is the total XP a level starts at
+func (c *Curve) MinXP(level int) int {
+	if level <= 1 {
+		return 0
+	}
+	if i := level - 2; i < len(c.thresholds) {
+		return c.thresholds[i]
+	}
+	last := len(c.thresholds) + 1
+	return c.thresholds[len(c.thresholds)-1] + (level-last)*c.step
+}
+
+// Level is the level reached with xp
+func (c *Curve) Level(xp int) int {
+	for i, threshold := range c.thresholds {
+		if xp < threshold {
+			return i + 1
+		}
+	}
+	last := len(c.thresholds) + 1
+	return last + (xp-c.thresholds[len(c.thresholds)-1])/c.step
+}
+
+// Progress is where xp stands within its level
+func (c *Curve) Progress(xp int) *models.LevelProgress {
+	if xp < 0 {
+		xp = 0
+	}
+
+	level := c.Level(xp)
+	levelXP := c.MinXP(level)
+	nextLevelXP := c.MinXP(level + 1)
+
+	return &models.LevelProgress{
+		Level:         level,
+		XP:            xp,
+		LevelXP:       levelXP,
+		NextLevelXP:   nextLevelXP,
+		XPIntoLevel:   xp - levelXP,
+		XPToNextLevel: nextLevelXP - xp,
+	}
+}
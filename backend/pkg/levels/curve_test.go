@@ -0,0 +1,126 @@
+package levels
+
+import (
+	"testing"
+)
+
+func TestNewCurve(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds []int
+		wantErr    bool
+	}{
+		{name: "increasing", thresholds: []int{100, 250, 450}},
+		{name: "single threshold", thresholds: []int{100}},
+		{name: "empty", thresholds: nil, wantErr: true},
+		{name: "zero threshold", thresholds: []int{0, 100}, wantErr: true},
+		{name: "negative threshold", thresholds: []int{-10}, wantErr: true},
+		{name: "equal thresholds", thresholds: []int{100, 100}, wantErr: true},
+		{name: "decreasing", thresholds: []int{100, 250, 200}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCurve(tt.thresholds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCurve(%v) error = %v, want error %v", tt.thresholds, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCurveLevel(t *testing.T) {
+	// Past the last threshold every level costs the last step, 200 XP
+	curve, err := NewCurve([]int{100, 250, 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+	single, err := NewCurve([]int{100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		curve *Curve
+		xp    int
+		want  int
+	}{
+		{name: "no xp", curve: curve, xp: 0, want: 1},
+		{name: "one below the first threshold", curve: curve, xp: 99, want: 1},
+		{name: "at the first threshold", curve: curve, xp: 100, want: 2},
+		{name: "one below the second threshold", curve: curve, xp: 249, want: 2},
+		{name: "at the second threshold", curve: curve, xp: 250, want: 3},
+		{name: "at the last threshold", curve: curve, xp: 450, want: 4},
+		{name: "one below the first extra step", curve: curve, xp: 649, want: 4},
+		{name: "at the first extra step", curve: curve, xp: 650, want: 5},
+		{name: "far past the thresholds", curve: curve, xp: 450 + 10*200, want: 14},
+		{name: "single threshold repeats its step", curve: single, xp: 299, want: 3},
+		{name: "single threshold at a step", curve: single, xp: 300, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.curve.Level(tt.xp); got != tt.want {
+				t.Errorf("Level(%d) = %d, want %d", tt.xp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurveMinXPMatchesLevel(t *testing.T) {
+	curve, err := NewCurve([]int{100, 250, 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for level := 1; level <= 20; level++ {
+		minXP := curve.MinXP(level)
+		if got := curve.Level(minXP); got != level {
+			t.Errorf("Level(MinXP(%d) = %d) = %d", level, minXP, got)
+		}
+		if level > 1 {
+			if got := curve.Level(minXP - 1); got != level-1 {
+				t.Errorf("Level(MinXP(%d) - 1 = %d) = %d, want %d", level, minXP-1, got, level-1)
+			}
+		}
+	}
+
+	if got := curve.MinXP(0); got != 0 {
+		t.Errorf("MinXP(0) = %d, want 0", got)
+	}
+}
+
+func TestCurveProgress(t *testing.T) {
+	curve, err := NewCurve([]int{100, 250, 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		xp            int
+		level         int
+		levelXP       int
+		nextLevelXP   int
+		xpIntoLevel   int
+		xpToNextLevel int
+	}{
+		{name: "negative xp counts as none", xp: -5, level: 1, levelXP: 0, nextLevelXP: 100, xpIntoLevel: 0, xpToNextLevel: 100},
+		{name: "start", xp: 0, level: 1, levelXP: 0, nextLevelXP: 100, xpIntoLevel: 0, xpToNextLevel: 100},
+		{name: "one before a level up", xp: 249, level: 2, levelXP: 100, nextLevelXP: 250, xpIntoLevel: 149, xpToNextLevel: 1},
+		{name: "exactly at a level", xp: 250, level: 3, levelXP: 250, nextLevelXP: 450, xpIntoLevel: 0, xpToNextLevel: 200},
+		{name: "past the last threshold", xp: 700, level: 5, levelXP: 650, nextLevelXP: 850, xpIntoLevel: 50, xpToNextLevel: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := curve.Progress(tt.xp)
+			if got.Level != tt.level || got.LevelXP != tt.levelXP || got.NextLevelXP != tt.nextLevelXP ||
+				got.XPIntoLevel != tt.xpIntoLevel || got.XPToNextLevel != tt.xpToNextLevel {
+				t.Errorf("Progress(%d) = %+v, want level %d from %d to %d, %d into it and %d to go",
+					tt.xp, got, tt.level, tt.levelXP, tt.nextLevelXP, tt.xpIntoLevel, tt.xpToNextLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package quizformat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Options are kept in a single cell separated by this character
+const csvOptionSeparator = "|"
+
+var csvHeader = []string{"text", "question_type", "options", "answer", "explanation", "points", "difficulty"}
+
+func decodeCSV(data []byte) (*Document, []*models.QuizImportIssue, error) {
+	doc := &Document{}
+	var issues []*models.QuizImportIssue
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"text", "answer"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header is missing the %q column, expected %s", required, strings.Join(csvHeader, ","))
+		}
+	}
+
+	item := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		item++
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		question := &models.Question{
+			Text:         cell("text"),
+			QuestionType: strings.ToLower(cell("question_type")),
+			Answer:       cell("answer"),
+			Explanation:  cell("explanation"),
+			Difficulty:   strings.ToLower(cell("difficulty")),
+		}
+
+		if options := cell("options"); options != "" {
+			for _, option := range strings.Split(options, csvOptionSeparator) {
+				if option = strings.TrimSpace(option); option != "" {
+					question.Options = append(question.Options, option)
+				}
+			}
+		}
+
+		if question.QuestionType == "" {
+			question.QuestionType = inferQuestionType(question)
+		}
+
+		if points := cell("points"); points != "" {
+			n, err := strconv.Atoi(points)
+			if err != nil {
+				issues = append(issues, &models.QuizImportIssue{
+					Item:     item,
+					Position: line,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("invalid points %q, using the default", points),
+				})
+			}
+			question.Points = n
+		}
+
+		doc.Questions = append(doc.Questions, question)
+		doc.Positions = append(doc.Positions, line)
+	}
+
+	return doc, issues, nil
+}
+
+// inferQuestionType guesses the type when the column is missing or empty
+func inferQuestionType(question *models.Question) string {
+	if _, ok := normalizeBool(question.Answer); ok && (len(question.Options) == 0 || isTrueFalseChoices(question.Options)) {
+		return "true_false"
+	}
+	if len(question.Options) > 0 {
+		return "multiple_choice"
+	}
+	return "open_ended"
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeCSV(_ *models.Quiz, questions []*models.Question) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, q := range questions {
+		for _, option := range q.Options {
+			if strings.Contains(option, csvOptionSeparator) {
+				return nil, fmt.Errorf("question %s: option %q contains the %q separator", q.QuestionID, option, csvOptionSeparator)
+			}
+		}
+
+		if err := writer.Write([]string{
+			q.Text,
+			q.QuestionType,
+			strings.Join(q.Options, csvOptionSeparator),
+			q.Answer,
+			q.Explanation,
+			strconv.Itoa(q.Points),
+			q.Difficulty,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
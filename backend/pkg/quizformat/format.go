@@ -0,0 +1,205 @@
+package quizformat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Format is a quiz exchange format
+type Format string
+
+const (
+	GIFT Format = "gift"
+	QTI  Format = "qti"
+	CSV  Format = "csv"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	trueAnswer  = "True"
+	falseAnswer = "False"
+
+	defaultDifficulty = "medium"
+)
+
+// Points by difficulty, the same scale the quiz generator uses
+var pointsByDifficulty = map[string]int{
+	"easy":   5,
+	"medium": 10,
+	"hard":   15,
+}
+
+// Document is a quiz decoded from or encoded to an exchange format
+type Document struct {
+	Title       string
+	Description string
+	TimeLimit   *int
+	Questions   []*models.Question
+	// Source position of each question, a line number or item index depending on the format
+	Positions []int
+}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(name))); f {
+	case GIFT, QTI, CSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported quiz format %q, expected one of gift, qti, csv", name)
+	}
+}
+
+// ContentType returns the MIME type used when serving an export
+func (f Format) ContentType() string {
+	switch f {
+	case QTI:
+		return "application/zip"
+	case CSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension used when serving an export
+func (f Format) Extension() string {
+	switch f {
+	case QTI:
+		return "zip"
+	case CSV:
+		return "csv"
+	default:
+		return "gift.txt"
+	}
+}
+
+// Decode parses a quiz in the given format. Problems with individual questions are
+// reported as issues, an error is only returned when the input cannot be read at all.
+func Decode(format Format, data []byte) (*Document, []*models.QuizImportIssue, error) {
+	switch format {
+	case GIFT:
+		return decodeGIFT(data)
+	case QTI:
+		return decodeQTI(data)
+	case CSV:
+		return decodeCSV(data)
+	default:
+		return nil, nil, fmt.Errorf("unsupported quiz format %q", format)
+	}
+}
+
+// Encode renders a quiz in the given format
+func Encode(format Format, quiz *models.Quiz, questions []*models.Question) ([]byte, error) {
+	switch format {
+	case GIFT:
+		return encodeGIFT(quiz, questions)
+	case QTI:
+		return encodeQTI(quiz, questions)
+	case CSV:
+		return encodeCSV(quiz, questions)
+	default:
+		return nil, fmt.Errorf("unsupported quiz format %q", format)
+	}
+}
+
+// Validate checks a decoded question against the rules the questions table enforces and fills
+// in defaults. It returns the issues found, the question is importable when none is an error.
+func Validate(question *models.Question, item int, position int) []*models.QuizImportIssue {
+	var issues []*models.QuizImportIssue
+	report := func(severity, format string, args ...interface{}) {
+		issues = append(issues, &models.QuizImportIssue{
+			Item:     item,
+			Position: position,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	question.Text = strings.TrimSpace(question.Text)
+	question.Answer = strings.TrimSpace(question.Answer)
+	question.Explanation = strings.TrimSpace(question.Explanation)
+
+	if question.Text == "" {
+		report(SeverityError, "question text is empty")
+	}
+
+	switch question.QuestionType {
+	case "multiple_choice":
+		if len(question.Options) < 2 {
+			report(SeverityError, "multiple choice question needs at least 2 options, got %d", len(question.Options))
+		}
+		if question.Answer != "" && !containsString(question.Options, question.Answer) {
+			report(SeverityError, "answer %q is not one of the options", question.Answer)
+		}
+	case "true_false":
+		answer, ok := normalizeBool(question.Answer)
+		if !ok {
+			report(SeverityError, "true/false answer must be true or false, got %q", question.Answer)
+		}
+		question.Answer = answer
+		question.Options = []string{trueAnswer, falseAnswer}
+	case "open_ended":
+		question.Options = nil
+	default:
+		report(SeverityError, "unsupported question type %q", question.QuestionType)
+	}
+
+	if question.Answer == "" {
+		report(SeverityError, "question has no correct answer")
+	}
+
+	if question.Difficulty == "" {
+		question.Difficulty = defaultDifficulty
+	} else if _, ok := pointsByDifficulty[question.Difficulty]; !ok {
+		report(SeverityWarning, "unknown difficulty %q, using %s", question.Difficulty, defaultDifficulty)
+		question.Difficulty = defaultDifficulty
+	}
+
+	if question.Points < 1 {
+		if question.Points < 0 {
+			report(SeverityWarning, "negative points, using the default for %s questions", question.Difficulty)
+		}
+		question.Points = pointsByDifficulty[question.Difficulty]
+	}
+
+	if question.Explanation == "" && question.Answer != "" {
+		report(SeverityWarning, "no explanation given, a default one was generated")
+		question.Explanation = fmt.Sprintf("The correct answer is: %s", question.Answer)
+	}
+
+	return issues
+}
+
+// HasErrors reports whether any issue is an error
+func HasErrors(issues []*models.QuizImportIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeBool(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "t", "true":
+		return trueAnswer, true
+	case "f", "false":
+		return falseAnswer, true
+	default:
+		return value, false
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,423 @@
+package quizformat
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+func sampleQuestions() []*models.Question {
+	return []*models.Question{
+		{
+			Text:         "What is 2 + 2?",
+			QuestionType: "multiple_choice",
+			Options:      []string{"3", "4", "5"},
+			Answer:       "4",
+			Explanation:  "Two and two make four.",
+			Points:       5,
+			Difficulty:   "easy",
+		},
+		{
+			Text:         "The Earth orbits the Sun.",
+			QuestionType: "true_false",
+			Options:      []string{trueAnswer, falseAnswer},
+			Answer:       trueAnswer,
+			Explanation:  "It takes a year.",
+			Points:       10,
+			Difficulty:   "medium",
+		},
+		{
+			Text:         "Name the largest planet: {braces} = ~ # and a colon",
+			QuestionType: "open_ended",
+			Answer:       "Jupiter",
+			Explanation:  "It is a gas giant.",
+			Points:       15,
+			Difficulty:   "hard",
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	quiz := &models.Quiz{Title: "Space", Description: "Planets and orbits"}
+
+	for _, format := range []Format{GIFT, QTI, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			questions := sampleQuestions()
+			data, err := Encode(format, quiz, questions)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			doc, issues, err := Decode(format, data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if HasErrors(issues) {
+				t.Fatalf("Decode reported errors: %v", issueMessages(issues))
+			}
+			if len(doc.Questions) != len(questions) {
+				t.Fatalf("decoded %d questions, want %d", len(doc.Questions), len(questions))
+			}
+
+			for i, got := range doc.Questions {
+				if problems := Validate(got, i+1, doc.Positions[i]); HasErrors(problems) {
+					t.Fatalf("question %d does not validate: %v", i+1, issueMessages(problems))
+				}
+
+				want := questions[i]
+				if got.Text != want.Text {
+					t.Errorf("question %d text = %q, want %q", i+1, got.Text, want.Text)
+				}
+				if got.QuestionType != want.QuestionType {
+					t.Errorf("question %d type = %q, want %q", i+1, got.QuestionType, want.QuestionType)
+				}
+				if got.Answer != want.Answer {
+					t.Errorf("question %d answer = %q, want %q", i+1, got.Answer, want.Answer)
+				}
+				if strings.Join(got.Options, "|") != strings.Join(want.Options, "|") {
+					t.Errorf("question %d options = %q, want %q", i+1, got.Options, want.Options)
+				}
+				if got.Difficulty != want.Difficulty {
+					t.Errorf("question %d difficulty = %q, want %q", i+1, got.Difficulty, want.Difficulty)
+				}
+				if got.Points != want.Points {
+					t.Errorf("question %d points = %d, want %d", i+1, got.Points, want.Points)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeGIFT(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		questions int
+		errors    []string // substrings of the expected error issues, in order
+	}{
+		{
+			name:      "multiple choice",
+			input:     "::Q1:: What is 2 + 2? {~3 =4 ~5}\n",
+			questions: 1,
+		},
+		{
+			name:      "true false",
+			input:     "The sky is green. {F}\n",
+			questions: 1,
+		},
+		{
+			name:      "questions split on blank lines",
+			input:     "First? {=yes ~no}\n\nSecond? {T}\n",
+			questions: 2,
+		},
+		{
+			name:      "answer block not closed",
+			input:     "What is 2 + 2? {~3 =4\n",
+			questions: 1,
+			errors:    []string{"answer block is not closed"},
+		},
+		{
+			name:      "no answer block",
+			input:     "Just some text without answers\n",
+			questions: 1,
+			errors:    []string{"question has no answer block"},
+		},
+		{
+			name:      "no correct answer",
+			input:     "Pick one {~a ~b}\n",
+			questions: 1,
+			errors:    []string{"no answer is marked correct"},
+		},
+		{
+			name:      "essay",
+			input:     "Write about the sea {}\n",
+			questions: 1,
+			errors:    []string{"essay questions are not supported"},
+		},
+		{
+			name:      "matching",
+			input:     "Match {=cat -> meow =dog -> woof}\n",
+			questions: 1,
+			errors:    []string{"matching questions are not supported"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, issues, err := Decode(GIFT, []byte(tt.input))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if len(doc.Questions) != tt.questions {
+				t.Fatalf("decoded %d questions, want %d", len(doc.Questions), tt.questions)
+			}
+			assertErrors(t, issues, tt.errors)
+		})
+	}
+}
+
+func TestDecodeQTI(t *testing.T) {
+	item := func(body string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="item-1" title="Q">` + body + `</assessmentItem>`
+	}
+	choice := `<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+<correctResponse><value>B</value></correctResponse></responseDeclaration>
+<itemBody><choiceInteraction responseIdentifier="RESPONSE" maxChoices="1"><prompt>What is 2 + 2?</prompt>
+<simpleChoice identifier="A">3</simpleChoice><simpleChoice identifier="B">4</simpleChoice></choiceInteraction></itemBody>`
+
+	tests := []struct {
+		name      string
+		input     []byte
+		questions int
+		wantErr   bool
+		errors    []string
+	}{
+		{
+			name:      "single item",
+			input:     []byte(item(choice)),
+			questions: 1,
+		},
+		{
+			name:    "not XML",
+			input:   []byte("this is not a QTI document"),
+			wantErr: true,
+		},
+		{
+			name:    "truncated XML",
+			input:   []byte(item(choice)[:120]),
+			wantErr: true,
+		},
+		{
+			name:    "corrupt zip",
+			input:   []byte("PK\x03\x04 definitely not a zip archive"),
+			wantErr: true,
+		},
+		{
+			name:      "package with items",
+			input:     zipOf(t, map[string]string{"imsmanifest.xml": "<manifest/>", "item-1.xml": item(choice)}),
+			questions: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, issues, err := Decode(QTI, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Decode succeeded with %d questions, want an error", len(doc.Questions))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if len(doc.Questions) != tt.questions {
+				t.Fatalf("decoded %d questions, want %d", len(doc.Questions), tt.questions)
+			}
+			assertErrors(t, issues, tt.errors)
+		})
+	}
+}
+
+func TestQTIPackageSizeLimit(t *testing.T) {
+	// Highly compressible, the archive is small while the content is over the limit
+	large := "<assessmentItem>" + strings.Repeat(" ", maxQTIPackageSize) + "</assessmentItem>"
+	data := zipOf(t, map[string]string{"item-1.xml": large})
+
+	if _, _, err := Decode(QTI, data); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Fatalf("Decode error = %v, want the package size limit", err)
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		questions int
+		wantErr   bool
+		errors    []string
+	}{
+		{
+			name:      "valid rows",
+			input:     "text,question_type,options,answer,explanation,points,difficulty\nWhat is 2 + 2?,multiple_choice,3|4|5,4,,5,easy\n",
+			questions: 1,
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:      "unsupported type",
+			input:     "text,question_type,options,answer\nWhat?,matching,,x\n",
+			questions: 1,
+			errors:    []string{"unsupported question type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, issues, err := Decode(CSV, []byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Decode succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if len(doc.Questions) != tt.questions {
+				t.Fatalf("decoded %d questions, want %d", len(doc.Questions), tt.questions)
+			}
+			for i, question := range doc.Questions {
+				issues = append(issues, Validate(question, i+1, doc.Positions[i])...)
+			}
+			assertErrors(t, issues, tt.errors)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		question   models.Question
+		errors     []string
+		answer     string
+		points     int
+		difficulty string
+	}{
+		{
+			name:       "defaults filled in",
+			question:   models.Question{Text: "Q", QuestionType: "open_ended", Answer: "A"},
+			answer:     "A",
+			points:     10,
+			difficulty: "medium",
+		},
+		{
+			name:       "true false answer normalized",
+			question:   models.Question{Text: "Q", QuestionType: "true_false", Answer: "t", Difficulty: "hard"},
+			answer:     trueAnswer,
+			points:     15,
+			difficulty: "hard",
+		},
+		{
+			name:     "answer not an option",
+			question: models.Question{Text: "Q", QuestionType: "multiple_choice", Options: []string{"a", "b"}, Answer: "c"},
+			errors:   []string{"is not one of the options"},
+		},
+		{
+			name:     "too few options",
+			question: models.Question{Text: "Q", QuestionType: "multiple_choice", Options: []string{"a"}, Answer: "a"},
+			errors:   []string{"at least 2 options"},
+		},
+		{
+			name:     "empty text and answer",
+			question: models.Question{QuestionType: "open_ended"},
+			errors:   []string{"question text is empty", "question has no correct answer"},
+		},
+		{
+			name:       "unknown difficulty falls back",
+			question:   models.Question{Text: "Q", QuestionType: "open_ended", Answer: "A", Difficulty: "brutal", Points: -3},
+			answer:     "A",
+			points:     10,
+			difficulty: "medium",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := tt.question
+			issues := Validate(&question, 1, 1)
+			assertErrors(t, issues, tt.errors)
+			if len(tt.errors) > 0 {
+				return
+			}
+			if question.Answer != tt.answer {
+				t.Errorf("answer = %q, want %q", question.Answer, tt.answer)
+			}
+			if question.Points != tt.points {
+				t.Errorf("points = %d, want %d", question.Points, tt.points)
+			}
+			if question.Difficulty != tt.difficulty {
+				t.Errorf("difficulty = %q, want %q", question.Difficulty, tt.difficulty)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "gift", want: GIFT},
+		{name: " QTI ", want: QTI},
+		{name: "Csv", want: CSV},
+		{name: "xlsx", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseFormat(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// assertErrors checks the error issues, in order, against substrings of their messages
+func assertErrors(t *testing.T, issues []*models.QuizImportIssue, want []string) {
+	t.Helper()
+
+	var got []string
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			got = append(got, issue.Message)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("error issues = %q, want %d matching %q", got, len(want), want)
+	}
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("error issue %d = %q, want it to contain %q", i+1, got[i], want[i])
+		}
+	}
+}
+
+func issueMessages(issues []*models.QuizImportIssue) []string {
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.Severity+": "+issue.Message)
+	}
+	return messages
+}
+
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("zip.Create: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("zip.Write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("zip.Close: %v", err)
+	}
+	return buf.Bytes()
+}
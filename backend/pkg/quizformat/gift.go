@@ -0,0 +1,458 @@
+package quizformat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// GIFT has no fields for difficulty or points, they travel in a comment above the question
+var giftMetaRegexp = regexp.MustCompile(`(?i)(difficulty|points)\s*:\s*([a-z0-9]+)`)
+
+var giftFormatPrefixRegexp = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+
+// giftQuestion is the raw text of one question and where it started
+type giftQuestion struct {
+	text string
+	line int
+	meta map[string]string
+}
+
+// giftAnswer is one =/~ entry of an answer block
+type giftAnswer struct {
+	text     string
+	correct  bool
+	weight   *float64
+	feedback string
+}
+
+func decodeGIFT(data []byte) (*Document, []*models.QuizImportIssue, error) {
+	doc := &Document{}
+	var issues []*models.QuizImportIssue
+
+	questions, category, err := splitGIFT(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if category != "" {
+		parts := strings.Split(category, "/")
+		doc.Title = strings.TrimSpace(parts[len(parts)-1])
+	}
+
+	for i, raw := range questions {
+		question, problems := parseGIFTQuestion(raw)
+		for _, p := range problems {
+			p.Item = i + 1
+			p.Position = raw.line
+		}
+		issues = append(issues, problems...)
+
+		doc.Questions = append(doc.Questions, question)
+		doc.Positions = append(doc.Positions, raw.line)
+	}
+
+	return doc, issues, nil
+}
+
+// splitGIFT splits the input on blank lines outside answer blocks and collects comment metadata
+func splitGIFT(data []byte) ([]*giftQuestion, string, error) {
+	var (
+		questions []*giftQuestion
+		current   *giftQuestion
+		category  string
+		meta      = map[string]string{}
+		depth     int
+		lineNo    int
+	)
+
+	flush := func() {
+		if current != nil && strings.TrimSpace(current.text) != "" {
+			questions = append(questions, current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if depth == 0 {
+			switch {
+			case trimmed == "":
+				flush()
+				continue
+			case strings.HasPrefix(trimmed, "//"):
+				for _, m := range giftMetaRegexp.FindAllStringSubmatch(trimmed, -1) {
+					meta[strings.ToLower(m[1])] = strings.ToLower(m[2])
+				}
+				continue
+			case strings.HasPrefix(trimmed, "$CATEGORY:"):
+				flush()
+				if category == "" {
+					category = strings.TrimSpace(strings.TrimPrefix(trimmed, "$CATEGORY:"))
+					category = strings.TrimPrefix(category, "$course$/")
+				}
+				continue
+			}
+		}
+
+		if current == nil {
+			current = &giftQuestion{line: lineNo, meta: meta}
+			meta = map[string]string{}
+		} else {
+			current.text += "\n"
+		}
+		current.text += line
+		depth += braceDelta(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read GIFT input: %w", err)
+	}
+	flush()
+
+	return questions, category, nil
+}
+
+// braceDelta counts unescaped braces so blank lines inside an answer block do not split a question
+func braceDelta(line string) int {
+	delta := 0
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '{':
+			delta++
+		case r == '}':
+			delta--
+		}
+	}
+	return delta
+}
+
+func parseGIFTQuestion(raw *giftQuestion) (*models.Question, []*models.QuizImportIssue) {
+	var issues []*models.QuizImportIssue
+	report := func(severity, format string, args ...interface{}) {
+		issues = append(issues, &models.QuizImportIssue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	question := &models.Question{
+		Difficulty: raw.meta["difficulty"],
+	}
+	if points, ok := raw.meta["points"]; ok {
+		if n, err := strconv.Atoi(points); err == nil {
+			question.Points = n
+		}
+	}
+
+	text := strings.TrimSpace(raw.text)
+
+	// Optional ::title:: prefix, only used when the question text is empty
+	var title string
+	if strings.HasPrefix(text, "::") {
+		if end := indexUnescaped(text[2:], "::"); end >= 0 {
+			title = text[2 : 2+end]
+			text = strings.TrimSpace(text[2+end+2:])
+		}
+	}
+	text = giftFormatPrefixRegexp.ReplaceAllString(text, "")
+
+	open := indexUnescaped(text, "{")
+	if open < 0 {
+		report(SeverityError, "question has no answer block")
+		question.Text = unescapeGIFT(text)
+		return question, issues
+	}
+	closeIdx := indexUnescaped(text[open:], "}")
+	if closeIdx < 0 {
+		report(SeverityError, "answer block is not closed")
+		question.Text = unescapeGIFT(text[:open])
+		return question, issues
+	}
+	closeIdx += open
+
+	before := strings.TrimSpace(text[:open])
+	after := strings.TrimSpace(text[closeIdx+1:])
+	block := strings.TrimSpace(text[open+1 : closeIdx])
+
+	// An answer block in the middle of the text is a missing word question
+	question.Text = unescapeGIFT(before)
+	if after != "" {
+		question.Text = unescapeGIFT(before + " _____ " + after)
+	}
+	if question.Text == "" {
+		question.Text = unescapeGIFT(title)
+	}
+
+	body, general := splitGeneralFeedback(block)
+	question.Explanation = unescapeGIFT(general)
+
+	switch {
+	case body == "":
+		report(SeverityError, "essay questions are not supported, an answer is required")
+		question.QuestionType = "open_ended"
+
+	case isGIFTBool(body):
+		question.QuestionType = "true_false"
+		value, feedback := splitFeedback(body)
+		question.Answer, _ = normalizeBool(value)
+		if question.Explanation == "" {
+			// T/F feedback is #wrong#right, prefer the feedback shown for a correct answer
+			parts := splitUnescaped(feedback, '#')
+			question.Explanation = unescapeGIFT(parts[len(parts)-1])
+		}
+
+	case strings.HasPrefix(body, "#"):
+		question.QuestionType = "open_ended"
+		value, _ := splitFeedback(strings.TrimPrefix(body, "#"))
+		value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+		switch {
+		case strings.Contains(value, ".."):
+			report(SeverityError, "numeric ranges are not supported")
+		case strings.Contains(value, ":"):
+			i := strings.Index(value, ":")
+			report(SeverityWarning, "numeric tolerance %q is ignored, only the exact value is accepted", value[i+1:])
+			value = value[:i]
+		}
+		question.Answer = strings.TrimSpace(value)
+
+	default:
+		answers, err := parseGIFTAnswers(body)
+		if err != nil {
+			report(SeverityError, "%s", err.Error())
+			question.QuestionType = "open_ended"
+			return question, issues
+		}
+
+		var correct []*giftAnswer
+		hasWrong := false
+		for _, a := range answers {
+			if a.correct {
+				correct = append(correct, a)
+			} else {
+				hasWrong = true
+			}
+			if a.weight != nil && *a.weight > 0 && *a.weight < 100 {
+				report(SeverityWarning, "partial credit %.0f%% for %q is not supported and is treated as wrong", *a.weight, a.text)
+			}
+		}
+
+		if hasWrong {
+			question.QuestionType = "multiple_choice"
+			for _, a := range answers {
+				question.Options = append(question.Options, a.text)
+			}
+		} else {
+			question.QuestionType = "open_ended"
+		}
+
+		switch len(correct) {
+		case 0:
+			report(SeverityError, "no answer is marked correct")
+		case 1:
+			question.Answer = correct[0].text
+		default:
+			if hasWrong {
+				report(SeverityError, "multiple correct answers are not supported")
+			} else {
+				report(SeverityWarning, "only the first of %d accepted answers is kept", len(correct))
+			}
+			question.Answer = correct[0].text
+		}
+
+		if question.Explanation == "" && len(correct) > 0 {
+			question.Explanation = correct[0].feedback
+		}
+	}
+
+	return question, issues
+}
+
+// parseGIFTAnswers splits a choice or short answer block into its =/~ entries
+func parseGIFTAnswers(body string) ([]*giftAnswer, error) {
+	var answers []*giftAnswer
+	var current *strings.Builder
+	var marker rune
+
+	finish := func() error {
+		if current == nil {
+			return nil
+		}
+		raw := strings.TrimSpace(current.String())
+		if strings.Contains(raw, "->") {
+			return fmt.Errorf("matching questions are not supported")
+		}
+
+		answer := &giftAnswer{correct: marker == '='}
+		if strings.HasPrefix(raw, "%") {
+			if end := strings.Index(raw[1:], "%"); end >= 0 {
+				weight, err := strconv.ParseFloat(raw[1:1+end], 64)
+				if err != nil {
+					return fmt.Errorf("invalid answer weight %q", raw[1:1+end])
+				}
+				answer.weight = &weight
+				answer.correct = weight >= 100
+				raw = raw[end+2:]
+			}
+		}
+
+		value, feedback := splitFeedback(raw)
+		answer.text = unescapeGIFT(value)
+		answer.feedback = unescapeGIFT(feedback)
+		if answer.text == "" {
+			return fmt.Errorf("empty answer option")
+		}
+		answers = append(answers, answer)
+		return nil
+	}
+
+	escaped := false
+	for _, r := range body {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '=' || r == '~':
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			current = &strings.Builder{}
+			marker = r
+			continue
+		}
+		if current == nil {
+			if r == ' ' || r == '\n' || r == '\t' || r == '\r' {
+				continue
+			}
+			return nil, fmt.Errorf("answers must start with = or ~")
+		}
+		current.WriteRune(r)
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("answer block has no answers")
+	}
+	return answers, nil
+}
+
+func isGIFTBool(body string) bool {
+	value, _ := splitFeedback(body)
+	_, ok := normalizeBool(value)
+	return ok
+}
+
+// splitGeneralFeedback separates the ####general feedback from the answers
+func splitGeneralFeedback(block string) (string, string) {
+	if i := indexUnescaped(block, "####"); i >= 0 {
+		return strings.TrimSpace(block[:i]), strings.TrimSpace(block[i+4:])
+	}
+	return block, ""
+}
+
+// splitFeedback separates an answer from its #feedback
+func splitFeedback(answer string) (string, string) {
+	if i := indexUnescaped(answer, "#"); i >= 0 {
+		return strings.TrimSpace(answer[:i]), strings.TrimSpace(answer[i+1:])
+	}
+	return strings.TrimSpace(answer), ""
+}
+
+func indexUnescaped(s string, sep string) int {
+	for i := 0; i+len(sep) <= len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i:i+len(sep)] == sep {
+			return i
+		}
+	}
+	return -1
+}
+
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var giftUnescaper = strings.NewReplacer(`\~`, "~", `\=`, "=", `\#`, "#", `\{`, "{", `\}`, "}", `\:`, ":", `\n`, "\n", `\\`, `\`)
+
+var giftEscaper = strings.NewReplacer(`\`, `\\`, "~", `\~`, "=", `\=`, "#", `\#`, "{", `\{`, "}", `\}`, ":", `\:`, "\n", `\n`)
+
+func unescapeGIFT(s string) string {
+	return strings.TrimSpace(giftUnescaper.Replace(strings.TrimSpace(s)))
+}
+
+func escapeGIFT(s string) string {
+	return giftEscaper.Replace(s)
+}
+
+func encodeGIFT(quiz *models.Quiz, questions []*models.Question) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "$CATEGORY: $course$/%s\n", strings.ReplaceAll(quiz.Title, "/", "-"))
+	if quiz.Description != "" {
+		fmt.Fprintf(&buf, "// %s\n", strings.ReplaceAll(quiz.Description, "\n", " "))
+	}
+	buf.WriteString("\n")
+
+	for i, q := range questions {
+		fmt.Fprintf(&buf, "// difficulty: %s, points: %d\n", q.Difficulty, q.Points)
+		fmt.Fprintf(&buf, "::Q%d:: %s {", i+1, escapeGIFT(q.Text))
+
+		switch q.QuestionType {
+		case "true_false":
+			answer, ok := normalizeBool(q.Answer)
+			if !ok {
+				return nil, fmt.Errorf("question %s has an invalid true/false answer %q", q.QuestionID, q.Answer)
+			}
+			buf.WriteString(strings.ToUpper(answer))
+		case "multiple_choice":
+			buf.WriteString("\n")
+			for _, option := range q.Options {
+				marker := "~"
+				if option == q.Answer {
+					marker = "="
+				}
+				fmt.Fprintf(&buf, "\t%s%s\n", marker, escapeGIFT(option))
+			}
+		default:
+			fmt.Fprintf(&buf, "=%s", escapeGIFT(q.Answer))
+		}
+
+		if q.Explanation != "" {
+			fmt.Fprintf(&buf, "####%s", escapeGIFT(q.Explanation))
+			if q.QuestionType == "multiple_choice" {
+				buf.WriteString("\n")
+			}
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	return buf.Bytes(), nil
+}
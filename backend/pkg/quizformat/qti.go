@@ -0,0 +1,669 @@
+package quizformat
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+const (
+	qtiNamespace      = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiCPNamespace    = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiResponseID     = "RESPONSE"
+	qtiExplanationID  = "EXPLANATION"
+	maxQTIPackageSize = 20 << 20
+)
+
+// Scoring: full MAXSCORE when the response matches, and always show the explanation
+const qtiResponseProcessing = `
+		<responseCondition>
+			<responseIf>
+				<match>
+					<variable identifier="RESPONSE"/>
+					<correct identifier="RESPONSE"/>
+				</match>
+				<setOutcomeValue identifier="SCORE">
+					<variable identifier="MAXSCORE"/>
+				</setOutcomeValue>
+			</responseIf>
+		</responseCondition>
+		<setOutcomeValue identifier="FEEDBACK">
+			<baseValue baseType="identifier">EXPLANATION</baseValue>
+		</setOutcomeValue>
+	`
+
+// Decoding types, matched on local names so any namespace prefix is accepted
+
+type qtiItem struct {
+	Identifier           string                   `xml:"identifier,attr"`
+	Title                string                   `xml:"title,attr"`
+	ResponseDeclarations []qtiResponseDeclaration `xml:"responseDeclaration"`
+	OutcomeDeclarations  []qtiOutcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody             qtiInnerXML              `xml:"itemBody"`
+	ModalFeedback        []qtiInnerXML            `xml:"modalFeedback"`
+}
+
+type qtiResponseDeclaration struct {
+	Identifier      string   `xml:"identifier,attr"`
+	Cardinality     string   `xml:"cardinality,attr"`
+	BaseType        string   `xml:"baseType,attr"`
+	CorrectResponse []string `xml:"correctResponse>value"`
+}
+
+type qtiOutcomeDeclaration struct {
+	Identifier   string `xml:"identifier,attr"`
+	Cardinality  string `xml:"cardinality,attr"`
+	BaseType     string `xml:"baseType,attr"`
+	DefaultValue string `xml:"defaultValue>value,omitempty"`
+}
+
+type qtiInnerXML struct {
+	Inner string `xml:",innerxml"`
+}
+
+type qtiTest struct {
+	Title string `xml:"title,attr"`
+}
+
+// Encoding types
+
+type qtiItemOut struct {
+	XMLName              xml.Name                 `xml:"assessmentItem"`
+	Xmlns                string                   `xml:"xmlns,attr"`
+	Identifier           string                   `xml:"identifier,attr"`
+	Title                string                   `xml:"title,attr"`
+	Adaptive             bool                     `xml:"adaptive,attr"`
+	TimeDependent        bool                     `xml:"timeDependent,attr"`
+	ResponseDeclarations []qtiResponseDeclaration `xml:"responseDeclaration"`
+	OutcomeDeclarations  []qtiOutcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody             qtiInnerXML              `xml:"itemBody"`
+	ResponseProcessing   qtiInnerXML              `xml:"responseProcessing"`
+	ModalFeedback        *qtiFeedbackOut          `xml:"modalFeedback,omitempty"`
+}
+
+type qtiFeedbackOut struct {
+	OutcomeIdentifier string `xml:"outcomeIdentifier,attr"`
+	Identifier        string `xml:"identifier,attr"`
+	ShowHide          string `xml:"showHide,attr"`
+	Text              string `xml:",chardata"`
+}
+
+type qtiTestOut struct {
+	XMLName    xml.Name       `xml:"assessmentTest"`
+	Xmlns      string         `xml:"xmlns,attr"`
+	Identifier string         `xml:"identifier,attr"`
+	Title      string         `xml:"title,attr"`
+	TestPart   qtiTestPartOut `xml:"testPart"`
+}
+
+type qtiTestPartOut struct {
+	Identifier     string        `xml:"identifier,attr"`
+	NavigationMode string        `xml:"navigationMode,attr"`
+	SubmissionMode string        `xml:"submissionMode,attr"`
+	Section        qtiSectionOut `xml:"assessmentSection"`
+}
+
+type qtiSectionOut struct {
+	Identifier string          `xml:"identifier,attr"`
+	Title      string          `xml:"title,attr"`
+	Visible    bool            `xml:"visible,attr"`
+	ItemRefs   []qtiItemRefOut `xml:"assessmentItemRef"`
+}
+
+type qtiItemRefOut struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type qtiManifestOut struct {
+	XMLName       xml.Name         `xml:"manifest"`
+	Xmlns         string           `xml:"xmlns,attr"`
+	Identifier    string           `xml:"identifier,attr"`
+	Organizations struct{}         `xml:"organizations"`
+	Resources     []qtiResourceOut `xml:"resources>resource"`
+}
+
+type qtiResourceOut struct {
+	Identifier   string             `xml:"identifier,attr"`
+	Type         string             `xml:"type,attr"`
+	Href         string             `xml:"href,attr"`
+	Files        []qtiFileOut       `xml:"file"`
+	Dependencies []qtiDependencyOut `xml:"dependency"`
+}
+
+type qtiFileOut struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiDependencyOut struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+// qtiBody is what the question needs from an itemBody
+type qtiBody struct {
+	text        []string
+	interaction string
+	maxChoices  int
+	choiceIDs   []string
+	choices     map[string]string
+}
+
+func decodeQTI(data []byte) (*Document, []*models.QuizImportIssue, error) {
+	doc := &Document{}
+	var issues []*models.QuizImportIssue
+
+	files, err := qtiFiles(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item := 0
+	for _, file := range files {
+		root, err := rootElement(file.data)
+		if err != nil {
+			issues = append(issues, &models.QuizImportIssue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("%s: skipped, not valid XML: %v", file.name, err),
+			})
+			continue
+		}
+
+		switch root {
+		case "assessmentTest":
+			var test qtiTest
+			if err := xml.Unmarshal(file.data, &test); err == nil && doc.Title == "" {
+				doc.Title = test.Title
+			}
+		case "assessmentItem":
+			item++
+			question, problems := parseQTIItem(file.data)
+			for _, p := range problems {
+				p.Item = item
+				p.Position = item
+				if file.name != "" {
+					p.Message = file.name + ": " + p.Message
+				}
+			}
+			issues = append(issues, problems...)
+			doc.Questions = append(doc.Questions, question)
+			doc.Positions = append(doc.Positions, item)
+		}
+	}
+
+	if item == 0 {
+		if len(files) == 1 && files[0].name == "" {
+			return nil, nil, fmt.Errorf("no assessmentItem found, upload a single item or a zipped QTI content package")
+		}
+		return nil, nil, fmt.Errorf("no assessmentItem found in the QTI package")
+	}
+
+	return doc, issues, nil
+}
+
+type qtiFile struct {
+	name string
+	data []byte
+}
+
+// qtiFiles returns the XML documents of a content package, or the input itself when it is plain XML
+func qtiFiles(data []byte) ([]qtiFile, error) {
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		return []qtiFile{{data: data}}, nil
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open QTI package: %w", err)
+	}
+
+	// The budget counts the bytes actually inflated, the sizes the archive declares can be forged
+	var files []qtiFile
+	remaining := int64(maxQTIPackageSize)
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".xml") || path.Base(f.Name) == "imsmanifest.xml" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, remaining+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+
+		remaining -= int64(len(content))
+		if remaining < 0 {
+			return nil, fmt.Errorf("QTI package is larger than %d MB uncompressed", maxQTIPackageSize>>20)
+		}
+		files = append(files, qtiFile{name: f.Name, data: content})
+	}
+
+	// Zip order is arbitrary, item-1.xml, item-2.xml, ... read back in order
+	sort.SliceStable(files, func(i, j int) bool {
+		return naturalLess(files[i].name, files[j].name)
+	})
+
+	return files, nil
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseQTIItem(data []byte) (*models.Question, []*models.QuizImportIssue) {
+	var issues []*models.QuizImportIssue
+	report := func(severity, format string, args ...interface{}) {
+		issues = append(issues, &models.QuizImportIssue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	question := &models.Question{}
+
+	var item qtiItem
+	if err := xml.Unmarshal(data, &item); err != nil {
+		report(SeverityError, "invalid assessmentItem: %v", err)
+		return question, issues
+	}
+
+	for _, outcome := range item.OutcomeDeclarations {
+		value := strings.TrimSpace(outcome.DefaultValue)
+		switch strings.ToUpper(outcome.Identifier) {
+		case "MAXSCORE":
+			if points, err := strconv.ParseFloat(value, 64); err == nil {
+				question.Points = int(points)
+			}
+		case "DIFFICULTY":
+			question.Difficulty = strings.ToLower(value)
+		}
+	}
+
+	for _, feedback := range item.ModalFeedback {
+		if text := xmlText(feedback.Inner); text != "" {
+			question.Explanation = text
+			break
+		}
+	}
+
+	body, err := parseQTIBody(item.ItemBody.Inner)
+	if err != nil {
+		report(SeverityError, "invalid itemBody: %v", err)
+		return question, issues
+	}
+	question.Text = strings.Join(body.text, " ")
+	if question.Text == "" {
+		question.Text = item.Title
+	}
+
+	var correct []string
+	for _, declaration := range item.ResponseDeclarations {
+		if declaration.Identifier == qtiResponseID || len(item.ResponseDeclarations) == 1 {
+			correct = declaration.CorrectResponse
+			if declaration.Cardinality != "" && declaration.Cardinality != "single" {
+				report(SeverityError, "response cardinality %q is not supported, only single answers are", declaration.Cardinality)
+			}
+		}
+	}
+	if len(correct) == 0 {
+		report(SeverityError, "no correctResponse declared")
+	}
+
+	switch body.interaction {
+	case "choiceInteraction":
+		if body.maxChoices > 1 {
+			report(SeverityError, "choice interactions with more than one selectable answer are not supported")
+		}
+		for _, id := range body.choiceIDs {
+			question.Options = append(question.Options, body.choices[id])
+		}
+		if len(correct) > 0 {
+			answer, ok := body.choices[strings.TrimSpace(correct[0])]
+			if !ok {
+				report(SeverityError, "correct response %q does not match any choice", correct[0])
+			}
+			question.Answer = answer
+		}
+
+		question.QuestionType = "multiple_choice"
+		if isTrueFalseChoices(question.Options) {
+			question.QuestionType = "true_false"
+		}
+	case "textEntryInteraction", "extendedTextInteraction":
+		question.QuestionType = "open_ended"
+		if len(correct) > 0 {
+			question.Answer = strings.TrimSpace(correct[0])
+		}
+	case "":
+		report(SeverityError, "itemBody has no interaction")
+	default:
+		report(SeverityError, "%s is not supported", body.interaction)
+	}
+
+	return question, issues
+}
+
+// parseQTIBody walks an itemBody collecting the stem text and the single interaction it holds
+func parseQTIBody(inner string) (*qtiBody, error) {
+	body := &qtiBody{choices: map[string]string{}, maxChoices: 1}
+
+	decoder := xml.NewDecoder(strings.NewReader("<itemBody>" + inner + "</itemBody>"))
+	decoder.Strict = false
+
+	var (
+		choiceID   string
+		choiceText strings.Builder
+		stem       strings.Builder
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if strings.HasSuffix(t.Name.Local, "Interaction") {
+				if body.interaction != "" {
+					return nil, fmt.Errorf("items with more than one interaction are not supported")
+				}
+				body.interaction = t.Name.Local
+				if t.Name.Local == "textEntryInteraction" {
+					stem.WriteString(" _____ ")
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "maxChoices" {
+						if n, err := strconv.Atoi(attr.Value); err == nil {
+							body.maxChoices = n
+						}
+					}
+				}
+				continue
+			}
+			if t.Name.Local == "simpleChoice" {
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "identifier" {
+						choiceID = attr.Value
+					}
+				}
+				choiceText.Reset()
+			}
+			if isBlockElement(t.Name.Local) {
+				stem.WriteString(" ")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "simpleChoice" && choiceID != "" {
+				body.choiceIDs = append(body.choiceIDs, choiceID)
+				body.choices[choiceID] = collapseSpace(choiceText.String())
+				choiceID = ""
+			}
+			if t.Name.Local == "prompt" || isBlockElement(t.Name.Local) {
+				stem.WriteString(" ")
+			}
+		case xml.CharData:
+			// Inside an interaction only prompts and choices carry text
+			if choiceID != "" {
+				choiceText.Write(t)
+			} else {
+				stem.Write(t)
+			}
+		}
+	}
+
+	// A blank at the very end only marks where the answer box goes
+	text := strings.TrimSpace(strings.TrimSuffix(collapseSpace(stem.String()), "_____"))
+	if text != "" {
+		body.text = append(body.text, text)
+	}
+
+	return body, nil
+}
+
+func isBlockElement(name string) bool {
+	switch name {
+	case "p", "div", "br", "li", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
+
+func isTrueFalseChoices(options []string) bool {
+	if len(options) != 2 {
+		return false
+	}
+	first, ok1 := normalizeBool(options[0])
+	second, ok2 := normalizeBool(options[1])
+	return ok1 && ok2 && first != second
+}
+
+// xmlText strips the markup from an XML fragment
+func xmlText(inner string) string {
+	decoder := xml.NewDecoder(strings.NewReader("<root>" + inner + "</root>"))
+	decoder.Strict = false
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			if isBlockElement(t.Name.Local) {
+				text.WriteString(" ")
+			}
+		}
+	}
+	return collapseSpace(text.String())
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// naturalLess orders item-2.xml before item-10.xml
+func naturalLess(a, b string) bool {
+	na, sa := trailingNumber(a)
+	nb, sb := trailingNumber(b)
+	if sa == sb && na >= 0 && nb >= 0 {
+		return na < nb
+	}
+	return a < b
+}
+
+func trailingNumber(name string) (int, string) {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	i := len(base)
+	for i > 0 && base[i-1] >= '0' && base[i-1] <= '9' {
+		i--
+	}
+	n, err := strconv.Atoi(base[i:])
+	if err != nil {
+		return -1, base
+	}
+	return n, base[:i]
+}
+
+func encodeQTI(quiz *models.Quiz, questions []*models.Question) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	test := qtiTestOut{
+		Xmlns:      qtiNamespace,
+		Identifier: "quiz-" + quiz.QuizID.String(),
+		Title:      quiz.Title,
+		TestPart: qtiTestPartOut{
+			Identifier:     "part-1",
+			NavigationMode: "linear",
+			SubmissionMode: "simultaneous",
+			Section: qtiSectionOut{
+				Identifier: "section-1",
+				Title:      quiz.Title,
+				Visible:    true,
+			},
+		},
+	}
+
+	manifest := qtiManifestOut{
+		Xmlns:      qtiCPNamespace,
+		Identifier: "manifest-" + quiz.QuizID.String(),
+	}
+	testResource := qtiResourceOut{
+		Identifier: "test",
+		Type:       "imsqti_test_xmlv2p1",
+		Href:       "assessmentTest.xml",
+		Files:      []qtiFileOut{{Href: "assessmentTest.xml"}},
+	}
+
+	for i, q := range questions {
+		identifier := fmt.Sprintf("item-%d", i+1)
+		href := fmt.Sprintf("items/%s.xml", identifier)
+
+		item, err := qtiItemFromQuestion(identifier, q)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeXML(archive, href, item); err != nil {
+			return nil, err
+		}
+
+		test.TestPart.Section.ItemRefs = append(test.TestPart.Section.ItemRefs, qtiItemRefOut{Identifier: identifier, Href: href})
+		testResource.Dependencies = append(testResource.Dependencies, qtiDependencyOut{IdentifierRef: identifier})
+		manifest.Resources = append(manifest.Resources, qtiResourceOut{
+			Identifier: identifier,
+			Type:       "imsqti_item_xmlv2p1",
+			Href:       href,
+			Files:      []qtiFileOut{{Href: href}},
+		})
+	}
+	manifest.Resources = append([]qtiResourceOut{testResource}, manifest.Resources...)
+
+	if err := writeXML(archive, "assessmentTest.xml", test); err != nil {
+		return nil, err
+	}
+	if err := writeXML(archive, "imsmanifest.xml", manifest); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write QTI package: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func qtiItemFromQuestion(identifier string, q *models.Question) (*qtiItemOut, error) {
+	item := &qtiItemOut{
+		Xmlns:      qtiNamespace,
+		Identifier: identifier,
+		Title:      truncate(q.Text, 100),
+		OutcomeDeclarations: []qtiOutcomeDeclaration{
+			{Identifier: "SCORE", Cardinality: "single", BaseType: "float", DefaultValue: "0"},
+			{Identifier: "MAXSCORE", Cardinality: "single", BaseType: "float", DefaultValue: strconv.Itoa(q.Points)},
+			{Identifier: "DIFFICULTY", Cardinality: "single", BaseType: "identifier", DefaultValue: q.Difficulty},
+			{Identifier: "FEEDBACK", Cardinality: "single", BaseType: "identifier"},
+		},
+		ResponseProcessing: qtiInnerXML{Inner: qtiResponseProcessing},
+	}
+
+	if q.Explanation != "" {
+		item.ModalFeedback = &qtiFeedbackOut{
+			OutcomeIdentifier: "FEEDBACK",
+			Identifier:        qtiExplanationID,
+			ShowHide:          "show",
+			Text:              q.Explanation,
+		}
+	}
+
+	var body strings.Builder
+	switch q.QuestionType {
+	case "multiple_choice", "true_false":
+		options := q.Options
+		answer := q.Answer
+		if q.QuestionType == "true_false" {
+			options = []string{trueAnswer, falseAnswer}
+			answer, _ = normalizeBool(q.Answer)
+		}
+
+		correctID := ""
+		body.WriteString(`<choiceInteraction responseIdentifier="RESPONSE" shuffle="false" maxChoices="1"><prompt>`)
+		body.WriteString(escapeXML(q.Text))
+		body.WriteString(`</prompt>`)
+		for i, option := range options {
+			choiceID := fmt.Sprintf("choice-%d", i+1)
+			if option == answer {
+				correctID = choiceID
+			}
+			fmt.Fprintf(&body, `<simpleChoice identifier="%s">%s</simpleChoice>`, choiceID, escapeXML(option))
+		}
+		body.WriteString(`</choiceInteraction>`)
+
+		if correctID == "" {
+			return nil, fmt.Errorf("question %s: answer %q is not one of the options", q.QuestionID, q.Answer)
+		}
+		item.ResponseDeclarations = []qtiResponseDeclaration{{
+			Identifier: qtiResponseID, Cardinality: "single", BaseType: "identifier", CorrectResponse: []string{correctID},
+		}}
+	default:
+		fmt.Fprintf(&body, `<p>%s</p><p><textEntryInteraction responseIdentifier="RESPONSE" expectedLength="%d"/></p>`,
+			escapeXML(q.Text), len(q.Answer)+5)
+		item.ResponseDeclarations = []qtiResponseDeclaration{{
+			Identifier: qtiResponseID, Cardinality: "single", BaseType: "string", CorrectResponse: []string{q.Answer},
+		}}
+	}
+	item.ItemBody = qtiInnerXML{Inner: body.String()}
+
+	return item, nil
+}
+
+func writeXML(archive *zip.Writer, name string, v interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to QTI package: %w", name, err)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return encoder.Flush()
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
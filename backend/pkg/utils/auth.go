@@ -5,27 +5,19 @@ import (
 
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
-	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-// Validate is user from owner of content
-func ValidateIsOwner(ctx context.Context, creatorID string, logger logger.Logger) error {
+// Check the user of the context was granted the permission, for the checks of the use cases
+func CheckPermission(ctx context.Context, permission string) error {
 	user, err := GetUserFromCtx(ctx)
 	if err != nil {
 		return err
 	}
-
-	if user.UserID.String() != creatorID {
-		logger.Errorf(
-			"ValidateIsOwner, userID: %v, creatorID: %v",
-			user.UserID.String(),
-			creatorID,
-		)
-		return httpErrors.Forbidden
+	if !user.HasPermission(permission) {
+		return httpErrors.PermissionDenied
 	}
-
 	return nil
 }
 
@@ -37,3 +29,12 @@ func GetUserIDFromContext(c echo.Context) (uuid.UUID, error) {
 	}
 	return user.UserID, nil
 }
+
+// Get the claims of the JWT the request was authenticated with from Echo context
+func GetClaimsFromContext(c echo.Context) (*Claims, error) {
+	claims, ok := c.Get("claims").(*Claims)
+	if !ok {
+		return nil, httpErrors.Unauthorized
+	}
+	return claims, nil
+}
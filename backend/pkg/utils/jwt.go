@@ -8,26 +8,52 @@ import (
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
+const defaultAccessTokenTTL = 15 * time.Minute
+
 // JWT Claims struct
 type Claims struct {
-	Email string `json:"email"`
-	ID    string `json:"id"`
+	Email       string   `json:"email"`
+	ID          string   `json:"id"`
+	SessionID   string   `json:"sid"` // refresh token family of the login, revoked together on logout
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
 	jwt.StandardClaims
 }
 
+// Signed JWT access token, its id (jti) is what the revocation denylist holds
+type JWTToken struct {
+	Token     string
+	TokenID   uuid.UUID
+	ExpiresAt time.Time
+}
+
 // Generate new JWT Token
-func GenerateJWTToken(user *models.User, config *config.Config) (string, error) {
-	// Register the JWT claims, which includes the username and expiry time
+func GenerateJWTToken(user *models.User, sessionID uuid.UUID, config *config.Config) (*JWTToken, error) {
+	ttl := config.Auth.AccessTokenTTL * time.Minute
+	if ttl <= 0 {
+		ttl = defaultAccessTokenTTL
+	}
+
+	now := time.Now()
+	tokenID := uuid.New()
+
+	// Register the JWT claims, which includes the username, the roles, the token id and expiry time
 	claims := &Claims{
-		Email: user.Email,
-		ID:    user.UserID.String(),
+		Email:       user.Email,
+		ID:          user.UserID.String(),
+		SessionID:   sessionID.String(),
+		Roles:       user.Roles,
+		Permissions: user.Permissions,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Minute * 60).Unix(),
+			Id:        tokenID.String(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
 		},
 	}
 
@@ -37,10 +63,14 @@ func GenerateJWTToken(user *models.User, config *config.Config) (string, error)
 	// Register the JWT string
 	tokenString, err := token.SignedString([]byte(config.Server.JwtSecretKey))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return tokenString, nil
+	return &JWTToken{
+		Token:     tokenString,
+		TokenID:   tokenID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
 }
 
 // Extract JWT From Request